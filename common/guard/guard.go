@@ -0,0 +1,172 @@
+// Package guard keeps tool responses within a token budget. Listings that fit are printed directly, larger ones are
+// written to a dataset and only a short summary is printed, which the model can page through with the dataset tools.
+// The budget is shared by all tools and can be changed for the deployment with OBOT_TOOL_OUTPUT_MAX_TOKENS.
+package guard
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gptscript-ai/go-gptscript"
+)
+
+const (
+	// EnvMaxTokens overrides DefaultMaxTokens for the deployment
+	EnvMaxTokens = "OBOT_TOOL_OUTPUT_MAX_TOKENS"
+	// DefaultMaxTokens is the largest output that is printed directly
+	DefaultMaxTokens = 10_000
+)
+
+// MaxTokens returns the configured token budget of a tool response.
+func MaxTokens() int {
+	if n, err := strconv.Atoi(os.Getenv(EnvMaxTokens)); err == nil && n > 0 {
+		return n
+	}
+	return DefaultMaxTokens
+}
+
+// EstimateTokens approximates the number of tokens of s with the usual four bytes per token.
+func EstimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+// Output returns output if it fits in the token budget. Otherwise it creates a dataset with the elements, which are
+// only built in that case, and returns a summary with the ID of the dataset and the number of elements, e.g.
+// "Created dataset with ID ... with 250 messages". The client is created on demand if it is nil.
+func Output(ctx context.Context, client *gptscript.GPTScript, output string, elements func() ([]gptscript.DatasetElement, error), opts gptscript.DatasetOptions, noun string) (string, error) {
+	maxTokens := MaxTokens()
+	tokens := EstimateTokens(output)
+	if tokens <= maxTokens {
+		return output, nil
+	}
+
+	datasetElements, err := elements()
+	if err != nil {
+		return "", fmt.Errorf("failed to split output into dataset elements: %w", err)
+	}
+
+	if client == nil {
+		client, err = gptscript.NewGPTScript()
+		if err != nil {
+			return "", fmt.Errorf("failed to create GPTScript client: %w", err)
+		}
+		defer client.Close()
+	}
+
+	datasetID, err := client.CreateDatasetWithElements(ctx, datasetElements, opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to create dataset with elements: %w", err)
+	}
+	return summary(datasetID, len(datasetElements), noun, tokens, maxTokens), nil
+}
+
+// Print prints output if it fits in the token budget, otherwise it creates a dataset with the elements and prints a
+// summary, see Output.
+func Print(ctx context.Context, client *gptscript.GPTScript, output string, elements func() ([]gptscript.DatasetElement, error), opts gptscript.DatasetOptions, noun string) error {
+	result, err := Output(ctx, client, output, elements, opts, noun)
+	if err != nil {
+		return err
+	}
+	fmt.Print(result)
+	return nil
+}
+
+// PrintElements prints the contents of the elements, separated by newlines, if they fit in the token budget.
+// Otherwise it creates a dataset with the elements and prints a summary.
+func PrintElements(ctx context.Context, client *gptscript.GPTScript, elements []gptscript.DatasetElement, opts gptscript.DatasetOptions, noun string) error {
+	return Print(ctx, client, joinContents(elements), func() ([]gptscript.DatasetElement, error) {
+		return elements, nil
+	}, opts, noun)
+}
+
+func joinContents(elements []gptscript.DatasetElement) string {
+	contents := make([]string, 0, len(elements))
+	for _, element := range elements {
+		contents = append(contents, element.Contents)
+	}
+	return strings.Join(contents, "\n")
+}
+
+func summary(datasetID string, count int, noun string, tokens, maxTokens int) string {
+	return fmt.Sprintf("Created dataset with ID %s with %d %s, because the output of about %d tokens exceeds the limit of %d tokens\n", datasetID, count, noun, tokens, maxTokens)
+}
+
+// streamBatchSize is the number of elements added to the dataset at once, once a Writer streams into a dataset
+const streamBatchSize = 100
+
+// datasetClient is the part of the GPTScript client the Writer uses
+type datasetClient interface {
+	AddDatasetElements(ctx context.Context, datasetID string, elements []gptscript.DatasetElement, options ...gptscript.DatasetOptions) (string, error)
+}
+
+// Writer is the streaming variant of Print for listings that can be too large to keep in memory. Elements are
+// buffered while they fit in the token budget. Once they exceed it, they are written to a dataset, and all following
+// elements are added to the dataset in batches.
+type Writer struct {
+	client    datasetClient
+	out       io.Writer
+	opts      gptscript.DatasetOptions
+	noun      string
+	maxTokens int
+
+	buffered  []gptscript.DatasetElement
+	tokens    int
+	datasetID string
+	count     int
+}
+
+func NewWriter(client *gptscript.GPTScript, opts gptscript.DatasetOptions, noun string) *Writer {
+	return &Writer{
+		client:    client,
+		out:       os.Stdout,
+		opts:      opts,
+		noun:      noun,
+		maxTokens: MaxTokens(),
+	}
+}
+
+// Add adds the elements to the output.
+func (w *Writer) Add(ctx context.Context, elements ...gptscript.DatasetElement) error {
+	for _, element := range elements {
+		w.buffered = append(w.buffered, element)
+		w.tokens += EstimateTokens(element.Contents + "\n")
+		w.count++
+	}
+
+	if w.datasetID == "" && w.tokens <= w.maxTokens || w.datasetID != "" && len(w.buffered) < streamBatchSize {
+		return nil
+	}
+	return w.flush(ctx)
+}
+
+// Close prints the elements, or the ID of the dataset if they didn't fit in the token budget.
+func (w *Writer) Close(ctx context.Context) error {
+	if w.datasetID == "" {
+		_, err := fmt.Fprint(w.out, joinContents(w.buffered))
+		return err
+	}
+
+	if err := w.flush(ctx); err != nil {
+		return err
+	}
+	_, err := fmt.Fprint(w.out, summary(w.datasetID, w.count, w.noun, w.tokens, w.maxTokens))
+	return err
+}
+
+func (w *Writer) flush(ctx context.Context) error {
+	if len(w.buffered) == 0 {
+		return nil
+	}
+
+	datasetID, err := w.client.AddDatasetElements(ctx, w.datasetID, w.buffered, w.opts)
+	if err != nil {
+		return fmt.Errorf("failed to add elements to dataset: %w", err)
+	}
+	w.datasetID = datasetID
+	w.buffered = w.buffered[:0]
+	return nil
+}
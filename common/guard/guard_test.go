@@ -0,0 +1,195 @@
+package guard
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/gptscript-ai/go-gptscript"
+)
+
+type fakeDatasets struct {
+	calls    int
+	elements []gptscript.DatasetElement
+}
+
+func (f *fakeDatasets) AddDatasetElements(_ context.Context, datasetID string, elements []gptscript.DatasetElement, _ ...gptscript.DatasetOptions) (string, error) {
+	f.calls++
+	f.elements = append(f.elements, elements...)
+	if datasetID == "" {
+		datasetID = "dataset-1"
+	}
+	return datasetID, nil
+}
+
+func newTestWriter(maxTokens int) (*Writer, *fakeDatasets, *bytes.Buffer) {
+	var (
+		datasets = &fakeDatasets{}
+		out      = &bytes.Buffer{}
+	)
+	return &Writer{client: datasets, out: out, noun: "messages", maxTokens: maxTokens}, datasets, out
+}
+
+func element(contents string) gptscript.DatasetElement {
+	return gptscript.DatasetElement{Contents: contents}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"", 0},
+		{"a", 1},
+		{"abcd", 1},
+		{"abcde", 2},
+		// Multi-byte runes count with their encoded size
+		{"é", 1},
+		{"€€", 2},
+		{"😀", 1},
+		{"😀a", 2},
+	}
+
+	for _, tt := range tests {
+		if got := EstimateTokens(tt.s); got != tt.want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestWriterBudgetEdge(t *testing.T) {
+	tests := []struct {
+		name        string
+		contents    []string
+		wantDataset bool
+	}{
+		// Each element is counted with the newline that separates it from the next one
+		{name: "empty", contents: nil},
+		{name: "exactly at the budget", contents: []string{strings.Repeat("a", 39)}},
+		{name: "one byte over the budget", contents: []string{strings.Repeat("a", 40)}, wantDataset: true},
+		{name: "several elements at the budget", contents: []string{strings.Repeat("a", 19), strings.Repeat("b", 19)}},
+		{name: "several elements over the budget", contents: []string{strings.Repeat("a", 19), strings.Repeat("b", 20)}, wantDataset: true},
+		{name: "multi-byte runes at the budget", contents: []string{strings.Repeat("€", 13)}},
+		{name: "multi-byte runes one byte over the budget", contents: []string{strings.Repeat("€", 13) + "a"}, wantDataset: true},
+		{name: "4-byte runes over the budget", contents: []string{strings.Repeat("😀", 10)}, wantDataset: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, datasets, out := newTestWriter(10)
+			for _, contents := range tt.contents {
+				if err := w.Add(context.Background(), element(contents)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Close(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			if !tt.wantDataset {
+				if datasets.calls != 0 {
+					t.Fatalf("created a dataset for output within the budget")
+				}
+				if got, want := out.String(), strings.Join(tt.contents, "\n"); got != want {
+					t.Errorf("output = %q, want %q", got, want)
+				}
+				return
+			}
+
+			if !strings.HasPrefix(out.String(), "Created dataset with ID dataset-1 with ") {
+				t.Errorf("output = %q, want the dataset summary", out.String())
+			}
+			// Elements are never cut, so multi-byte runes stay intact
+			if len(datasets.elements) != len(tt.contents) {
+				t.Fatalf("dataset has %d elements, want %d", len(datasets.elements), len(tt.contents))
+			}
+			for i, e := range datasets.elements {
+				if e.Contents != tt.contents[i] {
+					t.Errorf("element %d = %q, want %q", i, e.Contents, tt.contents[i])
+				}
+				if !utf8.ValidString(e.Contents) {
+					t.Errorf("element %d is not valid UTF-8", i)
+				}
+			}
+		})
+	}
+}
+
+func TestWriterStreamsInBatches(t *testing.T) {
+	w, datasets, out := newTestWriter(1)
+
+	const n = 2*streamBatchSize + 5
+	for i := range n {
+		if err := w.Add(context.Background(), element(fmt.Sprintf("message %d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(datasets.elements) != n {
+		t.Fatalf("dataset has %d elements, want %d", len(datasets.elements), n)
+	}
+	for i, e := range datasets.elements {
+		if want := fmt.Sprintf("message %d", i); e.Contents != want {
+			t.Fatalf("element %d = %q, want %q", i, e.Contents, want)
+		}
+	}
+	// The first element exceeds the budget, then every full batch and the rest on close
+	if want := 1 + 2 + 1; datasets.calls != want {
+		t.Errorf("dataset calls = %d, want %d", datasets.calls, want)
+	}
+	if !strings.Contains(out.String(), fmt.Sprintf("with %d messages", n)) {
+		t.Errorf("output = %q, want the number of messages", out.String())
+	}
+}
+
+func TestMaxTokens(t *testing.T) {
+	tests := []struct {
+		env  string
+		want int
+	}{
+		{"", DefaultMaxTokens},
+		{"2000", 2000},
+		{"0", DefaultMaxTokens},
+		{"-5", DefaultMaxTokens},
+		{"lots", DefaultMaxTokens},
+	}
+	for _, tt := range tests {
+		t.Setenv(EnvMaxTokens, tt.env)
+		if got := MaxTokens(); got != tt.want {
+			t.Errorf("MaxTokens() with %s=%q = %d, want %d", EnvMaxTokens, tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestOutputWithinBudget(t *testing.T) {
+	t.Setenv(EnvMaxTokens, "10")
+
+	output := strings.Repeat("a", 40)
+	got, err := Output(context.Background(), nil, output, func() ([]gptscript.DatasetElement, error) {
+		t.Fatal("elements built for output within the budget")
+		return nil, nil
+	}, gptscript.DatasetOptions{}, "rows")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != output {
+		t.Errorf("Output() = %q, want %q", got, output)
+	}
+}
+
+func TestOutputElementsError(t *testing.T) {
+	t.Setenv(EnvMaxTokens, "10")
+
+	_, err := Output(context.Background(), nil, strings.Repeat("a", 41), func() ([]gptscript.DatasetElement, error) {
+		return nil, fmt.Errorf("invalid result")
+	}, gptscript.DatasetOptions{}, "rows")
+	if err == nil || !strings.Contains(err.Error(), "invalid result") {
+		t.Errorf("Output() error = %v, want the error of the elements", err)
+	}
+}
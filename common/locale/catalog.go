@@ -0,0 +1,916 @@
+package locale
+
+// catalog holds the translations of the fixed strings, keyed by language and the English string. Every language
+// that has formats must translate every string, so that no output mixes languages.
+var catalog = map[string]map[string]string{
+	"de": {
+		// Mail
+		"Name":                   "Name",
+		"ID":                     "ID",
+		"Parent folder ID":       "ID des übergeordneten Ordners",
+		"Unread item count":      "Anzahl ungelesener Elemente",
+		"Total item count":       "Anzahl Elemente",
+		"Subject":                "Betreff",
+		"Message ID":             "Nachrichten-ID",
+		"Sender":                 "Absender",
+		"email address":          "E-Mail-Adresse",
+		"Received":               "Empfangen",
+		"Created":                "Erstellt",
+		"Is unread":              "Ungelesen",
+		"Link":                   "Link",
+		"To":                     "An",
+		"CC":                     "CC",
+		"Has attachments":        "Hat Anhänge",
+		"Body":                   "Inhalt",
+		"Body preview":           "Vorschau",
+		"no messages found":      "keine Nachrichten gefunden",
+		"Type":                   "Typ",
+		"Is inline":              "Inline",
+		"Follow-up flag":         "Nachverfolgung",
+		"Categories":             "Kategorien",
+		"Enabled":                "Aktiviert",
+		"Conditions":             "Bedingungen",
+		"Actions":                "Aktionen",
+		"Status":                 "Status",
+		"External audience":      "Externe Empfänger",
+		"Internal reply message": "Interne Antwort",
+		"External reply message": "Externe Antwort",
+		"due":                    "fällig",
+		"Draft ID":               "Entwurfs-ID",
+		"Last modified":          "Zuletzt geändert",
+		"BCC":                    "BCC",
+		// Inbox rules
+		"from %s":                    "von %s",
+		"sender contains %s":         "Absender enthält %s",
+		"subject contains %s":        "Betreff enthält %s",
+		"body contains %s":           "Inhalt enthält %s",
+		"has attachments":            "hat Anhänge",
+		"move to folder %s":          "in Ordner %s verschieben",
+		"copy to folder %s":          "in Ordner %s kopieren",
+		"assign categories %s":       "Kategorien %s zuweisen",
+		"mark as read":               "als gelesen markieren",
+		"delete":                     "löschen",
+		"forward to %s":              "weiterleiten an %s",
+		"redirect to %s":             "umleiten an %s",
+		"stop processing more rules": "keine weiteren Regeln verarbeiten",
+		" or ":                       " oder ",
+		// Threads
+		"Messages in thread": "Nachrichten in der Unterhaltung",
+		"--- Message %d ---": "--- Nachricht %d ---",
+		"From":               "Von",
+		"Date":               "Datum",
+		"The thread does not contain any messages": "Die Unterhaltung enthält keine Nachrichten",
+		// Calendar
+		"Owner":                                  "Besitzer",
+		"Owner Type":                             "Besitzertyp",
+		"Start":                                  "Beginn",
+		"End":                                    "Ende",
+		"In calendar":                            "Im Kalender",
+		"Location":                               "Ort",
+		"Is All Day":                             "Ganztägig",
+		"Is Recurring":                           "Serientermin",
+		"Is Cancelled":                           "Abgesagt",
+		"Is Online Meeting":                      "Onlinebesprechung",
+		"Online Meeting Join URL":                "Link zur Onlinebesprechung",
+		"Response Status":                        "Antwortstatus",
+		"Attendees":                              "Teilnehmer",
+		"Response":                               "Antwort",
+		"End Body":                               "Ende Inhalt",
+		"File Attachment":                        "Dateianhang",
+		"Size":                                   "Größe",
+		"bytes":                                  "Bytes",
+		"Content Type":                           "Inhaltstyp",
+		"Item Attachment":                        "Elementanhang",
+		"You can open the event using this link": "Der Termin kann über diesen Link geöffnet werden",
+		"No events found":                        "Keine Termine gefunden",
+		// Contacts
+		"Email addresses":   "E-Mail-Adressen",
+		"Business phone":    "Telefon geschäftlich",
+		"Mobile phone":      "Mobiltelefon",
+		"Home phone":        "Telefon privat",
+		"Company":           "Firma",
+		"Job title":         "Position",
+		"Department":        "Abteilung",
+		"Business address":  "Geschäftsadresse",
+		"Notes":             "Notizen",
+		"No contacts found": "Keine Kontakte gefunden",
+		// To Do
+		"Importance":     "Wichtigkeit",
+		"Due date":       "Fälligkeitsdatum",
+		"Reminder":       "Erinnerung",
+		"Completed":      "Erledigt",
+		"Linked items":   "Verknüpfte Elemente",
+		"No tasks found": "Keine Aufgaben gefunden",
+		// OneDrive
+		"Drive ID":       "Laufwerks-ID",
+		"Folder":         "Ordner",
+		"No files found": "Keine Dateien gefunden",
+		// Excel
+		"Workbook ID":                        "Arbeitsmappen-ID",
+		"Table ID":                           "Tabellen-ID",
+		"Column added successfully":          "Spalte erfolgreich hinzugefügt",
+		"Row added successfully":             "Zeile erfolgreich hinzugefügt",
+		"Worksheet created with ID":          "Arbeitsblatt erstellt mit ID",
+		"Workbook created with ID":           "Arbeitsmappe erstellt mit ID",
+		"Workbook saved to the workspace as": "Arbeitsmappe im Arbeitsbereich gespeichert als",
+		"Position":                           "Position",
+		"Worksheet deleted successfully":     "Arbeitsblatt erfolgreich gelöscht",
+		"Worksheet updated successfully":     "Arbeitsblatt erfolgreich aktualisiert",
+		"Range formatted successfully":       "Bereich erfolgreich formatiert",
+		// Result formatter
+		"no relevant documents found":      "keine relevanten Dokumente gefunden",
+		"No changes":                       "Keine Änderungen",
+		"added":                            "hinzugefügt",
+		"changed":                          "geändert",
+		"removed":                          "entfernt",
+		"%d added, %d changed, %d removed": "%d hinzugefügt, %d geändert, %d entfernt",
+		" (rows matched by %s)":            " (Zeilen zugeordnet über %s)",
+		"Change":                           "Änderung",
+		"Field":                            "Feld",
+		"Before":                           "Vorher",
+		"After":                            "Nachher",
+		"(empty)":                          "(leer)",
+		"(not set)":                        "(nicht gesetzt)",
+		"(removed)":                        "(entfernt)",
+	},
+	"fr": {
+		// Mail
+		"Name":                   "Nom",
+		"ID":                     "ID",
+		"Parent folder ID":       "ID du dossier parent",
+		"Unread item count":      "Nombre d'éléments non lus",
+		"Total item count":       "Nombre total d'éléments",
+		"Subject":                "Objet",
+		"Message ID":             "ID du message",
+		"Sender":                 "Expéditeur",
+		"email address":          "adresse e-mail",
+		"Received":               "Reçu",
+		"Created":                "Créé",
+		"Is unread":              "Non lu",
+		"Link":                   "Lien",
+		"To":                     "À",
+		"CC":                     "Cc",
+		"Has attachments":        "Pièces jointes",
+		"Body":                   "Contenu",
+		"Body preview":           "Aperçu",
+		"no messages found":      "aucun message trouvé",
+		"Type":                   "Type",
+		"Is inline":              "Intégré",
+		"Follow-up flag":         "Indicateur de suivi",
+		"Categories":             "Catégories",
+		"Enabled":                "Activée",
+		"Conditions":             "Conditions",
+		"Actions":                "Actions",
+		"Status":                 "Statut",
+		"External audience":      "Destinataires externes",
+		"Internal reply message": "Réponse interne",
+		"External reply message": "Réponse externe",
+		"due":                    "échéance",
+		"Draft ID":               "ID du brouillon",
+		"Last modified":          "Dernière modification",
+		"BCC":                    "CCI",
+		// Inbox rules
+		"from %s":                    "de %s",
+		"sender contains %s":         "l'expéditeur contient %s",
+		"subject contains %s":        "l'objet contient %s",
+		"body contains %s":           "le contenu contient %s",
+		"has attachments":            "a des pièces jointes",
+		"move to folder %s":          "déplacer vers le dossier %s",
+		"copy to folder %s":          "copier dans le dossier %s",
+		"assign categories %s":       "attribuer les catégories %s",
+		"mark as read":               "marquer comme lu",
+		"delete":                     "supprimer",
+		"forward to %s":              "transférer à %s",
+		"redirect to %s":             "rediriger vers %s",
+		"stop processing more rules": "arrêter le traitement des autres règles",
+		" or ":                       " ou ",
+		// Threads
+		"Messages in thread": "Messages dans la conversation",
+		"--- Message %d ---": "--- Message %d ---",
+		"From":               "De",
+		"Date":               "Date",
+		"The thread does not contain any messages": "La conversation ne contient aucun message",
+		// Calendar
+		"Owner":                                  "Propriétaire",
+		"Owner Type":                             "Type de propriétaire",
+		"Start":                                  "Début",
+		"End":                                    "Fin",
+		"In calendar":                            "Dans le calendrier",
+		"Location":                               "Lieu",
+		"Is All Day":                             "Toute la journée",
+		"Is Recurring":                           "Périodique",
+		"Is Cancelled":                           "Annulé",
+		"Is Online Meeting":                      "Réunion en ligne",
+		"Online Meeting Join URL":                "Lien de la réunion en ligne",
+		"Response Status":                        "Statut de la réponse",
+		"Attendees":                              "Participants",
+		"Response":                               "Réponse",
+		"End Body":                               "Fin du contenu",
+		"File Attachment":                        "Pièce jointe",
+		"Size":                                   "Taille",
+		"bytes":                                  "octets",
+		"Content Type":                           "Type de contenu",
+		"Item Attachment":                        "Élément joint",
+		"You can open the event using this link": "L'événement peut être ouvert avec ce lien",
+		"No events found":                        "Aucun événement trouvé",
+		// Contacts
+		"Email addresses":   "Adresses e-mail",
+		"Business phone":    "Téléphone professionnel",
+		"Mobile phone":      "Téléphone mobile",
+		"Home phone":        "Téléphone personnel",
+		"Company":           "Société",
+		"Job title":         "Fonction",
+		"Department":        "Service",
+		"Business address":  "Adresse professionnelle",
+		"Notes":             "Notes",
+		"No contacts found": "Aucun contact trouvé",
+		// To Do
+		"Importance":     "Importance",
+		"Due date":       "Date d'échéance",
+		"Reminder":       "Rappel",
+		"Completed":      "Terminée",
+		"Linked items":   "Éléments liés",
+		"No tasks found": "Aucune tâche trouvée",
+		// OneDrive
+		"Drive ID":       "ID du lecteur",
+		"Folder":         "Dossier",
+		"No files found": "Aucun fichier trouvé",
+		// Excel
+		"Workbook ID":                        "ID du classeur",
+		"Table ID":                           "ID du tableau",
+		"Column added successfully":          "Colonne ajoutée avec succès",
+		"Row added successfully":             "Ligne ajoutée avec succès",
+		"Worksheet created with ID":          "Feuille de calcul créée avec l'ID",
+		"Workbook created with ID":           "Classeur créé avec l'ID",
+		"Workbook saved to the workspace as": "Classeur enregistré dans l'espace de travail sous",
+		"Position":                           "Position",
+		"Worksheet deleted successfully":     "Feuille de calcul supprimée avec succès",
+		"Worksheet updated successfully":     "Feuille de calcul mise à jour avec succès",
+		"Range formatted successfully":       "Plage mise en forme avec succès",
+		// Result formatter
+		"no relevant documents found":      "aucun document pertinent trouvé",
+		"No changes":                       "Aucune modification",
+		"added":                            "ajouté",
+		"changed":                          "modifié",
+		"removed":                          "supprimé",
+		"%d added, %d changed, %d removed": "%d ajouté(s), %d modifié(s), %d supprimé(s)",
+		" (rows matched by %s)":            " (lignes associées par %s)",
+		"Change":                           "Modification",
+		"Field":                            "Champ",
+		"Before":                           "Avant",
+		"After":                            "Après",
+		"(empty)":                          "(vide)",
+		"(not set)":                        "(non défini)",
+		"(removed)":                        "(supprimé)",
+	},
+	"es": {
+		// Mail
+		"Name":                   "Nombre",
+		"ID":                     "ID",
+		"Parent folder ID":       "ID de la carpeta principal",
+		"Unread item count":      "Elementos no leídos",
+		"Total item count":       "Total de elementos",
+		"Subject":                "Asunto",
+		"Message ID":             "ID del mensaje",
+		"Sender":                 "Remitente",
+		"email address":          "correo electrónico",
+		"Received":               "Recibido",
+		"Created":                "Creado",
+		"Is unread":              "No leído",
+		"Link":                   "Enlace",
+		"To":                     "Para",
+		"CC":                     "CC",
+		"Has attachments":        "Tiene adjuntos",
+		"Body":                   "Cuerpo",
+		"Body preview":           "Vista previa",
+		"no messages found":      "no se encontraron mensajes",
+		"Type":                   "Tipo",
+		"Is inline":              "En línea",
+		"Follow-up flag":         "Marca de seguimiento",
+		"Categories":             "Categorías",
+		"Enabled":                "Habilitada",
+		"Conditions":             "Condiciones",
+		"Actions":                "Acciones",
+		"Status":                 "Estado",
+		"External audience":      "Destinatarios externos",
+		"Internal reply message": "Respuesta interna",
+		"External reply message": "Respuesta externa",
+		"due":                    "vence",
+		"Draft ID":               "ID del borrador",
+		"Last modified":          "Última modificación",
+		"BCC":                    "CCO",
+		// Inbox rules
+		"from %s":                    "de %s",
+		"sender contains %s":         "el remitente contiene %s",
+		"subject contains %s":        "el asunto contiene %s",
+		"body contains %s":           "el cuerpo contiene %s",
+		"has attachments":            "tiene adjuntos",
+		"move to folder %s":          "mover a la carpeta %s",
+		"copy to folder %s":          "copiar en la carpeta %s",
+		"assign categories %s":       "asignar las categorías %s",
+		"mark as read":               "marcar como leído",
+		"delete":                     "eliminar",
+		"forward to %s":              "reenviar a %s",
+		"redirect to %s":             "redirigir a %s",
+		"stop processing more rules": "detener el procesamiento de más reglas",
+		" or ":                       " o ",
+		// Threads
+		"Messages in thread": "Mensajes en la conversación",
+		"--- Message %d ---": "--- Mensaje %d ---",
+		"From":               "De",
+		"Date":               "Fecha",
+		"The thread does not contain any messages": "La conversación no contiene mensajes",
+		// Calendar
+		"Owner":                                  "Propietario",
+		"Owner Type":                             "Tipo de propietario",
+		"Start":                                  "Inicio",
+		"End":                                    "Fin",
+		"In calendar":                            "En el calendario",
+		"Location":                               "Ubicación",
+		"Is All Day":                             "Todo el día",
+		"Is Recurring":                           "Periódico",
+		"Is Cancelled":                           "Cancelado",
+		"Is Online Meeting":                      "Reunión en línea",
+		"Online Meeting Join URL":                "Enlace de la reunión en línea",
+		"Response Status":                        "Estado de la respuesta",
+		"Attendees":                              "Asistentes",
+		"Response":                               "Respuesta",
+		"End Body":                               "Fin del cuerpo",
+		"File Attachment":                        "Archivo adjunto",
+		"Size":                                   "Tamaño",
+		"bytes":                                  "bytes",
+		"Content Type":                           "Tipo de contenido",
+		"Item Attachment":                        "Elemento adjunto",
+		"You can open the event using this link": "El evento se puede abrir con este enlace",
+		"No events found":                        "No se encontraron eventos",
+		// Contacts
+		"Email addresses":   "Direcciones de correo electrónico",
+		"Business phone":    "Teléfono del trabajo",
+		"Mobile phone":      "Teléfono móvil",
+		"Home phone":        "Teléfono particular",
+		"Company":           "Empresa",
+		"Job title":         "Puesto",
+		"Department":        "Departamento",
+		"Business address":  "Dirección del trabajo",
+		"Notes":             "Notas",
+		"No contacts found": "No se encontraron contactos",
+		// To Do
+		"Importance":     "Importancia",
+		"Due date":       "Fecha de vencimiento",
+		"Reminder":       "Recordatorio",
+		"Completed":      "Completada",
+		"Linked items":   "Elementos vinculados",
+		"No tasks found": "No se encontraron tareas",
+		// OneDrive
+		"Drive ID":       "ID de la unidad",
+		"Folder":         "Carpeta",
+		"No files found": "No se encontraron archivos",
+		// Excel
+		"Workbook ID":                        "ID del libro",
+		"Table ID":                           "ID de la tabla",
+		"Column added successfully":          "Columna añadida correctamente",
+		"Row added successfully":             "Fila añadida correctamente",
+		"Worksheet created with ID":          "Hoja de cálculo creada con ID",
+		"Workbook created with ID":           "Libro creado con ID",
+		"Workbook saved to the workspace as": "Libro guardado en el espacio de trabajo como",
+		"Position":                           "Posición",
+		"Worksheet deleted successfully":     "Hoja de cálculo eliminada correctamente",
+		"Worksheet updated successfully":     "Hoja de cálculo actualizada correctamente",
+		"Range formatted successfully":       "Rango formateado correctamente",
+		// Result formatter
+		"no relevant documents found":      "no se encontraron documentos relevantes",
+		"No changes":                       "Sin cambios",
+		"added":                            "añadido",
+		"changed":                          "modificado",
+		"removed":                          "eliminado",
+		"%d added, %d changed, %d removed": "%d añadidos, %d modificados, %d eliminados",
+		" (rows matched by %s)":            " (filas emparejadas por %s)",
+		"Change":                           "Cambio",
+		"Field":                            "Campo",
+		"Before":                           "Antes",
+		"After":                            "Después",
+		"(empty)":                          "(vacío)",
+		"(not set)":                        "(sin definir)",
+		"(removed)":                        "(eliminado)",
+	},
+	"it": {
+		// Mail
+		"Name":                   "Nome",
+		"ID":                     "ID",
+		"Parent folder ID":       "ID della cartella padre",
+		"Unread item count":      "Elementi non letti",
+		"Total item count":       "Totale elementi",
+		"Subject":                "Oggetto",
+		"Message ID":             "ID messaggio",
+		"Sender":                 "Mittente",
+		"email address":          "indirizzo e-mail",
+		"Received":               "Ricevuto",
+		"Created":                "Creato",
+		"Is unread":              "Non letto",
+		"Link":                   "Link",
+		"To":                     "A",
+		"CC":                     "Cc",
+		"Has attachments":        "Ha allegati",
+		"Body":                   "Corpo",
+		"Body preview":           "Anteprima",
+		"no messages found":      "nessun messaggio trovato",
+		"Type":                   "Tipo",
+		"Is inline":              "In linea",
+		"Follow-up flag":         "Contrassegno di completamento",
+		"Categories":             "Categorie",
+		"Enabled":                "Attiva",
+		"Conditions":             "Condizioni",
+		"Actions":                "Azioni",
+		"Status":                 "Stato",
+		"External audience":      "Destinatari esterni",
+		"Internal reply message": "Risposta interna",
+		"External reply message": "Risposta esterna",
+		"due":                    "scadenza",
+		"Draft ID":               "ID bozza",
+		"Last modified":          "Ultima modifica",
+		"BCC":                    "Ccn",
+		// Inbox rules
+		"from %s":                    "da %s",
+		"sender contains %s":         "il mittente contiene %s",
+		"subject contains %s":        "l'oggetto contiene %s",
+		"body contains %s":           "il corpo contiene %s",
+		"has attachments":            "ha allegati",
+		"move to folder %s":          "sposta nella cartella %s",
+		"copy to folder %s":          "copia nella cartella %s",
+		"assign categories %s":       "assegna le categorie %s",
+		"mark as read":               "segna come letto",
+		"delete":                     "elimina",
+		"forward to %s":              "inoltra a %s",
+		"redirect to %s":             "reindirizza a %s",
+		"stop processing more rules": "interrompi l'elaborazione di altre regole",
+		" or ":                       " o ",
+		// Threads
+		"Messages in thread": "Messaggi nella conversazione",
+		"--- Message %d ---": "--- Messaggio %d ---",
+		"From":               "Da",
+		"Date":               "Data",
+		"The thread does not contain any messages": "La conversazione non contiene messaggi",
+		// Calendar
+		"Owner":                                  "Proprietario",
+		"Owner Type":                             "Tipo di proprietario",
+		"Start":                                  "Inizio",
+		"End":                                    "Fine",
+		"In calendar":                            "Nel calendario",
+		"Location":                               "Luogo",
+		"Is All Day":                             "Tutto il giorno",
+		"Is Recurring":                           "Ricorrente",
+		"Is Cancelled":                           "Annullato",
+		"Is Online Meeting":                      "Riunione online",
+		"Online Meeting Join URL":                "Link della riunione online",
+		"Response Status":                        "Stato della risposta",
+		"Attendees":                              "Partecipanti",
+		"Response":                               "Risposta",
+		"End Body":                               "Fine del corpo",
+		"File Attachment":                        "File allegato",
+		"Size":                                   "Dimensione",
+		"bytes":                                  "byte",
+		"Content Type":                           "Tipo di contenuto",
+		"Item Attachment":                        "Elemento allegato",
+		"You can open the event using this link": "L'evento può essere aperto con questo link",
+		"No events found":                        "Nessun evento trovato",
+		// Contacts
+		"Email addresses":   "Indirizzi e-mail",
+		"Business phone":    "Telefono ufficio",
+		"Mobile phone":      "Cellulare",
+		"Home phone":        "Telefono abitazione",
+		"Company":           "Società",
+		"Job title":         "Posizione",
+		"Department":        "Reparto",
+		"Business address":  "Indirizzo ufficio",
+		"Notes":             "Note",
+		"No contacts found": "Nessun contatto trovato",
+		// To Do
+		"Importance":     "Importanza",
+		"Due date":       "Data di scadenza",
+		"Reminder":       "Promemoria",
+		"Completed":      "Completata",
+		"Linked items":   "Elementi collegati",
+		"No tasks found": "Nessuna attività trovata",
+		// OneDrive
+		"Drive ID":       "ID unità",
+		"Folder":         "Cartella",
+		"No files found": "Nessun file trovato",
+		// Excel
+		"Workbook ID":                        "ID cartella di lavoro",
+		"Table ID":                           "ID tabella",
+		"Column added successfully":          "Colonna aggiunta correttamente",
+		"Row added successfully":             "Riga aggiunta correttamente",
+		"Worksheet created with ID":          "Foglio di lavoro creato con ID",
+		"Workbook created with ID":           "Cartella di lavoro creata con ID",
+		"Workbook saved to the workspace as": "Cartella di lavoro salvata nell'area di lavoro come",
+		"Position":                           "Posizione",
+		"Worksheet deleted successfully":     "Foglio di lavoro eliminato correttamente",
+		"Worksheet updated successfully":     "Foglio di lavoro aggiornato correttamente",
+		"Range formatted successfully":       "Intervallo formattato correttamente",
+		// Result formatter
+		"no relevant documents found":      "nessun documento pertinente trovato",
+		"No changes":                       "Nessuna modifica",
+		"added":                            "aggiunto",
+		"changed":                          "modificato",
+		"removed":                          "rimosso",
+		"%d added, %d changed, %d removed": "%d aggiunti, %d modificati, %d rimossi",
+		" (rows matched by %s)":            " (righe abbinate tramite %s)",
+		"Change":                           "Modifica",
+		"Field":                            "Campo",
+		"Before":                           "Prima",
+		"After":                            "Dopo",
+		"(empty)":                          "(vuoto)",
+		"(not set)":                        "(non impostato)",
+		"(removed)":                        "(rimosso)",
+	},
+	"nl": {
+		// Mail
+		"Name":                   "Naam",
+		"ID":                     "ID",
+		"Parent folder ID":       "ID van bovenliggende map",
+		"Unread item count":      "Aantal ongelezen items",
+		"Total item count":       "Totaal aantal items",
+		"Subject":                "Onderwerp",
+		"Message ID":             "Bericht-ID",
+		"Sender":                 "Afzender",
+		"email address":          "e-mailadres",
+		"Received":               "Ontvangen",
+		"Created":                "Gemaakt",
+		"Is unread":              "Ongelezen",
+		"Link":                   "Koppeling",
+		"To":                     "Aan",
+		"CC":                     "CC",
+		"Has attachments":        "Heeft bijlagen",
+		"Body":                   "Inhoud",
+		"Body preview":           "Voorbeeld",
+		"no messages found":      "geen berichten gevonden",
+		"Type":                   "Type",
+		"Is inline":              "Inline",
+		"Follow-up flag":         "Opvolgvlag",
+		"Categories":             "Categorieën",
+		"Enabled":                "Ingeschakeld",
+		"Conditions":             "Voorwaarden",
+		"Actions":                "Acties",
+		"Status":                 "Status",
+		"External audience":      "Externe ontvangers",
+		"Internal reply message": "Intern antwoord",
+		"External reply message": "Extern antwoord",
+		"due":                    "vervalt",
+		"Draft ID":               "Concept-ID",
+		"Last modified":          "Laatst gewijzigd",
+		"BCC":                    "BCC",
+		// Inbox rules
+		"from %s":                    "van %s",
+		"sender contains %s":         "afzender bevat %s",
+		"subject contains %s":        "onderwerp bevat %s",
+		"body contains %s":           "inhoud bevat %s",
+		"has attachments":            "heeft bijlagen",
+		"move to folder %s":          "verplaatsen naar map %s",
+		"copy to folder %s":          "kopiëren naar map %s",
+		"assign categories %s":       "categorieën %s toewijzen",
+		"mark as read":               "markeren als gelezen",
+		"delete":                     "verwijderen",
+		"forward to %s":              "doorsturen naar %s",
+		"redirect to %s":             "omleiden naar %s",
+		"stop processing more rules": "geen andere regels meer verwerken",
+		" or ":                       " of ",
+		// Threads
+		"Messages in thread": "Berichten in gesprek",
+		"--- Message %d ---": "--- Bericht %d ---",
+		"From":               "Van",
+		"Date":               "Datum",
+		"The thread does not contain any messages": "Het gesprek bevat geen berichten",
+		// Calendar
+		"Owner":                                  "Eigenaar",
+		"Owner Type":                             "Type eigenaar",
+		"Start":                                  "Begin",
+		"End":                                    "Einde",
+		"In calendar":                            "In agenda",
+		"Location":                               "Locatie",
+		"Is All Day":                             "Hele dag",
+		"Is Recurring":                           "Terugkerend",
+		"Is Cancelled":                           "Geannuleerd",
+		"Is Online Meeting":                      "Onlinevergadering",
+		"Online Meeting Join URL":                "Link naar onlinevergadering",
+		"Response Status":                        "Antwoordstatus",
+		"Attendees":                              "Deelnemers",
+		"Response":                               "Antwoord",
+		"End Body":                               "Einde inhoud",
+		"File Attachment":                        "Bijlage",
+		"Size":                                   "Grootte",
+		"bytes":                                  "bytes",
+		"Content Type":                           "Inhoudstype",
+		"Item Attachment":                        "Itembijlage",
+		"You can open the event using this link": "Het evenement kan met deze koppeling worden geopend",
+		"No events found":                        "Geen afspraken gevonden",
+		// Contacts
+		"Email addresses":   "E-mailadressen",
+		"Business phone":    "Telefoon werk",
+		"Mobile phone":      "Mobiele telefoon",
+		"Home phone":        "Telefoon thuis",
+		"Company":           "Bedrijf",
+		"Job title":         "Functie",
+		"Department":        "Afdeling",
+		"Business address":  "Werkadres",
+		"Notes":             "Notities",
+		"No contacts found": "Geen contactpersonen gevonden",
+		// To Do
+		"Importance":     "Urgentie",
+		"Due date":       "Vervaldatum",
+		"Reminder":       "Herinnering",
+		"Completed":      "Voltooid",
+		"Linked items":   "Gekoppelde items",
+		"No tasks found": "Geen taken gevonden",
+		// OneDrive
+		"Drive ID":       "Station-ID",
+		"Folder":         "Map",
+		"No files found": "Geen bestanden gevonden",
+		// Excel
+		"Workbook ID":                        "Werkmap-ID",
+		"Table ID":                           "Tabel-ID",
+		"Column added successfully":          "Kolom toegevoegd",
+		"Row added successfully":             "Rij toegevoegd",
+		"Worksheet created with ID":          "Werkblad gemaakt met ID",
+		"Workbook created with ID":           "Werkmap gemaakt met ID",
+		"Workbook saved to the workspace as": "Werkmap opgeslagen in de werkruimte als",
+		"Position":                           "Positie",
+		"Worksheet deleted successfully":     "Werkblad succesvol verwijderd",
+		"Worksheet updated successfully":     "Werkblad succesvol bijgewerkt",
+		"Range formatted successfully":       "Bereik succesvol opgemaakt",
+		// Result formatter
+		"no relevant documents found":      "geen relevante documenten gevonden",
+		"No changes":                       "Geen wijzigingen",
+		"added":                            "toegevoegd",
+		"changed":                          "gewijzigd",
+		"removed":                          "verwijderd",
+		"%d added, %d changed, %d removed": "%d toegevoegd, %d gewijzigd, %d verwijderd",
+		" (rows matched by %s)":            " (rijen gekoppeld op %s)",
+		"Change":                           "Wijziging",
+		"Field":                            "Veld",
+		"Before":                           "Voor",
+		"After":                            "Na",
+		"(empty)":                          "(leeg)",
+		"(not set)":                        "(niet ingesteld)",
+		"(removed)":                        "(verwijderd)",
+	},
+	"pt": {
+		// Mail
+		"Name":                   "Nome",
+		"ID":                     "ID",
+		"Parent folder ID":       "ID da pasta pai",
+		"Unread item count":      "Itens não lidos",
+		"Total item count":       "Total de itens",
+		"Subject":                "Assunto",
+		"Message ID":             "ID da mensagem",
+		"Sender":                 "Remetente",
+		"email address":          "endereço de e-mail",
+		"Received":               "Recebido",
+		"Created":                "Criado",
+		"Is unread":              "Não lido",
+		"Link":                   "Link",
+		"To":                     "Para",
+		"CC":                     "Cc",
+		"Has attachments":        "Tem anexos",
+		"Body":                   "Corpo",
+		"Body preview":           "Pré-visualização",
+		"no messages found":      "nenhuma mensagem encontrada",
+		"Type":                   "Tipo",
+		"Is inline":              "Embutido",
+		"Follow-up flag":         "Sinalizador de acompanhamento",
+		"Categories":             "Categorias",
+		"Enabled":                "Ativada",
+		"Conditions":             "Condições",
+		"Actions":                "Ações",
+		"Status":                 "Estado",
+		"External audience":      "Destinatários externos",
+		"Internal reply message": "Resposta interna",
+		"External reply message": "Resposta externa",
+		"due":                    "vence",
+		"Draft ID":               "ID do rascunho",
+		"Last modified":          "Última modificação",
+		"BCC":                    "Cco",
+		// Inbox rules
+		"from %s":                    "de %s",
+		"sender contains %s":         "o remetente contém %s",
+		"subject contains %s":        "o assunto contém %s",
+		"body contains %s":           "o corpo contém %s",
+		"has attachments":            "tem anexos",
+		"move to folder %s":          "mover para a pasta %s",
+		"copy to folder %s":          "copiar para a pasta %s",
+		"assign categories %s":       "atribuir as categorias %s",
+		"mark as read":               "marcar como lido",
+		"delete":                     "excluir",
+		"forward to %s":              "encaminhar para %s",
+		"redirect to %s":             "redirecionar para %s",
+		"stop processing more rules": "parar de processar mais regras",
+		" or ":                       " ou ",
+		// Threads
+		"Messages in thread": "Mensagens na conversa",
+		"--- Message %d ---": "--- Mensagem %d ---",
+		"From":               "De",
+		"Date":               "Data",
+		"The thread does not contain any messages": "A conversa não contém mensagens",
+		// Calendar
+		"Owner":                                  "Proprietário",
+		"Owner Type":                             "Tipo de proprietário",
+		"Start":                                  "Início",
+		"End":                                    "Fim",
+		"In calendar":                            "No calendário",
+		"Location":                               "Local",
+		"Is All Day":                             "Dia inteiro",
+		"Is Recurring":                           "Recorrente",
+		"Is Cancelled":                           "Cancelado",
+		"Is Online Meeting":                      "Reunião online",
+		"Online Meeting Join URL":                "Link da reunião online",
+		"Response Status":                        "Estado da resposta",
+		"Attendees":                              "Participantes",
+		"Response":                               "Resposta",
+		"End Body":                               "Fim do corpo",
+		"File Attachment":                        "Anexo de arquivo",
+		"Size":                                   "Tamanho",
+		"bytes":                                  "bytes",
+		"Content Type":                           "Tipo de conteúdo",
+		"Item Attachment":                        "Item anexado",
+		"You can open the event using this link": "O evento pode ser aberto com este link",
+		"No events found":                        "Nenhum evento encontrado",
+		// Contacts
+		"Email addresses":   "Endereços de e-mail",
+		"Business phone":    "Telefone comercial",
+		"Mobile phone":      "Celular",
+		"Home phone":        "Telefone residencial",
+		"Company":           "Empresa",
+		"Job title":         "Cargo",
+		"Department":        "Departamento",
+		"Business address":  "Endereço comercial",
+		"Notes":             "Observações",
+		"No contacts found": "Nenhum contato encontrado",
+		// To Do
+		"Importance":     "Importância",
+		"Due date":       "Data de conclusão",
+		"Reminder":       "Lembrete",
+		"Completed":      "Concluída",
+		"Linked items":   "Itens vinculados",
+		"No tasks found": "Nenhuma tarefa encontrada",
+		// OneDrive
+		"Drive ID":       "ID da unidade",
+		"Folder":         "Pasta",
+		"No files found": "Nenhum arquivo encontrado",
+		// Excel
+		"Workbook ID":                        "ID da pasta de trabalho",
+		"Table ID":                           "ID da tabela",
+		"Column added successfully":          "Coluna adicionada com sucesso",
+		"Row added successfully":             "Linha adicionada com sucesso",
+		"Worksheet created with ID":          "Planilha criada com ID",
+		"Workbook created with ID":           "Pasta de trabalho criada com ID",
+		"Workbook saved to the workspace as": "Pasta de trabalho salva no espaço de trabalho como",
+		"Position":                           "Posição",
+		"Worksheet deleted successfully":     "Planilha excluída com sucesso",
+		"Worksheet updated successfully":     "Planilha atualizada com sucesso",
+		"Range formatted successfully":       "Intervalo formatado com sucesso",
+		// Result formatter
+		"no relevant documents found":      "nenhum documento relevante encontrado",
+		"No changes":                       "Sem alterações",
+		"added":                            "adicionado",
+		"changed":                          "alterado",
+		"removed":                          "removido",
+		"%d added, %d changed, %d removed": "%d adicionados, %d alterados, %d removidos",
+		" (rows matched by %s)":            " (linhas associadas por %s)",
+		"Change":                           "Alteração",
+		"Field":                            "Campo",
+		"Before":                           "Antes",
+		"After":                            "Depois",
+		"(empty)":                          "(vazio)",
+		"(not set)":                        "(não definido)",
+		"(removed)":                        "(removido)",
+	},
+	"ja": {
+		// Mail
+		"Name":                   "名前",
+		"ID":                     "ID",
+		"Parent folder ID":       "親フォルダー ID",
+		"Unread item count":      "未読アイテム数",
+		"Total item count":       "アイテム総数",
+		"Subject":                "件名",
+		"Message ID":             "メッセージ ID",
+		"Sender":                 "差出人",
+		"email address":          "メールアドレス",
+		"Received":               "受信日時",
+		"Created":                "作成日時",
+		"Is unread":              "未読",
+		"Link":                   "リンク",
+		"To":                     "宛先",
+		"CC":                     "CC",
+		"Has attachments":        "添付ファイルあり",
+		"Body":                   "本文",
+		"Body preview":           "本文のプレビュー",
+		"no messages found":      "メッセージが見つかりません",
+		"Type":                   "種類",
+		"Is inline":              "インライン",
+		"Follow-up flag":         "フォローアップ フラグ",
+		"Categories":             "分類",
+		"Enabled":                "有効",
+		"Conditions":             "条件",
+		"Actions":                "アクション",
+		"Status":                 "状態",
+		"External audience":      "外部の受信者",
+		"Internal reply message": "組織内への返信",
+		"External reply message": "組織外への返信",
+		"due":                    "期限",
+		"Draft ID":               "下書き ID",
+		"Last modified":          "最終更新日時",
+		"BCC":                    "BCC",
+		// Inbox rules
+		"from %s":                    "差出人が %s",
+		"sender contains %s":         "差出人に %s を含む",
+		"subject contains %s":        "件名に %s を含む",
+		"body contains %s":           "本文に %s を含む",
+		"has attachments":            "添付ファイルあり",
+		"move to folder %s":          "フォルダー %s に移動",
+		"copy to folder %s":          "フォルダー %s にコピー",
+		"assign categories %s":       "分類 %s を割り当て",
+		"mark as read":               "開封済みにする",
+		"delete":                     "削除",
+		"forward to %s":              "%s に転送",
+		"redirect to %s":             "%s にリダイレクト",
+		"stop processing more rules": "以降のルールを処理しない",
+		" or ":                       " または ",
+		// Threads
+		"Messages in thread": "スレッド内のメッセージ",
+		"--- Message %d ---": "--- メッセージ %d ---",
+		"From":               "差出人",
+		"Date":               "日時",
+		"The thread does not contain any messages": "スレッドにメッセージがありません",
+		// Calendar
+		"Owner":                                  "所有者",
+		"Owner Type":                             "所有者の種類",
+		"Start":                                  "開始",
+		"End":                                    "終了",
+		"In calendar":                            "カレンダー",
+		"Location":                               "場所",
+		"Is All Day":                             "終日",
+		"Is Recurring":                           "定期的な予定",
+		"Is Cancelled":                           "キャンセル済み",
+		"Is Online Meeting":                      "オンライン会議",
+		"Online Meeting Join URL":                "オンライン会議の参加 URL",
+		"Response Status":                        "返信の状態",
+		"Attendees":                              "出席者",
+		"Response":                               "返信",
+		"End Body":                               "本文の終わり",
+		"File Attachment":                        "添付ファイル",
+		"Size":                                   "サイズ",
+		"bytes":                                  "バイト",
+		"Content Type":                           "コンテンツの種類",
+		"Item Attachment":                        "添付アイテム",
+		"You can open the event using this link": "このリンクから予定を開けます",
+		"No events found":                        "予定が見つかりません",
+		// Contacts
+		"Email addresses":   "メールアドレス",
+		"Business phone":    "勤務先電話",
+		"Mobile phone":      "携帯電話",
+		"Home phone":        "自宅電話",
+		"Company":           "会社",
+		"Job title":         "役職",
+		"Department":        "部署",
+		"Business address":  "勤務先住所",
+		"Notes":             "メモ",
+		"No contacts found": "連絡先が見つかりません",
+		// To Do
+		"Importance":     "重要度",
+		"Due date":       "期限",
+		"Reminder":       "アラーム",
+		"Completed":      "完了",
+		"Linked items":   "リンクされたアイテム",
+		"No tasks found": "タスクが見つかりません",
+		// OneDrive
+		"Drive ID":       "ドライブ ID",
+		"Folder":         "フォルダー",
+		"No files found": "ファイルが見つかりません",
+		// Excel
+		"Workbook ID":                        "ブック ID",
+		"Table ID":                           "テーブル ID",
+		"Column added successfully":          "列を追加しました",
+		"Row added successfully":             "行を追加しました",
+		"Worksheet created with ID":          "ワークシートを作成しました。ID",
+		"Workbook created with ID":           "ブックを作成しました。ID",
+		"Workbook saved to the workspace as": "ブックをワークスペースに保存しました。ファイル名",
+		"Position":                           "位置",
+		"Worksheet deleted successfully":     "ワークシートを削除しました",
+		"Worksheet updated successfully":     "ワークシートを更新しました",
+		"Range formatted successfully":       "範囲の書式を設定しました",
+		// Result formatter
+		"no relevant documents found":      "関連するドキュメントが見つかりません",
+		"No changes":                       "変更なし",
+		"added":                            "追加",
+		"changed":                          "変更",
+		"removed":                          "削除",
+		"%d added, %d changed, %d removed": "追加 %d 件、変更 %d 件、削除 %d 件",
+		" (rows matched by %s)":            "（%s で行を照合）",
+		"Change":                           "変更",
+		"Field":                            "フィールド",
+		"Before":                           "変更前",
+		"After":                            "変更後",
+		"(empty)":                          "（空）",
+		"(not set)":                        "（未設定）",
+		"(removed)":                        "（削除済み）",
+	},
+}
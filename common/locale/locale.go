@@ -0,0 +1,178 @@
+// Package locale localizes the fixed strings, dates and numbers printed by the tools.
+// The locale is read from the LOCALE environment variable, which is set by a tool's locale parameter, or from
+// OBOT_LOCALE for the whole deployment. Without a locale, the output is English with RFC 3339 dates, as before.
+// Every language in formats has a complete catalog of translations.
+package locale
+
+import (
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// EnvLocale is set by the locale parameter of a tool
+	EnvLocale = "LOCALE"
+	// EnvDefaultLocale is the locale of the deployment, e.g. de-DE
+	EnvDefaultLocale = "OBOT_LOCALE"
+)
+
+type format struct {
+	date, time       string
+	decimal, group   string
+	yes, no, unknown string
+}
+
+// formats are keyed by language, or by language and region where the region changes the format.
+var formats = map[string]format{
+	"en":    {date: "01/02/2006", time: "3:04 PM", decimal: ".", group: ",", yes: "yes", no: "no", unknown: "unknown"},
+	"en-gb": {date: "02/01/2006", time: "15:04", decimal: ".", group: ",", yes: "yes", no: "no", unknown: "unknown"},
+	"de":    {date: "02.01.2006", time: "15:04", decimal: ",", group: ".", yes: "ja", no: "nein", unknown: "unbekannt"},
+	"de-ch": {date: "02.01.2006", time: "15:04", decimal: ".", group: "’", yes: "ja", no: "nein", unknown: "unbekannt"},
+	"fr":    {date: "02/01/2006", time: "15:04", decimal: ",", group: " ", yes: "oui", no: "non", unknown: "inconnu"},
+	"es":    {date: "02/01/2006", time: "15:04", decimal: ",", group: ".", yes: "sí", no: "no", unknown: "desconocido"},
+	"it":    {date: "02/01/2006", time: "15:04", decimal: ",", group: ".", yes: "sì", no: "no", unknown: "sconosciuto"},
+	"nl":    {date: "02-01-2006", time: "15:04", decimal: ",", group: ".", yes: "ja", no: "nee", unknown: "onbekend"},
+	"pt":    {date: "02/01/2006", time: "15:04", decimal: ",", group: ".", yes: "sim", no: "não", unknown: "desconhecido"},
+	"ja":    {date: "2006/01/02", time: "15:04", decimal: ".", group: ",", yes: "はい", no: "いいえ", unknown: "不明"},
+}
+
+// Locale formats output for a language. The zero value keeps the original English output.
+type Locale struct {
+	lang   string
+	format *format
+}
+
+// FromEnv returns the locale configured in the environment.
+func FromEnv() Locale {
+	tag := os.Getenv(EnvLocale)
+	if tag == "" {
+		tag = os.Getenv(EnvDefaultLocale)
+	}
+	return Parse(tag)
+}
+
+// Parse returns the locale for a BCP 47 tag such as de, de-DE or pt_BR. Unknown languages fall back to English
+// strings with the default formats.
+func Parse(tag string) Locale {
+	tag = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+	// Drop encodings of POSIX locales, e.g. de_DE.UTF-8
+	tag, _, _ = strings.Cut(tag, ".")
+	if tag == "" || tag == "c" || tag == "posix" {
+		return Locale{}
+	}
+
+	lang, _, _ := strings.Cut(tag, "-")
+	l := Locale{lang: lang}
+	if f, ok := formats[tag]; ok {
+		l.format = &f
+	} else if f, ok := formats[lang]; ok {
+		l.format = &f
+	} else {
+		f := formats["en"]
+		l.format = &f
+	}
+	return l
+}
+
+// IsSet reports whether a locale is configured.
+func (l Locale) IsSet() bool {
+	return l.format != nil
+}
+
+// T translates a fixed English string, or returns it unchanged if there is no translation.
+func (l Locale) T(s string) string {
+	if translated, ok := catalog[l.lang][s]; ok {
+		return translated
+	}
+	return s
+}
+
+// DateTime formats a timestamp in the local date and time format, or as RFC 3339 without a locale.
+func (l Locale) DateTime(t time.Time) string {
+	if l.format == nil {
+		return t.Format(time.RFC3339)
+	}
+	return t.Format(l.format.date + " " + l.format.time + " MST")
+}
+
+// LocalDateTime formats a date and time without the time zone, e.g. if the zone is printed separately.
+func (l Locale) LocalDateTime(t time.Time) string {
+	if l.format == nil {
+		return t.Format("2006-01-02T15:04:05")
+	}
+	return t.Format(l.format.date + " " + l.format.time)
+}
+
+// Date formats the date in the local format, or as YYYY-MM-DD without a locale.
+func (l Locale) Date(t time.Time) string {
+	if l.format == nil {
+		return t.Format(time.DateOnly)
+	}
+	return t.Format(l.format.date)
+}
+
+// Int formats an integer with the local digit grouping.
+func (l Locale) Int(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	if l.format == nil {
+		return s
+	}
+	return l.group(s)
+}
+
+// Float formats a number with the local decimal separator and digit grouping.
+func (l Locale) Float(f float64) string {
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if l.format == nil || math.IsInf(f, 0) || math.IsNaN(f) {
+		return s
+	}
+	integer, fraction, hasFraction := strings.Cut(s, ".")
+	s = l.group(integer)
+	if hasFraction {
+		s += l.format.decimal + fraction
+	}
+	return s
+}
+
+// Bool formats a boolean as yes or no in the local language, or as true or false without a locale.
+func (l Locale) Bool(b bool) string {
+	switch {
+	case l.format == nil:
+		return strconv.FormatBool(b)
+	case b:
+		return l.format.yes
+	default:
+		return l.format.no
+	}
+}
+
+// Unknown is the placeholder for missing values.
+func (l Locale) Unknown() string {
+	if l.format == nil {
+		return "unknown"
+	}
+	return l.format.unknown
+}
+
+func (l Locale) group(digits string) string {
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	if len(digits) <= 3 {
+		return sign + digits
+	}
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, c := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteString(l.format.group)
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
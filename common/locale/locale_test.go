@@ -0,0 +1,83 @@
+package locale
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocale(t *testing.T) {
+	date := time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		tag, date, label string
+	}{
+		{"", "2024-03-05", "Workbook ID"},
+		{"en-US", "03/05/2024", "Workbook ID"},
+		{"en_GB.UTF-8", "05/03/2024", "Workbook ID"},
+		{"de-DE", "05.03.2024", "Arbeitsmappen-ID"},
+		{"fr", "05/03/2024", "ID du classeur"},
+		{"it-IT", "05/03/2024", "ID cartella di lavoro"},
+		{"ja_JP", "2024/03/05", "ブック ID"},
+		{"sv", "03/05/2024", "Workbook ID"},
+	}
+	for _, test := range tests {
+		l := Parse(test.tag)
+		if got := l.Date(date); got != test.date {
+			t.Errorf("Parse(%q).Date() = %q, want %q", test.tag, got, test.date)
+		}
+		if got := l.T("Workbook ID"); got != test.label {
+			t.Errorf("Parse(%q).T() = %q, want %q", test.tag, got, test.label)
+		}
+	}
+}
+
+func TestNumbers(t *testing.T) {
+	tests := []struct {
+		tag, i, f string
+	}{
+		{"", "1234567", "-1234.5"},
+		{"en", "1,234,567", "-1,234.5"},
+		{"de", "1.234.567", "-1.234,5"},
+		{"de-CH", "1’234’567", "-1’234.5"},
+		{"fr", "1\u202f234\u202f567", "-1\u202f234,5"},
+	}
+	for _, test := range tests {
+		l := Parse(test.tag)
+		if got := l.Int(1234567); got != test.i {
+			t.Errorf("Parse(%q).Int() = %q, want %q", test.tag, got, test.i)
+		}
+		if got := l.Float(-1234.5); got != test.f {
+			t.Errorf("Parse(%q).Float() = %q, want %q", test.tag, got, test.f)
+		}
+	}
+}
+
+// TestCatalogComplete makes sure that every language with formats translates the same strings, so that a
+// language is not advertised without its catalog.
+func TestCatalogComplete(t *testing.T) {
+	want := catalog["de"]
+	for tag := range formats {
+		lang, _, _ := strings.Cut(tag, "-")
+		if lang == "en" {
+			continue
+		}
+		translations, ok := catalog[lang]
+		if !ok {
+			t.Errorf("no catalog for %q", lang)
+			continue
+		}
+		for s := range want {
+			if _, ok := translations[s]; !ok {
+				t.Errorf("catalog %q does not translate %q", lang, s)
+			}
+		}
+		for s, translated := range translations {
+			if _, ok := want[s]; !ok {
+				t.Errorf("catalog %q translates unknown string %q", lang, s)
+			}
+			if strings.Count(translated, "%") != strings.Count(s, "%") {
+				t.Errorf("catalog %q: %q has different verbs than %q", lang, translated, s)
+			}
+		}
+	}
+}
@@ -2,8 +2,11 @@ module obot-platform/database
 
 go 1.23.3
 
+replace github.com/gptscript-ai/tools/common => ../common
+
 require (
-	github.com/gptscript-ai/go-gptscript v0.9.6-0.20241023195750-c09e0f56b39b
+	github.com/gptscript-ai/go-gptscript v0.9.6-0.20241106212914-ba040ce8f47b
+	github.com/gptscript-ai/tools/common v0.0.0-00010101000000-000000000000
 	github.com/ncruces/go-sqlite3 v0.20.3
 )
 
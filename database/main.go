@@ -8,6 +8,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"obot-platform/database/pkg/cmd"
 
@@ -32,8 +34,11 @@ func main() {
 	}
 	defer g.Close()
 
+	// The caller cancels a tool call by signaling the process, which needs to interrupt a running statement
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	var (
-		ctx             = context.Background()
 		dbFileName      = "acorn.db"
 		dbWorkspacePath = "/databases/" + dbFileName
 	)
@@ -73,20 +78,36 @@ func main() {
 	}
 	defer db.Close()
 
+	timeout, err := cmd.StatementTimeout(os.Getenv("TIMEOUT"))
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	stmtCtx, cancel := cmd.WithStatementTimeout(ctx, timeout)
+	defer cancel()
+
 	// Run the requested command
 	var result string
 	switch command {
 	case "listTables":
-		result, err = cmd.ListTables(ctx, db)
+		result, err = cmd.ListTables(stmtCtx, db)
+		if err == nil {
+			result, err = cmd.GuardTablesOutput(ctx, g, result)
+		}
 	case "exec":
-		result, err = cmd.Exec(ctx, db, os.Getenv("STATEMENT"))
+		result, err = cmd.Exec(stmtCtx, db, os.Getenv("STATEMENT"))
+		err = cmd.StatementError(stmtCtx, timeout, err)
 		if err == nil {
 			err = saveWorkspaceDB(ctx, g, dbWorkspacePath, dbFile, initialDBData)
 		}
 	case "query":
-		result, err = cmd.Query(ctx, db, os.Getenv("QUERY"))
+		result, err = cmd.Query(stmtCtx, db, os.Getenv("QUERY"))
+		err = cmd.StatementError(stmtCtx, timeout, err)
+		if err == nil {
+			result, err = cmd.GuardQueryOutput(ctx, g, result)
+		}
 	case "context":
-		result, err = cmd.Context(ctx, db)
+		result, err = cmd.Context(stmtCtx, db)
 	default:
 		err = fmt.Errorf("unknown command: %s", command)
 	}
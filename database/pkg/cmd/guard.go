@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/common/guard"
+)
+
+// GuardQueryOutput returns the result of Query if it fits in the token budget. Otherwise it stores one dataset
+// element per row and returns a summary with the ID of the dataset and the columns of the result.
+func GuardQueryOutput(ctx context.Context, g *gptscript.GPTScript, result string) (string, error) {
+	var output Output
+	if err := json.Unmarshal([]byte(result), &output); err != nil {
+		return "", fmt.Errorf("failed to parse query result: %w", err)
+	}
+
+	return guard.Output(ctx, g, result, func() ([]gptscript.DatasetElement, error) {
+		elements := make([]gptscript.DatasetElement, 0, len(output.Rows))
+		for i, row := range output.Rows {
+			content, err := json.Marshal(row)
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, gptscript.DatasetElement{
+				DatasetElementMeta: gptscript.DatasetElementMeta{Name: fmt.Sprintf("row_%d", i+1)},
+				Contents:           string(content),
+			})
+		}
+		return elements, nil
+	}, gptscript.DatasetOptions{
+		Name:        "database_query_results",
+		Description: "Rows returned by a database query",
+	}, fmt.Sprintf("rows with the columns %s", strings.Join(output.Columns, ", ")))
+}
+
+// GuardTablesOutput returns the result of ListTables if it fits in the token budget, otherwise it stores the tables
+// in a dataset.
+func GuardTablesOutput(ctx context.Context, g *gptscript.GPTScript, result string) (string, error) {
+	return guard.Output(ctx, g, result, func() ([]gptscript.DatasetElement, error) {
+		var tables tables
+		if err := json.Unmarshal([]byte(result), &tables); err != nil {
+			return nil, err
+		}
+
+		elements := make([]gptscript.DatasetElement, 0, len(tables.Tables))
+		for _, table := range tables.Tables {
+			elements = append(elements, gptscript.DatasetElement{
+				DatasetElementMeta: gptscript.DatasetElementMeta{Name: table.Name},
+				Contents:           table.Name,
+			})
+		}
+		return elements, nil
+	}, gptscript.DatasetOptions{
+		Name:        "database_tables",
+		Description: "Tables of the database",
+	}, "tables")
+}
@@ -57,6 +57,11 @@ func Query(ctx context.Context, db *sql.DB, query string) (string, error) {
 		output.Rows = append(output.Rows, rowData)
 	}
 
+	// An interrupted query stops the iteration early, which is only reported here
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("error reading rows: %w", err)
+	}
+
 	content, err := json.Marshal(output)
 	return string(content), err
 }
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// StatementTimeoutEnv is the default time limit for a single query or statement in seconds. 0 disables the limit.
+const StatementTimeoutEnv = "DATABASE_STATEMENT_TIMEOUT_SECONDS"
+
+const defaultStatementTimeout = 60 * time.Second
+
+// StatementTimeout returns the time limit for a statement. The override (in seconds, e.g. passed by the caller
+// for a single query) takes precedence over the StatementTimeoutEnv environment variable.
+func StatementTimeout(override string) (time.Duration, error) {
+	for _, v := range []string{override, os.Getenv(StatementTimeoutEnv)} {
+		if v == "" {
+			continue
+		}
+		seconds, err := strconv.Atoi(v)
+		if err != nil || seconds < 0 {
+			return 0, fmt.Errorf("invalid statement timeout %q: must be a non-negative number of seconds", v)
+		}
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return defaultStatementTimeout, nil
+}
+
+// WithStatementTimeout returns a context that is canceled after the timeout. A timeout of 0 means no limit.
+func WithStatementTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// StatementError explains errors caused by the statement context being done, as the driver only reports
+// that the statement was interrupted.
+func StatementError(ctx context.Context, timeout time.Duration, err error) error {
+	if err == nil {
+		return nil
+	}
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("statement canceled after exceeding the time limit of %s (%w)", timeout, err)
+	case errors.Is(ctx.Err(), context.Canceled):
+		return fmt.Errorf("statement canceled (%w)", err)
+	}
+	return err
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/gptscript-ai/tools/excel/pkg/commands"
+	"github.com/gptscript-ai/tools/excel/pkg/format"
 	"os"
 	"strconv"
 	"strings"
@@ -36,8 +37,31 @@ switchBlock:
 		err = commands.AddWorksheetRow(context.Background(), os.Getenv("WORKBOOK_ID"), os.Getenv("WORKSHEET_ID"), os.Getenv("CONTENTS"))
 	case "addWorksheetColumn":
 		err = commands.AddWorksheetColumn(context.Background(), os.Getenv("WORKBOOK_ID"), os.Getenv("WORKSHEET_ID"), os.Getenv("COLUMN_ID"), os.Getenv("CONTENTS"))
+	case "createWorkbook":
+		err = commands.CreateWorkbook(context.Background(), os.Getenv("NAME"), os.Getenv("SHEETS"), os.Getenv("LOCATION"))
 	case "createWorksheet":
 		err = commands.CreateWorksheet(context.Background(), os.Getenv("WORKBOOK_ID"), os.Getenv("NAME"))
+	case "renameWorksheet":
+		err = commands.RenameWorksheet(context.Background(), os.Getenv("WORKBOOK_ID"), os.Getenv("WORKSHEET_ID"), os.Getenv("NAME"))
+	case "moveWorksheet":
+		err = commands.MoveWorksheet(context.Background(), os.Getenv("WORKBOOK_ID"), os.Getenv("WORKSHEET_ID"), os.Getenv("POSITION"))
+	case "deleteWorksheet":
+		err = commands.DeleteWorksheet(context.Background(), os.Getenv("WORKBOOK_ID"), os.Getenv("WORKSHEET_ID"))
+	case "formatRange":
+		var options format.Options
+		if options, err = format.Parse(os.Getenv("NUMBER_FORMAT"), os.Getenv("BOLD"), os.Getenv("ITALIC"), os.Getenv("FONT_COLOR"),
+			os.Getenv("FILL_COLOR"), os.Getenv("BORDERS"), os.Getenv("BORDER_COLOR"), os.Getenv("COLUMN_WIDTH")); err != nil {
+			break
+		}
+		err = commands.FormatRange(context.Background(), os.Getenv("WORKBOOK_ID"), os.Getenv("WORKSHEET_ID"), os.Getenv("RANGE"), options)
+	case "traceFormula":
+		var depth int
+		if d := os.Getenv("DEPTH"); d != "" {
+			if depth, err = strconv.Atoi(d); err != nil {
+				break
+			}
+		}
+		err = commands.TraceFormula(context.Background(), os.Getenv("WORKBOOK_ID"), os.Getenv("WORKSHEET_ID"), os.Getenv("CELL"), os.Getenv("DIRECTION"), depth)
 	case "getDate":
 		serialStrings := strings.Split(os.Getenv("SERIALS"), "|")
 		serials := make([]int, len(serialStrings))
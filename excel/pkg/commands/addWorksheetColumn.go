@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/gptscript-ai/tools/common/locale"
 	"github.com/gptscript-ai/tools/excel/pkg/graph"
 )
 
@@ -12,6 +13,6 @@ func AddWorksheetColumn(ctx context.Context, workbookID, worksheetID, columnID,
 	if err := graph.AddWorksheetColumn(ctx, workbookID, worksheetID, columnID, strings.Split(contents, "|")); err != nil {
 		return err
 	}
-	fmt.Println("Column added successfully")
+	fmt.Println(locale.FromEnv().T("Column added successfully"))
 	return nil
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/gptscript-ai/tools/common/locale"
 	"github.com/gptscript-ai/tools/excel/pkg/client"
 	"github.com/gptscript-ai/tools/excel/pkg/global"
 	"github.com/gptscript-ai/tools/excel/pkg/graph"
@@ -19,6 +20,6 @@ func AddWorksheetRow(ctx context.Context, workbookID, worksheetID, contents stri
 	if err := graph.AddWorksheetRow(ctx, c, workbookID, worksheetID, strings.Split(contents, "|")); err != nil {
 		return err
 	}
-	fmt.Println("Row added successfully")
+	fmt.Println(locale.FromEnv().T("Row added successfully"))
 	return nil
 }
@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/common/locale"
+	"github.com/gptscript-ai/tools/excel/pkg/client"
+	"github.com/gptscript-ai/tools/excel/pkg/global"
+	"github.com/gptscript-ai/tools/excel/pkg/graph"
+	"github.com/gptscript-ai/tools/excel/pkg/printers"
+	"github.com/gptscript-ai/tools/excel/pkg/xlsx"
+)
+
+// CreateWorkbook creates an empty workbook with the pipe-separated worksheet names, either in the root folder of the
+// user's OneDrive (the default) or in the files of the workspace.
+func CreateWorkbook(ctx context.Context, name, sheets, location string) error {
+	fileName, err := xlsx.FileName(name)
+	if err != nil {
+		return err
+	}
+	sheetNames, err := xlsx.SheetNames(sheets)
+	if err != nil {
+		return err
+	}
+	data, err := xlsx.New(sheetNames)
+	if err != nil {
+		return err
+	}
+
+	loc := locale.FromEnv()
+	switch strings.ToLower(strings.TrimSpace(location)) {
+	case "", "onedrive":
+		c, err := client.NewClient(global.AllScopes)
+		if err != nil {
+			return err
+		}
+
+		info, err := graph.CreateWorkbook(ctx, c, fileName, data)
+		if err != nil {
+			return fmt.Errorf("failed to create workbook: %w", err)
+		}
+		fmt.Printf("%s: %s\n", loc.T("Workbook created with ID"), info.ID)
+		fmt.Print(printers.WorkbookInfoToString(info))
+	case "workspace":
+		gptscriptClient, err := gptscript.NewGPTScript()
+		if err != nil {
+			return fmt.Errorf("failed to create GPTScript client: %w", err)
+		}
+
+		if err := gptscriptClient.WriteFileInWorkspace(ctx, path.Join("files", fileName), data); err != nil {
+			return fmt.Errorf("failed to save workbook to workspace: %w", err)
+		}
+		fmt.Printf("%s: %s\n", loc.T("Workbook saved to the workspace as"), fileName)
+	default:
+		return fmt.Errorf("invalid location %q, must be onedrive or workspace", location)
+	}
+	return nil
+}
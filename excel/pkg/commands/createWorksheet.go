@@ -3,13 +3,22 @@ package commands
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/gptscript-ai/tools/common/locale"
 	"github.com/gptscript-ai/tools/excel/pkg/client"
 	"github.com/gptscript-ai/tools/excel/pkg/global"
 	"github.com/gptscript-ai/tools/excel/pkg/graph"
+	"github.com/gptscript-ai/tools/excel/pkg/xlsx"
 )
 
 func CreateWorksheet(ctx context.Context, workbookID, name string) error {
+	if name = strings.TrimSpace(name); name != "" {
+		if err := xlsx.ValidSheetName(name); err != nil {
+			return err
+		}
+	}
+
 	c, err := client.NewClient(global.AllScopes)
 	if err != nil {
 		return err
@@ -20,6 +29,6 @@ func CreateWorksheet(ctx context.Context, workbookID, name string) error {
 		return err
 	}
 
-	fmt.Printf("Worksheet created with ID: %s\n", id)
+	fmt.Printf("%s: %s\n", locale.FromEnv().T("Worksheet created with ID"), id)
 	return nil
 }
@@ -0,0 +1,24 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gptscript-ai/tools/common/locale"
+	"github.com/gptscript-ai/tools/excel/pkg/client"
+	"github.com/gptscript-ai/tools/excel/pkg/global"
+	"github.com/gptscript-ai/tools/excel/pkg/graph"
+)
+
+func DeleteWorksheet(ctx context.Context, workbookID, worksheetID string) error {
+	c, err := client.NewClient(global.AllScopes)
+	if err != nil {
+		return err
+	}
+
+	if err := graph.DeleteWorksheet(ctx, c, workbookID, worksheetID); err != nil {
+		return fmt.Errorf("failed to delete worksheet: %w", err)
+	}
+	fmt.Println(locale.FromEnv().T("Worksheet deleted successfully"))
+	return nil
+}
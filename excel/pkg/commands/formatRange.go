@@ -0,0 +1,27 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gptscript-ai/tools/common/locale"
+	"github.com/gptscript-ai/tools/excel/pkg/format"
+	"github.com/gptscript-ai/tools/excel/pkg/graph"
+)
+
+// FormatRange formats the cells of the range with the address, e.g. A1:D1. Options that are empty are left unchanged.
+func FormatRange(ctx context.Context, workbookID, worksheetID, address string, options format.Options) error {
+	if address = strings.TrimSpace(address); address == "" {
+		return fmt.Errorf("range is required")
+	}
+	if options.Empty() {
+		return fmt.Errorf("no formatting options given")
+	}
+
+	if err := graph.FormatRange(ctx, workbookID, worksheetID, address, options); err != nil {
+		return err
+	}
+	fmt.Println(locale.FromEnv().T("Range formatted successfully"))
+	return nil
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/common/guard"
 	"github.com/gptscript-ai/tools/excel/pkg/client"
 	"github.com/gptscript-ai/tools/excel/pkg/global"
 	"github.com/gptscript-ai/tools/excel/pkg/graph"
@@ -25,6 +27,25 @@ func GetWorksheetData(ctx context.Context, workbookID, worksheetID string) error
 	if err != nil {
 		return err
 	}
-	fmt.Println(string(dataBytes))
-	return nil
+
+	// Large worksheets are stored with one element per row of the used range
+	return guard.Print(ctx, nil, string(dataBytes)+"\n", func() ([]gptscript.DatasetElement, error) {
+		elements := make([]gptscript.DatasetElement, 0, len(data))
+		for i, row := range data {
+			rowBytes, err := json.Marshal(row)
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, gptscript.DatasetElement{
+				DatasetElementMeta: gptscript.DatasetElementMeta{
+					Name: fmt.Sprintf("row_%d", i+1),
+				},
+				Contents: string(rowBytes),
+			})
+		}
+		return elements, nil
+	}, gptscript.DatasetOptions{
+		Name:        worksheetID + "_excel_worksheet_data",
+		Description: "Rows of Excel worksheet " + worksheetID,
+	}, "rows")
 }
@@ -2,6 +2,9 @@ package commands
 
 import (
 	"context"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/common/guard"
 	"github.com/gptscript-ai/tools/excel/pkg/client"
 	"github.com/gptscript-ai/tools/excel/pkg/global"
 	"github.com/gptscript-ai/tools/excel/pkg/graph"
@@ -18,6 +21,20 @@ func GetWorksheetTables(ctx context.Context, workbookID, worksheetID string) err
 	if err != nil {
 		return err
 	}
-	printers.PrintWorksheetTableInfos(tables)
-	return nil
+
+	elements := make([]gptscript.DatasetElement, 0, len(tables))
+	for _, table := range tables {
+		elements = append(elements, gptscript.DatasetElement{
+			DatasetElementMeta: gptscript.DatasetElementMeta{
+				Name:        table.ID,
+				Description: table.Name,
+			},
+			Contents: printers.WorksheetTableInfoToString(table),
+		})
+	}
+
+	return guard.PrintElements(ctx, nil, elements, gptscript.DatasetOptions{
+		Name:        worksheetID + "_excel_tables",
+		Description: "Tables of Excel worksheet " + worksheetID,
+	}, "tables")
 }
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/common/guard"
 	"github.com/gptscript-ai/tools/excel/pkg/client"
 	"github.com/gptscript-ai/tools/excel/pkg/global"
 	"github.com/gptscript-ai/tools/excel/pkg/graph"
@@ -21,6 +23,19 @@ func ListWorkbooks(ctx context.Context) error {
 		return fmt.Errorf("failed to list spreadsheets: %w", err)
 	}
 
-	printers.PrintWorkbookInfos(workbookInfos)
-	return nil
+	elements := make([]gptscript.DatasetElement, 0, len(workbookInfos))
+	for _, info := range workbookInfos {
+		elements = append(elements, gptscript.DatasetElement{
+			DatasetElementMeta: gptscript.DatasetElementMeta{
+				Name:        info.ID,
+				Description: info.Name,
+			},
+			Contents: printers.WorkbookInfoToString(info),
+		})
+	}
+
+	return guard.PrintElements(ctx, nil, elements, gptscript.DatasetOptions{
+		Name:        "excel_workbooks",
+		Description: "Excel workbooks available to the user",
+	}, "workbooks")
 }
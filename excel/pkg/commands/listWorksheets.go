@@ -3,6 +3,8 @@ package commands
 import (
 	"context"
 
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/common/guard"
 	"github.com/gptscript-ai/tools/excel/pkg/client"
 	"github.com/gptscript-ai/tools/excel/pkg/global"
 	"github.com/gptscript-ai/tools/excel/pkg/graph"
@@ -20,6 +22,19 @@ func ListWorksheets(ctx context.Context, workbookID string) error {
 		return err
 	}
 
-	printers.PrintWorksheetInfos(infos)
-	return nil
+	elements := make([]gptscript.DatasetElement, 0, len(infos))
+	for _, info := range infos {
+		elements = append(elements, gptscript.DatasetElement{
+			DatasetElementMeta: gptscript.DatasetElementMeta{
+				Name:        info.ID,
+				Description: info.Name,
+			},
+			Contents: printers.WorksheetInfoToString(info),
+		})
+	}
+
+	return guard.PrintElements(ctx, nil, elements, gptscript.DatasetOptions{
+		Name:        workbookID + "_excel_worksheets",
+		Description: "Worksheets of Excel workbook " + workbookID,
+	}, "worksheets")
 }
@@ -0,0 +1,34 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gptscript-ai/tools/common/locale"
+	"github.com/gptscript-ai/tools/excel/pkg/client"
+	"github.com/gptscript-ai/tools/excel/pkg/global"
+	"github.com/gptscript-ai/tools/excel/pkg/graph"
+	"github.com/gptscript-ai/tools/excel/pkg/printers"
+	"github.com/gptscript-ai/tools/excel/pkg/util"
+)
+
+// MoveWorksheet moves the worksheet to the one-based position, e.g. 1 to make it the first worksheet.
+func MoveWorksheet(ctx context.Context, workbookID, worksheetID, position string) error {
+	pos, err := util.ParsePosition(position)
+	if err != nil {
+		return err
+	}
+
+	c, err := client.NewClient(global.AllScopes)
+	if err != nil {
+		return err
+	}
+
+	info, err := graph.UpdateWorksheet(ctx, c, workbookID, worksheetID, nil, &pos)
+	if err != nil {
+		return fmt.Errorf("failed to move worksheet: %w", err)
+	}
+	fmt.Println(locale.FromEnv().T("Worksheet updated successfully"))
+	fmt.Print(printers.WorksheetInfoToString(info))
+	return nil
+}
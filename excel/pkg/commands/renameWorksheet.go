@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gptscript-ai/tools/common/locale"
+	"github.com/gptscript-ai/tools/excel/pkg/client"
+	"github.com/gptscript-ai/tools/excel/pkg/global"
+	"github.com/gptscript-ai/tools/excel/pkg/graph"
+	"github.com/gptscript-ai/tools/excel/pkg/printers"
+	"github.com/gptscript-ai/tools/excel/pkg/util"
+	"github.com/gptscript-ai/tools/excel/pkg/xlsx"
+)
+
+func RenameWorksheet(ctx context.Context, workbookID, worksheetID, name string) error {
+	// Validate the name the way Excel does, so that the error explains what is wrong with it
+	if name = strings.TrimSpace(name); name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if err := xlsx.ValidSheetName(name); err != nil {
+		return err
+	}
+
+	c, err := client.NewClient(global.AllScopes)
+	if err != nil {
+		return err
+	}
+
+	info, err := graph.UpdateWorksheet(ctx, c, workbookID, worksheetID, util.Ptr(name), nil)
+	if err != nil {
+		return fmt.Errorf("failed to rename worksheet: %w", err)
+	}
+	fmt.Println(locale.FromEnv().T("Worksheet updated successfully"))
+	fmt.Print(printers.WorksheetInfoToString(info))
+	return nil
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gptscript-ai/tools/excel/pkg/client"
+	"github.com/gptscript-ai/tools/excel/pkg/formula"
+	"github.com/gptscript-ai/tools/excel/pkg/global"
+	"github.com/gptscript-ai/tools/excel/pkg/graph"
+)
+
+// TraceFormula prints the tree of precedents and/or dependents of a cell across all worksheets of the workbook.
+// direction is "precedents", "dependents" or "both".
+func TraceFormula(ctx context.Context, workbookID, worksheetID, cell, direction string, depth int) error {
+	var precedents, dependents bool
+	switch strings.ToLower(direction) {
+	case "", "both":
+		precedents, dependents = true, true
+	case "precedents":
+		precedents = true
+	case "dependents":
+		dependents = true
+	default:
+		return fmt.Errorf("invalid direction %q, must be one of precedents, dependents or both", direction)
+	}
+	if depth <= 0 {
+		depth = 5
+	}
+
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return err
+	}
+
+	worksheets, err := graph.ListWorksheetsInWorkbook(ctx, c, workbookID)
+	if err != nil {
+		return err
+	}
+
+	var sheetName string
+	for _, ws := range worksheets {
+		if ws.ID == worksheetID || strings.EqualFold(ws.Name, worksheetID) {
+			sheetName = ws.Name
+			break
+		}
+	}
+	if sheetName == "" {
+		return fmt.Errorf("worksheet %q not found", worksheetID)
+	}
+
+	target, err := formula.ParseCell(cell, sheetName)
+	if err != nil {
+		return err
+	}
+
+	// References can point to any sheet, so the whole workbook is loaded
+	wb := formula.NewWorkbook()
+	for _, ws := range worksheets {
+		address, formulas, values, err := graph.GetWorksheetFormulas(ctx, c, workbookID, ws.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get formulas of worksheet %q: %w", ws.Name, err)
+		}
+
+		start, _, _ := strings.Cut(address, ":")
+		origin, err := formula.ParseCell(start, ws.Name)
+		if err != nil {
+			return fmt.Errorf("failed to parse used range of worksheet %q: %w", ws.Name, err)
+		}
+		origin.Sheet = ws.Name
+
+		wb.AddSheet(origin, formulas, values)
+	}
+
+	tree := wb.Trace(target, precedents, dependents, depth)
+
+	treeBytes, err := json.Marshal(tree)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(treeBytes))
+	return nil
+}
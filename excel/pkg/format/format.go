@@ -0,0 +1,203 @@
+// Package format turns the formatting options of the formatRange command into the requests of the Graph workbook
+// range format APIs.
+package format
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MaxNumberFormatCells is the maximum number of cells of a range whose number format can be set. Graph expects a
+// number format for every cell of the range, so whole columns or rows are too large.
+const MaxNumberFormatCells = 100_000
+
+// Request is a request to the range, Path is relative to the URL of the range, e.g. /format/font.
+type Request struct {
+	Method string
+	Path   string
+	Body   map[string]any
+}
+
+// Options are the formatting options of a range. Empty options are left unchanged.
+type Options struct {
+	// NumberFormat is the Excel number format code of the cells, e.g. #,##0.00 or yyyy-mm-dd
+	NumberFormat string
+	Bold         *bool
+	Italic       *bool
+	// FontColor and FillColor are HTML colors, e.g. #FF0000 or red. A FillColor of none removes the fill.
+	FontColor string
+	FillColor string
+	// Borders are the sides of the borders to draw, see borderSides
+	Borders     []string
+	BorderColor string
+	// RemoveBorders removes the borders of the sides instead of drawing them
+	RemoveBorders bool
+	// ColumnWidth is the width of the columns in points, or 0 to fit the columns to their contents
+	ColumnWidth *float64
+}
+
+// borderSides are the named sets of borders, the other values are the sides of the cells, e.g. top,bottom
+var borderSides = map[string][]string{
+	"all":     {"EdgeTop", "EdgeBottom", "EdgeLeft", "EdgeRight", "InsideVertical", "InsideHorizontal"},
+	"outline": {"EdgeTop", "EdgeBottom", "EdgeLeft", "EdgeRight"},
+	"inside":  {"InsideVertical", "InsideHorizontal"},
+	"none":    {"EdgeTop", "EdgeBottom", "EdgeLeft", "EdgeRight", "InsideVertical", "InsideHorizontal"},
+}
+
+var sides = map[string]string{
+	"top":              "EdgeTop",
+	"bottom":           "EdgeBottom",
+	"left":             "EdgeLeft",
+	"right":            "EdgeRight",
+	"insidevertical":   "InsideVertical",
+	"insidehorizontal": "InsideHorizontal",
+}
+
+var colorPattern = regexp.MustCompile(`^(#[0-9A-Fa-f]{6}|[A-Za-z]+)$`)
+
+// Parse parses the formatting options from the parameters of the command. Bold and italic are true or false, borders
+// are all, outline, inside, none or comma-separated sides (top, bottom, left, right, insideVertical,
+// insideHorizontal), and the column width is a number of points or auto.
+func Parse(numberFormat, bold, italic, fontColor, fillColor, borders, borderColor, columnWidth string) (Options, error) {
+	options := Options{
+		NumberFormat: strings.TrimSpace(numberFormat),
+	}
+
+	var err error
+	if options.Bold, err = parseBool("bold", bold); err != nil {
+		return Options{}, err
+	}
+	if options.Italic, err = parseBool("italic", italic); err != nil {
+		return Options{}, err
+	}
+
+	for _, c := range []struct {
+		name  string
+		value string
+		dest  *string
+	}{
+		{"font color", fontColor, &options.FontColor},
+		{"fill color", fillColor, &options.FillColor},
+		{"border color", borderColor, &options.BorderColor},
+	} {
+		if value := strings.TrimSpace(c.value); value != "" {
+			if !colorPattern.MatchString(value) {
+				return Options{}, fmt.Errorf("invalid %s %q, must be a color like #FF0000 or red", c.name, value)
+			}
+			*c.dest = value
+		}
+	}
+
+	if borders = strings.ToLower(strings.TrimSpace(borders)); borders != "" {
+		if set, ok := borderSides[borders]; ok {
+			options.Borders = set
+		} else {
+			for _, side := range strings.Split(borders, ",") {
+				if side = strings.TrimSpace(side); side == "" {
+					continue
+				}
+				index, ok := sides[side]
+				if !ok {
+					return Options{}, fmt.Errorf("invalid border %q, must be all, outline, inside, none or a comma-separated list of top, bottom, left, right, insideVertical and insideHorizontal", side)
+				}
+				options.Borders = append(options.Borders, index)
+			}
+		}
+	}
+	if options.RemoveBorders = borders == "none"; options.RemoveBorders && options.BorderColor != "" {
+		return Options{}, fmt.Errorf("a border color can't be set when removing the borders")
+	}
+
+	if columnWidth = strings.TrimSpace(columnWidth); strings.EqualFold(columnWidth, "auto") {
+		options.ColumnWidth = new(float64)
+	} else if columnWidth != "" {
+		width, err := strconv.ParseFloat(columnWidth, 64)
+		if err != nil || width <= 0 {
+			return Options{}, fmt.Errorf("invalid column width %q, must be a number of points greater than 0 or auto", columnWidth)
+		}
+		options.ColumnWidth = &width
+	}
+
+	return options, nil
+}
+
+func parseBool(name, value string) (*bool, error) {
+	if value = strings.TrimSpace(value); value == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q, must be true or false", name, value)
+	}
+	return &b, nil
+}
+
+// Empty returns whether the options don't change anything.
+func (o Options) Empty() bool {
+	return o.NumberFormat == "" && o.Bold == nil && o.Italic == nil && o.FontColor == "" && o.FillColor == "" &&
+		len(o.Borders) == 0 && o.ColumnWidth == nil
+}
+
+// Requests returns the requests that apply the options, except for the number format, see NumberFormatRequest.
+func (o Options) Requests() []Request {
+	var requests []Request
+
+	font := map[string]any{}
+	if o.Bold != nil {
+		font["bold"] = *o.Bold
+	}
+	if o.Italic != nil {
+		font["italic"] = *o.Italic
+	}
+	if o.FontColor != "" {
+		font["color"] = o.FontColor
+	}
+	if len(font) > 0 {
+		requests = append(requests, Request{Method: http.MethodPatch, Path: "/format/font", Body: font})
+	}
+
+	if strings.EqualFold(o.FillColor, "none") {
+		requests = append(requests, Request{Method: http.MethodPost, Path: "/format/fill/clear"})
+	} else if o.FillColor != "" {
+		requests = append(requests, Request{Method: http.MethodPatch, Path: "/format/fill", Body: map[string]any{"color": o.FillColor}})
+	}
+
+	for _, side := range o.Borders {
+		border := map[string]any{"style": "Continuous", "weight": "Thin"}
+		if o.RemoveBorders {
+			border = map[string]any{"style": "None"}
+		} else if o.BorderColor != "" {
+			border["color"] = o.BorderColor
+		}
+		requests = append(requests, Request{Method: http.MethodPatch, Path: "/format/borders/" + side, Body: border})
+	}
+
+	if o.ColumnWidth != nil {
+		if *o.ColumnWidth == 0 {
+			requests = append(requests, Request{Method: http.MethodPost, Path: "/format/autofitColumns"})
+		} else {
+			requests = append(requests, Request{Method: http.MethodPatch, Path: "/format", Body: map[string]any{"columnWidth": *o.ColumnWidth}})
+		}
+	}
+	return requests
+}
+
+// NumberFormatRequest returns the request that sets the number format of every cell of a range with the rows and
+// columns.
+func (o Options) NumberFormatRequest(rows, columns int) (Request, error) {
+	if rows*columns > MaxNumberFormatCells {
+		return Request{}, fmt.Errorf("the range has %d cells, the number format can be set for at most %d cells at once, use a smaller range like A1:A1000", rows*columns, MaxNumberFormatCells)
+	}
+
+	numberFormat := make([][]string, rows)
+	for i := range numberFormat {
+		numberFormat[i] = make([]string, columns)
+		for j := range numberFormat[i] {
+			numberFormat[i][j] = o.NumberFormat
+		}
+	}
+	return Request{Method: http.MethodPatch, Body: map[string]any{"numberFormat": numberFormat}}, nil
+}
@@ -0,0 +1,115 @@
+package format
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	options, err := Parse(" #,##0.00 ", "true", "", "#1F4E79", "none", "outline", "gray", "auto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if options.NumberFormat != "#,##0.00" || !*options.Bold || options.Italic != nil || options.FontColor != "#1F4E79" ||
+		options.FillColor != "none" || len(options.Borders) != 4 || options.BorderColor != "gray" || *options.ColumnWidth != 0 {
+		t.Fatalf("unexpected options: %+v", options)
+	}
+
+	if options, err = Parse("", "", "", "", "", "", "", ""); err != nil || !options.Empty() {
+		t.Fatalf("expected empty options, got %+v, %v", options, err)
+	}
+
+	for _, args := range []struct {
+		bold, fontColor, borders, borderColor, columnWidth string
+		message                                            string
+	}{
+		{bold: "yes please", message: "invalid bold"},
+		{fontColor: "#12345", message: "invalid font color"},
+		{fontColor: "rgb(1,2,3)", message: "invalid font color"},
+		{borders: "top,diagonal", message: `invalid border "diagonal"`},
+		{borders: "none", borderColor: "red", message: "border color"},
+		{columnWidth: "-5", message: "invalid column width"},
+		{columnWidth: "wide", message: "invalid column width"},
+	} {
+		if _, err := Parse("", args.bold, "", args.fontColor, "", args.borders, args.borderColor, args.columnWidth); err == nil || !strings.Contains(err.Error(), args.message) {
+			t.Errorf("%+v: expected an error containing %q, got %v", args, args.message, err)
+		}
+	}
+}
+
+func TestRequests(t *testing.T) {
+	for _, test := range []struct {
+		name                                                                  string
+		bold, italic, fontColor, fillColor, borders, borderColor, columnWidth string
+		expected                                                              []string
+	}{
+		{
+			name: "header", bold: "true", fontColor: "white", fillColor: "#1F4E79", borders: "bottom", columnWidth: "120",
+			expected: []string{
+				`PATCH /format/font {"bold":true,"color":"white"}`,
+				`PATCH /format/fill {"color":"#1F4E79"}`,
+				`PATCH /format/borders/EdgeBottom {"style":"Continuous","weight":"Thin"}`,
+				`PATCH /format {"columnWidth":120}`,
+			},
+		},
+		{
+			name: "cleared", italic: "false", fillColor: "None", borders: "none", columnWidth: "auto",
+			expected: []string{
+				`PATCH /format/font {"italic":false}`,
+				`POST /format/fill/clear null`,
+				`PATCH /format/borders/EdgeTop {"style":"None"}`,
+				`PATCH /format/borders/EdgeBottom {"style":"None"}`,
+				`PATCH /format/borders/EdgeLeft {"style":"None"}`,
+				`PATCH /format/borders/EdgeRight {"style":"None"}`,
+				`PATCH /format/borders/InsideVertical {"style":"None"}`,
+				`PATCH /format/borders/InsideHorizontal {"style":"None"}`,
+				`POST /format/autofitColumns null`,
+			},
+		},
+		{
+			name: "inside", borders: "Inside", borderColor: "#000000",
+			expected: []string{
+				`PATCH /format/borders/InsideVertical {"color":"#000000","style":"Continuous","weight":"Thin"}`,
+				`PATCH /format/borders/InsideHorizontal {"color":"#000000","style":"Continuous","weight":"Thin"}`,
+			},
+		},
+	} {
+		options, err := Parse("", test.bold, test.italic, test.fontColor, test.fillColor, test.borders, test.borderColor, test.columnWidth)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		var requests []string
+		for _, req := range options.Requests() {
+			body, err := json.Marshal(req.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			requests = append(requests, req.Method+" "+req.Path+" "+string(body))
+		}
+		if strings.Join(requests, "\n") != strings.Join(test.expected, "\n") {
+			t.Errorf("%s: unexpected requests:\n%s", test.name, strings.Join(requests, "\n"))
+		}
+	}
+}
+
+func TestNumberFormatRequest(t *testing.T) {
+	options := Options{NumberFormat: "0%"}
+	req, err := options.NumberFormatRequest(2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := json.Marshal(req.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"numberFormat":[["0%","0%","0%"],["0%","0%","0%"]]}`; req.Method != "PATCH" || req.Path != "" || string(body) != expected {
+		t.Fatalf("expected PATCH %s, got %s %s %s", expected, req.Method, req.Path, body)
+	}
+
+	// A whole column has more than a million cells
+	if _, err := options.NumberFormatRequest(1048576, 1); err == nil || !strings.Contains(err.Error(), "smaller range") {
+		t.Fatalf("expected the range to be too large, got %v", err)
+	}
+}
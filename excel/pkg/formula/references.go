@@ -0,0 +1,192 @@
+package formula
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gptscript-ai/tools/excel/pkg/util"
+)
+
+const (
+	maxColumn = 16384   // XFD
+	maxRow    = 1048576 // rows per worksheet
+)
+
+// Matches A1 style references, optionally with a (quoted) sheet name: A1, $B$2, A1:C3, A:C, Sheet1!A1, 'My Sheet'!A1:B2
+var referencePattern = regexp.MustCompile(`(?:(?:'((?:[^']|'')+)'|([A-Za-z_][A-Za-z0-9_.]*))!)?(\$?[A-Za-z]{1,3}\$?[0-9]+(?::\$?[A-Za-z]{1,3}\$?[0-9]+)?|\$?[A-Za-z]{1,3}:\$?[A-Za-z]{1,3})`)
+
+// Cell is a single cell of a workbook. Column and row are 1-based.
+type Cell struct {
+	Sheet string
+	Col   int
+	Row   int
+}
+
+func (c Cell) String() string {
+	return fmt.Sprintf("%s!%s%d", quoteSheet(c.Sheet), util.ColumnNumberToLetters(c.Col), c.Row)
+}
+
+// key identifies the cell regardless of the casing of the sheet name, as sheet names are case-insensitive in Excel
+func (c Cell) key() string {
+	return strings.ToLower(c.Sheet) + "!" + strconv.Itoa(c.Col) + ":" + strconv.Itoa(c.Row)
+}
+
+// Reference is a cell or a rectangular range referenced by a formula. For whole column references (A:C),
+// the rows span the entire sheet.
+type Reference struct {
+	Start Cell
+	End   Cell
+}
+
+func (r Reference) IsCell() bool {
+	return r.Start == r.End
+}
+
+func (r Reference) Contains(c Cell) bool {
+	return strings.EqualFold(r.Start.Sheet, c.Sheet) &&
+		c.Col >= r.Start.Col && c.Col <= r.End.Col &&
+		c.Row >= r.Start.Row && c.Row <= r.End.Row
+}
+
+func (r Reference) String() string {
+	if r.IsCell() {
+		return r.Start.String()
+	}
+	if r.Start.Row == 1 && r.End.Row == maxRow {
+		return fmt.Sprintf("%s!%s:%s", quoteSheet(r.Start.Sheet), util.ColumnNumberToLetters(r.Start.Col), util.ColumnNumberToLetters(r.End.Col))
+	}
+	return fmt.Sprintf("%s:%s%d", r.Start.String(), util.ColumnNumberToLetters(r.End.Col), r.End.Row)
+}
+
+func quoteSheet(sheet string) string {
+	for _, r := range sheet {
+		if !(r == '_' || r == '.' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return "'" + strings.ReplaceAll(sheet, "'", "''") + "'"
+		}
+	}
+	return sheet
+}
+
+// ParseCell parses a cell address like "B7", "$B$7" or "Sheet1!B7". Addresses without a sheet name refer to defaultSheet.
+func ParseCell(address, defaultSheet string) (Cell, error) {
+	refs := References("="+address, defaultSheet)
+	if len(refs) != 1 || !refs[0].IsCell() {
+		return Cell{}, fmt.Errorf("invalid cell address %q", address)
+	}
+	return refs[0].Start, nil
+}
+
+// References returns the cell and range references of a formula. References without a sheet name refer to sheet.
+// Named ranges, structured table references and references to other workbooks are not resolved.
+func References(formula, sheet string) []Reference {
+	formula = blankStringLiterals(formula)
+
+	var refs []Reference
+	for _, m := range referencePattern.FindAllStringSubmatchIndex(formula, -1) {
+		start, end := m[0], m[1]
+
+		// Must not be part of a longer identifier, an external workbook reference ([1]Sheet1!A1) or a function call (LOG10())
+		if start > 0 && isIdentifierChar(formula[start-1], true) {
+			continue
+		}
+		if end < len(formula) && (isIdentifierChar(formula[end], false) || formula[end] == '(' || formula[end] == '!') {
+			continue
+		}
+
+		refSheet := sheet
+		switch {
+		case m[2] >= 0:
+			refSheet = strings.ReplaceAll(formula[m[2]:m[3]], "''", "'")
+		case m[4] >= 0:
+			refSheet = formula[m[4]:m[5]]
+		}
+
+		ref, ok := parseReference(formula[m[6]:m[7]], refSheet)
+		if ok {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+func isIdentifierChar(b byte, before bool) bool {
+	return b == '_' || b == '.' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' ||
+		before && (b == ']' || b == '$' || b == '\'')
+}
+
+// blankStringLiterals replaces the contents of string literals with spaces, so text like "A1" isn't taken for a reference
+func blankStringLiterals(formula string) string {
+	b := []byte(formula)
+	inString := false
+	for i := 0; i < len(b); i++ {
+		if b[i] != '"' {
+			if inString {
+				b[i] = ' '
+			}
+			continue
+		}
+		if inString && i+1 < len(b) && b[i+1] == '"' {
+			b[i], b[i+1] = ' ', ' '
+			i++
+			continue
+		}
+		inString = !inString
+	}
+	return string(b)
+}
+
+func parseReference(s, sheet string) (Reference, bool) {
+	from, to, isRange := strings.Cut(strings.ReplaceAll(s, "$", ""), ":")
+	if !isRange {
+		to = from
+	}
+
+	start, okStart := parseA1(from, sheet)
+	end, okEnd := parseA1(to, sheet)
+	if !okStart || !okEnd {
+		return Reference{}, false
+	}
+
+	// Whole columns
+	if start.Row == 0 && end.Row == 0 {
+		start.Row, end.Row = 1, maxRow
+	} else if start.Row == 0 || end.Row == 0 {
+		return Reference{}, false
+	}
+
+	if start.Col > end.Col {
+		start.Col, end.Col = end.Col, start.Col
+	}
+	if start.Row > end.Row {
+		start.Row, end.Row = end.Row, start.Row
+	}
+	return Reference{Start: start, End: end}, true
+}
+
+// parseA1 parses "B7" (or "B" for column references, which returns row 0)
+func parseA1(s, sheet string) (Cell, bool) {
+	i := 0
+	for i < len(s) && (s[i] >= 'a' && s[i] <= 'z' || s[i] >= 'A' && s[i] <= 'Z') {
+		i++
+	}
+	if i == 0 {
+		return Cell{}, false
+	}
+
+	col := util.ColumnLettersToNumber(s[:i])
+	if col > maxColumn {
+		return Cell{}, false
+	}
+
+	row := 0
+	if i < len(s) {
+		var err error
+		if row, err = strconv.Atoi(s[i:]); err != nil || row < 1 || row > maxRow {
+			return Cell{}, false
+		}
+	}
+
+	return Cell{Sheet: sheet, Col: col, Row: row}, true
+}
@@ -0,0 +1,85 @@
+package formula
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReferences(t *testing.T) {
+	tests := []struct {
+		formula string
+		want    []string
+	}{
+		{"=A1+B2", []string{"Sheet1!A1", "Sheet1!B2"}},
+		{"=SUM($A$1:$C$3)", []string{"Sheet1!A1:C3"}},
+		{"=Data!B7*2", []string{"Data!B7"}},
+		{"='My Sheet'!A1:B2", []string{"'My Sheet'!A1:B2"}},
+		{"='It''s'!C4", []string{"'It''s'!C4"}},
+		{"=SUM(A:B)", []string{"Sheet1!A:B"}},
+		{"=LOG10(A2)", []string{"Sheet1!A2"}},
+		{`=IF(A1="B2","C3",D4)`, []string{"Sheet1!A1", "Sheet1!D4"}},
+		{"=Revenue*2", nil},
+		{"=[1]Other!A1", nil},
+		{"=Table1[Amount]", nil},
+	}
+	for _, test := range tests {
+		var got []string
+		for _, ref := range References(test.formula, "Sheet1") {
+			got = append(got, ref.String())
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("References(%q) = %v, want %v", test.formula, got, test.want)
+		}
+	}
+}
+
+func TestTrace(t *testing.T) {
+	w := NewWorkbook()
+	w.AddSheet(Cell{Sheet: "Sheet1", Col: 1, Row: 1}, [][]any{
+		{1, "=A1*2", "=SUM(A1:B1)"},
+		{"=C1+Totals!A1", "", ""},
+	}, [][]any{
+		{1, 2, 3},
+		{13, "", ""},
+	})
+	w.AddSheet(Cell{Sheet: "Totals", Col: 1, Row: 1}, [][]any{
+		{10},
+	}, [][]any{
+		{10},
+	})
+
+	cell, err := ParseCell("C1", "Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := w.Trace(cell, true, true, 5)
+	if root.Formula != "=SUM(A1:B1)" {
+		t.Errorf("unexpected formula %q", root.Formula)
+	}
+	if len(root.Precedents) != 1 || root.Precedents[0].Address != "Sheet1!A1:B1" {
+		t.Fatalf("unexpected precedents: %+v", root.Precedents)
+	}
+	// Only the formula cell of the range is expanded
+	if rng := root.Precedents[0]; len(rng.Precedents) != 1 || rng.Precedents[0].Address != "Sheet1!B1" || len(rng.Precedents[0].Precedents) != 1 {
+		t.Errorf("unexpected range precedents: %+v", rng.Precedents)
+	}
+	if len(root.Dependents) != 1 || root.Dependents[0].Address != "Sheet1!A2" {
+		t.Errorf("unexpected dependents: %+v", root.Dependents)
+	}
+
+	other, _ := ParseCell("Totals!A1", "Sheet1")
+	if deps := w.Trace(other, false, true, 5).Dependents; len(deps) != 1 || deps[0].Address != "Sheet1!A2" {
+		t.Errorf("unexpected cross-sheet dependents: %+v", deps)
+	}
+}
+
+func TestTraceCircular(t *testing.T) {
+	w := NewWorkbook()
+	w.AddSheet(Cell{Sheet: "Sheet1", Col: 1, Row: 1}, [][]any{{"=B1", "=A1"}}, [][]any{{0, 0}})
+
+	root := w.Trace(Cell{Sheet: "Sheet1", Col: 1, Row: 1}, true, false, 10)
+	if len(root.Precedents) != 1 || len(root.Precedents[0].Precedents) != 1 || !root.Precedents[0].Precedents[0].Circular {
+		t.Errorf("expected circular reference to be detected: %+v", root.Precedents)
+	}
+}
@@ -0,0 +1,176 @@
+package formula
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// maxNodes limits the size of a trace, as ranges over large formula blocks can fan out quickly
+const maxNodes = 1000
+
+// Workbook holds the formulas and values of all worksheets of a workbook.
+type Workbook struct {
+	cells    map[string]*cellInfo
+	formulas []*cellInfo // cells containing a formula, in sheet order
+}
+
+type cellInfo struct {
+	Cell
+	Value   any
+	Formula string
+	refs    []Reference
+}
+
+func NewWorkbook() *Workbook {
+	return &Workbook{cells: map[string]*cellInfo{}}
+}
+
+// AddSheet adds the used range of a worksheet. origin is the top left cell of the range, formulas and values are
+// the row-major contents of the range as returned by Excel (formulas contain the value for constant cells).
+func (w *Workbook) AddSheet(origin Cell, formulas, values [][]any) {
+	for r, row := range formulas {
+		for c, f := range row {
+			cell := Cell{Sheet: origin.Sheet, Col: origin.Col + c, Row: origin.Row + r}
+			info := &cellInfo{Cell: cell}
+			if r < len(values) && c < len(values[r]) {
+				info.Value = values[r][c]
+			}
+			if s, ok := f.(string); ok && strings.HasPrefix(s, "=") {
+				info.Formula = s
+				info.refs = References(s, origin.Sheet)
+				w.formulas = append(w.formulas, info)
+			} else if info.Value == nil || info.Value == "" {
+				continue
+			}
+			w.cells[cell.key()] = info
+		}
+	}
+}
+
+// Node is a cell or range in a dependency tree.
+type Node struct {
+	Address string `json:"address"`
+	Value   any    `json:"value,omitempty"`
+	Formula string `json:"formula,omitempty"`
+	// Circular is set if the cell was already visited on the path from the root, the node is not expanded further
+	Circular   bool    `json:"circular,omitempty"`
+	Precedents []*Node `json:"precedents,omitempty"`
+	Dependents []*Node `json:"dependents,omitempty"`
+	// Truncated is set if the node was not expanded because the maximum depth or size of the trace was reached
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+type tracer struct {
+	w     *Workbook
+	nodes int
+}
+
+// Trace returns the dependency tree of the cell: the cells its formula uses (recursively) and/or the formulas
+// using the cell (recursively), up to the given depth.
+func (w *Workbook) Trace(cell Cell, precedents, dependents bool, depth int) *Node {
+	root := w.node(cell)
+	if precedents {
+		t := &tracer{w: w}
+		root.Precedents = t.precedents(w.cells[cell.key()], depth, map[string]bool{cell.key(): true})
+		root.Truncated = root.Truncated || t.nodes >= maxNodes
+	}
+	if dependents {
+		t := &tracer{w: w}
+		root.Dependents = t.dependents(cell, depth, map[string]bool{cell.key(): true})
+		root.Truncated = root.Truncated || t.nodes >= maxNodes
+	}
+	return root
+}
+
+func (w *Workbook) node(cell Cell) *Node {
+	n := &Node{Address: cell.String()}
+	if info, ok := w.cells[cell.key()]; ok {
+		n.Value = info.Value
+		n.Formula = info.Formula
+	}
+	return n
+}
+
+func (t *tracer) precedents(info *cellInfo, depth int, path map[string]bool) []*Node {
+	if info == nil || info.Formula == "" {
+		return nil
+	}
+
+	var nodes []*Node
+	for _, ref := range info.refs {
+		if t.nodes >= maxNodes {
+			break
+		}
+		t.nodes++
+
+		if ref.IsCell() {
+			nodes = append(nodes, t.expandPrecedent(ref.Start, depth, path))
+			continue
+		}
+
+		// Only the formula cells of a range are expanded, constants would just repeat the range
+		n := &Node{Address: ref.String()}
+		for _, f := range t.w.formulas {
+			if !ref.Contains(f.Cell) {
+				continue
+			}
+			if t.nodes >= maxNodes {
+				n.Truncated = true
+				break
+			}
+			t.nodes++
+			n.Precedents = append(n.Precedents, t.expandPrecedent(f.Cell, depth, path))
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes
+}
+
+func (t *tracer) expandPrecedent(cell Cell, depth int, path map[string]bool) *Node {
+	n := t.w.node(cell)
+	key := cell.key()
+	switch {
+	case path[key]:
+		n.Circular = true
+	case n.Formula != "" && depth <= 1:
+		n.Truncated = true
+	default:
+		path[key] = true
+		n.Precedents = t.precedents(t.w.cells[key], depth-1, path)
+		delete(path, key)
+	}
+	return n
+}
+
+func (t *tracer) dependents(cell Cell, depth int, path map[string]bool) []*Node {
+	var nodes []*Node
+	for _, f := range t.w.formulas {
+		if !slices.ContainsFunc(f.refs, func(r Reference) bool { return r.Contains(cell) }) {
+			continue
+		}
+		if t.nodes >= maxNodes {
+			break
+		}
+		t.nodes++
+
+		n := t.w.node(f.Cell)
+		key := f.key()
+		switch {
+		case path[key]:
+			n.Circular = true
+		case depth <= 1:
+			n.Truncated = slices.ContainsFunc(t.w.formulas, func(o *cellInfo) bool {
+				return slices.ContainsFunc(o.refs, func(r Reference) bool { return r.Contains(f.Cell) })
+			})
+		default:
+			path[key] = true
+			n.Dependents = t.dependents(f.Cell, depth-1, path)
+			delete(path, key)
+		}
+		nodes = append(nodes, n)
+	}
+
+	slices.SortFunc(nodes, func(a, b *Node) int { return cmp.Compare(a.Address, b.Address) })
+	return nodes
+}
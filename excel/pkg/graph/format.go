@@ -0,0 +1,92 @@
+package graph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/gptscript-ai/tools/excel/pkg/format"
+	"github.com/gptscript-ai/tools/excel/pkg/global"
+)
+
+// FormatRange applies the formatting options to the range with the address, e.g. A1:D1, of a worksheet.
+// The SDK lacks the range format requests, so they are raw HTTP requests like the updates of the rows and columns.
+func FormatRange(ctx context.Context, workbookID, worksheetID, address string, options format.Options) error {
+	rangeURL := fmt.Sprintf("https://graph.microsoft.com/v1.0/me/drive/items/%s/workbook/worksheets/%s/range(address='%s')",
+		url.PathEscape(workbookID), url.PathEscape(worksheetID), url.PathEscape(address))
+
+	if options.NumberFormat != "" {
+		// The number format is set per cell, so the size of the range is needed first
+		data, err := rangeRequest(ctx, http.MethodGet, rangeURL+"?$select=rowCount,columnCount", nil)
+		if err != nil {
+			return fmt.Errorf("failed to get range: %w", err)
+		}
+		var size struct {
+			RowCount    int `json:"rowCount"`
+			ColumnCount int `json:"columnCount"`
+		}
+		if err := json.Unmarshal(data, &size); err != nil {
+			return fmt.Errorf("failed to unmarshal range: %w", err)
+		}
+
+		req, err := options.NumberFormatRequest(size.RowCount, size.ColumnCount)
+		if err != nil {
+			return err
+		}
+		if _, err := rangeRequest(ctx, req.Method, rangeURL, req.Body); err != nil {
+			return fmt.Errorf("failed to set number format: %w", err)
+		}
+	}
+
+	for _, req := range options.Requests() {
+		if _, err := rangeRequest(ctx, req.Method, rangeURL+req.Path, req.Body); err != nil {
+			return fmt.Errorf("failed to update %s: %w", req.Path[1:], err)
+		}
+	}
+	return nil
+}
+
+// rangeRequest sends a request with the JSON body, if any, and returns the body of the response.
+func rangeRequest(ctx context.Context, method, requestURL string, body map[string]any) ([]byte, error) {
+	var reqBody io.Reader
+	if body != nil {
+		bodyJSON, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal body: %w", err)
+		}
+		reqBody = bytes.NewReader(bodyJSON)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", "Bearer "+os.Getenv(global.CredentialEnv))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		var errorBody HTTPErrorBody
+		if err := json.Unmarshal(data, &errorBody); err == nil && errorBody.Error.Message != "" {
+			return nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, errorBody.Error.Message)
+		}
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return data, nil
+}
@@ -0,0 +1,32 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/gptscript-ai/tools/excel/pkg/util"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+)
+
+// CreateWorkbook uploads the workbook to the root folder of the user's OneDrive. If a file with the name already
+// exists, OneDrive renames the new workbook, so the returned name can differ from the name.
+func CreateWorkbook(ctx context.Context, c *msgraphsdkgo.GraphServiceClient, name string, data []byte) (WorkbookInfo, error) {
+	drive, err := c.Me().Drive().Get(ctx, nil)
+	if err != nil {
+		return WorkbookInfo{}, err
+	}
+
+	// The file doesn't exist yet, so it is addressed by its path in the root folder
+	driveID := util.Deref(drive.GetId())
+	content := c.Drives().ByDriveId(driveID).Items().ByDriveItemId("root").Content()
+	item, err := content.WithUrl(fmt.Sprintf("%s/drives/%s/items/root:/%s:/content?@microsoft.graph.conflictBehavior=rename",
+		c.BaseRequestBuilder.RequestAdapter.GetBaseUrl(), url.PathEscape(driveID), url.PathEscape(name))).Put(ctx, data, nil)
+	if err != nil {
+		return WorkbookInfo{}, err
+	}
+	return WorkbookInfo{
+		ID:   util.Deref(item.GetId()),
+		Name: util.Deref(item.GetName()),
+	}, nil
+}
@@ -116,6 +116,8 @@ func CreateWorksheet(ctx context.Context, c *msgraphsdkgo.GraphServiceClient, wo
 
 type WorksheetInfo struct {
 	ID, Name, WorkbookID string
+	// Position is the zero-based position of the worksheet in the workbook
+	Position int32
 }
 
 func ListWorksheetsInWorkbook(ctx context.Context, c *msgraphsdkgo.GraphServiceClient, workbookID string) ([]WorksheetInfo, error) {
@@ -135,11 +137,45 @@ func ListWorksheetsInWorkbook(ctx context.Context, c *msgraphsdkgo.GraphServiceC
 			ID:         util.Deref(sheet.GetId()),
 			Name:       util.Deref(sheet.GetName()),
 			WorkbookID: workbookID,
+			Position:   util.Deref(sheet.GetPosition()),
 		})
 	}
 	return infos, nil
 }
 
+// UpdateWorksheet renames the worksheet and/or moves it to the zero-based position. A nil name or position is left
+// unchanged. Excel moves the other worksheets to make room for the worksheet.
+func UpdateWorksheet(ctx context.Context, c *msgraphsdkgo.GraphServiceClient, workbookID, worksheetID string, name *string, position *int32) (WorksheetInfo, error) {
+	drive, err := c.Me().Drive().Get(ctx, nil)
+	if err != nil {
+		return WorksheetInfo{}, err
+	}
+
+	requestBody := models.NewWorkbookWorksheet()
+	requestBody.SetName(name)
+	requestBody.SetPosition(position)
+	sheet, err := c.Drives().ByDriveId(util.Deref(drive.GetId())).Items().ByDriveItemId(workbookID).Workbook().Worksheets().ByWorkbookWorksheetId(worksheetID).Patch(ctx, requestBody, nil)
+	if err != nil {
+		return WorksheetInfo{}, err
+	}
+	return WorksheetInfo{
+		ID:         util.Deref(sheet.GetId()),
+		Name:       util.Deref(sheet.GetName()),
+		WorkbookID: workbookID,
+		Position:   util.Deref(sheet.GetPosition()),
+	}, nil
+}
+
+// DeleteWorksheet deletes the worksheet and its data. Excel doesn't delete the last visible worksheet of a workbook.
+func DeleteWorksheet(ctx context.Context, c *msgraphsdkgo.GraphServiceClient, workbookID, worksheetID string) error {
+	drive, err := c.Me().Drive().Get(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	return c.Drives().ByDriveId(util.Deref(drive.GetId())).Items().ByDriveItemId(workbookID).Workbook().Worksheets().ByWorkbookWorksheetId(worksheetID).Delete(ctx, nil)
+}
+
 func GetWorksheetData(ctx context.Context, c *msgraphsdkgo.GraphServiceClient, workbookID, worksheetID string) ([][]any, models.WorkbookRangeable, error) {
 	drive, err := c.Me().Drive().Get(ctx, nil)
 	if err != nil {
@@ -163,6 +199,39 @@ func GetWorksheetData(ctx context.Context, c *msgraphsdkgo.GraphServiceClient, w
 	return data, usedRange, nil
 }
 
+// GetWorksheetFormulas returns the address, formulas and values of the used range of a worksheet.
+// For cells without a formula, Excel returns the value in place of the formula.
+func GetWorksheetFormulas(ctx context.Context, c *msgraphsdkgo.GraphServiceClient, workbookID, worksheetID string) (string, [][]any, [][]any, error) {
+	drive, err := c.Me().Drive().Get(ctx, nil)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	usedRange, err := c.Drives().ByDriveId(util.Deref(drive.GetId())).Items().ByDriveItemId(workbookID).Workbook().Worksheets().ByWorkbookWorksheetId(worksheetID).UsedRange().Get(ctx, nil)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	var formulas, values [][]any
+	for _, v := range []struct {
+		node serialization.UntypedNodeable
+		dest *[][]any
+	}{
+		{usedRange.GetFormulas(), &formulas},
+		{usedRange.GetValues(), &values},
+	} {
+		result, err := serialization.SerializeToJson(v.node)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		if err = json.Unmarshal(result, v.dest); err != nil {
+			return "", nil, nil, fmt.Errorf("failed to unmarshal data: %w", err)
+		}
+	}
+
+	return util.Deref(usedRange.GetAddress()), formulas, values, nil
+}
+
 func GetWorksheetColumnHeaders(ctx context.Context, c *msgraphsdkgo.GraphServiceClient, workbookID, worksheetID string) ([][]any, models.WorkbookRangeable, error) {
 	drive, err := c.Me().Drive().Get(ctx, nil)
 	if err != nil {
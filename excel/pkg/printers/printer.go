@@ -3,27 +3,22 @@ package printers
 import (
 	"fmt"
 
+	"github.com/gptscript-ai/tools/common/locale"
 	"github.com/gptscript-ai/tools/excel/pkg/graph"
 )
 
-func PrintWorkbookInfos(infos []graph.WorkbookInfo) {
-	for _, info := range infos {
-		fmt.Printf("Name: %s\n", info.Name)
-		fmt.Printf("  ID: %s\n", info.ID)
-	}
+func WorkbookInfoToString(info graph.WorkbookInfo) string {
+	loc := locale.FromEnv()
+	return fmt.Sprintf("%s: %s\n  %s: %s\n", loc.T("Name"), info.Name, loc.T("ID"), info.ID)
 }
 
-func PrintWorksheetInfos(infos []graph.WorksheetInfo) {
-	for _, info := range infos {
-		fmt.Printf("Name: %s\n", info.Name)
-		fmt.Printf("  ID: %s\n", info.ID)
-		fmt.Printf("  Workbook ID: %s\n", info.WorkbookID)
-	}
+func WorksheetInfoToString(info graph.WorksheetInfo) string {
+	loc := locale.FromEnv()
+	// Positions are shown one-based, the way the worksheet tabs are counted
+	return fmt.Sprintf("%s: %s\n  %s: %s\n  %s: %s\n  %s: %d\n", loc.T("Name"), info.Name, loc.T("ID"), info.ID, loc.T("Workbook ID"), info.WorkbookID, loc.T("Position"), info.Position+1)
 }
 
-func PrintWorksheetTableInfos(infos []graph.Table) {
-	for _, info := range infos {
-		fmt.Printf("Name: %s\n", info.Name)
-		fmt.Printf("  Table ID: %s\n", info.ID)
-	}
+func WorksheetTableInfoToString(info graph.Table) string {
+	loc := locale.FromEnv()
+	return fmt.Sprintf("%s: %s\n  %s: %s\n", loc.T("Name"), info.Name, loc.T("Table ID"), info.ID)
 }
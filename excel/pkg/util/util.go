@@ -1,6 +1,8 @@
 package util
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -35,3 +37,13 @@ func ColumnNumberToLetters(col int) string {
 	}
 	return res
 }
+
+// ParsePosition parses a one-based worksheet position (i.e. 1 for the first worksheet) into the zero-based position
+// that Graph expects (i.e. 0).
+func ParsePosition(position string) (int32, error) {
+	n, err := strconv.ParseInt(strings.TrimSpace(position), 10, 32)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid position %q, must be a number starting at 1 for the first worksheet", position)
+	}
+	return int32(n - 1), nil
+}
@@ -31,3 +31,16 @@ func TestConversions(t *testing.T) {
 		}
 	}
 }
+
+func TestParsePosition(t *testing.T) {
+	for position, want := range map[string]int32{"1": 0, " 3 ": 2, "255": 254} {
+		if got, err := ParsePosition(position); err != nil || got != want {
+			t.Errorf("ParsePosition(%q) = %v, %v, want %v", position, got, err, want)
+		}
+	}
+	for _, position := range []string{"", "0", "-1", "first", "1.5", "4294967296"} {
+		if _, err := ParsePosition(position); err == nil {
+			t.Errorf("ParsePosition(%q) should fail", position)
+		}
+	}
+}
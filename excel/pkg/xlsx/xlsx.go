@@ -0,0 +1,165 @@
+// Package xlsx writes empty Excel workbooks in the Office Open XML format, so that new workbooks can be created
+// without a template. Graph can only create worksheets in existing workbooks.
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultSheet is the name of the worksheet of a workbook created without names.
+const DefaultSheet = "Sheet1"
+
+// maxSheetNameLength is the maximum length of a worksheet name in Excel.
+const maxSheetNameLength = 31
+
+// FileName returns the file name of a workbook with the .xlsx extension, e.g. "Budget 2025.xlsx" for "Budget 2025".
+// The name can't contain a folder, or characters that OneDrive doesn't allow in file names.
+func FileName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if !strings.HasSuffix(strings.ToLower(name), ".xlsx") {
+		name += ".xlsx"
+	}
+	if strings.TrimSpace(name[:len(name)-len(".xlsx")]) == "" {
+		return "", fmt.Errorf("workbook name is required")
+	}
+	if strings.ContainsAny(name, `\/:*?"<>|`) {
+		return "", fmt.Errorf(`workbook name %q must not contain any of \ / : * ? " < > |`, name)
+	}
+	return name, nil
+}
+
+// SheetNames parses the pipe-separated (`|`) worksheet names and validates them the way Excel does. Without names, the
+// workbook has a single worksheet named DefaultSheet.
+func SheetNames(value string) ([]string, error) {
+	var names []string
+	for _, name := range strings.Split(value, "|") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if err := ValidSheetName(name); err != nil {
+			return nil, err
+		}
+		for _, existing := range names {
+			if strings.EqualFold(existing, name) {
+				return nil, fmt.Errorf("duplicate worksheet name %q", name)
+			}
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return []string{DefaultSheet}, nil
+	}
+	return names, nil
+}
+
+// ValidSheetName returns an error if Excel doesn't accept the worksheet name, which must already be trimmed.
+func ValidSheetName(name string) error {
+	if utf8.RuneCountInString(name) > maxSheetNameLength {
+		return fmt.Errorf("worksheet name %q is longer than %d characters", name, maxSheetNameLength)
+	}
+	if strings.ContainsAny(name, `\/?*[]:`) {
+		return fmt.Errorf(`worksheet name %q must not contain any of \ / ? * [ ] :`, name)
+	}
+	if strings.HasPrefix(name, "'") || strings.HasSuffix(name, "'") {
+		return fmt.Errorf("worksheet name %q must not start or end with an apostrophe", name)
+	}
+	if strings.EqualFold(name, "History") {
+		return fmt.Errorf("worksheet name %q is reserved by Excel", name)
+	}
+	return nil
+}
+
+// New returns an .xlsx file with an empty worksheet for each of the names, in order. The names must be valid, see
+// SheetNames.
+func New(sheets []string) ([]byte, error) {
+	if len(sheets) == 0 {
+		sheets = []string{DefaultSheet}
+	}
+
+	var (
+		workbook, rels, types strings.Builder
+		buf                   bytes.Buffer
+	)
+	w := zip.NewWriter(&buf)
+	for i, name := range sheets {
+		// EscapeText also escapes quotes, so the name can be used as an attribute
+		var escaped bytes.Buffer
+		if err := xml.EscapeText(&escaped, []byte(name)); err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&workbook, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escaped.String(), i+1, i+1)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="%s/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, relationshipTypes, i+1)
+		fmt.Fprintf(&types, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+
+		if err := writePart(w, fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), worksheetXML); err != nil {
+			return nil, err
+		}
+	}
+	// The styles are the last relationship of the workbook, after the worksheets
+	fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="%s/styles" Target="styles.xml"/>`, len(sheets)+1, relationshipTypes)
+
+	for _, part := range []struct {
+		name, content string
+	}{
+		{"[Content_Types].xml", fmt.Sprintf(contentTypesXML, types.String())},
+		{"_rels/.rels", rootRelsXML},
+		{"xl/workbook.xml", fmt.Sprintf(workbookXML, workbook.String())},
+		{"xl/_rels/workbook.xml.rels", fmt.Sprintf(workbookRelsXML, rels.String())},
+		{"xl/styles.xml", stylesXML},
+	} {
+		if err := writePart(w, part.name, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write workbook: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func writePart(w *zip.Writer, name, content string) error {
+	f, err := w.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if _, err = f.Write([]byte(xml.Header + content)); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+const relationshipTypes = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+
+const contentTypesXML = `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`%s</Types>`
+
+const rootRelsXML = `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="` + relationshipTypes + `/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const workbookXML = `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+	`xmlns:r="` + relationshipTypes + `"><sheets>%s</sheets></workbook>`
+
+const workbookRelsXML = `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">%s</Relationships>`
+
+const worksheetXML = `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData/></worksheet>`
+
+// stylesXML has the single default font, fill, border and cell format that Excel expects in every workbook.
+const stylesXML = `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="1"><font><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/></cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
@@ -0,0 +1,128 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFileName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"Budget 2025":      "Budget 2025.xlsx",
+		" report.XLSX ":    "report.XLSX",
+		"Q1 numbers.v2":    "Q1 numbers.v2.xlsx",
+		"Résumé des coûts": "Résumé des coûts.xlsx",
+	} {
+		if got, err := FileName(name); err != nil || got != expected {
+			t.Errorf("%q: expected %q, got %q, %v", name, expected, got, err)
+		}
+	}
+
+	for _, name := range []string{"", " .xlsx", "reports/q1", `a:b`, "what?"} {
+		if _, err := FileName(name); err == nil {
+			t.Errorf("%q: expected an error", name)
+		}
+	}
+}
+
+func TestSheetNames(t *testing.T) {
+	for value, expected := range map[string][]string{
+		"":                           {DefaultSheet},
+		" | ":                        {DefaultSheet},
+		"Summary| Data 2025 |Notes ": {"Summary", "Data 2025", "Notes"},
+		"Q&A|Bob's <notes>":          {"Q&A", "Bob's <notes>"},
+	} {
+		got, err := SheetNames(value)
+		if err != nil || !slices.Equal(got, expected) {
+			t.Errorf("%q: expected %q, got %q, %v", value, expected, got, err)
+		}
+	}
+
+	for value, message := range map[string]string{
+		"Data|data":                         "duplicate",
+		"Q1/Q2":                             "must not contain",
+		"[draft]":                           "must not contain",
+		"'quoted'":                          "apostrophe",
+		"history":                           "reserved",
+		"A worksheet name that is too long": "longer than 31",
+	} {
+		if _, err := SheetNames(value); err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("%q: expected an error containing %q, got %v", value, message, err)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	data, err := New([]string{"Summary", `Q&A "draft"`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := map[string][]byte{}
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts[f.Name], err = io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels",
+		"xl/styles.xml", "xl/worksheets/sheet1.xml", "xl/worksheets/sheet2.xml"} {
+		content, ok := parts[name]
+		if !ok {
+			t.Fatalf("expected part %s, got %d parts", name, len(parts))
+		}
+		// Every part must be well-formed XML
+		d := xml.NewDecoder(bytes.NewReader(content))
+		for {
+			if _, err := d.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+		}
+	}
+
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := xml.Unmarshal(parts["xl/workbook.xml"], &workbook); err != nil {
+		t.Fatal(err)
+	}
+	if len(workbook.Sheets) != 2 || workbook.Sheets[0].Name != "Summary" || workbook.Sheets[1].Name != `Q&A "draft"` || workbook.Sheets[1].ID != "rId2" {
+		t.Fatalf("unexpected worksheets: %+v", workbook.Sheets)
+	}
+
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := xml.Unmarshal(parts["xl/_rels/workbook.xml.rels"], &rels); err != nil {
+		t.Fatal(err)
+	}
+	var targets []string
+	for _, rel := range rels.Relationships {
+		targets = append(targets, rel.ID+"="+rel.Target)
+	}
+	if expected := []string{"rId1=worksheets/sheet1.xml", "rId2=worksheets/sheet2.xml", "rId3=styles.xml"}; !slices.Equal(targets, expected) {
+		t.Fatalf("expected relationships %v, got %v", expected, targets)
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+const boxAPI = "https://api.box.com/2.0"
+
+// rootFolderID is the ID of the "All Files" folder
+const rootFolderID = "0"
+
+const (
+	itemFields = "id,type,name,size,modified_at,extension,item_status,path_collection"
+	listFields = "id,type,name,size,modified_at,extension"
+)
+
+// errNotFound is returned for items that don't exist (anymore) or are in the trash
+var errNotFound = errors.New("box item not found")
+
+type Item struct {
+	Type           string         `json:"type"`
+	ID             string         `json:"id"`
+	Name           string         `json:"name"`
+	Size           int64          `json:"size"`
+	ModifiedAt     string         `json:"modified_at"`
+	Extension      string         `json:"extension"`
+	ItemStatus     string         `json:"item_status"`
+	PathCollection PathCollection `json:"path_collection"`
+}
+
+// PathCollection are the ancestor folders of an item, starting with "All Files"
+type PathCollection struct {
+	Entries []Item `json:"entries"`
+}
+
+func (i Item) IsFile() bool {
+	return i.Type == "file"
+}
+
+func (i Item) IsFolder() bool {
+	return i.Type == "folder"
+}
+
+// IsActive is false for items in the trash or deleted
+func (i Item) IsActive() bool {
+	return i.ItemStatus == "" || i.ItemStatus == "active"
+}
+
+type Event struct {
+	EventType string `json:"event_type"`
+	Source    *Item  `json:"source"`
+}
+
+type eventsResult struct {
+	Entries            []Event         `json:"entries"`
+	NextStreamPosition json.RawMessage `json:"next_stream_position"`
+}
+
+type boxClient struct {
+	token string
+}
+
+func (c boxClient) get(ctx context.Context, endpoint string, query url.Values) (io.ReadCloser, error) {
+	u := boxAPI + endpoint
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	// Downloads are redirected to dl.boxcloud.com, which is followed by the default client
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		var apiErr struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: %s", errNotFound, endpoint)
+		}
+		data, _ := io.ReadAll(resp.Body)
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+			return nil, fmt.Errorf("box request %s failed with status %d: %s (%s)", endpoint, resp.StatusCode, apiErr.Message, apiErr.Code)
+		}
+		return nil, fmt.Errorf("box request %s failed with status %d: %s", endpoint, resp.StatusCode, string(data))
+	}
+	return resp.Body, nil
+}
+
+func (c boxClient) getJSON(ctx context.Context, endpoint string, query url.Values, v any) error {
+	body, err := c.get(ctx, endpoint, query)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return json.NewDecoder(body).Decode(v)
+}
+
+func (c boxClient) GetFolder(ctx context.Context, id string) (Item, error) {
+	var item Item
+	return item, c.getJSON(ctx, "/folders/"+id, url.Values{"fields": {itemFields}}, &item)
+}
+
+func (c boxClient) GetFile(ctx context.Context, id string) (Item, error) {
+	var item Item
+	return item, c.getJSON(ctx, "/files/"+id, url.Values{"fields": {itemFields}}, &item)
+}
+
+// ListFolder returns the files and folders directly in the folder.
+func (c boxClient) ListFolder(ctx context.Context, id string) ([]Item, error) {
+	var (
+		items  []Item
+		marker string
+	)
+	for {
+		query := url.Values{
+			"fields":    {listFields},
+			"limit":     {"1000"},
+			"usemarker": {"true"},
+		}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+
+		var result struct {
+			Entries    []Item `json:"entries"`
+			NextMarker string `json:"next_marker"`
+		}
+		if err := c.getJSON(ctx, "/folders/"+id+"/items", query, &result); err != nil {
+			return nil, err
+		}
+		items = append(items, result.Entries...)
+
+		if result.NextMarker == "" {
+			return items, nil
+		}
+		marker = result.NextMarker
+	}
+}
+
+// CurrentStreamPosition returns the position of the latest event, changes after it are returned by ListEvents.
+func (c boxClient) CurrentStreamPosition(ctx context.Context) (string, error) {
+	var result eventsResult
+	if err := c.getJSON(ctx, "/events", url.Values{"stream_position": {"now"}, "stream_type": {"changes"}}, &result); err != nil {
+		return "", err
+	}
+	return streamPosition(result.NextStreamPosition), nil
+}
+
+// ListEvents returns all events of the user's "changes" stream since the stream position, and the new position.
+func (c boxClient) ListEvents(ctx context.Context, position string) ([]Event, string, error) {
+	var events []Event
+	for {
+		var result eventsResult
+		if err := c.getJSON(ctx, "/events", url.Values{
+			"stream_position": {position},
+			"stream_type":     {"changes"},
+			"limit":           {"500"},
+		}, &result); err != nil {
+			return nil, "", err
+		}
+		events = append(events, result.Entries...)
+
+		next := streamPosition(result.NextStreamPosition)
+		if len(result.Entries) == 0 || next == position {
+			return events, next, nil
+		}
+		position = next
+	}
+}
+
+// streamPosition handles that the position is returned as a string or a number, depending on the request
+func streamPosition(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var n json.Number
+	if json.Unmarshal(raw, &n) == nil {
+		return n.String()
+	}
+	return string(raw)
+}
+
+// Download returns the content of the file.
+func (c boxClient) Download(ctx context.Context, id string) ([]byte, error) {
+	body, err := c.get(ctx, "/files/"+id+"/content", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+// fileURL returns the link to the file in the Box web app
+func fileURL(id string) string {
+	return "https://app.box.com/file/" + url.PathEscape(id)
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gptscript-ai/knowledge/pkg/datasource"
+)
+
+const boxNoteExtension = "boxnote"
+
+// boxNoteToMarkdown converts a Box Note to Markdown, so that knowledge can load it. Notes created before 2022 only
+// contain the plain text.
+func boxNoteToMarkdown(data []byte) (string, error) {
+	var note struct {
+		// Doc is a ProseMirror document
+		Doc   *datasource.RichTextNode `json:"doc"`
+		AText *struct {
+			Text string `json:"text"`
+		} `json:"atext"`
+	}
+	if err := json.Unmarshal(data, &note); err != nil {
+		return "", fmt.Errorf("failed to parse box note: %w", err)
+	}
+
+	switch {
+	case note.Doc != nil:
+		return datasource.RichTextToMarkdown(note.Doc) + "\n", nil
+	case note.AText != nil:
+		return note.AText.Text, nil
+	default:
+		return "", fmt.Errorf("unknown box note format")
+	}
+}
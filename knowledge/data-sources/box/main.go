@@ -0,0 +1,275 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/knowledge/pkg/datasource"
+	"github.com/gptscript-ai/knowledge/pkg/datasource/indexstore"
+	"github.com/sirupsen/logrus"
+)
+
+// We only sync files that are less than 50 MB, as most of the bigger files won't be supported by knowledge
+const maxFileSize = 1024 * 1024 * 50
+
+// errFolderChanged is returned by the incremental sync if a folder was moved, renamed or deleted, which changes the
+// paths of all files below it - a full sync is needed then
+var errFolderChanged = fmt.Errorf("box folder changed: %w", datasource.ErrFullSyncRequired)
+
+type MetadataInput struct {
+	BoxConfig *BoxConfig `json:"boxConfig,omitempty"`
+}
+
+type BoxConfig struct {
+	// FolderIDs are the IDs of the folders to sync (the number at the end of the folder URL), all files are synced if empty
+	FolderIDs []string `json:"folderIds"`
+}
+
+type RootState struct {
+	Name string `json:"name"`
+}
+
+// boxSource lists the files below the configured folders. Changes are listed with the event stream of the user, the
+// stream position is the cursor.
+type boxSource struct {
+	box    boxClient
+	logErr *logrus.Logger
+	// roots are the synced folders by ID
+	roots map[string]RootState
+}
+
+func main() {
+	logOut := logrus.New()
+	logOut.SetOutput(os.Stdout)
+	logOut.SetFormatter(&logrus.JSONFormatter{})
+	logErr := logrus.New()
+	logErr.SetOutput(os.Stderr)
+
+	ctx := context.Background()
+	gptscriptClient, err := gptscript.NewGPTScript()
+	if err != nil {
+		logOut.WithError(fmt.Errorf("failed to create gptscript client, error: %w", err)).Error()
+		os.Exit(0)
+	}
+
+	inputData := os.Getenv("GPTSCRIPT_INPUT")
+	input := MetadataInput{}
+
+	if err := json.Unmarshal([]byte(inputData), &input); err != nil {
+		logOut.WithError(fmt.Errorf("failed to unmarshal input data, error: %w", err)).Error()
+		os.Exit(0)
+	}
+	if input.BoxConfig == nil {
+		input.BoxConfig = &BoxConfig{}
+	}
+
+	source := &boxSource{
+		box:    boxClient{token: os.Getenv("BOX_OAUTH_TOKEN")},
+		logErr: logErr,
+	}
+	if err := source.resolveRoots(ctx, input.BoxConfig); err != nil {
+		logOut.WithError(fmt.Errorf("failed to sync box, error: %w", err)).Error()
+		os.Exit(0)
+	}
+	// Renamed roots change the paths of all files, so they are part of the config
+	roots, err := json.Marshal(source.roots)
+	if err != nil {
+		logOut.WithError(fmt.Errorf("failed to marshal roots, error: %w", err)).Error()
+		os.Exit(0)
+	}
+
+	workspace := &datasource.GPTScriptWorkspace{Client: gptscriptClient}
+	store, closeStore, err := indexstore.FromEnv(ctx, workspace)
+	if err != nil {
+		logOut.WithError(fmt.Errorf("failed to open sync state store, error: %w", err)).Error()
+		os.Exit(0)
+	}
+	defer closeStore()
+
+	syncer := datasource.NewSyncer(source, store, workspace, datasource.Options{
+		Config: string(roots),
+	})
+	if _, err := syncer.Sync(ctx); err != nil {
+		logOut.WithError(fmt.Errorf("failed to sync box, error: %w", err)).Error()
+		os.Exit(0)
+	}
+}
+
+func (s *boxSource) resolveRoots(ctx context.Context, config *BoxConfig) error {
+	s.roots = map[string]RootState{}
+	for _, id := range config.FolderIDs {
+		id = strings.TrimSpace(id)
+		if id == "" || id == rootFolderID {
+			continue
+		}
+		folder, err := s.box.GetFolder(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get folder %q: %w", id, err)
+		}
+		s.roots[folder.ID] = RootState{Name: folder.Name}
+	}
+	if len(s.roots) == 0 {
+		// The name "All Files" is not used as a path prefix
+		s.roots[rootFolderID] = RootState{}
+	}
+	return nil
+}
+
+func (s *boxSource) Name() string {
+	return "box"
+}
+
+// List walks all roots, one page per folder
+func (s *boxSource) List(ctx context.Context, fn func([]datasource.Item) error) error {
+	for id, root := range s.roots {
+		if err := s.walk(ctx, id, root.Name, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *boxSource) walk(ctx context.Context, folderID, dir string, fn func([]datasource.Item) error) error {
+	entries, err := s.box.ListFolder(ctx, folderID)
+	if err != nil {
+		return fmt.Errorf("failed to list folder %q: %w", folderID, err)
+	}
+
+	var items []datasource.Item
+	var folders []Item
+	for _, entry := range entries {
+		switch {
+		case entry.IsFolder():
+			folders = append(folders, entry)
+		case entry.IsFile():
+			if item, ok := s.item(entry, path.Join(dir, entry.Name)); ok {
+				items = append(items, item)
+			}
+		}
+	}
+	if err := fn(items); err != nil {
+		return err
+	}
+
+	for _, folder := range folders {
+		if err := s.walk(ctx, folder.ID, path.Join(dir, folder.Name), fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Cursor returns the current position of the event stream
+func (s *boxSource) Cursor(ctx context.Context) (string, error) {
+	return s.box.CurrentStreamPosition(ctx)
+}
+
+// Changes lists the events since the stream position. Every changed file is looked up again, as the events may be
+// outdated (e.g. a file that was uploaded and trashed since).
+func (s *boxSource) Changes(ctx context.Context, cursor string, fn func([]datasource.Change, string) error) error {
+	events, position, err := s.box.ListEvents(ctx, cursor)
+	if err != nil {
+		// Stream positions are only valid for a limited time
+		return fmt.Errorf("failed to list events: %v: %w", err, datasource.ErrFullSyncRequired)
+	}
+
+	var fileIDs []string
+	changed := map[string]struct{}{}
+	for _, event := range events {
+		if event.Source == nil {
+			continue
+		}
+		if event.Source.IsFolder() {
+			// New folders are empty, their files have their own events
+			if event.EventType != "ITEM_CREATE" {
+				return errFolderChanged
+			}
+			continue
+		}
+		if _, ok := changed[event.Source.ID]; event.Source.IsFile() && !ok {
+			changed[event.Source.ID] = struct{}{}
+			fileIDs = append(fileIDs, event.Source.ID)
+		}
+	}
+
+	var changes []datasource.Change
+	for _, id := range fileIDs {
+		entry, err := s.box.GetFile(ctx, id)
+		if errors.Is(err, errNotFound) {
+			changes = append(changes, datasource.Change{Item: datasource.Item{ID: id}, Deleted: true})
+			continue
+		} else if err != nil {
+			return fmt.Errorf("failed to get file %q: %w", id, err)
+		}
+
+		filePath, ok := s.filePath(entry)
+		if !ok || !entry.IsActive() {
+			changes = append(changes, datasource.Change{Item: datasource.Item{ID: id}, Deleted: true})
+			continue
+		}
+		item, ok := s.item(entry, filePath)
+		changes = append(changes, datasource.Change{Item: item, Deleted: !ok})
+	}
+
+	return fn(changes, position)
+}
+
+// Fetch downloads the file, Box Notes are converted to Markdown
+func (s *boxSource) Fetch(ctx context.Context, item datasource.Item) ([]byte, error) {
+	data, err := s.box.Download(ctx, item.ID)
+	if err != nil {
+		return nil, err
+	}
+	if item.Metadata["extension"] != boxNoteExtension {
+		return data, nil
+	}
+
+	markdown, err := boxNoteToMarkdown(data)
+	if err != nil {
+		return nil, datasource.Permanent(fmt.Errorf("failed to convert %s: %w", item.Path, err))
+	}
+	return []byte(markdown), nil
+}
+
+// filePath returns the workspace path of the file: the path below the synced folder, prefixed with the name of the
+// folder. It returns false if the file isn't in any of the synced folders.
+func (s *boxSource) filePath(entry Item) (string, bool) {
+	ancestors := entry.PathCollection.Entries
+	for i, folder := range ancestors {
+		root, ok := s.roots[folder.ID]
+		if !ok {
+			continue
+		}
+		parts := []string{root.Name}
+		for _, f := range ancestors[i+1:] {
+			parts = append(parts, f.Name)
+		}
+		return path.Join(append(parts, entry.Name)...), true
+	}
+	return "", false
+}
+
+// item returns the item of the file, it returns false if the file is too big
+func (s *boxSource) item(entry Item, filePath string) (datasource.Item, bool) {
+	if entry.Extension == boxNoteExtension {
+		filePath = strings.TrimSuffix(filePath, "."+boxNoteExtension) + ".md"
+	}
+	item := datasource.Item{
+		ID:       entry.ID,
+		Path:     filePath,
+		URL:      fileURL(entry.ID),
+		Version:  entry.ModifiedAt,
+		Metadata: map[string]string{"extension": entry.Extension},
+	}
+	if entry.Size >= maxFileSize {
+		s.logErr.Infof("Skipping %s because it is larger than 50 MB", filePath)
+		return item, false
+	}
+	return item, true
+}
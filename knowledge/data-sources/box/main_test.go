@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestStreamPosition(t *testing.T) {
+	for raw, expected := range map[string]string{
+		`"1152922976252290886"`: "1152922976252290886",
+		`1152922976252290886`:   "1152922976252290886",
+		`now`:                   "now",
+	} {
+		if position := streamPosition(json.RawMessage(raw)); position != expected {
+			t.Errorf("streamPosition(%s): expected %s, got %s", raw, expected, position)
+		}
+	}
+}
+
+func TestBoxNoteToMarkdown(t *testing.T) {
+	markdown, err := boxNoteToMarkdown([]byte(`{"doc": {"type": "doc", "content": [
+		{"type": "heading", "attrs": {"level": 1}, "content": [{"type": "text", "text": "Meeting notes"}]},
+		{"type": "paragraph", "content": [{"type": "text", "text": "Ship it", "marks": [{"type": "strong"}]}]}
+	]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if markdown != "# Meeting notes\n\n**Ship it**\n" {
+		t.Errorf("unexpected markdown %q", markdown)
+	}
+
+	// Notes created before 2022 only have the text
+	markdown, err = boxNoteToMarkdown([]byte(`{"atext": {"text": "Old note\n"}}`))
+	if err != nil || markdown != "Old note\n" {
+		t.Errorf("unexpected markdown %q, error: %v", markdown, err)
+	}
+
+	if _, err := boxNoteToMarkdown([]byte(`{"version": 1}`)); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestFilePath(t *testing.T) {
+	s := &boxSource{roots: map[string]RootState{"10": {Name: "Projects"}}}
+	entry := Item{Type: "file", ID: "1", Name: "plan.docx", PathCollection: PathCollection{Entries: []Item{
+		{ID: "0", Name: "All Files"}, {ID: "10", Name: "Projects"}, {ID: "11", Name: "2024"},
+	}}}
+	if filePath, ok := s.filePath(entry); !ok || filePath != "Projects/2024/plan.docx" {
+		t.Errorf("unexpected path %q", filePath)
+	}
+
+	entry.PathCollection.Entries = []Item{{ID: "0", Name: "All Files"}, {ID: "20", Name: "Other"}}
+	if _, ok := s.filePath(entry); ok {
+		t.Error("expected files outside of the synced folders to be skipped")
+	}
+
+	// All files are synced without a prefix
+	s.roots = map[string]RootState{rootFolderID: {}}
+	if filePath, ok := s.filePath(entry); !ok || filePath != "Other/plan.docx" {
+		t.Errorf("unexpected path %q", filePath)
+	}
+}
+
+func TestItem(t *testing.T) {
+	s := &boxSource{logErr: logrus.New()}
+	s.logErr.SetOutput(io.Discard)
+
+	item, ok := s.item(Item{ID: "1", Name: "notes.boxnote", Extension: boxNoteExtension, ModifiedAt: "v1"}, "Projects/notes.boxnote")
+	if !ok || item.Path != "Projects/notes.md" || item.URL != "https://app.box.com/file/1" || item.Version != "v1" {
+		t.Errorf("unexpected item %+v", item)
+	}
+
+	if _, ok := s.item(Item{ID: "2", Name: "video.mp4", Size: maxFileSize}, "video.mp4"); ok {
+		t.Error("expected files over the size limit to be skipped")
+	}
+}
+
+func TestItemStatus(t *testing.T) {
+	for status, active := range map[string]bool{"": true, "active": true, "trashed": false, "deleted": false} {
+		if (Item{ItemStatus: status}).IsActive() != active {
+			t.Errorf("expected IsActive() of %q to be %v", status, active)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	accessibleResourcesURL = "https://api.atlassian.com/oauth/token/accessible-resources"
+	confluenceAPI          = "https://api.atlassian.com/ex/confluence/%s/wiki/rest/api"
+
+	pageLimit = 100
+)
+
+type Site struct {
+	ID   string `json:"id"`
+	URL  string `json:"url"`
+	Name string `json:"name"`
+}
+
+type Space struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+type Page struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Version struct {
+		When   string `json:"when"`
+		Number int    `json:"number"`
+	} `json:"version"`
+	Ancestors []struct {
+		ID    string `json:"id"`
+		Title string `json:"title"`
+	} `json:"ancestors"`
+	Body struct {
+		Storage struct {
+			Value string `json:"value"`
+		} `json:"storage"`
+	} `json:"body"`
+	Metadata struct {
+		Labels struct {
+			Results []struct {
+				Name string `json:"name"`
+			} `json:"results"`
+		} `json:"labels"`
+	} `json:"metadata"`
+	Links struct {
+		WebUI string `json:"webui"`
+	} `json:"_links"`
+}
+
+func (p Page) Labels() []string {
+	labels := make([]string, 0, len(p.Metadata.Labels.Results))
+	for _, label := range p.Metadata.Labels.Results {
+		labels = append(labels, label.Name)
+	}
+	return labels
+}
+
+type confluenceClient struct {
+	token string
+	// baseURL is the REST API of the Confluence site, set by selectSite
+	baseURL string
+	site    Site
+}
+
+func (c *confluenceClient) get(ctx context.Context, rawURL string, query url.Values, v any) error {
+	if len(query) > 0 {
+		rawURL += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("confluence request %s failed with status %d: %s", strings.TrimPrefix(rawURL, c.baseURL), resp.StatusCode, string(body))
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// selectSite picks the Confluence site the token has access to. If siteURL is set the site with that URL is used,
+// otherwise the token must only have access to a single site.
+func (c *confluenceClient) selectSite(ctx context.Context, siteURL string) error {
+	var sites []Site
+	if err := c.get(ctx, accessibleResourcesURL, nil, &sites); err != nil {
+		return err
+	}
+
+	siteURL = strings.TrimSuffix(strings.TrimSuffix(strings.TrimSpace(siteURL), "/"), "/wiki")
+	var candidates []Site
+	for _, site := range sites {
+		if siteURL == "" || strings.EqualFold(strings.TrimSuffix(site.URL, "/"), siteURL) {
+			candidates = append(candidates, site)
+		}
+	}
+
+	switch {
+	case len(candidates) == 0 && siteURL != "":
+		return fmt.Errorf("no access to Confluence site %s", siteURL)
+	case len(candidates) == 0:
+		return fmt.Errorf("no access to any Confluence site")
+	case len(candidates) > 1:
+		urls := make([]string, 0, len(candidates))
+		for _, site := range candidates {
+			urls = append(urls, site.URL)
+		}
+		return fmt.Errorf("access to multiple Confluence sites, set the site to sync: %s", strings.Join(urls, ", "))
+	}
+
+	c.site = candidates[0]
+	c.baseURL = fmt.Sprintf(confluenceAPI, c.site.ID)
+	return nil
+}
+
+// PageURL returns the link to the page in the Confluence UI.
+func (c *confluenceClient) PageURL(page Page) string {
+	return strings.TrimSuffix(c.site.URL, "/") + "/wiki" + page.Links.WebUI
+}
+
+func (c *confluenceClient) GetSpace(ctx context.Context, key string) (Space, error) {
+	var space Space
+	return space, c.get(ctx, c.baseURL+"/space/"+url.PathEscape(key), nil, &space)
+}
+
+// ListSpaces returns all global (non-personal) spaces.
+func (c *confluenceClient) ListSpaces(ctx context.Context) ([]Space, error) {
+	query := url.Values{
+		"type":   {"global"},
+		"status": {"current"},
+		"limit":  {strconv.Itoa(pageLimit)},
+	}
+
+	var spaces []Space
+	for start := 0; ; start += pageLimit {
+		query.Set("start", strconv.Itoa(start))
+		var resp struct {
+			Results []Space `json:"results"`
+		}
+		if err := c.get(ctx, c.baseURL+"/space", query, &resp); err != nil {
+			return nil, err
+		}
+		spaces = append(spaces, resp.Results...)
+		if len(resp.Results) < pageLimit {
+			return spaces, nil
+		}
+	}
+}
+
+// ListPages returns all current pages of the space with their version and ancestors, but without their content.
+func (c *confluenceClient) ListPages(ctx context.Context, spaceKey string) ([]Page, error) {
+	query := url.Values{
+		"spaceKey": {spaceKey},
+		"type":     {"page"},
+		"status":   {"current"},
+		"expand":   {"version,ancestors"},
+		"limit":    {strconv.Itoa(pageLimit)},
+	}
+
+	var pages []Page
+	for start := 0; ; start += pageLimit {
+		query.Set("start", strconv.Itoa(start))
+		var resp struct {
+			Results []Page `json:"results"`
+		}
+		if err := c.get(ctx, c.baseURL+"/content", query, &resp); err != nil {
+			return nil, err
+		}
+		pages = append(pages, resp.Results...)
+		if len(resp.Results) < pageLimit {
+			return pages, nil
+		}
+	}
+}
+
+// GetPage returns the page with its storage format body and labels.
+func (c *confluenceClient) GetPage(ctx context.Context, id string) (Page, error) {
+	var page Page
+	return page, c.get(ctx, c.baseURL+"/content/"+url.PathEscape(id), url.Values{
+		"expand": {"body.storage,metadata.labels,version,ancestors"},
+	}, &page)
+}
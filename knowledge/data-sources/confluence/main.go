@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/knowledge/pkg/datasource"
+	"github.com/gptscript-ai/knowledge/pkg/datasource/indexstore"
+	"github.com/sirupsen/logrus"
+)
+
+type MetadataInput struct {
+	ConfluenceConfig *ConfluenceConfig `json:"confluenceConfig,omitempty"`
+}
+
+type ConfluenceConfig struct {
+	// Site is the URL of the Confluence site, e.g. https://example.atlassian.net. It is only required if the account
+	// has access to multiple sites.
+	Site string `json:"site,omitempty"`
+	// Spaces are the keys of the spaces to sync, all global spaces are synced if empty
+	Spaces []string `json:"spaces"`
+}
+
+// confluenceSource lists the pages of the configured spaces. The pages are stored below their ancestors, e.g.
+// <space>/<parent page>/<page>.md
+type confluenceSource struct {
+	confluence *confluenceClient
+	// spaces are the synced spaces by key
+	spaces map[string]Space
+}
+
+func main() {
+	logOut := logrus.New()
+	logOut.SetOutput(os.Stdout)
+	logOut.SetFormatter(&logrus.JSONFormatter{})
+
+	ctx := context.Background()
+	gptscriptClient, err := gptscript.NewGPTScript()
+	if err != nil {
+		logOut.WithError(fmt.Errorf("failed to create gptscript client, error: %w", err)).Error()
+		os.Exit(0)
+	}
+
+	inputData := os.Getenv("GPTSCRIPT_INPUT")
+	input := MetadataInput{}
+
+	if err := json.Unmarshal([]byte(inputData), &input); err != nil {
+		logOut.WithError(fmt.Errorf("failed to unmarshal input data, error: %w", err)).Error()
+		os.Exit(0)
+	}
+	if input.ConfluenceConfig == nil {
+		input.ConfluenceConfig = &ConfluenceConfig{}
+	}
+
+	source := &confluenceSource{
+		confluence: &confluenceClient{token: os.Getenv("ATLASSIAN_OAUTH_TOKEN")},
+	}
+	if err := source.confluence.selectSite(ctx, input.ConfluenceConfig.Site); err != nil {
+		logOut.WithError(fmt.Errorf("failed to sync confluence, error: %w", err)).Error()
+		os.Exit(0)
+	}
+	if err := source.resolveSpaces(ctx, input.ConfluenceConfig.Spaces); err != nil {
+		logOut.WithError(fmt.Errorf("failed to sync confluence, error: %w", err)).Error()
+		os.Exit(0)
+	}
+
+	workspace := &datasource.GPTScriptWorkspace{Client: gptscriptClient}
+	store, closeStore, err := indexstore.FromEnv(ctx, workspace)
+	if err != nil {
+		logOut.WithError(fmt.Errorf("failed to open sync state store, error: %w", err)).Error()
+		os.Exit(0)
+	}
+	defer closeStore()
+
+	syncer := datasource.NewSyncer(source, store, workspace, datasource.Options{})
+	if _, err := syncer.Sync(ctx); err != nil {
+		logOut.WithError(fmt.Errorf("failed to sync confluence, error: %w", err)).Error()
+		os.Exit(0)
+	}
+}
+
+func (s *confluenceSource) resolveSpaces(ctx context.Context, keys []string) error {
+	s.spaces = map[string]Space{}
+	if len(keys) == 0 {
+		all, err := s.confluence.ListSpaces(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list spaces: %w", err)
+		}
+		for _, space := range all {
+			s.spaces[space.Key] = space
+		}
+		return nil
+	}
+
+	for _, key := range keys {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		space, err := s.confluence.GetSpace(ctx, key)
+		if err != nil {
+			return fmt.Errorf("failed to get space %q: %w", key, err)
+		}
+		s.spaces[space.Key] = space
+	}
+	return nil
+}
+
+func (s *confluenceSource) Name() string {
+	return "confluence"
+}
+
+// List lists the pages of all spaces, one page of items per space. The last modification of a page is its version.
+func (s *confluenceSource) List(ctx context.Context, fn func([]datasource.Item) error) error {
+	for _, space := range s.spaces {
+		pages, err := s.confluence.ListPages(ctx, space.Key)
+		if err != nil {
+			return fmt.Errorf("failed to list pages of space %s: %w", space.Key, err)
+		}
+
+		items := make([]datasource.Item, 0, len(pages))
+		for _, page := range pages {
+			elements := []string{datasource.SanitizeName(space.Name)}
+			for _, ancestor := range page.Ancestors {
+				elements = append(elements, datasource.SanitizeName(ancestor.Title))
+			}
+			items = append(items, datasource.Item{
+				ID:       page.ID,
+				Path:     path.Join(append(elements, datasource.SanitizeName(page.Title)+".md")...),
+				URL:      s.confluence.PageURL(page),
+				Version:  page.Version.When,
+				Metadata: map[string]string{"space": space.Key},
+			})
+		}
+		if err := fn(items); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Fetch returns the page as Markdown. The listing doesn't include the body and labels.
+func (s *confluenceSource) Fetch(ctx context.Context, item datasource.Item) ([]byte, error) {
+	page, err := s.confluence.GetPage(ctx, item.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page %s: %w", item.Path, err)
+	}
+	text, err := storageToText(page.Body.Storage.Value)
+	if err != nil {
+		return nil, datasource.Permanent(fmt.Errorf("failed to convert page %s: %w", item.Path, err))
+	}
+
+	space := s.spaces[item.Metadata["space"]]
+	return []byte(pageHeader(page.Title, space, page.Labels(), s.confluence.PageURL(page), page.Version.When) + text + "\n"), nil
+}
+
+// pageHeader returns the front matter of the page, so the labels and the source are ingested together with the content
+func pageHeader(title string, space Space, labels []string, url, updatedAt string) string {
+	var header strings.Builder
+	header.WriteString("---\n")
+	fmt.Fprintf(&header, "title: %q\n", title)
+	fmt.Fprintf(&header, "space: %q\n", space.Name)
+	if len(labels) > 0 {
+		quoted := make([]string, 0, len(labels))
+		for _, label := range labels {
+			quoted = append(quoted, fmt.Sprintf("%q", label))
+		}
+		fmt.Fprintf(&header, "labels: [%s]\n", strings.Join(quoted, ", "))
+	}
+	fmt.Fprintf(&header, "url: %q\n", url)
+	fmt.Fprintf(&header, "lastModified: %q\n", updatedAt)
+	header.WriteString("---\n\n")
+	fmt.Fprintf(&header, "# %s\n\n", title)
+	return header.String()
+}
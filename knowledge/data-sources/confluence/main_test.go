@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gptscript-ai/knowledge/pkg/datasource"
+)
+
+func TestStorageToText(t *testing.T) {
+	for storage, expected := range map[string]string{
+		`<h2>Setup</h2><p>Install the <strong>CLI</strong>&nbsp;first.<br/>Then log in.</p>`:                                                                                                 "## Setup\n\nInstall the CLI first.\nThen log in.",
+		`<ul><li>One</li><li>Two<ul><li>Nested</li></ul></li></ul>`:                                                                                                                          "- One\n- Two\n  - Nested",
+		`<table><tr><th>Name</th><th>Owner</th></tr><tr><td><p>API</p></td><td>Jane</td></tr></table>`:                                                                                       "Name | Owner\nAPI | Jane",
+		`<ac:structured-macro ac:name="code"><ac:parameter ac:name="language">go</ac:parameter><ac:plain-text-body><![CDATA[fmt.Println("hi")]]></ac:plain-text-body></ac:structured-macro>`: "```\nfmt.Println(\"hi\")\n```",
+		`<p>Before</p><ac:structured-macro ac:name="toc"><ac:parameter ac:name="maxLevel">2</ac:parameter></ac:structured-macro><p>After</p>`:                                                "Before\n\nAfter",
+	} {
+		text, err := storageToText(storage)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if text != expected {
+			t.Errorf("storageToText(%s): expected %q, got %q", storage, expected, text)
+		}
+	}
+}
+
+func TestPageHeader(t *testing.T) {
+	expected := `---
+title: "Release \"Process\""
+space: "Engineering"
+labels: ["release", "howto"]
+url: "https://example.atlassian.net/wiki/spaces/ENG/pages/1"
+lastModified: "2024-11-01T10:00:00.000Z"
+---
+
+# Release "Process"
+
+`
+	if header := pageHeader(`Release "Process"`, Space{Key: "ENG", Name: "Engineering"}, []string{"release", "howto"},
+		"https://example.atlassian.net/wiki/spaces/ENG/pages/1", "2024-11-01T10:00:00.000Z"); header != expected {
+		t.Errorf("unexpected header:\n%s", header)
+	}
+}
+
+func TestConfluenceSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/content":
+			if r.URL.Query().Get("spaceKey") != "ENG" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = io.WriteString(w, `{"results": [{"id": "2", "title": "Release/Process", "version": {"when": "v2"},
+				"ancestors": [{"id": "1", "title": "Guides"}], "_links": {"webui": "/spaces/ENG/pages/2"}}]}`)
+		case "/content/2":
+			_, _ = io.WriteString(w, `{"id": "2", "title": "Release/Process", "version": {"when": "v2"},
+				"body": {"storage": {"value": "<p>Tag the release.</p>"}},
+				"metadata": {"labels": {"results": [{"name": "release"}]}}, "_links": {"webui": "/spaces/ENG/pages/2"}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	source := &confluenceSource{
+		confluence: &confluenceClient{token: "token", baseURL: server.URL, site: Site{URL: "https://example.atlassian.net/"}},
+		spaces:     map[string]Space{"ENG": {Key: "ENG", Name: "Engineering"}},
+	}
+
+	var items []datasource.Item
+	if err := source.List(context.Background(), func(page []datasource.Item) error {
+		items = append(items, page...)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected 1 item, got %+v", items)
+	}
+	if item := items[0]; item.ID != "2" || item.Path != "Engineering/Guides/"+datasource.SanitizeName("Release/Process")+".md" ||
+		item.URL != "https://example.atlassian.net/wiki/spaces/ENG/pages/2" || item.Version != "v2" || item.Metadata["space"] != "ENG" {
+		t.Errorf("unexpected item %+v", item)
+	}
+
+	data, err := source.Fetch(context.Background(), items[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `labels: ["release"]`) || !strings.HasSuffix(string(data), "# Release/Process\n\nTag the release.\n") {
+		t.Errorf("unexpected page:\n%s", data)
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var (
+	whitespace = regexp.MustCompile(`\s+`)
+	blankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+// blockElements start and end on their own line
+var blockElements = map[string]bool{
+	"p": true, "div": true, "blockquote": true, "table": true, "ul": true, "ol": true, "hr": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "pre": true,
+	"ac:structured-macro": true, "ac:layout-section": true, "ac:layout-cell": true, "ac:task": true,
+}
+
+// skippedElements are dropped together with their content, e.g. the parameters of macros
+var skippedElements = map[string]bool{
+	"ac:parameter": true, "style": true, "script": true, "ac:placeholder": true,
+}
+
+type storageConverter struct {
+	out       strings.Builder
+	lists     int
+	cells     int
+	skip      int
+	pre       int
+	rowCells  int
+	lineStart bool
+}
+
+// storageToText converts a page body in the Confluence storage format (XHTML with Confluence specific elements) to
+// Markdown-like text: headings, lists, tables and code blocks are kept, formatting and macro parameters are dropped.
+func storageToText(storage string) (string, error) {
+	decoder := xml.NewDecoder(strings.NewReader(storage))
+	decoder.Strict = false
+	// xml.HTMLAutoClose can't be used, it contains "link" which would also close <ac:link>
+	decoder.AutoClose = []string{"br", "hr", "img", "col", "wbr"}
+	decoder.Entity = xml.HTMLEntity
+
+	c := &storageConverter{lineStart: true}
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return "", err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			c.start(elementName(t.Name), t)
+		case xml.EndElement:
+			c.end(elementName(t.Name))
+		case xml.CharData:
+			c.text(string(t))
+		}
+	}
+
+	text := blankLines.ReplaceAllString(c.out.String(), "\n\n")
+	return strings.TrimSpace(text), nil
+}
+
+func elementName(name xml.Name) string {
+	if name.Space != "" {
+		return strings.ToLower(name.Space + ":" + name.Local)
+	}
+	return strings.ToLower(name.Local)
+}
+
+func (c *storageConverter) start(name string, element xml.StartElement) {
+	if c.skip > 0 || skippedElements[name] {
+		c.skip++
+		return
+	}
+
+	c.block(name)
+
+	switch name {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		c.write(strings.Repeat("#", int(name[1]-'0')) + " ")
+	case "ul", "ol":
+		c.lists++
+	case "li", "ac:task":
+		c.newline()
+		c.write(strings.Repeat("  ", max(c.lists-1, 0)) + "- ")
+	case "tr":
+		c.newline()
+		c.rowCells = 0
+	case "td", "th":
+		if c.rowCells > 0 {
+			c.text(" | ")
+		}
+		c.rowCells++
+		c.cells++
+	case "br":
+		c.newline()
+	case "hr":
+		c.write("---")
+	case "pre", "ac:plain-text-body":
+		if c.pre == 0 {
+			c.newline()
+			c.write("```\n")
+		}
+		c.pre++
+	case "ac:structured-macro":
+		for _, attr := range element.Attr {
+			if attr.Name.Local == "name" && attr.Value == "toc" {
+				// The table of contents is generated, it has no content of its own
+				c.skip++
+			}
+		}
+	}
+}
+
+func (c *storageConverter) end(name string) {
+	if c.skip > 0 {
+		c.skip--
+		return
+	}
+
+	switch name {
+	case "ul", "ol":
+		c.lists = max(c.lists-1, 0)
+	case "td", "th":
+		c.cells = max(c.cells-1, 0)
+	case "pre", "ac:plain-text-body":
+		if c.pre > 0 {
+			c.pre--
+			if c.pre == 0 {
+				c.newline()
+				c.write("```")
+			}
+		}
+	}
+
+	c.block(name)
+}
+
+// block separates block elements by blank lines, except within lists and tables where they would break the structure
+func (c *storageConverter) block(name string) {
+	switch {
+	case !blockElements[name]:
+	case c.cells > 0:
+		c.text(" ")
+	case c.lists > 0:
+		if name == "ul" || name == "ol" {
+			c.newline()
+		}
+	default:
+		c.paragraph()
+	}
+}
+
+func (c *storageConverter) text(s string) {
+	if c.skip > 0 {
+		return
+	}
+	if c.pre > 0 {
+		c.write(s)
+		return
+	}
+
+	s = whitespace.ReplaceAllString(s, " ")
+	if c.lineStart || strings.HasSuffix(c.out.String(), " ") {
+		// Avoid repeated spaces between inline elements
+		s = strings.TrimLeft(s, " ")
+	}
+	c.write(s)
+}
+
+func (c *storageConverter) write(s string) {
+	if s == "" {
+		return
+	}
+	c.out.WriteString(s)
+	c.lineStart = strings.HasSuffix(s, "\n")
+}
+
+func (c *storageConverter) newline() {
+	if !c.lineStart {
+		c.write("\n")
+	}
+}
+
+// paragraph ends the current line and leaves a blank line, consecutive calls don't add more blank lines
+func (c *storageConverter) paragraph() {
+	c.newline()
+	if c.out.Len() > 0 && !strings.HasSuffix(c.out.String(), "\n\n") {
+		c.write("\n")
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gptscript-ai/knowledge/pkg/datasource"
+)
+
+const (
+	dropboxAPI     = "https://api.dropboxapi.com/2"
+	dropboxContent = "https://content.dropboxapi.com/2"
+)
+
+// errCursorReset is returned when a list_folder cursor expired, a full sync is needed then
+var errCursorReset = fmt.Errorf("dropbox cursor was reset: %w", datasource.ErrFullSyncRequired)
+
+type Entry struct {
+	Tag            string `json:".tag"`
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	PathLower      string `json:"path_lower"`
+	PathDisplay    string `json:"path_display"`
+	ServerModified string `json:"server_modified"`
+	Rev            string `json:"rev"`
+	Size           int64  `json:"size"`
+	IsDownloadable *bool  `json:"is_downloadable"`
+}
+
+func (e Entry) IsFile() bool {
+	return e.Tag == "file"
+}
+
+func (e Entry) IsFolder() bool {
+	return e.Tag == "folder"
+}
+
+func (e Entry) IsDeleted() bool {
+	return e.Tag == "deleted"
+}
+
+type listFolderResult struct {
+	Entries []Entry `json:"entries"`
+	Cursor  string  `json:"cursor"`
+	HasMore bool    `json:"has_more"`
+}
+
+type dropboxClient struct {
+	token string
+}
+
+func (c dropboxClient) do(ctx context.Context, url string, body io.Reader, header http.Header) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		var apiErr struct {
+			ErrorSummary string `json:"error_summary"`
+		}
+		data, _ := io.ReadAll(resp.Body)
+		endpoint := strings.TrimPrefix(strings.TrimPrefix(url, dropboxAPI), dropboxContent)
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.ErrorSummary != "" {
+			if resp.StatusCode == http.StatusConflict && strings.HasPrefix(apiErr.ErrorSummary, "reset/") {
+				return nil, errCursorReset
+			}
+			return nil, fmt.Errorf("dropbox request %s failed with status %d: %s", endpoint, resp.StatusCode, apiErr.ErrorSummary)
+		}
+		return nil, fmt.Errorf("dropbox request %s failed with status %d: %s", endpoint, resp.StatusCode, string(data))
+	}
+	return resp.Body, nil
+}
+
+func (c dropboxClient) rpc(ctx context.Context, endpoint string, args, v any) error {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+	body, err := c.do(ctx, dropboxAPI+endpoint, bytes.NewReader(data), http.Header{"Content-Type": {"application/json"}})
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return json.NewDecoder(body).Decode(v)
+}
+
+// GetMetadata returns the file or folder at the path, which can also be an ID ("id:...").
+func (c dropboxClient) GetMetadata(ctx context.Context, path string) (Entry, error) {
+	var e Entry
+	return e, c.rpc(ctx, "/files/get_metadata", map[string]any{"path": path}, &e)
+}
+
+// ListFolder returns all files and folders below the folder ("" for the whole Dropbox).
+func (c dropboxClient) ListFolder(ctx context.Context, path string) ([]Entry, error) {
+	var result listFolderResult
+	if err := c.rpc(ctx, "/files/list_folder", map[string]any{
+		"path":      path,
+		"recursive": true,
+		"limit":     2000,
+	}, &result); err != nil {
+		return nil, err
+	}
+	entries, _, err := c.continueListing(ctx, result)
+	return entries, err
+}
+
+// LatestCursor returns the cursor of the current state of the folder, for listing the changes since then.
+func (c dropboxClient) LatestCursor(ctx context.Context, path string) (string, error) {
+	var result listFolderResult
+	return result.Cursor, c.rpc(ctx, "/files/list_folder/get_latest_cursor", map[string]any{
+		"path":      path,
+		"recursive": true,
+	}, &result)
+}
+
+// ListChanges returns all entries that were added, changed or deleted since the cursor, and the new cursor.
+func (c dropboxClient) ListChanges(ctx context.Context, cursor string) ([]Entry, string, error) {
+	var result listFolderResult
+	if err := c.rpc(ctx, "/files/list_folder/continue", map[string]any{"cursor": cursor}, &result); err != nil {
+		return nil, "", err
+	}
+	return c.continueListing(ctx, result)
+}
+
+func (c dropboxClient) continueListing(ctx context.Context, result listFolderResult) ([]Entry, string, error) {
+	entries := result.Entries
+	for result.HasMore {
+		cursor := result.Cursor
+		result = listFolderResult{}
+		if err := c.rpc(ctx, "/files/list_folder/continue", map[string]any{"cursor": cursor}, &result); err != nil {
+			return nil, "", err
+		}
+		entries = append(entries, result.Entries...)
+	}
+	return entries, result.Cursor, nil
+}
+
+// Download returns the content of the file.
+func (c dropboxClient) Download(ctx context.Context, id string) ([]byte, error) {
+	arg, err := json.Marshal(map[string]any{"path": id})
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.do(ctx, dropboxContent+"/files/download", nil, http.Header{"Dropbox-API-Arg": {string(arg)}})
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/knowledge/pkg/datasource"
+	"github.com/gptscript-ai/knowledge/pkg/datasource/indexstore"
+	"github.com/sirupsen/logrus"
+)
+
+// We only sync files that are less than 50 MB, as most of the bigger files won't be supported by knowledge
+const maxFileSize = 1024 * 1024 * 50
+
+type MetadataInput struct {
+	DropboxConfig *DropboxConfig `json:"dropboxConfig,omitempty"`
+}
+
+type DropboxConfig struct {
+	// Folders are folder paths (e.g. /Documents/Reports) or IDs (id:...), the whole Dropbox is synced if empty
+	Folders []string `json:"folders"`
+}
+
+type RootState struct {
+	Name      string `json:"name"`
+	PathLower string `json:"pathLower"`
+}
+
+type dropboxState struct {
+	// Files maps the IDs of the synced files to their lower-case path, which is used to find the files of deleted
+	// entries, as they don't have an ID
+	Files map[string]string `json:"files,omitempty"`
+}
+
+// dropboxSource lists the files below the configured folders. Changes are listed with one list_folder cursor per
+// folder as cursor. Moved and renamed files and folders are reported as deleted at their old path and added at their
+// new one.
+type dropboxSource struct {
+	dropbox dropboxClient
+	logErr  *logrus.Logger
+	// roots are the synced folders by their lower-case path, "" is the whole Dropbox
+	roots map[string]RootState
+	state dropboxState
+}
+
+func main() {
+	logOut := logrus.New()
+	logOut.SetOutput(os.Stdout)
+	logOut.SetFormatter(&logrus.JSONFormatter{})
+	logErr := logrus.New()
+	logErr.SetOutput(os.Stderr)
+
+	ctx := context.Background()
+	gptscriptClient, err := gptscript.NewGPTScript()
+	if err != nil {
+		logOut.WithError(fmt.Errorf("failed to create gptscript client, error: %w", err)).Error()
+		os.Exit(0)
+	}
+
+	inputData := os.Getenv("GPTSCRIPT_INPUT")
+	input := MetadataInput{}
+
+	if err := json.Unmarshal([]byte(inputData), &input); err != nil {
+		logOut.WithError(fmt.Errorf("failed to unmarshal input data, error: %w", err)).Error()
+		os.Exit(0)
+	}
+	if input.DropboxConfig == nil {
+		input.DropboxConfig = &DropboxConfig{}
+	}
+
+	source := &dropboxSource{
+		dropbox: dropboxClient{token: os.Getenv("DROPBOX_OAUTH_TOKEN")},
+		logErr:  logErr,
+	}
+	if err := source.resolveRoots(ctx, input.DropboxConfig); err != nil {
+		logOut.WithError(fmt.Errorf("failed to sync dropbox, error: %w", err)).Error()
+		os.Exit(0)
+	}
+	// Renamed roots change the paths of all files, so they are part of the config
+	roots, err := json.Marshal(source.roots)
+	if err != nil {
+		logOut.WithError(fmt.Errorf("failed to marshal roots, error: %w", err)).Error()
+		os.Exit(0)
+	}
+
+	workspace := &datasource.GPTScriptWorkspace{Client: gptscriptClient}
+	store, closeStore, err := indexstore.FromEnv(ctx, workspace)
+	if err != nil {
+		logOut.WithError(fmt.Errorf("failed to open sync state store, error: %w", err)).Error()
+		os.Exit(0)
+	}
+	defer closeStore()
+
+	syncer := datasource.NewSyncer(source, store, workspace, datasource.Options{
+		Config: string(roots),
+	})
+	if _, err := syncer.Sync(ctx); err != nil {
+		logOut.WithError(fmt.Errorf("failed to sync dropbox, error: %w", err)).Error()
+		os.Exit(0)
+	}
+}
+
+func (s *dropboxSource) resolveRoots(ctx context.Context, config *DropboxConfig) error {
+	s.roots = map[string]RootState{}
+	for _, folder := range config.Folders {
+		folder = strings.TrimSpace(folder)
+		if folder == "" || folder == "/" {
+			continue
+		}
+		entry, err := s.dropbox.GetMetadata(ctx, folder)
+		if err != nil {
+			return fmt.Errorf("failed to get folder %q: %w", folder, err)
+		}
+		if !entry.IsFolder() {
+			return fmt.Errorf("%q is not a folder", folder)
+		}
+		s.roots[entry.PathLower] = RootState{Name: entry.Name, PathLower: entry.PathLower}
+	}
+	if len(s.roots) == 0 {
+		s.roots[""] = RootState{}
+	}
+	return nil
+}
+
+func (s *dropboxSource) Name() string {
+	return "dropbox"
+}
+
+// List lists the files of all roots, one page per root
+func (s *dropboxSource) List(ctx context.Context, fn func([]datasource.Item) error) error {
+	s.state.Files = map[string]string{}
+	for _, root := range s.roots {
+		entries, err := s.dropbox.ListFolder(ctx, root.PathLower)
+		if err != nil {
+			return fmt.Errorf("failed to list folder %q: %w", root.PathLower, err)
+		}
+
+		var items []datasource.Item
+		for _, entry := range entries {
+			if !entry.IsFile() {
+				continue
+			}
+			if item, ok := s.item(entry, root); ok {
+				items = append(items, item)
+			}
+		}
+		if err := fn(items); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Cursor returns the latest list_folder cursor of each root
+func (s *dropboxSource) Cursor(ctx context.Context) (string, error) {
+	cursors := map[string]string{}
+	for key, root := range s.roots {
+		cursor, err := s.dropbox.LatestCursor(ctx, root.PathLower)
+		if err != nil {
+			return "", err
+		}
+		cursors[key] = cursor
+	}
+	data, err := json.Marshal(cursors)
+	return string(data), err
+}
+
+// Changes lists the changes of each root since its cursor
+func (s *dropboxSource) Changes(ctx context.Context, cursor string, fn func([]datasource.Change, string) error) error {
+	cursors := map[string]string{}
+	if err := json.Unmarshal([]byte(cursor), &cursors); err != nil {
+		return fmt.Errorf("invalid cursor: %w", datasource.ErrFullSyncRequired)
+	}
+
+	for key, rootCursor := range cursors {
+		entries, newCursor, err := s.dropbox.ListChanges(ctx, rootCursor)
+		if err != nil {
+			return err
+		}
+
+		var changes []datasource.Change
+		for _, entry := range entries {
+			switch {
+			case entry.IsDeleted():
+				// The files at the path, or below it if it was a folder
+				for id, pathLower := range s.state.Files {
+					if pathLower == entry.PathLower || strings.HasPrefix(pathLower, entry.PathLower+"/") {
+						changes = append(changes, datasource.Change{Item: datasource.Item{ID: id}, Deleted: true})
+						delete(s.state.Files, id)
+					}
+				}
+			case entry.IsFile():
+				item, ok := s.item(entry, s.roots[key])
+				changes = append(changes, datasource.Change{Item: item, Deleted: !ok})
+			}
+		}
+
+		cursors[key] = newCursor
+		data, err := json.Marshal(cursors)
+		if err != nil {
+			return err
+		}
+		if err := fn(changes, string(data)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *dropboxSource) Fetch(ctx context.Context, item datasource.Item) ([]byte, error) {
+	return s.dropbox.Download(ctx, item.ID)
+}
+
+func (s *dropboxSource) LoadState(data json.RawMessage) error {
+	s.state = dropboxState{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &s.state); err != nil {
+			return err
+		}
+	}
+	if s.state.Files == nil {
+		s.state.Files = make(map[string]string)
+	}
+	return nil
+}
+
+func (s *dropboxSource) SaveState() (json.RawMessage, error) {
+	return json.Marshal(s.state)
+}
+
+// item returns the item of the file, it returns false if the file can't be downloaded or is too big
+func (s *dropboxSource) item(entry Entry, root RootState) (datasource.Item, bool) {
+	item := datasource.Item{
+		ID:      entry.ID,
+		Path:    workspacePath(root, entry.PathDisplay),
+		URL:     fileURL(entry.PathDisplay),
+		Version: entry.ServerModified,
+	}
+	if entry.IsDownloadable != nil && !*entry.IsDownloadable {
+		// e.g. Paper documents and Google files stored in Dropbox
+		s.logErr.Infof("Skipping %s because it can't be downloaded", item.Path)
+		return item, false
+	}
+	if entry.Size >= maxFileSize {
+		s.logErr.Infof("Skipping %s because it is larger than 50 MB", item.Path)
+		return item, false
+	}
+
+	s.state.Files[entry.ID] = entry.PathLower
+	return item, true
+}
+
+// workspacePath returns the path of the file in the workspace: the path below the synced folder, prefixed with the
+// name of the folder.
+func workspacePath(root RootState, pathDisplay string) string {
+	parts := strings.Split(strings.Trim(pathDisplay, "/"), "/")
+	depth := 0
+	if root.PathLower != "" {
+		depth = min(len(strings.Split(strings.Trim(root.PathLower, "/"), "/")), len(parts))
+	}
+	return path.Join(append([]string{root.Name}, parts[depth:]...)...)
+}
+
+func fileURL(pathDisplay string) string {
+	dir, name := path.Split(pathDisplay)
+	u := url.URL{
+		Scheme:   "https",
+		Host:     "www.dropbox.com",
+		Path:     path.Join("/home", dir),
+		RawQuery: url.Values{"preview": {name}}.Encode(),
+	}
+	return u.String()
+}
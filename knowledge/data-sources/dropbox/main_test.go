@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/gptscript-ai/knowledge/pkg/datasource"
+	"github.com/sirupsen/logrus"
+)
+
+func TestWorkspacePath(t *testing.T) {
+	for _, tc := range []struct {
+		root        RootState
+		pathDisplay string
+		expected    string
+	}{
+		{RootState{}, "/Documents/Reports/q1.pdf", "Documents/Reports/q1.pdf"},
+		{RootState{Name: "Reports", PathLower: "/documents/reports"}, "/Documents/Reports/q1.pdf", "Reports/q1.pdf"},
+		{RootState{Name: "Reports", PathLower: "/documents/reports"}, "/Documents/Reports/2024/Q1.pdf", "Reports/2024/Q1.pdf"},
+		{RootState{Name: "Reports", PathLower: "/documents/reports/archive"}, "/Documents/Reports", "Reports"},
+	} {
+		if p := workspacePath(tc.root, tc.pathDisplay); p != tc.expected {
+			t.Errorf("workspacePath(%+v, %q): expected %q, got %q", tc.root, tc.pathDisplay, tc.expected, p)
+		}
+	}
+}
+
+func TestFileURL(t *testing.T) {
+	if u := fileURL("/Documents/Q1 Report.pdf"); u != "https://www.dropbox.com/home/Documents?preview=Q1+Report.pdf" {
+		t.Errorf("unexpected URL %s", u)
+	}
+	if u := fileURL("/notes.txt"); u != "https://www.dropbox.com/home?preview=notes.txt" {
+		t.Errorf("unexpected URL %s", u)
+	}
+}
+
+func TestItem(t *testing.T) {
+	s := &dropboxSource{logErr: logrus.New()}
+	s.logErr.SetOutput(io.Discard)
+	if err := s.LoadState(nil); err != nil {
+		t.Fatal(err)
+	}
+	root := RootState{Name: "Reports", PathLower: "/documents/reports"}
+
+	item, ok := s.item(Entry{Tag: "file", ID: "id:1", PathLower: "/documents/reports/q1.pdf", PathDisplay: "/Documents/Reports/Q1.pdf", ServerModified: "v1"}, root)
+	if !ok || item.ID != "id:1" || item.Path != "Reports/Q1.pdf" || item.Version != "v1" {
+		t.Errorf("unexpected item %+v", item)
+	}
+	if s.state.Files["id:1"] != "/documents/reports/q1.pdf" {
+		t.Errorf("expected the file in the state, got %v", s.state.Files)
+	}
+
+	downloadable := false
+	if _, ok := s.item(Entry{Tag: "file", ID: "id:2", PathDisplay: "/Documents/Reports/Plan.paper", IsDownloadable: &downloadable}, root); ok {
+		t.Error("expected files that can't be downloaded to be skipped")
+	}
+	if _, ok := s.item(Entry{Tag: "file", ID: "id:3", PathDisplay: "/Documents/Reports/video.mp4", Size: maxFileSize}, root); ok {
+		t.Error("expected files over the size limit to be skipped")
+	}
+	if len(s.state.Files) != 1 {
+		t.Errorf("expected skipped files not to be in the state, got %v", s.state.Files)
+	}
+}
+
+func TestState(t *testing.T) {
+	s := &dropboxSource{}
+	if err := s.LoadState(json.RawMessage(`{"files": {"id:1": "/documents/q1.pdf"}}`)); err != nil {
+		t.Fatal(err)
+	}
+	data, err := s.SaveState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"files":{"id:1":"/documents/q1.pdf"}}` {
+		t.Errorf("unexpected state %s", data)
+	}
+
+	if err := s.Changes(context.Background(), "not a cursor", nil); !errors.Is(err, datasource.ErrFullSyncRequired) {
+		t.Errorf("expected a full sync for an invalid cursor, got %v", err)
+	}
+}
+
+func TestEntry(t *testing.T) {
+	for tag, expected := range map[string][3]bool{
+		"file":    {true, false, false},
+		"folder":  {false, true, false},
+		"deleted": {false, false, true},
+	} {
+		e := Entry{Tag: tag}
+		if [3]bool{e.IsFile(), e.IsFolder(), e.IsDeleted()} != expected {
+			t.Errorf("unexpected predicates for %q", tag)
+		}
+	}
+}
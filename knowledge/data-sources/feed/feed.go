@@ -0,0 +1,329 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	userAgent = "knowledge-feed-data-source"
+	// maxFeedSize is the maximum size of a feed document
+	maxFeedSize = 1024 * 1024 * 20
+)
+
+// errNotModified is returned when the feed didn't change since the last sync
+var errNotModified = errors.New("feed not modified")
+
+// Feed is a parsed RSS or Atom feed.
+type Feed struct {
+	Title   string
+	Link    string
+	Entries []Entry
+}
+
+// Entry is an item of an RSS feed or an entry of an Atom feed.
+type Entry struct {
+	// GUID identifies the entry: the guid or id of the entry, or its link if it has none
+	GUID      string
+	Title     string
+	Link      string
+	Author    string
+	Content   string
+	Published time.Time
+	Updated   time.Time
+}
+
+// fetchResult is the response to a (conditional) feed request.
+type fetchResult struct {
+	Feed         *Feed
+	ETag         string
+	LastModified string
+}
+
+// fetchFeed downloads and parses the feed. The ETag and Last-Modified of the previous sync are sent, so servers can
+// answer with 304 Not Modified, which is returned as errNotModified.
+func fetchFeed(ctx context.Context, client *http.Client, feedURL, etag, lastModified string) (*fetchResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/rdf+xml, application/xml;q=0.9, text/xml;q=0.9, */*;q=0.8")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, errNotModified
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxFeedSize {
+		return nil, errors.New("feed is larger than 20 MB")
+	}
+
+	feed, err := parseFeed(data)
+	if err != nil {
+		return nil, err
+	}
+	return &fetchResult{
+		Feed:         feed,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}, nil
+}
+
+type rssDocument struct {
+	Channel struct {
+		Title string `xml:"title"`
+		// Links also contains the (empty) atom:link elements many RSS feeds have
+		Links []string  `xml:"link"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	// Items are direct children of the root in RSS 1.0 (RDF) feeds
+	Items []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Links       []string `xml:"link"`
+	GUID        string   `xml:"guid"`
+	Description string   `xml:"description"`
+	Encoded     string   `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	PubDate     string   `xml:"pubDate"`
+	Date        string   `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Author      string   `xml:"author"`
+	Creator     string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	About       string   `xml:"about,attr"`
+}
+
+type atomDocument struct {
+	Title   atomText    `xml:"title"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID        string     `xml:"id"`
+	Title     atomText   `xml:"title"`
+	Links     []atomLink `xml:"link"`
+	Summary   atomText   `xml:"summary"`
+	Content   atomText   `xml:"content"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+	Authors   []struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+// String returns the text, or the markup of xhtml text
+func (t atomText) String() string {
+	if t.Type == "xhtml" {
+		return strings.TrimSpace(t.Inner)
+	}
+	return strings.TrimSpace(t.Text)
+}
+
+// parseFeed parses an RSS 2.0, RSS 1.0 (RDF) or Atom feed.
+func parseFeed(data []byte) (*Feed, error) {
+	root, err := rootElement(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch root {
+	case "rss", "RDF":
+		var doc rssDocument
+		if err := newDecoder(data).Decode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to parse RSS feed: %w", err)
+		}
+		feed := &Feed{
+			Title: strings.TrimSpace(doc.Channel.Title),
+			Link:  firstNonEmpty(doc.Channel.Links...),
+		}
+		for _, item := range append(doc.Channel.Items, doc.Items...) {
+			feed.Entries = append(feed.Entries, item.entry())
+		}
+		return feed, nil
+	case "feed":
+		var doc atomDocument
+		if err := newDecoder(data).Decode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to parse Atom feed: %w", err)
+		}
+		feed := &Feed{
+			Title: doc.Title.String(),
+			Link:  alternateLink(doc.Links),
+		}
+		for _, entry := range doc.Entries {
+			feed.Entries = append(feed.Entries, entry.entry())
+		}
+		return feed, nil
+	default:
+		return nil, fmt.Errorf("not an RSS or Atom feed (root element %q)", root)
+	}
+}
+
+func (i rssItem) entry() Entry {
+	e := Entry{
+		GUID:      strings.TrimSpace(i.GUID),
+		Title:     strings.TrimSpace(i.Title),
+		Link:      firstNonEmpty(i.Links...),
+		Author:    firstNonEmpty(i.Creator, i.Author),
+		Content:   firstNonEmpty(i.Encoded, i.Description),
+		Published: parseDate(firstNonEmpty(i.PubDate, i.Date)),
+	}
+	if e.GUID == "" {
+		e.GUID = firstNonEmpty(i.About, e.Link)
+	}
+	return e
+}
+
+func (a atomEntry) entry() Entry {
+	e := Entry{
+		GUID:      strings.TrimSpace(a.ID),
+		Title:     a.Title.String(),
+		Link:      alternateLink(a.Links),
+		Content:   firstNonEmpty(a.Content.String(), a.Summary.String()),
+		Published: parseDate(a.Published),
+		Updated:   parseDate(a.Updated),
+	}
+	if len(a.Authors) > 0 {
+		e.Author = strings.TrimSpace(a.Authors[0].Name)
+	}
+	if e.GUID == "" {
+		e.GUID = e.Link
+	}
+	if e.Published.IsZero() {
+		// published is optional in Atom, updated is required
+		e.Published = e.Updated
+	}
+	return e
+}
+
+func alternateLink(links []atomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return strings.TrimSpace(link.Href)
+		}
+	}
+	return ""
+}
+
+func rootElement(data []byte) (string, error) {
+	decoder := newDecoder(data)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", fmt.Errorf("failed to parse feed: %w", err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+// newDecoder returns a lenient decoder, as many feeds contain HTML entities or are not UTF-8 encoded.
+func newDecoder(data []byte) *xml.Decoder {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	decoder.CharsetReader = charsetReader
+	return decoder
+}
+
+// charsetReader converts Latin-1 (and the mostly identical Windows-1252) to UTF-8, the only other encodings that are
+// common in feeds.
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "utf-8", "utf8", "us-ascii", "ascii":
+		return input, nil
+	case "iso-8859-1", "iso8859-1", "latin1", "latin-1", "windows-1252", "cp1252":
+		data, err := io.ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, 0, len(data)*2)
+		for _, b := range data {
+			buf = utf8.AppendRune(buf, rune(b))
+		}
+		return bytes.NewReader(buf), nil
+	default:
+		return nil, fmt.Errorf("unsupported charset %q", charset)
+	}
+}
+
+// dateLayouts are the formats of dates found in feeds. RSS uses RFC 822 dates, but many feeds deviate from it.
+var dateLayouts = []string{
+	time.RFC3339,
+	time.RFC3339Nano,
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 2 Jan 2006 15:04 -0700",
+	"Mon, 2 Jan 2006 15:04 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 MST",
+	time.RFC822Z,
+	time.RFC822,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// parseDate returns the zero time if the date can't be parsed
+func parseDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gptscript-ai/knowledge/pkg/datasource"
+	"github.com/sirupsen/logrus"
+)
+
+func TestParseRSS(t *testing.T) {
+	feed, err := parseFeed([]byte(`<?xml version="1.0" encoding="ISO-8859-1"?>
+<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+<channel>
+  <title>Release Notes</title>
+  <link>https://example.com/releases</link>
+  <atom:link href="https://example.com/releases/feed.xml" rel="self" type="application/rss+xml"/>
+  <item>
+    <title>Version 1.2 &amp; more</title>
+    <link>https://example.com/releases/1.2</link>
+    <guid isPermaLink="false">release-1.2</guid>
+    <description>Short summary</description>
+    <content:encoded><![CDATA[<p>Full notes for caf` + "\xe9" + `</p>]]></content:encoded>
+    <pubDate>Fri, 1 Nov 2024 10:00:00 +0100</pubDate>
+    <dc:creator>Jane</dc:creator>
+  </item>
+  <item>
+    <title>Version 1.1</title>
+    <link>https://example.com/releases/1.1</link>
+    <description>&lt;p&gt;Escaped &amp;hellip;&lt;/p&gt;</description>
+    <pubDate>Tue, 01 Oct 2024 09:00:00 GMT</pubDate>
+  </item>
+</channel>
+</rss>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if feed.Title != "Release Notes" || feed.Link != "https://example.com/releases" {
+		t.Errorf("unexpected feed %q %q", feed.Title, feed.Link)
+	}
+	if len(feed.Entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(feed.Entries))
+	}
+
+	first := feed.Entries[0]
+	if first.GUID != "release-1.2" || first.Title != "Version 1.2 & more" || first.Author != "Jane" {
+		t.Errorf("unexpected entry %+v", first)
+	}
+	if first.Content != "<p>Full notes for café</p>" {
+		t.Errorf("unexpected content %q", first.Content)
+	}
+	if !first.Published.Equal(time.Date(2024, 11, 1, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected published date %v", first.Published)
+	}
+
+	// Without a guid, the link identifies the entry
+	second := feed.Entries[1]
+	if second.GUID != "https://example.com/releases/1.1" || second.Content != "<p>Escaped &hellip;</p>" {
+		t.Errorf("unexpected entry %+v", second)
+	}
+	if !second.Published.Equal(time.Date(2024, 10, 1, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected published date %v", second.Published)
+	}
+}
+
+func TestParseAtom(t *testing.T) {
+	feed, err := parseFeed([]byte(`<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Engineering Blog</title>
+  <link rel="self" href="https://example.com/blog/atom.xml"/>
+  <link href="https://example.com/blog"/>
+  <entry>
+    <id>tag:example.com,2024:post-1</id>
+    <title type="html">Scaling &lt;em&gt;things&lt;/em&gt;</title>
+    <link rel="alternate" href="https://example.com/blog/scaling"/>
+    <published>2024-11-01T10:00:00Z</published>
+    <updated>2024-11-03T12:00:00Z</updated>
+    <author><name>Sam</name></author>
+    <summary>Summary</summary>
+    <content type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml"><p>Body</p></div></content>
+  </entry>
+  <entry>
+    <id>tag:example.com,2024:post-2</id>
+    <title>Only updated</title>
+    <updated>2024-10-01T08:00:00+02:00</updated>
+    <summary type="html">&lt;p&gt;Summary only&lt;/p&gt;</summary>
+  </entry>
+</feed>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if feed.Title != "Engineering Blog" || feed.Link != "https://example.com/blog" {
+		t.Errorf("unexpected feed %q %q", feed.Title, feed.Link)
+	}
+	if len(feed.Entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(feed.Entries))
+	}
+
+	first := feed.Entries[0]
+	if first.GUID != "tag:example.com,2024:post-1" || first.Link != "https://example.com/blog/scaling" || first.Author != "Sam" {
+		t.Errorf("unexpected entry %+v", first)
+	}
+	if first.Title != "Scaling <em>things</em>" {
+		t.Errorf("unexpected title %q", first.Title)
+	}
+	if first.Content != `<div xmlns="http://www.w3.org/1999/xhtml"><p>Body</p></div>` {
+		t.Errorf("unexpected content %q", first.Content)
+	}
+	if !first.Updated.After(first.Published) {
+		t.Errorf("expected updated %v after published %v", first.Updated, first.Published)
+	}
+
+	// published falls back to updated
+	second := feed.Entries[1]
+	if !second.Published.Equal(time.Date(2024, 10, 1, 6, 0, 0, 0, time.UTC)) || second.Content != "<p>Summary only</p>" {
+		t.Errorf("unexpected entry %+v", second)
+	}
+}
+
+func TestParseFeedNotAFeed(t *testing.T) {
+	if _, err := parseFeed([]byte(`<!DOCTYPE html><html><body>Not a feed</body></html>`)); err == nil {
+		t.Error("expected an error for an HTML page")
+	}
+}
+
+func TestFetchFeedNotModified(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = io.WriteString(w, `<rss version="2.0"><channel><title>T</title><item><guid>1</guid><title>A</title></item></channel></rss>`)
+	}))
+	defer server.Close()
+
+	result, err := fetchFeed(context.Background(), server.Client(), server.URL, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ETag != `"v1"` || len(result.Feed.Entries) != 1 {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	if _, err := fetchFeed(context.Background(), server.Client(), server.URL, result.ETag, ""); !errors.Is(err, errNotModified) {
+		t.Errorf("expected errNotModified, got %v", err)
+	}
+}
+
+func TestEntryFileName(t *testing.T) {
+	for entry, expected := range map[*Entry]string{
+		{Title: "Release Notes: v1.2!", Published: time.Date(2024, 11, 1, 23, 0, 0, 0, time.FixedZone("", -3600))}: "2024-11-02-release-notes-v1-2.html",
+		{Title: "Ünïcödé Title"}: "ünïcödé-title.html",
+		{Title: "???"}:           "entry.html",
+	} {
+		if name := entryFileName(*entry); name != expected {
+			t.Errorf("expected %s, got %s", expected, name)
+		}
+	}
+}
+
+func TestFeedSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = io.WriteString(w, `<rss version="2.0"><channel><title>Blog</title>
+<item><guid>1</guid><title>New</title><pubDate>`+time.Now().Format(time.RFC1123Z)+`</pubDate></item>
+<item><guid>2</guid><title>Old</title><pubDate>Tue, 01 Oct 2019 09:00:00 GMT</pubDate></item>
+</channel></rss>`)
+	}))
+	defer server.Close()
+
+	source := &feedSource{httpClient: server.Client(), urls: []string{server.URL}, maxAge: 30 * 24 * time.Hour}
+	source.logErr = logrus.New()
+	source.logErr.SetOutput(io.Discard)
+	if err := source.LoadState(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var items []datasource.Item
+	list := func(page []datasource.Item) error {
+		items = append(items, page...)
+		return nil
+	}
+	if err := source.List(context.Background(), list); err != nil {
+		t.Fatal(err)
+	}
+	// Entries older than the max age are skipped
+	if len(items) != 1 || !strings.HasPrefix(items[0].Path, "blog/") || items[0].Metadata["feed"] != server.URL {
+		t.Fatalf("unexpected items %+v", items)
+	}
+	if data, err := source.Fetch(context.Background(), items[0]); err != nil || !strings.Contains(string(data), "<h1>New</h1>") {
+		t.Errorf("unexpected document %q, error: %v", data, err)
+	}
+
+	// The ETag is part of the state, unmodified feeds list no entries, but their entries are retained
+	state, err := source.SaveState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := source.LoadState(state); err != nil {
+		t.Fatal(err)
+	}
+	items = nil
+	if err := source.List(context.Background(), list); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 0 {
+		t.Errorf("expected no items of an unmodified feed, got %+v", items)
+	}
+
+	file := datasource.FileState{Metadata: map[string]string{"feed": server.URL, "published": time.Now().Format(time.RFC3339)}}
+	if !source.Retain("1", file) {
+		t.Error("expected the entry to be retained")
+	}
+	file.Metadata["published"] = "2019-10-01T09:00:00Z"
+	if source.Retain("2", file) {
+		t.Error("expected entries older than the max age to be deleted")
+	}
+	source.urls = []string{"https://example.com/other.xml"}
+	if source.Retain("1", datasource.FileState{Metadata: map[string]string{"feed": server.URL}}) {
+		t.Error("expected entries of removed feeds to be deleted")
+	}
+}
@@ -0,0 +1,325 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/knowledge/pkg/datasource"
+	"github.com/gptscript-ai/knowledge/pkg/datasource/indexstore"
+	"github.com/sirupsen/logrus"
+)
+
+type MetadataInput struct {
+	FeedConfig *FeedConfig `json:"feedConfig,omitempty"`
+}
+
+type FeedConfig struct {
+	// URLs are the URLs of the RSS or Atom feeds
+	URLs []string `json:"urls"`
+	// MaxAgeDays skips entries published more than this many days ago and removes them from the workspace once they
+	// are older. All entries are kept if 0.
+	MaxAgeDays int `json:"maxAgeDays,omitempty"`
+}
+
+type feedState struct {
+	// Feeds are keyed by the feed URL
+	Feeds map[string]FeedInfo `json:"feeds,omitempty"`
+}
+
+type FeedInfo struct {
+	Title string `json:"title"`
+	// Folder is the workspace folder of the entries, derived from the feed title on the first sync
+	Folder       string `json:"folder"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	LastSynced   string `json:"lastSynced,omitempty"`
+}
+
+// feedSource lists the entries of the configured feeds. Entries are identified by their GUID, so entries that were
+// synced before are skipped unless their updated date changed. Entries that drop out of a feed are kept, only the
+// entries of feeds that are no longer configured, and entries older than the max age, are deleted.
+type feedSource struct {
+	logErr     *logrus.Logger
+	httpClient *http.Client
+	urls       []string
+	maxAge     time.Duration
+	state      feedState
+	// rendered are the documents of the entries listed in the current page, by entry ID
+	rendered map[string][]byte
+}
+
+func main() {
+	logOut := logrus.New()
+	logOut.SetOutput(os.Stdout)
+	logOut.SetFormatter(&logrus.JSONFormatter{})
+	logErr := logrus.New()
+	logErr.SetOutput(os.Stderr)
+
+	ctx := context.Background()
+	gptscriptClient, err := gptscript.NewGPTScript()
+	if err != nil {
+		logOut.WithError(fmt.Errorf("failed to create gptscript client, error: %w", err)).Error()
+		os.Exit(0)
+	}
+
+	inputData := os.Getenv("GPTSCRIPT_INPUT")
+	input := MetadataInput{}
+
+	if err := json.Unmarshal([]byte(inputData), &input); err != nil {
+		logOut.WithError(fmt.Errorf("failed to unmarshal input data, error: %w", err)).Error()
+		os.Exit(0)
+	}
+	if input.FeedConfig == nil || len(input.FeedConfig.URLs) == 0 {
+		logOut.WithError(errors.New("no feed URLs configured")).Error()
+		os.Exit(0)
+	}
+
+	source := &feedSource{
+		logErr:     logErr,
+		httpClient: &http.Client{Timeout: time.Minute},
+		maxAge:     time.Duration(input.FeedConfig.MaxAgeDays) * 24 * time.Hour,
+	}
+	for _, feedURL := range input.FeedConfig.URLs {
+		if feedURL = strings.TrimSpace(feedURL); feedURL != "" {
+			source.urls = append(source.urls, feedURL)
+		}
+	}
+
+	workspace := &datasource.GPTScriptWorkspace{Client: gptscriptClient}
+	store, closeStore, err := indexstore.FromEnv(ctx, workspace)
+	if err != nil {
+		logOut.WithError(fmt.Errorf("failed to open sync state store, error: %w", err)).Error()
+		os.Exit(0)
+	}
+	defer closeStore()
+
+	syncer := datasource.NewSyncer(source, store, workspace, datasource.Options{})
+	if _, err := syncer.Sync(ctx); err != nil {
+		logOut.WithError(fmt.Errorf("failed to sync feeds, error: %w", err)).Error()
+		os.Exit(0)
+	}
+}
+
+func (s *feedSource) Name() string {
+	return "feed"
+}
+
+// List fetches the feeds and lists their entries, one page per feed. Feeds that fail are logged and skipped, their
+// entries are kept, as the feed may only be unavailable temporarily.
+func (s *feedSource) List(ctx context.Context, fn func([]datasource.Item) error) error {
+	for feedURL := range s.state.Feeds {
+		if !slices.Contains(s.urls, feedURL) {
+			delete(s.state.Feeds, feedURL)
+		}
+	}
+
+	var failed []string
+	for _, feedURL := range s.urls {
+		items, err := s.listFeed(ctx, feedURL)
+		if err != nil {
+			s.logErr.Errorf("Failed to sync feed %s: %v", feedURL, err)
+			failed = append(failed, feedURL)
+			continue
+		}
+		if err := fn(items); err != nil {
+			return err
+		}
+	}
+	if len(failed) == len(s.urls) {
+		return fmt.Errorf("failed to sync all feeds: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func (s *feedSource) listFeed(ctx context.Context, feedURL string) ([]datasource.Item, error) {
+	info := s.state.Feeds[feedURL]
+
+	result, err := fetchFeed(ctx, s.httpClient, feedURL, info.ETag, info.LastModified)
+	if errors.Is(err, errNotModified) {
+		s.logErr.Infof("Feed %s is not modified", feedURL)
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	feed := result.Feed
+	if feed.Title != "" {
+		info.Title = feed.Title
+	}
+	if info.Folder == "" {
+		info.Folder = s.uniqueFolder(feedURL, folderName(feed.Title, feedURL))
+	}
+	info.ETag = result.ETag
+	info.LastModified = result.LastModified
+	info.LastSynced = time.Now().UTC().Format(time.RFC3339)
+	s.state.Feeds[feedURL] = info
+
+	s.logErr.Infof("Syncing %d entries of feed %s", len(feed.Entries), feedURL)
+	s.rendered = map[string][]byte{}
+	items := make([]datasource.Item, 0, len(feed.Entries))
+	for _, entry := range feed.Entries {
+		if entry.GUID == "" {
+			s.logErr.Infof("Skipping entry %q of %s because it has neither a GUID nor a link", entry.Title, feedURL)
+			continue
+		}
+		if s.tooOld(entry.Published) {
+			continue
+		}
+
+		id := entryID(feedURL, entry.GUID)
+		published := formatDate(entry.Published)
+		s.rendered[id] = []byte(renderEntry(info.Title, entry))
+		items = append(items, datasource.Item{
+			ID:   id,
+			Path: path.Join(info.Folder, entryFileName(entry)),
+			URL:  entry.Link,
+			// Entries without dates are only written once
+			Version: firstNonEmpty(formatDate(entry.Updated), published, entry.GUID),
+			Metadata: map[string]string{
+				"feed":      feedURL,
+				"guid":      entry.GUID,
+				"title":     entry.Title,
+				"published": published,
+			},
+		})
+	}
+	return items, nil
+}
+
+// Fetch returns the document of an entry of the current page, the entries are part of the feed document
+func (s *feedSource) Fetch(_ context.Context, item datasource.Item) ([]byte, error) {
+	data, ok := s.rendered[item.ID]
+	if !ok {
+		return nil, datasource.Permanent(fmt.Errorf("entry %s is not part of the feed", item.ID))
+	}
+	return data, nil
+}
+
+// Retain keeps the entries that dropped out of a feed, unless the feed is no longer configured or the entry is older
+// than the max age
+func (s *feedSource) Retain(_ string, file datasource.FileState) bool {
+	return slices.Contains(s.urls, file.Metadata["feed"]) && !s.tooOld(parseDate(file.Metadata["published"]))
+}
+
+func (s *feedSource) LoadState(data json.RawMessage) error {
+	s.state = feedState{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &s.state); err != nil {
+			return err
+		}
+	}
+	if s.state.Feeds == nil {
+		s.state.Feeds = make(map[string]FeedInfo)
+	}
+	return nil
+}
+
+func (s *feedSource) SaveState() (json.RawMessage, error) {
+	return json.Marshal(s.state)
+}
+
+// tooOld reports whether an entry was published before the max age. Entries without a date are never too old.
+func (s *feedSource) tooOld(published time.Time) bool {
+	return s.maxAge > 0 && !published.IsZero() && time.Since(published) > s.maxAge
+}
+
+// uniqueFolder appends a hash of the feed URL to the folder if another feed already uses it
+func (s *feedSource) uniqueFolder(feedURL, folder string) string {
+	for otherURL, info := range s.state.Feeds {
+		if otherURL != feedURL && info.Folder == folder {
+			return fmt.Sprintf("%s-%s", folder, entryID(feedURL, ""))
+		}
+	}
+	return folder
+}
+
+// entryID is the file ID of an entry. GUIDs are only unique within a feed, so the feed URL is part of the ID.
+func entryID(feedURL, guid string) string {
+	sum := sha256.Sum256([]byte(feedURL + "\n" + guid))
+	return hex.EncodeToString(sum[:8])
+}
+
+var nonNameChars = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+
+// slug returns a file name friendly version of s, with at most 80 characters
+func slug(s string) string {
+	s = strings.Trim(nonNameChars.ReplaceAllString(strings.ToLower(s), "-"), "-")
+	if runes := []rune(s); len(runes) > 80 {
+		s = strings.TrimRight(string(runes[:80]), "-")
+	}
+	return s
+}
+
+func folderName(title, feedURL string) string {
+	if name := slug(title); name != "" {
+		return name
+	}
+	if u, err := url.Parse(feedURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return "feed"
+}
+
+// entryFileName returns the name of the entry's file, prefixed with the publication date so the files sort
+// chronologically, e.g. 2024-11-01-release-notes-v1-2.html
+func entryFileName(entry Entry) string {
+	name := slug(entry.Title)
+	if name == "" {
+		name = "entry"
+	}
+	if !entry.Published.IsZero() {
+		name = entry.Published.UTC().Format("2006-01-02") + "-" + name
+	}
+	return name + ".html"
+}
+
+// renderEntry returns an HTML document with the content of the entry. The publication details are part of the
+// document, so they are also found by retrieval.
+func renderEntry(feedTitle string, entry Entry) string {
+	var b strings.Builder
+	title := html.EscapeString(entry.Title)
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n</head>\n<body>\n<h1>%s</h1>\n<p>\n", title, title)
+	if feedTitle != "" {
+		fmt.Fprintf(&b, "Feed: %s<br>\n", html.EscapeString(feedTitle))
+	}
+	if !entry.Published.IsZero() {
+		fmt.Fprintf(&b, "Published: %s<br>\n", entry.Published.Format(time.RFC1123Z))
+	}
+	if !entry.Updated.IsZero() && !entry.Updated.Equal(entry.Published) {
+		fmt.Fprintf(&b, "Updated: %s<br>\n", entry.Updated.Format(time.RFC1123Z))
+	}
+	if entry.Author != "" {
+		fmt.Fprintf(&b, "Author: %s<br>\n", html.EscapeString(entry.Author))
+	}
+	if entry.Link != "" {
+		link := html.EscapeString(entry.Link)
+		fmt.Fprintf(&b, "Link: <a href=\"%s\">%s</a>\n", link, link)
+	}
+	b.WriteString("</p>\n")
+	// The content is HTML in all common feed formats
+	b.WriteString(entry.Content)
+	b.WriteString("\n</body>\n</html>\n")
+	return b.String()
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
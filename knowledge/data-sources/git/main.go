@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/knowledge/pkg/datasource"
+	"github.com/gptscript-ai/knowledge/pkg/datasource/indexstore"
+	"github.com/sirupsen/logrus"
+)
+
+// We only sync files that are less than 50 MB, as most of the bigger files won't be supported by knowledge
+const maxFileSize = 1024 * 1024 * 50
+
+type MetadataInput struct {
+	GitConfig *GitConfig `json:"gitConfig,omitempty"`
+}
+
+type GitConfig struct {
+	URL string `json:"url"`
+	// Branch defaults to the default branch of the repository
+	Branch string `json:"branch,omitempty"`
+	// Include are glob patterns matched against the file paths, e.g. docs/**/*.md. All files are synced if empty.
+	Include []string `json:"include,omitempty"`
+	// Exclude are glob patterns of files that are not synced, even if they match an include pattern
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// gitSource lists the files of the latest commit of the branch that match the patterns. Files are written to
+// <repository name>/<path>, their blob hash is the version, so only files whose content changed are written.
+type gitSource struct {
+	repo    *repo
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+	logErr  *logrus.Logger
+}
+
+func main() {
+	logOut := logrus.New()
+	logOut.SetOutput(os.Stdout)
+	logOut.SetFormatter(&logrus.JSONFormatter{})
+	logErr := logrus.New()
+	logErr.SetOutput(os.Stderr)
+
+	ctx := context.Background()
+	gptscriptClient, err := gptscript.NewGPTScript()
+	if err != nil {
+		logOut.WithError(fmt.Errorf("failed to create gptscript client, error: %w", err)).Error()
+		os.Exit(0)
+	}
+
+	inputData := os.Getenv("GPTSCRIPT_INPUT")
+	input := MetadataInput{}
+
+	if err := json.Unmarshal([]byte(inputData), &input); err != nil {
+		logOut.WithError(fmt.Errorf("failed to unmarshal input data, error: %w", err)).Error()
+		os.Exit(0)
+	}
+	if input.GitConfig == nil || strings.TrimSpace(input.GitConfig.URL) == "" {
+		logOut.WithError(errors.New("no repository URL configured")).Error()
+		os.Exit(0)
+	}
+
+	source := &gitSource{
+		repo:   newRepo(strings.TrimSpace(input.GitConfig.URL), strings.TrimSpace(input.GitConfig.Branch), os.Getenv("GIT_TOKEN")),
+		logErr: logErr,
+	}
+	if source.include, err = datasource.CompileGlobs(input.GitConfig.Include); err == nil {
+		source.exclude, err = datasource.CompileGlobs(input.GitConfig.Exclude)
+	}
+	if err != nil {
+		logOut.WithError(fmt.Errorf("failed to sync git repository, error: %w", err)).Error()
+		os.Exit(0)
+	}
+
+	workspace := &datasource.GPTScriptWorkspace{Client: gptscriptClient}
+	store, closeStore, err := indexstore.FromEnv(ctx, workspace)
+	if err != nil {
+		logOut.WithError(fmt.Errorf("failed to open sync state store, error: %w", err)).Error()
+		os.Exit(0)
+	}
+	defer closeStore()
+
+	syncer := datasource.NewSyncer(source, store, workspace, datasource.Options{})
+	if _, err := syncer.Sync(ctx); err != nil {
+		logOut.WithError(fmt.Errorf("failed to sync git repository, error: %w", err)).Error()
+		os.Exit(0)
+	}
+}
+
+func (s *gitSource) Name() string {
+	return "git"
+}
+
+// List updates the clone and lists the files of the commit. Files that were removed from the repository, or no
+// longer match the patterns, are deleted.
+func (s *gitSource) List(ctx context.Context, fn func([]datasource.Item) error) error {
+	commit, err := s.repo.Update(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to update repository %s: %w", s.repo.url, err)
+	}
+	committedAt, err := s.repo.CommitTime(ctx)
+	if err != nil {
+		return err
+	}
+	s.logErr.Infof("Syncing commit %s", commit)
+
+	entries, err := s.repo.Files(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list files of commit %s: %w", commit, err)
+	}
+
+	items := make([]datasource.Item, 0, len(entries))
+	for _, entry := range entries {
+		if (len(s.include) > 0 && !datasource.MatchesAny(s.include, entry.Path)) || datasource.MatchesAny(s.exclude, entry.Path) {
+			continue
+		}
+		if entry.Size >= maxFileSize {
+			s.logErr.Infof("Skipping %s because it is larger than 50 MB", entry.Path)
+			continue
+		}
+
+		filePath := path.Join(s.repo.Name(), entry.Path)
+		items = append(items, datasource.Item{
+			ID:       filePath,
+			Path:     filePath,
+			URL:      s.repo.FileURL(commit, entry.Path),
+			Version:  entry.Blob,
+			Metadata: map[string]string{"path": entry.Path, "commit": commit, "committedAt": committedAt},
+		})
+	}
+	return fn(items)
+}
+
+// Fetch reads the file from the clone, which is at the commit of the listing
+func (s *gitSource) Fetch(_ context.Context, item datasource.Item) ([]byte, error) {
+	return s.repo.ReadFile(item.Metadata["path"])
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/gptscript-ai/knowledge/pkg/datasource"
+	"github.com/sirupsen/logrus"
+)
+
+func TestRepoName(t *testing.T) {
+	for repoURL, expected := range map[string]string{
+		"https://github.com/obot-platform/tools.git": "tools",
+		"https://gitlab.com/group/sub/project/":      "project",
+		"git@github.com:org/repo.git":                "repo",
+		"git@example.com:repo":                       "repo",
+	} {
+		if name := (&repo{url: repoURL}).Name(); name != expected {
+			t.Errorf("Name() of %s: expected %q, got %q", repoURL, expected, name)
+		}
+	}
+}
+
+func TestFileURL(t *testing.T) {
+	for repoURL, expected := range map[string]string{
+		"https://github.com/obot-platform/tools.git":   "https://github.com/obot-platform/tools/blob/abc/docs/README.md",
+		"https://user@gitlab.com/group/project":        "https://gitlab.com/group/project/-/blob/abc/docs/README.md",
+		"https://git.example.com/project.git":          "https://git.example.com/project.git",
+		"git@github.com:obot-platform/tools.git":       "git@github.com:obot-platform/tools.git",
+		"ssh://git@github.com/obot-platform/tools.git": "ssh://git@github.com/obot-platform/tools.git",
+	} {
+		if u := (&repo{url: repoURL}).FileURL("abc", "docs/README.md"); u != expected {
+			t.Errorf("FileURL() of %s: expected %s, got %s", repoURL, expected, u)
+		}
+	}
+}
+
+func TestGitSource(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	origin := filepath.Join(t.TempDir(), "docs")
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", origin}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(origin, name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(origin, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.MkdirAll(origin, 0755); err != nil {
+		t.Fatal(err)
+	}
+	run("init", "-q", "-b", "main")
+	write("guide/setup.md", "# Setup\n")
+	write("guide/draft.md", "# Draft\n")
+	write("main.go", "package main\n")
+	write("build/out.md", "generated\n")
+	run("add", ".")
+	// Tracked files that match a .gitignore pattern and symlinks are skipped
+	write(".gitignore", "build/\n")
+	if err := os.Symlink("guide/setup.md", filepath.Join(origin, "setup.md")); err != nil {
+		t.Fatal(err)
+	}
+	run("add", ".gitignore", "setup.md")
+	run("commit", "-q", "-m", "docs")
+
+	source := &gitSource{
+		repo:   &repo{url: "file://" + filepath.ToSlash(origin), branch: "main", dir: filepath.Join(t.TempDir(), "clone")},
+		logErr: logrus.New(),
+	}
+	source.logErr.SetOutput(io.Discard)
+	var err error
+	if source.include, err = datasource.CompileGlobs([]string{"**/*.md"}); err != nil {
+		t.Fatal(err)
+	}
+	if source.exclude, err = datasource.CompileGlobs([]string{"**/draft.md"}); err != nil {
+		t.Fatal(err)
+	}
+
+	list := func() []datasource.Item {
+		t.Helper()
+		var items []datasource.Item
+		if err := source.List(context.Background(), func(page []datasource.Item) error {
+			items = append(items, page...)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		return items
+	}
+
+	items := list()
+	if len(items) != 1 {
+		t.Fatalf("expected 1 item, got %+v", items)
+	}
+	item := items[0]
+	if item.Path != "docs/guide/setup.md" || item.Metadata["path"] != "guide/setup.md" || item.Version == "" || item.Metadata["commit"] == "" {
+		t.Errorf("unexpected item %+v", item)
+	}
+	data, err := source.Fetch(context.Background(), item)
+	if err != nil || string(data) != "# Setup\n" {
+		t.Errorf("unexpected content %q, error: %v", data, err)
+	}
+
+	// Updating the clone fetches the new commit, the version only changes with the content
+	write("guide/setup.md", "# Setup\n\nRun make.\n")
+	write("guide/usage.md", "# Usage\n")
+	run("add", ".")
+	run("commit", "-q", "-m", "usage")
+
+	items = list()
+	if len(items) != 2 || items[0].Path != "docs/guide/setup.md" || items[0].Version == item.Version || items[0].Metadata["commit"] == item.Metadata["commit"] {
+		t.Fatalf("unexpected items after the update %+v", items)
+	}
+	if data, err := source.Fetch(context.Background(), items[0]); err != nil || string(data) != "# Setup\n\nRun make.\n" {
+		t.Errorf("unexpected content %q, error: %v", data, err)
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type repo struct {
+	url    string
+	branch string
+	token  string
+	dir    string
+}
+
+// TreeEntry is a file in the tree of a commit.
+type TreeEntry struct {
+	Path string
+	// Blob is the hash of the file content, it only changes if the content changes
+	Blob string
+	Size int64
+}
+
+// newRepo returns the repository, cloned to a cache directory that is kept between syncs so updates only fetch
+// the new commits.
+func newRepo(repoURL, branch, token string) *repo {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = os.TempDir()
+	}
+	sum := sha256.Sum256([]byte(repoURL + "#" + branch))
+	return &repo{
+		url:    repoURL,
+		branch: branch,
+		token:  token,
+		dir:    filepath.Join(cacheDir, "knowledge-git-data-source", hex.EncodeToString(sum[:8])),
+	}
+}
+
+func (r *repo) git(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	command := args[0]
+	if r.token != "" {
+		// Passed per command, so the token is not stored in the git config of the clone
+		auth := base64.StdEncoding.EncodeToString([]byte("x-access-token:" + r.token))
+		args = append([]string{"-c", "http.extraHeader=Authorization: Basic " + auth}, args...)
+	}
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s failed: %w: %s", command, err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// Update clones the repository, or fetches the latest commit of the branch if it was already cloned,
+// and returns the SHA of the checked out commit.
+func (r *repo) Update(ctx context.Context) (string, error) {
+	if _, err := os.Stat(filepath.Join(r.dir, ".git")); err == nil {
+		ref := r.branch
+		if ref == "" {
+			ref = "HEAD"
+		}
+		_, err := r.git(ctx, r.dir, "fetch", "--depth", "1", "origin", ref)
+		if err == nil {
+			_, err = r.git(ctx, r.dir, "reset", "--hard", "FETCH_HEAD")
+		}
+		if err != nil {
+			// Clone again, e.g. if the history was rewritten
+			if err := os.RemoveAll(r.dir); err != nil {
+				return "", err
+			}
+			return r.clone(ctx)
+		}
+		return r.head(ctx)
+	}
+	return r.clone(ctx)
+}
+
+func (r *repo) clone(ctx context.Context) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(r.dir), 0755); err != nil {
+		return "", err
+	}
+	args := []string{"clone", "--depth", "1", "--single-branch"}
+	if r.branch != "" {
+		args = append(args, "--branch", r.branch)
+	}
+	if _, err := r.git(ctx, "", append(args, "--", r.url, r.dir)...); err != nil {
+		return "", err
+	}
+	return r.head(ctx)
+}
+
+func (r *repo) head(ctx context.Context) (string, error) {
+	out, err := r.git(ctx, r.dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// CommitTime returns the commit date of the checked out commit in ISO 8601 format.
+func (r *repo) CommitTime(ctx context.Context) (string, error) {
+	out, err := r.git(ctx, r.dir, "log", "-1", "--format=%cI")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Files returns the files of the checked out commit. Submodules and symlinks are skipped, as well as tracked files
+// that match a .gitignore pattern.
+func (r *repo) Files(ctx context.Context) ([]TreeEntry, error) {
+	ignoredOut, err := r.git(ctx, r.dir, "ls-files", "-z", "--cached", "--ignored", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+	ignored := map[string]bool{}
+	for _, p := range strings.Split(string(ignoredOut), "\x00") {
+		ignored[p] = true
+	}
+
+	out, err := r.git(ctx, r.dir, "ls-tree", "-r", "-z", "-l", "--full-tree", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []TreeEntry
+	for _, line := range strings.Split(string(out), "\x00") {
+		// <mode> <type> <object> <size>\t<path>
+		info, p, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(info)
+		if len(fields) != 4 || fields[1] != "blob" || fields[0] == "120000" || ignored[p] {
+			continue
+		}
+		size, _ := strconv.ParseInt(fields[3], 10, 64)
+		entries = append(entries, TreeEntry{Path: p, Blob: fields[2], Size: size})
+	}
+	return entries, nil
+}
+
+func (r *repo) ReadFile(p string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(r.dir, filepath.FromSlash(p)))
+}
+
+// Name returns the name of the repository, e.g. tools for https://github.com/obot-platform/tools.git
+func (r *repo) Name() string {
+	name := strings.TrimSuffix(path.Base(strings.TrimSuffix(r.url, "/")), ".git")
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		// scp-like URLs, e.g. git@github.com:org/repo
+		name = name[i+1:]
+	}
+	return name
+}
+
+// FileURL returns a permanent link to the file at the commit for GitHub and GitLab, otherwise the repository URL.
+func (r *repo) FileURL(commit, p string) string {
+	u, err := url.Parse(r.url)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return r.url
+	}
+	base := strings.TrimSuffix(strings.TrimSuffix(u.Scheme+"://"+u.Host+u.Path, "/"), ".git")
+	switch u.Host {
+	case "github.com":
+		return base + "/blob/" + commit + "/" + p
+	case "gitlab.com":
+		return base + "/-/blob/" + commit + "/" + p
+	}
+	return r.url
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	driveAPI = "https://www.googleapis.com/drive/v3"
+
+	folderMimeType   = "application/vnd.google-apps.folder"
+	googleAppsPrefix = "application/vnd.google-apps."
+
+	fileFields = "id,name,mimeType,size,modifiedTime,webViewLink,parents,driveId,trashed"
+)
+
+// exportFormats maps the Google Workspace file types to the format they're exported in, so knowledge can ingest them.
+// Other Google Workspace types (forms, drawings, sites, shortcuts, ...) are not synced.
+var exportFormats = map[string]struct {
+	MimeType  string
+	Extension string
+}{
+	"application/vnd.google-apps.document":     {"application/vnd.openxmlformats-officedocument.wordprocessingml.document", ".docx"},
+	"application/vnd.google-apps.spreadsheet":  {"application/pdf", ".pdf"}, // CSV would only contain the first sheet
+	"application/vnd.google-apps.presentation": {"application/pdf", ".pdf"},
+}
+
+// Matches folder and shared drive links, e.g. https://drive.google.com/drive/folders/<id> or https://drive.google.com/open?id=<id>
+var idFromLinkPattern = regexp.MustCompile(`(?:/folders/|/d/|[?&]id=)([a-zA-Z0-9_-]+)`)
+
+type DriveFile struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	MimeType     string   `json:"mimeType"`
+	Size         int64    `json:"size,string"`
+	ModifiedTime string   `json:"modifiedTime"`
+	WebViewLink  string   `json:"webViewLink"`
+	Parents      []string `json:"parents"`
+	DriveID      string   `json:"driveId"`
+	Trashed      bool     `json:"trashed"`
+}
+
+func (f DriveFile) IsFolder() bool {
+	return f.MimeType == folderMimeType
+}
+
+type Change struct {
+	FileID  string     `json:"fileId"`
+	Removed bool       `json:"removed"`
+	File    *DriveFile `json:"file"`
+}
+
+type driveClient struct {
+	token string
+}
+
+func idFromLink(s string) string {
+	if m := idFromLinkPattern.FindStringSubmatch(s); m != nil {
+		return m[1]
+	}
+	return s
+}
+
+func (c driveClient) do(ctx context.Context, path string, query url.Values) (io.ReadCloser, error) {
+	query.Set("supportsAllDrives", "true")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, driveAPI+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Error.Message != "" {
+			return nil, fmt.Errorf("google drive request %s failed with status %d: %s", path, resp.StatusCode, apiErr.Error.Message)
+		}
+		return nil, fmt.Errorf("google drive request %s failed with status %d: %s", path, resp.StatusCode, string(body))
+	}
+	return resp.Body, nil
+}
+
+func (c driveClient) get(ctx context.Context, path string, query url.Values, v any) error {
+	body, err := c.do(ctx, path, query)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return json.NewDecoder(body).Decode(v)
+}
+
+func (c driveClient) GetFile(ctx context.Context, id string) (DriveFile, error) {
+	var f DriveFile
+	return f, c.get(ctx, "/files/"+url.PathEscape(id), url.Values{"fields": {fileFields}}, &f)
+}
+
+func (c driveClient) GetSharedDriveName(ctx context.Context, id string) (string, error) {
+	var d struct {
+		Name string `json:"name"`
+	}
+	return d.Name, c.get(ctx, "/drives/"+url.PathEscape(id), url.Values{"fields": {"name"}}, &d)
+}
+
+// ListChildren returns all files and folders directly inside the folder.
+func (c driveClient) ListChildren(ctx context.Context, folderID, driveID string) ([]DriveFile, error) {
+	query := url.Values{
+		"q":                         {fmt.Sprintf("'%s' in parents and trashed = false", strings.ReplaceAll(folderID, "'", `\'`))},
+		"fields":                    {"nextPageToken,files(" + fileFields + ")"},
+		"pageSize":                  {"1000"},
+		"includeItemsFromAllDrives": {"true"},
+	}
+	if driveID != "" {
+		query.Set("corpora", "drive")
+		query.Set("driveId", driveID)
+	}
+
+	var files []DriveFile
+	for {
+		var page struct {
+			NextPageToken string      `json:"nextPageToken"`
+			Files         []DriveFile `json:"files"`
+		}
+		if err := c.get(ctx, "/files", query, &page); err != nil {
+			return nil, err
+		}
+		files = append(files, page.Files...)
+		if page.NextPageToken == "" {
+			return files, nil
+		}
+		query.Set("pageToken", page.NextPageToken)
+	}
+}
+
+// GetStartPageToken returns the token for listing future changes of the user's drive, or of the shared drive if driveID is set.
+func (c driveClient) GetStartPageToken(ctx context.Context, driveID string) (string, error) {
+	query := url.Values{}
+	if driveID != "" {
+		query.Set("driveId", driveID)
+	}
+	var resp struct {
+		StartPageToken string `json:"startPageToken"`
+	}
+	return resp.StartPageToken, c.get(ctx, "/changes/startPageToken", query, &resp)
+}
+
+// ListChanges returns all changes since the page token and the token to use for the next sync.
+func (c driveClient) ListChanges(ctx context.Context, driveID, pageToken string) ([]Change, string, error) {
+	query := url.Values{
+		"fields":                    {"nextPageToken,newStartPageToken,changes(fileId,removed,file(" + fileFields + "))"},
+		"pageSize":                  {"1000"},
+		"includeItemsFromAllDrives": {"true"},
+		"includeRemoved":            {"true"},
+	}
+	if driveID != "" {
+		query.Set("driveId", driveID)
+	}
+
+	var changes []Change
+	for {
+		query.Set("pageToken", pageToken)
+		var page struct {
+			NextPageToken     string   `json:"nextPageToken"`
+			NewStartPageToken string   `json:"newStartPageToken"`
+			Changes           []Change `json:"changes"`
+		}
+		if err := c.get(ctx, "/changes", query, &page); err != nil {
+			return nil, "", err
+		}
+		changes = append(changes, page.Changes...)
+		if page.NewStartPageToken != "" {
+			return changes, page.NewStartPageToken, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+// Content downloads the file, Google Workspace files are exported to the given format.
+func (c driveClient) Content(ctx context.Context, fileID, exportMimeType string) ([]byte, error) {
+	var (
+		body io.ReadCloser
+		err  error
+	)
+	if exportMimeType != "" {
+		body, err = c.do(ctx, "/files/"+url.PathEscape(fileID)+"/export", url.Values{"mimeType": {exportMimeType}})
+	} else {
+		body, err = c.do(ctx, "/files/"+url.PathEscape(fileID), url.Values{"alt": {"media"}})
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
@@ -0,0 +1,325 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/knowledge/pkg/datasource"
+	"github.com/gptscript-ai/knowledge/pkg/datasource/indexstore"
+	"github.com/sirupsen/logrus"
+)
+
+// We only sync files that are less than 50 MB, as most of the bigger files won't be supported by knowledge
+const maxFileSize = 1024 * 1024 * 50
+
+type MetadataInput struct {
+	GoogleDriveConfig *GoogleDriveConfig `json:"googleDriveConfig,omitempty"`
+}
+
+type GoogleDriveConfig struct {
+	// Folders are folder IDs or links
+	Folders []string `json:"folders"`
+	// SharedDrives are shared drive IDs or links
+	SharedDrives []string `json:"sharedDrives"`
+}
+
+type RootState struct {
+	Name          string `json:"name"`
+	IsSharedDrive bool   `json:"isSharedDrive"`
+	DriveID       string `json:"driveId,omitempty"`
+}
+
+type driveState struct {
+	// Folders maps the IDs of all synced folders (including the roots) to their path in the workspace
+	Folders map[string]string `json:"folders,omitempty"`
+}
+
+// driveSource lists the files of the configured folders and shared drives. Changes are listed with the changes API,
+// with one page token per drive ("" for the user's drive) as cursor.
+type driveSource struct {
+	drive  driveClient
+	logErr *logrus.Logger
+	// roots are the synced folders and shared drives by ID
+	roots map[string]RootState
+	state driveState
+}
+
+func main() {
+	logOut := logrus.New()
+	logOut.SetOutput(os.Stdout)
+	logOut.SetFormatter(&logrus.JSONFormatter{})
+	logErr := logrus.New()
+	logErr.SetOutput(os.Stderr)
+
+	ctx := context.Background()
+	gptscriptClient, err := gptscript.NewGPTScript()
+	if err != nil {
+		logOut.WithError(fmt.Errorf("failed to create gptscript client, error: %w", err)).Error()
+		os.Exit(0)
+	}
+
+	inputData := os.Getenv("GPTSCRIPT_INPUT")
+	input := MetadataInput{}
+
+	if err := json.Unmarshal([]byte(inputData), &input); err != nil {
+		logOut.WithError(fmt.Errorf("failed to unmarshal input data, error: %w", err)).Error()
+		os.Exit(0)
+	}
+	if input.GoogleDriveConfig == nil {
+		input.GoogleDriveConfig = &GoogleDriveConfig{}
+	}
+
+	source := &driveSource{
+		drive:  driveClient{token: os.Getenv("GOOGLE_OAUTH_TOKEN")},
+		logErr: logErr,
+	}
+	if err := source.resolveRoots(ctx, input.GoogleDriveConfig); err != nil {
+		logOut.WithError(fmt.Errorf("failed to sync google drive, error: %w", err)).Error()
+		os.Exit(0)
+	}
+	// Renamed roots change the paths of all files, so they are part of the config
+	roots, err := json.Marshal(source.roots)
+	if err != nil {
+		logOut.WithError(fmt.Errorf("failed to marshal roots, error: %w", err)).Error()
+		os.Exit(0)
+	}
+
+	workspace := &datasource.GPTScriptWorkspace{Client: gptscriptClient}
+	store, closeStore, err := indexstore.FromEnv(ctx, workspace)
+	if err != nil {
+		logOut.WithError(fmt.Errorf("failed to open sync state store, error: %w", err)).Error()
+		os.Exit(0)
+	}
+	defer closeStore()
+
+	syncer := datasource.NewSyncer(source, store, workspace, datasource.Options{
+		Config: string(roots),
+	})
+	if _, err := syncer.Sync(ctx); err != nil {
+		logOut.WithError(fmt.Errorf("failed to sync google drive, error: %w", err)).Error()
+		os.Exit(0)
+	}
+}
+
+func (s *driveSource) resolveRoots(ctx context.Context, config *GoogleDriveConfig) error {
+	s.roots = map[string]RootState{}
+	for _, link := range config.Folders {
+		link = strings.TrimSpace(link)
+		if link == "" {
+			continue
+		}
+		folder, err := s.drive.GetFile(ctx, idFromLink(link))
+		if err != nil {
+			return fmt.Errorf("failed to get folder %q: %w", link, err)
+		}
+		if !folder.IsFolder() {
+			return fmt.Errorf("%q is not a folder", link)
+		}
+		s.roots[folder.ID] = RootState{Name: folder.Name, DriveID: folder.DriveID}
+	}
+	for _, link := range config.SharedDrives {
+		link = strings.TrimSpace(link)
+		if link == "" {
+			continue
+		}
+		id := idFromLink(link)
+		name, err := s.drive.GetSharedDriveName(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get shared drive %q: %w", link, err)
+		}
+		// The root folder of a shared drive has the ID of the drive
+		s.roots[id] = RootState{Name: name, IsSharedDrive: true, DriveID: id}
+	}
+	return nil
+}
+
+func (s *driveSource) Name() string {
+	return "google-drive"
+}
+
+// List walks all roots, one page per folder
+func (s *driveSource) List(ctx context.Context, fn func([]datasource.Item) error) error {
+	s.state.Folders = map[string]string{}
+	for id, root := range s.roots {
+		if err := s.walk(ctx, id, root.DriveID, datasource.SanitizeName(root.Name), fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *driveSource) walk(ctx context.Context, folderID, driveID, folderPath string, fn func([]datasource.Item) error) error {
+	s.state.Folders[folderID] = folderPath
+
+	children, err := s.drive.ListChildren(ctx, folderID, driveID)
+	if err != nil {
+		return fmt.Errorf("failed to list folder %q: %w", folderPath, err)
+	}
+
+	var (
+		items   []datasource.Item
+		folders []DriveFile
+	)
+	for _, child := range children {
+		if child.IsFolder() {
+			folders = append(folders, child)
+		} else if item, ok := s.item(child, folderPath); ok {
+			items = append(items, item)
+		}
+	}
+	if err := fn(items); err != nil {
+		return err
+	}
+
+	for _, folder := range folders {
+		if err := s.walk(ctx, folder.ID, driveID, path.Join(folderPath, datasource.SanitizeName(folder.Name)), fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Cursor returns the start page tokens of all drives of the roots
+func (s *driveSource) Cursor(ctx context.Context) (string, error) {
+	tokens := map[string]string{}
+	for _, root := range s.roots {
+		if _, ok := tokens[root.DriveID]; ok {
+			continue
+		}
+		token, err := s.drive.GetStartPageToken(ctx, root.DriveID)
+		if err != nil {
+			return "", err
+		}
+		tokens[root.DriveID] = token
+	}
+	data, err := json.Marshal(tokens)
+	return string(data), err
+}
+
+// Changes lists the changes of each drive. Changes to folders would require moving or removing all files below
+// them, so in that case a full sync is required instead.
+func (s *driveSource) Changes(ctx context.Context, cursor string, fn func([]datasource.Change, string) error) error {
+	tokens := map[string]string{}
+	if err := json.Unmarshal([]byte(cursor), &tokens); err != nil {
+		return fmt.Errorf("invalid cursor: %w", datasource.ErrFullSyncRequired)
+	}
+
+	for driveID, token := range tokens {
+		driveChanges, newToken, err := s.drive.ListChanges(ctx, driveID, token)
+		if err != nil {
+			return fmt.Errorf("failed to list changes: %w", err)
+		}
+
+		var changes []datasource.Change
+		for _, change := range driveChanges {
+			removed := change.Removed || change.File == nil || change.File.Trashed
+			if folderPath, ok := s.state.Folders[change.FileID]; ok {
+				if removed {
+					return fmt.Errorf("folder %q was removed: %w", folderPath, datasource.ErrFullSyncRequired)
+				}
+				// Renamed roots are detected by the config
+				if _, isRoot := s.roots[change.FileID]; isRoot {
+					continue
+				}
+				// Renamed or moved
+				if parentPath, ok := s.folderPath(*change.File); !ok || path.Join(parentPath, datasource.SanitizeName(change.File.Name)) != folderPath {
+					return fmt.Errorf("folder %q was moved: %w", folderPath, datasource.ErrFullSyncRequired)
+				}
+				continue
+			}
+
+			if removed {
+				changes = append(changes, datasource.Change{Item: datasource.Item{ID: change.FileID}, Deleted: true})
+				continue
+			}
+
+			if change.File.IsFolder() {
+				// New folders inside a synced folder need to be walked
+				if slices.ContainsFunc(change.File.Parents, func(p string) bool { _, ok := s.state.Folders[p]; return ok }) {
+					return fmt.Errorf("folder %q was added: %w", change.File.Name, datasource.ErrFullSyncRequired)
+				}
+				continue
+			}
+
+			// Files moved out of the synced folders, or that are no longer supported, are removed
+			folderPath, ok := s.folderPath(*change.File)
+			if !ok {
+				changes = append(changes, datasource.Change{Item: datasource.Item{ID: change.FileID}, Deleted: true})
+				continue
+			}
+			item, ok := s.item(*change.File, folderPath)
+			changes = append(changes, datasource.Change{Item: item, Deleted: !ok})
+		}
+
+		tokens[driveID] = newToken
+		data, err := json.Marshal(tokens)
+		if err != nil {
+			return err
+		}
+		if err := fn(changes, string(data)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Fetch downloads the file, Google Workspace files are exported
+func (s *driveSource) Fetch(ctx context.Context, item datasource.Item) ([]byte, error) {
+	return s.drive.Content(ctx, item.ID, item.Metadata["exportMimeType"])
+}
+
+func (s *driveSource) LoadState(data json.RawMessage) error {
+	s.state = driveState{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &s.state); err != nil {
+			return err
+		}
+	}
+	if s.state.Folders == nil {
+		s.state.Folders = make(map[string]string)
+	}
+	return nil
+}
+
+func (s *driveSource) SaveState() (json.RawMessage, error) {
+	return json.Marshal(s.state)
+}
+
+func (s *driveSource) folderPath(file DriveFile) (string, bool) {
+	for _, parent := range file.Parents {
+		if p, ok := s.state.Folders[parent]; ok {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// item returns the item of the file, it returns false if the file type or size is not supported
+func (s *driveSource) item(file DriveFile, folderPath string) (datasource.Item, bool) {
+	item := datasource.Item{ID: file.ID, URL: file.WebViewLink, Version: file.ModifiedTime}
+
+	name := datasource.SanitizeName(file.Name)
+	if strings.HasPrefix(file.MimeType, googleAppsPrefix) {
+		format, ok := exportFormats[file.MimeType]
+		if !ok {
+			s.logErr.Infof("Skipping %s because Google file type %s is not supported", path.Join(folderPath, name), file.MimeType)
+			return item, false
+		}
+		item.Metadata = map[string]string{"exportMimeType": format.MimeType}
+		if !strings.EqualFold(path.Ext(name), format.Extension) {
+			name += format.Extension
+		}
+	} else if file.Size >= maxFileSize {
+		s.logErr.Infof("Skipping %s because it is larger than 50 MB", path.Join(folderPath, name))
+		return item, false
+	}
+
+	item.Path = path.Join(folderPath, name)
+	return item, true
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/gptscript-ai/knowledge/pkg/datasource"
+	"github.com/sirupsen/logrus"
+)
+
+func TestIDFromLink(t *testing.T) {
+	for s, expected := range map[string]string{
+		"https://drive.google.com/drive/folders/1AbC-d_E":        "1AbC-d_E",
+		"https://drive.google.com/drive/u/0/folders/0AFx?usp=sh": "0AFx",
+		"https://drive.google.com/open?id=1xyz":                  "1xyz",
+		"https://docs.google.com/document/d/1doc/edit":           "1doc",
+		"1AbC-d_E": "1AbC-d_E",
+	} {
+		if id := idFromLink(s); id != expected {
+			t.Errorf("idFromLink(%q): expected %q, got %q", s, expected, id)
+		}
+	}
+}
+
+func TestItem(t *testing.T) {
+	s := &driveSource{logErr: logrus.New()}
+	s.logErr.SetOutput(io.Discard)
+
+	item, ok := s.item(DriveFile{ID: "1", Name: "Roadmap", MimeType: "application/vnd.google-apps.document", ModifiedTime: "v1", WebViewLink: "https://docs.google.com/document/d/1"}, "Team")
+	if !ok || item.Path != "Team/Roadmap.docx" || item.Version != "v1" || item.URL != "https://docs.google.com/document/d/1" ||
+		item.Metadata["exportMimeType"] != "application/vnd.openxmlformats-officedocument.wordprocessingml.document" {
+		t.Errorf("unexpected item %+v", item)
+	}
+
+	// The extension isn't added twice
+	if item, ok := s.item(DriveFile{ID: "2", Name: "Budget.PDF", MimeType: "application/vnd.google-apps.spreadsheet"}, "Team"); !ok || item.Path != "Team/Budget.PDF" {
+		t.Errorf("unexpected item %+v", item)
+	}
+
+	if item, ok := s.item(DriveFile{ID: "3", Name: "notes.txt", MimeType: "text/plain", Size: 10}, "Team"); !ok || item.Path != "Team/notes.txt" || item.Metadata != nil {
+		t.Errorf("unexpected item %+v", item)
+	}
+
+	if _, ok := s.item(DriveFile{ID: "4", Name: "Survey", MimeType: "application/vnd.google-apps.form"}, "Team"); ok {
+		t.Error("expected unsupported Google file types to be skipped")
+	}
+	if _, ok := s.item(DriveFile{ID: "5", Name: "video.mp4", MimeType: "video/mp4", Size: maxFileSize}, "Team"); ok {
+		t.Error("expected files over the size limit to be skipped")
+	}
+}
+
+func TestState(t *testing.T) {
+	s := &driveSource{}
+	if err := s.LoadState(json.RawMessage(`{"folders": {"root": "Team", "sub": "Team/Plans"}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := s.folderPath(DriveFile{Parents: []string{"other", "sub"}}); !ok || p != "Team/Plans" {
+		t.Errorf("unexpected folder path %q", p)
+	}
+	if _, ok := s.folderPath(DriveFile{Parents: []string{"other"}}); ok {
+		t.Error("expected files outside of the synced folders to be skipped")
+	}
+
+	data, err := s.SaveState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"folders":{"root":"Team","sub":"Team/Plans"}}` {
+		t.Errorf("unexpected state %s", data)
+	}
+
+	if err := s.LoadState(nil); err != nil || s.state.Folders == nil {
+		t.Errorf("expected empty folders, got %v, error: %v", s.state.Folders, err)
+	}
+
+	if err := s.Changes(context.Background(), "not a cursor", nil); !errors.Is(err, datasource.ErrFullSyncRequired) {
+		t.Errorf("expected a full sync for an invalid cursor, got %v", err)
+	}
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gptscript-ai/knowledge/pkg/datasource"
+)
+
+const (
+	accessibleResourcesURL = "https://api.atlassian.com/oauth/token/accessible-resources"
+	jiraAPI                = "https://api.atlassian.com/ex/jira/%s/rest/api/3"
+
+	pageLimit = 100
+	// idPageLimit is the page size when only the IDs, versions and metadata of the issues are listed
+	idPageLimit = 1000
+)
+
+// issueFields are the fields of an issue that are synced, in addition to the configured custom fields
+var issueFields = []string{
+	"summary", "description", "issuetype", "status", "priority", "resolution", "project", "parent",
+	"assignee", "reporter", "labels", "components", "fixVersions", "created", "updated", "resolutiondate", "comment",
+}
+
+type Site struct {
+	ID   string `json:"id"`
+	URL  string `json:"url"`
+	Name string `json:"name"`
+}
+
+// Field is a system or custom field of the Jira site.
+type Field struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Custom bool   `json:"custom"`
+}
+
+type Issue struct {
+	ID     string      `json:"id"`
+	Key    string      `json:"key"`
+	Fields IssueFields `json:"fields"`
+}
+
+type IssueFields struct {
+	Summary     string                   `json:"summary"`
+	Description *datasource.RichTextNode `json:"description"`
+	IssueType   *named                   `json:"issuetype"`
+	Status      *named                   `json:"status"`
+	Priority    *named                   `json:"priority"`
+	Resolution  *named                   `json:"resolution"`
+	Project     struct {
+		Key  string `json:"key"`
+		Name string `json:"name"`
+	} `json:"project"`
+	Parent *struct {
+		Key string `json:"key"`
+	} `json:"parent"`
+	Assignee       *user    `json:"assignee"`
+	Reporter       *user    `json:"reporter"`
+	Labels         []string `json:"labels"`
+	Components     []named  `json:"components"`
+	FixVersions    []named  `json:"fixVersions"`
+	Created        string   `json:"created"`
+	Updated        string   `json:"updated"`
+	ResolutionDate string   `json:"resolutiondate"`
+	Comment        *struct {
+		Comments []Comment `json:"comments"`
+		Total    int       `json:"total"`
+	} `json:"comment"`
+
+	// Custom are the values of the custom fields by field ID
+	Custom map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON also keeps the raw values of all fields, so the values of the configured custom fields are available
+func (f *IssueFields) UnmarshalJSON(data []byte) error {
+	type plain IssueFields
+	if err := json.Unmarshal(data, (*plain)(f)); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &f.Custom)
+}
+
+type Comment struct {
+	ID      string                   `json:"id"`
+	Author  *user                    `json:"author"`
+	Body    *datasource.RichTextNode `json:"body"`
+	Created string                   `json:"created"`
+	Updated string                   `json:"updated"`
+}
+
+type named struct {
+	Name string `json:"name"`
+}
+
+func (n *named) String() string {
+	if n == nil {
+		return ""
+	}
+	return n.Name
+}
+
+type user struct {
+	DisplayName string `json:"displayName"`
+}
+
+func (u *user) String() string {
+	if u == nil {
+		return ""
+	}
+	return u.DisplayName
+}
+
+type jiraClient struct {
+	token string
+	// baseURL is the REST API of the Jira site, set by selectSite
+	baseURL string
+	site    Site
+}
+
+func (c *jiraClient) get(ctx context.Context, rawURL string, query url.Values, v any) error {
+	if len(query) > 0 {
+		rawURL += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("jira request %s failed with status %d: %s", strings.TrimPrefix(rawURL, c.baseURL), resp.StatusCode, string(body))
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// selectSite picks the Jira site the token has access to. If siteURL is set the site with that URL is used,
+// otherwise the token must only have access to a single site.
+func (c *jiraClient) selectSite(ctx context.Context, siteURL string) error {
+	var sites []Site
+	if err := c.get(ctx, accessibleResourcesURL, nil, &sites); err != nil {
+		return err
+	}
+
+	siteURL = strings.TrimSuffix(strings.TrimSpace(siteURL), "/")
+	var candidates []Site
+	for _, site := range sites {
+		if siteURL == "" || strings.EqualFold(strings.TrimSuffix(site.URL, "/"), siteURL) {
+			candidates = append(candidates, site)
+		}
+	}
+
+	switch {
+	case len(candidates) == 0 && siteURL != "":
+		return fmt.Errorf("no access to Jira site %s", siteURL)
+	case len(candidates) == 0:
+		return fmt.Errorf("no access to any Jira site")
+	case len(candidates) > 1:
+		urls := make([]string, 0, len(candidates))
+		for _, site := range candidates {
+			urls = append(urls, site.URL)
+		}
+		return fmt.Errorf("access to multiple Jira sites, set the site to sync: %s", strings.Join(urls, ", "))
+	}
+
+	c.site = candidates[0]
+	c.baseURL = fmt.Sprintf(jiraAPI, c.site.ID)
+	return nil
+}
+
+// IssueURL returns the link to the issue in the Jira UI.
+func (c *jiraClient) IssueURL(key string) string {
+	return strings.TrimSuffix(c.site.URL, "/") + "/browse/" + url.PathEscape(key)
+}
+
+// ListFields returns all system and custom fields.
+func (c *jiraClient) ListFields(ctx context.Context) ([]Field, error) {
+	var fields []Field
+	return fields, c.get(ctx, c.baseURL+"/field", nil, &fields)
+}
+
+// SearchIssues calls fn with the issues matching the JQL query, page by page. Only the given fields are returned.
+func (c *jiraClient) SearchIssues(ctx context.Context, jql string, fields []string, pageSize int, fn func([]Issue) error) error {
+	query := url.Values{
+		"jql":        {jql},
+		"fields":     {strings.Join(fields, ",")},
+		"maxResults": {strconv.Itoa(pageSize)},
+	}
+	for {
+		var resp struct {
+			Issues        []Issue `json:"issues"`
+			NextPageToken string  `json:"nextPageToken"`
+			IsLast        bool    `json:"isLast"`
+		}
+		if err := c.get(ctx, c.baseURL+"/search/jql", query, &resp); err != nil {
+			return err
+		}
+		if err := fn(resp.Issues); err != nil {
+			return err
+		}
+		if resp.IsLast || resp.NextPageToken == "" {
+			return nil
+		}
+		query.Set("nextPageToken", resp.NextPageToken)
+	}
+}
+
+// GetIssue returns the issue with the given fields.
+func (c *jiraClient) GetIssue(ctx context.Context, id string, fields []string) (Issue, error) {
+	var issue Issue
+	return issue, c.get(ctx, c.baseURL+"/issue/"+url.PathEscape(id), url.Values{"fields": {strings.Join(fields, ",")}}, &issue)
+}
+
+// ListComments returns all comments of the issue, oldest first.
+func (c *jiraClient) ListComments(ctx context.Context, issueID string) ([]Comment, error) {
+	query := url.Values{
+		"orderBy":    {"created"},
+		"maxResults": {strconv.Itoa(pageLimit)},
+	}
+
+	var comments []Comment
+	for start := 0; ; start += pageLimit {
+		query.Set("startAt", strconv.Itoa(start))
+		var resp struct {
+			Comments []Comment `json:"comments"`
+			Total    int       `json:"total"`
+		}
+		if err := c.get(ctx, c.baseURL+"/issue/"+url.PathEscape(issueID)+"/comment", query, &resp); err != nil {
+			return nil, err
+		}
+		comments = append(comments, resp.Comments...)
+		if len(resp.Comments) < pageLimit || len(comments) >= resp.Total {
+			return comments, nil
+		}
+	}
+}
@@ -0,0 +1,429 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/knowledge/pkg/datasource"
+	"github.com/gptscript-ai/knowledge/pkg/datasource/indexstore"
+	"github.com/sirupsen/logrus"
+)
+
+// incrementalOverlap is added to the time since the last sync, so issues updated during the last sync (or with a
+// skewed clock) are not missed
+const incrementalOverlap = 5 * time.Minute
+
+var orderByClause = regexp.MustCompile(`(?is)\s*\border\s+by\b.*$`)
+
+type MetadataInput struct {
+	JiraConfig *JiraConfig `json:"jiraConfig,omitempty"`
+}
+
+type JiraConfig struct {
+	// Site is the URL of the Jira site, e.g. https://example.atlassian.net. It is only required if the account has
+	// access to multiple sites.
+	Site string `json:"site,omitempty"`
+	// Projects are the keys of the projects to sync
+	Projects []string `json:"projects,omitempty"`
+	// JQL selects the issues to sync, e.g. "type = Bug AND created >= -365d". Combined with the projects if both are
+	// set, at least one of them is required.
+	JQL string `json:"jql,omitempty"`
+	// CustomFields are the IDs (e.g. customfield_10016) or names (e.g. Story Points) of the custom fields that are
+	// added to the metadata of the issues
+	CustomFields []string `json:"customFields,omitempty"`
+}
+
+// jiraConfig is the resolved configuration, all issues are listed again if it changes
+type jiraConfig struct {
+	Query string `json:"query"`
+	// CustomFields change the front matter of all issues
+	CustomFields []Field `json:"customFields,omitempty"`
+}
+
+// jiraSource lists the issues matching the query. The cursor is the start time of the last sync, the changes are
+// the issues updated since then. Deleted issues and issues that no longer match the query are only removed by the
+// daily full sync.
+type jiraSource struct {
+	jira   *jiraClient
+	logErr *logrus.Logger
+	config jiraConfig
+	// issues are the issues of the current page of changes, which are returned with all fields
+	issues map[string]Issue
+}
+
+func main() {
+	logOut := logrus.New()
+	logOut.SetOutput(os.Stdout)
+	logOut.SetFormatter(&logrus.JSONFormatter{})
+	logErr := logrus.New()
+	logErr.SetOutput(os.Stderr)
+
+	ctx := context.Background()
+	gptscriptClient, err := gptscript.NewGPTScript()
+	if err != nil {
+		logOut.WithError(fmt.Errorf("failed to create gptscript client, error: %w", err)).Error()
+		os.Exit(0)
+	}
+
+	inputData := os.Getenv("GPTSCRIPT_INPUT")
+	input := MetadataInput{}
+
+	if err := json.Unmarshal([]byte(inputData), &input); err != nil {
+		logOut.WithError(fmt.Errorf("failed to unmarshal input data, error: %w", err)).Error()
+		os.Exit(0)
+	}
+	if input.JiraConfig == nil {
+		input.JiraConfig = &JiraConfig{}
+	}
+
+	source := &jiraSource{
+		jira:   &jiraClient{token: os.Getenv("ATLASSIAN_OAUTH_TOKEN")},
+		logErr: logErr,
+	}
+	if err := source.resolveConfig(ctx, input.JiraConfig); err != nil {
+		logOut.WithError(fmt.Errorf("failed to sync jira, error: %w", err)).Error()
+		os.Exit(0)
+	}
+	config, err := json.Marshal(source.config)
+	if err != nil {
+		logOut.WithError(fmt.Errorf("failed to marshal config, error: %w", err)).Error()
+		os.Exit(0)
+	}
+
+	workspace := &datasource.GPTScriptWorkspace{Client: gptscriptClient}
+	store, closeStore, err := indexstore.FromEnv(ctx, workspace)
+	if err != nil {
+		logOut.WithError(fmt.Errorf("failed to open sync state store, error: %w", err)).Error()
+		os.Exit(0)
+	}
+	defer closeStore()
+
+	syncer := datasource.NewSyncer(source, store, workspace, datasource.Options{
+		Config: string(config),
+	})
+	if _, err := syncer.Sync(ctx); err != nil {
+		logOut.WithError(fmt.Errorf("failed to sync jira, error: %w", err)).Error()
+		os.Exit(0)
+	}
+}
+
+func (s *jiraSource) resolveConfig(ctx context.Context, config *JiraConfig) error {
+	query, err := baseQuery(config)
+	if err != nil {
+		return err
+	}
+	if err := s.jira.selectSite(ctx, config.Site); err != nil {
+		return err
+	}
+	customFields, err := s.resolveCustomFields(ctx, config.CustomFields)
+	if err != nil {
+		return err
+	}
+	s.config = jiraConfig{Query: query, CustomFields: customFields}
+	return nil
+}
+
+func (s *jiraSource) Name() string {
+	return "jira"
+}
+
+// List lists all issues matching the query with the fields of their metadata only, changed issues are fetched with
+// all fields
+func (s *jiraSource) List(ctx context.Context, fn func([]datasource.Item) error) error {
+	listFields := append([]string{"project", "status", "updated"}, fieldIDs(s.config.CustomFields)...)
+	return s.jira.SearchIssues(ctx, s.config.Query, listFields, idPageLimit, func(issues []Issue) error {
+		items := make([]datasource.Item, 0, len(issues))
+		for _, issue := range issues {
+			items = append(items, s.item(issue))
+		}
+		return fn(items)
+	})
+}
+
+// Cursor returns the current time
+func (s *jiraSource) Cursor(context.Context) (string, error) {
+	return time.Now().UTC().Format(time.RFC3339), nil
+}
+
+// Changes lists the issues matching the query that were updated since the cursor. Relative times are used in the
+// query, because absolute times are interpreted in the timezone of the user. The new cursor is only returned with
+// the last page, so an interrupted sync starts over.
+func (s *jiraSource) Changes(ctx context.Context, cursor string, fn func([]datasource.Change, string) error) error {
+	lastSync, err := time.Parse(time.RFC3339, cursor)
+	if err != nil {
+		return fmt.Errorf("invalid cursor: %w", datasource.ErrFullSyncRequired)
+	}
+
+	startedAt := time.Now().UTC()
+	minutes := int((startedAt.Sub(lastSync) + incrementalOverlap).Minutes())
+	updatedQuery := fmt.Sprintf("%s AND updated >= -%dm", s.config.Query, minutes)
+	s.logErr.Infof("Syncing issues updated in the last %d minutes", minutes)
+
+	if err := s.jira.SearchIssues(ctx, updatedQuery, fields(s.config.CustomFields), pageLimit, func(issues []Issue) error {
+		s.issues = make(map[string]Issue, len(issues))
+		changes := make([]datasource.Change, 0, len(issues))
+		for _, issue := range issues {
+			s.issues[issue.ID] = issue
+			changes = append(changes, datasource.Change{Item: s.item(issue)})
+		}
+		return fn(changes, cursor)
+	}); err != nil {
+		return fmt.Errorf("failed to search updated issues: %w", err)
+	}
+	s.issues = nil
+	return fn(nil, startedAt.Format(time.RFC3339))
+}
+
+// Fetch renders the issue with its comments as Markdown
+func (s *jiraSource) Fetch(ctx context.Context, item datasource.Item) ([]byte, error) {
+	issue, ok := s.issues[item.ID]
+	if !ok {
+		var err error
+		if issue, err = s.jira.GetIssue(ctx, item.ID, fields(s.config.CustomFields)); err != nil {
+			return nil, fmt.Errorf("failed to get issue %s: %w", item.Metadata["key"], err)
+		}
+	}
+
+	var comments []Comment
+	if c := issue.Fields.Comment; c != nil {
+		comments = c.Comments
+		if c.Total > len(c.Comments) {
+			// Issues only include the first comments
+			var err error
+			if comments, err = s.jira.ListComments(ctx, issue.ID); err != nil {
+				return nil, fmt.Errorf("failed to list comments of %s: %w", issue.Key, err)
+			}
+		}
+	}
+
+	values := customFieldValues(issue, s.config.CustomFields)
+	return []byte(renderIssue(issue, comments, s.config.CustomFields, values, item.URL)), nil
+}
+
+// item returns the item of the issue, written to <project>/<issue key>.md
+func (s *jiraSource) item(issue Issue) datasource.Item {
+	metadata := customFieldValues(issue, s.config.CustomFields)
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	metadata["key"] = issue.Key
+	metadata["project"] = issue.Fields.Project.Key
+	if status := issue.Fields.Status.String(); status != "" {
+		metadata["status"] = status
+	}
+
+	// The front matter changes with the custom fields
+	version := issue.Fields.Updated
+	if ids := fieldIDs(s.config.CustomFields); len(ids) > 0 {
+		version += ";" + strings.Join(ids, ",")
+	}
+
+	return datasource.Item{
+		ID:       issue.ID,
+		Path:     path.Join(datasource.SanitizeName(issue.Fields.Project.Key), datasource.SanitizeName(issue.Key)+".md"),
+		URL:      s.jira.IssueURL(issue.Key),
+		Version:  version,
+		Metadata: metadata,
+	}
+}
+
+// resolveCustomFields looks up the custom fields by ID or name
+func (s *jiraSource) resolveCustomFields(ctx context.Context, names []string) ([]Field, error) {
+	var wanted []string
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			wanted = append(wanted, name)
+		}
+	}
+	if len(wanted) == 0 {
+		return nil, nil
+	}
+
+	all, err := s.jira.ListFields(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fields: %w", err)
+	}
+
+	fields := make([]Field, 0, len(wanted))
+	for _, name := range wanted {
+		i := slices.IndexFunc(all, func(f Field) bool {
+			return f.ID == name || strings.EqualFold(f.Name, name)
+		})
+		if i < 0 {
+			return nil, fmt.Errorf("unknown field %q", name)
+		}
+		fields = append(fields, Field{ID: all[i].ID, Name: all[i].Name, Custom: all[i].Custom})
+	}
+	return fields, nil
+}
+
+// baseQuery combines the projects and the JQL of the configuration. The ORDER BY clause of the JQL is dropped, so
+// more conditions can be added to the query.
+func baseQuery(config *JiraConfig) (string, error) {
+	var clauses []string
+	var projects []string
+	for _, project := range config.Projects {
+		if project = strings.TrimSpace(project); project != "" {
+			projects = append(projects, fmt.Sprintf("%q", project))
+		}
+	}
+	if len(projects) > 0 {
+		clauses = append(clauses, fmt.Sprintf("project in (%s)", strings.Join(projects, ", ")))
+	}
+	if jql := strings.TrimSpace(orderByClause.ReplaceAllString(config.JQL, "")); jql != "" {
+		clauses = append(clauses, "("+jql+")")
+	}
+	if len(clauses) == 0 {
+		return "", errors.New("no projects or JQL query configured")
+	}
+	return strings.Join(clauses, " AND "), nil
+}
+
+func fields(customFields []Field) []string {
+	return append(slices.Clone(issueFields), fieldIDs(customFields)...)
+}
+
+func fieldIDs(customFields []Field) []string {
+	ids := make([]string, 0, len(customFields))
+	for _, field := range customFields {
+		ids = append(ids, field.ID)
+	}
+	return ids
+}
+
+// customFieldValues returns the non-empty values of the custom fields as text, by field name
+func customFieldValues(issue Issue, customFields []Field) map[string]string {
+	values := map[string]string{}
+	for _, field := range customFields {
+		if value := fieldValue(issue.Fields.Custom[field.ID]); value != "" {
+			values[field.Name] = value
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+// fieldValue returns the text of a field value: option, user and version values are objects, multi-value fields
+// are arrays and rich text fields are ADF documents
+func fieldValue(raw json.RawMessage) string {
+	var value any
+	if len(raw) == 0 || json.Unmarshal(raw, &value) != nil {
+		return ""
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64, bool:
+		return fmt.Sprint(v)
+	case []any:
+		parts := make([]string, 0, len(v))
+		for i := range v {
+			item, _ := json.Marshal(v[i])
+			if text := fieldValue(item); text != "" {
+				parts = append(parts, text)
+			}
+		}
+		return strings.Join(parts, ", ")
+	case map[string]any:
+		if v["type"] == "doc" {
+			var doc datasource.RichTextNode
+			if json.Unmarshal(raw, &doc) == nil {
+				return datasource.RichTextToMarkdown(&doc)
+			}
+		}
+		for _, key := range []string{"value", "name", "displayName", "key"} {
+			if text, ok := v[key].(string); ok && text != "" {
+				if child, ok := v["child"].(map[string]any); ok {
+					// Cascading select
+					if childValue, _ := child["value"].(string); childValue != "" {
+						text += " - " + childValue
+					}
+				}
+				return text
+			}
+		}
+	}
+	return ""
+}
+
+// renderIssue returns the issue as Markdown. The fields are added as front matter, so they are ingested together
+// with the description and the comments.
+func renderIssue(issue Issue, comments []Comment, customFields []Field, values map[string]string, url string) string {
+	f := issue.Fields
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	writeField := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&sb, "%s: %q\n", name, value)
+		}
+	}
+	writeList := func(name string, values []string) {
+		if len(values) > 0 {
+			quoted := make([]string, 0, len(values))
+			for _, value := range values {
+				quoted = append(quoted, fmt.Sprintf("%q", value))
+			}
+			fmt.Fprintf(&sb, "%s: [%s]\n", name, strings.Join(quoted, ", "))
+		}
+	}
+
+	writeField("key", issue.Key)
+	writeField("summary", f.Summary)
+	writeField("project", f.Project.Name)
+	writeField("type", f.IssueType.String())
+	writeField("status", f.Status.String())
+	writeField("priority", f.Priority.String())
+	writeField("resolution", f.Resolution.String())
+	writeField("assignee", f.Assignee.String())
+	writeField("reporter", f.Reporter.String())
+	if f.Parent != nil {
+		writeField("parent", f.Parent.Key)
+	}
+	writeList("labels", f.Labels)
+	writeList("components", names(f.Components))
+	writeList("fixVersions", names(f.FixVersions))
+	writeField("created", f.Created)
+	writeField("updated", f.Updated)
+	writeField("resolved", f.ResolutionDate)
+	writeField("url", url)
+	for _, field := range customFields {
+		if value, ok := values[field.Name]; ok {
+			fmt.Fprintf(&sb, "%q: %q\n", field.Name, value)
+		}
+	}
+	sb.WriteString("---\n\n")
+
+	fmt.Fprintf(&sb, "# %s: %s\n\n", issue.Key, f.Summary)
+	if description := datasource.RichTextToMarkdown(f.Description); description != "" {
+		sb.WriteString("## Description\n\n" + description + "\n\n")
+	}
+	if len(comments) > 0 {
+		sb.WriteString("## Comments\n\n")
+		for _, comment := range comments {
+			fmt.Fprintf(&sb, "### %s (%s)\n\n%s\n\n", comment.Author.String(), comment.Created, datasource.RichTextToMarkdown(comment.Body))
+		}
+	}
+	return strings.TrimSpace(sb.String()) + "\n"
+}
+
+func names(values []named) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		result = append(result, value.Name)
+	}
+	return result
+}
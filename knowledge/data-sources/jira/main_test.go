@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gptscript-ai/knowledge/pkg/datasource"
+	"github.com/sirupsen/logrus"
+)
+
+func TestBaseQuery(t *testing.T) {
+	for _, tc := range []struct {
+		config JiraConfig
+		query  string
+	}{
+		{JiraConfig{Projects: []string{"ENG", " OPS "}}, `project in ("ENG", "OPS")`},
+		{JiraConfig{JQL: "type = Bug ORDER BY created DESC"}, "(type = Bug)"},
+		{JiraConfig{Projects: []string{"ENG"}, JQL: "status != Done order\nby rank"}, `project in ("ENG") AND (status != Done)`},
+	} {
+		query, err := baseQuery(&tc.config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if query != tc.query {
+			t.Errorf("expected %s, got %s", tc.query, query)
+		}
+	}
+
+	if _, err := baseQuery(&JiraConfig{Projects: []string{" "}, JQL: "ORDER BY created"}); err == nil {
+		t.Error("expected an error without projects and JQL")
+	}
+}
+
+func TestFieldValue(t *testing.T) {
+	for raw, expected := range map[string]string{
+		`"plain"`:                              "plain",
+		`8`:                                    "8",
+		`2.5`:                                  "2.5",
+		`true`:                                 "true",
+		`null`:                                 "",
+		``:                                     "",
+		`{"value": "High", "id": "1"}`:         "High",
+		`{"displayName": "Jane Doe"}`:          "Jane Doe",
+		`[{"name": "v1.0"}, {"name": "v1.1"}]`: "v1.0, v1.1",
+		`{"value": "EU", "child": {"value": "Berlin"}}`:                                                           "EU - Berlin",
+		`{"type": "doc", "content": [{"type": "paragraph", "content": [{"type": "text", "text": "Rich text"}]}]}`: "Rich text",
+		`{"id": "10000"}`: "",
+	} {
+		if value := fieldValue(json.RawMessage(raw)); value != expected {
+			t.Errorf("fieldValue(%s): expected %q, got %q", raw, expected, value)
+		}
+	}
+}
+
+const testIssue = `{
+  "id": "10001",
+  "key": "ENG-1",
+  "fields": {
+    "summary": "Login fails",
+    "description": {"type": "doc", "content": [{"type": "paragraph", "content": [{"type": "text", "text": "Steps to reproduce"}]}]},
+    "issuetype": {"name": "Bug"},
+    "status": {"name": "In Progress"},
+    "priority": {"name": "High"},
+    "project": {"key": "ENG", "name": "Engineering"},
+    "assignee": {"displayName": "Jane Doe"},
+    "labels": ["auth", "web"],
+    "components": [{"name": "Frontend"}],
+    "created": "2024-11-01T10:00:00.000+0000",
+    "updated": "2024-11-02T10:00:00.000+0000",
+    "customfield_10016": 5,
+    "comment": {
+      "total": 1,
+      "comments": [{"id": "1", "author": {"displayName": "John Roe"}, "created": "2024-11-02T09:00:00.000+0000",
+        "body": {"type": "doc", "content": [{"type": "paragraph", "content": [{"type": "text", "text": "Can't reproduce"}]}]}}]
+    }
+  }
+}`
+
+func TestRenderIssue(t *testing.T) {
+	var issue Issue
+	if err := json.Unmarshal([]byte(testIssue), &issue); err != nil {
+		t.Fatal(err)
+	}
+	customFields := []Field{{ID: "customfield_10016", Name: "Story Points", Custom: true}}
+	values := customFieldValues(issue, customFields)
+	if values["Story Points"] != "5" {
+		t.Fatalf("unexpected custom field values %v", values)
+	}
+
+	expected := `---
+key: "ENG-1"
+summary: "Login fails"
+project: "Engineering"
+type: "Bug"
+status: "In Progress"
+priority: "High"
+assignee: "Jane Doe"
+labels: ["auth", "web"]
+components: ["Frontend"]
+created: "2024-11-01T10:00:00.000+0000"
+updated: "2024-11-02T10:00:00.000+0000"
+url: "https://example.atlassian.net/browse/ENG-1"
+"Story Points": "5"
+---
+
+# ENG-1: Login fails
+
+## Description
+
+Steps to reproduce
+
+## Comments
+
+### John Roe (2024-11-02T09:00:00.000+0000)
+
+Can't reproduce
+`
+	if rendered := renderIssue(issue, issue.Fields.Comment.Comments, customFields, values, "https://example.atlassian.net/browse/ENG-1"); rendered != expected {
+		t.Errorf("unexpected issue:\n%s", rendered)
+	}
+}
+
+func TestJiraSource(t *testing.T) {
+	var searches []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/search/jql":
+			searches = append(searches, r.URL.Query().Get("jql"))
+			// Two pages of issues
+			if r.URL.Query().Get("nextPageToken") == "" {
+				_, _ = io.WriteString(w, `{"issues": [`+testIssue+`], "nextPageToken": "2"}`)
+				return
+			}
+			_, _ = io.WriteString(w, `{"issues": [{"id": "10002", "key": "OPS-7", "fields": {"project": {"key": "OPS"}, "updated": "2024-11-03T10:00:00.000+0000"}}], "isLast": true}`)
+		case "/issue/10002":
+			_, _ = io.WriteString(w, `{"id": "10002", "key": "OPS-7", "fields": {"summary": "Rotate keys", "project": {"key": "OPS", "name": "Operations"}}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	source := &jiraSource{
+		jira:   &jiraClient{token: "token", baseURL: server.URL, site: Site{URL: "https://example.atlassian.net/"}},
+		logErr: logrus.New(),
+		config: jiraConfig{Query: `project in ("ENG", "OPS")`},
+	}
+	source.logErr.SetOutput(io.Discard)
+
+	var items []datasource.Item
+	if err := source.List(context.Background(), func(page []datasource.Item) error {
+		items = append(items, page...)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || len(searches) != 2 {
+		t.Fatalf("expected 2 items from 2 pages, got %+v", items)
+	}
+	if item := items[0]; item.ID != "10001" || item.Path != "ENG/ENG-1.md" || item.URL != "https://example.atlassian.net/browse/ENG-1" ||
+		item.Version != "2024-11-02T10:00:00.000+0000" || item.Metadata["status"] != "In Progress" {
+		t.Errorf("unexpected item %+v", item)
+	}
+
+	data, err := source.Fetch(context.Background(), items[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "# OPS-7: Rotate keys") {
+		t.Errorf("unexpected document %s", data)
+	}
+
+	// Changes are searched with the updated time relative to the cursor
+	searches = nil
+	var cursors []string
+	if err := source.Changes(context.Background(), "2024-11-01T00:00:00Z", func(_ []datasource.Change, cursor string) error {
+		cursors = append(cursors, cursor)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(searches) == 0 || !strings.HasPrefix(searches[0], `project in ("ENG", "OPS") AND updated >= -`) {
+		t.Errorf("unexpected search %v", searches)
+	}
+	if len(cursors) != 3 || cursors[0] != "2024-11-01T00:00:00Z" || cursors[2] == cursors[0] {
+		t.Errorf("expected the new cursor with the last page only, got %v", cursors)
+	}
+
+	if err := source.Changes(context.Background(), "not a time", nil); err == nil {
+		t.Error("expected an error for an invalid cursor")
+	}
+}
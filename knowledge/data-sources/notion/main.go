@@ -0,0 +1,334 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/knowledge/pkg/datasource"
+	"github.com/gptscript-ai/knowledge/pkg/datasource/indexstore"
+	"github.com/sirupsen/logrus"
+)
+
+type notionState struct {
+	// Pages are all synced pages, databases and blocks that contain pages, by ID. They are used to build the folder
+	// structure and to find removed pages without walking the whole workspace again.
+	Pages map[string]PageState `json:"pages,omitempty"`
+}
+
+type PageState struct {
+	Object         string   `json:"object"`
+	Title          string   `json:"title"`
+	ParentID       string   `json:"parentId,omitempty"`
+	LastEditedTime string   `json:"lastEditedTime"`
+	URL            string   `json:"url,omitempty"`
+	ChildIDs       []string `json:"childIds,omitempty"`
+	HasChildPages  bool     `json:"hasChildPages,omitempty"`
+}
+
+// notionSource lists the pages shared with the integration. Changes are found by searching for the pages and
+// databases edited since the newest last_edited_time of the last sync, which is the cursor. The children of the
+// edited pages are listed again to find new and removed child pages. Pages removed from the top level of the
+// workspace can't be found that way, they are removed by the regular full syncs.
+type notionSource struct {
+	notion notionClient
+	logErr *logrus.Logger
+	state  notionState
+}
+
+func main() {
+	logOut := logrus.New()
+	logOut.SetOutput(os.Stdout)
+	logOut.SetFormatter(&logrus.JSONFormatter{})
+	logErr := logrus.New()
+	logErr.SetOutput(os.Stderr)
+
+	ctx := context.Background()
+	gptscriptClient, err := gptscript.NewGPTScript()
+	if err != nil {
+		logOut.WithError(fmt.Errorf("failed to create gptscript client, error: %w", err)).Error()
+		os.Exit(0)
+	}
+
+	source := &notionSource{
+		notion: notionClient{token: os.Getenv("NOTION_TOKEN")},
+		logErr: logErr,
+	}
+
+	opts := datasource.Options{}
+	if hours, err := strconv.ParseFloat(os.Getenv("NOTION_FULL_SYNC_INTERVAL_HOURS"), 64); err == nil {
+		// 0 is the default of the syncer, the shortest interval lists all pages on every sync instead
+		opts.FullSyncInterval = max(time.Duration(hours*float64(time.Hour)), time.Nanosecond)
+	}
+
+	workspace := &datasource.GPTScriptWorkspace{Client: gptscriptClient}
+	store, closeStore, err := indexstore.FromEnv(ctx, workspace)
+	if err != nil {
+		logOut.WithError(fmt.Errorf("failed to open sync state store, error: %w", err)).Error()
+		os.Exit(0)
+	}
+	defer closeStore()
+
+	syncer := datasource.NewSyncer(source, store, workspace, opts)
+	if _, err := syncer.Sync(ctx); err != nil {
+		logOut.WithError(fmt.Errorf("failed to sync notion pages, error: %w", err)).Error()
+		os.Exit(0)
+	}
+}
+
+func (s *notionSource) Name() string {
+	return "notion"
+}
+
+// List walks all pages and databases, the pages are listed once the tree is complete as their paths depend on their
+// ancestors
+func (s *notionSource) List(ctx context.Context, fn func([]datasource.Item) error) error {
+	var objects []Object
+	if err := s.notion.Search(ctx, pageSize, func(object Object) bool {
+		objects = append(objects, object)
+		return true
+	}); err != nil {
+		return fmt.Errorf("failed to search pages: %w", err)
+	}
+
+	pages := map[string]PageState{}
+	s.syncTree(ctx, pages, objects, true)
+	s.state.Pages = pages
+
+	for items := range slices.Chunk(pageItems(pages), pageSize) {
+		if err := fn(items); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Cursor returns the newest last_edited_time of the pages and databases
+func (s *notionSource) Cursor(ctx context.Context) (string, error) {
+	var cursor string
+	err := s.notion.Search(ctx, 1, func(object Object) bool {
+		cursor = object.LastEditedTime
+		return false
+	})
+	return cursor, err
+}
+
+// Changes searches the pages and databases edited since the cursor and reports the pages whose version or path
+// changed, e.g. because an ancestor was renamed, and the pages that were removed
+func (s *notionSource) Changes(ctx context.Context, cursor string, fn func([]datasource.Change, string) error) error {
+	if cursor == "" {
+		return fmt.Errorf("no pages were found by the last sync: %w", datasource.ErrFullSyncRequired)
+	}
+
+	var objects []Object
+	newest := cursor
+	if err := s.notion.Search(ctx, pageSize, func(object Object) bool {
+		// last_edited_time is rounded to the minute, so the pages edited in the minute of the cursor are searched
+		// again, the syncer skips them if their version didn't change
+		if object.LastEditedTime < cursor {
+			return false
+		}
+		objects = append(objects, object)
+		newest = max(newest, object.LastEditedTime)
+		return true
+	}); err != nil {
+		return fmt.Errorf("failed to search pages: %w", err)
+	}
+
+	before := map[string]datasource.Item{}
+	for _, item := range pageItems(s.state.Pages) {
+		before[item.ID] = item
+	}
+	previous := maps.Clone(s.state.Pages)
+	s.syncTree(ctx, s.state.Pages, objects, false)
+
+	var changes []datasource.Change
+	for _, item := range pageItems(s.state.Pages) {
+		if old, ok := before[item.ID]; !ok || old.Path != item.Path || old.Version != item.Version {
+			changes = append(changes, datasource.Change{Item: item})
+		}
+		delete(before, item.ID)
+	}
+	for _, id := range slices.Sorted(maps.Keys(before)) {
+		changes = append(changes, datasource.Change{Item: datasource.Item{ID: id}, Deleted: true})
+	}
+
+	if err := fn(changes, newest); err != nil {
+		// The changes are listed again by the next sync
+		s.state.Pages = previous
+		return err
+	}
+	return nil
+}
+
+func (s *notionSource) Fetch(ctx context.Context, item datasource.Item) ([]byte, error) {
+	content, err := pageMarkdown(ctx, &s.notion, item.ID)
+	return []byte(content), err
+}
+
+func (s *notionSource) LoadState(data json.RawMessage) error {
+	s.state = notionState{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &s.state); err != nil {
+			return err
+		}
+	}
+	if s.state.Pages == nil {
+		s.state.Pages = make(map[string]PageState)
+	}
+	return nil
+}
+
+func (s *notionSource) SaveState() (json.RawMessage, error) {
+	return json.Marshal(s.state)
+}
+
+// syncTree updates the pages of the objects and walks their children to find new and removed child pages. Pages that
+// didn't change since the last sync keep their children, unless all pages are listed again.
+func (s *notionSource) syncTree(ctx context.Context, pages map[string]PageState, objects []Object, full bool) {
+	stack := slices.Clone(objects)
+	visited := map[string]struct{}{}
+	seen := map[string]struct{}{}
+	for _, object := range objects {
+		seen[object.ID] = struct{}{}
+	}
+	var removed []string
+
+	for len(stack) > 0 {
+		object := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if _, ok := visited[object.ID]; ok {
+			continue
+		}
+		visited[object.ID] = struct{}{}
+
+		previous, known := pages[object.ID]
+		node := PageState{
+			Object:         object.Object,
+			Title:          object.PageTitle(),
+			ParentID:       object.Parent.ID(),
+			LastEditedTime: object.LastEditedTime,
+			URL:            object.URL,
+			ChildIDs:       previous.ChildIDs,
+			HasChildPages:  previous.HasChildPages,
+		}
+		pages[object.ID] = node
+
+		// Database rows are pages of their own, which are found by the search
+		if object.Object == "database" || object.Type == "child_database" {
+			continue
+		}
+		// Blocks are only reached through an edited page, so their children are always listed
+		if !full && known && object.Object != "block" && previous.LastEditedTime == object.LastEditedTime {
+			continue
+		}
+
+		children, err := s.notion.Children(ctx, object.ID)
+		if err != nil {
+			s.logErr.Infof("Failed to get children of %s: %v", object.ID, err)
+			continue
+		}
+
+		var childIDs []string
+		hasChildPages := false
+		for _, child := range children {
+			if child.Type == "child_page" {
+				hasChildPages = true
+			} else if child.Type != "child_database" && !child.HasChildren {
+				continue
+			}
+			childIDs = append(childIDs, child.ID)
+			seen[child.ID] = struct{}{}
+			if _, ok := visited[child.ID]; ok {
+				continue
+			}
+
+			if child.Type != "child_page" {
+				stack = append(stack, child)
+				continue
+			}
+			// Known child pages are found by the search when they change
+			if _, ok := pages[child.ID]; ok && !full {
+				continue
+			}
+			page, err := s.notion.GetPage(ctx, child.ID)
+			if err != nil {
+				s.logErr.Infof("Failed to get page %s: %v", child.ID, err)
+				continue
+			}
+			stack = append(stack, page)
+		}
+
+		for _, id := range node.ChildIDs {
+			if !slices.Contains(childIDs, id) {
+				removed = append(removed, id)
+			}
+		}
+		node.ChildIDs = childIDs
+		node.HasChildPages = hasChildPages
+		pages[object.ID] = node
+	}
+
+	// Pages moved to another parent are seen below their new parent, so only the ones that were not seen are removed
+	for _, id := range removed {
+		removeSubtree(pages, id, seen)
+	}
+}
+
+func removeSubtree(pages map[string]PageState, id string, seen map[string]struct{}) {
+	node, ok := pages[id]
+	if _, isSeen := seen[id]; !ok || isSeen {
+		return
+	}
+	delete(pages, id)
+	for _, childID := range node.ChildIDs {
+		removeSubtree(pages, childID, seen)
+	}
+}
+
+// pageItems returns the items of the pages in the tree
+func pageItems(pages map[string]PageState) []datasource.Item {
+	var items []datasource.Item
+	for _, id := range slices.Sorted(maps.Keys(pages)) {
+		if page := pages[id]; page.Object == "page" {
+			items = append(items, datasource.Item{
+				ID:      id,
+				Path:    pagePath(pages, id),
+				URL:     page.URL,
+				Version: page.LastEditedTime,
+			})
+		}
+	}
+	return items
+}
+
+// pagePath returns the path of the file of the page below the titles of its ancestors. Pages with child pages get a
+// folder of their own, which also contains their children.
+func pagePath(pages map[string]PageState, id string) string {
+	page := pages[id]
+	var folders []string
+	if page.HasChildPages {
+		folders = append(folders, page.Title)
+	}
+	visited := map[string]struct{}{id: {}}
+	for parentID := page.ParentID; parentID != ""; {
+		parent, ok := pages[parentID]
+		if _, isVisited := visited[parentID]; !ok || isVisited {
+			break
+		}
+		if parent.Title != "" {
+			folders = append(folders, parent.Title)
+		}
+		visited[parentID] = struct{}{}
+		parentID = parent.ParentID
+	}
+	slices.Reverse(folders)
+	return path.Join(append(folders, id, page.Title+".md")...)
+}
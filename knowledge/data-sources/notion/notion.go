@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gptscript-ai/knowledge/pkg/datasource"
+)
+
+const (
+	notionAPI     = "https://api.notion.com/v1"
+	notionVersion = "2022-06-28"
+
+	pageSize = 100
+)
+
+type RichText struct {
+	PlainText string `json:"plain_text"`
+}
+
+type Parent struct {
+	Type       string `json:"type"`
+	PageID     string `json:"page_id"`
+	BlockID    string `json:"block_id"`
+	DatabaseID string `json:"database_id"`
+}
+
+// ID returns the ID of the parent page, block or database, or "" for the top level of the workspace
+func (p Parent) ID() string {
+	switch p.Type {
+	case "page_id":
+		return p.PageID
+	case "block_id":
+		return p.BlockID
+	case "database_id":
+		return p.DatabaseID
+	default:
+		return ""
+	}
+}
+
+type Property struct {
+	Type  string     `json:"type"`
+	Title []RichText `json:"title"`
+}
+
+// Object is a page or database returned by the search, or a block
+type Object struct {
+	Object         string              `json:"object"`
+	ID             string              `json:"id"`
+	URL            string              `json:"url"`
+	LastEditedTime string              `json:"last_edited_time"`
+	Parent         Parent              `json:"parent"`
+	Properties     map[string]Property `json:"properties"`
+	// Title is the title of a database
+	Title       []RichText `json:"title"`
+	Type        string     `json:"type"`
+	HasChildren bool       `json:"has_children"`
+	// Content is the content of a block, which is stored under the type of the block, e.g. "paragraph"
+	Content BlockContent `json:"-"`
+}
+
+// BlockContent has the fields of all supported block types
+type BlockContent struct {
+	RichText   []RichText `json:"rich_text"`
+	Caption    []RichText `json:"caption"`
+	Title      string     `json:"title"`
+	URL        string     `json:"url"`
+	Language   string     `json:"language"`
+	Expression string     `json:"expression"`
+	Checked    bool       `json:"checked"`
+	// Type is "file" or "external" for files, images, PDFs and videos
+	Type string `json:"type"`
+	File struct {
+		URL        string `json:"url"`
+		ExpiryTime string `json:"expiry_time"`
+	} `json:"file"`
+	External struct {
+		URL string `json:"url"`
+	} `json:"external"`
+	HasColumnHeader bool         `json:"has_column_header"`
+	HasRowHeader    bool         `json:"has_row_header"`
+	Cells           [][]RichText `json:"cells"`
+	SyncedFrom      *struct {
+		BlockID string `json:"block_id"`
+	} `json:"synced_from"`
+}
+
+func (o *Object) UnmarshalJSON(data []byte) error {
+	type object Object
+	if err := json.Unmarshal(data, (*object)(o)); err != nil {
+		return err
+	}
+	if o.Object != "block" || o.Type == "" {
+		return nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if content, ok := fields[o.Type]; ok && string(content) != "null" {
+		return json.Unmarshal(content, &o.Content)
+	}
+	return nil
+}
+
+// PageTitle returns the name of the page, database or child database, which is used for its file and folder
+func (o Object) PageTitle() string {
+	var title string
+	switch {
+	case o.Type == "child_database":
+		return o.Content.Title
+	case o.Object == "database":
+		title = richText(o.Title)
+	case o.Object == "page":
+		// Database rows name their title property freely
+		for _, property := range o.Properties {
+			if property.Type == "title" {
+				title = richText(property.Title)
+				break
+			}
+		}
+	default:
+		return ""
+	}
+	if title = datasource.SanitizeName(strings.TrimSpace(title)); title == "" {
+		return o.ID
+	}
+	return title
+}
+
+type listResult struct {
+	Results    []Object `json:"results"`
+	HasMore    bool     `json:"has_more"`
+	NextCursor string   `json:"next_cursor"`
+}
+
+type notionClient struct {
+	token string
+}
+
+func (c *notionClient) do(ctx context.Context, method, path string, body, v any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, notionAPI+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Notion-Version", notionVersion)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		err := fmt.Errorf("notion request %s %s failed with status %d: %s", method, path, resp.StatusCode, string(data))
+		if resp.StatusCode == http.StatusTooManyRequests {
+			seconds, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+			return &datasource.RetryAfterError{Err: err, After: time.Duration(seconds) * time.Second}
+		}
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// Search calls fn with the pages and databases shared with the integration, the most recently edited first, until fn
+// returns false
+func (c *notionClient) Search(ctx context.Context, limit int, fn func(Object) bool) error {
+	body := map[string]any{
+		"page_size": limit,
+		"sort": map[string]string{
+			"direction": "descending",
+			"timestamp": "last_edited_time",
+		},
+	}
+	for {
+		var result listResult
+		if err := c.do(ctx, http.MethodPost, "/search", body, &result); err != nil {
+			return err
+		}
+		for _, object := range result.Results {
+			if !fn(object) {
+				return nil
+			}
+		}
+		if !result.HasMore || result.NextCursor == "" {
+			return nil
+		}
+		body["start_cursor"] = result.NextCursor
+	}
+}
+
+// Children returns the child blocks of the page or block
+func (c *notionClient) Children(ctx context.Context, id string) ([]Object, error) {
+	query := url.Values{"page_size": {strconv.Itoa(pageSize)}}
+	var children []Object
+	for {
+		var result listResult
+		if err := c.do(ctx, http.MethodGet, "/blocks/"+url.PathEscape(id)+"/children?"+query.Encode(), nil, &result); err != nil {
+			return nil, err
+		}
+		children = append(children, result.Results...)
+		if !result.HasMore || result.NextCursor == "" {
+			return children, nil
+		}
+		query.Set("start_cursor", result.NextCursor)
+	}
+}
+
+func (c *notionClient) GetPage(ctx context.Context, id string) (Object, error) {
+	var page Object
+	return page, c.do(ctx, http.MethodGet, "/pages/"+url.PathEscape(id), nil, &page)
+}
+
+func richText(texts []RichText) string {
+	var sb strings.Builder
+	for _, text := range texts {
+		sb.WriteString(text.PlainText)
+	}
+	return sb.String()
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestObjectContent(t *testing.T) {
+	var block Object
+	if err := json.Unmarshal([]byte(`{
+		"object": "block",
+		"id": "b1",
+		"type": "to_do",
+		"has_children": false,
+		"to_do": {"rich_text": [{"plain_text": "Ship "}, {"plain_text": "it"}], "checked": true}
+	}`), &block); err != nil {
+		t.Fatal(err)
+	}
+	if got := blockText(block); got != "[x] Ship it" {
+		t.Errorf("unexpected text %q", got)
+	}
+
+	var page Object
+	if err := json.Unmarshal([]byte(`{
+		"object": "page",
+		"id": "p1",
+		"properties": {"Task": {"type": "title", "title": [{"plain_text": " Q1/Q2 plan "}]}}
+	}`), &page); err != nil {
+		t.Fatal(err)
+	}
+	if got := page.PageTitle(); got != "Q1_Q2 plan" {
+		t.Errorf("unexpected title %q", got)
+	}
+}
+
+func TestPagePath(t *testing.T) {
+	pages := map[string]PageState{
+		"root":  {Object: "page", Title: "Root", HasChildPages: true, ChildIDs: []string{"child"}},
+		"child": {Object: "page", Title: "Child", ParentID: "root"},
+		"loop":  {Object: "page", Title: "Loop", ParentID: "loop"},
+	}
+	for id, expected := range map[string]string{
+		"root":  "Root/root/Root.md",
+		"child": "Root/child/Child.md",
+		"loop":  "loop/Loop.md",
+	} {
+		if got := pagePath(pages, id); got != expected {
+			t.Errorf("expected %s for %s, got %s", expected, id, got)
+		}
+	}
+}
+
+func TestRemoveSubtree(t *testing.T) {
+	pages := map[string]PageState{
+		"a": {Object: "page", ChildIDs: []string{"b", "moved"}},
+		"b": {Object: "page", ParentID: "a"},
+		// moved was seen below another parent, so it is kept
+		"moved": {Object: "page", ParentID: "other"},
+	}
+	removeSubtree(pages, "a", map[string]struct{}{"moved": {}})
+	if _, ok := pages["a"]; ok {
+		t.Error("expected a to be removed")
+	}
+	if _, ok := pages["b"]; ok {
+		t.Error("expected the child of a to be removed")
+	}
+	if _, ok := pages["moved"]; !ok {
+		t.Error("expected the moved page to be kept")
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// pageMarkdown returns the content of the page as markdown
+func pageMarkdown(ctx context.Context, client *notionClient, id string) (string, error) {
+	var sb strings.Builder
+	if err := writeBlocks(ctx, client, &sb, id, 0); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// writeBlocks writes the child blocks of the page or block, nested blocks are indented
+func writeBlocks(ctx context.Context, client *notionClient, sb *strings.Builder, id string, indentation int) error {
+	blocks, err := client.Children(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	for _, block := range blocks {
+		switch block.Type {
+		case "table":
+			rows, err := client.Children(ctx, block.ID)
+			if err != nil {
+				return err
+			}
+			writeTable(sb, block, rows, indentation)
+			continue
+		case "synced_block":
+			// The original synced block has the content as children, the copies point to it
+			source := block.ID
+			if block.Content.SyncedFrom != nil {
+				source = block.Content.SyncedFrom.BlockID
+			}
+			if err := writeBlocks(ctx, client, sb, source, indentation); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if text := blockText(block); text != "" {
+			indent := strings.Repeat(" ", indentation)
+			sb.WriteString(indent + strings.ReplaceAll(text, "\n", "\n"+indent) + "\n")
+		}
+		// Child pages are synced as pages of their own
+		if block.HasChildren && block.Type != "child_page" {
+			if err := writeBlocks(ctx, client, sb, block.ID, indentation+2); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// blockText returns the markdown of a single block, without its children
+func blockText(block Object) string {
+	content := block.Content
+	switch block.Type {
+	case "bookmark":
+		return withCaption("Bookmark: "+content.URL, content.Caption)
+	case "bulleted_list_item":
+		return "- " + richText(content.RichText)
+	case "callout", "toggle":
+		return "> " + richText(content.RichText)
+	case "child_database":
+		return "Child Database: " + content.Title
+	case "child_page":
+		return "Child Page: " + content.Title
+	case "code":
+		text := "```" + content.Language + "\n" + richText(content.RichText) + "\n```"
+		if caption := richText(content.Caption); caption != "" {
+			text += "\n(" + caption + ")"
+		}
+		return text
+	case "divider":
+		return "---"
+	case "embed":
+		return "Embed: " + content.URL
+	case "equation":
+		return "Equation: " + content.Expression
+	case "file":
+		return fileText("File", content)
+	case "heading_1":
+		return "# " + richText(content.RichText)
+	case "heading_2":
+		return "## " + richText(content.RichText)
+	case "heading_3":
+		return "### " + richText(content.RichText)
+	case "image":
+		return fileText("Image", content)
+	case "link_preview":
+		return content.URL
+	case "numbered_list_item":
+		return "1. " + richText(content.RichText)
+	case "paragraph":
+		return richText(content.RichText)
+	case "pdf":
+		return fileText("PDF", content)
+	case "quote":
+		return "\"\"\"\n" + richText(content.RichText) + "\n\"\"\""
+	case "to_do":
+		if content.Checked {
+			return "[x] " + richText(content.RichText)
+		}
+		return "[ ] " + richText(content.RichText)
+	case "video":
+		return fileText("Video", content)
+	default:
+		return ""
+	}
+}
+
+func fileText(prefix string, content BlockContent) string {
+	var text string
+	switch content.Type {
+	case "file":
+		text = fmt.Sprintf("%s: %s (expires %s)", prefix, content.File.URL, content.File.ExpiryTime)
+	case "external":
+		text = fmt.Sprintf("External %s: %s", prefix, content.External.URL)
+	}
+	return withCaption(text, content.Caption)
+}
+
+func withCaption(text string, caption []RichText) string {
+	if caption := richText(caption); caption != "" {
+		return text + " (" + caption + ")"
+	}
+	return text
+}
+
+// writeTable writes the rows of the table as a markdown table, header cells are bold
+func writeTable(sb *strings.Builder, table Object, rows []Object, indentation int) {
+	indent := strings.Repeat(" ", indentation)
+	for i, row := range rows {
+		cells := make([]string, 0, len(row.Content.Cells))
+		for j, cell := range row.Content.Cells {
+			text := strings.ReplaceAll(richText(cell), "\n", " ")
+			if (i == 0 && table.Content.HasColumnHeader) || (j == 0 && table.Content.HasRowHeader) {
+				text = "**" + text + "**"
+			}
+			cells = append(cells, text)
+		}
+		sb.WriteString(indent + "| " + strings.Join(cells, " | ") + " |\n")
+		if i == 0 {
+			// Markdown tables always start with a header row
+			sb.WriteString(indent + "|" + strings.Repeat(" --- |", len(cells)) + "\n")
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	msgraphsdk "github.com/microsoftgraph/msgraph-sdk-go"
+	drives2 "github.com/microsoftgraph/msgraph-sdk-go/drives"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
+	"github.com/microsoftgraph/msgraph-sdk-go/shares"
+)
+
+// We only sync files that are less than 50 MB, as most of the bigger files won't be supported by knowledge
+const maxFileSize = 1024 * 1024 * 50
+
+// errDeltaUnsupported is returned if the folder can't be synced with delta queries, e.g. OneDrive for Business and
+// SharePoint only support them on the root of a drive.
+var errDeltaUnsupported = errors.New("delta query not supported")
+
+type StaticTokenCredential struct {
+	token string
+}
+
+func NewStaticTokenCredential(token string) StaticTokenCredential {
+	return StaticTokenCredential{
+		token: token,
+	}
+}
+
+func (s StaticTokenCredential) GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{
+		Token: s.token,
+	}, nil
+}
+
+// sharedItem is the drive item a shared link points to
+type sharedItem struct {
+	ID       string
+	DriveID  string
+	Name     string
+	IsFolder bool
+	// Root is the folder of the shared item, which is cut from the paths of its files
+	Root string
+	File DeltaItem
+}
+
+func getSharedItem(ctx context.Context, client *msgraphsdk.GraphServiceClient, link string) (sharedItem, error) {
+	item, err := client.Shares().BySharedDriveItemId(encodeURL(link)).DriveItem().Get(ctx, &shares.ItemDriveItemRequestBuilderGetRequestConfiguration{})
+	if err != nil {
+		return sharedItem{}, err
+	}
+	return sharedItem{
+		ID:       deref(item.GetId()),
+		DriveID:  deref(item.GetParentReference().GetDriveId()),
+		Name:     deref(item.GetName()),
+		IsFolder: item.GetFile() == nil,
+		Root:     path.Dir(getFullName(item)),
+		File:     toDeltaItem(item, deref(item.GetParentReference().GetDriveId())),
+	}, nil
+}
+
+// latestDeltaLink returns the delta link of the current state of the folder, without listing its items
+func latestDeltaLink(ctx context.Context, client *msgraphsdk.GraphServiceClient, driveID, folderID string) (string, error) {
+	builder := client.Drives().ByDriveId(driveID).Items().ByDriveItemId(folderID).Delta().
+		WithUrl(fmt.Sprintf("https://graph.microsoft.com/v1.0/drives/%s/items/%s/delta?token=latest", url.PathEscape(driveID), url.PathEscape(folderID)))
+	for {
+		resp, err := builder.GetAsDeltaGetResponse(ctx, nil)
+		if err != nil {
+			if isClientError(err) {
+				return "", fmt.Errorf("%w: %v", errDeltaUnsupported, err)
+			}
+			return "", err
+		}
+		if next := resp.GetOdataNextLink(); next != nil && *next != "" {
+			builder = builder.WithUrl(*next)
+			continue
+		}
+		return deref(resp.GetOdataDeltaLink()), nil
+	}
+}
+
+// applyDelta fetches the changes since the delta link, or the whole tree of the folder if there is none, and applies
+// them to the items of the link state. It returns the new delta link.
+func applyDelta(ctx context.Context, client *msgraphsdk.GraphServiceClient, driveID, folderID, deltaLink string, linkState *LinkState) (string, error) {
+	builder := client.Drives().ByDriveId(driveID).Items().ByDriveItemId(folderID).Delta()
+	if deltaLink != "" {
+		builder = builder.WithUrl(deltaLink)
+	}
+	if linkState.Items == nil {
+		linkState.Items = make(map[string]DeltaItem)
+	}
+
+	for {
+		resp, err := builder.GetAsDeltaGetResponse(ctx, nil)
+		if err != nil {
+			return "", err
+		}
+
+		for _, item := range resp.GetValue() {
+			id := deref(item.GetId())
+			if id == folderID {
+				continue
+			}
+			if item.GetDeleted() != nil {
+				delete(linkState.Items, id)
+				continue
+			}
+			linkState.Items[id] = toDeltaItem(item, driveID)
+		}
+
+		if next := resp.GetOdataNextLink(); next != nil && *next != "" {
+			builder = builder.WithUrl(*next)
+			continue
+		}
+		return deref(resp.GetOdataDeltaLink()), nil
+	}
+}
+
+// listChildren lists the files below the folder recursively, for folders that don't support delta queries. The
+// paths are relative to root.
+func listChildren(ctx context.Context, client *msgraphsdk.GraphServiceClient, driveID, folderID, root string) (map[string]DeltaItem, map[string]string, error) {
+	files := map[string]DeltaItem{}
+	paths := map[string]string{}
+
+	var walk func(driveID, id string) error
+	walk = func(driveID, id string) error {
+		item, err := client.Drives().ByDriveId(driveID).Items().ByDriveItemId(id).Get(ctx, &drives2.ItemItemsDriveItemItemRequestBuilderGetRequestConfiguration{
+			QueryParameters: &drives2.ItemItemsDriveItemItemRequestBuilderGetQueryParameters{
+				Expand: []string{"children"},
+			},
+		})
+		if err != nil {
+			return err
+		}
+		if item.GetFile() != nil {
+			files[id] = toDeltaItem(item, driveID)
+			paths[id] = strings.TrimPrefix(getFullName(item), root)
+			return nil
+		}
+		for _, child := range item.GetChildren() {
+			if err := walk(deref(child.GetParentReference().GetDriveId()), deref(child.GetId())); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return files, paths, walk(driveID, folderID)
+}
+
+func download(ctx context.Context, client *msgraphsdk.GraphServiceClient, driveID, id string) ([]byte, error) {
+	return client.Drives().ByDriveId(driveID).Items().ByDriveItemId(id).Content().Get(ctx, nil)
+}
+
+// itemPath returns the path of the item relative to the parent of the shared folder, e.g. /<shared folder>/sub/file.
+// It returns false if the item is no longer inside the shared folder.
+func (l LinkState) itemPath(id, rootID string) (string, bool) {
+	var names []string
+	for range len(l.Items) + 1 {
+		if id == rootID {
+			slices.Reverse(names)
+			return "/" + path.Join(append([]string{l.Name}, names...)...), true
+		}
+		item, ok := l.Items[id]
+		if !ok {
+			return "", false
+		}
+		names = append(names, item.Name)
+		id = item.ParentID
+	}
+	// Cycle in the parent references
+	return "", false
+}
+
+func toDeltaItem(item models.DriveItemable, driveID string) DeltaItem {
+	deltaItem := DeltaItem{
+		Name:        deref(item.GetName()),
+		IsFolder:    item.GetFolder() != nil || item.GetRoot() != nil,
+		DriveID:     driveID,
+		URL:         deref(item.GetWebUrl()),
+		SizeInBytes: deref(item.GetSize()),
+	}
+	if parent := item.GetParentReference(); parent != nil {
+		deltaItem.ParentID = deref(parent.GetId())
+		if parent.GetDriveId() != nil {
+			deltaItem.DriveID = *parent.GetDriveId()
+		}
+	}
+	if item.GetLastModifiedDateTime() != nil {
+		deltaItem.LastModified = item.GetLastModifiedDateTime().String()
+	}
+	return deltaItem
+}
+
+// isExpired returns whether the delta link expired
+func isExpired(err error) bool {
+	var odataErr *odataerrors.ODataError
+	return errors.As(err, &odataErr) && odataErr.ResponseStatusCode == http.StatusGone
+}
+
+// isClientError returns whether the request was rejected, other than for missing authorization or throttling
+func isClientError(err error) bool {
+	var odataErr *odataerrors.ODataError
+	return errors.As(err, &odataErr) && odataErr.ResponseStatusCode < http.StatusInternalServerError &&
+		odataErr.ResponseStatusCode != http.StatusUnauthorized && odataErr.ResponseStatusCode != http.StatusTooManyRequests
+}
+
+func getFullName(item models.DriveItemable) string {
+	if item.GetParentReference() == nil {
+		return ""
+	}
+	p := item.GetParentReference().GetPath()
+	if p != nil {
+		_, after, found := strings.Cut(*p, ":")
+		if found {
+			return path.Join(after, deref(item.GetName()))
+		}
+	}
+	return ""
+}
+
+func encodeURL(u string) string {
+	base64Value := base64.StdEncoding.EncodeToString([]byte(u))
+
+	encodedUrl := "u!" + strings.TrimRight(base64Value, "=")
+	encodedUrl = strings.ReplaceAll(encodedUrl, "/", "_")
+	encodedUrl = strings.ReplaceAll(encodedUrl, "+", "-")
+	return encodedUrl
+}
+
+func deref[T any](v *T) T {
+	if v == nil {
+		var zero T
+		return zero
+	}
+	return *v
+}
@@ -2,22 +2,18 @@ package main
 
 import (
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
-	"path"
-	"path/filepath"
+	"slices"
 	"strings"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/knowledge/pkg/datasource"
+	"github.com/gptscript-ai/knowledge/pkg/datasource/indexstore"
 	msgraphsdk "github.com/microsoftgraph/msgraph-sdk-go"
-	drives2 "github.com/microsoftgraph/msgraph-sdk-go/drives"
-	"github.com/microsoftgraph/msgraph-sdk-go/models"
-	"github.com/microsoftgraph/msgraph-sdk-go/shares"
 	"github.com/sirupsen/logrus"
 )
 
@@ -29,53 +25,36 @@ type OneDriveConfig struct {
 	SharedLinks []string `json:"sharedLinks"`
 }
 
-type MetadataOutput struct {
-	Status string                 `json:"status"`
-	Files  map[string]FileDetails `json:"files"`
-	State  State                  `json:"state"`
-}
-
-type State struct {
-	OneDriveState *OneDriveLinksConnectorState `json:"onedriveState,omitempty"`
-}
-
-type OneDriveLinksConnectorState struct {
-	Files map[string]FileState `json:"files,omitempty"`
+type onedriveState struct {
 	Links map[string]LinkState `json:"links,omitempty"`
 }
 
 type LinkState struct {
-	IsFolder bool   `json:"isFolder"`
-	Name     string `json:"name"`
-}
-
-type FileState struct {
-	FolderPath string `json:"folderPath"`
-	FileName   string `json:"fileName"`
-	URL        string `json:"url"`
-}
-
-type StaticTokenCredential struct {
-	token string
-}
-
-func NewStaticTokenCredential(token string) StaticTokenCredential {
-	return StaticTokenCredential{
-		token: token,
-	}
+	// Name is the name of the shared folder, the first segment of the paths of its files
+	Name string `json:"-"`
+	// Items is the tree of the shared folder as of the last delta query, by item ID. It is empty for folders that
+	// don't support delta queries.
+	Items map[string]DeltaItem `json:"items,omitempty"`
 }
 
-func (s StaticTokenCredential) GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
-	return azcore.AccessToken{
-		Token: s.token,
-	}, nil
+type DeltaItem struct {
+	Name         string `json:"name"`
+	ParentID     string `json:"parentId,omitempty"`
+	IsFolder     bool   `json:"isFolder,omitempty"`
+	DriveID      string `json:"driveId,omitempty"`
+	URL          string `json:"url,omitempty"`
+	SizeInBytes  int64  `json:"sizeInBytes,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
 }
 
-type FileDetails struct {
-	FilePath    string `json:"filePath"`
-	URL         string `json:"url"`
-	SizeInBytes int64  `json:"sizeInBytes"`
-	UpdatedAt   string `json:"updatedAt"`
+// onedriveSource lists the files of the shared links. Shared folders are listed with delta queries and their changes
+// are listed with one delta link per folder as cursor. Folders that don't support delta queries are listed
+// completely on every sync.
+type onedriveSource struct {
+	client *msgraphsdk.GraphServiceClient
+	logErr *logrus.Logger
+	links  map[string]sharedItem
+	state  onedriveState
 }
 
 func main() {
@@ -110,199 +89,253 @@ func main() {
 		input.OneDriveConfig = &OneDriveConfig{}
 	}
 
-	output := MetadataOutput{}
-
-	var notfoundErr *gptscript.NotFoundInWorkspaceError
-	outputData, err := gptscriptClient.ReadFileInWorkspace(ctx, ".metadata.json")
-	if err != nil && !errors.As(err, &notfoundErr) {
-		logOut.WithError(fmt.Errorf("failed to read .metadata.json in workspace, error: %w", err)).Error()
-		os.Exit(0)
-	} else if err == nil {
-		if err := json.Unmarshal(outputData, &output); err != nil {
-			logrus.WithError(err).Fatal("Failed to unmarshal output data")
-		}
-	}
-
-	if output.Files == nil {
-		output.Files = make(map[string]FileDetails)
+	source := &onedriveSource{
+		client: client,
+		logErr: logErr,
 	}
-
-	if output.State.OneDriveState == nil {
-		output.State.OneDriveState = &OneDriveLinksConnectorState{
-			Files: make(map[string]FileState),
-		}
-	}
-	if output.State.OneDriveState.Files == nil {
-		output.State.OneDriveState.Files = make(map[string]FileState)
+	if err := source.resolveLinks(ctx, input.OneDriveConfig); err != nil {
+		logOut.WithError(fmt.Errorf("failed to sync onedrive links, error: %w", err)).Error()
+		os.Exit(0)
 	}
-	if output.State.OneDriveState.Links == nil {
-		output.State.OneDriveState.Links = make(map[string]LinkState)
+	// Renamed shared items change the paths of all files, so their names are part of the config
+	names := map[string]string{}
+	for link, item := range source.links {
+		names[link] = item.Name
 	}
-
-	for i := range input.OneDriveConfig.SharedLinks {
-		input.OneDriveConfig.SharedLinks[i] = strings.TrimSpace(input.OneDriveConfig.SharedLinks[i])
+	config, err := json.Marshal(names)
+	if err != nil {
+		logOut.WithError(fmt.Errorf("failed to marshal links, error: %w", err)).Error()
+		os.Exit(0)
 	}
 
-	if err := sync(ctx, logErr, input, &output, client, gptscriptClient); err != nil {
-		logOut.WithError(fmt.Errorf("failed to sync onedrive links, error: %w", err)).Error()
+	workspace := &datasource.GPTScriptWorkspace{Client: gptscriptClient}
+	store, closeStore, err := indexstore.FromEnv(ctx, workspace)
+	if err != nil {
+		logOut.WithError(fmt.Errorf("failed to open sync state store, error: %w", err)).Error()
 		os.Exit(0)
 	}
+	defer closeStore()
 
-	output.Status = ""
-	if err := writeMetadata(ctx, &output, gptscriptClient); err != nil {
-		logOut.WithError(fmt.Errorf("failed to write metadata, error: %w", err)).Error()
+	syncer := datasource.NewSyncer(source, store, workspace, datasource.Options{
+		Config: string(config),
+	})
+	if _, err := syncer.Sync(ctx); err != nil {
+		logOut.WithError(fmt.Errorf("failed to sync onedrive links, error: %w", err)).Error()
 		os.Exit(0)
 	}
 }
 
-func sync(ctx context.Context, logErr *logrus.Logger, input MetadataInput, output *MetadataOutput, client *msgraphsdk.GraphServiceClient, gptscript *gptscript.GPTScript) error {
-	items := map[string]models.DriveItemable{}
-	for _, link := range input.OneDriveConfig.SharedLinks {
-		requestParameters := &shares.ItemDriveItemRequestBuilderGetQueryParameters{
-			Expand: []string{"children"},
-		}
-		configuration := &shares.ItemDriveItemRequestBuilderGetRequestConfiguration{
-			QueryParameters: requestParameters,
+func (s *onedriveSource) resolveLinks(ctx context.Context, config *OneDriveConfig) error {
+	s.links = map[string]sharedItem{}
+	for _, link := range config.SharedLinks {
+		link = strings.TrimSpace(link)
+		if link == "" {
+			continue
 		}
-		shareDriveItem, err := client.Shares().BySharedDriveItemId(encodeURL(link)).DriveItem().Get(ctx, configuration)
+		item, err := getSharedItem(ctx, s.client, link)
 		if err != nil {
-			return err
+			return fmt.Errorf("failed to get shared item of %s: %w", link, err)
 		}
-		root := path.Dir(getFullName(shareDriveItem))
-		output.State.OneDriveState.Links[link] = LinkState{
-			IsFolder: shareDriveItem.GetFile() == nil,
-			Name:     *shareDriveItem.GetName(),
+		s.links[link] = item
+	}
+	return nil
+}
+
+func (s *onedriveSource) Name() string {
+	return "onedrive"
+}
+
+// List lists the files of all shared links, one page per link
+func (s *onedriveSource) List(ctx context.Context, fn func([]datasource.Item) error) error {
+	s.state.Links = map[string]LinkState{}
+	for _, link := range slices.Sorted(maps.Keys(s.links)) {
+		shared := s.links[link]
+		if !shared.IsFolder {
+			var items []datasource.Item
+			if item, ok := s.item(shared.ID, "/"+shared.Name, shared.File, true); ok {
+				items = append(items, item)
+			}
+			if err := fn(items); err != nil {
+				return err
+			}
+			continue
 		}
 
-		children, err := syncChildrenFileForItem(ctx, client, gptscript, shareDriveItem, output, root, logErr)
-		if err != nil {
-			return err
+		linkState := LinkState{}
+		_, err := applyDelta(ctx, s.client, shared.DriveID, shared.ID, "", &linkState)
+		if err == nil {
+			s.state.Links[link] = linkState
+			if err := fn(s.folderItems(shared, linkState, true)); err != nil {
+				return err
+			}
+			continue
 		}
-		for _, child := range children {
-			items[*child.GetId()] = child
+		if !isClientError(err) {
+			return fmt.Errorf("failed to list %s: %w", link, err)
 		}
-	}
 
-	for id := range output.Files {
-		if _, ok := items[id]; !ok {
-			if output.Files[id].FilePath != "" {
-				logErr.Infof("Deleting %s", output.Files[id].FilePath)
-				if err := gptscript.DeleteFileInWorkspace(ctx, output.Files[id].FilePath); err != nil {
-					return err
-				}
+		s.logErr.Infof("Delta sync is not available for %s, listing all files: %v", link, err)
+		files, paths, err := listChildren(ctx, s.client, shared.DriveID, shared.ID, shared.Root)
+		if err != nil {
+			return fmt.Errorf("failed to list %s: %w", link, err)
+		}
+		var items []datasource.Item
+		for _, id := range slices.Sorted(maps.Keys(files)) {
+			if item, ok := s.item(id, paths[id], files[id], true); ok {
+				items = append(items, item)
 			}
-			delete(output.Files, id)
+		}
+		if err := fn(items); err != nil {
+			return err
 		}
 	}
-
 	return nil
 }
 
-func writeMetadata(ctx context.Context, output *MetadataOutput, gptscript *gptscript.GPTScript) error {
-	data, err := json.MarshalIndent(output, "", "  ")
-	if err != nil {
-		return err
+// Cursor returns the latest delta link of each shared folder. Folders without delta support have no delta link, so
+// their changes require a full sync.
+func (s *onedriveSource) Cursor(ctx context.Context) (string, error) {
+	deltaLinks := map[string]string{}
+	for link, shared := range s.links {
+		if !shared.IsFolder {
+			continue
+		}
+		deltaLink, err := latestDeltaLink(ctx, s.client, shared.DriveID, shared.ID)
+		if errors.Is(err, errDeltaUnsupported) {
+			deltaLink = ""
+		} else if err != nil {
+			return "", err
+		}
+		deltaLinks[link] = deltaLink
 	}
-	return gptscript.WriteFileInWorkspace(ctx, ".metadata.json", data)
+	data, err := json.Marshal(deltaLinks)
+	return string(data), err
 }
 
-func syncChildrenFileForItem(ctx context.Context, client *msgraphsdk.GraphServiceClient, gptscriptClient *gptscript.GPTScript, item models.DriveItemable, output *MetadataOutput, root string, logErr *logrus.Logger) ([]models.DriveItemable, error) {
-	if item.GetFile() != nil {
-		// We only sync item that is less than 50 MB, as most of the bigger files won't be supported from knowledge
-		if item.GetSize() != nil && *item.GetSize() >= 1024*1024*50 {
-			return nil, nil
+// Changes applies the delta of each shared folder since its delta link to its tree and reports the files whose path
+// or version changed. Moving or renaming a folder changes the paths of all files below it. Shared files are reported
+// every time, the syncer skips them if their version didn't change.
+func (s *onedriveSource) Changes(ctx context.Context, cursor string, fn func([]datasource.Change, string) error) error {
+	deltaLinks := map[string]string{}
+	if err := json.Unmarshal([]byte(cursor), &deltaLinks); err != nil {
+		return fmt.Errorf("invalid cursor: %w", datasource.ErrFullSyncRequired)
+	}
+
+	for _, link := range slices.Sorted(maps.Keys(s.links)) {
+		shared := s.links[link]
+		if !shared.IsFolder {
+			continue
 		}
-		if err := saveToMetadata(ctx, logErr, output, client, gptscriptClient, item, root); err != nil {
-			return nil, err
+		if deltaLinks[link] == "" {
+			return fmt.Errorf("%s doesn't support delta queries: %w", link, datasource.ErrFullSyncRequired)
 		}
-		return []models.DriveItemable{item}, nil
-	}
 
-	var result []models.DriveItemable
-	for _, child := range item.GetChildren() {
-		item, err := client.Drives().ByDriveId(*child.GetParentReference().GetDriveId()).Items().ByDriveItemId(*child.GetId()).Get(ctx, &drives2.ItemItemsDriveItemItemRequestBuilderGetRequestConfiguration{
-			QueryParameters: &drives2.ItemItemsDriveItemItemRequestBuilderGetQueryParameters{
-				Expand: []string{"children"},
-			},
-		})
-		if err != nil {
-			return nil, err
+		before := s.state.Links[link]
+		// The tree is only replaced once the whole delta is applied, so a failed request doesn't lose changes
+		after := LinkState{Items: maps.Clone(before.Items)}
+		deltaLink, err := applyDelta(ctx, s.client, shared.DriveID, shared.ID, deltaLinks[link], &after)
+		if isExpired(err) {
+			return fmt.Errorf("delta link of %s expired: %w", link, datasource.ErrFullSyncRequired)
+		} else if err != nil {
+			return fmt.Errorf("failed to get changes of %s: %w", link, err)
+		}
+
+		oldItems := map[string]datasource.Item{}
+		for _, item := range s.folderItems(shared, before, false) {
+			oldItems[item.ID] = item
 		}
-		children, err := syncChildrenFileForItem(ctx, client, gptscriptClient, item, output, root, logErr)
+		var changes []datasource.Change
+		for _, item := range s.folderItems(shared, after, true) {
+			if old, ok := oldItems[item.ID]; !ok || old.Path != item.Path || old.Version != item.Version {
+				changes = append(changes, datasource.Change{Item: item})
+			}
+			delete(oldItems, item.ID)
+		}
+		for _, id := range slices.Sorted(maps.Keys(oldItems)) {
+			changes = append(changes, datasource.Change{Item: datasource.Item{ID: id}, Deleted: true})
+		}
+
+		deltaLinks[link] = deltaLink
+		data, err := json.Marshal(deltaLinks)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		s.state.Links[link] = after
+		if err := fn(changes, string(data)); err != nil {
+			// The changes are listed again by the next sync
+			s.state.Links[link] = before
+			return err
 		}
-		result = append(result, children...)
 	}
-	return result, nil
-}
 
-func saveToMetadata(ctx context.Context, logErr *logrus.Logger, output *MetadataOutput, client *msgraphsdk.GraphServiceClient, gptscriptClient *gptscript.GPTScript, item models.DriveItemable, root string) error {
-	folders := make(map[string]struct{})
-	files := make(map[string]FileState)
-	fullPath := getFullName(item)
-	relativePath := strings.TrimPrefix(fullPath, root)
-	topRootFolder := strings.Split(strings.TrimPrefix(relativePath, string(os.PathSeparator)), string(os.PathSeparator))[0]
-	created := false
-	detail, ok := output.Files[*item.GetId()]
-	if !ok {
-		created = true
-		detail.FilePath = relativePath
-		detail.URL = *item.GetWebUrl()
-		detail.UpdatedAt = (*item.GetLastModifiedDateTime()).String()
-		detail.SizeInBytes = *item.GetSize()
-		output.Files[*item.GetId()] = detail
+	var changes []datasource.Change
+	for _, link := range slices.Sorted(maps.Keys(s.links)) {
+		if shared := s.links[link]; !shared.IsFolder {
+			item, ok := s.item(shared.ID, "/"+shared.Name, shared.File, true)
+			changes = append(changes, datasource.Change{Item: item, Deleted: !ok})
+		}
 	}
-	files[*item.GetId()] = FileState{
-		FolderPath: strings.TrimPrefix(filepath.Dir(relativePath), string(os.PathSeparator)),
-		FileName:   path.Base(relativePath),
-		URL:        *item.GetWebUrl(),
+	if len(changes) == 0 {
+		return nil
 	}
-	if created || detail.UpdatedAt != item.GetLastModifiedDateTime().String() {
-		driveID := *item.GetParentReference().GetDriveId()
-		data, err := client.Drives().ByDriveId(driveID).Items().ByDriveItemId(*item.GetId()).Content().Get(ctx, nil)
-		if err != nil {
-			return err
-		}
+	return fn(changes, cursor)
+}
+
+func (s *onedriveSource) Fetch(ctx context.Context, item datasource.Item) ([]byte, error) {
+	return download(ctx, s.client, item.Metadata["driveId"], item.ID)
+}
 
-		if err := gptscriptClient.WriteFileInWorkspace(ctx, relativePath, data); err != nil {
+func (s *onedriveSource) LoadState(data json.RawMessage) error {
+	s.state = onedriveState{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &s.state); err != nil {
 			return err
 		}
-		logErr.Infof("Downloaded %s", relativePath)
-		detail.UpdatedAt = item.GetLastModifiedDateTime().String()
-		detail.URL = *item.GetWebUrl()
-		detail.FilePath = relativePath
-		output.Files[*item.GetId()] = detail
-	} else {
-		logErr.Infof("Skipping %s because it is not changed", relativePath)
 	}
-	folders[topRootFolder] = struct{}{}
-	output.State.OneDriveState.Files = files
-	output.Status = fmt.Sprintf("Syncing file %v", relativePath)
-	if err := writeMetadata(ctx, output, gptscriptClient); err != nil {
-		return err
+	if s.state.Links == nil {
+		s.state.Links = make(map[string]LinkState)
 	}
-
-	output.State.OneDriveState.Files = files
 	return nil
 }
 
-func getFullName(item models.DriveItemable) string {
-	p := item.GetParentReference().GetPath()
-	if p != nil {
-		_, after, found := strings.Cut(*p, ":")
-		if found {
-			return path.Join(after, *item.GetName())
+func (s *onedriveSource) SaveState() (json.RawMessage, error) {
+	return json.Marshal(s.state)
+}
+
+// folderItems returns the items of the files in the tree of the shared folder
+func (s *onedriveSource) folderItems(shared sharedItem, linkState LinkState, logSkipped bool) []datasource.Item {
+	linkState.Name = shared.Name
+	var items []datasource.Item
+	for _, id := range slices.Sorted(maps.Keys(linkState.Items)) {
+		file := linkState.Items[id]
+		if file.IsFolder {
+			continue
+		}
+		filePath, ok := linkState.itemPath(id, shared.ID)
+		if !ok {
+			continue
+		}
+		if item, ok := s.item(id, filePath, file, logSkipped); ok {
+			items = append(items, item)
 		}
 	}
-	return ""
+	return items
 }
 
-func encodeURL(u string) string {
-	base64Value := base64.StdEncoding.EncodeToString([]byte(u))
-
-	encodedUrl := "u!" + strings.TrimRight(base64Value, "=")
-	encodedUrl = strings.ReplaceAll(encodedUrl, "/", "_")
-	encodedUrl = strings.ReplaceAll(encodedUrl, "+", "-")
-	return encodedUrl
+// item returns the item of the file, it returns false if the file is too big
+func (s *onedriveSource) item(id, filePath string, file DeltaItem, logSkipped bool) (datasource.Item, bool) {
+	item := datasource.Item{
+		ID:       id,
+		Path:     filePath,
+		URL:      file.URL,
+		Version:  file.LastModified,
+		Metadata: map[string]string{"driveId": file.DriveID},
+	}
+	if file.SizeInBytes >= maxFileSize {
+		if logSkipped {
+			s.logErr.Infof("Skipping %s because it is larger than 50 MB", filePath)
+		}
+		return item, false
+	}
+	return item, true
 }
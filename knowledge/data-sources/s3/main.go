@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/knowledge/pkg/datasource"
+	"github.com/gptscript-ai/knowledge/pkg/datasource/indexstore"
+	"github.com/sirupsen/logrus"
+)
+
+// We only sync objects that are less than 50 MB, as most of the bigger files won't be supported by knowledge
+const maxFileSize = 1024 * 1024 * 50
+
+type MetadataInput struct {
+	S3Config *S3Config `json:"s3Config,omitempty"`
+}
+
+type S3Config struct {
+	Bucket string `json:"bucket"`
+	// Prefix limits the sync to the objects below the prefix, e.g. reports/
+	Prefix string `json:"prefix,omitempty"`
+	// Include are glob patterns matched against the object keys relative to the prefix, e.g. **/*.pdf. All objects
+	// are synced if empty.
+	Include []string `json:"include,omitempty"`
+	// Region defaults to AWS_REGION or us-east-1
+	Region string `json:"region,omitempty"`
+	// Endpoint is the URL of an S3-compatible service such as MinIO, path-style requests are used for custom endpoints
+	Endpoint string `json:"endpoint,omitempty"`
+	// RoleARN is the role assumed with the configured credentials, if set
+	RoleARN    string `json:"roleArn,omitempty"`
+	ExternalID string `json:"externalId,omitempty"`
+}
+
+// s3Source lists the objects of the bucket below the prefix that match the include patterns. Objects are written
+// to <bucket>/<key>, their ETag is the version, so only new and changed objects are downloaded.
+type s3Source struct {
+	client   *s3Client
+	config   *S3Config
+	patterns []*regexp.Regexp
+	logErr   *logrus.Logger
+}
+
+func main() {
+	logOut := logrus.New()
+	logOut.SetOutput(os.Stdout)
+	logOut.SetFormatter(&logrus.JSONFormatter{})
+	logErr := logrus.New()
+	logErr.SetOutput(os.Stderr)
+
+	ctx := context.Background()
+	gptscriptClient, err := gptscript.NewGPTScript()
+	if err != nil {
+		logOut.WithError(fmt.Errorf("failed to create gptscript client, error: %w", err)).Error()
+		os.Exit(0)
+	}
+
+	inputData := os.Getenv("GPTSCRIPT_INPUT")
+	input := MetadataInput{}
+
+	if err := json.Unmarshal([]byte(inputData), &input); err != nil {
+		logOut.WithError(fmt.Errorf("failed to unmarshal input data, error: %w", err)).Error()
+		os.Exit(0)
+	}
+	if input.S3Config == nil || input.S3Config.Bucket == "" {
+		logOut.WithError(errors.New("no bucket configured")).Error()
+		os.Exit(0)
+	}
+
+	patterns, err := datasource.CompileGlobs(input.S3Config.Include)
+	if err != nil {
+		logOut.WithError(fmt.Errorf("invalid include pattern, error: %w", err)).Error()
+		os.Exit(0)
+	}
+
+	source := &s3Source{client: newClient(input.S3Config), config: input.S3Config, patterns: patterns, logErr: logErr}
+	workspace := &datasource.GPTScriptWorkspace{Client: gptscriptClient}
+	store, closeStore, err := indexstore.FromEnv(ctx, workspace)
+	if err != nil {
+		logOut.WithError(fmt.Errorf("failed to open sync state store, error: %w", err)).Error()
+		os.Exit(0)
+	}
+	defer closeStore()
+
+	syncer := datasource.NewSyncer(source, store, workspace, datasource.Options{})
+	if _, err := syncer.Sync(ctx); err != nil {
+		logOut.WithError(fmt.Errorf("failed to sync s3 bucket, error: %w", err)).Error()
+		os.Exit(0)
+	}
+}
+
+func newClient(config *S3Config) *s3Client {
+	region := config.Region
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	creds := aws.Credentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	return newS3Client(config.Endpoint, region, creds, config.RoleARN, config.ExternalID)
+}
+
+func (s *s3Source) Name() string {
+	return "s3"
+}
+
+// List lists the objects page by page, objects that no longer exist or no longer match the patterns are removed
+func (s *s3Source) List(ctx context.Context, fn func([]datasource.Item) error) error {
+	err := s.client.ListObjects(ctx, s.config.Bucket, s.config.Prefix, func(objects []types.Object) error {
+		items := make([]datasource.Item, 0, len(objects))
+		for _, object := range objects {
+			key := aws.ToString(object.Key)
+			// Keys ending with a slash are folder placeholders
+			if strings.HasSuffix(key, "/") {
+				continue
+			}
+			if len(s.patterns) > 0 && !datasource.MatchesAny(s.patterns, strings.TrimPrefix(key, s.config.Prefix)) {
+				continue
+			}
+			if aws.ToInt64(object.Size) >= maxFileSize {
+				s.logErr.Infof("Skipping %s because it is larger than 50 MB", key)
+				continue
+			}
+
+			id := s.config.Bucket + "/" + key
+			items = append(items, datasource.Item{
+				ID:      id,
+				Path:    id,
+				URL:     "s3://" + id,
+				Version: aws.ToString(object.ETag),
+				Metadata: map[string]string{
+					"key":          key,
+					"lastModified": aws.ToTime(object.LastModified).UTC().Format(time.RFC3339),
+				},
+			})
+		}
+		return fn(items)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list objects of bucket %s: %w", s.config.Bucket, err)
+	}
+	return nil
+}
+
+func (s *s3Source) Fetch(ctx context.Context, item datasource.Item) ([]byte, error) {
+	return s.client.GetObject(ctx, s.config.Bucket, item.Metadata["key"])
+}
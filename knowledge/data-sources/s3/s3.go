@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+const roleSessionName = "knowledge-s3-data-source"
+
+type s3Client struct {
+	client *s3.Client
+}
+
+// newS3Client creates a client with the static credentials, which assumes the role first if roleARN is set. Requests
+// to a custom endpoint use path-style URLs, as S3-compatible services often don't support virtual hosted buckets.
+func newS3Client(endpoint, region string, creds aws.Credentials, roleARN, externalID string) *s3Client {
+	cfg := aws.Config{
+		Region:      region,
+		Credentials: credentials.StaticCredentialsProvider{Value: creds},
+	}
+	if roleARN != "" {
+		provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(cfg), roleARN, func(o *stscreds.AssumeRoleOptions) {
+			o.RoleSessionName = roleSessionName
+			if externalID != "" {
+				o.ExternalID = aws.String(externalID)
+			}
+		})
+		cfg.Credentials = aws.NewCredentialsCache(provider)
+	}
+
+	return &s3Client{
+		client: s3.NewFromConfig(cfg, func(o *s3.Options) {
+			if endpoint != "" {
+				if !strings.Contains(endpoint, "://") {
+					endpoint = "https://" + endpoint
+				}
+				o.BaseEndpoint = aws.String(strings.TrimSuffix(endpoint, "/"))
+				o.UsePathStyle = true
+			}
+		}),
+	}
+}
+
+// ListObjects calls fn with each page of the objects of the bucket below the prefix.
+func (c *s3Client) ListObjects(ctx context.Context, bucket, prefix string, fn func([]types.Object) error) error {
+	paginator := s3.NewListObjectsV2Paginator(c.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		if err := fn(page.Contents); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *s3Client) GetObject(ctx context.Context, bucket, key string) ([]byte, error) {
+	output, err := c.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object %s: %w", key, err)
+	}
+	defer output.Body.Close()
+	return io.ReadAll(output.Body)
+}
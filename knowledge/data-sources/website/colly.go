@@ -12,132 +12,256 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gocolly/colly"
-	"github.com/gptscript-ai/go-gptscript"
-	"github.com/sirupsen/logrus"
+	"github.com/gptscript-ai/knowledge/pkg/datasource"
 )
 
-func crawlColly(ctx context.Context, input *MetadataInput, output *MetadataOutput, logOut *logrus.Logger, gptscript *gptscript.GPTScript) error {
-	visited := make(map[string]struct{})
-	folders := make(map[string]struct{})
+const (
+	// userAgent identifies the crawler, so sites can address it in their robots.txt
+	userAgent      = "obot-knowledge-crawler"
+	defaultWorkers = 4
+	// batchSize is the number of crawled pages that are passed to the syncer at once
+	batchSize = 50
+)
 
-	for _, url := range input.WebsiteCrawlingConfig.URLs {
-		if err := scrape(ctx, logOut, output, gptscript, visited, folders, url, input.Limit); err != nil {
-			return fmt.Errorf("failed to scrape %s: %w", url, err)
+// stateLock guards visited and the state of the source, which are shared by the fetch workers of a collector.
+var stateLock sync.Mutex
+
+// crawlOptions are the limits of a crawl and its politeness settings.
+type crawlOptions struct {
+	// limit is the maximum number of pages
+	limit int
+	// maxDepth is the number of links followed from the URL, unlimited if 0
+	maxDepth int
+	// workers is the number of pages fetched concurrently from a host
+	workers int
+	// delay is the pause of each worker between two requests to a host
+	delay time.Duration
+	// robots is nil if robots.txt is ignored
+	robots *robotsRules
+	// render is renderAuto or renderAlways to render pages in a browser, empty to never render them
+	render string
+}
+
+func newCrawlOptions(input *MetadataInput) (crawlOptions, error) {
+	config := input.WebsiteCrawlingConfig
+	opts := crawlOptions{
+		limit:    input.Limit,
+		maxDepth: config.MaxDepth,
+		workers:  config.Workers,
+	}
+	if opts.workers <= 0 {
+		opts.workers = defaultWorkers
+	}
+	if config.Delay != "" {
+		delay, err := time.ParseDuration(config.Delay)
+		if err != nil || delay < 0 {
+			return opts, fmt.Errorf("invalid delay %q, expected a duration like 500ms", config.Delay)
 		}
+		opts.delay = delay
+	}
+	switch config.RenderJavaScript {
+	case "", "never":
+	case renderAuto, renderAlways:
+		opts.render = config.RenderJavaScript
+	default:
+		return opts, fmt.Errorf("invalid renderJavaScript %q, expected never, auto or always", config.RenderJavaScript)
+	}
+	if !config.IgnoreRobotsTxt {
+		opts.robots = newRobotsRules(userAgent)
+	}
+	return opts, nil
+}
+
+func (s *websiteSource) crawl(ctx context.Context) error {
+	visited := make(map[string]struct{})
+
+	opts, err := newCrawlOptions(s.input)
+	if err != nil {
+		return err
 	}
 
-	for p, file := range output.Files {
-		if _, ok := visited[p]; !ok {
-			logOut.Infof("removing file %s", file.FilePath)
-			if err := gptscript.DeleteFileInWorkspace(ctx, file.FilePath); err != nil {
-				return err
+	config := s.input.WebsiteCrawlingConfig
+	if len(config.Sitemaps) > 0 {
+		s.scrapeSitemap(ctx, visited, config.Sitemaps, nil, opts)
+	}
+
+	for _, url := range config.URLs {
+		if config.UseSitemaps {
+			baseURL, err := url2.Parse(url)
+			if err != nil {
+				return fmt.Errorf("invalid URL %s: %w", url, err)
+			}
+			include := func(pageURL *url2.URL) bool {
+				return isSameDomainOrSubdomain(pageURL.Host, baseURL.Host) && strings.HasPrefix(pageURL.Path, baseURL.Path)
+			}
+			if s.scrapeSitemap(ctx, visited, discoverSitemaps(ctx, baseURL), include, opts) > 0 {
+				continue
 			}
-			delete(output.Files, p)
+			s.logErr.Infof("no sitemap found for %s, following links instead", url)
+		}
+
+		if err := s.scrape(ctx, visited, url, opts); err != nil {
+			return fmt.Errorf("failed to scrape %s: %w", url, err)
 		}
 	}
 
-	output.Status = ""
-	return writeMetadata(ctx, output, gptscript)
+	if err := s.flush(); err != nil {
+		// The error is from the workspace, crawling again won't help
+		return datasource.Permanent(err)
+	}
+	return nil
+}
+
+// add queues the item of a crawled page, body is nil for pages that are not fetched again. It must be called with
+// stateLock held.
+func (s *websiteSource) add(item datasource.Item, body []byte) {
+	if s.err != nil {
+		return
+	}
+	s.batch = append(s.batch, item)
+	if body != nil {
+		s.bodies[item.ID] = body
+	}
+	if len(s.batch) >= batchSize {
+		s.err = s.flush()
+	}
+}
+
+// flush passes the queued items to the syncer. It must be called with stateLock held, or after the crawl.
+func (s *websiteSource) flush() error {
+	if s.err != nil || len(s.batch) == 0 {
+		return s.err
+	}
+	err := s.emit(s.batch)
+	s.batch = nil
+	clear(s.bodies)
+	return err
+}
+
+func (s *websiteSource) failed() bool {
+	stateLock.Lock()
+	defer stateLock.Unlock()
+	return s.err != nil
 }
 
-func scrape(ctx context.Context, logOut *logrus.Logger, output *MetadataOutput, gptscriptClient *gptscript.GPTScript, visited map[string]struct{}, folders map[string]struct{}, url string, limit int) error {
-	collector := colly.NewCollector()
+// newCollector returns a collector that adds every visited page to the items of the source. Pages are fetched
+// concurrently by opts.workers per host, the hosts get the Crawl-delay of their robots.txt if it is longer than
+// opts.delay. Call Wait to wait for the requests to finish.
+func (s *websiteSource) newCollector(ctx context.Context, visited map[string]struct{}, opts crawlOptions, hosts ...*url2.URL) *colly.Collector {
+	logOut := s.logErr
+	collectorOpts := []func(*colly.Collector){colly.Async(true), colly.UserAgent(userAgent)}
+	if opts.maxDepth > 0 {
+		// colly counts the start URL as depth 1
+		collectorOpts = append(collectorOpts, colly.MaxDepth(opts.maxDepth+1))
+	}
+	collector := colly.NewCollector(collectorOpts...)
+	collector.IgnoreRobotsTxt = opts.robots == nil
+
+	limited := map[string]struct{}{}
+	for _, host := range hosts {
+		if _, ok := limited[host.Host]; ok {
+			continue
+		}
+		limited[host.Host] = struct{}{}
+		rule := &colly.LimitRule{DomainGlob: host.Host, Parallelism: opts.workers, Delay: opts.delay}
+		if crawlDelay := opts.robots.crawlDelay(ctx, host); crawlDelay > 0 {
+			// The Crawl-delay is the time between two requests to the host, not per worker
+			rule.Parallelism = 1
+			rule.Delay = max(rule.Delay, crawlDelay)
+		}
+		if err := collector.Limit(rule); err != nil {
+			logOut.Infof("Failed to limit requests to %s: %v", host.Host, err)
+		}
+	}
+	// All other hosts, e.g. the www. variant of a host
+	if err := collector.Limit(&colly.LimitRule{DomainGlob: "*", Parallelism: opts.workers, Delay: opts.delay}); err != nil {
+		logOut.Infof("Failed to limit requests: %v", err)
+	}
+
+	// Stop crawling once the items can't be synced
+	collector.OnRequest(func(r *colly.Request) {
+		if s.failed() {
+			r.Abort()
+		}
+	})
+
 	collector.OnHTML("body", func(e *colly.HTMLElement) {
 		html, err := e.DOM.Html()
 		if err != nil {
 			logOut.Errorf("Failed to grab HTML: %v", err)
 			return
 		}
-		hostname := e.Request.URL.Hostname()
-		urlPathWithQuery := e.Request.URL.Path
-		if e.Request.URL.RawQuery != "" {
-			urlPathWithQuery += "?" + url2.QueryEscape(e.Request.URL.RawQuery)
-		}
 
-		var filePath string
-		if urlPathWithQuery == "" {
-			filePath = path.Join(hostname, "index.html")
-		} else {
-			trimmedPath := strings.Trim(urlPathWithQuery, "/")
-			if trimmedPath == "" {
-				filePath = path.Join(hostname, "index.html")
-			} else {
-				segments := strings.Split(trimmedPath, "/")
-				fileName := segments[len(segments)-1] + ".html"
-				filePath = path.Join(hostname, strings.Join(segments[:len(segments)-1], "/"), fileName)
-			}
-		}
-		if _, ok := visited[filePath]; ok {
+		pageURL := e.Request.URL.String()
+		filePath := pageFilePath(e.Request.URL)
+		if isVisited(visited, filePath) || isVisited(visited, renderedFilePath(filePath)) {
 			return
 		}
 
-		logOut.Infof("scraping %s", e.Request.URL.String())
-		fileNotExists := false
-		var notFoundError *gptscript.NotFoundInWorkspaceError
-		if _, err := gptscriptClient.ReadFileInWorkspace(ctx, filePath); errors.As(err, &notFoundError) {
-			fileNotExists = true
-		}
-
-		etag := e.Response.Headers.Get("ETag")
-		lastModified := e.Response.Headers.Get("Last-Modified")
-		var updatedAt string
-		if etag != "" {
-			updatedAt = etag
-		} else if lastModified != "" {
-			updatedAt = lastModified
-		} else {
-			updatedAt = time.Now().Format(time.RFC3339)
-		}
-
-		defer func() {
-			if err := writeMetadata(ctx, output, gptscriptClient); err != nil {
-				logOut.Infof("Failed to write metadata: %v", err)
+		// Pages rendered in the browser are stored as markdown
+		if needsRendering(opts.render, e) {
+			logOut.Infof("rendering %s", e.Request.URL.String())
+			markdown, err := renderPage(ctx, s.gptscriptClient, e.Request.URL.String())
+			if err != nil {
+				logOut.Infof("Failed to render %s, keeping the HTML: %v", e.Request.URL.String(), err)
+			} else {
+				html, filePath = markdown, renderedFilePath(filePath)
+				// The links of the page are often only added by JavaScript
+				// Requests share the context of the page that linked them, so the key includes the URL
+				e.Request.Ctx.Put("renderedLinks "+e.Request.URL.String(), strings.Join(markdownLinks(markdown), "\n"))
 			}
-		}()
-
-		if updatedAt == output.Files[e.Request.URL.String()].UpdatedAt && !fileNotExists {
-			output.Status = fmt.Sprintf("Skipping %s because it has not changed", e.Request.URL.String())
-			logOut.Infof("skipping %s because it has not changed for etag/last-modified: %s/%s", e.Request.URL.String(), etag, lastModified)
-			return
 		}
 
-		checksum, err := getChecksum([]byte(html))
-		if err != nil {
-			logOut.Errorf("Failed to get checksum for %s: %v", e.Request.URL.String(), err)
-			return
-		}
-		if checksum == output.Files[e.Request.URL.String()].Checksum && !fileNotExists {
-			output.Status = fmt.Sprintf("Skipping %s because it has not changed", e.Request.URL.String())
-			logOut.Infof("skipping %s because it has not changed", e.Request.URL.String())
+		stateLock.Lock()
+		defer stateLock.Unlock()
+
+		if _, ok := visited[filePath]; ok {
 			return
 		}
-
-		if err := gptscriptClient.WriteFileInWorkspace(ctx, filePath, []byte(html)); err != nil {
-			logOut.Errorf("Failed to write file %s: %v", filePath, err)
+		if len(visited) >= opts.limit {
 			return
 		}
-
 		visited[filePath] = struct{}{}
 
-		output.Files[filePath] = FileDetails{
-			FilePath:    filePath,
-			URL:         e.Request.URL.String(),
-			UpdatedAt:   updatedAt,
-			Checksum:    checksum,
-			SizeInBytes: int64(len([]byte(html))),
+		logOut.Infof("scraping %s", pageURL)
+		// Pages of a sitemap are only fetched again if their lastmod changes, the others if their content changes
+		version := e.Request.Ctx.Get("lastmod")
+		if version != "" {
+			s.state.Sitemap[pageURL] = SitemapPage{LastModified: version, Path: filePath}
+		} else {
+			checksum, err := getChecksum([]byte(html))
+			if err != nil {
+				logOut.Errorf("Failed to get checksum for %s: %v", pageURL, err)
+				return
+			}
+			version = checksum
 		}
 
-		folders[hostname] = struct{}{}
-		output.State.WebsiteCrawlingState.Folders = folders
-		output.Status = fmt.Sprintf("Scraped %v", e.Request.URL.String())
+		s.add(datasource.Item{
+			ID:      pageURL,
+			Path:    filePath,
+			URL:     pageURL,
+			Version: version,
+		}, []byte(html))
 	})
 
-	collector.OnHTML("a[href]", func(e *colly.HTMLElement) {
-		link := e.Attr("href")
-		if len(visited) == limit {
+	return collector
+}
+
+func (s *websiteSource) scrape(ctx context.Context, visited map[string]struct{}, url string, opts crawlOptions) error {
+	logOut := s.logErr
+	startURL, err := url2.Parse(url)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	collector := s.newCollector(ctx, visited, opts, startURL)
+
+	followLink := func(request *colly.Request, link string) {
+		if countVisited(visited) >= opts.limit {
 			return
 		}
 
@@ -151,11 +275,11 @@ func scrape(ctx context.Context, logOut *logrus.Logger, output *MetadataOutput,
 			logOut.Infof("Invalid link URL %s: %v", link, err)
 			return
 		}
-		if _, ok := visited[linkURL.String()]; ok {
+		if isVisited(visited, linkURL.String()) {
 			return
 		}
 		if strings.ToLower(path.Ext(linkURL.Path)) == ".pdf" {
-			if err := scrapePDF(ctx, logOut, output, visited, linkURL, baseURL, gptscriptClient); err != nil {
+			if err := s.scrapePDF(ctx, visited, linkURL, baseURL, opts.robots); err != nil {
 				logOut.Infof("Failed to scrape PDF %s: %v", linkURL.String(), err)
 			}
 		} else {
@@ -170,7 +294,7 @@ func scrape(ctx context.Context, logOut *logrus.Logger, output *MetadataOutput,
 			}
 
 			// if it is relative path, join with current path and check again
-			finalPath := filepath.Clean(filepath.Join(e.Request.URL.Path, linkURL.Path))
+			finalPath := filepath.Clean(filepath.Join(request.URL.Path, linkURL.Path))
 
 			if !strings.HasPrefix(finalPath, baseURL.Path) {
 				return
@@ -192,10 +316,63 @@ func scrape(ctx context.Context, logOut *logrus.Logger, output *MetadataOutput,
 				}
 				linkURL = parsedLink
 			}
-			e.Request.Visit(linkURL.String())
+			request.Visit(linkURL.String())
+		}
+	}
+	collector.OnHTML("a[href]", func(e *colly.HTMLElement) {
+		followLink(e.Request, e.Attr("href"))
+	})
+	collector.OnScraped(func(r *colly.Response) {
+		if links := r.Ctx.Get("renderedLinks " + r.Request.URL.String()); links != "" {
+			for _, link := range strings.Split(links, "\n") {
+				followLink(r.Request, link)
+			}
 		}
 	})
-	return collector.Visit(url)
+
+	if err := collector.Visit(url); errors.Is(err, colly.ErrRobotsTxtBlocked) {
+		logOut.Infof("skipping %s because it is disallowed by robots.txt", url)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	collector.Wait()
+	return nil
+}
+
+func countVisited(visited map[string]struct{}) int {
+	stateLock.Lock()
+	defer stateLock.Unlock()
+	return len(visited)
+}
+
+func isVisited(visited map[string]struct{}, key string) bool {
+	stateLock.Lock()
+	defer stateLock.Unlock()
+	_, ok := visited[key]
+	return ok
+}
+
+// renderedFilePath returns the workspace path of the markdown file of a page rendered in the browser.
+func renderedFilePath(filePath string) string {
+	return strings.TrimSuffix(filePath, ".html") + ".md"
+}
+
+// pageFilePath returns the workspace path of the HTML file of a page, e.g. example.com/docs/intro.html.
+func pageFilePath(u *url2.URL) string {
+	hostname := u.Hostname()
+	urlPathWithQuery := u.Path
+	if u.RawQuery != "" {
+		urlPathWithQuery += "?" + url2.QueryEscape(u.RawQuery)
+	}
+
+	trimmedPath := strings.Trim(urlPathWithQuery, "/")
+	if trimmedPath == "" {
+		return path.Join(hostname, "index.html")
+	}
+	segments := strings.Split(trimmedPath, "/")
+	fileName := segments[len(segments)-1] + ".html"
+	return path.Join(hostname, strings.Join(segments[:len(segments)-1], "/"), fileName)
 }
 
 func isSameDomainOrSubdomain(linkHostname, baseHostname string) bool {
@@ -225,7 +402,8 @@ func isSameDomainOrSubdomain(linkHostname, baseHostname string) bool {
 	return false
 }
 
-func scrapePDF(ctx context.Context, logOut *logrus.Logger, output *MetadataOutput, visited map[string]struct{}, linkURL *url2.URL, baseURL *url2.URL, gptscript *gptscript.GPTScript) error {
+func (s *websiteSource) scrapePDF(ctx context.Context, visited map[string]struct{}, linkURL *url2.URL, baseURL *url2.URL, robots *robotsRules) error {
+	logOut := s.logErr
 	if linkURL.Host == "" {
 		var err error
 		fullLink := baseURL.ResolveReference(linkURL).String()
@@ -238,12 +416,21 @@ func scrapePDF(ctx context.Context, logOut *logrus.Logger, output *MetadataOutpu
 	if !isSameDomainOrSubdomain(linkURL.Host, baseURL.Host) {
 		filePath = path.Join(baseURL.Host, filePath)
 	}
-	if _, ok := visited[filePath]; ok {
+	if isVisited(visited, filePath) {
+		return nil
+	}
+	if !robots.allowed(ctx, linkURL) {
+		logOut.Infof("skipping %s because it is disallowed by robots.txt", linkURL.String())
 		return nil
 	}
 
 	logOut.Infof("downloading PDF %s", linkURL.String())
-	resp, err := http.Get(linkURL.String())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, linkURL.String(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to download PDF %s: %v", linkURL.String(), err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to download PDF %s: %v", linkURL.String(), err)
 	}
@@ -267,32 +454,39 @@ func scrapePDF(ctx context.Context, logOut *logrus.Logger, output *MetadataOutpu
 		return fmt.Errorf("failed to calculate checksum: %v", err)
 	}
 
-	if fileDetails, exists := output.Files[linkURL.String()]; exists {
-		if fileDetails.Checksum == newChecksum {
-			logOut.Infof("PDF %s has not been modified", linkURL.String())
-			return nil
-		}
-	}
+	stateLock.Lock()
+	defer stateLock.Unlock()
 
-	if err := gptscript.WriteFileInWorkspace(ctx, filePath, data); err != nil {
-		return fmt.Errorf("failed to write PDF %s: %v", linkURL.String(), err)
+	if _, ok := visited[filePath]; ok {
+		return nil
 	}
-
 	visited[filePath] = struct{}{}
 
-	output.Status = fmt.Sprintf("Scraped %v", linkURL.String())
-	output.Files[filePath] = FileDetails{
-		FilePath:    filePath,
-		URL:         linkURL.String(),
-		UpdatedAt:   time.Now().String(),
-		Checksum:    newChecksum,
-		SizeInBytes: int64(len(data)),
-	}
+	s.add(datasource.Item{
+		ID:      linkURL.String(),
+		Path:    filePath,
+		URL:     linkURL.String(),
+		Version: newChecksum,
+	}, data)
+	return nil
+}
 
-	if err := writeMetadata(ctx, output, gptscript); err != nil {
-		return fmt.Errorf("failed to write metadata: %v", err)
+// downloadPage returns the HTML of the page
+func downloadPage(ctx context.Context, pageURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: status code %d", pageURL, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
 }
 
 func getChecksum(content []byte) (string, error) {
@@ -1,6 +1,13 @@
 package main
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gptscript-ai/knowledge/pkg/datasource"
+)
 
 func TestIsSameDomainOrSubdomain(t *testing.T) {
 	tests := []struct {
@@ -30,3 +37,56 @@ func TestIsSameDomainOrSubdomain(t *testing.T) {
 		}
 	}
 }
+
+func TestAddBatches(t *testing.T) {
+	var batches [][]datasource.Item
+	var fetched []string
+	s := &websiteSource{bodies: map[string][]byte{}}
+	s.emit = func(items []datasource.Item) error {
+		batches = append(batches, items)
+		for _, item := range items {
+			data, err := s.Fetch(context.Background(), item)
+			if err != nil {
+				return err
+			}
+			fetched = append(fetched, string(data))
+		}
+		return nil
+	}
+
+	for i := range batchSize + 1 {
+		id := fmt.Sprintf("https://example.com/%d", i)
+		s.add(datasource.Item{ID: id, Path: fmt.Sprintf("example.com/%d.html", i), URL: id}, []byte(id))
+	}
+	if len(batches) != 1 || len(batches[0]) != batchSize {
+		t.Fatalf("expected one full batch before the flush, got %d batches", len(batches))
+	}
+	if err := s.flush(); err != nil {
+		t.Fatal(err)
+	}
+	if len(batches) != 2 || len(batches[1]) != 1 {
+		t.Fatalf("expected the flush to pass the last item, got %d batches", len(batches))
+	}
+	if len(fetched) != batchSize+1 || fetched[batchSize] != fmt.Sprintf("https://example.com/%d", batchSize) {
+		t.Errorf("expected the bodies of the crawled pages, got %d", len(fetched))
+	}
+	if len(s.bodies) != 0 {
+		t.Errorf("expected the bodies to be released after the flush, got %d", len(s.bodies))
+	}
+}
+
+func TestAddStopsAfterError(t *testing.T) {
+	calls := 0
+	s := &websiteSource{bodies: map[string][]byte{}}
+	s.emit = func([]datasource.Item) error {
+		calls++
+		return errors.New("workspace unavailable")
+	}
+
+	for i := range 2 * batchSize {
+		s.add(datasource.Item{ID: fmt.Sprint(i)}, []byte("body"))
+	}
+	if err := s.flush(); err == nil || calls != 1 {
+		t.Errorf("expected the first error to stop the sync after one call, got %v after %d calls", err, calls)
+	}
+}
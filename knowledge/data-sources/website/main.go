@@ -3,12 +3,14 @@ package main
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/knowledge/pkg/datasource"
+	"github.com/gptscript-ai/knowledge/pkg/datasource/indexstore"
 	"github.com/sirupsen/logrus"
 )
 
@@ -19,28 +21,49 @@ type MetadataInput struct {
 
 type WebsiteCrawlingConfig struct {
 	URLs []string `json:"urls"`
+	// Sitemaps are URLs of sitemaps or sitemap indexes. All pages listed in them are scraped, no links are followed.
+	Sitemaps []string `json:"sitemaps,omitempty"`
+	// UseSitemaps looks up the sitemaps of the URLs in robots.txt (or at /sitemap.xml) and scrapes the pages below the
+	// URLs listed in them. URLs without a sitemap are crawled by following links.
+	UseSitemaps bool `json:"useSitemaps,omitempty"`
+	// IgnoreRobotsTxt also scrapes the pages that robots.txt disallows
+	IgnoreRobotsTxt bool `json:"ignoreRobotsTxt,omitempty"`
+	// MaxDepth is the number of links followed from the URLs, unlimited if 0
+	MaxDepth int `json:"maxDepth,omitempty"`
+	// Workers is the number of pages fetched concurrently from a host, 4 if 0
+	Workers int `json:"workers,omitempty"`
+	// Delay is the pause of each worker between two requests to a host, e.g. 500ms
+	Delay string `json:"delay,omitempty"`
+	// RenderJavaScript renders pages in a headless browser before scraping them: "auto" for pages that have almost no
+	// text without JavaScript, "always" for all pages. Defaults to "never".
+	RenderJavaScript string `json:"renderJavaScript,omitempty"`
 }
 
-type MetadataOutput struct {
-	Status string                 `json:"status"`
-	State  State                  `json:"state"`
-	Files  map[string]FileDetails `json:"files"`
+type websiteState struct {
+	// Sitemap are the pages found in a sitemap by URL, pages whose lastmod didn't change are not fetched again
+	Sitemap map[string]SitemapPage `json:"sitemap,omitempty"`
 }
 
-type State struct {
-	WebsiteCrawlingState WebsiteCrawlingState `json:"websiteCrawlingState"`
+type SitemapPage struct {
+	LastModified string `json:"lastModified"`
+	Path         string `json:"path"`
 }
 
-type WebsiteCrawlingState struct {
-	Folders map[string]struct{} `json:"folders"`
-}
-
-type FileDetails struct {
-	FilePath    string `json:"filePath,omitempty"`
-	URL         string `json:"url,omitempty"`
-	UpdatedAt   string `json:"updatedAt,omitempty"`
-	Checksum    string `json:"checksum,omitempty"`
-	SizeInBytes int64  `json:"sizeInBytes,omitempty"`
+// websiteSource crawls the websites. The pages are passed to the syncer in batches while they are crawled, Fetch
+// returns the body of the crawled page.
+type websiteSource struct {
+	input           *MetadataInput
+	gptscriptClient *gptscript.GPTScript
+	logErr          *logrus.Logger
+	state           websiteState
+	// previous are the sitemap pages of the last sync
+	previous map[string]SitemapPage
+
+	// The fields below are guarded by stateLock
+	emit   func([]datasource.Item) error
+	batch  []datasource.Item
+	bodies map[string][]byte
+	err    error
 }
 
 func main() {
@@ -68,25 +91,22 @@ func main() {
 		input.Limit = getFromEnvOrDefault("OBOT_WEBSCRAPER_LIMIT", 250)
 	}
 
-	output := MetadataOutput{}
-
-	var notfoundErr *gptscript.NotFoundInWorkspaceError
-	outputData, err := gptscriptClient.ReadFileInWorkspace(ctx, ".metadata.json")
-	if err != nil && !errors.As(err, &notfoundErr) {
-		logOut.WithError(fmt.Errorf("failed to read .metadata.json in workspace, error: %w", err)).Error()
-		os.Exit(0)
-	} else if err == nil {
-		if err := json.Unmarshal(outputData, &output); err != nil {
-			logOut.WithError(fmt.Errorf("failed to unmarshal output data, error: %w", err)).Error()
-			os.Exit(0)
-		}
+	source := &websiteSource{
+		input:           &input,
+		gptscriptClient: gptscriptClient,
+		logErr:          logErr,
 	}
 
-	if output.Files == nil {
-		output.Files = make(map[string]FileDetails)
+	workspace := &datasource.GPTScriptWorkspace{Client: gptscriptClient}
+	store, closeStore, err := indexstore.FromEnv(ctx, workspace)
+	if err != nil {
+		logOut.WithError(fmt.Errorf("failed to open sync state store, error: %w", err)).Error()
+		os.Exit(0)
 	}
+	defer closeStore()
 
-	if err := crawlColly(ctx, &input, &output, logErr, gptscriptClient); err != nil {
+	syncer := datasource.NewSyncer(source, store, workspace, datasource.Options{})
+	if _, err := syncer.Sync(ctx); err != nil {
 		logOut.WithError(fmt.Errorf("failed to crawl website: error: %w", err)).Error()
 		os.Exit(0)
 	}
@@ -100,10 +120,44 @@ func getFromEnvOrDefault(env string, def int) int {
 	return def
 }
 
-func writeMetadata(ctx context.Context, output *MetadataOutput, gptscript *gptscript.GPTScript) error {
-	data, err := json.MarshalIndent(output, "", "  ")
-	if err != nil {
-		return err
+func (s *websiteSource) Name() string {
+	return "website"
+}
+
+// List crawls the websites. The pages that are not found anymore are deleted by the syncer.
+func (s *websiteSource) List(ctx context.Context, fn func([]datasource.Item) error) error {
+	s.previous = s.state.Sitemap
+	s.state.Sitemap = map[string]SitemapPage{}
+	s.emit = fn
+	s.batch = nil
+	s.bodies = map[string][]byte{}
+	s.err = nil
+	return s.crawl(ctx)
+}
+
+// Fetch returns the body of the crawled page. It is called by the syncer while stateLock is held. Pages that were not
+// crawled in this sync, i.e. unchanged sitemap pages whose file is missing, are downloaded again.
+func (s *websiteSource) Fetch(ctx context.Context, item datasource.Item) ([]byte, error) {
+	if body, ok := s.bodies[item.ID]; ok {
+		return body, nil
+	}
+	if strings.HasSuffix(item.Path, ".md") {
+		markdown, err := renderPage(ctx, s.gptscriptClient, item.URL)
+		return []byte(markdown), err
 	}
-	return gptscript.WriteFileInWorkspace(ctx, ".metadata.json", data)
+	return downloadPage(ctx, item.URL)
+}
+
+func (s *websiteSource) LoadState(data json.RawMessage) error {
+	s.state = websiteState{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &s.state); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *websiteSource) SaveState() (json.RawMessage, error) {
+	return json.Marshal(s.state)
 }
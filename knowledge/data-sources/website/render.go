@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/gocolly/colly"
+	"github.com/gptscript-ai/go-gptscript"
+)
+
+const (
+	// renderAuto renders pages whose HTML has almost no text, which is typical for single page applications
+	renderAuto = "auto"
+	// renderAlways renders all pages
+	renderAlways = "always"
+
+	// minTextLength is the length of the text of a page below which renderAuto renders it
+	minTextLength = 200
+	// renderTabID is the browser tab that is reused for all pages, so no tabs are left open
+	renderTabID = "knowledge-website"
+)
+
+var (
+	// browserTool is the browser tool that renders pages in a headless browser
+	browserTool = gptscript.GetEnv("BROWSER_TOOL", filepath.Join(os.Getenv("GPTSCRIPT_TOOL_DIR"), "..", "..", "..", "browser", "tool.gpt"))
+
+	// renderLock serializes the pages rendered by the fetch workers, which share one browser tab
+	renderLock sync.Mutex
+
+	markdownLink = regexp.MustCompile(`\[[^\]]*\]\(([^)\s]+)`)
+)
+
+// needsRendering reports whether the page must be rendered in a browser to get its content.
+func needsRendering(mode string, e *colly.HTMLElement) bool {
+	switch mode {
+	case renderAlways:
+		return true
+	case renderAuto:
+		body := e.DOM.Clone()
+		body.Find("script, noscript, style, template").Remove()
+		return len(strings.TrimSpace(body.Text())) < minTextLength
+	default:
+		return false
+	}
+}
+
+// renderPage runs the JavaScript of the page in the browser tool and returns its content as markdown.
+func renderPage(ctx context.Context, gptscriptClient *gptscript.GPTScript, pageURL string) (string, error) {
+	renderLock.Lock()
+	defer renderLock.Unlock()
+
+	input, err := json.Marshal(map[string]string{
+		"website": pageURL,
+		"tabID":   renderTabID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	run, err := gptscriptClient.Run(ctx, browserTool, gptscript.Options{
+		Input:   string(input),
+		SubTool: "Get Page Contents",
+	})
+	if err != nil {
+		return "", err
+	}
+	defer run.Close()
+
+	text, err := run.Text()
+	if err != nil {
+		return "", err
+	}
+	// The browser service reports errors as plain text
+	if strings.HasPrefix(text, "Error: ") {
+		return "", errors.New(strings.TrimPrefix(text, "Error: "))
+	}
+
+	var resp struct {
+		Result string `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(text), &resp); err != nil {
+		return "", fmt.Errorf("failed to parse browser response: %w", err)
+	}
+	if strings.TrimSpace(resp.Result) == "" {
+		return "", errors.New("the rendered page is empty")
+	}
+	return resp.Result, nil
+}
+
+// markdownLinks returns the targets of the links in markdown, e.g. /docs/intro for [Intro](/docs/intro "Title").
+func markdownLinks(markdown string) []string {
+	var links []string
+	for _, match := range markdownLink.FindAllStringSubmatch(markdown, -1) {
+		links = append(links, match[1])
+	}
+	return links
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMarkdownLinks(t *testing.T) {
+	markdown := "# Docs\n\nSee [Intro](/docs/intro) and [Setup](setup \"Setup guide\").\n[](https://example.com/empty)\n\n[not a link]"
+	want := []string{"/docs/intro", "setup", "https://example.com/empty"}
+	if got := markdownLinks(markdown); !reflect.DeepEqual(got, want) {
+		t.Errorf("markdownLinks() = %v, want %v", got, want)
+	}
+}
+
+func TestRenderedFilePath(t *testing.T) {
+	tests := map[string]string{
+		"example.com/index.html":      "example.com/index.md",
+		"example.com/docs/intro.html": "example.com/docs/intro.md",
+	}
+	for filePath, want := range tests {
+		if got := renderedFilePath(filePath); got != want {
+			t.Errorf("renderedFilePath(%q) = %q, want %q", filePath, got, want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	url2 "net/url"
+	"sync"
+	"time"
+
+	"github.com/temoto/robotstxt"
+)
+
+// robotsRules caches the robots.txt of the crawled hosts. colly checks the pages it fetches itself, this covers the
+// downloads outside of colly (PDFs) and the Crawl-delay, which colly ignores.
+type robotsRules struct {
+	userAgent string
+
+	lock   sync.Mutex
+	groups map[string]*robotstxt.Group
+}
+
+func newRobotsRules(userAgent string) *robotsRules {
+	return &robotsRules{
+		userAgent: userAgent,
+		groups:    map[string]*robotstxt.Group{},
+	}
+}
+
+// group returns the rules of the robots.txt that apply to the user agent, or nil if the site has no robots.txt or
+// it could not be read.
+func (r *robotsRules) group(ctx context.Context, u *url2.URL) *robotstxt.Group {
+	host := u.Scheme + "://" + u.Host
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if group, ok := r.groups[host]; ok {
+		return group
+	}
+
+	var group *robotstxt.Group
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, host+"/robots.txt", nil)
+	if err == nil {
+		req.Header.Set("User-Agent", r.userAgent)
+		if resp, err := http.DefaultClient.Do(req); err == nil {
+			// FromResponse allows everything for 4xx responses and nothing for 5xx responses
+			if data, err := robotstxt.FromResponse(resp); err == nil {
+				group = data.FindGroup(r.userAgent)
+			}
+			resp.Body.Close()
+		}
+	}
+	r.groups[host] = group
+	return group
+}
+
+// allowed reports whether the robots.txt of the host allows fetching the URL.
+func (r *robotsRules) allowed(ctx context.Context, u *url2.URL) bool {
+	if r == nil {
+		return true
+	}
+	group := r.group(ctx, u)
+	return group == nil || group.Test(u.EscapedPath())
+}
+
+// crawlDelay returns the Crawl-delay of the robots.txt of the host, 0 if not set.
+func (r *robotsRules) crawlDelay(ctx context.Context, u *url2.URL) time.Duration {
+	if r == nil {
+		return 0
+	}
+	if group := r.group(ctx, u); group != nil {
+		return group.CrawlDelay
+	}
+	return 0
+}
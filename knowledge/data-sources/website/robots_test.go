@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	url2 "net/url"
+	"testing"
+	"time"
+)
+
+func TestRobotsRules(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		requests++
+		fmt.Fprintf(w, "User-agent: *\nDisallow: /private/\nCrawl-delay: 2\n\nUser-agent: %s\nDisallow: /drafts/\n", userAgent)
+	}))
+	defer server.Close()
+
+	robots := newRobotsRules(userAgent)
+	tests := []struct {
+		path    string
+		allowed bool
+	}{
+		{"/docs/intro", true},
+		{"/private/report.pdf", true},
+		{"/drafts/report.pdf", false},
+	}
+	for _, test := range tests {
+		u, _ := url2.Parse(server.URL + test.path)
+		if got := robots.allowed(context.Background(), u); got != test.allowed {
+			t.Errorf("allowed(%s) = %v, want %v", test.path, got, test.allowed)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("robots.txt was requested %d times, want 1", requests)
+	}
+
+	other := newRobotsRules("other-crawler")
+	u, _ := url2.Parse(server.URL + "/private/report.pdf")
+	if other.allowed(context.Background(), u) {
+		t.Errorf("allowed(%s) = true for other-crawler, want false", u.Path)
+	}
+	if delay := other.crawlDelay(context.Background(), u); delay != 2*time.Second {
+		t.Errorf("crawlDelay = %v, want 2s", delay)
+	}
+
+	var ignored *robotsRules
+	if !ignored.allowed(context.Background(), u) || ignored.crawlDelay(context.Background(), u) != 0 {
+		t.Error("nil robotsRules must allow everything without delay")
+	}
+}
+
+func TestRobotsRulesWithoutRobotsTxt(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	u, _ := url2.Parse(server.URL + "/docs/intro")
+	if !newRobotsRules(userAgent).allowed(context.Background(), u) {
+		t.Error("pages must be allowed if there is no robots.txt")
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	url2 "net/url"
+	"path"
+	"strings"
+
+	"github.com/gocolly/colly"
+	"github.com/gptscript-ai/knowledge/pkg/datasource"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// Sitemaps are limited to 50 MB uncompressed by the protocol
+	maxSitemapSize = 50 * 1024 * 1024
+	// maxSitemapDepth limits nested sitemap indexes
+	maxSitemapDepth = 3
+)
+
+type sitemapEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod"`
+}
+
+// sitemap is either a <urlset> with the pages or a <sitemapindex> pointing to more sitemaps.
+type sitemap struct {
+	URLs     []sitemapEntry `xml:"url"`
+	Sitemaps []sitemapEntry `xml:"sitemap"`
+}
+
+// readSitemaps returns the pages listed in the sitemaps, following sitemap indexes.
+func readSitemaps(ctx context.Context, logOut *logrus.Logger, sitemapURLs []string) []sitemapEntry {
+	var (
+		pages []sitemapEntry
+		seen  = map[string]struct{}{}
+	)
+	var read func(sitemapURL string, depth int)
+	read = func(sitemapURL string, depth int) {
+		if _, ok := seen[sitemapURL]; ok || depth > maxSitemapDepth {
+			return
+		}
+		seen[sitemapURL] = struct{}{}
+
+		sm, err := fetchSitemap(ctx, sitemapURL)
+		if err != nil {
+			logOut.Infof("Failed to read sitemap %s: %v", sitemapURL, err)
+			return
+		}
+		logOut.Infof("read sitemap %s with %d pages and %d sitemaps", sitemapURL, len(sm.URLs), len(sm.Sitemaps))
+
+		for _, page := range sm.URLs {
+			if page.Loc = strings.TrimSpace(page.Loc); page.Loc != "" {
+				page.LastMod = strings.TrimSpace(page.LastMod)
+				pages = append(pages, page)
+			}
+		}
+		for _, child := range sm.Sitemaps {
+			if loc := strings.TrimSpace(child.Loc); loc != "" {
+				read(loc, depth+1)
+			}
+		}
+	}
+
+	for _, sitemapURL := range sitemapURLs {
+		read(sitemapURL, 0)
+	}
+	return pages
+}
+
+func fetchSitemap(ctx context.Context, sitemapURL string) (*sitemap, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sitemapURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status code %d", resp.StatusCode)
+	}
+
+	var body io.Reader = bufio.NewReader(resp.Body)
+	// Sitemaps are often served as .xml.gz files without a Content-Encoding
+	if magic, _ := body.(*bufio.Reader).Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		body = gz
+	}
+
+	var sm sitemap
+	if err := xml.NewDecoder(io.LimitReader(body, maxSitemapSize)).Decode(&sm); err != nil {
+		return nil, fmt.Errorf("failed to parse sitemap: %w", err)
+	}
+	return &sm, nil
+}
+
+// discoverSitemaps returns the sitemaps listed in the robots.txt of the site, or /sitemap.xml if there are none.
+func discoverSitemaps(ctx context.Context, base *url2.URL) []string {
+	root := &url2.URL{Scheme: base.Scheme, Host: base.Host}
+	fallback := []string{root.JoinPath("sitemap.xml").String()}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, root.JoinPath("robots.txt").String(), nil)
+	if err != nil {
+		return fallback
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fallback
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fallback
+	}
+
+	var sitemaps []string
+	scanner := bufio.NewScanner(io.LimitReader(resp.Body, 1024*1024))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if ok && strings.EqualFold(strings.TrimSpace(key), "sitemap") {
+			if value = strings.TrimSpace(value); value != "" {
+				sitemaps = append(sitemaps, value)
+			}
+		}
+	}
+	if len(sitemaps) == 0 {
+		return fallback
+	}
+	return sitemaps
+}
+
+// scrapeSitemap scrapes the pages listed in the sitemaps instead of following links. Pages are only scraped again if
+// their lastmod changed since the last sync, or if it is not set. The include func filters the pages.
+// It returns the number of pages found in the sitemaps.
+func (s *websiteSource) scrapeSitemap(ctx context.Context, visited map[string]struct{}, sitemapURLs []string, include func(*url2.URL) bool, opts crawlOptions) int {
+	logOut := s.logErr
+	type page struct {
+		url     *url2.URL
+		lastMod string
+	}
+	var (
+		pages []page
+		hosts []*url2.URL
+	)
+	for _, entry := range readSitemaps(ctx, logOut, sitemapURLs) {
+		pageURL, err := url2.Parse(entry.Loc)
+		if err != nil || pageURL.Host == "" {
+			logOut.Infof("Invalid page URL %s in sitemap", entry.Loc)
+			continue
+		}
+		if include != nil && !include(pageURL) {
+			continue
+		}
+		pages = append(pages, page{url: pageURL, lastMod: entry.LastMod})
+		hosts = append(hosts, pageURL)
+	}
+
+	collector := s.newCollector(ctx, visited, opts, hosts...)
+
+	// The requests are asynchronous, so count the scheduled pages against the limit
+	scheduled := countVisited(visited)
+	for _, page := range pages {
+		if scheduled >= opts.limit {
+			logOut.Infof("reached the limit of %d pages", opts.limit)
+			break
+		}
+		scheduled++
+
+		pageURL := page.url
+		if strings.ToLower(path.Ext(pageURL.Path)) == ".pdf" {
+			if err := s.scrapePDF(ctx, visited, pageURL, pageURL, opts.robots); err != nil {
+				logOut.Infof("Failed to scrape PDF %s: %v", pageURL.String(), err)
+			}
+			continue
+		}
+
+		stateLock.Lock()
+		previous, ok := s.previous[pageURL.String()]
+		unchanged := ok && page.lastMod != "" && previous.LastModified == page.lastMod
+		if unchanged {
+			visited[previous.Path] = struct{}{}
+			s.state.Sitemap[pageURL.String()] = previous
+			s.add(datasource.Item{
+				ID:      pageURL.String(),
+				Path:    previous.Path,
+				URL:     pageURL.String(),
+				Version: previous.LastModified,
+			}, nil)
+		}
+		stateLock.Unlock()
+		if unchanged {
+			logOut.Infof("skipping %s because it has not changed since %s", pageURL.String(), page.lastMod)
+			continue
+		}
+
+		requestCtx := colly.NewContext()
+		requestCtx.Put("lastmod", page.lastMod)
+		if err := collector.Request(http.MethodGet, pageURL.String(), nil, requestCtx, nil); errors.Is(err, colly.ErrRobotsTxtBlocked) {
+			logOut.Infof("skipping %s because it is disallowed by robots.txt", pageURL.String())
+		} else if err != nil {
+			logOut.Infof("Failed to scrape %s: %v", pageURL.String(), err)
+		}
+	}
+	collector.Wait()
+	return len(pages)
+}
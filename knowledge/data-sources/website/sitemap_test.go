@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	url2 "net/url"
+	"reflect"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestReadSitemaps(t *testing.T) {
+	var gzipped bytes.Buffer
+	gz := gzip.NewWriter(&gzipped)
+	_, _ = gz.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://example.com/docs/b</loc></url>
+</urlset>`))
+	_ = gz.Close()
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			_, _ = io.WriteString(w, "User-agent: *\nDisallow: /private\nSitemap: "+server.URL+"/sitemap_index.xml\n")
+		case "/sitemap_index.xml":
+			_, _ = io.WriteString(w, `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>`+server.URL+`/pages.xml</loc></sitemap>
+  <sitemap><loc>`+server.URL+`/more.xml.gz</loc></sitemap>
+  <sitemap><loc>`+server.URL+`/sitemap_index.xml</loc></sitemap>
+</sitemapindex>`)
+		case "/pages.xml":
+			_, _ = io.WriteString(w, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url>
+    <loc> https://example.com/docs/a </loc>
+    <lastmod>2024-11-01</lastmod>
+  </url>
+</urlset>`)
+		case "/more.xml.gz":
+			_, _ = w.Write(gzipped.Bytes())
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	base, _ := url2.Parse(server.URL + "/docs")
+	sitemaps := discoverSitemaps(context.Background(), base)
+	if !reflect.DeepEqual(sitemaps, []string{server.URL + "/sitemap_index.xml"}) {
+		t.Fatalf("unexpected sitemaps: %v", sitemaps)
+	}
+
+	pages := readSitemaps(context.Background(), logrus.New(), sitemaps)
+	expected := []sitemapEntry{
+		{Loc: "https://example.com/docs/a", LastMod: "2024-11-01"},
+		{Loc: "https://example.com/docs/b"},
+	}
+	if !reflect.DeepEqual(pages, expected) {
+		t.Errorf("Expected: %v, Got: %v", expected, pages)
+	}
+}
+
+func TestDiscoverSitemapsFallback(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	base, _ := url2.Parse(server.URL + "/docs/")
+	sitemaps := discoverSitemaps(context.Background(), base)
+	if !reflect.DeepEqual(sitemaps, []string{server.URL + "/sitemap.xml"}) {
+		t.Errorf("unexpected sitemaps: %v", sitemaps)
+	}
+}
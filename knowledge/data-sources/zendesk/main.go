@@ -0,0 +1,533 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/knowledge/pkg/datasource"
+	"github.com/gptscript-ai/knowledge/pkg/datasource/indexstore"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// incrementalOverlap is subtracted from the start time of the incremental article export, so articles updated
+	// during the last sync are not missed
+	incrementalOverlap = 5 * time.Minute
+	// defaultTicketMaxAgeDays is how far back resolved tickets are synced by default
+	defaultTicketMaxAgeDays = 365
+)
+
+var (
+	nonNameChars = regexp.MustCompile(`[^a-z0-9]+`)
+	// defaultTicketStatuses are the statuses of resolved tickets
+	defaultTicketStatuses = []string{"solved", "closed"}
+)
+
+type MetadataInput struct {
+	ZendeskConfig *ZendeskConfig `json:"zendeskConfig,omitempty"`
+}
+
+type ZendeskConfig struct {
+	// Subdomain is the subdomain of the Zendesk account, e.g. example for example.zendesk.com
+	Subdomain string `json:"subdomain"`
+	// Brands are the names, subdomains or IDs of the brands whose help centers and tickets are synced. Defaults to the
+	// help center of the account and the tickets of all brands.
+	Brands []string `json:"brands,omitempty"`
+	// Locales are the locales of the articles to sync, e.g. en-us. Defaults to the default locale of each help center.
+	Locales []string `json:"locales,omitempty"`
+	// IncludeTickets also syncs resolved tickets with their public comments
+	IncludeTickets bool `json:"includeTickets,omitempty"`
+	// TicketStatuses are the statuses of the tickets to sync, defaults to solved and closed
+	TicketStatuses []string `json:"ticketStatuses,omitempty"`
+	// TicketMaxAgeDays is how many days back tickets are synced, based on their last update. Defaults to 365.
+	TicketMaxAgeDays int `json:"ticketMaxAgeDays,omitempty"`
+}
+
+// HelpCenter is the help center of a brand with the locales to sync
+type HelpCenter struct {
+	BrandID   int64    `json:"brandID,omitempty"`
+	Brand     string   `json:"brand,omitempty"`
+	Subdomain string   `json:"subdomain"`
+	Locales   []string `json:"locales"`
+}
+
+// TicketFilter describes the tickets to sync
+type TicketFilter struct {
+	Statuses []string `json:"statuses"`
+	// Brands are the names of the brands by ID, the tickets of all brands are synced if empty
+	Brands     map[int64]string `json:"brands,omitempty"`
+	MaxAgeDays int              `json:"maxAgeDays"`
+}
+
+// zendeskConfig is the resolved configuration, all articles and tickets are listed again if it changes
+type zendeskConfig struct {
+	HelpCenters []HelpCenter `json:"helpCenters"`
+	// Tickets is nil if tickets are not synced
+	Tickets *TicketFilter `json:"tickets,omitempty"`
+}
+
+// zendeskCursor is the position of the incremental exports
+type zendeskCursor struct {
+	// Articles are the Unix times to continue the incremental article export from, by brand subdomain
+	Articles map[string]int64 `json:"articles"`
+	// TicketTime is the Unix time to start the incremental ticket export from, until it returned a cursor
+	TicketTime int64 `json:"ticketTime,omitempty"`
+	// Tickets is the cursor to continue the incremental ticket export from
+	Tickets string `json:"tickets,omitempty"`
+}
+
+// zendeskSource lists the articles of the help centers and the resolved tickets. Changes are listed with the
+// incremental article and ticket exports. The incremental article export only returns the articles that still exist,
+// so deleted and archived articles are only removed by the daily full sync, like tickets that are too old.
+type zendeskSource struct {
+	zendesk *zendeskClient
+	logErr  *logrus.Logger
+	config  zendeskConfig
+	// articles and tickets are those of the current page, their content is part of the listing
+	articles map[string]pageArticle
+	tickets  map[string]Ticket
+}
+
+type pageArticle struct {
+	helpCenter HelpCenter
+	article    Article
+}
+
+func main() {
+	logOut := logrus.New()
+	logOut.SetOutput(os.Stdout)
+	logOut.SetFormatter(&logrus.JSONFormatter{})
+	logErr := logrus.New()
+	logErr.SetOutput(os.Stderr)
+
+	ctx := context.Background()
+	gptscriptClient, err := gptscript.NewGPTScript()
+	if err != nil {
+		logOut.WithError(fmt.Errorf("failed to create gptscript client, error: %w", err)).Error()
+		os.Exit(0)
+	}
+
+	inputData := os.Getenv("GPTSCRIPT_INPUT")
+	input := MetadataInput{}
+
+	if err := json.Unmarshal([]byte(inputData), &input); err != nil {
+		logOut.WithError(fmt.Errorf("failed to unmarshal input data, error: %w", err)).Error()
+		os.Exit(0)
+	}
+	if input.ZendeskConfig == nil {
+		input.ZendeskConfig = &ZendeskConfig{}
+	}
+
+	subdomain := strings.TrimSpace(input.ZendeskConfig.Subdomain)
+	subdomain = strings.TrimPrefix(strings.TrimPrefix(subdomain, "https://"), "http://")
+	subdomain, _, _ = strings.Cut(subdomain, ".")
+
+	source := &zendeskSource{
+		zendesk: &zendeskClient{token: os.Getenv("ZENDESK_OAUTH_TOKEN"), subdomain: subdomain},
+		logErr:  logErr,
+	}
+	if err := source.resolveConfig(ctx, input.ZendeskConfig); err != nil {
+		logOut.WithError(fmt.Errorf("failed to sync zendesk, error: %w", err)).Error()
+		os.Exit(0)
+	}
+	config, err := json.Marshal(source.config)
+	if err != nil {
+		logOut.WithError(fmt.Errorf("failed to marshal config, error: %w", err)).Error()
+		os.Exit(0)
+	}
+
+	workspace := &datasource.GPTScriptWorkspace{Client: gptscriptClient}
+	store, closeStore, err := indexstore.FromEnv(ctx, workspace)
+	if err != nil {
+		logOut.WithError(fmt.Errorf("failed to open sync state store, error: %w", err)).Error()
+		os.Exit(0)
+	}
+	defer closeStore()
+
+	syncer := datasource.NewSyncer(source, store, workspace, datasource.Options{
+		Config: string(config),
+	})
+	if _, err := syncer.Sync(ctx); err != nil {
+		logOut.WithError(fmt.Errorf("failed to sync zendesk, error: %w", err)).Error()
+		os.Exit(0)
+	}
+}
+
+func (s *zendeskSource) resolveConfig(ctx context.Context, config *ZendeskConfig) error {
+	if s.zendesk.subdomain == "" {
+		return errors.New("no Zendesk subdomain configured")
+	}
+
+	helpCenters, ticketBrands, err := s.resolveBrands(ctx, config)
+	if err != nil {
+		return err
+	}
+	s.config = zendeskConfig{HelpCenters: helpCenters}
+
+	if config.IncludeTickets {
+		filter := &TicketFilter{
+			Statuses:   config.TicketStatuses,
+			Brands:     ticketBrands,
+			MaxAgeDays: config.TicketMaxAgeDays,
+		}
+		if len(filter.Statuses) == 0 {
+			filter.Statuses = defaultTicketStatuses
+		}
+		if filter.MaxAgeDays <= 0 {
+			filter.MaxAgeDays = defaultTicketMaxAgeDays
+		}
+		s.config.Tickets = filter
+	}
+	return nil
+}
+
+// resolveBrands returns the help centers to sync and the IDs of the brands of the tickets to sync. Without configured
+// brands only the help center of the account is synced, and the tickets of all brands.
+func (s *zendeskSource) resolveBrands(ctx context.Context, config *ZendeskConfig) ([]HelpCenter, map[int64]string, error) {
+	var locales []string
+	for _, locale := range config.Locales {
+		if locale = strings.ToLower(strings.TrimSpace(locale)); locale != "" && !slices.Contains(locales, locale) {
+			locales = append(locales, locale)
+		}
+	}
+
+	helpCenters := []HelpCenter{{Subdomain: s.zendesk.subdomain}}
+	var ticketBrands map[int64]string
+	if len(config.Brands) > 0 {
+		brands, err := s.zendesk.ListBrands(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list brands: %w", err)
+		}
+
+		helpCenters, ticketBrands = nil, map[int64]string{}
+		for _, name := range config.Brands {
+			name = strings.TrimSpace(name)
+			i := slices.IndexFunc(brands, func(b Brand) bool {
+				return strings.EqualFold(b.Name, name) || strings.EqualFold(b.Subdomain, name) || strconv.FormatInt(b.ID, 10) == name
+			})
+			if i < 0 {
+				return nil, nil, fmt.Errorf("unknown brand %q", name)
+			}
+
+			brand := brands[i]
+			ticketBrands[brand.ID] = brand.Name
+			if !brand.HasHelpCenter {
+				s.logErr.Infof("Brand %s has no help center, only its tickets are synced", brand.Name)
+				continue
+			}
+			helpCenters = append(helpCenters, HelpCenter{BrandID: brand.ID, Brand: brand.Name, Subdomain: brand.Subdomain})
+		}
+	}
+
+	for i := range helpCenters {
+		helpCenters[i].Locales = locales
+		if len(locales) == 0 {
+			locale, err := s.zendesk.DefaultLocale(ctx, helpCenters[i].Subdomain)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to get the default locale of %s: %w", helpCenters[i].Subdomain, err)
+			}
+			helpCenters[i].Locales = []string{strings.ToLower(locale)}
+		}
+	}
+	return helpCenters, ticketBrands, nil
+}
+
+func (s *zendeskSource) Name() string {
+	return "zendesk"
+}
+
+// List lists the articles of each help center and locale, and the tickets updated within the maximum age
+func (s *zendeskSource) List(ctx context.Context, fn func([]datasource.Item) error) error {
+	for _, helpCenter := range s.config.HelpCenters {
+		for _, locale := range helpCenter.Locales {
+			if err := s.zendesk.ListArticles(ctx, helpCenter.Subdomain, locale, func(articles []Article) error {
+				s.articles = make(map[string]pageArticle, len(articles))
+				items := make([]datasource.Item, 0, len(articles))
+				for _, article := range articles {
+					// Draft articles are removed
+					if !article.Draft {
+						items = append(items, s.articleItem(helpCenter, article))
+					}
+				}
+				return fn(items)
+			}); err != nil {
+				return fmt.Errorf("failed to list articles of %s in %s: %w", helpCenter.Subdomain, locale, err)
+			}
+		}
+	}
+	s.articles = nil
+
+	if s.config.Tickets == nil {
+		return nil
+	}
+	if err := s.zendesk.ExportTickets(ctx, "", s.ticketCutoff(), func(tickets []Ticket, _ string) error {
+		s.tickets = make(map[string]Ticket, len(tickets))
+		items := make([]datasource.Item, 0, len(tickets))
+		for _, ticket := range tickets {
+			if s.matches(ticket) {
+				items = append(items, s.ticketItem(ticket))
+			}
+		}
+		return fn(items)
+	}); err != nil {
+		return fmt.Errorf("failed to export tickets: %w", err)
+	}
+	s.tickets = nil
+	return nil
+}
+
+// Cursor returns the current time as the start of the incremental exports
+func (s *zendeskSource) Cursor(context.Context) (string, error) {
+	startTime := time.Now().Add(-incrementalOverlap).Unix()
+	cursor := zendeskCursor{Articles: map[string]int64{}, TicketTime: startTime}
+	for _, helpCenter := range s.config.HelpCenters {
+		cursor.Articles[helpCenter.Subdomain] = startTime
+	}
+	data, err := json.Marshal(cursor)
+	return string(data), err
+}
+
+// Changes lists the articles and tickets that changed since the cursor. Articles that became drafts and tickets that
+// no longer match the filter are deleted.
+func (s *zendeskSource) Changes(ctx context.Context, cursor string, fn func([]datasource.Change, string) error) error {
+	var c zendeskCursor
+	if err := json.Unmarshal([]byte(cursor), &c); err != nil {
+		return fmt.Errorf("invalid cursor: %w", datasource.ErrFullSyncRequired)
+	}
+	encode := func() (string, error) {
+		data, err := json.Marshal(c)
+		return string(data), err
+	}
+
+	for _, helpCenter := range s.config.HelpCenters {
+		startTime, ok := c.Articles[helpCenter.Subdomain]
+		if !ok {
+			return fmt.Errorf("no cursor for %s: %w", helpCenter.Subdomain, datasource.ErrFullSyncRequired)
+		}
+
+		next, err := s.zendesk.ArticlesChangedSince(ctx, helpCenter.Subdomain, startTime, func(articles []Article) error {
+			s.articles = make(map[string]pageArticle, len(articles))
+			var changes []datasource.Change
+			for _, article := range articles {
+				if !slices.Contains(helpCenter.Locales, strings.ToLower(article.Locale)) {
+					continue
+				}
+				changes = append(changes, datasource.Change{Item: s.articleItem(helpCenter, article), Deleted: article.Draft})
+			}
+			// The time to continue from is only known at the end of the export
+			current, err := encode()
+			if err != nil {
+				return err
+			}
+			return fn(changes, current)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to export changed articles of %s: %w", helpCenter.Subdomain, err)
+		}
+		s.articles = nil
+
+		c.Articles[helpCenter.Subdomain] = next
+		current, err := encode()
+		if err != nil {
+			return err
+		}
+		if err := fn(nil, current); err != nil {
+			return err
+		}
+	}
+
+	if s.config.Tickets == nil {
+		return nil
+	}
+	if err := s.zendesk.ExportTickets(ctx, c.Tickets, time.Unix(c.TicketTime, 0), func(tickets []Ticket, next string) error {
+		s.tickets = make(map[string]Ticket, len(tickets))
+		changes := make([]datasource.Change, 0, len(tickets))
+		for _, ticket := range tickets {
+			changes = append(changes, datasource.Change{Item: s.ticketItem(ticket), Deleted: !s.matches(ticket)})
+		}
+		c.Tickets = next
+		current, err := encode()
+		if err != nil {
+			return err
+		}
+		return fn(changes, current)
+	}); err != nil {
+		return fmt.Errorf("failed to export tickets: %w", err)
+	}
+	s.tickets = nil
+	return nil
+}
+
+// Fetch renders the article or ticket of the current page
+func (s *zendeskSource) Fetch(ctx context.Context, item datasource.Item) ([]byte, error) {
+	if page, ok := s.articles[item.ID]; ok {
+		return []byte(renderArticle(page.helpCenter, page.article)), nil
+	}
+
+	ticket, ok := s.tickets[item.ID]
+	if !ok {
+		return nil, datasource.Permanent(fmt.Errorf("%s is not part of the current page", item.ID))
+	}
+	comments, authors, err := s.zendesk.ListComments(ctx, ticket.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list comments of ticket %d: %w", ticket.ID, err)
+	}
+	return []byte(renderTicket(ticket, item.Metadata["brand"], comments, authors, item.URL)), nil
+}
+
+// articleItem returns the item of the article, written to <brand>/<locale>/<article id>-<title>.html
+func (s *zendeskSource) articleItem(helpCenter HelpCenter, article Article) datasource.Item {
+	locale := strings.ToLower(article.Locale)
+	fileName := strconv.FormatInt(article.ID, 10)
+	if name := slug(article.Title); name != "" {
+		fileName += "-" + name
+	}
+
+	item := datasource.Item{
+		ID:       articleID(article),
+		Path:     path.Join(helpCenter.Subdomain, locale, fileName+".html"),
+		URL:      article.HTMLURL,
+		Version:  article.UpdatedAt,
+		Metadata: map[string]string{"type": "article", "locale": locale},
+	}
+	if helpCenter.Brand != "" {
+		item.Metadata["brand"] = helpCenter.Brand
+	}
+	s.articles[item.ID] = pageArticle{helpCenter: helpCenter, article: article}
+	return item
+}
+
+// ticketItem returns the item of the ticket, written to tickets/<ticket id>.md
+func (s *zendeskSource) ticketItem(ticket Ticket) datasource.Item {
+	item := datasource.Item{
+		ID:       ticketID(ticket.ID),
+		Path:     path.Join("tickets", strconv.FormatInt(ticket.ID, 10)+".md"),
+		URL:      fmt.Sprintf("https://%s.zendesk.com/agent/tickets/%d", s.zendesk.subdomain, ticket.ID),
+		Version:  ticket.UpdatedAt,
+		Metadata: map[string]string{"type": "ticket", "status": ticket.Status},
+	}
+	if brand := s.config.Tickets.Brands[ticket.BrandID]; brand != "" {
+		item.Metadata["brand"] = brand
+	}
+	s.tickets[item.ID] = ticket
+	return item
+}
+
+// matches returns whether the ticket has one of the statuses, belongs to one of the brands and was updated within the
+// maximum age
+func (s *zendeskSource) matches(ticket Ticket) bool {
+	filter := s.config.Tickets
+	updatedAt, _ := time.Parse(time.RFC3339, ticket.UpdatedAt)
+	_, brandOK := filter.Brands[ticket.BrandID]
+	return slices.Contains(filter.Statuses, ticket.Status) && (len(filter.Brands) == 0 || brandOK) &&
+		!updatedAt.Before(s.ticketCutoff())
+}
+
+func (s *zendeskSource) ticketCutoff() time.Time {
+	return time.Now().UTC().AddDate(0, 0, -s.config.Tickets.MaxAgeDays)
+}
+
+// articleID is the ID of the file of an article, translations of an article share the article ID
+func articleID(article Article) string {
+	return fmt.Sprintf("article-%d-%s", article.ID, strings.ToLower(article.Locale))
+}
+
+func ticketID(id int64) string {
+	return fmt.Sprintf("ticket-%d", id)
+}
+
+// renderArticle returns an HTML document with the article. The details of the article are part of the document, so
+// they are also found by retrieval.
+func renderArticle(helpCenter HelpCenter, article Article) string {
+	var b strings.Builder
+	title := html.EscapeString(article.Title)
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n</head>\n<body>\n<h1>%s</h1>\n<p>\n", title, title)
+	if helpCenter.Brand != "" {
+		fmt.Fprintf(&b, "Brand: %s<br>\n", html.EscapeString(helpCenter.Brand))
+	}
+	fmt.Fprintf(&b, "Locale: %s<br>\n", html.EscapeString(article.Locale))
+	if len(article.LabelNames) > 0 {
+		fmt.Fprintf(&b, "Labels: %s<br>\n", html.EscapeString(strings.Join(article.LabelNames, ", ")))
+	}
+	if article.Outdated {
+		b.WriteString("This translation is outdated.<br>\n")
+	}
+	fmt.Fprintf(&b, "Updated: %s<br>\n", html.EscapeString(article.UpdatedAt))
+	if article.HTMLURL != "" {
+		link := html.EscapeString(article.HTMLURL)
+		fmt.Fprintf(&b, "Link: <a href=\"%s\">%s</a>\n", link, link)
+	}
+	b.WriteString("</p>\n")
+	// The body of articles is HTML
+	b.WriteString(article.Body)
+	b.WriteString("\n</body>\n</html>\n")
+	return b.String()
+}
+
+// renderTicket returns the ticket as Markdown. The fields are added as front matter, so they are ingested together
+// with the conversation.
+func renderTicket(ticket Ticket, brand string, comments []TicketComment, authors map[int64]string, url string) string {
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	writeField := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&sb, "%s: %q\n", name, value)
+		}
+	}
+
+	writeField("id", strconv.FormatInt(ticket.ID, 10))
+	writeField("subject", ticket.Subject)
+	writeField("brand", brand)
+	writeField("status", ticket.Status)
+	writeField("priority", ticket.Priority)
+	writeField("type", ticket.Type)
+	if len(ticket.Tags) > 0 {
+		quoted := make([]string, 0, len(ticket.Tags))
+		for _, tag := range ticket.Tags {
+			quoted = append(quoted, fmt.Sprintf("%q", tag))
+		}
+		fmt.Fprintf(&sb, "tags: [%s]\n", strings.Join(quoted, ", "))
+	}
+	writeField("created", ticket.CreatedAt)
+	writeField("updated", ticket.UpdatedAt)
+	writeField("url", url)
+	sb.WriteString("---\n\n")
+
+	fmt.Fprintf(&sb, "# #%d: %s\n\n", ticket.ID, ticket.Subject)
+	// The description is the first comment, so it is only needed if there are no public comments
+	if len(comments) == 0 {
+		if description := strings.TrimSpace(ticket.Description); description != "" {
+			sb.WriteString("## Description\n\n" + description + "\n\n")
+		}
+	} else {
+		sb.WriteString("## Conversation\n\n")
+		for _, comment := range comments {
+			author := authors[comment.AuthorID]
+			if author == "" {
+				author = strconv.FormatInt(comment.AuthorID, 10)
+			}
+			fmt.Fprintf(&sb, "### %s (%s)\n\n%s\n\n", author, comment.CreatedAt, strings.TrimSpace(comment.PlainBody))
+		}
+	}
+	return strings.TrimSpace(sb.String()) + "\n"
+}
+
+func slug(s string) string {
+	s = strings.Trim(nonNameChars.ReplaceAllString(strings.ToLower(s), "-"), "-")
+	if runes := []rune(s); len(runes) > 80 {
+		s = strings.TrimRight(string(runes[:80]), "-")
+	}
+	return s
+}
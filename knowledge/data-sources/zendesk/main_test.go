@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlug(t *testing.T) {
+	for s, expected := range map[string]string{
+		"How do I reset my password?": "how-do-i-reset-my-password",
+		"  Ünïcode & more  ":          "n-code-more",
+		"???":                         "",
+		strings.Repeat("ab ", 40):     strings.TrimSuffix(strings.Repeat("ab-", 27), "-"),
+	} {
+		if name := slug(s); name != expected {
+			t.Errorf("slug(%q): expected %q, got %q", s, expected, name)
+		}
+	}
+}
+
+func TestRenderArticle(t *testing.T) {
+	article := Article{
+		ID:         42,
+		HTMLURL:    "https://help.example.com/hc/en-us/articles/42",
+		Title:      "Reset <your> password",
+		Body:       "<p>Click <b>Forgot password</b>.</p>",
+		Locale:     "en-us",
+		LabelNames: []string{"account", "login"},
+		Outdated:   true,
+		UpdatedAt:  "2024-11-01T10:00:00Z",
+	}
+	rendered := renderArticle(HelpCenter{Brand: "Acme", Subdomain: "acme"}, article)
+	for _, expected := range []string{
+		"<title>Reset &lt;your&gt; password</title>",
+		"<h1>Reset &lt;your&gt; password</h1>",
+		"Brand: Acme<br>",
+		"Labels: account, login<br>",
+		"This translation is outdated.",
+		`Link: <a href="https://help.example.com/hc/en-us/articles/42">`,
+		"<p>Click <b>Forgot password</b>.</p>",
+	} {
+		if !strings.Contains(rendered, expected) {
+			t.Errorf("expected %q in the article:\n%s", expected, rendered)
+		}
+	}
+}
+
+func TestRenderTicket(t *testing.T) {
+	ticket := Ticket{
+		ID:          7,
+		Subject:     "Cannot log in",
+		Description: "I can't log in since yesterday",
+		Status:      "solved",
+		Tags:        []string{"login"},
+		CreatedAt:   "2024-11-01T10:00:00Z",
+		UpdatedAt:   "2024-11-02T10:00:00Z",
+	}
+	comments := []TicketComment{
+		{AuthorID: 1, PlainBody: "I can't log in since yesterday", CreatedAt: "2024-11-01T10:00:00Z"},
+		{AuthorID: 2, PlainBody: " Please reset your password. ", CreatedAt: "2024-11-01T11:00:00Z"},
+	}
+
+	expected := `---
+id: "7"
+subject: "Cannot log in"
+brand: "Acme"
+status: "solved"
+tags: ["login"]
+created: "2024-11-01T10:00:00Z"
+updated: "2024-11-02T10:00:00Z"
+url: "https://acme.zendesk.com/agent/tickets/7"
+---
+
+# #7: Cannot log in
+
+## Conversation
+
+### Jane (2024-11-01T10:00:00Z)
+
+I can't log in since yesterday
+
+### 2 (2024-11-01T11:00:00Z)
+
+Please reset your password.
+`
+	if rendered := renderTicket(ticket, "Acme", comments, map[int64]string{1: "Jane"}, "https://acme.zendesk.com/agent/tickets/7"); rendered != expected {
+		t.Errorf("unexpected ticket:\n%s", rendered)
+	}
+
+	// Without public comments the description is used
+	if rendered := renderTicket(ticket, "", nil, nil, ""); !strings.HasSuffix(rendered, "## Description\n\nI can't log in since yesterday\n") {
+		t.Errorf("expected the description, got:\n%s", rendered)
+	}
+}
+
+func TestSourceItems(t *testing.T) {
+	s := &zendeskSource{
+		zendesk: &zendeskClient{subdomain: "acme"},
+		config: zendeskConfig{Tickets: &TicketFilter{
+			Statuses:   []string{"solved", "closed"},
+			Brands:     map[int64]string{1: "Acme"},
+			MaxAgeDays: 30,
+		}},
+		articles: map[string]pageArticle{},
+		tickets:  map[string]Ticket{},
+	}
+
+	article := s.articleItem(HelpCenter{Brand: "Acme", Subdomain: "acme"}, Article{ID: 42, Title: "Reset password", Locale: "en-US", UpdatedAt: "v1"})
+	if article.ID != "article-42-en-us" || article.Path != "acme/en-us/42-reset-password.html" || article.Metadata["brand"] != "Acme" {
+		t.Errorf("unexpected article item %+v", article)
+	}
+
+	ticket := s.ticketItem(Ticket{ID: 7, BrandID: 1, Status: "solved"})
+	if ticket.ID != "ticket-7" || ticket.Path != "tickets/7.md" || ticket.URL != "https://acme.zendesk.com/agent/tickets/7" || ticket.Metadata["brand"] != "Acme" {
+		t.Errorf("unexpected ticket item %+v", ticket)
+	}
+	if _, ok := s.tickets["ticket-7"]; !ok {
+		t.Error("expected the ticket to be part of the current page")
+	}
+
+	recent := time.Now().Add(-24 * time.Hour).UTC().Format(time.RFC3339)
+	for _, tc := range []struct {
+		ticket  Ticket
+		matches bool
+	}{
+		{Ticket{Status: "solved", BrandID: 1, UpdatedAt: recent}, true},
+		{Ticket{Status: "open", BrandID: 1, UpdatedAt: recent}, false},
+		{Ticket{Status: "closed", BrandID: 2, UpdatedAt: recent}, false},
+		{Ticket{Status: "closed", BrandID: 1, UpdatedAt: "2019-01-01T00:00:00Z"}, false},
+	} {
+		if matches := s.matches(tc.ticket); matches != tc.matches {
+			t.Errorf("matches(%+v): expected %v", tc.ticket, tc.matches)
+		}
+	}
+}
+
+func TestGetRetriesRateLimited(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer token" || r.URL.Query().Get("page[size]") != "100" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = io.WriteString(w, `{"brands": [{"id": 1, "name": "Acme"}]}`)
+	}))
+	defer server.Close()
+
+	c := &zendeskClient{token: "token"}
+	var resp struct {
+		Brands []Brand `json:"brands"`
+	}
+	if err := c.get(context.Background(), server.URL, map[string][]string{"page[size]": {"100"}}, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 || len(resp.Brands) != 1 || resp.Brands[0].Name != "Acme" {
+		t.Errorf("unexpected response after %d requests: %+v", requests, resp)
+	}
+}
+
+func TestNextPage(t *testing.T) {
+	if next, _ := nextPage(meta{HasMore: true}, links{Next: "https://acme.zendesk.com/api/v2/brands?page[after]=x"}); next == "" {
+		t.Error("expected the next page")
+	}
+	if next, _ := nextPage(meta{}, links{Next: "https://acme.zendesk.com/api/v2/brands?page[after]=x"}); next != "" {
+		t.Error("expected no next page without more results")
+	}
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	pageLimit = 100
+	// exportPageLimit is the page size of the incremental ticket export
+	exportPageLimit = 1000
+	// maxRetries is how often a rate limited request is retried
+	maxRetries = 3
+)
+
+type Brand struct {
+	ID            int64  `json:"id"`
+	Name          string `json:"name"`
+	Subdomain     string `json:"subdomain"`
+	HasHelpCenter bool   `json:"has_help_center"`
+}
+
+type Article struct {
+	ID         int64    `json:"id"`
+	HTMLURL    string   `json:"html_url"`
+	Title      string   `json:"title"`
+	Body       string   `json:"body"`
+	Locale     string   `json:"locale"`
+	SectionID  int64    `json:"section_id"`
+	Draft      bool     `json:"draft"`
+	Outdated   bool     `json:"outdated"`
+	LabelNames []string `json:"label_names"`
+	CreatedAt  string   `json:"created_at"`
+	UpdatedAt  string   `json:"updated_at"`
+}
+
+type Ticket struct {
+	ID          int64    `json:"id"`
+	Subject     string   `json:"subject"`
+	Description string   `json:"description"`
+	Status      string   `json:"status"`
+	Priority    string   `json:"priority"`
+	Type        string   `json:"type"`
+	Tags        []string `json:"tags"`
+	BrandID     int64    `json:"brand_id"`
+	CreatedAt   string   `json:"created_at"`
+	UpdatedAt   string   `json:"updated_at"`
+}
+
+type TicketComment struct {
+	ID        int64  `json:"id"`
+	AuthorID  int64  `json:"author_id"`
+	PlainBody string `json:"plain_body"`
+	Public    bool   `json:"public"`
+	CreatedAt string `json:"created_at"`
+}
+
+type zendeskClient struct {
+	token string
+	// subdomain is the subdomain of the Zendesk account, e.g. example for example.zendesk.com
+	subdomain string
+}
+
+// baseURL returns the API of the given (brand) subdomain
+func baseURL(subdomain string) string {
+	return "https://" + subdomain + ".zendesk.com/api/v2"
+}
+
+// get fetches the URL and decodes the JSON response into v. Rate limited requests are retried after the time the API
+// asks for.
+func (c *zendeskClient) get(ctx context.Context, rawURL string, query url.Values, v any) error {
+	if len(query) > 0 {
+		rawURL += "?" + query.Encode()
+	}
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+c.token)
+		req.Header.Set("Accept", "application/json")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < maxRetries {
+			resp.Body.Close()
+			wait, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(min(max(wait, 1), 60)) * time.Second):
+			}
+			continue
+		}
+
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			return fmt.Errorf("zendesk request %s failed with status %d: %s", strings.TrimPrefix(rawURL, "https://"), resp.StatusCode, string(body))
+		}
+		return json.NewDecoder(resp.Body).Decode(v)
+	}
+}
+
+// ListBrands returns all brands of the account
+func (c *zendeskClient) ListBrands(ctx context.Context) ([]Brand, error) {
+	var brands []Brand
+	query := url.Values{"page[size]": {strconv.Itoa(pageLimit)}}
+	next := baseURL(c.subdomain) + "/brands"
+	for next != "" {
+		var resp struct {
+			Brands []Brand `json:"brands"`
+			Meta   meta    `json:"meta"`
+			Links  links   `json:"links"`
+		}
+		if err := c.get(ctx, next, query, &resp); err != nil {
+			return nil, err
+		}
+		brands = append(brands, resp.Brands...)
+		next, query = nextPage(resp.Meta, resp.Links)
+	}
+	return brands, nil
+}
+
+// DefaultLocale returns the default locale of the help center of the brand
+func (c *zendeskClient) DefaultLocale(ctx context.Context, subdomain string) (string, error) {
+	var resp struct {
+		DefaultLocale string `json:"default_locale"`
+	}
+	return resp.DefaultLocale, c.get(ctx, baseURL(subdomain)+"/help_center/locales", nil, &resp)
+}
+
+// ListArticles calls fn with all articles of the help center of the brand in the locale, page by page
+func (c *zendeskClient) ListArticles(ctx context.Context, subdomain, locale string, fn func([]Article) error) error {
+	query := url.Values{"page[size]": {strconv.Itoa(pageLimit)}}
+	next := baseURL(subdomain) + "/help_center/" + url.PathEscape(locale) + "/articles"
+	for next != "" {
+		var resp struct {
+			Articles []Article `json:"articles"`
+			Meta     meta      `json:"meta"`
+			Links    links     `json:"links"`
+		}
+		if err := c.get(ctx, next, query, &resp); err != nil {
+			return err
+		}
+		if err := fn(resp.Articles); err != nil {
+			return err
+		}
+		next, query = nextPage(resp.Meta, resp.Links)
+	}
+	return nil
+}
+
+// ArticlesChangedSince calls fn with the articles of the help center of the brand that changed since the Unix time,
+// in all locales, page by page. It returns the time to continue the export from in the next sync.
+func (c *zendeskClient) ArticlesChangedSince(ctx context.Context, subdomain string, startTime int64, fn func([]Article) error) (int64, error) {
+	next := baseURL(subdomain) + "/help_center/incremental/articles"
+	query := url.Values{"start_time": {strconv.FormatInt(startTime, 10)}}
+	for {
+		var resp struct {
+			Articles []Article `json:"articles"`
+			NextPage string    `json:"next_page"`
+			EndTime  int64     `json:"end_time"`
+		}
+		if err := c.get(ctx, next, query, &resp); err != nil {
+			return startTime, err
+		}
+		if err := fn(resp.Articles); err != nil {
+			return startTime, err
+		}
+		// The export is done if there is no next page, or the next page doesn't start later
+		if resp.NextPage == "" || len(resp.Articles) == 0 || resp.EndTime <= startTime {
+			return max(startTime, resp.EndTime), nil
+		}
+		startTime = resp.EndTime
+		next, query = resp.NextPage, nil
+	}
+}
+
+// ExportTickets calls fn with the tickets that changed since the cursor, page by page. If the cursor is empty, the
+// export starts at startTime. fn is called with the cursor to continue the export from after each page, so the
+// progress can be saved.
+func (c *zendeskClient) ExportTickets(ctx context.Context, cursor string, startTime time.Time, fn func(tickets []Ticket, cursor string) error) error {
+	query := url.Values{"per_page": {strconv.Itoa(exportPageLimit)}}
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	} else {
+		query.Set("start_time", strconv.FormatInt(startTime.Unix(), 10))
+	}
+	for {
+		var resp struct {
+			Tickets     []Ticket `json:"tickets"`
+			AfterCursor string   `json:"after_cursor"`
+			EndOfStream bool     `json:"end_of_stream"`
+		}
+		if err := c.get(ctx, baseURL(c.subdomain)+"/incremental/tickets/cursor.json", query, &resp); err != nil {
+			return err
+		}
+		if resp.AfterCursor != "" {
+			cursor = resp.AfterCursor
+		}
+		if err := fn(resp.Tickets, cursor); err != nil {
+			return err
+		}
+		if resp.EndOfStream || resp.AfterCursor == "" {
+			return nil
+		}
+		query.Del("start_time")
+		query.Set("cursor", cursor)
+	}
+}
+
+// ListComments returns the public comments of the ticket, oldest first, and the names of their authors by user ID
+func (c *zendeskClient) ListComments(ctx context.Context, ticketID int64) ([]TicketComment, map[int64]string, error) {
+	var comments []TicketComment
+	authors := map[int64]string{}
+
+	query := url.Values{"page[size]": {strconv.Itoa(pageLimit)}, "include": {"users"}}
+	next := fmt.Sprintf("%s/tickets/%d/comments", baseURL(c.subdomain), ticketID)
+	for next != "" {
+		var resp struct {
+			Comments []TicketComment `json:"comments"`
+			Users    []struct {
+				ID   int64  `json:"id"`
+				Name string `json:"name"`
+			} `json:"users"`
+			Meta  meta  `json:"meta"`
+			Links links `json:"links"`
+		}
+		if err := c.get(ctx, next, query, &resp); err != nil {
+			return nil, nil, err
+		}
+		for _, comment := range resp.Comments {
+			if comment.Public {
+				comments = append(comments, comment)
+			}
+		}
+		for _, user := range resp.Users {
+			authors[user.ID] = user.Name
+		}
+		next, query = nextPage(resp.Meta, resp.Links)
+	}
+	return comments, authors, nil
+}
+
+// meta and links are part of the responses of endpoints with cursor pagination
+type meta struct {
+	HasMore bool `json:"has_more"`
+}
+
+type links struct {
+	Next string `json:"next"`
+}
+
+// nextPage returns the URL of the next page, which already contains the query, or an empty URL on the last page
+func nextPage(m meta, l links) (string, url.Values) {
+	if !m.HasMore {
+		return "", nil
+	}
+	return l.Next, nil
+}
@@ -2,6 +2,8 @@ package client
 
 import (
 	"context"
+	"path/filepath"
+	"strings"
 
 	"github.com/gptscript-ai/knowledge/pkg/datastore"
 	dstypes "github.com/gptscript-ai/knowledge/pkg/datastore/types"
@@ -17,6 +19,43 @@ type SharedIngestionOpts struct {
 	IngestionFlows      []flows.IngestionFlow
 	IsDuplicateFuncName string
 	Metadata            map[string]string
+	Password            string            // Password for encrypted files (zip, PDF, Office documents)
+	FilePasswords       map[string]string // Passwords for single files by glob pattern, matched against the path or the filename - take precedence over Password
+}
+
+// PasswordFor returns the password to use for the file at the given path. If several patterns match, the most specific
+// one wins: the path itself, then patterns matching the whole path over patterns matching the filename, then the
+// pattern with the most characters that aren't wildcards, e.g. reports/*.zip over *.zip.
+func (o SharedIngestionOpts) PasswordFor(path string) string {
+	if password, ok := o.FilePasswords[path]; ok {
+		return password
+	}
+
+	var best string
+	bestRank := -1
+	for pattern := range o.FilePasswords {
+		rank := matchRank(pattern, path)
+		if rank > bestRank || (rank == bestRank && rank >= 0 && pattern < best) {
+			best, bestRank = pattern, rank
+		}
+	}
+	if bestRank < 0 {
+		return o.Password
+	}
+	return o.FilePasswords[best]
+}
+
+// matchRank returns how specific the pattern is for the path, or -1 if it doesn't match. Patterns matching the whole
+// path rank above patterns matching only the filename, and then by their number of literal characters.
+func matchRank(pattern, p string) int {
+	literals := len(pattern) - strings.Count(pattern, "*") - strings.Count(pattern, "?")
+	if ok, _ := filepath.Match(pattern, p); ok {
+		return len(p) + 1 + literals
+	}
+	if ok, _ := filepath.Match(pattern, filepath.Base(p)); ok {
+		return literals
+	}
+	return -1
 }
 
 type IngestPathsOpts struct {
@@ -38,6 +77,7 @@ type Client interface {
 	GetDataset(ctx context.Context, datasetID string) (*types2.Dataset, error)
 	FindFile(ctx context.Context, searchFile types2.File) (*types2.File, error)
 	DeleteFile(ctx context.Context, datasetID, fileID string) error
+	UpdateFileMetadata(ctx context.Context, datasetID, fileID string, metadata map[string]any, opts *datastore.UpdateFileMetadataOpts) (*types2.File, error)
 	ListDatasets(ctx context.Context) ([]types2.Dataset, error)
 	Ingest(ctx context.Context, datasetID string, name string, data []byte, opts datastore.IngestOpts) ([]string, error)
 	IngestPaths(ctx context.Context, datasetID string, opts *IngestPathsOpts, paths ...string) (int, int, error) // returns number of files ingested, number of files skipped and first encountered error
@@ -45,8 +85,16 @@ type Client interface {
 	PrunePath(ctx context.Context, datasetID string, path string, keep []string) ([]types2.File, error)
 	DeleteDocuments(ctx context.Context, datasetID string, documentIDs ...string) error
 	Retrieve(ctx context.Context, datasetIDs []string, query string, opts datastore.RetrieveOpts) (*dstypes.RetrievalResponse, error)
+	RetrieveSimilar(ctx context.Context, datasetID, documentID, fileID string, opts datastore.SimilarOpts) (*dstypes.RetrievalResponse, error)
 	ExportDatasets(ctx context.Context, path string, datasets ...string) error
 	ImportDatasets(ctx context.Context, path string, datasets ...string) error
+	CloneDataset(ctx context.Context, sourceID, targetID string) (*datastore.MergeDatasetsResult, error)
+	MergeDatasets(ctx context.Context, sourceID, targetID string) (*datastore.MergeDatasetsResult, error)
 	UpdateDataset(ctx context.Context, dataset types2.Dataset, opts *datastore.UpdateDatasetOpts) (*types2.Dataset, error)
+	ListIngestionRuns(ctx context.Context, datasetID string, limit int) ([]types2.IngestionRun, error)
+	RecordFeedback(ctx context.Context, datasetID, documentID, query string, relevant bool, comment string) (*types2.Feedback, error)
+	ListFeedback(ctx context.Context, datasetID, documentID string, limit int) ([]types2.Feedback, error)
+	RetrievalCacheStats(ctx context.Context) (*types2.RetrievalCacheStats, error)
+	ClearRetrievalCache(ctx context.Context) error
 	Close() error
 }
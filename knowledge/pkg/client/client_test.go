@@ -0,0 +1,44 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordFor(t *testing.T) {
+	opts := SharedIngestionOpts{
+		Password: "default",
+		FilePasswords: map[string]string{
+			"*.zip":              "any zip",
+			"reports/*.zip":      "reports",
+			"reports/q?.zip":     "quarterly reports",
+			"q1.zip":             "q1",
+			"reports/annual.zip": "annual report",
+			"*.pdf":              "any pdf",
+			"*s.pdf":             "plural pdf",
+			"?s.pdf":             "short pdf",
+		},
+	}
+
+	for _, tc := range []struct {
+		path, want string
+	}{
+		// Patterns matching the whole path win over patterns matching the filename
+		{"reports/q1.zip", "quarterly reports"},
+		{"reports/summary.zip", "reports"},
+		{"archive/q1.zip", "q1"},
+		{"archive/old.zip", "any zip"},
+		// The path itself wins over every pattern
+		{"reports/annual.zip", "annual report"},
+		// Patterns with the same number of literal characters are ordered by name
+		{"docs/as.pdf", "plural pdf"},
+		{"docs/report.pdf", "any pdf"},
+		{"notes.txt", "default"},
+	} {
+		// Map iteration is random, so each path is checked repeatedly
+		for range 20 {
+			assert.Equal(t, tc.want, opts.PasswordFor(tc.path), tc.path)
+		}
+	}
+}
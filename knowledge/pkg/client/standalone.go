@@ -2,13 +2,17 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/gptscript-ai/go-gptscript"
 	"github.com/gptscript-ai/knowledge/pkg/datastore"
+	"github.com/gptscript-ai/knowledge/pkg/datastore/documentloader"
 	dstypes "github.com/gptscript-ai/knowledge/pkg/datastore/types"
 	types2 "github.com/gptscript-ai/knowledge/pkg/index/types"
 	"github.com/gptscript-ai/knowledge/pkg/log"
@@ -38,6 +42,10 @@ func (c *StandaloneClient) DeleteFile(ctx context.Context, datasetID, fileID str
 	return c.Datastore.DeleteFile(ctx, datasetID, fileID)
 }
 
+func (c *StandaloneClient) UpdateFileMetadata(ctx context.Context, datasetID, fileID string, metadata map[string]any, opts *datastore.UpdateFileMetadataOpts) (*types2.File, error) {
+	return c.Datastore.UpdateFileMetadata(ctx, datasetID, fileID, metadata, opts)
+}
+
 func (c *StandaloneClient) CreateDataset(ctx context.Context, datasetID string, opts *types2.DatasetCreateOpts) (*types2.Dataset, error) {
 	ds := types2.Dataset{
 		ID: datasetID,
@@ -73,8 +81,16 @@ func (c *StandaloneClient) ListDatasets(ctx context.Context) ([]types2.Dataset,
 
 func (c *StandaloneClient) Ingest(ctx context.Context, datasetID string, name string, data []byte, opts datastore.IngestOpts) ([]string, error) {
 	ids, err := c.Datastore.Ingest(ctx, datasetID, name, data, opts)
+	stats := datastore.IngestionStatsFromCtx(ctx)
 	if err != nil {
 		log.FromCtx(ctx).With("status", "failed").With("error", err.Error()).Error("Ingest failed")
+		if errors.Is(err, &documentloader.UnsupportedFileTypeError{}) {
+			stats.FilesUnsupported.Add(1)
+		} else {
+			stats.FilesFailed.Add(1)
+		}
+	} else if len(ids) > 0 {
+		stats.FilesIngested.Add(1)
 	}
 	return ids, err
 }
@@ -112,6 +128,7 @@ func (c *StandaloneClient) IngestFromWorkspace(ctx context.Context, datasetID st
 		IsDuplicateFuncName: opts.IsDuplicateFuncName,
 		ExtraMetadata:       meta,
 		IngestionFlows:      opts.IngestionFlows,
+		Password:            opts.PasswordFor(file),
 	}
 
 	_, err = c.Ingest(log.ToCtx(ctx, log.FromCtx(ctx).With("filepath", file).With("absolute_path", iopts.FileMetadata.AbsolutePath)), datasetID, finfo.Name, fileContent, iopts)
@@ -119,7 +136,21 @@ func (c *StandaloneClient) IngestFromWorkspace(ctx context.Context, datasetID st
 	return err
 }
 
+// IngestPaths ingests the given paths and records a summary of the run in the index (see ListIngestionRuns).
 func (c *StandaloneClient) IngestPaths(ctx context.Context, datasetID string, opts *IngestPathsOpts, paths ...string) (int, int, error) {
+	ctx, stats := datastore.WithIngestionStats(ctx)
+	startedAt := time.Now()
+
+	ingested, skipped, err := c.doIngestPaths(ctx, datasetID, opts, paths...)
+
+	if _, rerr := c.Datastore.RecordIngestionRun(ctx, datasetID, paths, startedAt, stats, err); rerr != nil {
+		slog.Warn("Failed to record ingestion run", "dataset", datasetID, "error", rerr)
+	}
+
+	return ingested, skipped, err
+}
+
+func (c *StandaloneClient) doIngestPaths(ctx context.Context, datasetID string, opts *IngestPathsOpts, paths ...string) (int, int, error) {
 	if strings.HasPrefix(paths[0], "ws://") {
 		if len(paths) > 1 {
 			return 0, 0, fmt.Errorf("cannot ingest multiple paths from workspace")
@@ -167,6 +198,7 @@ func (c *StandaloneClient) IngestPaths(ctx context.Context, datasetID string, op
 
 		if opts != nil {
 			iopts.IngestionFlows = opts.IngestionFlows
+			iopts.Password = opts.PasswordFor(abspath)
 		}
 
 		_, err = c.Ingest(log.ToCtx(ctx, log.FromCtx(ctx).With("filepath", path).With("absolute_path", iopts.FileMetadata.AbsolutePath)), datasetID, filename, file, iopts)
@@ -199,6 +231,10 @@ func (c *StandaloneClient) Retrieve(ctx context.Context, datasetIDs []string, qu
 	return c.Datastore.Retrieve(ctx, datasetIDs, query, opts)
 }
 
+func (c *StandaloneClient) RetrieveSimilar(ctx context.Context, datasetID, documentID, fileID string, opts datastore.SimilarOpts) (*dstypes.RetrievalResponse, error) {
+	return c.Datastore.RetrieveSimilar(ctx, datasetID, documentID, fileID, opts)
+}
+
 func (c *StandaloneClient) AskDirectory(ctx context.Context, path string, query string, opts *IngestPathsOpts, ropts *datastore.RetrieveOpts) (*dstypes.RetrievalResponse, error) {
 	return AskDir(ctx, c, path, query, opts, ropts)
 }
@@ -215,6 +251,34 @@ func (c *StandaloneClient) UpdateDataset(ctx context.Context, dataset types2.Dat
 	return c.Datastore.UpdateDataset(ctx, dataset, opts)
 }
 
+func (c *StandaloneClient) ListIngestionRuns(ctx context.Context, datasetID string, limit int) ([]types2.IngestionRun, error) {
+	return c.Datastore.ListIngestionRuns(ctx, datasetID, limit)
+}
+
+func (c *StandaloneClient) CloneDataset(ctx context.Context, sourceID, targetID string) (*datastore.MergeDatasetsResult, error) {
+	return c.Datastore.CloneDataset(ctx, sourceID, targetID)
+}
+
+func (c *StandaloneClient) MergeDatasets(ctx context.Context, sourceID, targetID string) (*datastore.MergeDatasetsResult, error) {
+	return c.Datastore.MergeDatasets(ctx, sourceID, targetID)
+}
+
+func (c *StandaloneClient) RecordFeedback(ctx context.Context, datasetID, documentID, query string, relevant bool, comment string) (*types2.Feedback, error) {
+	return c.Datastore.RecordFeedback(ctx, datasetID, documentID, query, relevant, comment)
+}
+
+func (c *StandaloneClient) ListFeedback(ctx context.Context, datasetID, documentID string, limit int) ([]types2.Feedback, error) {
+	return c.Datastore.ListFeedback(ctx, datasetID, documentID, limit)
+}
+
+func (c *StandaloneClient) RetrievalCacheStats(ctx context.Context) (*types2.RetrievalCacheStats, error) {
+	return c.Datastore.RetrievalCacheStats(ctx)
+}
+
+func (c *StandaloneClient) ClearRetrievalCache(ctx context.Context) error {
+	return c.Datastore.ClearRetrievalCache(ctx)
+}
+
 func (c *StandaloneClient) Close() error {
 	return c.Datastore.Close()
 }
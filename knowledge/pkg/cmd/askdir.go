@@ -46,6 +46,8 @@ func (s *ClientAskDir) Run(cmd *cobra.Command, args []string) error {
 	ingestOpts := &client.IngestPathsOpts{
 		SharedIngestionOpts: client.SharedIngestionOpts{
 			IsDuplicateFuncName: s.DeduplicationFuncName,
+			Password:            s.Password,
+			FilePasswords:       s.FilePasswords,
 		},
 		IgnoreExtensions:     strings.Split(s.IgnoreExtensions, ","),
 		Concurrency:          s.Concurrency,
@@ -56,9 +58,15 @@ func (s *ClientAskDir) Run(cmd *cobra.Command, args []string) error {
 		ErrOnUnsupportedFile: s.ErrOnUnsupportedFile,
 	}
 
+	feedbackWeight, err := s.feedbackWeight()
+	if err != nil {
+		return err
+	}
+
 	retrieveOpts := &datastore.RetrieveOpts{
-		TopK:     s.TopK,
-		Keywords: s.Keywords,
+		TopK:           s.TopK,
+		Keywords:       s.Keywords,
+		FeedbackWeight: feedbackWeight,
 	}
 
 	if s.FlowsFile != "" {
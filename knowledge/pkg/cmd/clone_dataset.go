@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+type ClientCloneDataset struct {
+	Client
+}
+
+func (s *ClientCloneDataset) Customize(cmd *cobra.Command) {
+	cmd.Use = "clone-dataset <source-dataset-id> <target-dataset-id>"
+	cmd.Short = "Create a copy of a dataset, including all documents and embeddings"
+	cmd.Long = "Create a copy of a dataset inside the same datastore, without re-embedding any content. Document IDs and metadata are preserved."
+	cmd.Args = cobra.ExactArgs(2)
+}
+
+func (s *ClientCloneDataset) Run(cmd *cobra.Command, args []string) error {
+	c, err := s.getClient(cmd.Context())
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	result, err := c.CloneDataset(cmd.Context(), args[0], args[1])
+	if err != nil {
+		return fmt.Errorf("failed to clone dataset: %w", err)
+	}
+
+	jsonOutput, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal result: %w", err)
+	}
+
+	fmt.Println(string(jsonOutput))
+	return nil
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gptscript-ai/knowledge/pkg/datastore"
+	"github.com/spf13/cobra"
+)
+
+type ClientEditFile struct {
+	Client
+	Dataset         string            `usage:"Target Dataset ID" short:"d"`
+	ResetMetadata   bool              `usage:"reset custom metadata to default (empty)"`
+	UpdateMetadata  map[string]string `usage:"update metadata key-value pairs (existing metadata will be updated/preserved)"`
+	ReplaceMetadata map[string]string `usage:"replace metadata with key-value pairs (existing metadata will be removed)"`
+}
+
+func (s *ClientEditFile) Customize(cmd *cobra.Command) {
+	cmd.Use = "edit-file <file-id>"
+	cmd.Short = "Edit the custom metadata of an ingested file (propagated to all of its documents, without re-embedding)"
+	cmd.Args = cobra.ExactArgs(1)
+	cmd.MarkFlagsMutuallyExclusive("reset-metadata", "update-metadata", "replace-metadata")
+}
+
+func (s *ClientEditFile) Run(cmd *cobra.Command, args []string) error {
+	if s.Dataset == "" {
+		exitErr0(fmt.Errorf("no dataset specified"))
+	}
+
+	c, err := s.getClient(cmd.Context())
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	// Since flags are mutually exclusive, this should be either an empty map, or one of the update/replace maps
+	metadata := map[string]any{}
+
+	for k, v := range s.UpdateMetadata {
+		metadata[k] = v
+	}
+
+	for k, v := range s.ReplaceMetadata {
+		metadata[k] = v
+	}
+
+	file, err := c.UpdateFileMetadata(cmd.Context(), s.Dataset, args[0], metadata, &datastore.UpdateFileMetadataOpts{ReplaceMetadata: s.ResetMetadata || len(s.ReplaceMetadata) > 0})
+	if err != nil {
+		return fmt.Errorf("failed to update file: %w", err)
+	}
+
+	file.Documents = nil // Don't print documents
+
+	jsonOutput, err := json.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("failed to marshal file: %w", err)
+	}
+
+	fmt.Println("Updated file:\n", string(jsonOutput))
+	return nil
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/gptscript-ai/knowledge/pkg/index/types"
+	"github.com/spf13/cobra"
+)
+
+type ClientFeedback struct {
+	Client
+	Datasets []string `usage:"Dataset IDs to look up the document in - the feedback is recorded for the first one containing it" short:"d" env:"KNOW_DATASETS" name:"dataset"`
+	Query    string   `usage:"Query that the document was retrieved for" short:"q"`
+	Comment  string   `usage:"Optional comment, e.g. why the document is (not) relevant"`
+}
+
+func (s *ClientFeedback) Customize(cmd *cobra.Command) {
+	cmd.Use = "feedback [--dataset <dataset-id>] [--query <query>] <document-id> up|down"
+	cmd.Short = "Record whether a retrieved document was relevant"
+	cmd.Long = "Recorded feedback can be used to re-score retrieval results, see the --feedback-weight flag of the retrieve command."
+	cmd.Args = cobra.ExactArgs(2)
+}
+
+func (s *ClientFeedback) Run(cmd *cobra.Command, args []string) error {
+	if len(s.Datasets) == 0 {
+		exitErr0(fmt.Errorf("no dataset specified"))
+	}
+
+	var relevant bool
+	switch args[1] {
+	case "up", "+", "+1", "relevant":
+		relevant = true
+	case "down", "-", "-1", "irrelevant":
+		relevant = false
+	default:
+		return fmt.Errorf("invalid feedback %q - must be \"up\" or \"down\"", args[1])
+	}
+
+	c, err := s.getClient(cmd.Context())
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	var feedback *types.Feedback
+	for _, dataset := range s.Datasets {
+		feedback, err = c.RecordFeedback(cmd.Context(), dataset, args[0], s.Query, relevant, s.Comment)
+		if err == nil || !errors.Is(err, types.ErrDBDocumentNotFound) {
+			break
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record feedback: %w", err)
+	}
+
+	jsonOutput, err := json.Marshal(feedback)
+	if err != nil {
+		return fmt.Errorf("failed to marshal feedback: %w", err)
+	}
+
+	fmt.Println(string(jsonOutput))
+	return nil
+}
+
+type ClientListFeedback struct {
+	Client
+	Dataset  string `usage:"Target Dataset ID" short:"d" env:"KNOW_DATASET"`
+	Document string `usage:"Only show the feedback for this document ID"`
+	Limit    int    `usage:"Maximum number of entries to show (newest first, 0 = all)" short:"n" default:"50"`
+}
+
+func (s *ClientListFeedback) Customize(cmd *cobra.Command) {
+	cmd.Use = "list-feedback --dataset <dataset-id>"
+	cmd.Short = "List the recorded relevance feedback of a dataset"
+	cmd.Args = cobra.NoArgs
+}
+
+func (s *ClientListFeedback) Run(cmd *cobra.Command, args []string) error {
+	if s.Dataset == "" {
+		exitErr0(fmt.Errorf("no dataset specified"))
+	}
+
+	c, err := s.getClient(cmd.Context())
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	feedback, err := c.ListFeedback(cmd.Context(), s.Dataset, s.Document, s.Limit)
+	if err != nil {
+		return fmt.Errorf("failed to list feedback: %w", err)
+	}
+
+	if len(feedback) == 0 {
+		fmt.Println("no feedback found")
+		return nil
+	}
+
+	jsonOutput, err := json.Marshal(feedback)
+	if err != nil {
+		return fmt.Errorf("failed to marshal feedback: %w", err)
+	}
+
+	fmt.Println(string(jsonOutput))
+	return nil
+}
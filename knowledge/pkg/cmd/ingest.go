@@ -23,6 +23,8 @@ type ClientIngest struct {
 	Client
 	Dataset string `usage:"Target Dataset ID" short:"d" env:"KNOW_DATASET"`
 	Prune   bool   `usage:"Prune deleted files" env:"KNOW_INGEST_PRUNE"`
+	// MetricsAddress is read by Knowledge.PersistentPre, which sets up telemetry before the command runs
+	MetricsAddress string `usage:"Serve Prometheus metrics on http://<address>/metrics while ingesting, e.g. :9090"`
 	ClientIngestOpts
 	ClientFlowsConfig
 }
@@ -39,6 +41,8 @@ type ClientIngestOpts struct {
 	ExitOnFailedFile      bool              `usage:"Exit directly on failed file" default:"false" env:"KNOW_INGEST_EXIT_ON_FAILED_FILE"`
 	Metadata              map[string]string `usage:"Metadata to attach to the ingested files" env:"KNOW_INGEST_METADATA"`
 	MetadataJSON          string            `usage:"Metadata to attach to the loaded files in JSON format" env:"METADATA_JSON"`
+	Password              string            `usage:"Password for encrypted files (zip, PDF, Office documents)" env:"KNOW_INGEST_PASSWORD"`
+	FilePasswords         map[string]string `usage:"Passwords for single encrypted files as <glob>=<password>, matched against the file path or name, the most specific pattern wins" env:"KNOW_INGEST_FILE_PASSWORDS"`
 }
 
 func (s *ClientIngest) Customize(cmd *cobra.Command) {
@@ -102,6 +106,8 @@ func (s *ClientIngest) run(ctx context.Context, filePath string) error {
 		SharedIngestionOpts: client.SharedIngestionOpts{
 			IsDuplicateFuncName: s.DeduplicationFuncName,
 			Metadata:            metadata,
+			Password:            s.Password,
+			FilePasswords:       s.FilePasswords,
 		},
 		IgnoreExtensions:     strings.Split(s.IgnoreExtensions, ","),
 		Concurrency:          s.Concurrency,
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+type ClientListIngestionRuns struct {
+	Client
+	Dataset string `usage:"Target Dataset ID" short:"d" env:"KNOW_DATASET"`
+	Limit   int    `usage:"Maximum number of runs to show (newest first, 0 = all)" short:"n" default:"20"`
+}
+
+func (s *ClientListIngestionRuns) Customize(cmd *cobra.Command) {
+	cmd.Use = "list-ingestion-runs --dataset <dataset-id>"
+	cmd.Short = "List the recorded ingestion runs of a dataset"
+	cmd.Args = cobra.NoArgs
+}
+
+func (s *ClientListIngestionRuns) Run(cmd *cobra.Command, args []string) error {
+	if s.Dataset == "" {
+		exitErr0(fmt.Errorf("no dataset specified"))
+	}
+
+	c, err := s.getClient(cmd.Context())
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	runs, err := c.ListIngestionRuns(cmd.Context(), s.Dataset, s.Limit)
+	if err != nil {
+		return fmt.Errorf("failed to list ingestion runs: %w", err)
+	}
+
+	if len(runs) == 0 {
+		fmt.Println("no ingestion runs found")
+		return nil
+	}
+
+	jsonOutput, err := json.Marshal(runs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal ingestion runs: %w", err)
+	}
+
+	fmt.Println(string(jsonOutput))
+	return nil
+}
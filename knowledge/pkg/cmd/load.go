@@ -25,9 +25,10 @@ import (
 
 type ClientLoad struct {
 	Loader       string            `usage:"Choose a document loader to use"`
-	OutputFormat string            `name:"format" usage:"Choose an output format" default:"structured"`
+	OutputFormat string            `name:"format" usage:"Choose an output format (structured, markdown or text)" default:"structured"`
 	Metadata     map[string]string `usage:"Metadata to attach to the loaded files" env:"METADATA"`
 	MetadataJSON string            `usage:"Metadata to attach to the loaded files in JSON format" env:"METADATA_JSON"`
+	Password     string            `usage:"Password for encrypted files (zip, PDF, Office documents)" env:"KNOW_LOAD_PASSWORD"`
 	ClientFlowsConfig
 }
 
@@ -49,7 +50,7 @@ func (s *ClientLoad) Run(cmd *cobra.Command, args []string) error {
 }
 
 func (s *ClientLoad) run(ctx context.Context, input, output string) error {
-	if !slices.Contains([]string{"structured", "markdown"}, s.OutputFormat) {
+	if !slices.Contains([]string{"structured", "markdown", "text"}, s.OutputFormat) {
 		return fmt.Errorf("unsupported output format %q", s.OutputFormat)
 	}
 
@@ -162,6 +163,16 @@ func (s *ClientLoad) run(ctx context.Context, input, output string) error {
 		}
 	}
 
+	ctx = documentloader.WithPassword(ctx, s.Password)
+	inputBytes, err = documentloader.Decrypt(ctx, input, inputBytes)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt input file %q: %w", input, err)
+	}
+	if encryptedLoader := documentloader.EncryptedLoaderFunc(filetype, inputBytes); encryptedLoader != nil {
+		slog.Debug("Loading password-protected PDF with GoPDF", "input", input)
+		loader = encryptedLoader
+	}
+
 	var reader io.Reader
 	if converter.Converter != nil {
 		reader, err = converter.Converter.Convert(ctx, bytes.NewReader(inputBytes), filepath.Ext(input), converter.TargetFormat)
@@ -207,6 +218,17 @@ func (s *ClientLoad) run(ctx context.Context, input, output string) error {
 
 		text = strings.Join(texts, "\n---docbreak---\n")
 
+	case "text":
+		// Only the content, e.g. to read a document without ingesting it
+		var texts []string
+		for _, doc := range docs {
+			if strings.TrimSpace(doc.Content) != "" {
+				texts = append(texts, doc.Content)
+			}
+		}
+
+		text = strings.Join(texts, "\n\n")
+
 	case "structured":
 		var structuredInput structured.StructuredInput
 		structuredInput.Metadata = map[string]any{}
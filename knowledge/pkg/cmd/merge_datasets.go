@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+type ClientMergeDatasets struct {
+	Client
+}
+
+func (s *ClientMergeDatasets) Customize(cmd *cobra.Command) {
+	cmd.Use = "merge-datasets <source-dataset-id> <target-dataset-id>"
+	cmd.Short = "Copy all files of a dataset into another dataset, including documents and embeddings"
+	cmd.Long = "Copy all files of a dataset into another existing dataset of the same datastore, without re-embedding any content. Document IDs and metadata are preserved. Files that already exist in the target dataset are skipped."
+	cmd.Args = cobra.ExactArgs(2)
+}
+
+func (s *ClientMergeDatasets) Run(cmd *cobra.Command, args []string) error {
+	c, err := s.getClient(cmd.Context())
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	result, err := c.MergeDatasets(cmd.Context(), args[0], args[1])
+	if err != nil {
+		return fmt.Errorf("failed to merge datasets: %w", err)
+	}
+
+	jsonOutput, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal result: %w", err)
+	}
+
+	fmt.Println(string(jsonOutput))
+	return nil
+}
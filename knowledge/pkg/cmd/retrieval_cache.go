@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+type ClientRetrievalCache struct {
+	Client
+	Clear bool `usage:"Remove all cached retrieval results (metrics are kept)"`
+}
+
+func (s *ClientRetrievalCache) Customize(cmd *cobra.Command) {
+	cmd.Use = "retrieval-cache"
+	cmd.Short = "Show retrieval cache metrics or clear the cache"
+	cmd.Long = "Retrieval results are cached if KNOW_RETRIEVAL_CACHE_TTL is set (e.g. \"10m\") and invalidated whenever one of the queried datasets changes. Retrievals with external postprocessors are never cached."
+	cmd.Args = cobra.NoArgs
+}
+
+func (s *ClientRetrievalCache) Run(cmd *cobra.Command, args []string) error {
+	c, err := s.getClient(cmd.Context())
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if s.Clear {
+		if err := c.ClearRetrievalCache(cmd.Context()); err != nil {
+			return fmt.Errorf("failed to clear retrieval cache: %w", err)
+		}
+	}
+
+	stats, err := c.RetrievalCacheStats(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("failed to get retrieval cache stats: %w", err)
+	}
+
+	jsonOutput, err := json.Marshal(stats)
+	if err != nil {
+		return fmt.Errorf("failed to marshal retrieval cache stats: %w", err)
+	}
+
+	fmt.Println(string(jsonOutput))
+	return nil
+}
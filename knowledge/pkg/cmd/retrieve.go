@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"strconv"
 	"strings"
 
 	"github.com/gptscript-ai/knowledge/pkg/datastore"
@@ -24,6 +25,16 @@ type ClientRetrieve struct {
 type ClientRetrieveOpts struct {
 	TopK     int      `usage:"Number of sources to retrieve" short:"k" default:"10"`
 	Keywords []string `usage:"Keywords that retrieved documents must contain" short:"w" name:"keyword" env:"KNOW_RETRIEVE_KEYWORDS"`
+	// FeedbackWeight is a string, as the flag library has no float support
+	FeedbackWeight string `usage:"Re-score results by recorded relevance feedback, shifting scores by up to +/- this value (e.g. 0.1, 0 = disabled)" env:"KNOW_RETRIEVE_FEEDBACK_WEIGHT" default:"0"`
+}
+
+func (o ClientRetrieveOpts) feedbackWeight() (float32, error) {
+	w, err := strconv.ParseFloat(o.FeedbackWeight, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid feedback weight %q: %w", o.FeedbackWeight, err)
+	}
+	return float32(w), nil
 }
 
 func (s *ClientRetrieve) Customize(cmd *cobra.Command) {
@@ -57,9 +68,15 @@ func (s *ClientRetrieve) Run(cmd *cobra.Command, args []string) error {
 	}
 	defer c.Close()
 
+	feedbackWeight, err := s.feedbackWeight()
+	if err != nil {
+		return err
+	}
+
 	retrieveOpts := datastore.RetrieveOpts{
-		TopK:     s.TopK,
-		Keywords: s.Keywords,
+		TopK:           s.TopK,
+		Keywords:       s.Keywords,
+		FeedbackWeight: feedbackWeight,
 	}
 
 	if s.FlowsFile != "" {
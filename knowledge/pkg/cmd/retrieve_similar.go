@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/gptscript-ai/knowledge/pkg/datastore"
+	"github.com/spf13/cobra"
+)
+
+type ClientRetrieveSimilar struct {
+	Client
+	Dataset         string   `usage:"Dataset ID of the source document or file" short:"d" env:"KNOW_DATASET"`
+	SearchDatasets  []string `usage:"Dataset IDs to search in (default: the source dataset)" name:"search-dataset"`
+	File            bool     `usage:"Treat the argument as a file ID instead of a document ID"`
+	IncludeSameFile bool     `usage:"Include other documents from the same file in the results"`
+	TopK            int      `usage:"Number of sources to retrieve" short:"k" default:"10"`
+}
+
+func (s *ClientRetrieveSimilar) Customize(cmd *cobra.Command) {
+	cmd.Use = "retrieve-similar --dataset <dataset-id> <document-id|file-id>"
+	cmd.Short = "Retrieve content similar to an already ingested document or file (more-like-this)"
+	cmd.Args = cobra.ExactArgs(1)
+}
+
+func (s *ClientRetrieveSimilar) Run(cmd *cobra.Command, args []string) error {
+	if s.Dataset == "" {
+		exitErr0(fmt.Errorf("no dataset specified"))
+	}
+
+	c, err := s.getClient(cmd.Context())
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	var documentID, fileID string
+	if s.File {
+		fileID = args[0]
+	} else {
+		documentID = args[0]
+	}
+
+	retrievalResp, err := c.RetrieveSimilar(cmd.Context(), s.Dataset, documentID, fileID, datastore.SimilarOpts{
+		TopK:            s.TopK,
+		Datasets:        s.SearchDatasets,
+		IncludeSameFile: s.IncludeSameFile,
+	})
+	if err != nil {
+		return err
+	}
+
+	jsonSources, err := json.Marshal(retrievalResp)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Retrieved similar sources", "source", args[0], "dataset", s.Dataset, "num_sources", retrievalResp.Responses[0].NumDocs)
+
+	fmt.Println(string(jsonSources))
+
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/acorn-io/cmd"
+	"github.com/gptscript-ai/knowledge/pkg/telemetry"
 	"github.com/gptscript-ai/knowledge/version"
 	"github.com/spf13/cobra"
 )
@@ -17,7 +18,7 @@ func init() {
 }
 
 func New() *cobra.Command {
-	return cmd.Command(
+	root := cmd.Command(
 		&Knowledge{},
 		new(ClientCreateDataset),
 		new(ClientGetDataset),
@@ -27,13 +28,31 @@ func New() *cobra.Command {
 		new(ClientDeleteFile),
 		new(ClientGetFile),
 		new(ClientRetrieve),
+		new(ClientRetrieveSimilar),
 		new(ClientAskDir),
 		new(ClientExportDatasets),
 		new(ClientImportDatasets),
+		new(ClientCloneDataset),
+		new(ClientMergeDatasets),
 		new(ClientEditDataset),
+		new(ClientEditFile),
 		new(ClientLoad),
+		new(ClientListIngestionRuns),
+		new(ClientRetrievalCache),
+		new(ClientFeedback),
+		new(ClientListFeedback),
 		new(Version),
 	)
+
+	// Flush spans and metrics before exiting
+	root.PersistentPostRunE = func(cmd *cobra.Command, _ []string) error {
+		if err := telemetry.Shutdown(cmd.Context()); err != nil {
+			slog.Warn("Failed to flush telemetry", "error", err)
+		}
+		return nil
+	}
+
+	return root
 }
 
 type Knowledge struct {
@@ -45,7 +64,7 @@ func (c *Knowledge) Run(cmd *cobra.Command, _ []string) error {
 	return cmd.Help()
 }
 
-func (c *Knowledge) PersistentPre(_ *cobra.Command, _ []string) error {
+func (c *Knowledge) PersistentPre(cmd *cobra.Command, _ []string) error {
 	lvl := slog.LevelInfo
 
 	if c.Debug {
@@ -59,6 +78,12 @@ func (c *Knowledge) PersistentPre(_ *cobra.Command, _ []string) error {
 			Level:     lvl,
 		})))
 	}
+
+	// Only long-running commands can serve metrics (see ClientIngest), short-lived ones push them via OTLP
+	metricsAddress, _ := cmd.Flags().GetString("metrics-address")
+	if err := telemetry.Setup(cmd.Context(), metricsAddress); err != nil {
+		slog.Warn("Failed to set up telemetry", "error", err)
+	}
 	return nil
 }
 
@@ -26,7 +26,7 @@ type ModelProviderConfig struct {
 }
 
 type DatabaseConfig struct {
-	DSN         string `name:"index-dsn" usage:"Index Database Connection string (relational DB) (default \"sqlite://$XDG_DATA_HOME/gptscript/knowledge/knowledge.db\")" default:"" env:"KNOW_INDEX_DSN"`
+	DSN         string `name:"index-dsn" usage:"Index Database Connection string (relational DB: sqlite:// or postgres://) (default \"sqlite://$XDG_DATA_HOME/gptscript/knowledge/knowledge.db\")" default:"" env:"KNOW_INDEX_DSN"`
 	AutoMigrate string `usage:"Auto migrate database" default:"true" env:"KNOW_DB_AUTO_MIGRATE"`
 }
 
@@ -0,0 +1,70 @@
+// Package datasource is the common framework of the knowledge data sources: a source only lists and fetches its items
+// (and optionally reports changes since a cursor), while the Syncer takes care of scheduling full and incremental
+// syncs, retries, the persisted sync state and the files and metadata written to the workspace.
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// ErrFullSyncRequired is returned (or wrapped) by ChangeTracker.Changes if the changes since the cursor can't be
+// listed, e.g. because the cursor expired or a change affects too many items. The Syncer lists all items instead.
+var ErrFullSyncRequired = errors.New("full sync required")
+
+// Item is a single document of a data source, written to one file in the workspace.
+type Item struct {
+	// ID is the stable ID of the item in the source, the key of its state
+	ID string `json:"id"`
+	// Path is the path of the file in the workspace, relative to the workspace root
+	Path string `json:"path"`
+	// URL is the link to the item in the source
+	URL string `json:"url,omitempty"`
+	// Version changes whenever the content of the item changes, e.g. the modification time or an ETag. Items with
+	// the same version as in the last sync are not fetched again. An empty version always fetches the item.
+	Version string `json:"version,omitempty"`
+	// Metadata is additional information about the item that is kept in the sync state
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Change is a changed or deleted item reported by a ChangeTracker.
+type Change struct {
+	Item
+	Deleted bool `json:"deleted,omitempty"`
+}
+
+// DataSource is implemented by all data sources.
+type DataSource interface {
+	// Name is the unique name of the configured data source, used as the key of its sync state
+	Name() string
+	// List calls fn with all items of the source, page by page
+	List(ctx context.Context, fn func(items []Item) error) error
+	// Fetch returns the content of the item
+	Fetch(ctx context.Context, item Item) ([]byte, error)
+}
+
+// ChangeTracker is implemented by data sources that can list the changes since the last sync, e.g. with a delta
+// API. Sources that don't implement it are listed completely on every sync.
+type ChangeTracker interface {
+	// Cursor returns the cursor of the current state of the source. It is requested before a full listing, so
+	// changes during the listing are picked up by the next incremental sync.
+	Cursor(ctx context.Context) (string, error)
+	// Changes calls fn with the changes since the cursor, page by page, together with the cursor to continue from
+	// after the page.
+	Changes(ctx context.Context, cursor string, fn func(changes []Change, cursor string) error) error
+}
+
+// StatefulSource is implemented by data sources that keep state of their own between syncs, e.g. the ETags of
+// conditional requests. The state is restored before each sync and saved with the sync state.
+type StatefulSource interface {
+	LoadState(data json.RawMessage) error
+	SaveState() (json.RawMessage, error)
+}
+
+// Retainer is implemented by data sources whose listing doesn't contain all items that should be kept, e.g. feeds
+// only list their latest entries. The files of items missing from a full listing are only deleted if Retain returns
+// false.
+type Retainer interface {
+	Retain(id string, file FileState) bool
+}
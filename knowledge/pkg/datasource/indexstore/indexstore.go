@@ -0,0 +1,105 @@
+// Package indexstore keeps the sync state of data sources in the index DB, next to the datasets the sources are
+// ingested into. It is separate from the datasource package, so that only the tools that open the index DB depend
+// on its drivers.
+package indexstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/gptscript-ai/knowledge/pkg/datasource"
+	"github.com/gptscript-ai/knowledge/pkg/index"
+	"github.com/gptscript-ai/knowledge/pkg/index/types"
+)
+
+const (
+	// EnvDSN is the index DB of the knowledge server, see the index-dsn flag
+	EnvDSN = "KNOW_INDEX_DSN"
+	// EnvWorkspaceID is the workspace the data source tools sync into
+	EnvWorkspaceID = "GPTSCRIPT_WORKSPACE_ID"
+)
+
+// FromEnv returns the state store of a data source tool: the index DB if KNOW_INDEX_DSN is set, with the state
+// scoped to the workspace of the tool, or the metadata file of the workspace otherwise. The returned function closes
+// the index DB.
+func FromEnv(ctx context.Context, workspace datasource.Workspace) (datasource.StateStore, func() error, error) {
+	dsn := os.Getenv(EnvDSN)
+	if dsn == "" {
+		return datasource.NewMetadataStore(workspace), func() error { return nil }, nil
+	}
+
+	db, err := index.New(ctx, dsn, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &Store{DB: db, Scope: os.Getenv(EnvWorkspaceID)}, db.Close, nil
+}
+
+// DB is the part of the index DB used by the Store
+type DB interface {
+	GetSyncState(ctx context.Context, source string) (*types.SyncState, error)
+	PutSyncState(ctx context.Context, state types.SyncState) error
+}
+
+// Store is a datasource.StateStore backed by the index DB.
+type Store struct {
+	DB DB
+	// Scope is prepended to the names of the sources, so that a source can be synced into several workspaces
+	Scope string
+}
+
+func New(db DB) *Store {
+	return &Store{DB: db}
+}
+
+func (s *Store) Load(ctx context.Context, source string) (*datasource.State, error) {
+	row, err := s.DB.GetSyncState(ctx, s.key(source))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sync state of %s: %w", source, err)
+	}
+
+	state := &datasource.State{}
+	if row != nil {
+		if len(row.State) > 0 {
+			if err := json.Unmarshal(row.State, state); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal sync state of %s: %w", source, err)
+			}
+		}
+		state.LastSync = row.LastSync
+		state.LastFullSync = row.LastFullSync
+		state.Failures = row.Failures
+		state.LastError = row.LastError
+	}
+	if state.Files == nil {
+		state.Files = make(map[string]datasource.FileState)
+	}
+	return state, nil
+}
+
+func (s *Store) Save(ctx context.Context, source string, state *datasource.State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal sync state of %s: %w", source, err)
+	}
+
+	if err := s.DB.PutSyncState(ctx, types.SyncState{
+		Source:       s.key(source),
+		State:        data,
+		LastSync:     state.LastSync,
+		LastFullSync: state.LastFullSync,
+		Failures:     state.Failures,
+		LastError:    state.LastError,
+	}); err != nil {
+		return fmt.Errorf("failed to save sync state of %s: %w", source, err)
+	}
+	return nil
+}
+
+func (s *Store) key(source string) string {
+	if s.Scope == "" {
+		return source
+	}
+	return s.Scope + "/" + source
+}
@@ -0,0 +1,99 @@
+package indexstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gptscript-ai/knowledge/pkg/datasource"
+	"github.com/gptscript-ai/knowledge/pkg/index/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testDB struct {
+	states map[string]types.SyncState
+}
+
+func (db *testDB) GetSyncState(_ context.Context, source string) (*types.SyncState, error) {
+	if state, ok := db.states[source]; ok {
+		return &state, nil
+	}
+	return nil, nil
+}
+
+func (db *testDB) PutSyncState(_ context.Context, state types.SyncState) error {
+	db.states[state.Source] = state
+	return nil
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	db := &testDB{states: map[string]types.SyncState{}}
+	store := New(db)
+
+	state, err := store.Load(ctx, "test")
+	require.NoError(t, err)
+	assert.Equal(t, &datasource.State{Files: map[string]datasource.FileState{}}, state, "never synced")
+
+	now := time.Now().UTC().Truncate(time.Second)
+	state.Files["1"] = datasource.FileState{FilePath: "a.md", UpdatedAt: "v1"}
+	state.Cursor = "c1"
+	state.LastSync = now
+	state.LastFullSync = now
+	state.LastAttempt = now
+	state.Failures = 2
+	state.LastError = "timeout"
+	require.NoError(t, store.Save(ctx, "test", state))
+
+	// The timestamps and failures are columns of the DB, the rest is the JSON state
+	row := db.states["test"]
+	assert.Equal(t, now, row.LastFullSync)
+	assert.Equal(t, 2, row.Failures)
+	assert.NotContains(t, string(row.State), "timeout")
+
+	loaded, err := store.Load(ctx, "test")
+	require.NoError(t, err)
+	assert.Equal(t, state, loaded)
+}
+
+func TestStoreScope(t *testing.T) {
+	ctx := context.Background()
+	db := &testDB{states: map[string]types.SyncState{}}
+
+	first := &Store{DB: db, Scope: "ws1"}
+	state, err := first.Load(ctx, "s3")
+	require.NoError(t, err)
+	state.Cursor = "c1"
+	require.NoError(t, first.Save(ctx, "s3", state))
+	assert.Contains(t, db.states, "ws1/s3")
+
+	// The same source in another workspace has its own state
+	second, err := (&Store{DB: db, Scope: "ws2"}).Load(ctx, "s3")
+	require.NoError(t, err)
+	assert.Empty(t, second.Cursor)
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv(EnvDSN, "")
+	store, closeStore, err := FromEnv(context.Background(), nil)
+	require.NoError(t, err)
+	assert.IsType(t, &datasource.MetadataStore{}, store, "without the index DB")
+	assert.NoError(t, closeStore())
+
+	t.Setenv(EnvDSN, "sqlite://"+t.TempDir()+"/knowledge.db")
+	t.Setenv(EnvWorkspaceID, "ws1")
+	store, closeStore, err = FromEnv(context.Background(), nil)
+	require.NoError(t, err)
+	defer closeStore()
+	require.IsType(t, &Store{}, store)
+	assert.Equal(t, "ws1", store.(*Store).Scope)
+
+	state, err := store.Load(context.Background(), "s3")
+	require.NoError(t, err)
+	state.Cursor = "c1"
+	require.NoError(t, store.Save(context.Background(), "s3", state))
+	loaded, err := store.Load(context.Background(), "s3")
+	require.NoError(t, err)
+	assert.Equal(t, "c1", loaded.Cursor)
+}
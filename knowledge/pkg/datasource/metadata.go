@@ -0,0 +1,142 @@
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+)
+
+const defaultMetadataPath = ".metadata.json"
+
+// metadata is the content of the metadata file. The status and files are read by the knowledge ingestion, the
+// state lets the next sync continue where this one stopped.
+type metadata struct {
+	Status string               `json:"status"`
+	Files  map[string]FileState `json:"files"`
+	State  metadataState        `json:"state"`
+}
+
+// metadataState is the State without the files, which are already part of the metadata. Timestamps are RFC 3339
+// strings, empty if unset.
+type metadataState struct {
+	Cursor       string          `json:"cursor,omitempty"`
+	Pending      map[string]Item `json:"pending,omitempty"`
+	Config       string          `json:"config,omitempty"`
+	Source       json.RawMessage `json:"source,omitempty"`
+	LastSync     string          `json:"lastSync,omitempty"`
+	LastFullSync string          `json:"lastFullSync,omitempty"`
+	LastAttempt  string          `json:"lastAttempt,omitempty"`
+	Failures     int             `json:"failures,omitempty"`
+	LastError    string          `json:"lastError,omitempty"`
+}
+
+func newMetadata(status string, state *State) metadata {
+	return metadata{
+		Status: status,
+		Files:  state.Files,
+		State: metadataState{
+			Cursor:       state.Cursor,
+			Pending:      state.Pending,
+			Config:       state.Config,
+			Source:       state.Source,
+			LastSync:     formatTime(state.LastSync),
+			LastFullSync: formatTime(state.LastFullSync),
+			LastAttempt:  formatTime(state.LastAttempt),
+			Failures:     state.Failures,
+			LastError:    state.LastError,
+		},
+	}
+}
+
+func (m metadata) syncState() *State {
+	state := &State{
+		Files:        m.Files,
+		Cursor:       m.State.Cursor,
+		Pending:      m.State.Pending,
+		Config:       m.State.Config,
+		Source:       m.State.Source,
+		LastSync:     parseTime(m.State.LastSync),
+		LastFullSync: parseTime(m.State.LastFullSync),
+		LastAttempt:  parseTime(m.State.LastAttempt),
+		Failures:     m.State.Failures,
+		LastError:    m.State.LastError,
+	}
+	if state.Files == nil {
+		state.Files = make(map[string]FileState)
+	}
+	return state
+}
+
+// MetadataStore keeps the sync state in the metadata file of the workspace, next to the status and the synced
+// files. It is used by the data source tools, which only have access to their workspace and sync a single source.
+type MetadataStore struct {
+	Workspace Workspace
+	// Path is the metadata file, defaults to .metadata.json
+	Path string
+}
+
+func NewMetadataStore(workspace Workspace) *MetadataStore {
+	return &MetadataStore{Workspace: workspace}
+}
+
+func (s *MetadataStore) Load(ctx context.Context, source string) (*State, error) {
+	m, err := s.read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sync state of %s: %w", source, err)
+	}
+	return m.syncState(), nil
+}
+
+// Save writes the state to the metadata file and keeps its status
+func (s *MetadataStore) Save(ctx context.Context, source string, state *State) error {
+	current, err := s.read(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to save sync state of %s: %w", source, err)
+	}
+
+	data, err := json.MarshalIndent(newMetadata(current.Status, state), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal sync state of %s: %w", source, err)
+	}
+	if err := s.Workspace.WriteFile(ctx, s.path(), data); err != nil {
+		return fmt.Errorf("failed to save sync state of %s: %w", source, err)
+	}
+	return nil
+}
+
+// read returns the content of the metadata file, which is empty if it doesn't exist yet
+func (s *MetadataStore) read(ctx context.Context) (metadata, error) {
+	var m metadata
+	data, err := s.Workspace.ReadFile(ctx, s.path())
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	} else if err != nil {
+		return m, err
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return m, fmt.Errorf("failed to unmarshal %s: %w", s.path(), err)
+	}
+	return m, nil
+}
+
+func (s *MetadataStore) path() string {
+	if s.Path != "" {
+		return s.Path
+	}
+	return defaultMetadataPath
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func parseTime(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, s)
+	return t
+}
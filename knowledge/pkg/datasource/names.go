@@ -0,0 +1,63 @@
+package datasource
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// SanitizeName makes a name of the source (e.g. a document title) usable as a single segment of a file path
+func SanitizeName(name string) string {
+	return strings.ReplaceAll(name, "/", "_")
+}
+
+// CompileGlobs compiles the glob patterns (see GlobToRegexp), skipping empty ones
+func CompileGlobs(globs []string) ([]*regexp.Regexp, error) {
+	patterns := make([]*regexp.Regexp, 0, len(globs))
+	for _, glob := range globs {
+		if glob = strings.TrimSpace(glob); glob == "" {
+			continue
+		}
+		pattern, err := GlobToRegexp(glob)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", glob, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// MatchesAny reports whether the path matches one of the patterns
+func MatchesAny(patterns []*regexp.Regexp, p string) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// GlobToRegexp converts a glob pattern to a regular expression. * and ? don't match slashes, ** matches any number
+// of path segments.
+func GlobToRegexp(glob string) (*regexp.Regexp, error) {
+	var expr strings.Builder
+	expr.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; {
+		case strings.HasPrefix(glob[i:], "**/"):
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case c == '*':
+			expr.WriteString("[^/]*")
+		case c == '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.Compile(expr.String())
+}
@@ -0,0 +1,56 @@
+package datasource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlobToRegexp(t *testing.T) {
+	for glob, matches := range map[string]map[string]bool{
+		"*.md":         {"a.md": true, "docs/a.md": false},
+		"**/*.md":      {"a.md": true, "docs/a.md": true, "a.mdx": false},
+		"docs/**":      {"docs/a/b.pdf": true, "src/a.pdf": false},
+		"report-?.pdf": {"report-1.pdf": true, "report-10.pdf": false},
+	} {
+		pattern, err := GlobToRegexp(glob)
+		require.NoError(t, err)
+		for p, match := range matches {
+			assert.Equal(t, match, pattern.MatchString(p), "%s matches %s", glob, p)
+		}
+	}
+}
+
+func TestRichTextToMarkdown(t *testing.T) {
+	text := func(s string, marks ...string) RichTextNode {
+		n := RichTextNode{Type: "text", Text: s}
+		for _, m := range marks {
+			n.Marks = append(n.Marks, RichTextNode{Type: m})
+		}
+		return n
+	}
+	paragraph := func(content ...RichTextNode) RichTextNode {
+		return RichTextNode{Type: "paragraph", Content: content}
+	}
+
+	// Box Notes use the ProseMirror node names, ADF (Jira) camel case ones
+	for name, doc := range map[string]RichTextNode{
+		"prosemirror": {Content: []RichTextNode{
+			{Type: "heading", Attrs: map[string]any{"level": float64(2)}, Content: []RichTextNode{text("Plan")}},
+			paragraph(text("ship "), text("now", "strong"), text(" or "), text("later", "strikethrough")),
+			{Type: "check_list", Content: []RichTextNode{
+				{Type: "check_list_item", Attrs: map[string]any{"checked": true}, Content: []RichTextNode{paragraph(text("tests"))}},
+			}},
+		}},
+		"adf": {Content: []RichTextNode{
+			{Type: "heading", Attrs: map[string]any{"level": float64(2)}, Content: []RichTextNode{text("Plan")}},
+			paragraph(text("ship "), text("now", "strong"), text(" or "), text("later", "strike")),
+			{Type: "taskList", Content: []RichTextNode{
+				{Type: "taskItem", Attrs: map[string]any{"state": "DONE"}, Content: []RichTextNode{text("tests")}},
+			}},
+		}},
+	} {
+		assert.Equal(t, "## Plan\n\nship **now** or ~~later~~\n\n- [x] tests", RichTextToMarkdown(&doc), name)
+	}
+}
@@ -0,0 +1,110 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	defaultAttempts   = 3
+	defaultBackoff    = time.Second
+	defaultMaxBackoff = 5 * time.Minute
+)
+
+// RetryPolicy retries failed requests to a source with exponential backoff. The zero value uses the defaults. The
+// backoff is also used to delay the next sync after failed syncs (see Syncer.NextSync).
+type RetryPolicy struct {
+	// Attempts is the number of attempts of a request, defaults to 3
+	Attempts int
+	// Backoff is the wait before the first retry, it doubles with each retry. Defaults to 1s.
+	Backoff time.Duration
+	// MaxBackoff limits the wait between retries, defaults to 5m
+	MaxBackoff time.Duration
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks an error that is not retried, e.g. because the item doesn't exist or access is denied
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// RetryAfterError is returned by sources that are told when to retry, e.g. by the Retry-After header of a rate
+// limited response. The wait replaces the backoff of the policy.
+type RetryAfterError struct {
+	Err   error
+	After time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// Do calls fn until it succeeds, returns a permanent error or the attempts are used up
+func (p RetryPolicy) Do(ctx context.Context, fn func() error) error {
+	attempts := p.Attempts
+	if attempts <= 0 {
+		attempts = defaultAttempts
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+
+		var permanentErr *permanentError
+		if errors.As(err, &permanentErr) || errors.Is(err, ErrFullSyncRequired) || attempt >= attempts || ctx.Err() != nil {
+			return err
+		}
+
+		wait := p.backoff(attempt)
+		var retryAfterErr *RetryAfterError
+		if errors.As(err, &retryAfterErr) && retryAfterErr.After > 0 {
+			wait = min(retryAfterErr.After, p.maxBackoff())
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+	}
+}
+
+// backoff returns the wait after the given number of failures
+func (p RetryPolicy) backoff(failures int) time.Duration {
+	backoff := p.Backoff
+	if backoff <= 0 {
+		backoff = defaultBackoff
+	}
+	for i := 1; i < failures && backoff < p.maxBackoff(); i++ {
+		backoff *= 2
+	}
+	return min(backoff, p.maxBackoff())
+}
+
+func (p RetryPolicy) maxBackoff() time.Duration {
+	if p.MaxBackoff > 0 {
+		return p.MaxBackoff
+	}
+	return defaultMaxBackoff
+}
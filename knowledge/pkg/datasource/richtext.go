@@ -0,0 +1,218 @@
+package datasource
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RichTextNode is a node of a ProseMirror document, the rich text format of e.g. Box Notes, or of the Atlassian
+// Document Format (ADF) based on it, used by Jira and Confluence. The formats name the same nodes differently
+// (bullet_list and bulletList), both are supported.
+type RichTextNode struct {
+	Type    string         `json:"type"`
+	Text    string         `json:"text"`
+	Attrs   map[string]any `json:"attrs"`
+	Marks   []RichTextNode `json:"marks"`
+	Content []RichTextNode `json:"content"`
+}
+
+// RichTextToMarkdown converts a rich text document to Markdown
+func RichTextToMarkdown(doc *RichTextNode) string {
+	if doc == nil {
+		return ""
+	}
+	var sb strings.Builder
+	writeBlocks(&sb, doc.Content, "")
+	return strings.TrimSpace(sb.String())
+}
+
+// writeBlocks writes block nodes separated by blank lines, each line prefixed with indent (for nested lists and quotes)
+func writeBlocks(sb *strings.Builder, nodes []RichTextNode, indent string) {
+	for _, n := range nodes {
+		switch n.Type {
+		case "heading":
+			level, _ := n.Attrs["level"].(float64)
+			sb.WriteString(indent + strings.Repeat("#", max(int(level), 1)) + " " + inline(n.Content) + "\n\n")
+		case "paragraph":
+			if text := inline(n.Content); text != "" {
+				sb.WriteString(indent + strings.ReplaceAll(text, "\n", "\n"+indent) + "\n\n")
+			}
+		case "bullet_list", "ordered_list", "check_list", "bulletList", "orderedList", "taskList":
+			writeList(sb, n, indent)
+			sb.WriteString("\n")
+		case "blockquote", "call_out_box", "panel":
+			writeBlocks(sb, n.Content, indent+"> ")
+		case "code_block", "codeBlock":
+			language, _ := n.Attrs["language"].(string)
+			sb.WriteString(indent + "```" + language + "\n" + indent + strings.ReplaceAll(inline(n.Content), "\n", "\n"+indent) + "\n" + indent + "```\n\n")
+		case "horizontal_rule", "rule":
+			sb.WriteString(indent + "---\n\n")
+		case "table":
+			writeTable(sb, n, indent)
+		case "image":
+			if alt, _ := n.Attrs["alt"].(string); alt != "" {
+				sb.WriteString(indent + "[image: " + alt + "]\n\n")
+			}
+		case "mediaSingle", "mediaGroup":
+			for _, media := range n.Content {
+				if alt, _ := media.Attrs["alt"].(string); alt != "" {
+					sb.WriteString(indent + "[attachment: " + alt + "]\n\n")
+				}
+			}
+		case "blockCard", "embedCard":
+			if url, _ := n.Attrs["url"].(string); url != "" {
+				sb.WriteString(indent + url + "\n\n")
+			}
+		case "text", "hard_break", "hardBreak", "mention", "emoji", "inlineCard", "date", "status":
+			sb.WriteString(indent + inline([]RichTextNode{n}) + "\n\n")
+		default:
+			// e.g. expand, nestedExpand, layoutSection and layoutColumn
+			if title, _ := n.Attrs["title"].(string); title != "" {
+				sb.WriteString(indent + "**" + title + "**\n\n")
+			}
+			writeBlocks(sb, n.Content, indent)
+		}
+	}
+}
+
+func writeList(sb *strings.Builder, list RichTextNode, indent string) {
+	for i, item := range list.Content {
+		marker := "- "
+		switch list.Type {
+		case "ordered_list", "orderedList":
+			start := 1
+			if order, ok := list.Attrs["order"].(float64); ok {
+				start = int(order)
+			}
+			marker = fmt.Sprintf("%d. ", start+i)
+		case "check_list", "taskList":
+			checked, _ := item.Attrs["checked"].(bool)
+			if state, _ := item.Attrs["state"].(string); checked || state == "DONE" {
+				marker = "- [x] "
+			} else {
+				marker = "- [ ] "
+			}
+		}
+
+		// ADF task items contain the inline nodes directly, list items contain paragraphs
+		children := item.Content
+		if item.Type == "taskItem" {
+			children = []RichTextNode{{Type: "paragraph", Content: item.Content}}
+		}
+
+		first := true
+		for _, child := range children {
+			switch child.Type {
+			case "bullet_list", "ordered_list", "check_list", "bulletList", "orderedList", "taskList":
+				writeList(sb, child, indent+"  ")
+			default:
+				text := inline(child.Content)
+				if child.Type == "text" {
+					text = inline([]RichTextNode{child})
+				}
+				if first {
+					sb.WriteString(indent + marker + text + "\n")
+					first = false
+				} else if text != "" {
+					sb.WriteString(indent + "  " + text + "\n")
+				}
+			}
+		}
+		if first {
+			sb.WriteString(indent + marker + "\n")
+		}
+	}
+}
+
+func writeTable(sb *strings.Builder, table RichTextNode, indent string) {
+	for i, row := range table.Content {
+		cells := make([]string, 0, len(row.Content))
+		for _, cell := range row.Content {
+			var parts []string
+			for _, block := range cell.Content {
+				if text := inline(block.Content); text != "" {
+					parts = append(parts, text)
+				}
+			}
+			cells = append(cells, strings.ReplaceAll(strings.ReplaceAll(strings.Join(parts, " "), "|", "\\|"), "\n", " "))
+		}
+		sb.WriteString(indent + "| " + strings.Join(cells, " | ") + " |\n")
+		if i == 0 {
+			sb.WriteString(indent + "|" + strings.Repeat(" --- |", len(cells)) + "\n")
+		}
+	}
+	sb.WriteString("\n")
+}
+
+// inline renders text nodes with their marks, and the inline nodes that have a text representation
+func inline(nodes []RichTextNode) string {
+	var sb strings.Builder
+	for _, n := range nodes {
+		switch n.Type {
+		case "text":
+			sb.WriteString(applyMarks(n.Text, n.Marks))
+		case "hard_break", "hardBreak":
+			sb.WriteString("\n")
+		case "mention":
+			text, _ := n.Attrs["text"].(string)
+			if text != "" && !strings.HasPrefix(text, "@") {
+				text = "@" + text
+			}
+			sb.WriteString(text)
+		case "emoji":
+			if text, _ := n.Attrs["text"].(string); text != "" {
+				sb.WriteString(text)
+			} else {
+				shortName, _ := n.Attrs["shortName"].(string)
+				sb.WriteString(shortName)
+			}
+		case "inlineCard":
+			url, _ := n.Attrs["url"].(string)
+			sb.WriteString(url)
+		case "date":
+			timestamp, _ := n.Attrs["timestamp"].(string)
+			sb.WriteString(formatUnixMilli(timestamp))
+		case "status":
+			text, _ := n.Attrs["text"].(string)
+			sb.WriteString("[" + text + "]")
+		default:
+			sb.WriteString(inline(n.Content))
+		}
+	}
+	return sb.String()
+}
+
+func applyMarks(text string, marks []RichTextNode) string {
+	if strings.TrimSpace(text) == "" {
+		return text
+	}
+	for _, m := range marks {
+		switch m.Type {
+		case "strong":
+			text = "**" + text + "**"
+		case "em":
+			text = "_" + text + "_"
+		case "strikethrough", "strike":
+			text = "~~" + text + "~~"
+		case "code":
+			text = "`" + text + "`"
+		case "link":
+			if href, _ := m.Attrs["href"].(string); href != "" {
+				text = "[" + text + "](" + href + ")"
+			}
+		}
+	}
+	return text
+}
+
+// formatUnixMilli formats a Unix timestamp in milliseconds (as used by ADF date nodes) as a date. Invalid
+// timestamps are returned unchanged.
+func formatUnixMilli(ms string) string {
+	n, err := strconv.ParseInt(ms, 10, 64)
+	if err != nil {
+		return ms
+	}
+	return time.UnixMilli(n).UTC().Format("2006-01-02")
+}
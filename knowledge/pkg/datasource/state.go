@@ -0,0 +1,47 @@
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// State is the sync state of a data source.
+type State struct {
+	// Files are the synced files by item ID
+	Files map[string]FileState `json:"files,omitempty"`
+	// Cursor is where the next incremental sync continues, only used for sources implementing ChangeTracker
+	Cursor string `json:"cursor,omitempty"`
+	// Pending are the items that failed to sync by item ID. The cursor already moved past their changes, so the next
+	// incremental sync fetches them again before it lists the new changes.
+	Pending map[string]Item `json:"pending,omitempty"`
+	// Config is the configuration of the last sync (see Options.Config), all items are listed again if it changes
+	Config string `json:"config,omitempty"`
+	// Source is the state of sources implementing StatefulSource
+	Source json.RawMessage `json:"source,omitempty"`
+
+	LastSync     time.Time `json:"-"`
+	LastFullSync time.Time `json:"-"`
+	// LastAttempt is the start of the last sync, successful or not
+	LastAttempt time.Time `json:"lastAttempt,omitempty"`
+	// Failures is the number of consecutive failed syncs
+	Failures  int    `json:"-"`
+	LastError string `json:"-"`
+}
+
+// FileState is a synced file. It uses the same fields as the files in the metadata of all data sources.
+type FileState struct {
+	FilePath    string            `json:"filePath"`
+	URL         string            `json:"url"`
+	SizeInBytes int64             `json:"sizeInBytes"`
+	UpdatedAt   string            `json:"updatedAt"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// StateStore persists the sync state of data sources. The index DB keeps the state of the sources synced by the
+// knowledge server (see the indexstore package), the MetadataStore that of the data source tools.
+type StateStore interface {
+	// Load returns the state of the source, or an empty state if it never synced
+	Load(ctx context.Context, source string) (*State, error)
+	Save(ctx context.Context, source string, state *State) error
+}
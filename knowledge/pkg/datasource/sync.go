@@ -0,0 +1,401 @@
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	defaultInterval         = time.Hour
+	defaultFullSyncInterval = 24 * time.Hour
+	// statusInterval is the minimum time between two writes of the metadata while files are synced
+	statusInterval = 5 * time.Second
+)
+
+// Options configure the Syncer. The zero value uses the defaults.
+type Options struct {
+	// Interval is the time between two syncs, defaults to 1h
+	Interval time.Duration
+	// FullSyncInterval is the time after which all items are listed again, even if the source implements
+	// ChangeTracker, so missed changes are eventually corrected. Defaults to 24h.
+	FullSyncInterval time.Duration
+	// Config describes the configuration of the source, e.g. the folders to sync. All items are listed again when
+	// it changes.
+	Config string
+	// Retry is the retry policy of the requests to the source
+	Retry RetryPolicy
+	// MetadataPath is the file in the workspace with the status and the synced files, defaults to .metadata.json
+	MetadataPath string
+	Logger       *slog.Logger
+}
+
+// Syncer syncs the items of a data source into the workspace.
+type Syncer struct {
+	Source    DataSource
+	Store     StateStore
+	Workspace Workspace
+	Options
+
+	// paths maps the file paths of the current sync to the IDs of their items
+	paths map[string]string
+	// statusWritten is when the metadata was written last
+	statusWritten time.Time
+}
+
+// Result summarizes a sync.
+type Result struct {
+	Full    bool `json:"full"`
+	Written int  `json:"written"`
+	Skipped int  `json:"skipped"`
+	Deleted int  `json:"deleted"`
+	// Failed are the items that could not be fetched or written, they are retried by the next sync
+	Failed int `json:"failed"`
+}
+
+func NewSyncer(source DataSource, store StateStore, workspace Workspace, opts Options) *Syncer {
+	return &Syncer{
+		Source:    source,
+		Store:     store,
+		Workspace: workspace,
+		Options:   opts,
+	}
+}
+
+// NextSync returns when the source should sync next: after the interval, or after the backoff of the retry policy
+// if the last sync failed.
+func (s *Syncer) NextSync(state *State) time.Time {
+	if state.LastAttempt.IsZero() {
+		return time.Time{}
+	}
+	if state.Failures > 0 {
+		return state.LastAttempt.Add(s.Retry.backoff(state.Failures))
+	}
+	return state.LastAttempt.Add(s.interval())
+}
+
+// Due returns whether the source should sync now.
+func (s *Syncer) Due(ctx context.Context, now time.Time) (bool, error) {
+	state, err := s.Store.Load(ctx, s.Source.Name())
+	if err != nil {
+		return false, err
+	}
+	return !now.Before(s.NextSync(state)), nil
+}
+
+// Sync lists all items, or only the changes since the last sync if possible, and writes the new and changed items
+// to the workspace. Files of deleted items are removed. The state is saved after each page, so an interrupted sync
+// continues where it stopped.
+func (s *Syncer) Sync(ctx context.Context) (*Result, error) {
+	name := s.Source.Name()
+	state, err := s.Store.Load(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if stateful, ok := s.Source.(StatefulSource); ok {
+		if err := stateful.LoadState(state.Source); err != nil {
+			return nil, fmt.Errorf("failed to load state of %s: %w", name, err)
+		}
+	}
+
+	s.statusWritten = time.Time{}
+	s.paths = make(map[string]string, len(state.Files))
+	for id, file := range state.Files {
+		s.paths[file.FilePath] = id
+	}
+
+	startedAt := time.Now().UTC()
+	state.LastAttempt = startedAt
+
+	tracker, incremental := s.Source.(ChangeTracker)
+	result := &Result{
+		Full: !incremental || state.Cursor == "" || state.Config != s.Config || state.LastFullSync.IsZero() ||
+			startedAt.Sub(state.LastFullSync) > s.fullSyncInterval(),
+	}
+	s.logger().Info("Syncing data source", "source", name, "full", result.Full)
+
+	if !result.Full {
+		err = s.incrementalSync(ctx, tracker, state, result)
+		if errors.Is(err, ErrFullSyncRequired) {
+			s.logger().Info("Changes can't be listed, listing all items", "source", name, "reason", err)
+			result.Full = true
+		}
+	}
+	if result.Full {
+		err = s.fullSync(ctx, tracker, state, result)
+	}
+	if err != nil {
+		state.Failures++
+		state.LastError = err.Error()
+		if saveErr := s.save(ctx, state); saveErr != nil {
+			s.logger().Error("Failed to save sync state", "source", name, "error", saveErr)
+		}
+		_ = s.writeMetadata(ctx, state, "Sync failed: "+err.Error())
+		return result, err
+	}
+
+	if result.Full {
+		state.LastFullSync = startedAt
+	}
+	state.Config = s.Config
+	state.LastSync = startedAt
+	state.Failures = 0
+	state.LastError = ""
+	if err := s.save(ctx, state); err != nil {
+		return result, err
+	}
+	if err := s.writeMetadata(ctx, state, ""); err != nil {
+		return result, err
+	}
+
+	s.logger().Info("Synced data source", "source", name, "full", result.Full, "written", result.Written,
+		"skipped", result.Skipped, "deleted", result.Deleted, "failed", result.Failed)
+	return result, nil
+}
+
+// fullSync lists all items and removes the files of the items that are gone, unless the source retains them
+func (s *Syncer) fullSync(ctx context.Context, tracker ChangeTracker, state *State, result *Result) error {
+	var cursor string
+	if tracker != nil {
+		if err := s.Retry.Do(ctx, func() (err error) {
+			cursor, err = tracker.Cursor(ctx)
+			return err
+		}); err != nil {
+			return fmt.Errorf("failed to get cursor: %w", err)
+		}
+	}
+
+	seen := map[string]struct{}{}
+	if err := s.Retry.Do(ctx, func() error {
+		return s.Source.List(ctx, func(items []Item) error {
+			for _, item := range items {
+				seen[item.ID] = struct{}{}
+				if err := s.syncItem(ctx, state, item, result); err != nil {
+					return err
+				}
+			}
+			return s.save(ctx, state)
+		})
+	}); err != nil {
+		return fmt.Errorf("failed to list items: %w", err)
+	}
+
+	// Pending items that weren't listed are gone
+	for id := range state.Pending {
+		if _, ok := seen[id]; !ok {
+			delete(state.Pending, id)
+		}
+	}
+
+	retainer, _ := s.Source.(Retainer)
+	for id, file := range state.Files {
+		if _, ok := seen[id]; ok || (retainer != nil && retainer.Retain(id, file)) {
+			continue
+		}
+		if err := s.deleteItem(ctx, state, id, result); err != nil {
+			return err
+		}
+	}
+	state.Cursor = cursor
+	return nil
+}
+
+// incrementalSync syncs the items that failed in the last sync again, then applies the changes since the cursor of
+// the last sync
+func (s *Syncer) incrementalSync(ctx context.Context, tracker ChangeTracker, state *State, result *Result) error {
+	if len(state.Pending) > 0 {
+		for _, id := range slices.Sorted(maps.Keys(state.Pending)) {
+			if err := s.syncItem(ctx, state, state.Pending[id], result); err != nil {
+				return err
+			}
+		}
+		if err := s.save(ctx, state); err != nil {
+			return err
+		}
+	}
+
+	if err := s.Retry.Do(ctx, func() error {
+		return tracker.Changes(ctx, state.Cursor, func(changes []Change, cursor string) error {
+			for _, change := range changes {
+				var err error
+				if change.Deleted {
+					err = s.deleteItem(ctx, state, change.ID, result)
+				} else {
+					err = s.syncItem(ctx, state, change.Item, result)
+				}
+				if err != nil {
+					return err
+				}
+			}
+			state.Cursor = cursor
+			return s.save(ctx, state)
+		})
+	}); err != nil {
+		return fmt.Errorf("failed to get changes: %w", err)
+	}
+	return nil
+}
+
+// syncItem fetches the item and writes it to the workspace, unless its version didn't change. Items that fail to
+// fetch are logged, counted and kept in the pending items of the state, they are fetched again by the next sync.
+// Only errors of the workspace abort the sync.
+func (s *Syncer) syncItem(ctx context.Context, state *State, item Item, result *Result) error {
+	filePath, err := s.cleanPath(item.Path)
+	if err != nil {
+		s.logger().Error("Skipping item", "source", s.Source.Name(), "id", item.ID, "error", err)
+		s.failItem(state, item, result)
+		return nil
+	}
+	filePath = s.uniquePath(item.ID, filePath)
+
+	existing, ok := state.Files[item.ID]
+	if ok && item.Version != "" && existing.UpdatedAt == item.Version && existing.FilePath == filePath {
+		delete(state.Pending, item.ID)
+		result.Skipped++
+		return nil
+	}
+
+	var data []byte
+	if err := s.Retry.Do(ctx, func() (err error) {
+		data, err = s.Source.Fetch(ctx, item)
+		return err
+	}); err != nil {
+		s.logger().Error("Failed to fetch item", "source", s.Source.Name(), "id", item.ID, "path", filePath, "error", err)
+		s.failItem(state, item, result)
+		return nil
+	}
+
+	// Moved or renamed
+	if ok && existing.FilePath != filePath {
+		if err := s.Workspace.DeleteFile(ctx, existing.FilePath); err != nil {
+			return err
+		}
+		delete(s.paths, existing.FilePath)
+	}
+	if err := s.Workspace.WriteFile(ctx, filePath, data); err != nil {
+		return err
+	}
+	s.paths[filePath] = item.ID
+
+	state.Files[item.ID] = FileState{
+		FilePath:    filePath,
+		URL:         item.URL,
+		SizeInBytes: int64(len(data)),
+		UpdatedAt:   item.Version,
+		Metadata:    item.Metadata,
+	}
+	delete(state.Pending, item.ID)
+	result.Written++
+	// The metadata lists all files, writing it for every file would make large syncs quadratic
+	if time.Since(s.statusWritten) < statusInterval {
+		return nil
+	}
+	return s.writeMetadata(ctx, state, "Syncing file "+filePath)
+}
+
+// failItem counts the item as failed and keeps it, so the next sync fetches it again
+func (s *Syncer) failItem(state *State, item Item, result *Result) {
+	if state.Pending == nil {
+		state.Pending = make(map[string]Item)
+	}
+	state.Pending[item.ID] = item
+	result.Failed++
+}
+
+func (s *Syncer) deleteItem(ctx context.Context, state *State, id string, result *Result) error {
+	delete(state.Pending, id)
+	file, ok := state.Files[id]
+	if !ok {
+		return nil
+	}
+	if err := s.Workspace.DeleteFile(ctx, file.FilePath); err != nil {
+		return err
+	}
+	delete(s.paths, file.FilePath)
+	delete(state.Files, id)
+	result.Deleted++
+	return nil
+}
+
+// save saves the sync state together with the state of the source
+func (s *Syncer) save(ctx context.Context, state *State) error {
+	if stateful, ok := s.Source.(StatefulSource); ok {
+		data, err := stateful.SaveState()
+		if err != nil {
+			return fmt.Errorf("failed to save state of %s: %w", s.Source.Name(), err)
+		}
+		state.Source = data
+	}
+	return s.Store.Save(ctx, s.Source.Name(), state)
+}
+
+// writeMetadata writes the status, the synced files and the sync state to the metadata file
+func (s *Syncer) writeMetadata(ctx context.Context, state *State, status string) error {
+	data, err := json.MarshalIndent(newMetadata(status, state), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := s.Workspace.WriteFile(ctx, s.metadataPath(), data); err != nil {
+		return err
+	}
+	s.statusWritten = time.Now()
+	return nil
+}
+
+func (s *Syncer) metadataPath() string {
+	if s.MetadataPath != "" {
+		return s.MetadataPath
+	}
+	return defaultMetadataPath
+}
+
+func (s *Syncer) interval() time.Duration {
+	if s.Interval > 0 {
+		return s.Interval
+	}
+	return defaultInterval
+}
+
+func (s *Syncer) fullSyncInterval() time.Duration {
+	if s.FullSyncInterval > 0 {
+		return s.FullSyncInterval
+	}
+	return defaultFullSyncInterval
+}
+
+func (s *Syncer) logger() *slog.Logger {
+	if s.Logger != nil {
+		return s.Logger
+	}
+	return slog.Default()
+}
+
+// uniquePath appends the item ID to the file name if another item already uses the path, e.g. two documents with the
+// same title in the same folder
+func (s *Syncer) uniquePath(id, filePath string) string {
+	if otherID, ok := s.paths[filePath]; !ok || otherID == id {
+		return filePath
+	}
+	ext := path.Ext(filePath)
+	return strings.TrimSuffix(filePath, ext) + "-" + SanitizeName(id) + ext
+}
+
+// cleanPath makes sure that the file of an item stays in the workspace and doesn't replace the metadata
+func (s *Syncer) cleanPath(p string) (string, error) {
+	if strings.TrimSpace(p) == "" {
+		return "", errors.New("empty path")
+	}
+	cleaned := path.Clean("/" + strings.ReplaceAll(p, "\\", "/"))[1:]
+	if cleaned == "" || cleaned == path.Clean(s.metadataPath()) {
+		return "", fmt.Errorf("invalid path %q", p)
+	}
+	return cleaned, nil
+}
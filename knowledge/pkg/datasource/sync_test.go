@@ -0,0 +1,369 @@
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSource struct {
+	items   map[string]Item
+	content map[string]string
+	// fetchErrs are returned by Fetch, by item ID, until they are used up
+	fetchErrs map[string][]error
+	fetches   int
+
+	// changes are reported by trackingSource, which implements ChangeTracker
+	changes []Change
+	cursor  int
+	// expired makes trackingSource report that the cursor can't be used anymore
+	expired bool
+}
+
+func (s *testSource) Name() string {
+	return "test"
+}
+
+func (s *testSource) List(_ context.Context, fn func([]Item) error) error {
+	ids := slices.Sorted(maps.Keys(s.items))
+	items := make([]Item, 0, len(ids))
+	for _, id := range ids {
+		items = append(items, s.items[id])
+	}
+	return fn(items)
+}
+
+func (s *testSource) Fetch(_ context.Context, item Item) ([]byte, error) {
+	s.fetches++
+	if errs := s.fetchErrs[item.ID]; len(errs) > 0 {
+		s.fetchErrs[item.ID] = errs[1:]
+		return nil, errs[0]
+	}
+	return []byte(s.content[item.ID]), nil
+}
+
+type trackingSource struct {
+	*testSource
+}
+
+func (s trackingSource) Cursor(context.Context) (string, error) {
+	return strconv.Itoa(s.cursor), nil
+}
+
+func (s trackingSource) Changes(_ context.Context, cursor string, fn func([]Change, string) error) error {
+	if s.expired {
+		return fmt.Errorf("cursor %s: %w", cursor, ErrFullSyncRequired)
+	}
+	start, err := strconv.Atoi(cursor)
+	if err != nil {
+		return Permanent(err)
+	}
+	for i := start; i < len(s.changes); i++ {
+		if err := fn(s.changes[i:i+1], strconv.Itoa(i+1)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type testWorkspace struct {
+	files map[string]string
+	// writes counts the writes by path
+	writes map[string]int
+}
+
+func (w *testWorkspace) ReadFile(_ context.Context, path string) ([]byte, error) {
+	data, ok := w.files[path]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	return []byte(data), nil
+}
+
+func (w *testWorkspace) WriteFile(_ context.Context, path string, data []byte) error {
+	w.files[path] = string(data)
+	w.writes[path]++
+	return nil
+}
+
+func (w *testWorkspace) DeleteFile(_ context.Context, path string) error {
+	delete(w.files, path)
+	return nil
+}
+
+func newTestSyncer(source DataSource) (*Syncer, *testWorkspace) {
+	workspace := &testWorkspace{files: map[string]string{}, writes: map[string]int{}}
+	return NewSyncer(source, NewMetadataStore(workspace), workspace, Options{
+		Retry: RetryPolicy{Backoff: time.Millisecond},
+	}), workspace
+}
+
+func TestFullSync(t *testing.T) {
+	ctx := context.Background()
+	source := &testSource{
+		items: map[string]Item{
+			"1": {ID: "1", Path: "docs/a.md", Version: "v1"},
+			"2": {ID: "2", Path: "docs/b.md", Version: "v1"},
+			"3": {ID: "3", Path: "../../etc/c.md", Version: "v1"},
+		},
+		content: map[string]string{"1": "a", "2": "b", "3": "c"},
+	}
+	syncer, workspace := newTestSyncer(source)
+
+	result, err := syncer.Sync(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &Result{Full: true, Written: 3}, result)
+	assert.Equal(t, "a", workspace.files["docs/a.md"])
+	assert.Equal(t, "c", workspace.files["etc/c.md"], "paths must stay in the workspace")
+
+	var meta metadata
+	require.NoError(t, json.Unmarshal([]byte(workspace.files[".metadata.json"]), &meta))
+	assert.Equal(t, "", meta.Status)
+	assert.Equal(t, FileState{FilePath: "docs/b.md", SizeInBytes: 1, UpdatedAt: "v1"}, meta.Files["2"])
+	assert.NotEmpty(t, meta.State.LastFullSync)
+
+	// Unchanged items are skipped, changed items are fetched again, moved items are renamed and deleted items removed
+	source.items["1"] = Item{ID: "1", Path: "docs/a.md", Version: "v2"}
+	source.items["2"] = Item{ID: "2", Path: "archive/b.md", Version: "v1"}
+	delete(source.items, "3")
+	source.content["1"] = "a2"
+
+	result, err = syncer.Sync(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &Result{Full: true, Written: 2, Deleted: 1}, result)
+	assert.Equal(t, map[string]string{
+		"docs/a.md":      "a2",
+		"archive/b.md":   "b",
+		".metadata.json": workspace.files[".metadata.json"],
+	}, workspace.files)
+}
+
+func TestIncrementalSync(t *testing.T) {
+	ctx := context.Background()
+	source := &testSource{
+		items:   map[string]Item{"1": {ID: "1", Path: "a.md", Version: "v1"}},
+		content: map[string]string{"1": "a", "2": "b"},
+	}
+	syncer, workspace := newTestSyncer(trackingSource{source})
+
+	result, err := syncer.Sync(ctx)
+	require.NoError(t, err)
+	assert.True(t, result.Full)
+
+	source.changes = []Change{
+		{Item: Item{ID: "2", Path: "b.md", Version: "v1"}},
+		{Item: Item{ID: "1"}, Deleted: true},
+	}
+	result, err = syncer.Sync(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &Result{Written: 1, Deleted: 1}, result)
+	assert.Equal(t, "b", workspace.files["b.md"])
+	assert.NotContains(t, workspace.files, "a.md")
+
+	state, err := syncer.Store.Load(ctx, "test")
+	require.NoError(t, err)
+	assert.Equal(t, "2", state.Cursor)
+	assert.Equal(t, []string{"2"}, slices.Collect(maps.Keys(state.Files)))
+
+	// Items that fail are fetched again by the next sync, although the cursor moved past their changes
+	source.changes = append(source.changes, Change{Item: Item{ID: "4", Path: "d.md", Version: "v1"}})
+	source.content["4"] = "d"
+	source.fetchErrs = map[string][]error{"4": {Permanent(errors.New("access denied"))}}
+	result, err = syncer.Sync(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &Result{Failed: 1}, result)
+	assert.NotContains(t, workspace.files, "d.md")
+	state, err = syncer.Store.Load(ctx, "test")
+	require.NoError(t, err)
+	assert.Equal(t, "3", state.Cursor)
+	assert.Equal(t, []string{"4"}, slices.Collect(maps.Keys(state.Pending)))
+
+	result, err = syncer.Sync(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &Result{Written: 1}, result)
+	assert.Equal(t, "d", workspace.files["d.md"])
+	state, err = syncer.Store.Load(ctx, "test")
+	require.NoError(t, err)
+	assert.Equal(t, "3", state.Cursor)
+	assert.Empty(t, state.Pending)
+
+	// A changed configuration lists all items again
+	syncer.Config = "folders=b"
+	result, err = syncer.Sync(ctx)
+	require.NoError(t, err)
+	assert.True(t, result.Full)
+	state, err = syncer.Store.Load(ctx, "test")
+	require.NoError(t, err)
+	assert.Equal(t, "folders=b", state.Config)
+	assert.Empty(t, state.LastError)
+
+	// Sources list all items if the cursor can't be used
+	source.expired = true
+	source.items = map[string]Item{"3": {ID: "3", Path: "c.md", Version: "v1"}}
+	source.content["3"] = "c"
+	syncer.Config = "folders=b"
+	result, err = syncer.Sync(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &Result{Full: true, Written: 1, Deleted: 1}, result)
+	assert.Equal(t, "c", workspace.files["c.md"])
+}
+
+func TestSyncRetries(t *testing.T) {
+	ctx := context.Background()
+	source := &testSource{
+		items: map[string]Item{
+			"1": {ID: "1", Path: "a.md", Version: "v1"},
+			"2": {ID: "2", Path: "b.md", Version: "v1"},
+		},
+		content: map[string]string{"1": "a", "2": "b"},
+		fetchErrs: map[string][]error{
+			// Transient errors are retried
+			"1": {errors.New("timeout"), &RetryAfterError{Err: errors.New("rate limited"), After: time.Millisecond}},
+			// Permanent errors are not retried, the item is fetched again by the next sync
+			"2": {Permanent(errors.New("access denied"))},
+		},
+	}
+	syncer, workspace := newTestSyncer(source)
+
+	result, err := syncer.Sync(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &Result{Full: true, Written: 1, Failed: 1}, result)
+	assert.Equal(t, 4, source.fetches)
+	assert.Equal(t, "a", workspace.files["a.md"])
+	assert.NotContains(t, workspace.files, "b.md")
+
+	result, err = syncer.Sync(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &Result{Full: true, Written: 1, Skipped: 1}, result)
+	assert.Equal(t, "b", workspace.files["b.md"])
+}
+
+func TestNextSync(t *testing.T) {
+	ctx := context.Background()
+	source := &testSource{}
+	syncer, workspace := newTestSyncer(trackingSource{source})
+	syncer.Interval = time.Hour
+	syncer.Retry = RetryPolicy{Attempts: 1, Backoff: time.Minute, MaxBackoff: 10 * time.Minute}
+
+	due, err := syncer.Due(ctx, time.Now())
+	require.NoError(t, err)
+	assert.True(t, due, "never synced")
+
+	_, err = syncer.Sync(ctx)
+	require.NoError(t, err)
+	state, err := syncer.Store.Load(ctx, "test")
+	require.NoError(t, err)
+	assert.Equal(t, state.LastAttempt.Add(time.Hour), syncer.NextSync(state))
+
+	// Failed syncs are retried with exponential backoff
+	for failures, backoff := range map[int]time.Duration{1: time.Minute, 2: 2 * time.Minute, 4: 8 * time.Minute, 5: 10 * time.Minute} {
+		state.Failures = failures
+		assert.Equal(t, state.LastAttempt.Add(backoff), syncer.NextSync(state), "failures: %d", failures)
+	}
+
+	state.Cursor = "x"
+	state.Failures = 0
+	require.NoError(t, syncer.Store.Save(ctx, "test", state))
+	_, err = syncer.Sync(ctx)
+	require.Error(t, err)
+	state, err = syncer.Store.Load(ctx, "test")
+	require.NoError(t, err)
+	assert.Equal(t, 1, state.Failures)
+	assert.Contains(t, state.LastError, "invalid syntax")
+	assert.Contains(t, workspace.files[".metadata.json"], `"status": "Sync failed: `)
+}
+
+// feedSource retains the items it no longer lists and remembers the items it saw, like a feed
+type feedSource struct {
+	*testSource
+	seen []string
+}
+
+func (s *feedSource) Retain(id string, _ FileState) bool {
+	return id != "expired"
+}
+
+func (s *feedSource) LoadState(data json.RawMessage) error {
+	s.seen = nil
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, &s.seen)
+}
+
+func (s *feedSource) SaveState() (json.RawMessage, error) {
+	return json.Marshal(slices.Sorted(maps.Keys(s.items)))
+}
+
+func TestSyncSourceState(t *testing.T) {
+	ctx := context.Background()
+	source := &feedSource{testSource: &testSource{
+		items: map[string]Item{
+			"1":       {ID: "1", Path: "a.md", Version: "v1"},
+			"expired": {ID: "expired", Path: "b.md", Version: "v1"},
+		},
+		content: map[string]string{"1": "a", "expired": "b"},
+	}}
+	syncer, workspace := newTestSyncer(source)
+
+	_, err := syncer.Sync(ctx)
+	require.NoError(t, err)
+
+	// Items that are no longer listed are only deleted if the source doesn't retain them
+	source.items = map[string]Item{}
+	result, err := syncer.Sync(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &Result{Full: true, Deleted: 1}, result)
+	assert.Equal(t, "a", workspace.files["a.md"])
+	assert.NotContains(t, workspace.files, "b.md")
+	assert.Equal(t, []string{"1", "expired"}, source.seen, "the state is restored before each sync")
+}
+
+func TestSyncUniquePaths(t *testing.T) {
+	ctx := context.Background()
+	source := &testSource{
+		items: map[string]Item{
+			"1": {ID: "1", Path: "docs/notes.md", Version: "v1"},
+			"2": {ID: "2", Path: "docs/notes.md", Version: "v1"},
+		},
+		content: map[string]string{"1": "a", "2": "b"},
+	}
+	syncer, workspace := newTestSyncer(source)
+
+	_, err := syncer.Sync(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "a", workspace.files["docs/notes.md"])
+	assert.Equal(t, "b", workspace.files["docs/notes-2.md"])
+
+	// The paths are stable between syncs
+	result, err := syncer.Sync(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &Result{Full: true, Skipped: 2}, result)
+}
+
+func TestSyncThrottlesMetadata(t *testing.T) {
+	ctx := context.Background()
+	source := &testSource{items: map[string]Item{}, content: map[string]string{}}
+	for i := range 100 {
+		id := strconv.Itoa(i)
+		source.items[id] = Item{ID: id, Path: id + ".md", Version: "v1"}
+		source.content[id] = id
+	}
+	syncer, workspace := newTestSyncer(source)
+
+	result, err := syncer.Sync(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 100, result.Written)
+	// The status of the first file, the state of the page and the final state and status
+	assert.Equal(t, 4, workspace.writes[".metadata.json"])
+}
@@ -0,0 +1,45 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/gptscript-ai/go-gptscript"
+)
+
+// Workspace is where the synced files are written to.
+type Workspace interface {
+	// ReadFile returns the content of the file, or an error wrapping fs.ErrNotExist if it doesn't exist
+	ReadFile(ctx context.Context, path string) ([]byte, error)
+	WriteFile(ctx context.Context, path string, data []byte) error
+	DeleteFile(ctx context.Context, path string) error
+}
+
+// GPTScriptWorkspace writes the files into the workspace of the gptscript run, like all data source tools do.
+type GPTScriptWorkspace struct {
+	Client *gptscript.GPTScript
+}
+
+func (w *GPTScriptWorkspace) ReadFile(ctx context.Context, path string) ([]byte, error) {
+	data, err := w.Client.ReadFileInWorkspace(ctx, path)
+	var notFoundErr *gptscript.NotFoundInWorkspaceError
+	if errors.As(err, &notFoundErr) {
+		return nil, fmt.Errorf("%w: %w", fs.ErrNotExist, err)
+	}
+	return data, err
+}
+
+func (w *GPTScriptWorkspace) WriteFile(ctx context.Context, path string, data []byte) error {
+	return w.Client.WriteFileInWorkspace(ctx, path, data)
+}
+
+// DeleteFile removes the file, files that don't exist are ignored
+func (w *GPTScriptWorkspace) DeleteFile(ctx context.Context, path string) error {
+	var notFoundErr *gptscript.NotFoundInWorkspaceError
+	if err := w.Client.DeleteFileInWorkspace(ctx, path); err != nil && !errors.As(err, &notFoundErr) {
+		return err
+	}
+	return nil
+}
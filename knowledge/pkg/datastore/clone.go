@@ -0,0 +1,150 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"reflect"
+
+	"github.com/gptscript-ai/knowledge/pkg/index/types"
+)
+
+type MergeDatasetsResult struct {
+	FilesCopied     int `json:"files_copied"`
+	FilesSkipped    int `json:"files_skipped"` // already present in the target dataset
+	DocumentsCopied int `json:"documents_copied"`
+}
+
+// CloneDataset creates the target dataset with the embeddings config and metadata of the source dataset and
+// copies all files, documents and vectors into it.
+func (s *Datastore) CloneDataset(ctx context.Context, sourceID, targetID string) (*MergeDatasetsResult, error) {
+	src, err := s.GetDataset(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	if src == nil {
+		return nil, fmt.Errorf("dataset not found: %s", sourceID)
+	}
+
+	target := types.Dataset{
+		ID:                       targetID,
+		EmbeddingsProviderConfig: src.EmbeddingsProviderConfig,
+		Metadata:                 maps.Clone(src.Metadata),
+	}
+	if err := s.CreateDataset(ctx, target, &types.DatasetCreateOpts{ErrOnExists: true}); err != nil {
+		return nil, fmt.Errorf("failed to create dataset %q: %w", targetID, err)
+	}
+
+	result, err := s.MergeDatasets(ctx, sourceID, targetID)
+	if err != nil {
+		// Don't leave a partial clone behind
+		if derr := s.DeleteDataset(ctx, targetID); derr != nil {
+			slog.Warn("Failed to remove partially cloned dataset", "dataset", targetID, "error", derr)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// MergeDatasets copies the files of the source dataset into the target dataset, keeping document metadata and
+// embeddings, so nothing has to be re-embedded. Files that already exist in the target dataset (same ID or
+// same absolute path) are skipped.
+func (s *Datastore) MergeDatasets(ctx context.Context, sourceID, targetID string) (*MergeDatasetsResult, error) {
+	if sourceID == targetID {
+		return nil, fmt.Errorf("source and target dataset must be different")
+	}
+
+	src, err := s.GetDataset(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	if src == nil {
+		return nil, fmt.Errorf("dataset not found: %s", sourceID)
+	}
+
+	target, err := s.GetDataset(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, fmt.Errorf("dataset not found: %s", targetID)
+	}
+
+	// Vectors of different embedding models are not comparable
+	if src.EmbeddingsProviderConfig != nil && target.EmbeddingsProviderConfig != nil && !reflect.DeepEqual(src.EmbeddingsProviderConfig, target.EmbeddingsProviderConfig) {
+		return nil, fmt.Errorf("datasets %q and %q use different embeddings configurations", sourceID, targetID)
+	}
+
+	existingIDs := make(map[string]struct{}, len(target.Files))
+	existingPaths := make(map[string]struct{}, len(target.Files))
+	for _, f := range target.Files {
+		existingIDs[f.ID] = struct{}{}
+		if f.AbsolutePath != "" {
+			existingPaths[f.AbsolutePath] = struct{}{}
+		}
+	}
+
+	result := &MergeDatasetsResult{}
+	handled := map[string]struct{}{} // survives retries of the locked operation
+
+	err = s.exclusive(ctx, []string{targetID}, func() error {
+		if target.EmbeddingsProviderConfig == nil && src.EmbeddingsProviderConfig != nil {
+			target.EmbeddingsProviderConfig = src.EmbeddingsProviderConfig
+			if err := s.Index.UpdateDataset(ctx, types.Dataset{ID: target.ID, EmbeddingsProviderConfig: target.EmbeddingsProviderConfig, Metadata: target.Metadata}); err != nil {
+				return fmt.Errorf("failed to update dataset %q: %w", targetID, err)
+			}
+		}
+
+		for _, file := range src.Files {
+			if _, ok := handled[file.ID]; ok {
+				continue
+			}
+			_, idExists := existingIDs[file.ID]
+			_, pathExists := existingPaths[file.AbsolutePath]
+			if idExists || (file.AbsolutePath != "" && pathExists) {
+				slog.Debug("Skipping file already present in target dataset", "file", file.ID, "absPath", file.AbsolutePath, "dataset", targetID)
+				handled[file.ID] = struct{}{}
+				result.FilesSkipped++
+				continue
+			}
+
+			docIDs := make([]string, len(file.Documents))
+			docs := make([]types.Document, len(file.Documents))
+			for i, doc := range file.Documents {
+				docIDs[i] = doc.ID
+				doc.Dataset = targetID
+				docs[i] = doc
+			}
+
+			if len(docIDs) > 0 {
+				// Some vector stores can't hold the same document ID in two collections and assign new ones
+				newIDs, err := s.Vectorstore.CopyDocuments(ctx, sourceID, targetID, docIDs...)
+				if err != nil {
+					return fmt.Errorf("failed to copy documents of file %q: %w", file.ID, err)
+				}
+				for i := range docs {
+					docs[i].ID = newIDs[i]
+				}
+			}
+
+			file.Dataset = targetID
+			file.Documents = docs
+			file.Metadata = maps.Clone(file.Metadata)
+			if err := s.Index.CreateFile(ctx, file); err != nil {
+				return fmt.Errorf("failed to create file %q in index: %w", file.ID, err)
+			}
+
+			handled[file.ID] = struct{}{}
+			result.FilesCopied++
+			result.DocumentsCopied += len(docs)
+		}
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	slog.Info("Merged datasets", "source", sourceID, "target", targetID, "filesCopied", result.FilesCopied, "filesSkipped", result.FilesSkipped, "documentsCopied", result.DocumentsCopied)
+	return result, nil
+}
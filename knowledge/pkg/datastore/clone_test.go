@@ -0,0 +1,148 @@
+package datastore
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gptscript-ai/knowledge/pkg/index"
+	"github.com/gptscript-ai/knowledge/pkg/index/types"
+	sqlitevec "github.com/gptscript-ai/knowledge/pkg/vectorstore/sqlite-vec"
+	vs "github.com/gptscript-ai/knowledge/pkg/vectorstore/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testEmbeddingFunc maps texts to fixed vectors and counts its calls, so tests can check that nothing is re-embedded
+type testEmbeddingFunc struct {
+	calls int
+}
+
+func (f *testEmbeddingFunc) embed(_ context.Context, text string) ([]float32, error) {
+	f.calls++
+	switch {
+	case strings.Contains(text, "apple"):
+		return []float32{1, 0, 0}, nil
+	case strings.Contains(text, "banana"):
+		return []float32{0, 1, 0}, nil
+	default:
+		return []float32{0, 0, 1}, nil
+	}
+}
+
+func newTestDatastore(t *testing.T) (*Datastore, *testEmbeddingFunc) {
+	t.Helper()
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	idx, err := index.New(ctx, "sqlite://"+filepath.Join(dir, "index.db"), true)
+	require.NoError(t, err)
+	require.NoError(t, idx.AutoMigrate())
+	t.Cleanup(func() { _ = idx.Close() })
+
+	ef := &testEmbeddingFunc{}
+	store, err := sqlitevec.New(ctx, "sqlite-vec://"+filepath.Join(dir, "vs.db"), ef.embed)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	return &Datastore{Index: idx, Vectorstore: store}, ef
+}
+
+// addTestFile adds a file with one document per content to the dataset, bypassing the ingestion flow
+func addTestFile(t *testing.T, ds *Datastore, datasetID, fileID, absPath string, contents ...string) {
+	t.Helper()
+	ctx := context.Background()
+
+	docs := make([]vs.Document, len(contents))
+	for i, c := range contents {
+		docs[i] = vs.Document{ID: fileID + "-" + string(rune('a'+i)), Content: c, Metadata: map[string]any{"fileID": fileID}}
+	}
+	ids, err := ds.Vectorstore.AddDocuments(ctx, docs, datasetID)
+	require.NoError(t, err)
+
+	file := types.File{ID: fileID, Dataset: datasetID, FileMetadata: types.FileMetadata{Name: filepath.Base(absPath), AbsolutePath: absPath}}
+	for i, id := range ids {
+		file.Documents = append(file.Documents, types.Document{ID: id, Dataset: datasetID, FileID: fileID, Index: i})
+	}
+	require.NoError(t, ds.Index.CreateFile(ctx, file))
+}
+
+func TestCloneDataset(t *testing.T) {
+	ctx := context.Background()
+	ds, ef := newTestDatastore(t)
+
+	require.NoError(t, ds.CreateDataset(ctx, types.Dataset{ID: "src", Metadata: map[string]any{"owner": "me"}}, nil))
+	addTestFile(t, ds, "src", "f1", "/docs/fruit.txt", "apple pie", "banana bread")
+
+	embedCalls := ef.calls
+	res, err := ds.CloneDataset(ctx, "src", "dst")
+	require.NoError(t, err)
+	assert.Equal(t, &MergeDatasetsResult{FilesCopied: 1, DocumentsCopied: 2}, res)
+	assert.Equal(t, embedCalls+1, ef.calls, "only the collection setup may embed")
+
+	dst, err := ds.GetDataset(ctx, "dst")
+	require.NoError(t, err)
+	require.NotNil(t, dst)
+	assert.Equal(t, "me", dst.Metadata["owner"])
+	require.Len(t, dst.Files, 1)
+	require.Len(t, dst.Files[0].Documents, 2)
+
+	// The index points at the copied documents, which carry the content and metadata of the originals
+	docs, err := ds.Vectorstore.GetDocuments(ctx, "dst", nil, nil)
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	byID := map[string]vs.Document{}
+	for _, d := range docs {
+		byID[d.ID] = d
+	}
+	for _, d := range dst.Files[0].Documents {
+		require.Contains(t, byID, d.ID)
+		assert.Equal(t, "f1", byID[d.ID].Metadata["fileID"])
+	}
+
+	// The copies keep their vectors
+	found, err := ds.Vectorstore.SimilaritySearch(ctx, "apple", 1, "dst", nil, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, "apple pie", found[0].Content)
+
+	// The source is untouched
+	docs, err = ds.Vectorstore.GetDocuments(ctx, "src", nil, nil)
+	require.NoError(t, err)
+	assert.Len(t, docs, 2)
+
+	_, err = ds.CloneDataset(ctx, "src", "dst")
+	assert.Error(t, err, "the target must not exist yet")
+}
+
+func TestMergeDatasets(t *testing.T) {
+	ctx := context.Background()
+	ds, _ := newTestDatastore(t)
+
+	require.NoError(t, ds.CreateDataset(ctx, types.Dataset{ID: "src"}, nil))
+	require.NoError(t, ds.CreateDataset(ctx, types.Dataset{ID: "dst"}, nil))
+	addTestFile(t, ds, "src", "f1", "/docs/fruit.txt", "apple pie", "banana bread")
+	addTestFile(t, ds, "src", "f2", "/docs/other.txt", "cherry")
+	addTestFile(t, ds, "dst", "f3", "/docs/other.txt", "cherry")
+
+	res, err := ds.MergeDatasets(ctx, "src", "dst")
+	require.NoError(t, err)
+	assert.Equal(t, &MergeDatasetsResult{FilesCopied: 1, FilesSkipped: 1, DocumentsCopied: 2}, res)
+
+	dst, err := ds.GetDataset(ctx, "dst")
+	require.NoError(t, err)
+	assert.Len(t, dst.Files, 2)
+
+	docs, err := ds.Vectorstore.GetDocuments(ctx, "dst", nil, nil)
+	require.NoError(t, err)
+	assert.Len(t, docs, 3)
+
+	// Merging again copies nothing
+	res, err = ds.MergeDatasets(ctx, "src", "dst")
+	require.NoError(t, err)
+	assert.Equal(t, &MergeDatasetsResult{FilesSkipped: 2}, res)
+
+	_, err = ds.MergeDatasets(ctx, "src", "src")
+	assert.Error(t, err)
+}
@@ -13,13 +13,15 @@ type UpdateDatasetOpts struct {
 }
 
 func (s *Datastore) CreateDataset(ctx context.Context, dataset types.Dataset, opts *types.DatasetCreateOpts) error {
-	// Create dataset
-	if err := s.Index.CreateDataset(ctx, dataset, opts); err != nil {
-		return err
-	}
-
-	// Create collection
-	err := s.Vectorstore.CreateCollection(ctx, dataset.ID, opts)
+	err := s.exclusive(ctx, []string{dataset.ID}, func() error {
+		// Create dataset
+		if err := s.Index.CreateDataset(ctx, dataset, opts); err != nil {
+			return err
+		}
+
+		// Create collection
+		return s.Vectorstore.CreateCollection(ctx, dataset.ID, opts)
+	})
 	if err != nil {
 		return err
 	}
@@ -28,17 +30,15 @@ func (s *Datastore) CreateDataset(ctx context.Context, dataset types.Dataset, op
 }
 
 func (s *Datastore) DeleteDataset(ctx context.Context, datasetID string) error {
-	// Delete dataset
-	if err := s.Index.DeleteDataset(ctx, datasetID); err != nil {
-		return err
-	}
-
-	// Delete collection
-	err := s.Vectorstore.RemoveCollection(ctx, datasetID)
-	if err != nil {
-		return err
-	}
-	return nil
+	return s.exclusive(ctx, []string{datasetID}, func() error {
+		// Delete dataset
+		if err := s.Index.DeleteDataset(ctx, datasetID); err != nil {
+			return err
+		}
+
+		// Delete collection
+		return s.Vectorstore.RemoveCollection(ctx, datasetID)
+	})
 }
 
 func (s *Datastore) GetDataset(ctx context.Context, datasetID string) (*types.Dataset, error) {
@@ -87,5 +87,7 @@ func (s *Datastore) UpdateDataset(ctx context.Context, updatedDataset types.Data
 
 	slog.Debug("Updating dataset", "id", updatedDataset.ID, "metadata", updatedDataset.Metadata, "embeddingsConfig", updatedDataset.EmbeddingsProviderConfig)
 
-	return origDS, s.Index.UpdateDataset(ctx, *origDS)
+	return origDS, s.exclusive(ctx, []string{origDS.ID}, func() error {
+		return s.Index.UpdateDataset(ctx, *origDS)
+	})
 }
@@ -13,11 +13,13 @@ import (
 
 	"github.com/gptscript-ai/knowledge/pkg/config"
 	etypes "github.com/gptscript-ai/knowledge/pkg/datastore/embeddings/types"
+	"github.com/gptscript-ai/knowledge/pkg/flock"
 	"github.com/gptscript-ai/knowledge/pkg/log"
 	"github.com/gptscript-ai/knowledge/pkg/output"
 
 	"github.com/adrg/xdg"
 	"github.com/gptscript-ai/knowledge/pkg/index"
+	"github.com/gptscript-ai/knowledge/pkg/telemetry"
 	"github.com/gptscript-ai/knowledge/pkg/vectorstore"
 	cg "github.com/philippgille/chromem-go"
 )
@@ -27,6 +29,11 @@ type Datastore struct {
 	Vectorstore            vectorstore.VectorStore
 	EmbeddingConfig        config.EmbeddingsConfig
 	EmbeddingModelProvider etypes.EmbeddingModelProvider
+
+	// lock serializes writes, including those of multiple processes sharing the same local (sqlite) datastore
+	lock locker
+	// unobserveSize stops reporting the vectorstore size metric
+	unobserveSize func()
 }
 
 // GetDefaultDSNs returns the paths for the datastore and vectorstore databases.
@@ -98,6 +105,11 @@ func NewDatastore(ctx context.Context, indexDSN string, automigrate bool, vector
 		Index:                  idx,
 		Vectorstore:            vsdb,
 		EmbeddingModelProvider: embeddingProvider,
+		lock:                   newProcessLock(),
+	}
+
+	if path, ok := strings.CutPrefix(indexDSN, "sqlite://"); ok && !isArchive && !strings.Contains(path, ":memory:") {
+		ds.lock = flock.New(strings.SplitN(path, "?", 2)[0] + ".lock")
 	}
 
 	// If loaded from archive, do not create a default dataset
@@ -105,10 +117,16 @@ func NewDatastore(ctx context.Context, indexDSN string, automigrate bool, vector
 		return ds, nil
 	}
 
+	ds.unobserveSize = telemetry.ObserveVectorstoreSize(idx.CountDocuments)
+
 	return ds, nil
 }
 
 func (s *Datastore) Close() error {
+	if s.unobserveSize != nil {
+		s.unobserveSize()
+	}
+
 	var errmsgs []string
 	if err := s.Index.Close(); err != nil {
 		errmsgs = append(errmsgs, fmt.Sprintf("failed to close index: %v", err))
@@ -219,15 +237,13 @@ func (s *Datastore) ImportDatasetsFromFile(ctx context.Context, path string, dat
 		return fmt.Errorf("knowledge archive must contain exactly one .db and one .gob file")
 	}
 
-	if err = s.Index.ImportDatasetsFromFile(ctx, dbFile); err != nil {
-		return err
-	}
-
-	if err = s.Vectorstore.ImportCollectionsFromFile(ctx, vectorStoreFile, datasets...); err != nil {
-		return err
-	}
+	return s.exclusive(ctx, datasets, func() error {
+		if err := s.Index.ImportDatasetsFromFile(ctx, dbFile); err != nil {
+			return err
+		}
 
-	return nil
+		return s.Vectorstore.ImportCollectionsFromFile(ctx, vectorStoreFile, datasets...)
+	})
 }
 
 func zipDir(src, dst string) error {
@@ -9,17 +9,19 @@ import (
 )
 
 func (s *Datastore) DeleteDocument(ctx context.Context, documentID, datasetID string) error {
-	// Remove from Index
-	if err := s.Index.DeleteDocument(ctx, documentID, datasetID); err != nil {
-		return fmt.Errorf("failed to remove document from Index: %w", err)
-	}
+	return s.exclusive(ctx, []string{datasetID}, func() error {
+		// Remove from Index
+		if err := s.Index.DeleteDocument(ctx, documentID, datasetID); err != nil {
+			return fmt.Errorf("failed to remove document from Index: %w", err)
+		}
 
-	// Remove from VectorStore
-	if err := s.Vectorstore.RemoveDocument(ctx, documentID, datasetID, nil, nil); err != nil {
-		return fmt.Errorf("failed to remove document from VectorStore: %w", err)
-	}
+		// Remove from VectorStore
+		if err := s.Vectorstore.RemoveDocument(ctx, documentID, datasetID, nil, nil); err != nil {
+			return fmt.Errorf("failed to remove document from VectorStore: %w", err)
+		}
 
-	return nil
+		return nil
+	})
 }
 
 func (s *Datastore) GetDocuments(ctx context.Context, datasetID string, where map[string]string, whereDocument []chromem.WhereDocument) ([]types.Document, error) {
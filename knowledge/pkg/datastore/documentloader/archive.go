@@ -0,0 +1,92 @@
+package documentloader
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+
+	"github.com/gptscript-ai/knowledge/pkg/datastore/documentloader/decrypt"
+	"github.com/gptscript-ai/knowledge/pkg/datastore/filetypes"
+	vs "github.com/gptscript-ai/knowledge/pkg/vectorstore/types"
+)
+
+// loadZip loads all supported files in a zip archive. Encrypted entries are decrypted with the password from the
+// context - password errors are always returned, as skipping them would silently ingest an empty archive.
+func loadZip(ctx context.Context, reader io.Reader, opts ArchiveOpts) ([]vs.Document, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+
+	var docs []vs.Document
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+
+		entryDocs, err := loadZipFile(ctx, f, opts)
+		if err != nil {
+			var unsupported *UnsupportedFileTypeError
+			switch {
+			case errors.As(err, &unsupported):
+				if opts.ErrOnUnsupportedFiletype {
+					return nil, fmt.Errorf("%w (archive file %q)", err, f.Name)
+				}
+				slog.Debug("Skipping unsupported file in archive", "file", f.Name, "type", unsupported.FileType)
+				continue
+			case errors.Is(err, &PasswordError{}), opts.ErrOnFailedFile:
+				return nil, fmt.Errorf("failed to load archive file %q: %w", f.Name, err)
+			default:
+				slog.Warn("Skipping archive file that failed to load", "file", f.Name, "error", err)
+				continue
+			}
+		}
+
+		for _, doc := range entryDocs {
+			if doc.Metadata == nil {
+				doc.Metadata = map[string]any{}
+			}
+			doc.Metadata["archiveFile"] = f.Name
+			docs = append(docs, doc)
+		}
+	}
+
+	return docs, nil
+}
+
+func loadZipFile(ctx context.Context, f *zip.File, opts ArchiveOpts) ([]vs.Document, error) {
+	content, err := decrypt.ReadZipFile(f, PasswordFromContext(ctx))
+	if err != nil {
+		return nil, passwordError(path.Ext(f.Name), err)
+	}
+
+	content, err = Decrypt(ctx, f.Name, content)
+	if err != nil {
+		return nil, err
+	}
+
+	filetype, err := filetypes.GetFiletype(f.Name, content)
+	if err != nil {
+		return nil, err
+	}
+
+	loader := EncryptedLoaderFunc(filetype, content)
+	if loader == nil {
+		loader = DefaultDocLoaderFunc(filetype, DefaultDocLoaderFuncOpts{Archive: opts})
+	}
+	if loader == nil {
+		return nil, &UnsupportedFileTypeError{FileType: filetype}
+	}
+
+	return loader(ctx, bytes.NewReader(content))
+}
@@ -0,0 +1,75 @@
+package documentloader
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testArchive(t *testing.T, encrypted bool) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	w, err := zw.Create("docs/readme.md")
+	require.NoError(t, err)
+	_, err = w.Write([]byte("# Readme\n\nHello from the archive"))
+	require.NoError(t, err)
+
+	w, err = zw.Create("image.bin")
+	require.NoError(t, err)
+	_, err = w.Write([]byte{0x00, 0x01, 0x02})
+	require.NoError(t, err)
+
+	if encrypted {
+		// The content does not matter, without a password it is never decrypted
+		w, err = zw.CreateRaw(&zip.FileHeader{
+			Name:             "secret.txt",
+			Method:           zip.Store,
+			Flags:            0x1,
+			CompressedSize64: 16,
+		})
+		require.NoError(t, err)
+		_, err = w.Write(bytes.Repeat([]byte{0x42}, 16))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestLoadZip(t *testing.T) {
+	loader := DefaultDocLoaderFunc(".zip", DefaultDocLoaderFuncOpts{})
+	require.NotNil(t, loader)
+
+	docs, err := loader(context.Background(), bytes.NewReader(testArchive(t, false)))
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Contains(t, docs[0].Content, "Hello from the archive")
+	assert.Equal(t, "docs/readme.md", docs[0].Metadata["archiveFile"])
+
+	_, err = DefaultDocLoaderFunc(".zip", DefaultDocLoaderFuncOpts{Archive: ArchiveOpts{ErrOnUnsupportedFiletype: true}})(context.Background(), bytes.NewReader(testArchive(t, false)))
+	assert.ErrorIs(t, err, &UnsupportedFileTypeError{})
+}
+
+func TestLoadZip_PasswordMissing(t *testing.T) {
+	loader := DefaultDocLoaderFunc(".zip", DefaultDocLoaderFuncOpts{})
+
+	_, err := loader(context.Background(), bytes.NewReader(testArchive(t, true)))
+	require.ErrorIs(t, err, &PasswordError{})
+
+	var passwordErr *PasswordError
+	require.True(t, errors.As(err, &passwordErr))
+	assert.Equal(t, PasswordMissing, passwordErr.Reason)
+	assert.Equal(t, ".txt", passwordErr.FileType)
+}
+
+func TestPasswordFromContext(t *testing.T) {
+	assert.Equal(t, "", PasswordFromContext(context.Background()))
+	assert.Equal(t, "s3cret", PasswordFromContext(WithPassword(context.Background(), "s3cret")))
+}
@@ -0,0 +1,24 @@
+// Package decrypt decrypts password-protected files (zip entries and Office Open XML documents) before they are
+// handed to the document loaders.
+package decrypt
+
+import "errors"
+
+var (
+	// ErrPasswordRequired is returned when a file is encrypted, but no password was given
+	ErrPasswordRequired = errors.New("password required")
+
+	// ErrWrongPassword is returned when the given password does not decrypt the file
+	ErrWrongPassword = errors.New("wrong password")
+
+	// ErrUnsupportedEncryption is returned for encryption schemes that can't be decrypted
+	ErrUnsupportedEncryption = errors.New("unsupported encryption")
+)
+
+// passwordError returns ErrPasswordRequired if no password was given, else ErrWrongPassword
+func passwordError(password string) error {
+	if password == "" {
+		return ErrPasswordRequired
+	}
+	return ErrWrongPassword
+}
@@ -0,0 +1,436 @@
+package decrypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"hash"
+	"io"
+	"unicode/utf16"
+
+	"github.com/richardlehane/mscfb"
+)
+
+// Encrypted Office Open XML documents (docx, xlsx, pptx) are stored in a Compound File Binary container, with the
+// encryption parameters in the EncryptionInfo stream and the encrypted zip package in the EncryptedPackage stream.
+// See [MS-OFFCRYPTO] https://learn.microsoft.com/en-us/openspecs/office_file_formats/ms-offcrypto
+var cfbMagic = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+const (
+	encryptionInfoStream   = "EncryptionInfo"
+	encryptedPackageStream = "EncryptedPackage"
+	encryptedSegmentLen    = 4096
+)
+
+// Standard encryption (Office 2007) parameters
+const (
+	standardSpinCount     = 50000
+	standardFlagAES       = 0x20
+	standardHeaderOffset  = 12
+	standardVerifierSize  = 72 // salt size, salt, encrypted verifier, verifier hash size and encrypted verifier hash
+	standardAlgorithmSHA1 = 0x8004
+)
+
+// Block keys of the agile encryption, used to derive the different keys from the password hash
+var (
+	blockKeyVerifierHashInput = []byte{0xfe, 0xa7, 0xd2, 0x76, 0x3b, 0x4b, 0x9e, 0x79}
+	blockKeyVerifierHashValue = []byte{0xd7, 0xaa, 0x0f, 0x6d, 0x30, 0x61, 0x34, 0x4e}
+	blockKeyEncryptedKey      = []byte{0x14, 0x6e, 0x0b, 0xe7, 0xab, 0xac, 0xd0, 0xd6}
+)
+
+type agileEncryption struct {
+	KeyData       agileKeyData `xml:"keyData"`
+	KeyEncryptors []struct {
+		URI          string                 `xml:"uri,attr"`
+		EncryptedKey agilePasswordEncryptor `xml:"encryptedKey"`
+	} `xml:"keyEncryptors>keyEncryptor"`
+}
+
+type agileKeyData struct {
+	SaltSize        int    `xml:"saltSize,attr"`
+	BlockSize       int    `xml:"blockSize,attr"`
+	KeyBits         int    `xml:"keyBits,attr"`
+	HashSize        int    `xml:"hashSize,attr"`
+	CipherAlgorithm string `xml:"cipherAlgorithm,attr"`
+	CipherChaining  string `xml:"cipherChaining,attr"`
+	HashAlgorithm   string `xml:"hashAlgorithm,attr"`
+	SaltValue       string `xml:"saltValue,attr"`
+}
+
+type agilePasswordEncryptor struct {
+	agileKeyData
+	SpinCount                  int    `xml:"spinCount,attr"`
+	EncryptedVerifierHashInput string `xml:"encryptedVerifierHashInput,attr"`
+	EncryptedVerifierHashValue string `xml:"encryptedVerifierHashValue,attr"`
+	EncryptedKeyValue          string `xml:"encryptedKeyValue,attr"`
+}
+
+// IsEncryptedOOXML reports whether the content is a password-protected Office Open XML document.
+func IsEncryptedOOXML(content []byte) bool {
+	if !bytes.HasPrefix(content, cfbMagic) {
+		return false
+	}
+	streams, err := readCFBStreams(content, encryptedPackageStream)
+	return err == nil && streams[encryptedPackageStream] != nil
+}
+
+// OOXML decrypts a password-protected Office Open XML document and returns the plain docx, xlsx or pptx content.
+// The agile encryption used by Office 2010 and later and the AES standard encryption of Office 2007 are supported.
+func OOXML(content []byte, password string) ([]byte, error) {
+	streams, err := readCFBStreams(content, encryptionInfoStream, encryptedPackageStream)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encrypted Office document: %w", err)
+	}
+	info, pkg := streams[encryptionInfoStream], streams[encryptedPackageStream]
+	if info == nil || pkg == nil {
+		return nil, fmt.Errorf("not an encrypted Office document")
+	}
+
+	if len(info) < 8 {
+		return nil, fmt.Errorf("invalid encryption info")
+	}
+	major, minor := binary.LittleEndian.Uint16(info), binary.LittleEndian.Uint16(info[2:])
+	agile := major == 4 && minor == 4
+	if !agile && (major < 2 || major > 4 || minor != 2) {
+		return nil, fmt.Errorf("%w: Office encryption version %d.%d", ErrUnsupportedEncryption, major, minor)
+	}
+
+	if password == "" {
+		return nil, ErrPasswordRequired
+	}
+
+	if !agile {
+		return standardDecrypt(info, pkg, password)
+	}
+	return agileDecrypt(info, pkg, password)
+}
+
+// agileDecrypt decrypts a document with the XML encryption info of the agile encryption.
+func agileDecrypt(info, pkg []byte, password string) ([]byte, error) {
+	var encryption agileEncryption
+	if err := xml.Unmarshal(info[8:], &encryption); err != nil {
+		return nil, fmt.Errorf("failed to parse encryption info: %w", err)
+	}
+
+	var encryptor *agilePasswordEncryptor
+	for i := range encryption.KeyEncryptors {
+		if encryption.KeyEncryptors[i].URI == "http://schemas.microsoft.com/office/2006/keyEncryptor/password" {
+			encryptor = &encryption.KeyEncryptors[i].EncryptedKey
+			break
+		}
+	}
+	if encryptor == nil {
+		return nil, fmt.Errorf("%w: Office document is not encrypted with a password", ErrUnsupportedEncryption)
+	}
+
+	key, err := agileSecretKey(*encryptor, password)
+	if err != nil {
+		return nil, err
+	}
+
+	return agileDecryptPackage(encryption.KeyData, key, pkg)
+}
+
+// agileSecretKey derives the key from the password, verifies it and uses it to decrypt the secret key of the package.
+func agileSecretKey(encryptor agilePasswordEncryptor, password string) ([]byte, error) {
+	newHash, err := hashFunc(encryptor.HashAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCipher(encryptor.agileKeyData); err != nil {
+		return nil, err
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(encryptor.SaltValue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid password salt: %w", err)
+	}
+
+	// H0 = H(salt + password), Hn = H(iterator + Hn-1)
+	h := newHash()
+	h.Write(salt)
+	h.Write(utf16LE(password))
+	passwordHash := h.Sum(nil)
+	iterator := make([]byte, 4)
+	for i := 0; i < encryptor.SpinCount; i++ {
+		binary.LittleEndian.PutUint32(iterator, uint32(i))
+		h.Reset()
+		h.Write(iterator)
+		h.Write(passwordHash)
+		passwordHash = h.Sum(passwordHash[:0])
+	}
+
+	decrypt := func(blockKey []byte, value string) ([]byte, error) {
+		encrypted, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, err
+		}
+		h := newHash()
+		h.Write(passwordHash)
+		h.Write(blockKey)
+		key := fitLen(h.Sum(nil), encryptor.KeyBits/8, 0x36)
+		return decryptCBC(key, fitLen(salt, encryptor.BlockSize, 0x36), encrypted)
+	}
+
+	verifierInput, err := decrypt(blockKeyVerifierHashInput, encryptor.EncryptedVerifierHashInput)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt password verifier: %w", err)
+	}
+	verifierHash, err := decrypt(blockKeyVerifierHashValue, encryptor.EncryptedVerifierHashValue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt password verifier: %w", err)
+	}
+	if len(verifierInput) < encryptor.SaltSize || len(verifierHash) < encryptor.HashSize {
+		return nil, fmt.Errorf("invalid password verifier")
+	}
+	h = newHash()
+	h.Write(verifierInput[:encryptor.SaltSize])
+	if subtle.ConstantTimeCompare(h.Sum(nil), verifierHash[:encryptor.HashSize]) != 1 {
+		return nil, ErrWrongPassword
+	}
+
+	key, err := decrypt(blockKeyEncryptedKey, encryptor.EncryptedKeyValue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt secret key: %w", err)
+	}
+	if len(key) < encryptor.KeyBits/8 {
+		return nil, fmt.Errorf("invalid secret key")
+	}
+	return key[:encryptor.KeyBits/8], nil
+}
+
+// agileDecryptPackage decrypts the EncryptedPackage stream, which holds the size of the package followed by its
+// content in segments of 4096 bytes, each encrypted with its own IV.
+func agileDecryptPackage(keyData agileKeyData, key, pkg []byte) ([]byte, error) {
+	newHash, err := hashFunc(keyData.HashAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCipher(keyData); err != nil {
+		return nil, err
+	}
+	salt, err := base64.StdEncoding.DecodeString(keyData.SaltValue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key data salt: %w", err)
+	}
+
+	if len(pkg) < 8 {
+		return nil, fmt.Errorf("invalid encrypted package")
+	}
+	size := binary.LittleEndian.Uint64(pkg)
+	pkg = pkg[8:]
+
+	content := make([]byte, 0, len(pkg))
+	segment := make([]byte, 4)
+	for i := 0; len(pkg) > 0; i++ {
+		n := min(encryptedSegmentLen, len(pkg))
+		// Streams are padded to their sector size, so the last segment may be longer than the encrypted data
+		n -= n % keyData.BlockSize
+
+		binary.LittleEndian.PutUint32(segment, uint32(i))
+		h := newHash()
+		h.Write(salt)
+		h.Write(segment)
+		plain, err := decryptCBC(key, fitLen(h.Sum(nil), keyData.BlockSize, 0x36), pkg[:n])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt package: %w", err)
+		}
+		content = append(content, plain...)
+		if n == 0 || uint64(len(content)) >= size {
+			break
+		}
+		pkg = pkg[n:]
+	}
+
+	if uint64(len(content)) < size {
+		return nil, fmt.Errorf("encrypted package is truncated")
+	}
+	return content[:size], nil
+}
+
+// standardDecrypt decrypts a document with the binary encryption info of the standard encryption, which encrypts the
+// whole package with AES in ECB mode.
+func standardDecrypt(info, pkg []byte, password string) ([]byte, error) {
+	if len(info) < standardHeaderOffset {
+		return nil, fmt.Errorf("invalid encryption info")
+	}
+	headerSize := int(binary.LittleEndian.Uint32(info[8:]))
+	if headerSize < 20 || len(info) < standardHeaderOffset+headerSize+standardVerifierSize {
+		return nil, fmt.Errorf("invalid encryption info")
+	}
+	header := info[standardHeaderOffset : standardHeaderOffset+headerSize]
+	flags := binary.LittleEndian.Uint32(header)
+	algorithm, hashAlgorithm := binary.LittleEndian.Uint32(header[8:]), binary.LittleEndian.Uint32(header[12:])
+	keyBits := int(binary.LittleEndian.Uint32(header[16:]))
+	if flags&standardFlagAES == 0 || (hashAlgorithm != 0 && hashAlgorithm != standardAlgorithmSHA1) {
+		return nil, fmt.Errorf("%w: Office standard encryption algorithm 0x%x", ErrUnsupportedEncryption, algorithm)
+	}
+	if keyBits != 128 && keyBits != 192 && keyBits != 256 {
+		return nil, fmt.Errorf("%w: key size %d", ErrUnsupportedEncryption, keyBits)
+	}
+
+	verifier := info[standardHeaderOffset+headerSize:]
+	if saltSize := binary.LittleEndian.Uint32(verifier); saltSize != 16 {
+		return nil, fmt.Errorf("invalid password salt size %d", saltSize)
+	}
+	salt, encryptedVerifier := verifier[4:20], verifier[20:36]
+	verifierHashSize, encryptedVerifierHash := int(binary.LittleEndian.Uint32(verifier[36:])), verifier[40:72]
+
+	// H0 = H(salt + password), Hn = H(iterator + Hn-1), Hfinal = H(Hn + block 0)
+	h := sha1.New()
+	h.Write(salt)
+	h.Write(utf16LE(password))
+	passwordHash := h.Sum(nil)
+	iterator := make([]byte, 4)
+	for i := 0; i < standardSpinCount; i++ {
+		binary.LittleEndian.PutUint32(iterator, uint32(i))
+		h.Reset()
+		h.Write(iterator)
+		h.Write(passwordHash)
+		passwordHash = h.Sum(passwordHash[:0])
+	}
+	h.Reset()
+	h.Write(passwordHash)
+	h.Write([]byte{0, 0, 0, 0})
+	passwordHash = h.Sum(nil)
+
+	// The key is derived like CryptDeriveKey: X1 = H(0x36 pad XOR Hfinal), X2 = H(0x5c pad XOR Hfinal), key = X1 + X2
+	var key []byte
+	for _, pad := range []byte{0x36, 0x5c} {
+		buf := bytes.Repeat([]byte{pad}, 64)
+		for i, b := range passwordHash {
+			buf[i] ^= b
+		}
+		x := sha1.Sum(buf)
+		key = append(key, x[:]...)
+	}
+	key = key[:keyBits/8]
+
+	plainVerifier, err := decryptECB(key, encryptedVerifier)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt password verifier: %w", err)
+	}
+	verifierHash, err := decryptECB(key, encryptedVerifierHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt password verifier: %w", err)
+	}
+	expected := sha1.Sum(plainVerifier)
+	if verifierHashSize != sha1.Size || subtle.ConstantTimeCompare(expected[:], verifierHash[:sha1.Size]) != 1 {
+		return nil, ErrWrongPassword
+	}
+
+	if len(pkg) < 8 {
+		return nil, fmt.Errorf("invalid encrypted package")
+	}
+	size := binary.LittleEndian.Uint64(pkg)
+	pkg = pkg[8:]
+	// Streams are padded to their sector size, so the encrypted data may be followed by some more bytes
+	content, err := decryptECB(key, pkg[:len(pkg)-len(pkg)%aes.BlockSize])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt package: %w", err)
+	}
+	if uint64(len(content)) < size {
+		return nil, fmt.Errorf("encrypted package is truncated")
+	}
+	return content[:size], nil
+}
+
+func readCFBStreams(content []byte, names ...string) (map[string][]byte, error) {
+	doc, err := mscfb.New(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+
+	streams := make(map[string][]byte, len(names))
+	for entry, err := doc.Next(); err == nil; entry, err = doc.Next() {
+		for _, name := range names {
+			if entry.Name != name {
+				continue
+			}
+			data, err := io.ReadAll(entry)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read stream %q: %w", name, err)
+			}
+			streams[name] = data
+		}
+	}
+	return streams, nil
+}
+
+func hashFunc(name string) (func() hash.Hash, error) {
+	switch name {
+	case "SHA1":
+		return sha1.New, nil
+	case "SHA256":
+		return sha256.New, nil
+	case "SHA384":
+		return sha512.New384, nil
+	case "SHA512":
+		return sha512.New, nil
+	default:
+		return nil, fmt.Errorf("%w: hash algorithm %q", ErrUnsupportedEncryption, name)
+	}
+}
+
+func checkCipher(keyData agileKeyData) error {
+	if keyData.CipherAlgorithm != "AES" || keyData.CipherChaining != "ChainingModeCBC" {
+		return fmt.Errorf("%w: cipher %s/%s", ErrUnsupportedEncryption, keyData.CipherAlgorithm, keyData.CipherChaining)
+	}
+	if keyData.BlockSize != aes.BlockSize {
+		return fmt.Errorf("%w: block size %d", ErrUnsupportedEncryption, keyData.BlockSize)
+	}
+	return nil
+}
+
+func decryptCBC(key, iv, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data)%block.BlockSize() != 0 {
+		return nil, fmt.Errorf("encrypted data is not a multiple of the block size")
+	}
+	plain := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, data)
+	return plain, nil
+}
+
+func decryptECB(key, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data)%block.BlockSize() != 0 {
+		return nil, fmt.Errorf("encrypted data is not a multiple of the block size")
+	}
+	plain := make([]byte, len(data))
+	for i := 0; i < len(data); i += block.BlockSize() {
+		block.Decrypt(plain[i:], data[i:])
+	}
+	return plain, nil
+}
+
+// fitLen truncates b to n bytes or pads it with the pad byte.
+func fitLen(b []byte, n int, pad byte) []byte {
+	if len(b) >= n {
+		return b[:n]
+	}
+	return append(append([]byte{}, b...), bytes.Repeat([]byte{pad}, n-len(b))...)
+}
+
+func utf16LE(s string) []byte {
+	codes := utf16.Encode([]rune(s))
+	b := make([]byte, 2*len(codes))
+	for i, c := range codes {
+		binary.LittleEndian.PutUint16(b[2*i:], c)
+	}
+	return b
+}
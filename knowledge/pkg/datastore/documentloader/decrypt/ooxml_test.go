@@ -0,0 +1,49 @@
+package decrypt
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// The fixtures are encrypted with the password "password" by other implementations: agile.xlsx by Excel and
+// standard.xlsx by LibreOffice (both from the excelize test suite), standard.docx by excelize.Encrypt.
+func TestOOXML(t *testing.T) {
+	for _, tc := range []struct {
+		file, part, text string
+	}{
+		{"agile.xlsx", "xl/sharedStrings.xml", "SECRET"},
+		{"standard.xlsx", "xl/sharedStrings.xml", "SECRET"},
+		{"standard.docx", "word/document.xml", "The encrypted document says hello."},
+	} {
+		t.Run(tc.file, func(t *testing.T) {
+			content, err := os.ReadFile(filepath.Join("testdata", tc.file))
+			require.NoError(t, err)
+			require.True(t, IsEncryptedOOXML(content))
+
+			plain, err := OOXML(content, "password")
+			require.NoError(t, err)
+			require.False(t, IsEncryptedOOXML(plain))
+
+			zr, err := zip.NewReader(bytes.NewReader(plain), int64(len(plain)))
+			require.NoError(t, err)
+			f, err := zr.Open(tc.part)
+			require.NoError(t, err)
+			defer f.Close()
+			part, err := io.ReadAll(f)
+			require.NoError(t, err)
+			require.Contains(t, string(part), tc.text)
+
+			_, err = OOXML(content, "")
+			require.ErrorIs(t, err, ErrPasswordRequired)
+
+			_, err = OOXML(content, "passwd")
+			require.ErrorIs(t, err, ErrWrongPassword)
+		})
+	}
+}
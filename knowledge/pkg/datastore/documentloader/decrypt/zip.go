@@ -0,0 +1,224 @@
+package decrypt
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"crypto/aes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	zipFlagEncrypted         = 0x1
+	zipFlagDataDescriptor    = 0x8
+	zipFlagStrongEncryption  = 0x40
+	zipMethodAES             = 99
+	zipExtraAES              = 0x9901
+	zipCryptoHeaderLen       = 12
+	zipAESPasswordVerifyLen  = 2
+	zipAESAuthCodeLen        = 10
+	zipAESKeyDerivationIters = 1000
+)
+
+// ZipEncrypted reports whether the zip entry is encrypted.
+func ZipEncrypted(f *zip.File) bool {
+	return f.Flags&zipFlagEncrypted != 0
+}
+
+// ReadZipFile returns the decompressed content of the zip entry, decrypting it with the password if it is encrypted.
+// The traditional PKWARE encryption (ZipCrypto) and the WinZip AES encryption are supported.
+func ReadZipFile(f *zip.File, password string) ([]byte, error) {
+	if !ZipEncrypted(f) {
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+
+	if f.Flags&zipFlagStrongEncryption != 0 {
+		return nil, fmt.Errorf("%w: PKWARE strong encryption", ErrUnsupportedEncryption)
+	}
+	if password == "" {
+		return nil, ErrPasswordRequired
+	}
+
+	raw, err := f.OpenRaw()
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.Method == zipMethodAES {
+		return readAESZipFile(f, data, password)
+	}
+	return readZipCryptoFile(f, data, password)
+}
+
+func readZipCryptoFile(f *zip.File, data []byte, password string) ([]byte, error) {
+	if len(data) < zipCryptoHeaderLen {
+		return nil, fmt.Errorf("zip entry %q is too short for its encryption header", f.Name)
+	}
+
+	keys := newZipCryptoKeys(password)
+	plain := make([]byte, len(data))
+	for i, c := range data {
+		plain[i] = keys.decrypt(c)
+	}
+
+	// The last byte of the encryption header is a check byte, which only matches with the right password
+	check := byte(f.CRC32 >> 24)
+	if f.Flags&zipFlagDataDescriptor != 0 {
+		check = byte(f.ModifiedTime >> 8)
+	}
+	if plain[zipCryptoHeaderLen-1] != check {
+		return nil, ErrWrongPassword
+	}
+
+	content, err := decompress(f.Method, plain[zipCryptoHeaderLen:])
+	if err != nil || crc32.ChecksumIEEE(content) != f.CRC32 {
+		// The check byte matches one in 256 wrong passwords, those end up here
+		return nil, ErrWrongPassword
+	}
+	return content, nil
+}
+
+func readAESZipFile(f *zip.File, data []byte, password string) ([]byte, error) {
+	version, strength, method, ok := zipAESExtra(f.Extra)
+	if !ok {
+		return nil, fmt.Errorf("zip entry %q has no AES extra field", f.Name)
+	}
+
+	var keyLen int
+	switch strength {
+	case 1:
+		keyLen = 16
+	case 2:
+		keyLen = 24
+	case 3:
+		keyLen = 32
+	default:
+		return nil, fmt.Errorf("%w: AES strength %d", ErrUnsupportedEncryption, strength)
+	}
+
+	saltLen := keyLen / 2
+	if len(data) < saltLen+zipAESPasswordVerifyLen+zipAESAuthCodeLen {
+		return nil, fmt.Errorf("zip entry %q is too short for its encryption header", f.Name)
+	}
+	salt := data[:saltLen]
+	passwordVerify := data[saltLen : saltLen+zipAESPasswordVerifyLen]
+	encrypted := data[saltLen+zipAESPasswordVerifyLen : len(data)-zipAESAuthCodeLen]
+	authCode := data[len(data)-zipAESAuthCodeLen:]
+
+	keys := pbkdf2.Key([]byte(password), salt, zipAESKeyDerivationIters, 2*keyLen+zipAESPasswordVerifyLen, sha1.New)
+	if !hmac.Equal(keys[2*keyLen:], passwordVerify) {
+		return nil, ErrWrongPassword
+	}
+
+	mac := hmac.New(sha1.New, keys[keyLen:2*keyLen])
+	mac.Write(encrypted)
+	if !hmac.Equal(mac.Sum(nil)[:zipAESAuthCodeLen], authCode) {
+		return nil, fmt.Errorf("zip entry %q failed the authentication check", f.Name)
+	}
+
+	block, err := aes.NewCipher(keys[:keyLen])
+	if err != nil {
+		return nil, err
+	}
+
+	// WinZip uses AES in CTR mode with a little-endian counter starting at 1, which crypto/cipher does not offer
+	plain := make([]byte, len(encrypted))
+	var counter, stream [aes.BlockSize]byte
+	for i := 0; i < len(encrypted); i += aes.BlockSize {
+		binary.LittleEndian.PutUint64(counter[:], uint64(i/aes.BlockSize+1))
+		block.Encrypt(stream[:], counter[:])
+		for j := i; j < len(encrypted) && j < i+aes.BlockSize; j++ {
+			plain[j] = encrypted[j] ^ stream[j-i]
+		}
+	}
+
+	content, err := decompress(method, plain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress zip entry %q: %w", f.Name, err)
+	}
+	// AE-2 does not store the CRC, the authentication code replaces it
+	if version == 1 && crc32.ChecksumIEEE(content) != f.CRC32 {
+		return nil, fmt.Errorf("zip entry %q: %w", f.Name, zip.ErrChecksum)
+	}
+	return content, nil
+}
+
+// zipAESExtra parses the WinZip AES extra field, which holds the encryption strength and the actual compression
+// method of the entry.
+func zipAESExtra(extra []byte) (version uint16, strength byte, method uint16, ok bool) {
+	for len(extra) >= 4 {
+		tag := binary.LittleEndian.Uint16(extra)
+		size := int(binary.LittleEndian.Uint16(extra[2:]))
+		extra = extra[4:]
+		if size > len(extra) {
+			break
+		}
+		if tag == zipExtraAES && size >= 7 {
+			field := extra[:size]
+			return binary.LittleEndian.Uint16(field), field[4], binary.LittleEndian.Uint16(field[5:]), true
+		}
+		extra = extra[size:]
+	}
+	return 0, 0, 0, false
+}
+
+func decompress(method uint16, data []byte) ([]byte, error) {
+	switch method {
+	case zip.Store:
+		return data, nil
+	case zip.Deflate:
+		r := flate.NewReader(bytes.NewReader(data))
+		defer r.Close()
+		return io.ReadAll(r)
+	default:
+		return nil, fmt.Errorf("%w: %d", zip.ErrAlgorithm, method)
+	}
+}
+
+// zipCryptoKeys is the key state of the traditional PKWARE encryption
+type zipCryptoKeys [3]uint32
+
+func newZipCryptoKeys(password string) *zipCryptoKeys {
+	keys := &zipCryptoKeys{0x12345678, 0x23456789, 0x34567890}
+	for i := 0; i < len(password); i++ {
+		keys.update(password[i])
+	}
+	return keys
+}
+
+func (k *zipCryptoKeys) update(b byte) {
+	k[0] = crc32Update(k[0], b)
+	k[1] = (k[1]+k[0]&0xff)*134775813 + 1
+	k[2] = crc32Update(k[2], byte(k[1]>>24))
+}
+
+func (k *zipCryptoKeys) streamByte() byte {
+	temp := (k[2] | 2) & 0xffff
+	return byte((temp * (temp ^ 1)) >> 8)
+}
+
+func (k *zipCryptoKeys) decrypt(c byte) byte {
+	p := c ^ k.streamByte()
+	k.update(p)
+	return p
+}
+
+func crc32Update(crc uint32, b byte) uint32 {
+	return crc32.IEEETable[byte(crc)^b] ^ crc>>8
+}
@@ -0,0 +1,126 @@
+package decrypt
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/aes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/binary"
+	"hash/crc32"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const testContent = "The quick brown fox jumps over the lazy dog, again and again and again."
+
+func zipCryptoEncrypt(password string, crc uint32, data []byte) []byte {
+	keys := newZipCryptoKeys(password)
+	header := make([]byte, zipCryptoHeaderLen)
+	header[zipCryptoHeaderLen-1] = byte(crc >> 24)
+
+	var out []byte
+	for _, p := range append(header, data...) {
+		c := p ^ keys.streamByte()
+		keys.update(p)
+		out = append(out, c)
+	}
+	return out
+}
+
+func aesEncrypt(password string, data []byte) ([]byte, []byte) {
+	const keyLen = 32
+	salt := bytes.Repeat([]byte{0x42}, keyLen/2)
+	keys := pbkdf2.Key([]byte(password), salt, zipAESKeyDerivationIters, 2*keyLen+zipAESPasswordVerifyLen, sha1.New)
+
+	block, _ := aes.NewCipher(keys[:keyLen])
+	encrypted := make([]byte, len(data))
+	var counter, stream [aes.BlockSize]byte
+	for i := 0; i < len(data); i += aes.BlockSize {
+		binary.LittleEndian.PutUint64(counter[:], uint64(i/aes.BlockSize+1))
+		block.Encrypt(stream[:], counter[:])
+		for j := i; j < len(data) && j < i+aes.BlockSize; j++ {
+			encrypted[j] = data[j] ^ stream[j-i]
+		}
+	}
+	mac := hmac.New(sha1.New, keys[keyLen:2*keyLen])
+	mac.Write(encrypted)
+
+	raw := append(append(append(salt, keys[2*keyLen:]...), encrypted...), mac.Sum(nil)[:zipAESAuthCodeLen]...)
+	// AE-2, AES-256, stored
+	extra := []byte{0x01, 0x99, 7, 0, 2, 0, 'A', 'E', 3, 0, 0}
+	return raw, extra
+}
+
+func testZip(t *testing.T) *zip.Reader {
+	t.Helper()
+	content := []byte(testContent)
+	crc := crc32.ChecksumIEEE(content)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	w, err := zw.Create("plain.txt")
+	require.NoError(t, err)
+	_, err = w.Write(content)
+	require.NoError(t, err)
+
+	raw := zipCryptoEncrypt("secret", crc, content)
+	w, err = zw.CreateRaw(&zip.FileHeader{
+		Name:               "zipcrypto.txt",
+		Method:             zip.Store,
+		Flags:              zipFlagEncrypted,
+		CRC32:              crc,
+		CompressedSize64:   uint64(len(raw)),
+		UncompressedSize64: uint64(len(content)),
+	})
+	require.NoError(t, err)
+	_, err = w.Write(raw)
+	require.NoError(t, err)
+
+	raw, extra := aesEncrypt("secret", content)
+	w, err = zw.CreateRaw(&zip.FileHeader{
+		Name:               "aes.txt",
+		Method:             zipMethodAES,
+		Flags:              zipFlagEncrypted,
+		Extra:              extra,
+		CompressedSize64:   uint64(len(raw)),
+		UncompressedSize64: uint64(len(content)),
+	})
+	require.NoError(t, err)
+	_, err = w.Write(raw)
+	require.NoError(t, err)
+
+	require.NoError(t, zw.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	return zr
+}
+
+func TestReadZipFile(t *testing.T) {
+	zr := testZip(t)
+
+	for _, f := range zr.File {
+		t.Run(f.Name, func(t *testing.T) {
+			encrypted := f.Name != "plain.txt"
+			require.Equal(t, encrypted, ZipEncrypted(f))
+
+			content, err := ReadZipFile(f, "secret")
+			require.NoError(t, err)
+			require.Equal(t, testContent, string(content))
+
+			if !encrypted {
+				return
+			}
+
+			_, err = ReadZipFile(f, "")
+			require.ErrorIs(t, err, ErrPasswordRequired)
+
+			_, err = ReadZipFile(f, "wrong")
+			require.ErrorIs(t, err, ErrWrongPassword)
+		})
+	}
+}
@@ -79,6 +79,10 @@ func DefaultDocLoaderFunc(filetype string, opts DefaultDocLoaderFuncOpts) Loader
 		return func(ctx context.Context, reader io.Reader) ([]vs.Document, error) {
 			return FromLangchain(lcgodocloaders.NewText(reader)).Load(ctx)
 		}
+	case ".zip", "application/zip":
+		return func(ctx context.Context, reader io.Reader) ([]vs.Document, error) {
+			return loadZip(ctx, reader, opts.Archive)
+		}
 	case ".ipynb":
 		return func(ctx context.Context, reader io.Reader) ([]vs.Document, error) {
 			return FromGolc(golcdocloaders.NewNotebook(reader)).Load(ctx)
@@ -0,0 +1,110 @@
+package documentloader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/gptscript-ai/knowledge/pkg/datastore/documentloader/decrypt"
+	"github.com/gptscript-ai/knowledge/pkg/datastore/documentloader/pdf/gopdf"
+	vs "github.com/gptscript-ai/knowledge/pkg/vectorstore/types"
+	"github.com/ledongthuc/pdf"
+)
+
+const (
+	PasswordMissing = "missing"
+	PasswordWrong   = "wrong"
+)
+
+// PasswordError is returned when an encrypted file can't be loaded, because the password is missing or wrong
+type PasswordError struct {
+	FileType string
+	Reason   string // PasswordMissing or PasswordWrong
+}
+
+func (e *PasswordError) Error() string {
+	if e.Reason == PasswordWrong {
+		return fmt.Sprintf("wrong password for encrypted file of type %q", e.FileType)
+	}
+	return fmt.Sprintf("password required for encrypted file of type %q", e.FileType)
+}
+
+func (e *PasswordError) Is(err error) bool {
+	var passwordError *PasswordError
+	ok := errors.As(err, &passwordError)
+	return ok
+}
+
+type passwordCtxKey struct{}
+
+// WithPassword returns a context carrying the password that is used to open encrypted files
+func WithPassword(ctx context.Context, password string) context.Context {
+	return context.WithValue(ctx, passwordCtxKey{}, password)
+}
+
+// PasswordFromContext returns the password set by WithPassword, empty if there is none
+func PasswordFromContext(ctx context.Context) string {
+	password, _ := ctx.Value(passwordCtxKey{}).(string)
+	return password
+}
+
+// passwordError turns the password errors of the decrypt package into a PasswordError
+func passwordError(filetype string, err error) error {
+	switch {
+	case errors.Is(err, decrypt.ErrPasswordRequired):
+		return &PasswordError{FileType: filetype, Reason: PasswordMissing}
+	case errors.Is(err, decrypt.ErrWrongPassword):
+		return &PasswordError{FileType: filetype, Reason: PasswordWrong}
+	default:
+		return err
+	}
+}
+
+// Decrypt returns the decrypted content of a password-protected Office document (docx, xlsx, pptx), using the
+// password from the context. Any other content is returned as is - archives and PDFs are decrypted by their loaders.
+func Decrypt(ctx context.Context, filename string, content []byte) ([]byte, error) {
+	if !decrypt.IsEncryptedOOXML(content) {
+		return content, nil
+	}
+	plain, err := decrypt.OOXML(content, PasswordFromContext(ctx))
+	if err != nil {
+		return nil, passwordError(filepath.Ext(filename), err)
+	}
+	return plain, nil
+}
+
+// EncryptedLoaderFunc returns the loader for a PDF that can only be opened with a password, nil for any other
+// content. MuPDF (and with it SmartPDF and the OCR loaders) can't open those, so they are always loaded with GoPDF.
+func EncryptedLoaderFunc(filetype string, content []byte) LoaderFunc {
+	if filetype != ".pdf" && filetype != "application/pdf" {
+		return nil
+	}
+	if !bytes.Contains(content, []byte("/Encrypt")) {
+		return nil
+	}
+	// PDFs that are only protected against editing or printing open with the empty password
+	if _, err := pdf.NewReader(bytes.NewReader(content), int64(len(content))); !errors.Is(err, pdf.ErrInvalidPassword) {
+		return nil
+	}
+
+	return func(ctx context.Context, reader io.Reader) ([]vs.Document, error) {
+		password := PasswordFromContext(ctx)
+		if password == "" {
+			return nil, &PasswordError{FileType: filetype, Reason: PasswordMissing}
+		}
+		r, err := gopdf.NewPDFFromReader(reader, gopdf.WithInterpreterOpts(pdf.WithIgnoreDefOfNonNameVals([]string{"CMapName"})), func(o *gopdf.PDFOptions) {
+			o.Password = password
+		})
+		if err != nil {
+			return nil, err
+		}
+		docs, err := r.Load(ctx)
+		if errors.Is(err, pdf.ErrInvalidPassword) {
+			return nil, &PasswordError{FileType: filetype, Reason: PasswordWrong}
+		}
+		return docs, err
+	}
+}
@@ -136,8 +136,12 @@ func (l *PDF) Load(ctx context.Context) ([]vs.Document, error) {
 	)
 
 	if l.opts.Password != "" {
+		// NewReaderEncrypted keeps asking for passwords until it gets an empty one, so only offer ours once
+		password := l.opts.Password
 		reader, err = pdf.NewReaderEncrypted(l.f, l.size, func() string {
-			return l.opts.Password
+			pw := password
+			password = ""
+			return pw
 		})
 		if err != nil {
 			return nil, err
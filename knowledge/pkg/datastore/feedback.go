@@ -0,0 +1,102 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gptscript-ai/knowledge/pkg/datastore/types"
+	itypes "github.com/gptscript-ai/knowledge/pkg/index/types"
+	vs "github.com/gptscript-ai/knowledge/pkg/vectorstore/types"
+)
+
+// feedbackPrior dampens the boost of documents with only a few votes: a single thumbs up yields a third of the
+// maximum boost, ten consistent votes yield ~83% of it.
+const feedbackPrior = 2
+
+// RecordFeedback stores a relevance judgement for a retrieved document (chunk) of a dataset.
+func (s *Datastore) RecordFeedback(ctx context.Context, datasetID, documentID, query string, relevant bool, comment string) (*itypes.Feedback, error) {
+	doc, err := s.Index.GetDocument(ctx, documentID, datasetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find document %q in dataset %q: %w", documentID, datasetID, err)
+	}
+
+	feedback := itypes.Feedback{
+		ID:         uuid.NewString(),
+		Dataset:    datasetID,
+		DocumentID: documentID,
+		FileID:     doc.FileID,
+		Query:      query,
+		Relevant:   relevant,
+		Comment:    comment,
+		CreatedAt:  time.Now(),
+	}
+
+	return &feedback, s.Index.CreateFeedback(ctx, feedback)
+}
+
+func (s *Datastore) ListFeedback(ctx context.Context, datasetID, documentID string, limit int) ([]itypes.Feedback, error) {
+	return s.Index.ListFeedback(ctx, datasetID, documentID, limit)
+}
+
+// applyFeedback re-scores the retrieved documents based on the recorded feedback: each document's similarity score is
+// shifted by up to +/- weight, depending on the balance of positive and negative votes, and the results are re-sorted.
+func (s *Datastore) applyFeedback(ctx context.Context, resp *types.RetrievalResponse, weight float32) error {
+	var docIDs []string
+	for _, r := range resp.Responses {
+		for _, doc := range r.ResultDocuments {
+			docIDs = append(docIDs, doc.ID)
+		}
+	}
+	if len(docIDs) == 0 {
+		return nil
+	}
+
+	feedback, err := s.Index.GetDocumentFeedback(ctx, resp.Datasets, docIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get document feedback: %w", err)
+	}
+
+	// The datasets of the result documents are unknown, so votes for the same ID in different datasets are summed up
+	votes := make(map[string]itypes.DocumentFeedback, len(feedback))
+	for _, f := range feedback {
+		v := votes[f.DocumentID]
+		v.Positive += f.Positive
+		v.Negative += f.Negative
+		votes[f.DocumentID] = v
+	}
+
+	applyFeedbackBoosts(resp, votes, weight)
+	slog.Debug("Applied feedback boosts", "documents_with_feedback", len(votes), "weight", weight)
+	return nil
+}
+
+func applyFeedbackBoosts(resp *types.RetrievalResponse, votes map[string]itypes.DocumentFeedback, weight float32) {
+	if len(votes) == 0 {
+		return
+	}
+
+	for i, r := range resp.Responses {
+		for j, doc := range r.ResultDocuments {
+			v, ok := votes[doc.ID]
+			if !ok {
+				continue
+			}
+			boost := float32(v.Positive-v.Negative) / float32(v.Positive+v.Negative+feedbackPrior)
+			resp.Responses[i].ResultDocuments[j].SimilarityScore += weight * boost
+		}
+		slices.SortStableFunc(resp.Responses[i].ResultDocuments, func(a, b vs.Document) int {
+			switch {
+			case a.SimilarityScore > b.SimilarityScore:
+				return -1
+			case a.SimilarityScore < b.SimilarityScore:
+				return 1
+			default:
+				return 0
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package datastore
+
+import (
+	"testing"
+
+	"github.com/gptscript-ai/knowledge/pkg/datastore/types"
+	itypes "github.com/gptscript-ai/knowledge/pkg/index/types"
+	vs "github.com/gptscript-ai/knowledge/pkg/vectorstore/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyFeedbackBoosts(t *testing.T) {
+	resp := &types.RetrievalResponse{
+		Responses: []types.Response{{
+			ResultDocuments: []vs.Document{
+				{ID: "a", SimilarityScore: 0.80},
+				{ID: "b", SimilarityScore: 0.78},
+				{ID: "c", SimilarityScore: 0.75},
+			},
+		}},
+	}
+
+	applyFeedbackBoosts(resp, map[string]itypes.DocumentFeedback{
+		"a": {Negative: 2},              // 0.80 - 0.1 * 2/4 = 0.75
+		"c": {Positive: 4, Negative: 1}, // 0.75 + 0.1 * 3/7 ~ 0.793
+	}, 0.1)
+
+	docs := resp.Responses[0].ResultDocuments
+	assert.Equal(t, []string{"c", "b", "a"}, []string{docs[0].ID, docs[1].ID, docs[2].ID})
+	assert.InDelta(t, 0.75, docs[2].SimilarityScore, 0.0001)
+	assert.InDelta(t, 0.78, docs[1].SimilarityScore, 0.0001)
+	assert.InDelta(t, 0.7929, docs[0].SimilarityScore, 0.0001)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 
 	"github.com/gptscript-ai/knowledge/pkg/index/types"
 )
@@ -19,21 +20,101 @@ func (s *Datastore) DeleteFile(ctx context.Context, datasetID, fileID string) er
 		return fmt.Errorf("failed to find file in DB: %w", err)
 	}
 
-	// Remove owned documents from VectorStore and Database
-	for _, doc := range file.Documents {
-		if err := s.Vectorstore.RemoveDocument(ctx, doc.ID, datasetID, nil, nil); err != nil {
-			return fmt.Errorf("failed to remove document from VectorStore: %w", err)
+	err = s.exclusive(ctx, []string{datasetID}, func() error {
+		// Remove owned documents from VectorStore and Database
+		for _, doc := range file.Documents {
+			if err := s.Vectorstore.RemoveDocument(ctx, doc.ID, datasetID, nil, nil); err != nil {
+				return fmt.Errorf("failed to remove document from VectorStore: %w", err)
+			}
 		}
+
+		// Remove file DB
+		return s.Index.DeleteFile(ctx, datasetID, fileID)
+	})
+	if err != nil {
+		return err
 	}
 
-	// Remove file DB
-	return s.Index.DeleteFile(ctx, datasetID, fileID)
+	IngestionStatsFromCtx(ctx).ChunksDeleted.Add(int64(len(file.Documents)))
+	return nil
 }
 
 func (s *Datastore) PruneFiles(ctx context.Context, datasetID string, pathPrefix string, keep []string) ([]types.File, error) {
-	return s.Index.PruneFiles(ctx, datasetID, pathPrefix, keep)
+	var files []types.File
+	err := s.exclusive(ctx, []string{datasetID}, func() (err error) {
+		files, err = s.Index.PruneFiles(ctx, datasetID, pathPrefix, keep)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stats := IngestionStatsFromCtx(ctx)
+	stats.FilesPruned.Add(int64(len(files)))
+	for _, f := range files {
+		stats.ChunksDeleted.Add(int64(len(f.Documents)))
+	}
+	return files, nil
 }
 
 func (s *Datastore) FindFile(ctx context.Context, searchFile types.File) (*types.File, error) {
 	return s.Index.FindFile(ctx, searchFile)
 }
+
+type UpdateFileMetadataOpts struct {
+	ReplaceMetadata bool
+}
+
+// UpdateFileMetadata sets custom metadata on an already ingested file and propagates it to all of its documents
+// in the vectorstore, so it can be used for filtering without re-embedding the file.
+func (s *Datastore) UpdateFileMetadata(ctx context.Context, datasetID, fileID string, metadata map[string]any, opts *UpdateFileMetadataOpts) (*types.File, error) {
+	if opts == nil {
+		opts = &UpdateFileMetadataOpts{}
+	}
+
+	file, err := s.Index.FindFile(ctx, types.File{ID: fileID, Dataset: datasetID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find file in DB: %w", err)
+	}
+
+	// Compute the change set for the documents: keys that are dropped from the file metadata are set to nil, so they're removed
+	update := make(map[string]any, len(metadata))
+	if opts.ReplaceMetadata {
+		for k := range file.Metadata {
+			update[k] = nil
+		}
+		file.ReplaceMetadata(metadata)
+	} else {
+		file.UpdateMetadata(metadata)
+	}
+	for k, v := range metadata {
+		if types.IsEmptyMetadataValue(v) {
+			update[k] = nil
+		} else {
+			update[k] = v
+		}
+	}
+
+	docIDs := make([]string, len(file.Documents))
+	for i, doc := range file.Documents {
+		docIDs[i] = doc.ID
+	}
+
+	slog.Debug("Updating file metadata", "file", fileID, "dataset", datasetID, "numDocuments", len(docIDs), "update", update)
+
+	err = s.exclusive(ctx, []string{datasetID}, func() error {
+		if err := s.Vectorstore.UpdateDocumentMetadata(ctx, datasetID, docIDs, update); err != nil {
+			return fmt.Errorf("failed to update document metadata in VectorStore: %w", err)
+		}
+
+		if err := s.Index.UpdateFile(ctx, *file); err != nil {
+			return fmt.Errorf("failed to update file in DB: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
@@ -20,6 +20,7 @@ var FirstclassFileExtensions = map[string]struct{}{
 	".csv":   {},
 	".ipynb": {},
 	".json":  {},
+	".zip":   {},
 	".pptx":  {}, // via libreoffice conversion to pdf
 	".doc":   {}, // via libreoffice conversion to pdf
 	".ppt":   {}, // via libreoffice conversion to pdf
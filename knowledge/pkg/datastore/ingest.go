@@ -3,6 +3,7 @@ package datastore
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -13,7 +14,10 @@ import (
 	"github.com/gptscript-ai/knowledge/pkg/index/types"
 	"github.com/gptscript-ai/knowledge/pkg/log"
 	"github.com/gptscript-ai/knowledge/pkg/output"
+	"github.com/gptscript-ai/knowledge/pkg/telemetry"
 	vs "github.com/gptscript-ai/knowledge/pkg/vectorstore/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 
 	"github.com/google/uuid"
 	"github.com/gptscript-ai/knowledge/pkg/datastore/filetypes"
@@ -27,11 +31,41 @@ type IngestOpts struct {
 	IsDuplicateFunc     IsDuplicateFunc
 	IngestionFlows      []flows.IngestionFlow
 	ExtraMetadata       map[string]any
+	Password            string // Password to open encrypted files (zip, PDF, Office documents)
 }
 
 // Ingest loads a document from a reader and adds it to the dataset.
 func (s *Datastore) Ingest(ctx context.Context, datasetID string, filename string, content []byte, opts IngestOpts) ([]string, error) {
 	ingestionStart := time.Now()
+
+	ctx, span := telemetry.StartSpan(ctx, "knowledge.ingest_file",
+		attribute.String("knowledge.dataset", datasetID),
+		attribute.String("knowledge.file.name", filename),
+		attribute.Int("knowledge.file.size", len(content)),
+	)
+
+	docIDs, err := s.ingest(ctx, ingestionStart, datasetID, filename, content, opts)
+
+	span.SetAttributes(attribute.Int("knowledge.chunks", len(docIDs)))
+	telemetry.EndSpan(span, err)
+
+	var unsupported *documentloader.UnsupportedFileTypeError
+	if err == nil && len(docIDs) > 0 {
+		attrs := metric.WithAttributes(attribute.String("knowledge.dataset", datasetID))
+		telemetry.Metrics().FilesIngested.Add(ctx, 1, attrs)
+		telemetry.Metrics().BytesIngested.Add(ctx, int64(len(content)), attrs)
+		telemetry.Metrics().IngestionLatency.Record(ctx, time.Since(ingestionStart).Seconds(), attrs)
+	} else if err != nil && !errors.As(err, &unsupported) {
+		// Unsupported files are expected when ingesting directories, so they don't count as errors
+		telemetry.Metrics().Errors.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("knowledge.operation", "ingest"),
+			attribute.String("knowledge.dataset", datasetID),
+		))
+	}
+	return docIDs, err
+}
+
+func (s *Datastore) ingest(ctx context.Context, ingestionStart time.Time, datasetID string, filename string, content []byte, opts IngestOpts) ([]string, error) {
 	if filename == "" {
 		return nil, fmt.Errorf("filename is required")
 	}
@@ -112,6 +146,16 @@ func (s *Datastore) Ingest(ctx context.Context, datasetID string, filename strin
 	}
 	fileID := fUUID.String()
 
+	/*
+	 * Decrypt password-protected Office documents, so that they are detected and loaded like any other document
+	 */
+	ctx = documentloader.WithPassword(ctx, opts.Password)
+	content, err = documentloader.Decrypt(ctx, filename, content)
+	if err != nil {
+		statusLog.With("status", "failed").Error("Failed to decrypt file", "error", err)
+		return nil, fmt.Errorf("failed to decrypt file %q: %w", filename, err)
+	}
+
 	/*
 	 * Detect filetype
 	 */
@@ -125,6 +169,20 @@ func (s *Datastore) Ingest(ctx context.Context, datasetID string, filename strin
 
 	slog.Debug("Loading data", "type", filetype, "filename", filename, "size", len(content))
 
+	// Custom metadata attached to a previous version of this file should survive re-ingestion
+	var customMetadata map[string]any
+	var reingested bool // chunks of a file that was already ingested count as updated, not added
+	if opts.FileMetadata != nil && opts.FileMetadata.AbsolutePath != "" {
+		existing, err := s.Index.FindFileByMetadata(ctx, datasetID, types.FileMetadata{AbsolutePath: opts.FileMetadata.AbsolutePath}, false)
+		if err != nil && !errors.Is(err, types.ErrDBFileNotFound) {
+			return nil, fmt.Errorf("failed to look up existing file: %w", err)
+		}
+		if existing != nil {
+			customMetadata = existing.Metadata
+			reingested = true
+		}
+	}
+
 	/*
 	 * Exit early if the document is a duplicate
 	 */
@@ -135,6 +193,7 @@ func (s *Datastore) Ingest(ctx context.Context, datasetID string, filename strin
 	}
 	if isDupe {
 		statusLog.With("status", "skipped").With("reason", "duplicate").Info("Ignoring duplicate document")
+		IngestionStatsFromCtx(ctx).FilesSkipped.Add(1)
 		return nil, nil
 	}
 
@@ -153,6 +212,11 @@ func (s *Datastore) Ingest(ctx context.Context, datasetID string, filename strin
 		return nil, err
 	}
 
+	if loader := documentloader.EncryptedLoaderFunc(filetype, content); loader != nil {
+		slog.Debug("Loading password-protected PDF with GoPDF", "filename", filename)
+		ingestionFlow.Load = loader
+	}
+
 	if ingestionFlow.Load == nil {
 		statusLog.With("status", "skipped").With("reason", "unsupported").Info(fmt.Sprintf("Unsupported file types: %s", filetype))
 		return nil, fmt.Errorf("%w (file %q)", &documentloader.UnsupportedFileTypeError{FileType: filetype}, opts.FileMetadata.AbsolutePath)
@@ -165,6 +229,11 @@ func (s *Datastore) Ingest(ctx context.Context, datasetID string, filename strin
 			metadata[k] = v
 		}
 	}
+	for k, v := range customMetadata {
+		if _, ok := metadata[k]; !ok {
+			metadata[k] = v
+		}
+	}
 	em := &transformers.ExtraMetadata{Metadata: metadata}
 	ingestionFlow.Transformations = append(ingestionFlow.Transformations, em)
 
@@ -182,49 +251,21 @@ func (s *Datastore) Ingest(ctx context.Context, datasetID string, filename strin
 	// Sort documents
 	vs.SortAndEnsureDocIndex(docs)
 
-	// Before adding doc, we need to remove the existing documents for duplicates or old contents
-	statusLog.With("component", "vectorstore").With("action", "remove").Debug("Removing existing documents")
-	where := map[string]string{
-		"absPath": opts.FileMetadata.AbsolutePath,
-	}
-	if err := s.Vectorstore.RemoveDocument(ctx, "", datasetID, where, nil); err != nil {
-		statusLog.With("status", "failed").With("component", "vectorstore").Error("Failed to remove existing documents", "error", err)
-		return nil, err
-	}
-
-	// Add documents to VectorStore -> This generates the embeddings
-	slog.Debug("Ingesting documents", "count", len(docs), "dataset", datasetID, "file", filename)
-
 	statusLog = statusLog.With("num_documents", len(docs))
 	ctx = log.ToCtx(ctx, statusLog)
 
-	statusLog.Debug("Adding documents to vectorstore")
-	startTime := time.Now()
-	docIDs, err := s.Vectorstore.AddDocuments(ctx, docs, datasetID)
-	if err != nil {
-		statusLog.With("component", "vectorstore").With("status", "failed").With("error", err.Error()).Error("Failed to add documents")
-		return nil, fmt.Errorf("failed to add documents from file %q: %w", opts.FileMetadata.AbsolutePath, err)
-	}
-	statusLog.Debug("Added documents to vectorstore", "duration", time.Since(startTime))
-
-	// Record file and documents in database
-	dbDocs := make([]types.Document, len(docIDs))
-	for idx, docID := range docIDs {
-		dbDocs[idx] = types.Document{
-			ID:      docID,
-			FileID:  fileID,
-			Dataset: datasetID,
-			Index:   idx,
-		}
+	var estimatedTokens int64
+	for _, doc := range docs {
+		estimatedTokens += int64(len(doc.Content)/4 + 1)
 	}
 
 	dbFile := types.File{
-		ID:        fileID,
-		Dataset:   datasetID,
-		Documents: dbDocs,
+		ID:      fileID,
+		Dataset: datasetID,
 		FileMetadata: types.FileMetadata{
 			Name: filename,
 		},
+		Metadata: customMetadata,
 	}
 
 	if opts.FileMetadata != nil {
@@ -233,15 +274,91 @@ func (s *Datastore) Ingest(ctx context.Context, datasetID string, filename strin
 		dbFile.FileMetadata.ModifiedAt = opts.FileMetadata.ModifiedAt
 	}
 
-	iLog := statusLog.With("component", "index")
-	iLog.Info("Inserting file and documents into index")
-	startTime = time.Now()
-	err = s.Index.CreateFile(ctx, dbFile)
+	// The embeddings are computed before taking the lock, so other processes can write meanwhile and retries on a
+	// busy database don't compute them again
+	statusLog.Debug("Computing embeddings")
+	startTime := time.Now()
+	embedCtx, span := telemetry.StartSpan(ctx, "knowledge.embed",
+		attribute.String("knowledge.dataset", datasetID),
+		attribute.Int("knowledge.chunks", len(docs)),
+		attribute.Int64("knowledge.embedding.estimated_tokens", estimatedTokens),
+	)
+	err = s.Vectorstore.EmbedDocuments(embedCtx, docs)
+	telemetry.EndSpan(span, err)
+	telemetry.Metrics().EmbeddingLatency.Record(ctx, time.Since(startTime).Seconds(), metric.WithAttributes(
+		attribute.String("knowledge.dataset", datasetID),
+		attribute.Bool("knowledge.success", err == nil),
+	))
 	if err != nil {
-		iLog.With("status", "failed").With("error", err).Error("Failed to create file in Index")
-		return nil, fmt.Errorf("failed to create file: %w", err)
+		statusLog.With("component", "vectorstore").With("status", "failed").With("error", err.Error()).Error("Failed to compute embeddings")
+		return nil, fmt.Errorf("failed to compute embeddings for file %q: %w", opts.FileMetadata.AbsolutePath, err)
 	}
-	iLog.Info("Created file in index", "duration", time.Since(startTime))
+	statusLog.Debug("Computed embeddings", "duration", time.Since(startTime))
+
+	// Replacing the file's documents and recording them in the index happens under a single lock, so concurrent
+	// ingestions of the same file can't interleave and leave orphaned or duplicate documents behind.
+	// A retry (busy database) starts over with removing what the previous attempt added.
+	var docIDs []string
+	err = s.exclusive(ctx, []string{datasetID}, func() error {
+		// Before adding doc, we need to remove the existing documents for duplicates or old contents
+		statusLog.With("component", "vectorstore").With("action", "remove").Debug("Removing existing documents")
+		where := map[string]string{
+			"absPath": opts.FileMetadata.AbsolutePath,
+		}
+		if err := s.Vectorstore.RemoveDocument(ctx, "", datasetID, where, nil); err != nil {
+			statusLog.With("status", "failed").With("component", "vectorstore").Error("Failed to remove existing documents", "error", err)
+			return err
+		}
+
+		// Add documents to VectorStore, with the embeddings computed above
+		slog.Debug("Ingesting documents", "count", len(docs), "dataset", datasetID, "file", filename)
+
+		statusLog.Debug("Adding documents to vectorstore")
+		startTime := time.Now()
+		var err error
+		docIDs, err = s.Vectorstore.AddDocuments(ctx, docs, datasetID)
+		if err != nil {
+			statusLog.With("component", "vectorstore").With("status", "failed").With("error", err.Error()).Error("Failed to add documents")
+			return fmt.Errorf("failed to add documents from file %q: %w", opts.FileMetadata.AbsolutePath, err)
+		}
+		statusLog.Debug("Added documents to vectorstore", "duration", time.Since(startTime))
+
+		// Record file and documents in database
+		dbFile.Documents = make([]types.Document, len(docIDs))
+		for idx, docID := range docIDs {
+			dbFile.Documents[idx] = types.Document{
+				ID:      docID,
+				FileID:  fileID,
+				Dataset: datasetID,
+				Index:   idx,
+			}
+		}
+
+		iLog := statusLog.With("component", "index")
+		iLog.Info("Inserting file and documents into index")
+		startTime = time.Now()
+		if err := s.Index.CreateFile(ctx, dbFile); err != nil {
+			iLog.With("status", "failed").With("error", err).Error("Failed to create file in Index")
+			return fmt.Errorf("failed to create file: %w", err)
+		}
+		iLog.Info("Created file in index", "duration", time.Since(startTime))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stats := IngestionStatsFromCtx(ctx)
+	if reingested {
+		stats.ChunksUpdated.Add(int64(len(docIDs)))
+	} else {
+		stats.ChunksAdded.Add(int64(len(docIDs)))
+	}
+	stats.EstimatedTokens.Add(estimatedTokens)
+
+	attrs := metric.WithAttributes(attribute.String("knowledge.dataset", datasetID))
+	telemetry.Metrics().ChunksStored.Add(ctx, int64(len(docIDs)), attrs)
+	telemetry.Metrics().TokensEmbedded.Add(ctx, estimatedTokens, attrs)
 
 	statusLog.With("status", "finished").Info("Ingested document", "num_documents", len(docIDs), "absolute_path", dbFile.FileMetadata.AbsolutePath, "ingestionTime", time.Since(ingestionStart))
 
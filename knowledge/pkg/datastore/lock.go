@@ -0,0 +1,98 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gptscript-ai/knowledge/pkg/env"
+)
+
+// EnvLockTimeoutSeconds controls how long a write waits for other knowledge processes using the same local datastore. Default is 300
+const EnvLockTimeoutSeconds = "KNOW_LOCK_TIMEOUT_SECONDS"
+
+const (
+	busyRetries      = 5
+	busyRetryBackoff = 200 * time.Millisecond
+)
+
+// locker is the lock taken by exclusive: a cross-process file lock for local datastores, otherwise a processLock.
+type locker interface {
+	Lock(ctx context.Context) error
+	Unlock() error
+}
+
+// processLock serializes writes of this process for datastores without a local lock file (e.g. postgres), so
+// multi-step writes like replacing a file's documents don't interleave.
+type processLock chan struct{}
+
+func newProcessLock() processLock {
+	return make(processLock, 1)
+}
+
+func (l processLock) Lock(ctx context.Context) error {
+	select {
+	case l <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("timed out waiting for datastore lock: %w", ctx.Err())
+	}
+}
+
+func (l processLock) Unlock() error {
+	<-l
+	return nil
+}
+
+// exclusive runs a write operation on the datasets while holding the datastore lock (cross-process if the datastore
+// uses local files) and retries it if the database still reports being busy. On success, the cached retrieval results
+// of the datasets are invalidated - all of them if no datasets are given.
+func (s *Datastore) exclusive(ctx context.Context, datasetIDs []string, fn func() error) error {
+	if s.lock != nil {
+		lctx, cancel := context.WithTimeout(ctx, time.Duration(env.GetIntFromEnvOrDefault(EnvLockTimeoutSeconds, 300))*time.Second)
+		defer cancel()
+
+		if err := s.lock.Lock(lctx); err != nil {
+			return fmt.Errorf("failed to acquire datastore lock: %w", err)
+		}
+		defer func() {
+			if err := s.lock.Unlock(); err != nil {
+				slog.Warn("Failed to release datastore lock", "error", err)
+			}
+		}()
+	}
+
+	if err := retryOnBusy(ctx, fn); err != nil {
+		return err
+	}
+
+	// Every write may change retrieval results
+	s.invalidateRetrievalCache(ctx, datasetIDs)
+	return nil
+}
+
+func retryOnBusy(ctx context.Context, fn func() error) error {
+	backoff := busyRetryBackoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || !isBusyErr(err) || attempt >= busyRetries {
+			return err
+		}
+
+		slog.Debug("Database is busy, retrying", "attempt", attempt, "backoff", backoff, "error", err)
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func isBusyErr(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "database is locked") || strings.Contains(msg, "SQLITE_BUSY") || strings.Contains(msg, "database table is locked")
+}
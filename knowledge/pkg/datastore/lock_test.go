@@ -0,0 +1,23 @@
+package datastore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessLock(t *testing.T) {
+	l := newProcessLock()
+	require.NoError(t, l.Lock(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Error(t, l.Lock(ctx), "the lock is held")
+
+	require.NoError(t, l.Unlock())
+	require.NoError(t, l.Lock(context.Background()))
+	require.NoError(t, l.Unlock())
+}
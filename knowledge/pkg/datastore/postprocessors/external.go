@@ -0,0 +1,34 @@
+package postprocessors
+
+import (
+	"context"
+
+	"github.com/gptscript-ai/knowledge/pkg/datastore/transformers"
+	"github.com/gptscript-ai/knowledge/pkg/datastore/types"
+)
+
+const ExternalPostprocessorName = transformers.ExternalTransformerName
+
+// ExternalPostprocessor is like the external transformer, but also passes the retrieval query to the external step
+type ExternalPostprocessor struct {
+	transformers.ExternalStep `mapstructure:",squash"`
+}
+
+func (e *ExternalPostprocessor) Transform(ctx context.Context, response *types.RetrievalResponse) error {
+	for i, resp := range response.Responses {
+		docs, err := e.Run(ctx, transformers.ExternalStepRequest{
+			Stage:     transformers.ExternalStageRetrieval,
+			Query:     resp.Query,
+			Documents: resp.ResultDocuments,
+		})
+		if err != nil {
+			return err
+		}
+		response.Responses[i].ResultDocuments = docs
+	}
+	return nil
+}
+
+func (e *ExternalPostprocessor) Name() string {
+	return ExternalPostprocessorName
+}
@@ -65,7 +65,16 @@ var PostprocessorMap = map[string]Postprocessor{
 	BM25PostprocessorName:                        &BM25Postprocessor{},
 }
 
+// postprocessorFactories create the postprocessors whose configuration differs per step, e.g. the command of an
+// external postprocessor, so that two steps of the same kind don't share one instance
+var postprocessorFactories = map[string]func() Postprocessor{
+	ExternalPostprocessorName: func() Postprocessor { return &ExternalPostprocessor{} },
+}
+
 func GetPostprocessor(name string) (Postprocessor, error) {
+	if factory, ok := postprocessorFactories[name]; ok {
+		return factory(), nil
+	}
 	var postprocessor Postprocessor
 	var ok bool
 	postprocessor, ok = PostprocessorMap[name]
@@ -0,0 +1,153 @@
+package datastore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/gptscript-ai/knowledge/pkg/datastore/postprocessors"
+	"github.com/gptscript-ai/knowledge/pkg/datastore/types"
+	"github.com/gptscript-ai/knowledge/pkg/flows"
+	itypes "github.com/gptscript-ai/knowledge/pkg/index/types"
+)
+
+// EnvRetrievalCacheTTL enables caching retrieval results for the given time-to-live, e.g. "10m". The cache is disabled by default
+const EnvRetrievalCacheTTL = "KNOW_RETRIEVAL_CACHE_TTL"
+
+func retrievalCacheTTL() time.Duration {
+	v := os.Getenv(EnvRetrievalCacheTTL)
+	if v == "" {
+		return 0
+	}
+	ttl, err := time.ParseDuration(v)
+	if err != nil {
+		slog.Warn("Invalid retrieval cache TTL - disabling the cache", "env", EnvRetrievalCacheTTL, "value", v)
+		return 0
+	}
+	return ttl
+}
+
+// retrievalCacheKey hashes everything that influences the retrieval result. An empty key means that the request
+// can't be cached, e.g. because the retrieval flow can't be serialized or calls external postprocessors, whose results
+// depend on the caller and on state outside of the datastore.
+func (s *Datastore) retrievalCacheKey(datasetIDs []string, query string, topK int, keywords []string, flow *flows.RetrievalFlow) string {
+	for _, pp := range flow.Postprocessors {
+		if pp.Name() == postprocessors.ExternalPostprocessorName {
+			return ""
+		}
+	}
+
+	datasets := slices.Clone(datasetIDs)
+	slices.Sort(datasets)
+
+	// Component names are included, as different components may have identically shaped configurations
+	type component struct {
+		Name   string `json:"name"`
+		Config any    `json:"config"`
+	}
+	var components []component
+	for _, qm := range flow.QueryModifiers {
+		components = append(components, component{"querymodifier:" + qm.Name(), qm})
+	}
+	if flow.Retriever != nil {
+		components = append(components, component{"retriever:" + flow.Retriever.Name(), flow.Retriever})
+	}
+	for _, pp := range flow.Postprocessors {
+		components = append(components, component{"postprocessor:" + pp.Name(), pp})
+	}
+
+	flowJSON, err := json.Marshal(components)
+	if err != nil {
+		slog.Debug("Retrieval flow is not cacheable", "error", err)
+		return ""
+	}
+
+	var model string
+	if s.EmbeddingModelProvider != nil {
+		model = s.EmbeddingModelProvider.EmbeddingModelName()
+	}
+
+	key, err := json.Marshal(map[string]any{
+		"query":    query,
+		"datasets": datasets,
+		"topK":     topK,
+		"keywords": keywords,
+		"flow":     json.RawMessage(flowJSON),
+		"model":    model,
+	})
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *Datastore) getCachedRetrieval(ctx context.Context, key string) *types.RetrievalResponse {
+	entry, err := s.Index.GetRetrievalCacheEntry(ctx, key)
+	if err != nil {
+		slog.Warn("Failed to read retrieval cache", "error", err)
+		return nil
+	}
+	if entry == nil {
+		return nil
+	}
+
+	var resp types.RetrievalResponse
+	if err := json.Unmarshal(entry.Response, &resp); err != nil {
+		slog.Warn("Failed to decode cached retrieval response", "error", err)
+		return nil
+	}
+	return &resp
+}
+
+func (s *Datastore) putCachedRetrieval(ctx context.Context, key string, ttl time.Duration, datasetIDs []string, resp *types.RetrievalResponse) {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		slog.Warn("Failed to encode retrieval response for caching", "error", err)
+		return
+	}
+
+	datasets := make([]itypes.RetrievalCacheDataset, 0, len(datasetIDs))
+	for _, id := range datasetIDs {
+		datasets = append(datasets, itypes.RetrievalCacheDataset{Key: key, DatasetID: id})
+	}
+
+	now := time.Now()
+	if err := s.Index.PutRetrievalCacheEntry(ctx, itypes.RetrievalCacheEntry{
+		Key:       key,
+		Response:  data,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+		Datasets:  datasets,
+	}); err != nil {
+		slog.Warn("Failed to write retrieval cache", "error", err)
+	}
+}
+
+// invalidateRetrievalCache drops the cached results of the datasets after their contents changed, or all cached
+// results if no datasets are given
+func (s *Datastore) invalidateRetrievalCache(ctx context.Context, datasetIDs []string) {
+	var err error
+	if len(datasetIDs) == 0 {
+		err = s.Index.ClearRetrievalCache(ctx)
+	} else {
+		err = s.Index.InvalidateRetrievalCache(ctx, datasetIDs)
+	}
+	if err != nil {
+		slog.Warn("Failed to invalidate retrieval cache", "dataset", datasetIDs, "error", err)
+	}
+}
+
+func (s *Datastore) RetrievalCacheStats(ctx context.Context) (*itypes.RetrievalCacheStats, error) {
+	return s.Index.GetRetrievalCacheStats(ctx)
+}
+
+func (s *Datastore) ClearRetrievalCache(ctx context.Context) error {
+	return s.Index.ClearRetrievalCache(ctx)
+}
@@ -0,0 +1,59 @@
+package datastore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gptscript-ai/knowledge/pkg/datastore/postprocessors"
+	"github.com/gptscript-ai/knowledge/pkg/datastore/retrievers"
+	"github.com/gptscript-ai/knowledge/pkg/datastore/transformers"
+	"github.com/gptscript-ai/knowledge/pkg/datastore/types"
+	"github.com/gptscript-ai/knowledge/pkg/flows"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetrievalCacheTTL(t *testing.T) {
+	t.Setenv(EnvRetrievalCacheTTL, "")
+	assert.Zero(t, retrievalCacheTTL(), "the cache is disabled by default")
+
+	t.Setenv(EnvRetrievalCacheTTL, "10m")
+	assert.Equal(t, 10*time.Minute, retrievalCacheTTL())
+
+	t.Setenv(EnvRetrievalCacheTTL, "soon")
+	assert.Zero(t, retrievalCacheTTL())
+}
+
+func TestRetrievalCacheKey(t *testing.T) {
+	s := &Datastore{}
+	flow := &flows.RetrievalFlow{Retriever: &retrievers.BasicRetriever{TopK: 5}}
+
+	key := s.retrievalCacheKey([]string{"b", "a"}, "query", 5, nil, flow)
+	require.NotEmpty(t, key)
+	assert.Equal(t, key, s.retrievalCacheKey([]string{"a", "b"}, "query", 5, nil, flow), "the order of the datasets doesn't matter")
+	assert.NotEqual(t, key, s.retrievalCacheKey([]string{"a", "b"}, "other query", 5, nil, flow))
+
+	flow.Postprocessors = []postprocessors.Postprocessor{&postprocessors.ExternalPostprocessor{ExternalStep: transformers.ExternalStep{Command: "rerank"}}}
+	assert.Empty(t, s.retrievalCacheKey([]string{"a", "b"}, "query", 5, nil, flow), "external postprocessors are not cached")
+}
+
+func TestRetrievalCacheInvalidatesChangedDatasets(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestDatastore(t)
+
+	s.putCachedRetrieval(ctx, "one", time.Hour, []string{"one"}, &types.RetrievalResponse{Query: "one"})
+	s.putCachedRetrieval(ctx, "both", time.Hour, []string{"one", "two"}, &types.RetrievalResponse{Query: "both"})
+	s.putCachedRetrieval(ctx, "two", time.Hour, []string{"two"}, &types.RetrievalResponse{Query: "two"})
+
+	require.NoError(t, s.exclusive(ctx, []string{"one"}, func() error { return nil }))
+	assert.Nil(t, s.getCachedRetrieval(ctx, "one"))
+	assert.Nil(t, s.getCachedRetrieval(ctx, "both"))
+	if resp := s.getCachedRetrieval(ctx, "two"); assert.NotNil(t, resp) {
+		assert.Equal(t, "two", resp.Query)
+	}
+
+	// Without datasets (e.g. an import of all datasets) everything is dropped
+	require.NoError(t, s.exclusive(ctx, nil, func() error { return nil }))
+	assert.Nil(t, s.getCachedRetrieval(ctx, "two"))
+}
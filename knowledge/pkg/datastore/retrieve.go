@@ -4,14 +4,18 @@ import (
 	"context"
 	"log/slog"
 	"os"
+	"time"
 
 	"github.com/gptscript-ai/knowledge/pkg/datastore/embeddings"
 	etypes "github.com/gptscript-ai/knowledge/pkg/datastore/embeddings/types"
 	"github.com/gptscript-ai/knowledge/pkg/datastore/types"
 	"github.com/gptscript-ai/knowledge/pkg/output"
+	"github.com/gptscript-ai/knowledge/pkg/telemetry"
 	types2 "github.com/gptscript-ai/knowledge/pkg/vectorstore/types"
 	"github.com/mitchellh/copystructure"
 	"github.com/philippgille/chromem-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 
 	"github.com/gptscript-ai/knowledge/pkg/datastore/defaults"
 	"github.com/gptscript-ai/knowledge/pkg/flows"
@@ -23,9 +27,29 @@ type RetrieveOpts struct {
 	TopK          int
 	Keywords      []string
 	RetrievalFlow *flows.RetrievalFlow
+	// FeedbackWeight enables re-scoring of the results based on the recorded relevance feedback (see RecordFeedback):
+	// the similarity score of a document is shifted by up to +/- FeedbackWeight. 0 disables it.
+	FeedbackWeight float32
 }
 
 func (s *Datastore) Retrieve(ctx context.Context, datasetIDs []string, query string, opts RetrieveOpts) (*types.RetrievalResponse, error) {
+	start := time.Now()
+	ctx, span := telemetry.StartSpan(ctx, "knowledge.retrieve", attribute.StringSlice("knowledge.datasets", datasetIDs))
+
+	resp, err := s.retrieve(ctx, datasetIDs, query, opts)
+
+	if resp != nil {
+		span.SetAttributes(attribute.Bool("knowledge.cache_hit", resp.Stats.CacheHit))
+		telemetry.Metrics().QueryLatency.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(attribute.Bool("knowledge.cache_hit", resp.Stats.CacheHit)))
+	}
+	if err != nil {
+		telemetry.Metrics().Errors.Add(ctx, 1, metric.WithAttributes(attribute.String("knowledge.operation", "retrieve")))
+	}
+	telemetry.EndSpan(span, err)
+	return resp, err
+}
+
+func (s *Datastore) retrieve(ctx context.Context, datasetIDs []string, query string, opts RetrieveOpts) (*types.RetrievalResponse, error) {
 	slog.Debug("Retrieving content from dataset", "dataset", datasetIDs, "query", query)
 
 	retrievalFlow := opts.RetrievalFlow
@@ -69,10 +93,48 @@ func (s *Datastore) Retrieve(ctx context.Context, datasetIDs []string, query str
 		}
 	}
 
-	return retrievalFlow.Run(ctx, s, query, datasetIDs, &flows.RetrievalFlowOpts{Where: nil, WhereDocument: whereDocs})
+	var cacheKey string
+	ttl := retrievalCacheTTL()
+	if ttl > 0 {
+		cacheKey = s.retrievalCacheKey(datasetIDs, query, topK, opts.Keywords, retrievalFlow)
+	}
+	if cacheKey != "" {
+		if resp := s.getCachedRetrieval(ctx, cacheKey); resp != nil {
+			slog.Debug("Retrieval cache hit", "dataset", datasetIDs, "query", query)
+			resp.Stats.CacheHit = true
+			return resp, s.maybeApplyFeedback(ctx, resp, opts.FeedbackWeight)
+		}
+	}
+
+	resp, err := retrievalFlow.Run(ctx, s, query, datasetIDs, &flows.RetrievalFlowOpts{Where: nil, WhereDocument: whereDocs})
+	if err != nil {
+		return nil, err
+	}
+
+	// Feedback is applied on top of the cached result, as it may change at any time
+	if cacheKey != "" {
+		s.putCachedRetrieval(ctx, cacheKey, ttl, datasetIDs, resp)
+	}
+	return resp, s.maybeApplyFeedback(ctx, resp, opts.FeedbackWeight)
 }
 
-func (s *Datastore) SimilaritySearch(ctx context.Context, query string, numDocuments int, datasetID string, where map[string]string, whereDocument []chromem.WhereDocument) ([]types2.Document, error) {
+func (s *Datastore) maybeApplyFeedback(ctx context.Context, resp *types.RetrievalResponse, weight float32) error {
+	if weight == 0 {
+		return nil
+	}
+	return s.applyFeedback(ctx, resp, weight)
+}
+
+func (s *Datastore) SimilaritySearch(ctx context.Context, query string, numDocuments int, datasetID string, where map[string]string, whereDocument []chromem.WhereDocument) (docs []types2.Document, err error) {
+	ctx, span := telemetry.StartSpan(ctx, "knowledge.vector_search",
+		attribute.String("knowledge.dataset", datasetID),
+		attribute.Int("knowledge.top_k", numDocuments),
+	)
+	defer func() {
+		span.SetAttributes(attribute.Int("knowledge.results", len(docs)))
+		telemetry.EndSpan(span, err)
+	}()
+
 	ds, err := s.GetDataset(ctx, datasetID)
 	if err != nil {
 		return nil, err
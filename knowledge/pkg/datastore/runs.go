@@ -0,0 +1,81 @@
+package datastore
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gptscript-ai/knowledge/pkg/index/types"
+)
+
+// EnvEmbeddingCostPer1MTokens can be set to the embedding model price (USD per 1M tokens) to get cost estimates in ingestion runs
+const EnvEmbeddingCostPer1MTokens = "KNOW_EMBEDDING_COST_PER_1M_TOKENS"
+
+// IngestionStats collects the counters of an ingestion run - it's passed down via the context,
+// so concurrent ingestion of many files can update it.
+type IngestionStats struct {
+	FilesIngested    atomic.Int64
+	FilesSkipped     atomic.Int64
+	FilesUnsupported atomic.Int64
+	FilesFailed      atomic.Int64
+	FilesPruned      atomic.Int64
+	ChunksAdded      atomic.Int64
+	ChunksUpdated    atomic.Int64
+	ChunksDeleted    atomic.Int64
+	EstimatedTokens  atomic.Int64
+}
+
+type ingestionStatsKey struct{}
+
+// WithIngestionStats attaches a new IngestionStats collector to the context.
+func WithIngestionStats(ctx context.Context) (context.Context, *IngestionStats) {
+	stats := &IngestionStats{}
+	return context.WithValue(ctx, ingestionStatsKey{}, stats), stats
+}
+
+// IngestionStatsFromCtx returns the collector attached to the context or a throwaway one, so callers never need nil checks.
+func IngestionStatsFromCtx(ctx context.Context) *IngestionStats {
+	if stats, ok := ctx.Value(ingestionStatsKey{}).(*IngestionStats); ok {
+		return stats
+	}
+	return &IngestionStats{}
+}
+
+// RecordIngestionRun persists the summary of an ingestion run in the index.
+func (s *Datastore) RecordIngestionRun(ctx context.Context, datasetID string, sources []string, startedAt time.Time, stats *IngestionStats, runErr error) (*types.IngestionRun, error) {
+	finishedAt := time.Now()
+	run := types.IngestionRun{
+		ID:               uuid.NewString(),
+		Dataset:          datasetID,
+		Sources:          sources,
+		StartedAt:        startedAt,
+		FinishedAt:       finishedAt,
+		DurationSeconds:  finishedAt.Sub(startedAt).Seconds(),
+		FilesIngested:    stats.FilesIngested.Load(),
+		FilesSkipped:     stats.FilesSkipped.Load(),
+		FilesUnsupported: stats.FilesUnsupported.Load(),
+		FilesFailed:      stats.FilesFailed.Load(),
+		FilesPruned:      stats.FilesPruned.Load(),
+		ChunksAdded:      stats.ChunksAdded.Load(),
+		ChunksUpdated:    stats.ChunksUpdated.Load(),
+		ChunksDeleted:    stats.ChunksDeleted.Load(),
+		EstimatedTokens:  stats.EstimatedTokens.Load(),
+	}
+
+	if price, err := strconv.ParseFloat(os.Getenv(EnvEmbeddingCostPer1MTokens), 64); err == nil {
+		run.EstimatedCostUSD = float64(run.EstimatedTokens) / 1_000_000 * price
+	}
+
+	if runErr != nil {
+		run.Error = runErr.Error()
+	}
+
+	return &run, s.Index.CreateIngestionRun(ctx, run)
+}
+
+func (s *Datastore) ListIngestionRuns(ctx context.Context, datasetID string, limit int) ([]types.IngestionRun, error) {
+	return s.Index.ListIngestionRuns(ctx, datasetID, limit)
+}
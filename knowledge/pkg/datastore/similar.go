@@ -0,0 +1,207 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/gptscript-ai/knowledge/pkg/datastore/defaults"
+	dstypes "github.com/gptscript-ai/knowledge/pkg/datastore/types"
+	"github.com/gptscript-ai/knowledge/pkg/index/types"
+	vs "github.com/gptscript-ai/knowledge/pkg/vectorstore/types"
+)
+
+// maxSimilarSourceChunks limits the number of chunks of a source file that are used as queries
+const maxSimilarSourceChunks = 10
+
+type SimilarOpts struct {
+	TopK int
+	// Datasets to search in - defaults to the dataset of the source document
+	Datasets []string
+	// IncludeSameFile also returns chunks of the file the source belongs to
+	IncludeSameFile bool
+}
+
+// RetrieveSimilar finds the content most similar to an already indexed document (chunk) or file (more-like-this).
+// Exactly one of documentID or fileID must be set.
+func (s *Datastore) RetrieveSimilar(ctx context.Context, datasetID, documentID, fileID string, opts SimilarOpts) (*dstypes.RetrievalResponse, error) {
+	startTime := time.Now()
+
+	if (documentID == "") == (fileID == "") {
+		return nil, fmt.Errorf("exactly one of document ID or file ID must be provided")
+	}
+
+	topK := defaults.TopK
+	if opts.TopK > 0 {
+		topK = opts.TopK
+	}
+
+	datasets := opts.Datasets
+	if len(datasets) == 0 {
+		datasets = []string{datasetID}
+	}
+
+	if fileID == "" {
+		doc, err := s.Index.GetDocument(ctx, documentID, datasetID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find document %q: %w", documentID, err)
+		}
+		fileID = doc.FileID
+	}
+
+	file, err := s.Index.FindFile(ctx, types.File{ID: fileID, Dataset: datasetID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find file %q: %w", fileID, err)
+	}
+
+	sourceChunks, err := s.fileChunks(ctx, datasetID, file)
+	if err != nil {
+		return nil, err
+	}
+	if documentID != "" {
+		sourceChunks = slices.DeleteFunc(sourceChunks, func(d vs.Document) bool { return d.ID != documentID })
+	}
+	if len(sourceChunks) == 0 {
+		return nil, fmt.Errorf("no content found for the requested source in dataset %q", datasetID)
+	}
+	if len(sourceChunks) > maxSimilarSourceChunks {
+		slog.Debug("Limiting number of source chunks for similarity search", "total", len(sourceChunks), "limit", maxSimilarSourceChunks)
+		sourceChunks = sourceChunks[:maxSimilarSourceChunks]
+	}
+
+	sourceIDs := make(map[string]struct{}, len(file.Documents))
+	for _, d := range file.Documents {
+		sourceIDs[d.ID] = struct{}{}
+	}
+
+	// The stored vectors of the source chunks are the queries, so nothing has to be re-embedded
+	chunkIDs := make([]string, len(sourceChunks))
+	for i, chunk := range sourceChunks {
+		chunkIDs[i] = chunk.ID
+	}
+	vectors, err := s.Vectorstore.GetEmbeddings(ctx, datasetID, chunkIDs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get embeddings of the source: %w", err)
+	}
+
+	if err := s.checkComparableEmbeddings(ctx, datasetID, datasets); err != nil {
+		return nil, err
+	}
+
+	// Each source chunk is used as a query - results are merged, keeping the best score per document
+	best := map[string]vs.Document{}
+	for _, ds := range datasets {
+		for i, chunk := range sourceChunks {
+			// fetch some extra results, since the source file's own chunks are usually the best matches
+			docs, err := s.Vectorstore.SimilaritySearchByVector(ctx, vectors[i], topK+len(sourceIDs), ds, nil, nil)
+			if err != nil {
+				return nil, fmt.Errorf("similarity search in dataset %q failed: %w", ds, err)
+			}
+			for _, doc := range docs {
+				if _, isSource := sourceIDs[doc.ID]; isSource && (!opts.IncludeSameFile || doc.ID == chunk.ID) {
+					continue
+				}
+				if !opts.IncludeSameFile && file.AbsolutePath != "" && doc.Metadata["absPath"] == file.AbsolutePath {
+					continue
+				}
+				if existing, ok := best[doc.ID]; !ok || existing.SimilarityScore < doc.SimilarityScore {
+					best[doc.ID] = doc
+				}
+			}
+		}
+	}
+
+	results := make([]vs.Document, 0, len(best))
+	for _, doc := range best {
+		results = append(results, doc)
+	}
+	slices.SortFunc(results, func(a, b vs.Document) int {
+		switch {
+		case a.SimilarityScore > b.SimilarityScore:
+			return -1
+		case a.SimilarityScore < b.SimilarityScore:
+			return 1
+		default:
+			return 0
+		}
+	})
+	if len(results) > topK {
+		results = results[:topK]
+	}
+
+	query := "similar to file " + fileID
+	if documentID != "" {
+		query = "similar to document " + documentID
+	}
+
+	return &dstypes.RetrievalResponse{
+		Query:    query,
+		Datasets: datasets,
+		Responses: []dstypes.Response{
+			{
+				Query:           query,
+				NumDocs:         len(results),
+				ResultDocuments: results,
+			},
+		},
+		Stats: dstypes.Stats{
+			RetrievalTimeSeconds: time.Since(startTime).Seconds(),
+		},
+	}, nil
+}
+
+// checkComparableEmbeddings makes sure that the vectors of the source dataset can be compared with those of the
+// datasets to search in, i.e. that they were created with the same embeddings configuration.
+func (s *Datastore) checkComparableEmbeddings(ctx context.Context, sourceID string, datasetIDs []string) error {
+	src, err := s.GetDataset(ctx, sourceID)
+	if err != nil {
+		return err
+	}
+	if src == nil {
+		return fmt.Errorf("dataset not found: %s", sourceID)
+	}
+
+	for _, id := range datasetIDs {
+		if id == sourceID {
+			continue
+		}
+		ds, err := s.GetDataset(ctx, id)
+		if err != nil {
+			return err
+		}
+		if ds == nil {
+			return fmt.Errorf("dataset not found: %s", id)
+		}
+		if src.EmbeddingsProviderConfig != nil && ds.EmbeddingsProviderConfig != nil && !reflect.DeepEqual(src.EmbeddingsProviderConfig, ds.EmbeddingsProviderConfig) {
+			return fmt.Errorf("datasets %q and %q use different embeddings configurations", sourceID, id)
+		}
+	}
+	return nil
+}
+
+// fileChunks returns the vectorstore documents belonging to the given file
+func (s *Datastore) fileChunks(ctx context.Context, datasetID string, file *types.File) ([]vs.Document, error) {
+	var where map[string]string
+	if file.AbsolutePath != "" {
+		where = map[string]string{"absPath": file.AbsolutePath}
+	}
+
+	docs, err := s.Vectorstore.GetDocuments(ctx, datasetID, where, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get documents of file %q: %w", file.ID, err)
+	}
+
+	ids := make(map[string]struct{}, len(file.Documents))
+	for _, d := range file.Documents {
+		ids[d.ID] = struct{}{}
+	}
+	docs = slices.DeleteFunc(docs, func(d vs.Document) bool {
+		_, ok := ids[d.ID]
+		return !ok
+	})
+
+	return docs, nil
+}
@@ -0,0 +1,41 @@
+package datastore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gptscript-ai/knowledge/pkg/index/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetrieveSimilarUsesStoredVectors(t *testing.T) {
+	ctx := context.Background()
+	ds, ef := newTestDatastore(t)
+
+	require.NoError(t, ds.CreateDataset(ctx, types.Dataset{ID: "ds"}, nil))
+	addTestFile(t, ds, "ds", "f1", "/docs/apple.txt", "apple pie")
+	addTestFile(t, ds, "ds", "f2", "/docs/apples.txt", "apple crumble")
+	addTestFile(t, ds, "ds", "f3", "/docs/banana.txt", "banana bread")
+
+	embedCalls := ef.calls
+	resp, err := ds.RetrieveSimilar(ctx, "ds", "", "f1", SimilarOpts{TopK: 1})
+	require.NoError(t, err)
+	assert.Equal(t, embedCalls, ef.calls, "nothing may be re-embedded")
+
+	require.Len(t, resp.Responses, 1)
+	docs := resp.Responses[0].ResultDocuments
+	require.Len(t, docs, 1)
+	assert.Equal(t, "apple crumble", docs[0].Content, "the source file itself is excluded")
+
+	resp, err = ds.RetrieveSimilar(ctx, "ds", "f3-a", "", SimilarOpts{TopK: 3})
+	require.NoError(t, err)
+	docs = resp.Responses[0].ResultDocuments
+	require.Len(t, docs, 2)
+	for _, d := range docs {
+		assert.NotEqual(t, "banana bread", d.Content)
+	}
+
+	_, err = ds.RetrieveSimilar(ctx, "ds", "f3-a", "f3", SimilarOpts{})
+	assert.Error(t, err, "exactly one of document and file ID")
+}
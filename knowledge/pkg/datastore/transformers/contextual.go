@@ -0,0 +1,170 @@
+package transformers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/adrg/xdg"
+	"github.com/gptscript-ai/knowledge/pkg/llm"
+	vs "github.com/gptscript-ai/knowledge/pkg/vectorstore/types"
+	"golang.org/x/sync/errgroup"
+)
+
+const ContextualEnrichmentName = "contextual"
+
+// ContextualEnrichment asks the LLM for a short sentence situating each chunk within its whole document and
+// prepends it to the chunk content before it is embedded ("contextual retrieval").
+// Generated contexts are cached on disk, keyed by model, document and chunk content, so re-ingesting unchanged
+// files doesn't cost any additional LLM calls.
+type ContextualEnrichment struct {
+	Model llm.LLMConfig
+	// MaxDocumentChars limits how much of the full document is sent along with each chunk. Default is 20000
+	MaxDocumentChars int
+	// Concurrency is the number of parallel LLM requests. Default is 4
+	Concurrency int
+	// CacheDir defaults to $XDG_CACHE_HOME/gptscript/knowledge/contextual
+	CacheDir     string
+	DisableCache bool
+	// Prompt overrides the default prompt template - it receives {.document} and {.chunk}
+	Prompt string
+}
+
+var contextualTpl = `<document>
+{.document}
+</document>
+Here is the chunk we want to situate within the whole document:
+<chunk>
+{.chunk}
+</chunk>
+Please give a short succinct context (one or two sentences) to situate this chunk within the overall document for the purposes of improving search retrieval of the chunk.
+Answer only with the succinct context and nothing else.`
+
+func (c *ContextualEnrichment) Transform(ctx context.Context, docs []vs.Document) ([]vs.Document, error) {
+	if len(docs) == 0 {
+		return docs, nil
+	}
+
+	m, err := llm.NewFromConfig(c.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	tpl := contextualTpl
+	if c.Prompt != "" {
+		tpl = c.Prompt
+	}
+
+	document := c.documentContent(docs)
+
+	cacheDir := c.CacheDir
+	if cacheDir == "" && !c.DisableCache {
+		cacheDir = filepath.Join(xdg.CacheHome, "gptscript", "knowledge", "contextual")
+	}
+
+	concurrency := c.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+
+	slog.Debug("Enriching documents with contextual information", "num_documents", len(docs), "model", c.Model.OpenAI.Model)
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(concurrency)
+	for i := range docs {
+		g.Go(func() error {
+			key := c.cacheKey(tpl, document, docs[i].Content)
+
+			chunkContext, ok := c.cached(cacheDir, key)
+			if !ok {
+				res, err := m.Prompt(gctx, tpl, map[string]any{"document": document, "chunk": docs[i].Content})
+				if err != nil {
+					return fmt.Errorf("failed to generate context for document %d: %w", i, err)
+				}
+				chunkContext = strings.TrimSpace(res)
+				c.store(cacheDir, key, chunkContext)
+			}
+
+			if chunkContext == "" {
+				return nil
+			}
+			if docs[i].Metadata == nil {
+				docs[i].Metadata = map[string]any{}
+			}
+			docs[i].Metadata["context"] = chunkContext
+			docs[i].Content = chunkContext + "\n\n" + docs[i].Content
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// documentContent reassembles the full document from its chunks, as the transformers only see the split documents
+func (c *ContextualEnrichment) documentContent(docs []vs.Document) string {
+	maxChars := c.MaxDocumentChars
+	if maxChars <= 0 {
+		maxChars = 20000
+	}
+
+	var sb strings.Builder
+	for _, doc := range docs {
+		if sb.Len() >= maxChars {
+			break
+		}
+		sb.WriteString(doc.Content)
+		sb.WriteString("\n")
+	}
+
+	content := sb.String()
+	if len(content) > maxChars {
+		content = content[:maxChars]
+	}
+	return content
+}
+
+func (c *ContextualEnrichment) cacheKey(tpl, document, chunk string) string {
+	h := sha256.New()
+	for _, s := range []string{c.Model.OpenAI.BaseURL, c.Model.OpenAI.Model, tpl, document, chunk} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *ContextualEnrichment) cached(dir, key string) (string, bool) {
+	if c.DisableCache {
+		return "", false
+	}
+	b, err := os.ReadFile(filepath.Join(dir, key[:2], key))
+	if err != nil {
+		return "", false
+	}
+	return string(b), true
+}
+
+func (c *ContextualEnrichment) store(dir, key, value string) {
+	if c.DisableCache {
+		return
+	}
+	path := filepath.Join(dir, key[:2], key)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		slog.Warn("Failed to create contextual enrichment cache directory", "error", err)
+		return
+	}
+	if err := os.WriteFile(path, []byte(value), 0600); err != nil {
+		slog.Warn("Failed to write contextual enrichment cache entry", "error", err)
+	}
+}
+
+func (c *ContextualEnrichment) Name() string {
+	return ContextualEnrichmentName
+}
@@ -0,0 +1,102 @@
+package transformers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gptscript-ai/knowledge/pkg/datastore/embeddings/openai"
+	"github.com/gptscript-ai/knowledge/pkg/llm"
+	vs "github.com/gptscript-ai/knowledge/pkg/vectorstore/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextualEnrichmentDocumentContent(t *testing.T) {
+	c := &ContextualEnrichment{MaxDocumentChars: 10}
+	docs := []vs.Document{{Content: "first"}, {Content: "second"}, {Content: "third"}}
+	assert.Equal(t, "first\nseco", c.documentContent(docs))
+
+	c.MaxDocumentChars = 0
+	assert.Equal(t, "first\nsecond\nthird\n", c.documentContent(docs))
+}
+
+func TestContextualEnrichmentCacheKey(t *testing.T) {
+	c := &ContextualEnrichment{Model: llm.LLMConfig{OpenAI: openai.OpenAIConfig{Model: "gpt-4o"}}}
+	key := c.cacheKey(contextualTpl, "document", "chunk")
+	assert.Len(t, key, 64)
+	assert.Equal(t, key, c.cacheKey(contextualTpl, "document", "chunk"))
+	assert.NotEqual(t, key, c.cacheKey(contextualTpl, "document", "other chunk"))
+	// The parts are separated, so moving text from one to the other changes the key
+	assert.NotEqual(t, key, c.cacheKey(contextualTpl, "documentchunk", ""))
+
+	other := &ContextualEnrichment{Model: llm.LLMConfig{OpenAI: openai.OpenAIConfig{Model: "gpt-4o-mini"}}}
+	assert.NotEqual(t, key, other.cacheKey(contextualTpl, "document", "chunk"))
+}
+
+func TestContextualEnrichmentCache(t *testing.T) {
+	dir := t.TempDir()
+	c := &ContextualEnrichment{CacheDir: dir}
+	key := c.cacheKey(contextualTpl, "document", "chunk")
+
+	_, ok := c.cached(dir, key)
+	assert.False(t, ok)
+
+	c.store(dir, key, "The chunk is about testing.")
+	value, ok := c.cached(dir, key)
+	assert.True(t, ok)
+	assert.Equal(t, "The chunk is about testing.", value)
+
+	c.DisableCache = true
+	_, ok = c.cached(dir, key)
+	assert.False(t, ok)
+}
+
+func TestContextualEnrichmentTransform(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/chat/completions") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		requests.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id":      "chatcmpl-1",
+			"object":  "chat.completion",
+			"model":   "gpt-4o",
+			"choices": []map[string]any{{"index": 0, "finish_reason": "stop", "message": map[string]any{"role": "assistant", "content": " Context of the chunk. "}}},
+			"usage":   map[string]any{"prompt_tokens": 1, "completion_tokens": 1, "total_tokens": 2},
+		})
+	}))
+	defer srv.Close()
+
+	c := &ContextualEnrichment{
+		Model:    llm.LLMConfig{OpenAI: openai.OpenAIConfig{APIKey: "sk-test", BaseURL: srv.URL + "/v1", Model: "gpt-4o"}},
+		CacheDir: t.TempDir(),
+	}
+	newDocs := func() []vs.Document {
+		return []vs.Document{{Content: "first"}, {Content: "second", Metadata: map[string]any{"source": "a.md"}}}
+	}
+
+	docs, err := c.Transform(context.Background(), newDocs())
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.Equal(t, int32(2), requests.Load())
+	assert.Equal(t, "Context of the chunk.\n\nfirst", docs[0].Content)
+	assert.Equal(t, "Context of the chunk.", docs[0].Metadata["context"])
+	assert.Equal(t, "a.md", docs[1].Metadata["source"])
+
+	// Unchanged chunks are served from the cache
+	docs, err = c.Transform(context.Background(), newDocs())
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), requests.Load())
+	assert.Equal(t, "Context of the chunk.\n\nsecond", docs[1].Content)
+
+	docs, err = c.Transform(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Empty(t, docs)
+}
@@ -0,0 +1,151 @@
+package transformers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"time"
+
+	vs "github.com/gptscript-ai/knowledge/pkg/vectorstore/types"
+)
+
+const ExternalTransformerName = "external"
+
+// ExternalStepRequest is the JSON payload sent to an external flow step, either on stdin (Command) or as the request body (URL).
+type ExternalStepRequest struct {
+	// Stage is either "ingestion" or "retrieval"
+	Stage string `json:"stage"`
+	// Query is only set in the retrieval stage
+	Query     string         `json:"query,omitempty"`
+	Documents []vs.Document  `json:"documents"`
+	Options   map[string]any `json:"options,omitempty"`
+}
+
+// ExternalStepResponse is the JSON payload expected back from an external flow step.
+// The returned documents fully replace the input documents, so a step can filter, reorder or enrich them.
+type ExternalStepResponse struct {
+	Documents []vs.Document `json:"documents"`
+	Error     string        `json:"error,omitempty"`
+}
+
+const (
+	ExternalStageIngestion = "ingestion"
+	ExternalStageRetrieval = "retrieval"
+)
+
+// ExternalStep calls a user-provided binary or HTTP endpoint. Exactly one of Command or URL must be set.
+type ExternalStep struct {
+	Command string            `json:"command,omitempty" mapstructure:"command"`
+	Args    []string          `json:"args,omitempty" mapstructure:"args"`
+	Env     map[string]string `json:"env,omitempty" mapstructure:"env"`
+	URL     string            `json:"url,omitempty" mapstructure:"url"`
+	Headers map[string]string `json:"headers,omitempty" mapstructure:"headers"`
+	// Timeout in seconds, defaults to 60
+	Timeout int `json:"timeout,omitempty" mapstructure:"timeout"`
+	// Options are passed through to the external step as-is
+	Options map[string]any `json:"options,omitempty" mapstructure:"options"`
+}
+
+func (e *ExternalStep) Run(ctx context.Context, req ExternalStepRequest) ([]vs.Document, error) {
+	if (e.Command == "") == (e.URL == "") {
+		return nil, fmt.Errorf("external step requires exactly one of command or url")
+	}
+
+	timeout := 60 * time.Second
+	if e.Timeout > 0 {
+		timeout = time.Duration(e.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req.Options = e.Options
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal external step request: %w", err)
+	}
+
+	var out []byte
+	if e.Command != "" {
+		out, err = e.exec(ctx, payload)
+	} else {
+		out, err = e.post(ctx, payload)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var resp ExternalStepResponse
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse external step response: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("external step failed: %s", resp.Error)
+	}
+
+	slog.Debug("External step finished", "stage", req.Stage, "inputDocs", len(req.Documents), "outputDocs", len(resp.Documents))
+	return resp.Documents, nil
+}
+
+func (e *ExternalStep) exec(ctx context.Context, payload []byte) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, e.Command, e.Args...)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	for k, v := range e.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run external step %q: %w", e.Command, err)
+	}
+	return out, nil
+}
+
+func (e *ExternalStep) post(ctx context.Context, payload []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.URL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call external step %q: %w", e.URL, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read external step response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("external step %q returned status %d: %s", e.URL, resp.StatusCode, string(body))
+	}
+	return body, nil
+}
+
+// ExternalTransformer hands documents off to an ExternalStep during ingestion.
+type ExternalTransformer struct {
+	ExternalStep `mapstructure:",squash"`
+}
+
+func (e *ExternalTransformer) Transform(ctx context.Context, docs []vs.Document) ([]vs.Document, error) {
+	return e.Run(ctx, ExternalStepRequest{
+		Stage:     ExternalStageIngestion,
+		Documents: docs,
+	})
+}
+
+func (e *ExternalTransformer) Name() string {
+	return ExternalTransformerName
+}
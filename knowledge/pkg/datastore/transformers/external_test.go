@@ -0,0 +1,56 @@
+package transformers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	vs "github.com/gptscript-ai/knowledge/pkg/vectorstore/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExternalTransformerHTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ExternalStepRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, ExternalStageIngestion, req.Stage)
+		assert.Equal(t, "secret", r.Header.Get("X-Token"))
+
+		// drop the first document, tag the rest
+		docs := req.Documents[1:]
+		for i := range docs {
+			docs[i].Metadata["tagged"] = true
+		}
+		_ = json.NewEncoder(w).Encode(ExternalStepResponse{Documents: docs})
+	}))
+	defer srv.Close()
+
+	tf := &ExternalTransformer{ExternalStep{URL: srv.URL, Headers: map[string]string{"X-Token": "secret"}}}
+	docs, err := tf.Transform(context.Background(), []vs.Document{
+		{Content: "a", Metadata: map[string]any{}},
+		{Content: "b", Metadata: map[string]any{}},
+	})
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "b", docs[0].Content)
+	assert.Equal(t, true, docs[0].Metadata["tagged"])
+}
+
+func TestExternalTransformerErrorResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(ExternalStepResponse{Error: "nope"})
+	}))
+	defer srv.Close()
+
+	tf := &ExternalTransformer{ExternalStep{URL: srv.URL}}
+	_, err := tf.Transform(context.Background(), []vs.Document{{Content: "a"}})
+	assert.ErrorContains(t, err, "nope")
+}
+
+func TestExternalTransformerRequiresTarget(t *testing.T) {
+	_, err := (&ExternalTransformer{}).Transform(context.Background(), nil)
+	assert.Error(t, err)
+}
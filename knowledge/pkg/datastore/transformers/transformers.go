@@ -11,9 +11,19 @@ var TransformerMap = map[string]dstypes.DocumentTransformer{
 	FilterMarkdownDocsNoContentName: &FilterMarkdownDocsNoContent{},
 	KeywordExtractorName:            &KeywordExtractor{},
 	MetadataManipulatorName:         &MetadataManipulator{},
+	ContextualEnrichmentName:        &ContextualEnrichment{},
+}
+
+// transformerFactories create the transformers whose configuration differs per step, e.g. the command of an external
+// transformer, so that two steps of the same kind don't share one instance
+var transformerFactories = map[string]func() dstypes.DocumentTransformer{
+	ExternalTransformerName: func() dstypes.DocumentTransformer { return &ExternalTransformer{} },
 }
 
 func GetTransformer(name string) (dstypes.DocumentTransformer, error) {
+	if factory, ok := transformerFactories[name]; ok {
+		return factory(), nil
+	}
 	transformer, ok := TransformerMap[name]
 	if !ok {
 		return nil, fmt.Errorf("unknown transformer %q", name)
@@ -34,6 +34,7 @@ type Response struct {
 
 type Stats struct {
 	RetrievalTimeSeconds float64 `json:"retrievalTimeSeconds,omitempty"`
+	CacheHit             bool    `json:"cacheHit,omitempty"`
 }
 
 type RetrievalResponse struct {
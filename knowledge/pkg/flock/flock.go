@@ -0,0 +1,82 @@
+// Package flock provides a cross-process exclusive lock based on a lock file, used to serialize writes of multiple
+// knowledge processes (e.g. parallel gptscript tool calls) to the same local database files.
+package flock
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const pollInterval = 50 * time.Millisecond
+
+type Lock struct {
+	path string
+
+	// held serializes goroutines of this process, since file locks are held per process (open file), not per
+	// goroutine. It's a channel rather than a mutex, so waiting for it can be cancelled.
+	held chan struct{}
+	file *os.File
+}
+
+func New(path string) *Lock {
+	return &Lock{path: path, held: make(chan struct{}, 1)}
+}
+
+// Lock blocks until the exclusive lock is acquired or the context is done.
+func (l *Lock) Lock(ctx context.Context) error {
+	select {
+	case l.held <- struct{}{}:
+	case <-ctx.Done():
+		return fmt.Errorf("timed out waiting for lock %q: %w", l.path, ctx.Err())
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0700); err != nil {
+		<-l.held
+		return fmt.Errorf("failed to create lock directory: %w", err)
+	}
+
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		<-l.held
+		return fmt.Errorf("failed to open lock file %q: %w", l.path, err)
+	}
+
+	for {
+		locked, err := tryLock(f)
+		if err != nil {
+			f.Close()
+			<-l.held
+			return fmt.Errorf("failed to lock %q: %w", l.path, err)
+		}
+		if locked {
+			l.file = f
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			f.Close()
+			<-l.held
+			return fmt.Errorf("timed out waiting for lock %q: %w", l.path, ctx.Err())
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// Unlock releases the lock acquired by Lock.
+func (l *Lock) Unlock() error {
+	defer func() { <-l.held }()
+
+	if l.file == nil {
+		return nil
+	}
+	err := unlock(l.file)
+	if cerr := l.file.Close(); err == nil {
+		err = cerr
+	}
+	l.file = nil
+	return err
+}
@@ -0,0 +1,42 @@
+package flock
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockExcludes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.lock")
+
+	l1 := New(path)
+	require.NoError(t, l1.Lock(context.Background()))
+
+	// A second lock on the same file (like another process would have) must not be acquired while l1 is held
+	l2 := New(path)
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	assert.Error(t, l2.Lock(ctx))
+
+	require.NoError(t, l1.Unlock())
+	require.NoError(t, l2.Lock(context.Background()))
+	require.NoError(t, l2.Unlock())
+}
+
+func TestLockWaitsForGoroutinesWithContext(t *testing.T) {
+	l := New(filepath.Join(t.TempDir(), "test.lock"))
+	require.NoError(t, l.Lock(context.Background()))
+
+	// Another goroutine of this process waits for the lock until its context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, l.Lock(ctx), context.DeadlineExceeded)
+
+	require.NoError(t, l.Unlock())
+	require.NoError(t, l.Lock(context.Background()))
+	require.NoError(t, l.Unlock())
+}
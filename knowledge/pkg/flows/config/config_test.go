@@ -3,6 +3,8 @@ package config
 import (
 	"testing"
 
+	"github.com/gptscript-ai/knowledge/pkg/datastore/postprocessors"
+	"github.com/gptscript-ai/knowledge/pkg/datastore/transformers"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -43,3 +45,39 @@ func TestLoadConfigInvalidDoubleDefault(t *testing.T) {
 	_, err := FromFile("testdata/invalid_doubledefault.yaml")
 	assert.Error(t, err)
 }
+
+func TestExternalStepsKeepTheirConfiguration(t *testing.T) {
+	redactor := map[string]any{"command": "redact-pii", "args": []any{"--strict"}, "timeout": 5}
+	enricher := map[string]any{"command": "enrich"}
+
+	ingestion := &IngestionFlowConfig{
+		Transformers: []TransformerConfig{
+			{GenericBaseConfig{Name: transformers.ExternalTransformerName, Options: redactor}},
+			{GenericBaseConfig{Name: transformers.ExternalTransformerName, Options: enricher}},
+		},
+	}
+	flow, err := ingestion.AsIngestionFlow(&FlowConfigGlobalsIngestion{})
+	require.NoError(t, err)
+	require.Len(t, flow.Transformations, 2)
+	first := flow.Transformations[0].(*transformers.ExternalTransformer)
+	second := flow.Transformations[1].(*transformers.ExternalTransformer)
+	assert.Equal(t, "redact-pii", first.Command)
+	assert.Equal(t, []string{"--strict"}, first.Args)
+	assert.Equal(t, 5, first.Timeout)
+	assert.Equal(t, "enrich", second.Command)
+	assert.Empty(t, second.Args, "options of the first step must not carry over")
+	assert.Zero(t, second.Timeout)
+
+	retrieval := &RetrievalFlowConfig{
+		Postprocessors: []TransformerConfig{
+			{GenericBaseConfig{Name: postprocessors.ExternalPostprocessorName, Options: redactor}},
+			{GenericBaseConfig{Name: postprocessors.ExternalPostprocessorName, Options: enricher}},
+		},
+	}
+	retrievalFlow, err := retrieval.AsRetrievalFlow()
+	require.NoError(t, err)
+	require.Len(t, retrievalFlow.Postprocessors, 2)
+	assert.Equal(t, "redact-pii", retrievalFlow.Postprocessors[0].(*postprocessors.ExternalPostprocessor).Command)
+	assert.Equal(t, "enrich", retrievalFlow.Postprocessors[1].(*postprocessors.ExternalPostprocessor).Command)
+	assert.Empty(t, retrievalFlow.Postprocessors[1].(*postprocessors.ExternalPostprocessor).Args)
+}
@@ -35,7 +35,7 @@ func New(ctx context.Context, dsn string, autoMigrate bool) (Index, error) {
 	switch dialect {
 	case "sqlite":
 		indexDB, err = sqlite.New(ctx, dsn, gormCfg, autoMigrate)
-	case "postgres":
+	case "postgres", "postgresql":
 		indexDB, err = postgres.New(ctx, dsn, gormCfg, autoMigrate)
 	default:
 		err = fmt.Errorf("unsupported dialect: %q", dialect)
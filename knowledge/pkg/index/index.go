@@ -26,12 +26,37 @@ type Index interface {
 	DeleteFile(ctx context.Context, datasetID, fileID string) error
 	FindFile(ctx context.Context, searchFile types.File) (*types.File, error)
 	FindFileByMetadata(ctx context.Context, dataset string, metadata types.FileMetadata, includeDocuments bool) (*types.File, error)
+	UpdateFile(ctx context.Context, file types.File) error
 
 	// Advanced File Operations
 	PruneFiles(ctx context.Context, datasetID string, pathPrefix string, keep []string) ([]types.File, error)
 
 	// Fundamental Document Operations
+	GetDocument(ctx context.Context, documentID, datasetID string) (*types.Document, error)
 	DeleteDocument(ctx context.Context, documentID, datasetID string) error
+	CountDocuments(ctx context.Context) (map[string]int64, error)
+
+	// Ingestion Run Operations
+	CreateIngestionRun(ctx context.Context, run types.IngestionRun) error
+	ListIngestionRuns(ctx context.Context, datasetID string, limit int) ([]types.IngestionRun, error)
+
+	// Feedback Operations
+	CreateFeedback(ctx context.Context, feedback types.Feedback) error
+	ListFeedback(ctx context.Context, datasetID, documentID string, limit int) ([]types.Feedback, error)
+	GetDocumentFeedback(ctx context.Context, datasetIDs []string, documentIDs []string) ([]types.DocumentFeedback, error)
+
+	// Retrieval Cache Operations
+	GetRetrievalCacheEntry(ctx context.Context, key string) (*types.RetrievalCacheEntry, error)
+	PutRetrievalCacheEntry(ctx context.Context, entry types.RetrievalCacheEntry) error
+	ClearRetrievalCache(ctx context.Context) error
+	InvalidateRetrievalCache(ctx context.Context, datasetIDs []string) error
+	GetRetrievalCacheStats(ctx context.Context) (*types.RetrievalCacheStats, error)
+
+	// Data Source Sync State Operations
+	GetSyncState(ctx context.Context, source string) (*types.SyncState, error)
+	PutSyncState(ctx context.Context, state types.SyncState) error
+	DeleteSyncState(ctx context.Context, source string) error
+	ListSyncStates(ctx context.Context) ([]types.SyncState, error)
 
 	Close() error
 }
@@ -3,8 +3,10 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/gptscript-ai/knowledge/pkg/index/sqlite"
 	"github.com/gptscript-ai/knowledge/pkg/index/types"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -14,7 +16,12 @@ type Index struct {
 	types.DB
 }
 
+// New opens a postgres index database - both postgres:// and postgresql:// DSNs are accepted
 func New(ctx context.Context, dsn string, gormCfg *gorm.Config, autoMigrate bool) (*Index, error) {
+	if strings.HasPrefix(dsn, "postgresql://") {
+		dsn = "postgres://" + strings.TrimPrefix(dsn, "postgresql://")
+	}
+
 	db, err := gorm.Open(postgres.Open(dsn), gormCfg)
 	if err != nil {
 		return nil, err
@@ -25,6 +32,10 @@ func New(ctx context.Context, dsn string, gormCfg *gorm.Config, autoMigrate bool
 		return nil, err
 	}
 
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return nil, fmt.Errorf("failed to connect to postgres index database: %w", err)
+	}
+
 	sqlDB.SetMaxIdleConns(10)
 	sqlDB.SetMaxOpenConns(50)
 	sqlDB.SetConnMaxIdleTime(30 * time.Minute)
@@ -47,12 +58,13 @@ func (i *Index) AutoMigrate() error {
 	return i.DB.DoAutoMigrate()
 }
 
+// ExportDatasetsToFile exports to a sqlite database file, so exports are portable between index backends
 func (i *Index) ExportDatasetsToFile(ctx context.Context, path string, ids ...string) error {
-	return fmt.Errorf("postgres: ExportDatasetsToFile not implemented")
+	return sqlite.ExportDatasets(ctx, i.DB.GormDB, path, ids...)
 }
 
 func (i *Index) ImportDatasetsFromFile(ctx context.Context, path string) error {
-	return fmt.Errorf("postgres: ImportDatasetsFromFile not implemented")
+	return sqlite.ImportDatasets(ctx, i.DB.GormDB, path)
 }
 
 func (i *Index) CreateDataset(ctx context.Context, dataset types.Dataset, opts *types.DatasetCreateOpts) error {
@@ -79,6 +91,10 @@ func (i *Index) FindFile(ctx context.Context, searchFile types.File) (*types.Fil
 	return i.DB.FindFile(ctx, searchFile)
 }
 
+func (i *Index) UpdateFile(ctx context.Context, file types.File) error {
+	return i.DB.UpdateFile(ctx, file)
+}
+
 func (i *Index) PruneFiles(ctx context.Context, datasetID string, pathPrefix string, keep []string) ([]types.File, error) {
 	return i.DB.PruneFiles(ctx, datasetID, pathPrefix, keep)
 }
@@ -87,6 +103,70 @@ func (i *Index) FindFileByMetadata(ctx context.Context, dataset string, metadata
 	return i.DB.FindFileByMetadata(ctx, dataset, metadata, includeDocuments)
 }
 
+func (i *Index) GetDocument(ctx context.Context, documentID, datasetID string) (*types.Document, error) {
+	return i.DB.GetDocument(ctx, documentID, datasetID)
+}
+
 func (i *Index) DeleteDocument(ctx context.Context, documentID, datasetID string) error {
 	return i.DB.DeleteDocument(ctx, documentID, datasetID)
 }
+
+func (i *Index) CountDocuments(ctx context.Context) (map[string]int64, error) {
+	return i.DB.CountDocuments(ctx)
+}
+
+func (i *Index) CreateIngestionRun(ctx context.Context, run types.IngestionRun) error {
+	return i.DB.CreateIngestionRun(ctx, run)
+}
+
+func (i *Index) ListIngestionRuns(ctx context.Context, datasetID string, limit int) ([]types.IngestionRun, error) {
+	return i.DB.ListIngestionRuns(ctx, datasetID, limit)
+}
+
+func (i *Index) CreateFeedback(ctx context.Context, feedback types.Feedback) error {
+	return i.DB.CreateFeedback(ctx, feedback)
+}
+
+func (i *Index) ListFeedback(ctx context.Context, datasetID, documentID string, limit int) ([]types.Feedback, error) {
+	return i.DB.ListFeedback(ctx, datasetID, documentID, limit)
+}
+
+func (i *Index) GetDocumentFeedback(ctx context.Context, datasetIDs []string, documentIDs []string) ([]types.DocumentFeedback, error) {
+	return i.DB.GetDocumentFeedback(ctx, datasetIDs, documentIDs)
+}
+
+func (i *Index) GetRetrievalCacheEntry(ctx context.Context, key string) (*types.RetrievalCacheEntry, error) {
+	return i.DB.GetRetrievalCacheEntry(ctx, key)
+}
+
+func (i *Index) PutRetrievalCacheEntry(ctx context.Context, entry types.RetrievalCacheEntry) error {
+	return i.DB.PutRetrievalCacheEntry(ctx, entry)
+}
+
+func (i *Index) ClearRetrievalCache(ctx context.Context) error {
+	return i.DB.ClearRetrievalCache(ctx)
+}
+
+func (i *Index) InvalidateRetrievalCache(ctx context.Context, datasetIDs []string) error {
+	return i.DB.InvalidateRetrievalCache(ctx, datasetIDs)
+}
+
+func (i *Index) GetRetrievalCacheStats(ctx context.Context) (*types.RetrievalCacheStats, error) {
+	return i.DB.GetRetrievalCacheStats(ctx)
+}
+
+func (i *Index) GetSyncState(ctx context.Context, source string) (*types.SyncState, error) {
+	return i.DB.GetSyncState(ctx, source)
+}
+
+func (i *Index) PutSyncState(ctx context.Context, state types.SyncState) error {
+	return i.DB.PutSyncState(ctx, state)
+}
+
+func (i *Index) DeleteSyncState(ctx context.Context, source string) error {
+	return i.DB.DeleteSyncState(ctx, source)
+}
+
+func (i *Index) ListSyncStates(ctx context.Context) ([]types.SyncState, error) {
+	return i.DB.ListSyncStates(ctx)
+}
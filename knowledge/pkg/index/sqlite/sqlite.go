@@ -26,9 +26,11 @@ func New(ctx context.Context, dsn string, gormCfg *gorm.Config, autoMigrate bool
 	// Enable PRAGMAs
 	// - busy_timeout (ms) to prevent db lockups as we're accessing the DB from multiple separate processes in acorn
 	// - foreign key constraint to make sure that deletes cascade
+	// - WAL journal mode, so readers in other processes don't block writers (and vice versa)
 	tx := db.Exec(`
 PRAGMA busy_timeout = 5000;
 PRAGMA foreign_keys = ON;
+PRAGMA journal_mode = WAL;
 `)
 	if tx.Error != nil {
 		return nil, tx.Error
@@ -65,7 +67,16 @@ func (i *Index) AutoMigrate() error {
 }
 
 func (i *Index) ExportDatasetsToFile(ctx context.Context, path string, ids ...string) error {
-	gdb := i.DB.GormDB.WithContext(ctx)
+	return ExportDatasets(ctx, i.DB.GormDB, path, ids...)
+}
+
+func (i *Index) ImportDatasetsFromFile(ctx context.Context, path string) error {
+	return ImportDatasets(ctx, i.DB.GormDB, path)
+}
+
+// ExportDatasets exports the given datasets from any index database to a new sqlite database file
+func ExportDatasets(ctx context.Context, db *gorm.DB, path string, ids ...string) error {
+	gdb := db.WithContext(ctx)
 
 	var datasets []types.Dataset
 	err := gdb.Preload("Files.Documents").Find(&datasets, "id IN ?", ids).Error
@@ -79,13 +90,13 @@ func (i *Index) ExportDatasetsToFile(ctx context.Context, path string, ids ...st
 	if err != nil && !os.IsNotExist(err) {
 		return err
 	}
-	if finfo.IsDir() {
+	if finfo != nil && finfo.IsDir() {
 		path = filepath.Join(path, "knowledge-export.db")
 	}
 
 	slog.Debug("Exporting datasets to file", "path", path)
 
-	ndb, err := New(ctx, "sqlite://"+path, nil, true)
+	ndb, err := New(ctx, "sqlite://"+path, exportConfig(db), true)
 	if err != nil {
 		return err
 	}
@@ -107,10 +118,16 @@ func (i *Index) ExportDatasetsToFile(ctx context.Context, path string, ids ...st
 	return nil
 }
 
-func (i *Index) ImportDatasetsFromFile(ctx context.Context, path string) error {
-	gdb := i.DB.GormDB.WithContext(ctx)
+// exportConfig is the configuration of the export database, which logs like the index database
+func exportConfig(db *gorm.DB) *gorm.Config {
+	return &gorm.Config{Logger: db.Logger, TranslateError: db.TranslateError}
+}
+
+// ImportDatasets imports all datasets from a sqlite database file (see ExportDatasets) into any index database
+func ImportDatasets(ctx context.Context, db *gorm.DB, path string) error {
+	gdb := db.WithContext(ctx)
 
-	ndb, err := New(ctx, "sqlite://"+strings.TrimPrefix(path, "sqlite://"), nil, false)
+	ndb, err := New(ctx, "sqlite://"+strings.TrimPrefix(path, "sqlite://"), exportConfig(db), false)
 	if err != nil {
 		return err
 	}
@@ -119,7 +136,7 @@ func (i *Index) ImportDatasetsFromFile(ctx context.Context, path string) error {
 	defer ndb.DB.Close()
 
 	var datasets []types.Dataset
-	err = ngdb.Find(&datasets).Error
+	err = ngdb.Preload("Files.Documents").Find(&datasets).Error
 	if err != nil {
 		return err
 	}
@@ -163,6 +180,10 @@ func (i *Index) FindFile(ctx context.Context, searchFile types.File) (*types.Fil
 	return i.DB.FindFile(ctx, searchFile)
 }
 
+func (i *Index) UpdateFile(ctx context.Context, file types.File) error {
+	return i.DB.UpdateFile(ctx, file)
+}
+
 func (i *Index) PruneFiles(ctx context.Context, datasetID string, pathPrefix string, keep []string) ([]types.File, error) {
 	return i.DB.PruneFiles(ctx, datasetID, pathPrefix, keep)
 }
@@ -171,6 +192,70 @@ func (i *Index) FindFileByMetadata(ctx context.Context, dataset string, metadata
 	return i.DB.FindFileByMetadata(ctx, dataset, metadata, includeDocuments)
 }
 
+func (i *Index) GetDocument(ctx context.Context, documentID, datasetID string) (*types.Document, error) {
+	return i.DB.GetDocument(ctx, documentID, datasetID)
+}
+
 func (i *Index) DeleteDocument(ctx context.Context, documentID, datasetID string) error {
 	return i.DB.DeleteDocument(ctx, documentID, datasetID)
 }
+
+func (i *Index) CountDocuments(ctx context.Context) (map[string]int64, error) {
+	return i.DB.CountDocuments(ctx)
+}
+
+func (i *Index) CreateIngestionRun(ctx context.Context, run types.IngestionRun) error {
+	return i.DB.CreateIngestionRun(ctx, run)
+}
+
+func (i *Index) ListIngestionRuns(ctx context.Context, datasetID string, limit int) ([]types.IngestionRun, error) {
+	return i.DB.ListIngestionRuns(ctx, datasetID, limit)
+}
+
+func (i *Index) CreateFeedback(ctx context.Context, feedback types.Feedback) error {
+	return i.DB.CreateFeedback(ctx, feedback)
+}
+
+func (i *Index) ListFeedback(ctx context.Context, datasetID, documentID string, limit int) ([]types.Feedback, error) {
+	return i.DB.ListFeedback(ctx, datasetID, documentID, limit)
+}
+
+func (i *Index) GetDocumentFeedback(ctx context.Context, datasetIDs []string, documentIDs []string) ([]types.DocumentFeedback, error) {
+	return i.DB.GetDocumentFeedback(ctx, datasetIDs, documentIDs)
+}
+
+func (i *Index) GetRetrievalCacheEntry(ctx context.Context, key string) (*types.RetrievalCacheEntry, error) {
+	return i.DB.GetRetrievalCacheEntry(ctx, key)
+}
+
+func (i *Index) PutRetrievalCacheEntry(ctx context.Context, entry types.RetrievalCacheEntry) error {
+	return i.DB.PutRetrievalCacheEntry(ctx, entry)
+}
+
+func (i *Index) ClearRetrievalCache(ctx context.Context) error {
+	return i.DB.ClearRetrievalCache(ctx)
+}
+
+func (i *Index) InvalidateRetrievalCache(ctx context.Context, datasetIDs []string) error {
+	return i.DB.InvalidateRetrievalCache(ctx, datasetIDs)
+}
+
+func (i *Index) GetRetrievalCacheStats(ctx context.Context) (*types.RetrievalCacheStats, error) {
+	return i.DB.GetRetrievalCacheStats(ctx)
+}
+
+func (i *Index) GetSyncState(ctx context.Context, source string) (*types.SyncState, error) {
+	return i.DB.GetSyncState(ctx, source)
+}
+
+func (i *Index) PutSyncState(ctx context.Context, state types.SyncState) error {
+	return i.DB.PutSyncState(ctx, state)
+}
+
+func (i *Index) DeleteSyncState(ctx context.Context, source string) error {
+	return i.DB.DeleteSyncState(ctx, source)
+}
+
+func (i *Index) ListSyncStates(ctx context.Context) ([]types.SyncState, error) {
+	return i.DB.ListSyncStates(ctx)
+}
@@ -0,0 +1,72 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gptscript-ai/knowledge/pkg/index/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func newTestIndex(t *testing.T, path string) *Index {
+	t.Helper()
+	idx, err := New(context.Background(), "sqlite://"+path, &gorm.Config{TranslateError: true}, true)
+	require.NoError(t, err)
+	require.NoError(t, idx.AutoMigrate())
+	t.Cleanup(func() { _ = idx.Close() })
+	return idx
+}
+
+func testDataset(id string) types.Dataset {
+	return types.Dataset{
+		ID:       id,
+		Metadata: map[string]any{"owner": "test"},
+		Files: []types.File{{
+			ID:      id + "-file",
+			Dataset: id,
+			FileMetadata: types.FileMetadata{
+				Name:         "file.md",
+				AbsolutePath: "/docs/file.md",
+				Size:         42,
+				ModifiedAt:   time.Date(2024, 10, 1, 9, 0, 0, 0, time.UTC),
+			},
+			Documents: []types.Document{
+				{ID: id + "-doc-0", Dataset: id, FileID: id + "-file", Index: 0},
+				{ID: id + "-doc-1", Dataset: id, FileID: id + "-file", Index: 1},
+			},
+		}},
+	}
+}
+
+func TestExportImportDatasets(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	source := newTestIndex(t, filepath.Join(dir, "source.db"))
+	for _, id := range []string{"exported", "other"} {
+		require.NoError(t, source.CreateDataset(ctx, testDataset(id), nil))
+	}
+
+	// The export is written to the directory if a directory is given
+	require.NoError(t, ExportDatasets(ctx, source.GormDB, dir, "exported"))
+	exportFile := filepath.Join(dir, "knowledge-export.db")
+
+	target := newTestIndex(t, filepath.Join(dir, "target.db"))
+	require.NoError(t, ImportDatasets(ctx, target.GormDB, exportFile))
+
+	datasets, err := target.ListDatasets(ctx)
+	require.NoError(t, err)
+	require.Len(t, datasets, 1)
+
+	imported, err := target.GetDataset(ctx, "exported")
+	require.NoError(t, err)
+	require.NotNil(t, imported)
+	assert.Equal(t, map[string]any{"owner": "test"}, imported.Metadata)
+	require.Len(t, imported.Files, 1)
+	assert.Equal(t, "/docs/file.md", imported.Files[0].AbsolutePath)
+	assert.Len(t, imported.Files[0].Documents, 2)
+}
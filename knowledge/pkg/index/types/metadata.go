@@ -32,9 +32,35 @@ func (d *Dataset) UpdateMetadata(metadata map[string]interface{}) {
 }
 
 func (d *Dataset) cleanMetadata() {
-	for k, v := range d.Metadata {
-		if v == nil || slices.Contains([]string{"", "-", "null", "nil"}, fmt.Sprintf("%v", v)) {
-			delete(d.Metadata, k)
+	cleanMetadata(d.Metadata)
+}
+
+// ReplaceMetadata replaces the custom metadata of the file with the given metadata.
+func (f *File) ReplaceMetadata(metadata map[string]interface{}) {
+	f.Metadata = metadata
+	cleanMetadata(f.Metadata)
+}
+
+// UpdateMetadata updates the custom metadata of the file with the given metadata, same as Dataset.UpdateMetadata.
+func (f *File) UpdateMetadata(metadata map[string]interface{}) {
+	if f.Metadata == nil {
+		f.Metadata = make(map[string]interface{})
+	}
+	for k, v := range metadata {
+		f.Metadata[k] = v
+	}
+	cleanMetadata(f.Metadata)
+}
+
+// IsEmptyMetadataValue returns true if the value marks a metadata field for deletion.
+func IsEmptyMetadataValue(v interface{}) bool {
+	return v == nil || slices.Contains([]string{"", "-", "null", "nil"}, fmt.Sprintf("%v", v))
+}
+
+func cleanMetadata(metadata map[string]interface{}) {
+	for k, v := range metadata {
+		if IsEmptyMetadataValue(v) {
+			delete(metadata, k)
 		}
 	}
 }
@@ -17,6 +17,8 @@ type Dataset struct {
 	EmbeddingsProviderConfig *config.ModelProviderConfig `json:"embeddingsProviderConfig,omitempty" gorm:"serializer:json"`
 	Files                    []File                      `gorm:"foreignKey:Dataset;references:ID;constraint:OnDelete:CASCADE;"`
 	Metadata                 map[string]any              `json:"metadata,omitempty" gorm:"serializer:json"`
+	IngestionRuns            []IngestionRun              `json:"-" gorm:"foreignKey:Dataset;references:ID;constraint:OnDelete:CASCADE;"`
+	Feedback                 []Feedback                  `json:"-" gorm:"foreignKey:Dataset;references:ID;constraint:OnDelete:CASCADE;"`
 }
 
 type File struct {
@@ -25,6 +27,8 @@ type File struct {
 	Documents []Document `gorm:"foreignKey:FileID,Dataset;references:ID,Dataset;constraint:OnDelete:CASCADE;"`
 	// File metadata, commonly used for deduplication
 	FileMetadata `json:",inline"`
+	// Metadata is custom, user-defined metadata which is propagated to all documents of the file
+	Metadata map[string]any `json:"metadata,omitempty" gorm:"serializer:json"`
 }
 
 type FileMetadata struct {
@@ -40,3 +44,81 @@ type Document struct {
 	FileID  string `gorm:"primaryKey" json:"file_id"` // Foreign key to File, part of composite primary key with Dataset
 	Index   int    `gorm:"index" json:"index"`        // Index of the document in the file (~ location within file, 0-based)
 }
+
+// IngestionRun is the persisted summary of a single ingestion run into a dataset.
+type IngestionRun struct {
+	ID               string    `gorm:"primaryKey" json:"id"`
+	Dataset          string    `gorm:"index" json:"dataset"` // Foreign key to Dataset
+	Sources          []string  `json:"sources" gorm:"serializer:json"`
+	StartedAt        time.Time `gorm:"index" json:"started_at"`
+	FinishedAt       time.Time `json:"finished_at"`
+	DurationSeconds  float64   `json:"duration_seconds"`
+	FilesIngested    int64     `json:"files_ingested"`
+	FilesSkipped     int64     `json:"files_skipped"` // e.g. duplicates or unchanged files
+	FilesUnsupported int64     `json:"files_unsupported"`
+	FilesFailed      int64     `json:"files_failed"`
+	FilesPruned      int64     `json:"files_pruned"`
+	ChunksAdded      int64     `json:"chunks_added"`   // chunks of newly ingested files
+	ChunksUpdated    int64     `json:"chunks_updated"` // chunks of re-ingested files, replacing their previous chunks
+	ChunksDeleted    int64     `json:"chunks_deleted"` // replaced by re-ingestion or pruned
+	// EstimatedTokens is a rough estimate of the embedded tokens (~4 characters per token)
+	EstimatedTokens  int64   `json:"estimated_tokens"`
+	EstimatedCostUSD float64 `json:"estimated_cost_usd,omitempty"`
+	Error            string  `json:"error,omitempty"`
+}
+
+// Feedback is a relevance judgement of a single retrieved document (chunk) for a query, given by an agent or user.
+type Feedback struct {
+	ID         string    `gorm:"primaryKey" json:"id"`
+	Dataset    string    `gorm:"index" json:"dataset"` // Foreign key to Dataset
+	DocumentID string    `gorm:"index" json:"document_id"`
+	FileID     string    `json:"file_id"`
+	Query      string    `json:"query,omitempty"`
+	Relevant   bool      `json:"relevant"` // thumbs up (true) or down (false)
+	Comment    string    `json:"comment,omitempty"`
+	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+}
+
+// DocumentFeedback is the aggregated feedback of a single document.
+type DocumentFeedback struct {
+	Dataset    string `json:"dataset"`
+	DocumentID string `json:"document_id"`
+	Positive   int64  `json:"positive"`
+	Negative   int64  `json:"negative"`
+}
+
+// RetrievalCacheEntry is a cached retrieval response, keyed by a hash of the query, datasets and retrieval options.
+type RetrievalCacheEntry struct {
+	Key       string    `gorm:"primaryKey" json:"key"`
+	Response  []byte    `json:"-"` // JSON encoded retrieval response
+	Hits      int64     `json:"hits"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `gorm:"index" json:"expires_at"`
+	// Datasets are the datasets the response was retrieved from, so it can be dropped when one of them changes
+	Datasets []RetrievalCacheDataset `gorm:"foreignKey:Key;references:Key;constraint:OnDelete:CASCADE;" json:"-"`
+}
+
+type RetrievalCacheDataset struct {
+	Key       string `gorm:"primaryKey"` // Foreign key to RetrievalCacheEntry
+	DatasetID string `gorm:"primaryKey;index"`
+}
+
+// RetrievalCacheStats holds the retrieval cache metrics - there is only a single row.
+type RetrievalCacheStats struct {
+	ID      int   `gorm:"primaryKey" json:"-"`
+	Hits    int64 `json:"hits"`
+	Misses  int64 `json:"misses"`
+	Entries int64 `gorm:"-" json:"entries"`
+}
+
+// SyncState is the persisted state of a data source sync, e.g. the cursor of the last incremental sync and the
+// synced files. The state itself is opaque JSON owned by the data source framework (see pkg/datasource).
+type SyncState struct {
+	Source       string    `gorm:"primaryKey" json:"source"` // unique name of the configured data source
+	State        []byte    `json:"-"`                        // JSON encoded state
+	LastSync     time.Time `json:"last_sync"`
+	LastFullSync time.Time `json:"last_full_sync"`
+	Failures     int       `json:"failures"` // consecutive failed syncs, reset by a successful sync
+	LastError    string    `json:"last_error,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
@@ -6,8 +6,10 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type DB struct {
@@ -25,6 +27,12 @@ func (db *DB) DoAutoMigrate() error {
 		&Dataset{},
 		&File{},
 		&Document{},
+		&IngestionRun{},
+		&Feedback{},
+		&RetrievalCacheEntry{},
+		&RetrievalCacheDataset{},
+		&RetrievalCacheStats{},
+		&SyncState{},
 	)
 }
 
@@ -132,6 +140,7 @@ func (db *DB) DeleteFile(ctx context.Context, datasetID, fileID string) error {
 func (db *DB) PruneFiles(ctx context.Context, datasetID string, pathPrefix string, keep []string) ([]File, error) {
 	var files []File
 	tx := db.WithContext(ctx).
+		Preload("Documents").
 		Where("dataset = ?", datasetID).
 		Where("absolute_path LIKE ?", pathPrefix+"%").
 		Not("absolute_path IN ?", keep).
@@ -201,6 +210,32 @@ func (db *DB) FindFileByMetadata(ctx context.Context, dataset string, metadata F
 	return &file, nil
 }
 
+// CountDocuments returns the number of documents per dataset.
+func (db *DB) CountDocuments(ctx context.Context) (map[string]int64, error) {
+	var rows []struct {
+		Dataset string
+		Count   int64
+	}
+	if err := db.WithContext(ctx).Model(&Document{}).Select("dataset, count(*) as count").Group("dataset").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Dataset] = row.Count
+	}
+	return counts, nil
+}
+
+func (db *DB) GetDocument(ctx context.Context, documentID, datasetID string) (*Document, error) {
+	var document Document
+	tx := db.WithContext(ctx).First(&document, "id = ? AND dataset = ?", documentID, datasetID)
+	if tx.Error != nil {
+		return nil, ErrDBDocumentNotFound
+	}
+	return &document, nil
+}
+
 func (db *DB) DeleteDocument(ctx context.Context, documentID, datasetID string) error {
 	// Find in Database
 	var document Document
@@ -217,14 +252,14 @@ func (db *DB) DeleteDocument(ctx context.Context, documentID, datasetID string)
 
 	// Check if owning file should be removed
 	var count int64
-	tx = db.WithContext(ctx).Model(&Document{}).Where("file_id = ?", document.FileID).Count(&count)
+	tx = db.WithContext(ctx).Model(&Document{}).Where("file_id = ? AND dataset = ?", document.FileID, document.Dataset).Count(&count)
 	if tx.Error != nil {
 		return tx.Error
 	}
 
 	if count == 0 {
 		slog.Info("Removing file, because all associated documents are gone", "file", document.FileID)
-		tx = db.WithContext(ctx).Delete(&File{}, "id = ?", document.FileID)
+		tx = db.WithContext(ctx).Delete(&File{}, "id = ? AND dataset = ?", document.FileID, document.Dataset)
 		if tx.Error != nil {
 			return fmt.Errorf("failed to delete owning file from DB: %w", tx.Error)
 		}
@@ -245,3 +280,200 @@ func (db *DB) CreateFile(ctx context.Context, file File) error {
 	gdb.Commit()
 	return nil
 }
+
+func (db *DB) UpdateFile(ctx context.Context, file File) error {
+	gdb := db.GormDB.WithContext(ctx)
+
+	slog.Debug("Updating file in DB", "id", file.ID, "dataset", file.Dataset, "metadata", file.Metadata)
+	err := gdb.Model(&file).Omit(clause.Associations).Select("Metadata").Updates(&file).Error
+	if err != nil {
+		return err
+	}
+
+	gdb.Commit()
+	return nil
+}
+
+func (db *DB) CreateIngestionRun(ctx context.Context, run IngestionRun) error {
+	gdb := db.GormDB.WithContext(ctx)
+
+	slog.Debug("Creating ingestion run in DB", "id", run.ID, "dataset", run.Dataset)
+	err := gdb.Create(&run).Error
+	if err != nil {
+		return err
+	}
+
+	gdb.Commit()
+	return nil
+}
+
+// ListIngestionRuns returns the ingestion runs of a dataset, newest first. A limit <= 0 returns all runs.
+func (db *DB) ListIngestionRuns(ctx context.Context, datasetID string, limit int) ([]IngestionRun, error) {
+	var runs []IngestionRun
+	tx := db.WithContext(ctx).Where("dataset = ?", datasetID).Order("started_at DESC")
+	if limit > 0 {
+		tx = tx.Limit(limit)
+	}
+	if err := tx.Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+func (db *DB) CreateFeedback(ctx context.Context, feedback Feedback) error {
+	gdb := db.GormDB.WithContext(ctx)
+
+	slog.Debug("Creating feedback in DB", "id", feedback.ID, "dataset", feedback.Dataset, "document", feedback.DocumentID)
+	err := gdb.Create(&feedback).Error
+	if err != nil {
+		return err
+	}
+
+	gdb.Commit()
+	return nil
+}
+
+// ListFeedback returns the feedback recorded for a dataset, optionally only for a single document, newest first.
+// A limit <= 0 returns all entries.
+func (db *DB) ListFeedback(ctx context.Context, datasetID, documentID string, limit int) ([]Feedback, error) {
+	var feedback []Feedback
+	tx := db.WithContext(ctx).Where("dataset = ?", datasetID)
+	if documentID != "" {
+		tx = tx.Where("document_id = ?", documentID)
+	}
+	tx = tx.Order("created_at DESC")
+	if limit > 0 {
+		tx = tx.Limit(limit)
+	}
+	if err := tx.Find(&feedback).Error; err != nil {
+		return nil, err
+	}
+	return feedback, nil
+}
+
+// GetDocumentFeedback returns the aggregated feedback of the given documents in the given datasets.
+// Documents without feedback are not included.
+func (db *DB) GetDocumentFeedback(ctx context.Context, datasetIDs []string, documentIDs []string) ([]DocumentFeedback, error) {
+	var rows []DocumentFeedback
+	if len(datasetIDs) == 0 || len(documentIDs) == 0 {
+		return rows, nil
+	}
+
+	err := db.WithContext(ctx).Model(&Feedback{}).
+		Select("dataset, document_id, "+
+			"SUM(CASE WHEN relevant THEN 1 ELSE 0 END) AS positive, "+
+			"SUM(CASE WHEN relevant THEN 0 ELSE 1 END) AS negative").
+		Where("dataset IN ? AND document_id IN ?", datasetIDs, documentIDs).
+		Group("dataset, document_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+const retrievalCacheStatsID = 1
+
+// GetRetrievalCacheEntry returns the unexpired cache entry for the given key or nil, and counts the hit or miss.
+func (db *DB) GetRetrievalCacheEntry(ctx context.Context, key string) (*RetrievalCacheEntry, error) {
+	gdb := db.GormDB.WithContext(ctx)
+
+	var entries []RetrievalCacheEntry
+	if err := gdb.Where("key = ? AND expires_at > ?", key, time.Now()).Limit(1).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	counter := "misses"
+	if len(entries) > 0 {
+		counter = "hits"
+		if err := gdb.Model(&RetrievalCacheEntry{Key: key}).UpdateColumn("hits", gorm.Expr("hits + ?", 1)).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if err := gdb.FirstOrCreate(&RetrievalCacheStats{}, RetrievalCacheStats{ID: retrievalCacheStatsID}).Error; err != nil {
+		return nil, err
+	}
+	if err := gdb.Model(&RetrievalCacheStats{ID: retrievalCacheStatsID}).UpdateColumn(counter, gorm.Expr(counter+" + ?", 1)).Error; err != nil {
+		return nil, err
+	}
+
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	return &entries[0], nil
+}
+
+// PutRetrievalCacheEntry stores (or replaces) a cache entry and drops expired entries.
+func (db *DB) PutRetrievalCacheEntry(ctx context.Context, entry RetrievalCacheEntry) error {
+	gdb := db.GormDB.WithContext(ctx)
+
+	if err := gdb.Where("expires_at <= ?", time.Now()).Delete(&RetrievalCacheEntry{}).Error; err != nil {
+		return err
+	}
+
+	return gdb.Clauses(clause.OnConflict{UpdateAll: true}).Create(&entry).Error
+}
+
+// ClearRetrievalCache removes all cache entries. The metrics are kept.
+func (db *DB) ClearRetrievalCache(ctx context.Context) error {
+	return db.GormDB.WithContext(ctx).Where("1 = 1").Delete(&RetrievalCacheEntry{}).Error
+}
+
+// InvalidateRetrievalCache removes the cache entries that were retrieved from any of the datasets, e.g. because their
+// contents changed.
+func (db *DB) InvalidateRetrievalCache(ctx context.Context, datasetIDs []string) error {
+	if len(datasetIDs) == 0 {
+		return nil
+	}
+	keys := db.GormDB.Model(&RetrievalCacheDataset{}).Select("key").Where("dataset_id IN ?", datasetIDs)
+	return db.GormDB.WithContext(ctx).Where("key IN (?)", keys).Delete(&RetrievalCacheEntry{}).Error
+}
+
+func (db *DB) GetRetrievalCacheStats(ctx context.Context) (*RetrievalCacheStats, error) {
+	gdb := db.GormDB.WithContext(ctx)
+
+	var stats []RetrievalCacheStats
+	if err := gdb.Where("id = ?", retrievalCacheStatsID).Find(&stats).Error; err != nil {
+		return nil, err
+	}
+	if len(stats) == 0 {
+		stats = append(stats, RetrievalCacheStats{ID: retrievalCacheStatsID})
+	}
+
+	if err := gdb.Model(&RetrievalCacheEntry{}).Where("expires_at > ?", time.Now()).Count(&stats[0].Entries).Error; err != nil {
+		return nil, err
+	}
+	return &stats[0], nil
+}
+
+// GetSyncState returns the sync state of the data source or nil if it never synced.
+func (db *DB) GetSyncState(ctx context.Context, source string) (*SyncState, error) {
+	var states []SyncState
+	if err := db.WithContext(ctx).Where("source = ?", source).Limit(1).Find(&states).Error; err != nil {
+		return nil, err
+	}
+	if len(states) == 0 {
+		return nil, nil
+	}
+	return &states[0], nil
+}
+
+// PutSyncState stores (or replaces) the sync state of a data source.
+func (db *DB) PutSyncState(ctx context.Context, state SyncState) error {
+	state.UpdatedAt = time.Now()
+	return db.GormDB.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&state).Error
+}
+
+// DeleteSyncState removes the sync state of a data source, so the next sync starts from scratch.
+func (db *DB) DeleteSyncState(ctx context.Context, source string) error {
+	return db.GormDB.WithContext(ctx).Where("source = ?", source).Delete(&SyncState{}).Error
+}
+
+func (db *DB) ListSyncStates(ctx context.Context) ([]SyncState, error) {
+	var states []SyncState
+	if err := db.WithContext(ctx).Order("source").Find(&states).Error; err != nil {
+		return nil, err
+	}
+	return states, nil
+}
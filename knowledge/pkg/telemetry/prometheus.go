@@ -0,0 +1,63 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	otelprom "go.opentelemetry.io/otel/exporters/prometheus"
+)
+
+// newPrometheusReader returns a metric reader that exposes the metrics in the returned registry. Names follow the
+// OpenTelemetry conventions, e.g. knowledge.retrieval.duration with unit s becomes knowledge_retrieval_duration_seconds
+// and monotonic sums get the _total suffix.
+func newPrometheusReader() (*otelprom.Exporter, *prometheus.Registry, error) {
+	registry := prometheus.NewRegistry()
+	exporter, err := otelprom.New(otelprom.WithRegisterer(registry), otelprom.WithoutScopeInfo())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create Prometheus exporter: %w", err)
+	}
+	return exporter, registry, nil
+}
+
+// metricsHandler serves the metrics of the registry in the Prometheus exposition formats.
+func metricsHandler(registry *prometheus.Registry) http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{
+		// Failing callbacks (e.g. the vectorstore size) only drop their own metrics, so serve the rest
+		ErrorHandling: promhttp.ContinueOnError,
+		ErrorLog:      metricsErrorLog{},
+	})
+}
+
+type metricsErrorLog struct{}
+
+func (metricsErrorLog) Println(v ...any) {
+	slog.Warn("Failed to collect some metrics", "error", fmt.Sprint(v...))
+}
+
+// serveMetrics serves the metrics of the registry on /metrics until the returned function is called.
+func serveMetrics(address string, registry *prometheus.Registry) (func(context.Context) error, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for metrics requests on %s: %w", address, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metricsHandler(registry))
+
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Metrics server failed", "error", err)
+		}
+	}()
+	slog.Info("Serving Prometheus metrics", "address", listener.Addr().String())
+
+	return server.Shutdown, nil
+}
@@ -0,0 +1,46 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	ctx := context.Background()
+	reader, registry, err := newPrometheusReader()
+	require.NoError(t, err)
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer mp.Shutdown(ctx)
+
+	meter := mp.Meter(instrumentationName)
+	dataset := metric.WithAttributes(attribute.String("knowledge.dataset", "default"))
+	files, err := meter.Int64Counter("knowledge.files.ingested", metric.WithDescription("Number of ingested files"), metric.WithUnit("{file}"))
+	require.NoError(t, err)
+	files.Add(ctx, 3, dataset)
+	duration, err := meter.Float64Histogram("knowledge.retrieval.duration", metric.WithUnit("s"), metric.WithExplicitBucketBoundaries(0.1, 1))
+	require.NoError(t, err)
+	for _, d := range []float64{0.05, 0.5, 0.5, 2} {
+		duration.Record(ctx, d)
+	}
+
+	rec := httptest.NewRecorder()
+	metricsHandler(registry).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	body := rec.Body.String()
+	assert.Contains(t, body, "# HELP knowledge_files_ingested_total Number of ingested files\n")
+	assert.Contains(t, body, "# TYPE knowledge_files_ingested_total counter\n")
+	assert.Contains(t, body, `knowledge_files_ingested_total{knowledge_dataset="default"} 3`)
+	assert.Contains(t, body, "# TYPE knowledge_retrieval_duration_seconds histogram\n")
+	assert.Contains(t, body, `knowledge_retrieval_duration_seconds_bucket{le="1"} 3`)
+	assert.Contains(t, body, `knowledge_retrieval_duration_seconds_bucket{le="+Inf"} 4`)
+	assert.Contains(t, body, "knowledge_retrieval_duration_seconds_count 4\n")
+}
@@ -0,0 +1,238 @@
+// Package telemetry sets up OpenTelemetry tracing and metrics for knowledge operations.
+// Export is only enabled if an OTLP endpoint is configured via the standard OTEL_EXPORTER_OTLP_* environment variables,
+// or metrics are served for Prometheus, otherwise the global no-op providers are used and instrumentation is
+// effectively free.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+
+	"github.com/gptscript-ai/knowledge/version"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/gptscript-ai/knowledge"
+
+var (
+	shutdownFuncs []func(context.Context) error
+	shutdownLock  sync.Mutex
+)
+
+// Enabled reports whether an OTLP endpoint is configured and the SDK is not disabled.
+func Enabled() bool {
+	if os.Getenv("OTEL_SDK_DISABLED") == "true" {
+		return false
+	}
+	for _, env := range []string{"OTEL_EXPORTER_OTLP_ENDPOINT", "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "OTEL_EXPORTER_OTLP_METRICS_ENDPOINT"} {
+		if os.Getenv(env) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Setup installs the global tracer and meter providers exporting via OTLP/HTTP and, if metricsAddress is set, starts
+// serving the metrics for Prometheus on http://<metricsAddress>/metrics.
+// It's a no-op if neither is enabled.
+func Setup(ctx context.Context, metricsAddress string) error {
+	otlpEnabled := Enabled()
+	if !otlpEnabled && metricsAddress == "" {
+		return nil
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(
+			attribute.String("service.name", "knowledge"),
+			attribute.String("service.version", version.Version),
+		),
+		resource.WithFromEnv(), // OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES take precedence
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create telemetry resource: %w", err)
+	}
+
+	var (
+		funcs       []func(context.Context) error
+		metricsOpts = []sdkmetric.Option{sdkmetric.WithResource(res)}
+	)
+	if otlpEnabled {
+		traceExporter, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+		}
+		tp := sdktrace.NewTracerProvider(
+			sdktrace.WithBatcher(traceExporter),
+			sdktrace.WithResource(res),
+		)
+		otel.SetTracerProvider(tp)
+		funcs = append(funcs, tp.Shutdown)
+
+		metricExporter, err := otlpmetrichttp.New(ctx)
+		if err != nil {
+			return errors.Join(fmt.Errorf("failed to create OTLP metric exporter: %w", err), tp.Shutdown(ctx))
+		}
+		metricsOpts = append(metricsOpts, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter)))
+	}
+
+	var registry *prometheus.Registry
+	if metricsAddress != "" {
+		var reader sdkmetric.Reader
+		reader, registry, err = newPrometheusReader()
+		if err != nil {
+			for _, fn := range funcs {
+				err = errors.Join(err, fn(ctx))
+			}
+			return err
+		}
+		metricsOpts = append(metricsOpts, sdkmetric.WithReader(reader))
+	}
+
+	mp := sdkmetric.NewMeterProvider(metricsOpts...)
+	otel.SetMeterProvider(mp)
+	funcs = append(funcs, mp.Shutdown)
+
+	if registry != nil {
+		stopServer, err := serveMetrics(metricsAddress, registry)
+		if err != nil {
+			for _, fn := range funcs {
+				err = errors.Join(err, fn(ctx))
+			}
+			return err
+		}
+		// Stop serving before the meter provider is shut down
+		funcs = append([]func(context.Context) error{stopServer}, funcs...)
+	}
+
+	shutdownLock.Lock()
+	shutdownFuncs = append(shutdownFuncs, funcs...)
+	shutdownLock.Unlock()
+
+	slog.Debug("OpenTelemetry enabled", "otlp", otlpEnabled, "metricsAddress", metricsAddress)
+	return nil
+}
+
+// Shutdown flushes and stops the exporters. Must be called before the process exits, as spans and metrics are batched.
+func Shutdown(ctx context.Context) error {
+	shutdownLock.Lock()
+	defer shutdownLock.Unlock()
+
+	var errs []error
+	for _, fn := range shutdownFuncs {
+		errs = append(errs, fn(ctx))
+	}
+	shutdownFuncs = nil
+	return errors.Join(errs...)
+}
+
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// StartSpan starts a span with the knowledge tracer.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// EndSpan records the error (if any) on the span and ends it.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+type Instruments struct {
+	TokensEmbedded   metric.Int64Counter
+	ChunksStored     metric.Int64Counter
+	FilesIngested    metric.Int64Counter
+	BytesIngested    metric.Int64Counter
+	Errors           metric.Int64Counter
+	IngestionLatency metric.Float64Histogram
+	EmbeddingLatency metric.Float64Histogram
+	QueryLatency     metric.Float64Histogram
+	// VectorstoreDocuments is observed via the callback registered with ObserveVectorstoreSize
+	VectorstoreDocuments metric.Int64ObservableGauge
+}
+
+var (
+	instruments     *Instruments
+	instrumentsOnce sync.Once
+)
+
+// Metrics returns the knowledge metric instruments. They're created on first use, so Setup must be called before.
+func Metrics() *Instruments {
+	instrumentsOnce.Do(func() {
+		meter := otel.Meter(instrumentationName)
+		instruments = &Instruments{}
+
+		var errs []error
+		var err error
+		instruments.TokensEmbedded, err = meter.Int64Counter("knowledge.embedding.tokens", metric.WithDescription("Estimated number of tokens sent to the embedding model"), metric.WithUnit("{token}"))
+		errs = append(errs, err)
+		instruments.ChunksStored, err = meter.Int64Counter("knowledge.chunks.stored", metric.WithDescription("Number of document chunks stored in the vector store"), metric.WithUnit("{chunk}"))
+		errs = append(errs, err)
+		instruments.FilesIngested, err = meter.Int64Counter("knowledge.files.ingested", metric.WithDescription("Number of ingested files"), metric.WithUnit("{file}"))
+		errs = append(errs, err)
+		instruments.BytesIngested, err = meter.Int64Counter("knowledge.ingestion.bytes", metric.WithDescription("Size of the ingested files"), metric.WithUnit("By"))
+		errs = append(errs, err)
+		instruments.Errors, err = meter.Int64Counter("knowledge.errors", metric.WithDescription("Number of failed ingestions and retrievals"), metric.WithUnit("{error}"))
+		errs = append(errs, err)
+		instruments.IngestionLatency, err = meter.Float64Histogram("knowledge.ingestion.duration", metric.WithDescription("Duration of single file ingestions"), metric.WithUnit("s"),
+			metric.WithExplicitBucketBoundaries(0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300))
+		errs = append(errs, err)
+		instruments.EmbeddingLatency, err = meter.Float64Histogram("knowledge.embedding.duration", metric.WithDescription("Duration of embedding the chunks of a file"), metric.WithUnit("s"),
+			metric.WithExplicitBucketBoundaries(0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120))
+		errs = append(errs, err)
+		instruments.QueryLatency, err = meter.Float64Histogram("knowledge.retrieval.duration", metric.WithDescription("Duration of retrieval queries"), metric.WithUnit("s"),
+			metric.WithExplicitBucketBoundaries(0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10))
+		errs = append(errs, err)
+		instruments.VectorstoreDocuments, err = meter.Int64ObservableGauge("knowledge.vectorstore.documents", metric.WithDescription("Number of documents stored in the vector store"), metric.WithUnit("{document}"))
+		errs = append(errs, err)
+
+		if err := errors.Join(errs...); err != nil {
+			// The API returns usable no-op instruments alongside errors, so this is not fatal
+			slog.Warn("Failed to create some telemetry instruments", "error", err)
+		}
+	})
+	return instruments
+}
+
+// ObserveVectorstoreSize registers a callback reporting the number of documents per dataset whenever metrics are
+// collected. The returned function unregisters it.
+func ObserveVectorstoreSize(countDocuments func(ctx context.Context) (map[string]int64, error)) func() {
+	gauge := Metrics().VectorstoreDocuments
+	registration, err := otel.Meter(instrumentationName).RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		counts, err := countDocuments(ctx)
+		if err != nil {
+			return err
+		}
+		for dataset, count := range counts {
+			o.ObserveInt64(gauge, count, metric.WithAttributes(attribute.String("knowledge.dataset", dataset)))
+		}
+		return nil
+	}, gauge)
+	if err != nil {
+		slog.Warn("Failed to observe vectorstore size", "error", err)
+		return func() {}
+	}
+	return func() {
+		_ = registration.Unregister()
+	}
+}
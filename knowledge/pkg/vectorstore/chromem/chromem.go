@@ -2,13 +2,16 @@ package chromem
 
 import (
 	"context"
+	"encoding/gob"
 	"fmt"
 	"log/slog"
 	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/gptscript-ai/knowledge/pkg/datastore/types"
 	"github.com/gptscript-ai/knowledge/pkg/env"
@@ -17,6 +20,7 @@ import (
 	"github.com/gptscript-ai/knowledge/pkg/vectorstore/errors"
 	vs "github.com/gptscript-ai/knowledge/pkg/vectorstore/types"
 	"github.com/philippgille/chromem-go"
+	"golang.org/x/sync/errgroup"
 )
 
 // VsChromemEmbeddingParallelThread can be set as an environment variable to control the number of parallel API calls to create embedding for documents. Default is 100
@@ -25,6 +29,13 @@ const VsChromemEmbeddingParallelThread = "VS_CHROMEM_EMBEDDING_PARALLEL_THREAD"
 type ChromemStore struct {
 	db            *chromem.DB
 	embeddingFunc chromem.EmbeddingFunc
+
+	// quantization is applied to newly created collections, existing collections keep their mode
+	quantization  Quantization
+	rescoreFactor int
+	quantizedDir  string // empty for in-memory stores
+	quantized     map[string]*quantizedCollection
+	quantizedLock sync.Mutex
 }
 
 // New creates a new Chromem vector store.
@@ -35,43 +46,169 @@ type ChromemStore struct {
 func New(dsn string, embeddingFunc chromem.EmbeddingFunc) (*ChromemStore, error) {
 	dsn = strings.TrimPrefix(dsn, "chromem://")
 
+	quantization, err := ParseQuantization(os.Getenv(VsChromemQuantization))
+	if err != nil {
+		return nil, err
+	}
+
+	store := &ChromemStore{
+		embeddingFunc: embeddingFunc,
+		quantization:  quantization,
+		rescoreFactor: env.GetIntFromEnvOrDefault(VsChromemQuantizationRescoreFactor, 4),
+		quantized:     map[string]*quantizedCollection{},
+	}
+
 	if dsn == ":memory:" {
-		return &ChromemStore{
-			db:            chromem.NewDB(),
-			embeddingFunc: embeddingFunc,
-		}, nil
+		store.db = chromem.NewDB()
+		return store, nil
 	}
 
-	var vsdb *chromem.DB
-	var err error
 	if strings.HasPrefix(dsn, types.ArchivePrefix) {
 		// Import from archive -> in-memory DB, not persisted back to the archive
-		vsdb = chromem.NewDB()
-		if err = vsdb.ImportFromFile(strings.TrimPrefix(dsn, types.ArchivePrefix), ""); err != nil {
+		archive := strings.TrimPrefix(dsn, types.ArchivePrefix)
+		store.db = chromem.NewDB()
+		if err = store.db.ImportFromFile(archive, ""); err != nil {
 			return nil, fmt.Errorf("failed to import vector database: %w", err)
 		}
+		if err = store.importQuantized(archive); err != nil {
+			return nil, err
+		}
 	} else {
-		vsdb, err = chromem.NewPersistentDB(dsn, false, chromem.WithOnCorruptedCollectionBehavior(chromem.OnCorruptedDelete))
+		store.db, err = chromem.NewPersistentDB(dsn, false, chromem.WithOnCorruptedCollectionBehavior(chromem.OnCorruptedDelete))
 		if err != nil {
 			return nil, err
 		}
+		// Sibling directory, since chromem treats every subdirectory of the DB path as a collection
+		store.quantizedDir = strings.TrimSuffix(dsn, string(filepath.Separator)) + ".quantized"
 	}
 
-	return &ChromemStore{
-		db:            vsdb,
-		embeddingFunc: embeddingFunc,
-	}, nil
+	return store, nil
+}
+
+// getQuantized returns the quantized side index of the collection or nil, if the collection is not quantized.
+func (s *ChromemStore) getQuantized(collection string) (*quantizedCollection, error) {
+	s.quantizedLock.Lock()
+	defer s.quantizedLock.Unlock()
+
+	if qc, ok := s.quantized[collection]; ok {
+		return qc, nil
+	}
+	if s.quantizedDir == "" {
+		return nil, nil
+	}
+
+	qc, err := loadQuantizedCollection(s.quantizedPath(collection))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	s.quantized[collection] = qc
+	return qc, nil
+}
+
+func (s *ChromemStore) quantizedPath(collection string) string {
+	return filepath.Join(s.quantizedDir, collection+".gob")
+}
+
+// quantizedExportPath is where the quantized side indexes are written next to a chromem export file
+func quantizedExportPath(path string) string {
+	return path + ".quantized"
+}
+
+func (s *ChromemStore) exportQuantized(path string, collections ...string) error {
+	export := map[string]*quantizedCollection{}
+	for _, name := range collections {
+		qc, err := s.getQuantized(name)
+		if err != nil {
+			return err
+		}
+		if qc != nil {
+			export[name] = qc
+		}
+	}
+	if len(export) == 0 {
+		return nil
+	}
+
+	f, err := os.Create(quantizedExportPath(path))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return gob.NewEncoder(f).Encode(export)
+}
+
+func (s *ChromemStore) importQuantized(path string, collections ...string) error {
+	f, err := os.Open(quantizedExportPath(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	var imported map[string]*quantizedCollection
+	if err := gob.NewDecoder(f).Decode(&imported); err != nil {
+		return fmt.Errorf("failed to decode quantized collections: %w", err)
+	}
+
+	s.quantizedLock.Lock()
+	defer s.quantizedLock.Unlock()
+	for name, qc := range imported {
+		if len(collections) > 0 && !slices.Contains(collections, name) {
+			continue
+		}
+		if s.quantizedDir != "" {
+			qc.path = s.quantizedPath(name)
+			if err := qc.persist(); err != nil {
+				return err
+			}
+		}
+		s.quantized[name] = qc
+	}
+	return nil
 }
 
 func (s *ChromemStore) CreateCollection(_ context.Context, name string, opts *dbtypes.DatasetCreateOpts) error {
+	existing := s.db.GetCollection(name, s.embeddingFunc)
+
 	_, err := s.db.CreateCollection(name, nil, s.embeddingFunc)
 	if err != nil {
 		return err
 	}
 
+	if s.quantization == QuantizationNone || (existing != nil && existing.Count() > 0) {
+		return nil
+	}
+
+	qc, err := s.getQuantized(name)
+	if err != nil || qc != nil {
+		return err
+	}
+
+	qc = newQuantizedCollection(s.quantization, "")
+	if s.quantizedDir != "" {
+		qc.path = s.quantizedPath(name)
+	}
+	if err := qc.persist(); err != nil {
+		return fmt.Errorf("failed to create quantized collection %q: %w", name, err)
+	}
+
+	s.quantizedLock.Lock()
+	s.quantized[name] = qc
+	s.quantizedLock.Unlock()
+
+	slog.Debug("Created quantized collection", "collection", name, "quantization", s.quantization)
 	return nil
 }
 
+func (s *ChromemStore) EmbedDocuments(ctx context.Context, docs []vs.Document) error {
+	return vs.EmbedDocuments(ctx, docs, s.embeddingFunc, env.GetIntFromEnvOrDefault(VsChromemEmbeddingParallelThread, 100))
+}
+
 func (s *ChromemStore) AddDocuments(ctx context.Context, docs []vs.Document, collection string) ([]string, error) {
 	l := log.FromCtx(ctx).With("stage", "vectorstore").With("vectorstore", "chromem-go")
 
@@ -90,7 +227,7 @@ func (s *ChromemStore) AddDocuments(ctx context.Context, docs []vs.Document, col
 		chromemDocs[docIdx] = chromem.Document{
 			ID:        ids[docIdx],
 			Metadata:  anyMapToStringMap(mc),
-			Embedding: nil, // Embeddings will be computed downstream
+			Embedding: doc.Embedding, // Embeddings will be computed downstream if not set
 			Content:   doc.Content,
 		}
 	}
@@ -103,12 +240,50 @@ func (s *ChromemStore) AddDocuments(ctx context.Context, docs []vs.Document, col
 
 	concurrency := env.GetIntFromEnvOrDefault(VsChromemEmbeddingParallelThread, 100)
 
-	err := col.AddDocuments(ctx, chromemDocs, concurrency)
+	qc, err := s.getQuantized(collection)
+	if err != nil {
+		return nil, err
+	}
+
+	var embeddings [][]float32
+	if qc != nil {
+		// Compute the embeddings here, so chromem only stores the placeholder and we keep the quantized vectors
+		embeddings = make([][]float32, len(chromemDocs))
+		g, gctx := errgroup.WithContext(ctx)
+		g.SetLimit(concurrency)
+		for i, doc := range chromemDocs {
+			g.Go(func() error {
+				if doc.Embedding != nil {
+					embeddings[i] = doc.Embedding
+					return nil
+				}
+				emb, err := s.embeddingFunc(gctx, doc.Content)
+				if err != nil {
+					return fmt.Errorf("failed to compute embedding for document %q: %w", doc.ID, err)
+				}
+				embeddings[i] = emb
+				return nil
+			})
+			chromemDocs[i].Embedding = placeholderEmbedding
+		}
+		if err := g.Wait(); err != nil {
+			l.With("status", "failed").With("error", err.Error()).Error("Failed to generate embeddings")
+			return nil, err
+		}
+	}
+
+	err = col.AddDocuments(ctx, chromemDocs, concurrency)
 	if err != nil {
 		l.With("status", "failed").With("error", err.Error()).Error("Failed to add documents to collection (generate embeddings)")
 		return nil, err
 	}
 
+	if qc != nil {
+		if err := qc.add(ids, embeddings); err != nil {
+			return nil, fmt.Errorf("failed to store quantized embeddings: %w", err)
+		}
+	}
+
 	l.With("status", "completed").Info("Added documents to collection (generated embeddings)")
 
 	return ids, nil
@@ -174,13 +349,60 @@ func (s *ChromemStore) SimilaritySearch(ctx context.Context, query string, numDo
 
 	slog.Debug("filtering documents", "where", where, "whereDocument", whereDocument)
 
+	qc, err := s.getQuantized(collection)
+	if err != nil {
+		return nil, err
+	}
+	if qc != nil {
+		qv, err := ef(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute query embedding: %w", err)
+		}
+		return s.quantizedSimilaritySearch(ctx, qc, col, qv, numDocuments, where, whereDocument)
+	}
+
 	qr, err := col.Query(ctx, query, numDocuments, where, whereDocument)
 	if err != nil {
 		return nil, err
 	}
 
+	return resultsToDocuments(qr), nil
+}
+
+// SimilaritySearchByVector is like SimilaritySearch, but queries with an existing embedding, e.g. a stored one.
+func (s *ChromemStore) SimilaritySearchByVector(ctx context.Context, vector []float32, numDocuments int, collection string, where map[string]string, whereDocument []chromem.WhereDocument) ([]vs.Document, error) {
+	col := s.db.GetCollection(collection, s.embeddingFunc)
+	if col == nil {
+		return nil, fmt.Errorf("%w: %q", errors.ErrCollectionNotFound, collection)
+	}
+
+	if col.Count() == 0 {
+		return nil, fmt.Errorf("%w: %q", errors.ErrCollectionEmpty, collection)
+	}
+
+	if numDocuments > col.Count() {
+		numDocuments = col.Count()
+	}
+
+	qc, err := s.getQuantized(collection)
+	if err != nil {
+		return nil, err
+	}
+	if qc != nil {
+		return s.quantizedSimilaritySearch(ctx, qc, col, vector, numDocuments, where, whereDocument)
+	}
+
+	qr, err := col.QueryEmbedding(ctx, vector, numDocuments, where, whereDocument)
+	if err != nil {
+		return nil, err
+	}
+
+	return resultsToDocuments(qr), nil
+}
+
+func resultsToDocuments(qr []chromem.Result) []vs.Document {
 	if len(qr) == 0 {
-		return nil, nil
+		return nil
 	}
 
 	var sDocs []vs.Document
@@ -194,11 +416,50 @@ func (s *ChromemStore) SimilaritySearch(ctx context.Context, query string, numDo
 		})
 	}
 
-	return sDocs, nil
+	return sDocs
+}
+
+// GetEmbeddings returns the stored embeddings of the given documents, in order.
+// For quantized collections, these are the dequantized vectors.
+func (s *ChromemStore) GetEmbeddings(ctx context.Context, collection string, documentIDs ...string) ([][]float32, error) {
+	col := s.db.GetCollection(collection, s.embeddingFunc)
+	if col == nil {
+		return nil, fmt.Errorf("%w: %q", errors.ErrCollectionNotFound, collection)
+	}
+
+	qc, err := s.getQuantized(collection)
+	if err != nil {
+		return nil, err
+	}
+	if qc != nil {
+		return qc.get(documentIDs)
+	}
+
+	embeddings := make([][]float32, len(documentIDs))
+	for i, id := range documentIDs {
+		doc, err := col.GetByID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get document %q: %w", id, err)
+		}
+		embeddings[i] = doc.Embedding
+	}
+	return embeddings, nil
 }
 
 func (s *ChromemStore) RemoveCollection(_ context.Context, collection string) error {
-	return s.db.DeleteCollection(collection)
+	if err := s.db.DeleteCollection(collection); err != nil {
+		return err
+	}
+
+	s.quantizedLock.Lock()
+	defer s.quantizedLock.Unlock()
+	delete(s.quantized, collection)
+	if s.quantizedDir != "" {
+		if err := os.Remove(s.quantizedPath(collection)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove quantized collection %q: %w", collection, err)
+		}
+	}
+	return nil
 }
 
 func (s *ChromemStore) RemoveDocument(ctx context.Context, documentID string, collection string, where map[string]string, whereDocument []chromem.WhereDocument) error {
@@ -206,7 +467,34 @@ func (s *ChromemStore) RemoveDocument(ctx context.Context, documentID string, co
 	if col == nil {
 		return fmt.Errorf("%w: %q", errors.ErrCollectionNotFound, collection)
 	}
-	return col.Delete(ctx, where, whereDocument, documentID)
+
+	qc, err := s.getQuantized(collection)
+	if err != nil {
+		return err
+	}
+	if qc == nil {
+		return col.Delete(ctx, where, whereDocument, documentID)
+	}
+
+	// Resolve the filters first, so the same documents are removed from the quantized index
+	ids := []string{documentID}
+	if len(where) > 0 || len(whereDocument) > 0 {
+		docs, err := col.GetDocuments(ctx, where, whereDocument)
+		if err != nil {
+			return err
+		}
+		ids = make([]string, 0, len(docs))
+		for _, doc := range docs {
+			if documentID == "" || doc.ID == documentID {
+				ids = append(ids, doc.ID)
+			}
+		}
+	}
+
+	if err := col.Delete(ctx, where, whereDocument, documentID); err != nil {
+		return err
+	}
+	return qc.remove(ids)
 }
 
 func (s *ChromemStore) ImportCollectionsFromFile(ctx context.Context, path string, collections ...string) error {
@@ -218,7 +506,10 @@ func (s *ChromemStore) ImportCollectionsFromFile(ctx context.Context, path strin
 		return fmt.Errorf("path %q is a directory", path)
 	}
 	slog.Debug("Importing collections from file", "path", path)
-	return s.db.ImportFromFile(path, "", collections...)
+	if err := s.db.ImportFromFile(path, "", collections...); err != nil {
+		return err
+	}
+	return s.importQuantized(path, collections...)
 }
 
 func (s *ChromemStore) ExportCollectionsToFile(ctx context.Context, path string, collections ...string) error {
@@ -230,7 +521,15 @@ func (s *ChromemStore) ExportCollectionsToFile(ctx context.Context, path string,
 		path = filepath.Join(path, "chromem-export.gob")
 	}
 	slog.Debug("Exporting collections to file", "path", path)
-	return s.db.ExportToFile(path, false, "", collections...)
+	if err := s.db.ExportToFile(path, false, "", collections...); err != nil {
+		return err
+	}
+	if len(collections) == 0 {
+		for name := range s.db.ListCollections() {
+			collections = append(collections, name)
+		}
+	}
+	return s.exportQuantized(path, collections...)
 }
 
 func (s *ChromemStore) GetDocuments(ctx context.Context, collection string, where map[string]string, whereDocument []chromem.WhereDocument) ([]vs.Document, error) {
@@ -255,3 +554,130 @@ func (s *ChromemStore) GetDocuments(ctx context.Context, collection string, wher
 
 	return docs, nil
 }
+
+func (s *ChromemStore) UpdateDocumentMetadata(ctx context.Context, collection string, documentIDs []string, metadata map[string]any) error {
+	col := s.db.GetCollection(collection, s.embeddingFunc)
+	if col == nil {
+		return fmt.Errorf("%w: %q", errors.ErrCollectionNotFound, collection)
+	}
+
+	for _, id := range documentIDs {
+		doc, err := col.GetByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to get document %q: %w", id, err)
+		}
+
+		doc.Metadata = anyMapToStringMap(vs.MergeMetadata(convertStringMapToAnyMap(doc.Metadata), metadata))
+
+		// The document still carries its embedding, so re-adding it only overwrites the stored document
+		if err := col.AddDocument(ctx, doc); err != nil {
+			return fmt.Errorf("failed to update document %q: %w", id, err)
+		}
+	}
+
+	return nil
+}
+
+// CopyDocuments copies documents including their embeddings from one collection to another, keeping their IDs.
+// No embeddings are computed. If only one of the collections is quantized, the vectors are converted accordingly.
+func (s *ChromemStore) CopyDocuments(ctx context.Context, fromCollection, toCollection string, documentIDs ...string) ([]string, error) {
+	src := s.db.GetCollection(fromCollection, s.embeddingFunc)
+	if src == nil {
+		return nil, fmt.Errorf("%w: %q", errors.ErrCollectionNotFound, fromCollection)
+	}
+	dst := s.db.GetCollection(toCollection, s.embeddingFunc)
+	if dst == nil {
+		return nil, fmt.Errorf("%w: %q", errors.ErrCollectionNotFound, toCollection)
+	}
+
+	srcQC, err := s.getQuantized(fromCollection)
+	if err != nil {
+		return nil, err
+	}
+	dstQC, err := s.getQuantized(toCollection)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([]chromem.Document, len(documentIDs))
+	for i, id := range documentIDs {
+		docs[i], err = src.GetByID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get document %q: %w", id, err)
+		}
+	}
+
+	// For quantized collections, chromem only holds the placeholder, so the vectors come from the side index
+	var embeddings [][]float32
+	if srcQC != nil {
+		if embeddings, err = srcQC.get(documentIDs); err != nil {
+			return nil, err
+		}
+	} else if dstQC != nil {
+		embeddings = make([][]float32, len(docs))
+		for i, doc := range docs {
+			embeddings[i] = doc.Embedding
+		}
+	}
+
+	for i := range docs {
+		switch {
+		case dstQC != nil:
+			docs[i].Embedding = placeholderEmbedding
+		case srcQC != nil:
+			docs[i].Embedding = embeddings[i]
+		}
+	}
+
+	// All documents carry an embedding, so chromem won't call the embedding function
+	if err := dst.AddDocuments(ctx, docs, env.GetIntFromEnvOrDefault(VsChromemEmbeddingParallelThread, 100)); err != nil {
+		return nil, fmt.Errorf("failed to add documents to collection %q: %w", toCollection, err)
+	}
+
+	if dstQC != nil {
+		if err := dstQC.add(documentIDs, embeddings); err != nil {
+			return nil, fmt.Errorf("failed to store quantized embeddings: %w", err)
+		}
+	}
+
+	return documentIDs, nil
+}
+
+func (s *ChromemStore) quantizedSimilaritySearch(ctx context.Context, qc *quantizedCollection, col *chromem.Collection, qv []float32, numDocuments int, where map[string]string, whereDocument []chromem.WhereDocument) ([]vs.Document, error) {
+	var err error
+	var candidates map[string]struct{}
+	var filtered map[string]chromem.Document
+	if len(where) > 0 || len(whereDocument) > 0 {
+		docs, err := col.GetDocuments(ctx, where, whereDocument)
+		if err != nil {
+			return nil, err
+		}
+		candidates = make(map[string]struct{}, len(docs))
+		filtered = make(map[string]chromem.Document, len(docs))
+		for _, doc := range docs {
+			candidates[doc.ID] = struct{}{}
+			filtered[doc.ID] = *doc
+		}
+	}
+
+	results := qc.search(qv, numDocuments, s.rescoreFactor, candidates)
+
+	sDocs := make([]vs.Document, 0, len(results))
+	for _, r := range results {
+		doc, ok := filtered[r.ID]
+		if !ok {
+			doc, err = col.GetByID(ctx, r.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get document %q: %w", r.ID, err)
+			}
+		}
+		sDocs = append(sDocs, vs.Document{
+			ID:              doc.ID,
+			Metadata:        convertStringMapToAnyMap(doc.Metadata),
+			SimilarityScore: r.Score,
+			Content:         doc.Content,
+		})
+	}
+
+	return sDocs, nil
+}
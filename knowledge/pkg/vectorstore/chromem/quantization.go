@@ -0,0 +1,318 @@
+package chromem
+
+import (
+	"encoding/gob"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+const (
+	// VsChromemQuantization can be set as an environment variable to store embeddings of newly created collections in
+	// reduced precision: "float16" (half the memory) or "int8" (a quarter of the memory, approximate scores are rescored)
+	VsChromemQuantization = "VS_CHROMEM_QUANTIZATION"
+
+	// VsChromemQuantizationRescoreFactor controls how many candidates (topK * factor) are rescored with the
+	// full precision query embedding when using int8 quantization. Default is 4
+	VsChromemQuantizationRescoreFactor = "VS_CHROMEM_QUANTIZATION_RESCORE_FACTOR"
+)
+
+type Quantization string
+
+const (
+	QuantizationNone    Quantization = ""
+	QuantizationFloat16 Quantization = "float16"
+	QuantizationInt8    Quantization = "int8"
+)
+
+// placeholderEmbedding is stored in chromem for documents of quantized collections, so chromem doesn't keep
+// (or compute) the full precision vector. Similarity search for those collections bypasses chromem's query.
+var placeholderEmbedding = []float32{1}
+
+func ParseQuantization(s string) (Quantization, error) {
+	switch q := Quantization(s); q {
+	case QuantizationNone, QuantizationFloat16, QuantizationInt8:
+		return q, nil
+	default:
+		return "", fmt.Errorf("unsupported quantization %q (supported: %q, %q)", s, QuantizationFloat16, QuantizationInt8)
+	}
+}
+
+// quantizedVector holds a normalized embedding in reduced precision - only one of F16 or I8 is set.
+type quantizedVector struct {
+	F16   []uint16
+	I8    []int8
+	Scale float32 // int8 only: value = I8[i] * Scale
+}
+
+func quantize(q Quantization, v []float32) quantizedVector {
+	switch q {
+	case QuantizationFloat16:
+		f16 := make([]uint16, len(v))
+		for i, x := range v {
+			f16[i] = float32ToFloat16(x)
+		}
+		return quantizedVector{F16: f16}
+	default:
+		var maxAbs float32
+		for _, x := range v {
+			maxAbs = max(maxAbs, float32(math.Abs(float64(x))))
+		}
+		scale := maxAbs / 127
+		if scale == 0 {
+			scale = 1
+		}
+		i8 := make([]int8, len(v))
+		for i, x := range v {
+			i8[i] = int8(math.Round(float64(x / scale)))
+		}
+		return quantizedVector{I8: i8, Scale: scale}
+	}
+}
+
+func (qv quantizedVector) dequantize() []float32 {
+	if qv.F16 != nil {
+		v := make([]float32, len(qv.F16))
+		for i, x := range qv.F16 {
+			v[i] = float16ToFloat32(x)
+		}
+		return v
+	}
+	v := make([]float32, len(qv.I8))
+	for i, x := range qv.I8 {
+		v[i] = float32(x) * qv.Scale
+	}
+	return v
+}
+
+// approxDot is the cheap integer dot product used to preselect int8 candidates
+func (qv quantizedVector) approxDot(query quantizedVector) float32 {
+	var sum int32
+	for i := range min(len(qv.I8), len(query.I8)) {
+		sum += int32(qv.I8[i]) * int32(query.I8[i])
+	}
+	return float32(sum) * qv.Scale * query.Scale
+}
+
+func dot(a, b []float32) float32 {
+	var sum float32
+	for i := range min(len(a), len(b)) {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+func normalize(v []float32) []float32 {
+	var norm float32
+	for _, x := range v {
+		norm += x * x
+	}
+	norm = float32(math.Sqrt(float64(norm)))
+	if norm == 0 {
+		return v
+	}
+	res := make([]float32, len(v))
+	for i, x := range v {
+		res[i] = x / norm
+	}
+	return res
+}
+
+func float32ToFloat16(f float32) uint16 {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int32((bits>>23)&0xff) - 127 + 15
+	mant := bits & 0x7fffff
+
+	switch {
+	case exp >= 0x1f: // overflow -> inf
+		return sign | 0x7c00
+	case exp <= 0: // subnormal or zero
+		if exp < -10 {
+			return sign
+		}
+		mant |= 0x800000
+		return sign | uint16(mant>>uint32(14-exp))
+	default:
+		return sign | uint16(exp)<<10 | uint16(mant>>13)
+	}
+}
+
+func float16ToFloat32(h uint16) float32 {
+	sign := uint32(h&0x8000) << 16
+	exp := uint32(h>>10) & 0x1f
+	mant := uint32(h & 0x3ff)
+
+	switch exp {
+	case 0:
+		if mant == 0 {
+			return math.Float32frombits(sign)
+		}
+		// subnormal
+		e := uint32(127 - 15 + 1)
+		for mant&0x400 == 0 {
+			mant <<= 1
+			e--
+		}
+		mant &= 0x3ff
+		return math.Float32frombits(sign | e<<23 | mant<<13)
+	case 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | mant<<13)
+	default:
+		return math.Float32frombits(sign | (exp+127-15)<<23 | mant<<13)
+	}
+}
+
+// quantizedCollection is the side index holding the reduced precision vectors of one chromem collection.
+type quantizedCollection struct {
+	Quantization Quantization
+	Vectors      map[string]quantizedVector
+
+	path string // empty for in-memory stores
+	lock sync.RWMutex
+}
+
+type scoredID struct {
+	ID    string
+	Score float32
+}
+
+func newQuantizedCollection(q Quantization, path string) *quantizedCollection {
+	return &quantizedCollection{
+		Quantization: q,
+		Vectors:      map[string]quantizedVector{},
+		path:         path,
+	}
+}
+
+func loadQuantizedCollection(path string) (*quantizedCollection, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	qc := &quantizedCollection{path: path}
+	if err := gob.NewDecoder(f).Decode(qc); err != nil {
+		return nil, fmt.Errorf("failed to decode quantized collection %q: %w", path, err)
+	}
+	return qc, nil
+}
+
+func (qc *quantizedCollection) add(ids []string, embeddings [][]float32) error {
+	qc.lock.Lock()
+	defer qc.lock.Unlock()
+	for i, id := range ids {
+		qc.Vectors[id] = quantize(qc.Quantization, normalize(embeddings[i]))
+	}
+	return qc.persist()
+}
+
+func (qc *quantizedCollection) remove(ids []string) error {
+	qc.lock.Lock()
+	defer qc.lock.Unlock()
+	for _, id := range ids {
+		delete(qc.Vectors, id)
+	}
+	return qc.persist()
+}
+
+// get returns the dequantized vectors of the given documents
+func (qc *quantizedCollection) get(ids []string) ([][]float32, error) {
+	qc.lock.RLock()
+	defer qc.lock.RUnlock()
+	embeddings := make([][]float32, len(ids))
+	for i, id := range ids {
+		v, ok := qc.Vectors[id]
+		if !ok {
+			return nil, fmt.Errorf("document %q not found in quantized collection", id)
+		}
+		embeddings[i] = v.dequantize()
+	}
+	return embeddings, nil
+}
+
+// search returns the topK most similar IDs (optionally restricted to the candidates set).
+// For int8, topK*rescoreFactor candidates are preselected using integer math and then rescored against the
+// full precision query.
+func (qc *quantizedCollection) search(query []float32, topK int, rescoreFactor int, candidates map[string]struct{}) []scoredID {
+	qc.lock.RLock()
+	defer qc.lock.RUnlock()
+
+	query = normalize(query)
+
+	var scored []scoredID
+	if qc.Quantization == QuantizationInt8 {
+		qq := quantize(QuantizationInt8, query)
+		for id, v := range qc.Vectors {
+			if candidates != nil {
+				if _, ok := candidates[id]; !ok {
+					continue
+				}
+			}
+			scored = append(scored, scoredID{ID: id, Score: v.approxDot(qq)})
+		}
+		scored = topN(scored, topK*max(rescoreFactor, 1))
+		for i, s := range scored {
+			scored[i].Score = dot(query, qc.Vectors[s.ID].dequantize())
+		}
+	} else {
+		for id, v := range qc.Vectors {
+			if candidates != nil {
+				if _, ok := candidates[id]; !ok {
+					continue
+				}
+			}
+			scored = append(scored, scoredID{ID: id, Score: dot(query, v.dequantize())})
+		}
+	}
+
+	return topN(scored, topK)
+}
+
+func topN(scored []scoredID, n int) []scoredID {
+	slices.SortFunc(scored, func(a, b scoredID) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		default:
+			return 0
+		}
+	})
+	if len(scored) > n {
+		scored = scored[:n]
+	}
+	return scored
+}
+
+// persist writes the side index to disk - must be called with the lock held
+func (qc *quantizedCollection) persist() error {
+	if qc.path == "" {
+		return nil
+	}
+	return writeQuantizedCollection(qc.path, qc)
+}
+
+func writeQuantizedCollection(path string, qc *quantizedCollection) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(f).Encode(qc); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to encode quantized collection: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,61 @@
+package chromem
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFloat16RoundTrip(t *testing.T) {
+	for _, f := range []float32{0, 1, -1, 0.5, 0.333, -0.0001, 65504} {
+		assert.InDelta(t, f, float16ToFloat32(float32ToFloat16(f)), float64(abs(f))*1e-3+1e-4, "value %v", f)
+	}
+}
+
+func TestQuantizedSearch(t *testing.T) {
+	vectors := map[string][]float32{
+		"a": {1, 0, 0},
+		"b": {0.9, 0.1, 0},
+		"c": {0, 1, 0},
+		"d": {0, 0, 1},
+	}
+
+	for _, q := range []Quantization{QuantizationFloat16, QuantizationInt8} {
+		t.Run(string(q), func(t *testing.T) {
+			qc := newQuantizedCollection(q, "")
+			for id, v := range vectors {
+				require.NoError(t, qc.add([]string{id}, [][]float32{v}))
+			}
+
+			res := qc.search([]float32{1, 0.05, 0}, 2, 2, nil)
+			require.Len(t, res, 2)
+			assert.Equal(t, "a", res[0].ID)
+			assert.Equal(t, "b", res[1].ID)
+			assert.InDelta(t, 1, res[0].Score, 0.01)
+
+			res = qc.search([]float32{1, 0, 0}, 3, 2, map[string]struct{}{"c": {}, "d": {}})
+			assert.Len(t, res, 2)
+
+			require.NoError(t, qc.remove([]string{"a"}))
+			res = qc.search([]float32{1, 0, 0}, 1, 2, nil)
+			assert.Equal(t, "b", res[0].ID)
+		})
+	}
+}
+
+func TestParseQuantization(t *testing.T) {
+	q, err := ParseQuantization("int8")
+	assert.NoError(t, err)
+	assert.Equal(t, QuantizationInt8, q)
+
+	_, err = ParseQuantization("int4")
+	assert.Error(t, err)
+}
+
+func abs(f float32) float32 {
+	if f < 0 {
+		return -f
+	}
+	return f
+}
@@ -273,6 +273,10 @@ func (v VectorStore) CreateCollection(ctx context.Context, collection string, op
 	return tx.Commit(ctx)
 }
 
+func (v VectorStore) EmbedDocuments(ctx context.Context, docs []vs.Document) error {
+	return vs.EmbedDocuments(ctx, docs, v.embeddingFunc, v.embeddingConcurrency)
+}
+
 func (v VectorStore) AddDocuments(ctx context.Context, docs []vs.Document, collection string) ([]string, error) {
 	cid, err := v.getCollectionUUID(ctx, collection)
 	if err != nil {
@@ -325,10 +329,13 @@ func (v VectorStore) AddDocuments(ctx context.Context, docs []vs.Document, colle
 			semaphore <- struct{}{}
 			defer func() { <-semaphore }()
 
-			vec, err := v.embeddingFunc(ctx, doc.Content)
-			if err != nil {
-				setSharedErr(fmt.Errorf("failed to embed document %s: %w", doc.ID, err))
-				return
+			vec := doc.Embedding
+			if vec == nil {
+				var err error
+				if vec, err = v.embeddingFunc(ctx, doc.Content); err != nil {
+					setSharedErr(fmt.Errorf("failed to embed document %s: %w", doc.ID, err))
+					return
+				}
 			}
 
 			b.Queue(sql, doc.ID, []byte(doc.Content), pgvector.NewVector(vec), doc.Metadata, cid)
@@ -372,6 +379,16 @@ func (v VectorStore) SimilaritySearch(ctx context.Context, query string, numDocu
 	if err != nil {
 		return nil, err
 	}
+
+	return v.SimilaritySearchByVector(ctx, queryEmbedding, numDocuments, collection, where, whereDocument)
+}
+
+// SimilaritySearchByVector is like SimilaritySearch, but queries with an existing embedding, e.g. a stored one.
+func (v VectorStore) SimilaritySearchByVector(ctx context.Context, queryEmbedding []float32, numDocuments int, collection string, where map[string]string, whereDocument []cg.WhereDocument) ([]vs.Document, error) {
+	if len(whereDocument) > 0 {
+		return nil, fmt.Errorf("pgvector does not support whereDocument")
+	}
+
 	dims := len(queryEmbedding)
 
 	whereClause, args, err := buildWhereClause([]any{dims, pgvector.NewVector(queryEmbedding), numDocuments}, where)
@@ -515,6 +532,34 @@ func (v VectorStore) GetDocuments(ctx context.Context, collection string, where
 	return docs, rows.Err()
 }
 
+func (v VectorStore) UpdateDocumentMetadata(ctx context.Context, collection string, documentIDs []string, metadata map[string]any) error {
+	cid, err := v.getCollectionUUID(ctx, collection)
+	if err != nil {
+		return fmt.Errorf("collection %s not found: %w", collection, err)
+	}
+
+	tx, err := v.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) // rollback on error (noop after commit)
+
+	for _, id := range documentIDs {
+		var docMetadata map[string]any
+		err := tx.QueryRow(ctx, fmt.Sprintf(`SELECT cmetadata FROM %s WHERE uuid = $1 AND collection_id = $2 FOR UPDATE`, v.embeddingTableName), id, cid).Scan(&docMetadata)
+		if err != nil {
+			return fmt.Errorf("failed to get document %s: %w", id, err)
+		}
+
+		_, err = tx.Exec(ctx, fmt.Sprintf(`UPDATE %s SET cmetadata = $1 WHERE uuid = $2 AND collection_id = $3`, v.embeddingTableName), vs.MergeMetadata(docMetadata, metadata), id, cid)
+		if err != nil {
+			return fmt.Errorf("failed to update metadata for document %s: %w", id, err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
 func (v VectorStore) ImportCollectionsFromFile(ctx context.Context, path string, collections ...string) error {
 	return fmt.Errorf("function ImportCollectionsFromFile not implemented for vectorstore pgvector")
 }
@@ -545,3 +590,58 @@ func buildWhereClause(args []any, where map[string]string) (string, []any, error
 	}
 	return whereClause, args, nil
 }
+
+// GetEmbeddings returns the stored embeddings of the given documents, in order.
+func (v VectorStore) GetEmbeddings(ctx context.Context, collection string, documentIDs ...string) ([][]float32, error) {
+	cid, err := v.getCollectionUUID(ctx, collection)
+	if err != nil {
+		return nil, fmt.Errorf("collection %s not found: %w", collection, err)
+	}
+
+	embeddings := make([][]float32, len(documentIDs))
+	for i, id := range documentIDs {
+		var emb pgvector.Vector
+		err := v.conn.QueryRow(ctx, fmt.Sprintf(`SELECT embedding FROM %s WHERE uuid = $1 AND collection_id = $2`, v.embeddingTableName), id, cid).Scan(&emb)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get embedding of document %s: %w", id, err)
+		}
+		embeddings[i] = emb.Slice()
+	}
+	return embeddings, nil
+}
+
+// CopyDocuments copies documents including their stored embeddings into another collection, so nothing is re-embedded.
+// Document IDs are the primary key of the embedding table, so the copies get fresh IDs, returned in order.
+func (v VectorStore) CopyDocuments(ctx context.Context, fromCollection, toCollection string, documentIDs ...string) ([]string, error) {
+	fromCID, err := v.getCollectionUUID(ctx, fromCollection)
+	if err != nil {
+		return nil, fmt.Errorf("collection %s not found: %w", fromCollection, err)
+	}
+	toCID, err := v.getCollectionUUID(ctx, toCollection)
+	if err != nil {
+		return nil, fmt.Errorf("collection %s not found: %w", toCollection, err)
+	}
+
+	tx, err := v.conn.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx) // rollback on error (noop after commit)
+
+	sql := fmt.Sprintf(`INSERT INTO %s (uuid, document, embedding, cmetadata, collection_id)
+		SELECT $1, document, embedding, cmetadata, $2 FROM %s WHERE uuid = $3 AND collection_id = $4`, v.embeddingTableName, v.embeddingTableName)
+
+	ids := make([]string, len(documentIDs))
+	for i, id := range documentIDs {
+		ids[i] = uuid.New().String()
+		tag, err := tx.Exec(ctx, sql, ids[i], toCID, id, fromCID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy document %s: %w", id, err)
+		}
+		if tag.RowsAffected() == 0 {
+			return nil, fmt.Errorf("document %s not found in collection %s", id, fromCollection)
+		}
+	}
+
+	return ids, tx.Commit(ctx)
+}
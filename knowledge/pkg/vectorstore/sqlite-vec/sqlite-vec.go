@@ -3,12 +3,15 @@ package sqlite_vec
 import (
 	"context"
 	_ "embed"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"math"
 	"strings"
 
 	sqlitevec "github.com/asg017/sqlite-vec-go-bindings/ncruces"
+	"github.com/google/uuid"
 	dbtypes "github.com/gptscript-ai/knowledge/pkg/index/types"
 	vs "github.com/gptscript-ai/knowledge/pkg/vectorstore/types"
 	cg "github.com/philippgille/chromem-go"
@@ -34,8 +37,10 @@ func New(ctx context.Context, dsn string, embeddingFunc cg.EmbeddingFunc) (*Vect
 
 	// Enable PRAGMAs
 	// - busy_timeout (ms) to prevent db lockups as we're accessing the DB from multiple separate processes in acorn
+	// - WAL journal mode, so readers in other processes don't block writers (and vice versa)
 	tx := db.Exec(`
 PRAGMA busy_timeout = 5000;
+PRAGMA journal_mode = WAL;
 `)
 	if tx.Error != nil {
 		return nil, tx.Error
@@ -103,31 +108,38 @@ func (v *VectorStore) CreateCollection(ctx context.Context, collection string, o
 	return nil
 }
 
+func (v *VectorStore) EmbedDocuments(ctx context.Context, docs []vs.Document) error {
+	return vs.EmbedDocuments(ctx, docs, v.embeddingFunc, 1)
+}
+
 func (v *VectorStore) AddDocuments(ctx context.Context, docs []vs.Document, collection string) ([]string, error) {
 	ids := make([]string, len(docs))
 
-	err := v.db.Transaction(func(tx *gorm.DB) error {
-		if len(docs) > 0 {
-			valuePlaceholders := make([]string, len(docs))
-			args := make([]interface{}, 0, len(docs)*2) // 2 args per doc: document_id and embedding
-
-			for i, doc := range docs {
-				emb, err := v.embeddingFunc(ctx, doc.Content)
-				if err != nil {
-					return fmt.Errorf("failed to compute embedding for document %s: %w", doc.ID, err)
-				}
+	// Compute embeddings before opening the transaction, so we don't hold the write lock while waiting for the provider
+	valuePlaceholders := make([]string, len(docs))
+	args := make([]interface{}, 0, len(docs)*2) // 2 args per doc: document_id and embedding
+	for i, doc := range docs {
+		emb := doc.Embedding
+		if emb == nil {
+			var err error
+			if emb, err = v.embeddingFunc(ctx, doc.Content); err != nil {
+				return nil, fmt.Errorf("failed to compute embedding for document %s: %w", doc.ID, err)
+			}
+		}
 
-				serializedEmb, err := sqlitevec.SerializeFloat32(emb)
-				if err != nil {
-					return fmt.Errorf("failed to serialize embedding for document %s: %w", doc.ID, err)
-				}
+		serializedEmb, err := sqlitevec.SerializeFloat32(emb)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize embedding for document %s: %w", doc.ID, err)
+		}
 
-				valuePlaceholders[i] = "(?, ?)"
-				args = append(args, doc.ID, serializedEmb)
+		valuePlaceholders[i] = "(?, ?)"
+		args = append(args, doc.ID, serializedEmb)
 
-				ids[i] = doc.ID
-			}
+		ids[i] = doc.ID
+	}
 
+	err := v.db.Transaction(func(tx *gorm.DB) error {
+		if len(docs) > 0 {
 			// Raw query for *_vec as gorm doesn't support virtual tables
 			query := fmt.Sprintf(`
 				INSERT INTO [%s_vec] (document_id, embedding)
@@ -179,7 +191,12 @@ func (v *VectorStore) SimilaritySearch(ctx context.Context, query string, numDoc
 		return nil, fmt.Errorf("failed to compute embedding: %w", err)
 	}
 
-	qv, err := sqlitevec.SerializeFloat32(q)
+	return v.SimilaritySearchByVector(ctx, q, numDocuments, collection, where, whereDocument)
+}
+
+// SimilaritySearchByVector is like SimilaritySearch, but queries with an existing embedding, e.g. a stored one.
+func (v *VectorStore) SimilaritySearchByVector(ctx context.Context, vector []float32, numDocuments int, collection string, where map[string]string, whereDocument []cg.WhereDocument) ([]vs.Document, error) {
+	qv, err := sqlitevec.SerializeFloat32(vector)
 	if err != nil {
 		return nil, fmt.Errorf("failed to serialize query embedding: %w", err)
 	}
@@ -376,3 +393,96 @@ func (v *VectorStore) ImportCollectionsFromFile(ctx context.Context, path string
 func (v *VectorStore) ExportCollectionsToFile(ctx context.Context, path string, collections ...string) error {
 	return fmt.Errorf("not implemented")
 }
+
+func (v *VectorStore) UpdateDocumentMetadata(ctx context.Context, collection string, documentIDs []string, metadata map[string]any) error {
+	return v.db.Transaction(func(tx *gorm.DB) error {
+		for _, id := range documentIDs {
+			var metadataJSON []byte
+			err := tx.Raw(fmt.Sprintf(`SELECT metadata FROM [%s] WHERE id = ? AND collection_id = ?`, v.embeddingsTableName), id, collection).Row().Scan(&metadataJSON)
+			if err != nil {
+				return fmt.Errorf("failed to query embeddings table for document %s: %w", id, err)
+			}
+
+			var docMetadata map[string]any
+			if err := json.Unmarshal(metadataJSON, &docMetadata); err != nil {
+				return fmt.Errorf("failed to parse metadata for document %s: %w", id, err)
+			}
+
+			updated, err := json.Marshal(vs.MergeMetadata(docMetadata, metadata))
+			if err != nil {
+				return fmt.Errorf("failed to marshal metadata for document %s: %w", id, err)
+			}
+
+			if err := tx.Exec(fmt.Sprintf(`UPDATE [%s] SET metadata = ? WHERE id = ? AND collection_id = ?`, v.embeddingsTableName), updated, id, collection).Error; err != nil {
+				return fmt.Errorf("failed to update metadata for document %s: %w", id, err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetEmbeddings returns the stored embeddings of the given documents, in order.
+func (v *VectorStore) GetEmbeddings(ctx context.Context, collection string, documentIDs ...string) ([][]float32, error) {
+	embeddings := make([][]float32, len(documentIDs))
+	for i, id := range documentIDs {
+		var serialized []byte
+		if err := v.db.Raw(fmt.Sprintf(`SELECT embedding FROM [%s_vec] WHERE document_id = ?`, collection), id).Row().Scan(&serialized); err != nil {
+			return nil, fmt.Errorf("failed to query vector table for document %s: %w", id, err)
+		}
+		if len(serialized)%4 != 0 {
+			return nil, fmt.Errorf("invalid embedding of document %s: %d bytes", id, len(serialized))
+		}
+
+		// Embeddings are stored as little-endian float32 values, see sqlitevec.SerializeFloat32
+		emb := make([]float32, len(serialized)/4)
+		for j := range emb {
+			emb[j] = math.Float32frombits(binary.LittleEndian.Uint32(serialized[j*4:]))
+		}
+		embeddings[i] = emb
+	}
+	return embeddings, nil
+}
+
+// CopyDocuments copies documents including their stored embeddings into another collection, so nothing is re-embedded.
+// Document IDs are the primary key of the shared embeddings table, so the copies get fresh IDs, returned in order.
+func (v *VectorStore) CopyDocuments(ctx context.Context, fromCollection, toCollection string, documentIDs ...string) ([]string, error) {
+	ids := make([]string, len(documentIDs))
+
+	err := v.db.Transaction(func(tx *gorm.DB) error {
+		for i, id := range documentIDs {
+			var content string
+			var metadataJSON []byte
+			err := tx.Raw(fmt.Sprintf(`SELECT content, metadata FROM [%s] WHERE id = ? AND collection_id = ?`, v.embeddingsTableName), id, fromCollection).Row().Scan(&content, &metadataJSON)
+			if err != nil {
+				return fmt.Errorf("failed to query embeddings table for document %s: %w", id, err)
+			}
+
+			var embedding []byte
+			if err := tx.Raw(fmt.Sprintf(`SELECT embedding FROM [%s_vec] WHERE document_id = ?`, fromCollection), id).Row().Scan(&embedding); err != nil {
+				return fmt.Errorf("failed to query vector table for document %s: %w", id, err)
+			}
+
+			ids[i] = uuid.NewString()
+
+			if err := tx.Exec(fmt.Sprintf(`INSERT INTO [%s_vec] (document_id, embedding) VALUES (?, ?)`, toCollection), ids[i], embedding).Error; err != nil {
+				return fmt.Errorf("failed to insert document %s into vector table: %w", id, err)
+			}
+
+			err = tx.Table(v.embeddingsTableName).Create(map[string]interface{}{
+				"id":            ids[i],
+				"collection_id": toCollection,
+				"content":       content,
+				"metadata":      metadataJSON,
+			}).Error
+			if err != nil {
+				return fmt.Errorf("failed to insert document %s into embeddings table: %w", id, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,63 @@
+package sqlite_vec
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	vs "github.com/gptscript-ai/knowledge/pkg/vectorstore/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testEmbeddingFunc maps texts to fixed vectors, so tests don't need an embeddings provider
+func testEmbeddingFunc(_ context.Context, text string) ([]float32, error) {
+	switch {
+	case strings.Contains(text, "apple"):
+		return []float32{1, 0, 0}, nil
+	case strings.Contains(text, "banana"):
+		return []float32{0, 1, 0}, nil
+	default:
+		return []float32{0, 0, 1}, nil
+	}
+}
+
+func newTestStore(t *testing.T, collections ...string) *VectorStore {
+	t.Helper()
+	ctx := context.Background()
+
+	store, err := New(ctx, "sqlite-vec://"+filepath.Join(t.TempDir(), "vs.db"), testEmbeddingFunc)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	for _, c := range collections {
+		require.NoError(t, store.CreateCollection(ctx, c, nil))
+	}
+	return store
+}
+
+func TestUpdateDocumentMetadataOnlyTouchesCollection(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t, "a", "b")
+
+	_, err := store.AddDocuments(ctx, []vs.Document{{ID: "doc-a", Content: "apple", Metadata: map[string]any{"k": "a"}}}, "a")
+	require.NoError(t, err)
+	_, err = store.AddDocuments(ctx, []vs.Document{{ID: "doc-b", Content: "banana", Metadata: map[string]any{"k": "b"}}}, "b")
+	require.NoError(t, err)
+
+	require.NoError(t, store.UpdateDocumentMetadata(ctx, "a", []string{"doc-a"}, map[string]any{"k": "updated"}))
+
+	// A document of another collection is not found, and stays as it is
+	assert.Error(t, store.UpdateDocumentMetadata(ctx, "a", []string{"doc-b"}, map[string]any{"k": "updated"}))
+
+	docs, err := store.GetDocuments(ctx, "a", nil, nil)
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "updated", docs[0].Metadata["k"])
+
+	docs, err = store.GetDocuments(ctx, "b", nil, nil)
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "b", docs[0].Metadata["k"])
+}
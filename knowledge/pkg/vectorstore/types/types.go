@@ -1,7 +1,12 @@
 package types
 
 import (
+	"context"
+	"fmt"
 	"slices"
+
+	cg "github.com/philippgille/chromem-go"
+	"golang.org/x/sync/errgroup"
 )
 
 type Document struct {
@@ -9,6 +14,8 @@ type Document struct {
 	Content         string         `json:"content"`
 	Metadata        map[string]any `json:"metadata"`
 	SimilarityScore float32        `json:"similarity_score"`
+	// Embedding is computed by the vector store when adding the document, unless it's set already
+	Embedding []float32 `json:"-"`
 }
 
 const (
@@ -56,3 +63,39 @@ func SortAndEnsureDocIndex(documents []Document) {
 		doc.Metadata[DocMetadataKeyDocsTotal] = l
 	}
 }
+
+// MergeMetadata applies the update to the given metadata map - nil values remove the key.
+func MergeMetadata(metadata map[string]any, update map[string]any) map[string]any {
+	if metadata == nil {
+		metadata = make(map[string]any, len(update))
+	}
+	for k, v := range update {
+		if v == nil {
+			delete(metadata, k)
+			continue
+		}
+		metadata[k] = v
+	}
+	return metadata
+}
+
+// EmbedDocuments computes the embeddings of the documents that don't have one yet, with at most concurrency calls at a
+// time. Documents without content are skipped, vector stores may embed a placeholder for them.
+func EmbedDocuments(ctx context.Context, docs []Document, embeddingFunc cg.EmbeddingFunc, concurrency int) error {
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(max(concurrency, 1))
+	for i := range docs {
+		if docs[i].Embedding != nil || docs[i].Content == "" {
+			continue
+		}
+		g.Go(func() error {
+			emb, err := embeddingFunc(gctx, docs[i].Content)
+			if err != nil {
+				return fmt.Errorf("failed to compute embedding for document %q: %w", docs[i].ID, err)
+			}
+			docs[i].Embedding = emb
+			return nil
+		})
+	}
+	return g.Wait()
+}
@@ -0,0 +1,38 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbedDocuments(t *testing.T) {
+	docs := []Document{
+		{ID: "a", Content: "apple"},
+		{ID: "b"},
+		{ID: "c", Content: "cherry", Embedding: []float32{3}},
+	}
+	var embedded []string
+	embed := func(_ context.Context, text string) ([]float32, error) {
+		embedded = append(embedded, text)
+		return []float32{1}, nil
+	}
+
+	require.NoError(t, EmbedDocuments(context.Background(), docs, embed, 1))
+	assert.Equal(t, []string{"apple"}, embedded, "documents without content or with an embedding are skipped")
+	assert.Equal(t, []float32{1}, docs[0].Embedding)
+	assert.Nil(t, docs[1].Embedding)
+	assert.Equal(t, []float32{3}, docs[2].Embedding)
+
+	// Embedding again doesn't call the provider
+	require.NoError(t, EmbedDocuments(context.Background(), docs, embed, 1))
+	assert.Len(t, embedded, 1)
+
+	err := EmbedDocuments(context.Background(), []Document{{ID: "d", Content: "date"}}, func(context.Context, string) ([]float32, error) {
+		return nil, errors.New("rate limited")
+	}, 1)
+	assert.ErrorContains(t, err, `failed to compute embedding for document "d": rate limited`)
+}
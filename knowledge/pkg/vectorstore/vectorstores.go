@@ -17,11 +17,16 @@ import (
 
 type VectorStore interface {
 	CreateCollection(ctx context.Context, collection string, opts *dbtypes.DatasetCreateOpts) error
+	EmbedDocuments(ctx context.Context, docs []types.Document) error                                                                                                                                              // sets the embeddings that AddDocuments would compute
 	AddDocuments(ctx context.Context, docs []types.Document, collection string) ([]string, error)                                                                                                                 // @return documentIDs, error
 	SimilaritySearch(ctx context.Context, query string, numDocuments int, collection string, where map[string]string, whereDocument []cg.WhereDocument, embeddingFunc cg.EmbeddingFunc) ([]types.Document, error) //nolint:lll
+	SimilaritySearchByVector(ctx context.Context, vector []float32, numDocuments int, collection string, where map[string]string, whereDocument []cg.WhereDocument) ([]types.Document, error)                     //nolint:lll
 	RemoveCollection(ctx context.Context, collection string) error
 	RemoveDocument(ctx context.Context, documentID string, collection string, where map[string]string, whereDocument []cg.WhereDocument) error
 	GetDocuments(ctx context.Context, collection string, where map[string]string, whereDocument []cg.WhereDocument) ([]types.Document, error)
+	UpdateDocumentMetadata(ctx context.Context, collection string, documentIDs []string, metadata map[string]any) error // nil values remove the metadata key, embeddings are kept as-is
+	GetEmbeddings(ctx context.Context, collection string, documentIDs ...string) ([][]float32, error)                   // stored embeddings, in order
+	CopyDocuments(ctx context.Context, fromCollection, toCollection string, documentIDs ...string) ([]string, error)    // keeps metadata and embeddings, returns the IDs of the copies in order
 
 	ImportCollectionsFromFile(ctx context.Context, path string, collections ...string) error
 	ExportCollectionsToFile(ctx context.Context, path string, collections ...string) error
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gptscript-ai/tools/onedrive/pkg/commands"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Println("Usage: gptscript-go-tool <command>")
+		os.Exit(1)
+	}
+
+	command := os.Args[1]
+
+	var (
+		err error
+		ctx = context.Background()
+	)
+	switch command {
+	case "listDrives":
+		err = commands.ListDrives(ctx, os.Getenv("SITE_QUERY"))
+	case "listFiles":
+		var limit int
+		if limit, err = limitFromEnv(); err == nil {
+			err = commands.ListFiles(ctx, os.Getenv("DRIVE_ID"), os.Getenv("FOLDER_ID"), limit)
+		}
+	case "searchFiles":
+		var limit int
+		if limit, err = limitFromEnv(); err == nil {
+			err = commands.SearchFiles(ctx, os.Getenv("DRIVE_ID"), os.Getenv("QUERY"), limit)
+		}
+	case "downloadFile":
+		err = commands.DownloadFile(ctx, os.Getenv("DRIVE_ID"), os.Getenv("FILE_ID"), os.Getenv("FILE_NAME"))
+	case "uploadFile":
+		err = commands.UploadFile(ctx, os.Getenv("DRIVE_ID"), os.Getenv("FOLDER_ID"), os.Getenv("FILE_PATH"), os.Getenv("NAME"), os.Getenv("CONFLICT_BEHAVIOR"))
+	case "shareFile":
+		var recipients []string
+		for _, recipient := range strings.Split(os.Getenv("RECIPIENTS"), ",") {
+			if recipient = strings.TrimSpace(recipient); recipient != "" {
+				recipients = append(recipients, recipient)
+			}
+		}
+		err = commands.ShareFile(ctx, os.Getenv("DRIVE_ID"), os.Getenv("FILE_ID"), os.Getenv("LINK_TYPE"), os.Getenv("SCOPE"), recipients, os.Getenv("MESSAGE"))
+	default:
+		fmt.Printf("Unknown command: %s\n", command)
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+// limitFromEnv returns the maximum number of files to list, 100 if it is not set.
+func limitFromEnv() (int, error) {
+	v := os.Getenv("LIMIT")
+	if v == "" {
+		return 100, nil
+	}
+	limit, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse limit: %w", err)
+	}
+	return limit, nil
+}
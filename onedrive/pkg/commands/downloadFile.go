@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/onedrive/pkg/client"
+	"github.com/gptscript-ai/tools/onedrive/pkg/global"
+	"github.com/gptscript-ai/tools/onedrive/pkg/graph"
+	"github.com/gptscript-ai/tools/onedrive/pkg/util"
+)
+
+// maxDownloadSize is the size of the largest file that is downloaded to the workspace
+const maxDownloadSize = 250 * 1024 * 1024
+
+// DownloadFile saves the file to the files of the workspace, and prints its path. The file name defaults to the name
+// of the file in OneDrive.
+func DownloadFile(ctx context.Context, driveID, itemID, fileName string) error {
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return err
+	}
+
+	driveID, err = graph.DriveID(ctx, c, driveID)
+	if err != nil {
+		return err
+	}
+
+	item, err := graph.GetItem(ctx, c, driveID, itemID)
+	if err != nil {
+		return err
+	}
+	if item.GetFolder() != nil {
+		return fmt.Errorf("%s is a folder, only files can be downloaded", util.Deref(item.GetName()))
+	}
+	if size := util.Deref(item.GetSize()); size > maxDownloadSize {
+		return fmt.Errorf("%s is too large to download (%d bytes, the limit is %d bytes)", util.Deref(item.GetName()), size, maxDownloadSize)
+	}
+
+	if fileName == "" {
+		fileName = util.Deref(item.GetName())
+	}
+	fileName, err = cleanFileName(fileName)
+	if err != nil {
+		return err
+	}
+
+	data, err := graph.DownloadItem(ctx, c, driveID, itemID)
+	if err != nil {
+		return err
+	}
+
+	gptscriptClient, err := gptscript.NewGPTScript()
+	if err != nil {
+		return fmt.Errorf("failed to create GPTScript client: %w", err)
+	}
+
+	if err := gptscriptClient.WriteFileInWorkspace(ctx, path.Join("files", fileName), data); err != nil {
+		return fmt.Errorf("failed to save file to workspace: %w", err)
+	}
+
+	fmt.Printf("File saved to the workspace as %s (%d bytes)\n", fileName, len(data))
+	return nil
+}
+
+// cleanFileName keeps the file inside the files of the workspace
+func cleanFileName(fileName string) (string, error) {
+	cleaned := strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(fileName, "\\", "/")), "/")
+	if cleaned == "" || cleaned == "." {
+		return "", errors.New("the file has no name, please provide a file name")
+	}
+	return cleaned, nil
+}
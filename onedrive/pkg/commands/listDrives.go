@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/common/guard"
+	"github.com/gptscript-ai/tools/onedrive/pkg/client"
+	"github.com/gptscript-ai/tools/onedrive/pkg/global"
+	"github.com/gptscript-ai/tools/onedrive/pkg/graph"
+	"github.com/gptscript-ai/tools/onedrive/pkg/printers"
+	"github.com/gptscript-ai/tools/onedrive/pkg/util"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// ListDrives lists the user's drives, or the document libraries of the SharePoint sites that match the site query.
+func ListDrives(ctx context.Context, siteQuery string) error {
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return err
+	}
+
+	var drives []models.Driveable
+	if siteQuery == "" {
+		drives, err = graph.ListDrives(ctx, c)
+	} else {
+		drives, err = graph.ListSiteDrives(ctx, c, siteQuery)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(drives) == 0 {
+		fmt.Println("No drives found")
+		return nil
+	}
+
+	elements := make([]gptscript.DatasetElement, 0, len(drives))
+	for _, drive := range drives {
+		elements = append(elements, gptscript.DatasetElement{
+			DatasetElementMeta: gptscript.DatasetElementMeta{
+				Name:        util.Deref(drive.GetId()),
+				Description: util.Deref(drive.GetName()),
+			},
+			Contents: printers.DriveToString(drive),
+		})
+	}
+
+	return guard.PrintElements(ctx, nil, elements, gptscript.DatasetOptions{
+		Name:        "onedrive_drives",
+		Description: "OneDrive and SharePoint drives available to the user",
+	}, "drives")
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/common/guard"
+	"github.com/gptscript-ai/tools/common/locale"
+	"github.com/gptscript-ai/tools/onedrive/pkg/client"
+	"github.com/gptscript-ai/tools/onedrive/pkg/global"
+	"github.com/gptscript-ai/tools/onedrive/pkg/graph"
+	"github.com/gptscript-ai/tools/onedrive/pkg/printers"
+	"github.com/gptscript-ai/tools/onedrive/pkg/util"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// ListFiles lists up to limit files and folders of the folder, or of the root folder if folderID is empty, in the
+// drive, or in the user's OneDrive if driveID is empty.
+func ListFiles(ctx context.Context, driveID, folderID string, limit int) error {
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return err
+	}
+
+	driveID, err = graph.DriveID(ctx, c, driveID)
+	if err != nil {
+		return err
+	}
+
+	items, err := graph.ListItems(ctx, c, driveID, folderID, limit)
+	if err != nil {
+		return err
+	}
+
+	return printItems(ctx, items, "onedrive_files", "Files and folders of a OneDrive folder")
+}
+
+// SearchFiles lists up to limit files and folders of the drive, or of the user's OneDrive if driveID is empty, that
+// match the query.
+func SearchFiles(ctx context.Context, driveID, query string, limit int) error {
+	if query == "" {
+		return fmt.Errorf("a query is required")
+	}
+
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return err
+	}
+
+	driveID, err = graph.DriveID(ctx, c, driveID)
+	if err != nil {
+		return err
+	}
+
+	items, err := graph.SearchItems(ctx, c, driveID, query, limit)
+	if err != nil {
+		return err
+	}
+
+	return printItems(ctx, items, "onedrive_search "+query, "OneDrive files matching "+query)
+}
+
+func printItems(ctx context.Context, items []models.DriveItemable, name, description string) error {
+	if len(items) == 0 {
+		fmt.Println(locale.FromEnv().T("No files found"))
+		return nil
+	}
+
+	elements := make([]gptscript.DatasetElement, 0, len(items))
+	for _, item := range items {
+		elements = append(elements, gptscript.DatasetElement{
+			DatasetElementMeta: gptscript.DatasetElementMeta{
+				Name:        util.Deref(item.GetId()),
+				Description: util.Deref(item.GetName()),
+			},
+			Contents: printers.ItemToString(item),
+		})
+	}
+
+	return guard.PrintElements(ctx, nil, elements, gptscript.DatasetOptions{
+		Name:        name,
+		Description: description,
+	}, "files")
+}
@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gptscript-ai/tools/onedrive/pkg/client"
+	"github.com/gptscript-ai/tools/onedrive/pkg/global"
+	"github.com/gptscript-ai/tools/onedrive/pkg/graph"
+)
+
+// ShareFile shares the file or folder of the drive, or of the user's OneDrive if driveID is empty. With recipients,
+// the recipients are invited by email; otherwise a sharing link with the scope is created.
+func ShareFile(ctx context.Context, driveID, itemID, linkType, scope string, recipients []string, message string) error {
+	linkType, scope, err := graph.ParseShareOptions(linkType, scope)
+	if err != nil {
+		return err
+	}
+
+	c, err := client.NewClient(global.AllScopes)
+	if err != nil {
+		return err
+	}
+
+	driveID, err = graph.DriveID(ctx, c, driveID)
+	if err != nil {
+		return err
+	}
+
+	if len(recipients) > 0 {
+		if _, err := graph.ShareWithUsers(ctx, c, driveID, itemID, linkType, recipients, message); err != nil {
+			return err
+		}
+		fmt.Printf("Shared with %s (%s access), an invitation was sent\n", strings.Join(recipients, ", "), linkType)
+		return nil
+	}
+
+	link, err := graph.CreateSharingLink(ctx, c, driveID, itemID, linkType, scope)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Sharing link (%s access for %s): %s\n", linkType, scope, link)
+	return nil
+}
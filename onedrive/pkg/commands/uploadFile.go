@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/onedrive/pkg/client"
+	"github.com/gptscript-ai/tools/onedrive/pkg/global"
+	"github.com/gptscript-ai/tools/onedrive/pkg/graph"
+)
+
+// UploadFile uploads the file of the workspace to the folder, or to the root folder if folderID is empty, of the
+// drive, or of the user's OneDrive if driveID is empty. The name defaults to the name of the workspace file.
+func UploadFile(ctx context.Context, driveID, folderID, filePath, name, conflictBehavior string) error {
+	conflictBehavior, err := graph.ParseConflictBehavior(conflictBehavior)
+	if err != nil {
+		return err
+	}
+
+	filePath, err = cleanFileName(filePath)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		name = path.Base(filePath)
+	}
+
+	gptscriptClient, err := gptscript.NewGPTScript()
+	if err != nil {
+		return fmt.Errorf("failed to create GPTScript client: %w", err)
+	}
+
+	data, err := gptscriptClient.ReadFileInWorkspace(ctx, path.Join("files", filePath))
+	if err != nil {
+		return fmt.Errorf("failed to read file %s from workspace: %w", filePath, err)
+	}
+
+	c, err := client.NewClient(global.AllScopes)
+	if err != nil {
+		return err
+	}
+
+	driveID, err = graph.DriveID(ctx, c, driveID)
+	if err != nil {
+		return err
+	}
+
+	item, err := graph.UploadFile(ctx, c, driveID, folderID, name, data, conflictBehavior)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("File uploaded as %s (%d bytes) with ID: %s\n", item.Name, len(data), item.ID)
+	if item.WebURL != "" {
+		fmt.Printf("Link: %s\n", item.WebURL)
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+package global
+
+const CredentialEnv = "GPTSCRIPT_MICROSOFT_ONEDRIVE_TOKEN"
+
+var (
+	ReadOnlyScopes = []string{"Files.Read", "Files.Read.All", "Sites.Read.All", "User.Read"}
+	AllScopes      = []string{"Files.Read", "Files.Read.All", "Files.ReadWrite", "Files.ReadWrite.All", "Sites.Read.All", "User.Read"}
+)
@@ -0,0 +1,70 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gptscript-ai/tools/onedrive/pkg/util"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/sites"
+)
+
+// ListDrives returns the drives of the user, which is usually their OneDrive.
+func ListDrives(ctx context.Context, c *msgraphsdkgo.GraphServiceClient) ([]models.Driveable, error) {
+	result, err := c.Me().Drives().Get(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list drives: %w", err)
+	}
+	return collect(ctx, result, func(ctx context.Context, nextLink string) (models.DriveCollectionResponseable, error) {
+		return c.Me().Drives().WithUrl(nextLink).Get(ctx, nil)
+	}, 0)
+}
+
+// ListSiteDrives returns the document libraries of the SharePoint sites that match the query.
+func ListSiteDrives(ctx context.Context, c *msgraphsdkgo.GraphServiceClient, query string) ([]models.Driveable, error) {
+	result, err := c.Sites().Get(ctx, &sites.SitesRequestBuilderGetRequestConfiguration{
+		QueryParameters: &sites.SitesRequestBuilderGetQueryParameters{
+			Search: util.Ptr(query),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search sites: %w", err)
+	}
+	siteList, err := collect(ctx, result, func(ctx context.Context, nextLink string) (models.SiteCollectionResponseable, error) {
+		return c.Sites().WithUrl(nextLink).Get(ctx, nil)
+	}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search sites: %w", err)
+	}
+
+	var drives []models.Driveable
+	for _, site := range siteList {
+		siteDrives := c.Sites().BySiteId(util.Deref(site.GetId())).Drives()
+		result, err := siteDrives.Get(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list drives of site %s: %w", util.Deref(site.GetDisplayName()), err)
+		}
+		d, err := collect(ctx, result, func(ctx context.Context, nextLink string) (models.DriveCollectionResponseable, error) {
+			return siteDrives.WithUrl(nextLink).Get(ctx, nil)
+		}, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list drives of site %s: %w", util.Deref(site.GetDisplayName()), err)
+		}
+		drives = append(drives, d...)
+	}
+	return drives, nil
+}
+
+// DriveID returns the ID of the drive, or of the user's OneDrive if driveID is empty.
+func DriveID(ctx context.Context, c *msgraphsdkgo.GraphServiceClient, driveID string) (string, error) {
+	if driveID != "" {
+		return driveID, nil
+	}
+
+	drive, err := c.Me().Drive().Get(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the user's drive: %w", err)
+	}
+	return util.Deref(drive.GetId()), nil
+}
@@ -0,0 +1,77 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gptscript-ai/tools/onedrive/pkg/util"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/drives"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// ListItems returns up to limit files and folders of the folder, or of the root folder of the drive if folderID is
+// empty. A limit of 0 or less returns all items.
+func ListItems(ctx context.Context, c *msgraphsdkgo.GraphServiceClient, driveID, folderID string, limit int) ([]models.DriveItemable, error) {
+	if folderID == "" {
+		folderID = "root"
+	}
+
+	children := c.Drives().ByDriveId(driveID).Items().ByDriveItemId(folderID).Children()
+	result, err := children.Get(ctx, &drives.ItemItemsItemChildrenRequestBuilderGetRequestConfiguration{
+		QueryParameters: &drives.ItemItemsItemChildrenRequestBuilderGetQueryParameters{
+			Top: pageSize(limit),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	items, err := collect(ctx, result, func(ctx context.Context, nextLink string) (models.DriveItemCollectionResponseable, error) {
+		return children.WithUrl(nextLink).Get(ctx, nil)
+	}, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+	return items, nil
+}
+
+// SearchItems returns up to limit files and folders of the drive that match the query in their name, metadata or
+// content. A limit of 0 or less returns all matches.
+func SearchItems(ctx context.Context, c *msgraphsdkgo.GraphServiceClient, driveID, query string, limit int) ([]models.DriveItemable, error) {
+	search := c.Drives().ByDriveId(driveID).SearchWithQ(util.Ptr(query))
+	result, err := search.GetAsSearchWithQGetResponse(ctx, &drives.ItemSearchWithQRequestBuilderGetRequestConfiguration{
+		QueryParameters: &drives.ItemSearchWithQRequestBuilderGetQueryParameters{
+			Top: pageSize(limit),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search files: %w", err)
+	}
+
+	items, err := collect(ctx, result, func(ctx context.Context, nextLink string) (drives.ItemSearchWithQGetResponseable, error) {
+		return search.WithUrl(nextLink).GetAsSearchWithQGetResponse(ctx, nil)
+	}, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search files: %w", err)
+	}
+	return items, nil
+}
+
+// GetItem returns the file or folder.
+func GetItem(ctx context.Context, c *msgraphsdkgo.GraphServiceClient, driveID, itemID string) (models.DriveItemable, error) {
+	item, err := c.Drives().ByDriveId(driveID).Items().ByDriveItemId(itemID).Get(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+	return item, nil
+}
+
+// DownloadItem returns the content of the file.
+func DownloadItem(ctx context.Context, c *msgraphsdkgo.GraphServiceClient, driveID, itemID string) ([]byte, error) {
+	data, err := c.Drives().ByDriveId(driveID).Items().ByDriveItemId(itemID).Content().Get(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,41 @@
+package graph
+
+import "context"
+
+// page is implemented by the Graph collection responses, e.g. models.DriveItemCollectionResponseable.
+type page[T any] interface {
+	GetValue() []T
+	GetOdataNextLink() *string
+}
+
+// collect returns up to limit items of the first page and the pages that follow it, which are requested with next.
+// A limit of 0 or less returns all items.
+func collect[T any, P page[T]](ctx context.Context, first P, next func(ctx context.Context, nextLink string) (P, error), limit int) ([]T, error) {
+	var items []T
+	for p := first; ; {
+		items = append(items, p.GetValue()...)
+		if limit > 0 && len(items) >= limit {
+			return items[:limit], nil
+		}
+
+		nextLink := p.GetOdataNextLink()
+		if nextLink == nil || *nextLink == "" {
+			return items, nil
+		}
+
+		var err error
+		if p, err = next(ctx, *nextLink); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// pageSize returns the $top of the first request for the limit. Without a limit, or with a limit above 200, the first
+// request uses Graph's default page size.
+func pageSize(limit int) *int32 {
+	if limit <= 0 || limit > 200 {
+		return nil
+	}
+	top := int32(limit)
+	return &top
+}
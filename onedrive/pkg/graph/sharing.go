@@ -0,0 +1,89 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gptscript-ai/tools/onedrive/pkg/util"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/drives"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// CreateSharingLink creates a link to the file or folder. The link type is view or edit, and the scope is anonymous
+// (anyone with the link) or organization (everyone in the user's organization).
+func CreateSharingLink(ctx context.Context, c *msgraphsdkgo.GraphServiceClient, driveID, itemID, linkType, scope string) (string, error) {
+	requestBody := drives.NewItemItemsItemCreateLinkPostRequestBody()
+	requestBody.SetTypeEscaped(util.Ptr(linkType))
+	requestBody.SetScope(util.Ptr(scope))
+
+	permission, err := c.Drives().ByDriveId(driveID).Items().ByDriveItemId(itemID).CreateLink().Post(ctx, requestBody, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create sharing link: %w", err)
+	}
+	if permission.GetLink() == nil || permission.GetLink().GetWebUrl() == nil {
+		return "", fmt.Errorf("no sharing link was returned")
+	}
+	return *permission.GetLink().GetWebUrl(), nil
+}
+
+// ShareWithUsers grants the users with the email addresses access to the file or folder and sends them an invitation
+// with the message. The link type is view or edit.
+func ShareWithUsers(ctx context.Context, c *msgraphsdkgo.GraphServiceClient, driveID, itemID, linkType string, emails []string, message string) ([]models.Permissionable, error) {
+	var recipients []models.DriveRecipientable
+	for _, email := range emails {
+		if email = strings.TrimSpace(email); email != "" {
+			recipient := models.NewDriveRecipient()
+			recipient.SetEmail(util.Ptr(email))
+			recipients = append(recipients, recipient)
+		}
+	}
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("no recipients to share with")
+	}
+
+	requestBody := drives.NewItemItemsItemInvitePostRequestBody()
+	requestBody.SetRecipients(recipients)
+	requestBody.SetRoles([]string{shareRole(linkType)})
+	requestBody.SetRequireSignIn(util.Ptr(true))
+	requestBody.SetSendInvitation(util.Ptr(true))
+	if message != "" {
+		requestBody.SetMessage(util.Ptr(message))
+	}
+
+	result, err := c.Drives().ByDriveId(driveID).Items().ByDriveItemId(itemID).Invite().PostAsInvitePostResponse(ctx, requestBody, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to share file: %w", err)
+	}
+	return result.GetValue(), nil
+}
+
+// ParseShareOptions parses the link type (view or edit, view by default) and the scope of a sharing link (anonymous
+// or organization, organization by default).
+func ParseShareOptions(linkType, scope string) (string, string, error) {
+	switch linkType = strings.ToLower(strings.TrimSpace(linkType)); linkType {
+	case "":
+		linkType = "view"
+	case "view", "edit":
+	default:
+		return "", "", fmt.Errorf("invalid link type %q, must be view or edit", linkType)
+	}
+
+	switch scope = strings.ToLower(strings.TrimSpace(scope)); scope {
+	case "":
+		scope = "organization"
+	case "anonymous", "organization":
+	default:
+		return "", "", fmt.Errorf("invalid scope %q, must be anonymous or organization", scope)
+	}
+	return linkType, scope, nil
+}
+
+// shareRole returns the role of the invited users for the link type.
+func shareRole(linkType string) string {
+	if linkType == "edit" {
+		return "write"
+	}
+	return "read"
+}
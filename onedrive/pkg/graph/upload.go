@@ -0,0 +1,207 @@
+package graph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gptscript-ai/tools/onedrive/pkg/util"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/drives"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+const (
+	// simpleUploadLimit is the size up to which files are uploaded in a single request. Larger files are uploaded in
+	// chunks with an upload session.
+	simpleUploadLimit = 4 * 1024 * 1024
+	// uploadChunkSize is the size of the chunks of an upload session, which Graph requires to be a multiple of 320 KiB
+	uploadChunkSize = 10 * 320 * 1024
+	// maxChunkAttempts is how often a chunk is sent before the upload fails, if Graph is throttling or unavailable
+	maxChunkAttempts = 3
+)
+
+// retryWait is the time to wait before sending a chunk again if Graph doesn't say how long to wait
+var retryWait = 2 * time.Second
+
+// UploadedItem is the file that was uploaded.
+type UploadedItem struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	WebURL string `json:"webUrl"`
+	Size   int64  `json:"size"`
+}
+
+// ParseConflictBehavior parses what happens if the folder already has a file with the same name: rename (the default),
+// replace or fail.
+func ParseConflictBehavior(conflictBehavior string) (string, error) {
+	switch conflictBehavior = strings.ToLower(strings.TrimSpace(conflictBehavior)); conflictBehavior {
+	case "":
+		return "rename", nil
+	case "rename", "replace", "fail":
+		return conflictBehavior, nil
+	default:
+		return "", fmt.Errorf("invalid conflict behavior %q, must be rename, replace or fail", conflictBehavior)
+	}
+}
+
+// UploadFile uploads the data as a file with the name to the folder, or to the root folder of the drive if folderID is
+// empty. Files larger than 4 MB are uploaded in chunks with an upload session.
+func UploadFile(ctx context.Context, c *msgraphsdkgo.GraphServiceClient, driveID, folderID, name string, data []byte, conflictBehavior string) (*UploadedItem, error) {
+	if folderID == "" {
+		folderID = "root"
+	}
+
+	if len(data) <= simpleUploadLimit {
+		content := c.Drives().ByDriveId(driveID).Items().ByDriveItemId(folderID).Content()
+		item, err := content.WithUrl(itemPathURL(c, driveID, folderID, name, "content")+"?@microsoft.graph.conflictBehavior="+conflictBehavior).Put(ctx, data, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload file: %w", err)
+		}
+		return &UploadedItem{
+			ID:     util.Deref(item.GetId()),
+			Name:   util.Deref(item.GetName()),
+			WebURL: util.Deref(item.GetWebUrl()),
+			Size:   util.Deref(item.GetSize()),
+		}, nil
+	}
+
+	properties := models.NewDriveItemUploadableProperties()
+	properties.SetAdditionalData(map[string]any{"@microsoft.graph.conflictBehavior": conflictBehavior})
+	requestBody := drives.NewItemItemsItemCreateUploadSessionPostRequestBody()
+	requestBody.SetItem(properties)
+
+	createUploadSession := c.Drives().ByDriveId(driveID).Items().ByDriveItemId(folderID).CreateUploadSession()
+	session, err := createUploadSession.WithUrl(itemPathURL(c, driveID, folderID, name, "createUploadSession")).Post(ctx, requestBody, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create upload session: %w", err)
+	}
+
+	// The upload URL is pre-authenticated, so the chunks are sent without the token of the Graph client
+	item, err := uploadChunks(ctx, http.DefaultClient, util.Deref(session.GetUploadUrl()), data, uploadChunkSize)
+	if err != nil {
+		cancelUploadSession(http.DefaultClient, util.Deref(session.GetUploadUrl()))
+		return nil, err
+	}
+	return item, nil
+}
+
+// itemPathURL returns the URL of the action of the item with the name in the folder, addressed by its path, e.g.
+// /drives/{drive}/items/{folder}:/report.pdf:/content. The item doesn't have to exist yet.
+func itemPathURL(c *msgraphsdkgo.GraphServiceClient, driveID, folderID, name, action string) string {
+	return fmt.Sprintf("%s/drives/%s/items/%s:/%s:/%s", c.BaseRequestBuilder.RequestAdapter.GetBaseUrl(),
+		url.PathEscape(driveID), url.PathEscape(folderID), url.PathEscape(name), action)
+}
+
+// uploadChunks sends the data to the upload URL of an upload session in chunks of chunkSize, and returns the item once
+// the last chunk is received. Graph reports the ranges it still expects after each chunk, which is where the next
+// chunk starts.
+func uploadChunks(ctx context.Context, client *http.Client, uploadURL string, data []byte, chunkSize int) (*UploadedItem, error) {
+	total := len(data)
+	for start := 0; start < total; {
+		end := min(start+chunkSize, total)
+
+		resp, body, err := putChunk(ctx, client, uploadURL, data[start:end], start, total)
+		if err != nil {
+			return nil, err
+		}
+
+		switch resp.StatusCode {
+		case http.StatusOK, http.StatusCreated:
+			var item UploadedItem
+			if err := json.Unmarshal(body, &item); err != nil {
+				return nil, fmt.Errorf("failed to decode uploaded file: %w", err)
+			}
+			return &item, nil
+		case http.StatusAccepted:
+			var status struct {
+				NextExpectedRanges []string `json:"nextExpectedRanges"`
+			}
+			if err := json.Unmarshal(body, &status); err != nil {
+				return nil, fmt.Errorf("failed to decode upload status: %w", err)
+			}
+			next, ok := nextExpectedStart(status.NextExpectedRanges)
+			if !ok {
+				next = end
+			}
+			if next <= start || next > total {
+				return nil, fmt.Errorf("unexpected range %v after uploading bytes %d-%d", status.NextExpectedRanges, start, end-1)
+			}
+			start = next
+		default:
+			return nil, fmt.Errorf("failed to upload bytes %d-%d/%d: %s: %s", start, end-1, total, resp.Status, strings.TrimSpace(string(body)))
+		}
+	}
+	return nil, fmt.Errorf("the upload session ended without returning the uploaded file")
+}
+
+// putChunk sends the chunk starting at offset start, and sends it again if Graph is throttling or unavailable.
+func putChunk(ctx context.Context, client *http.Client, uploadURL string, chunk []byte, start, total int) (*http.Response, []byte, error) {
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPut, uploadURL, bytes.NewReader(chunk))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create upload request: %w", err)
+		}
+		req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, start+len(chunk)-1, total))
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to upload bytes %d-%d/%d: %w", start, start+len(chunk)-1, total, err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read upload response: %w", err)
+		}
+
+		if attempt >= maxChunkAttempts || (resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < http.StatusInternalServerError) {
+			return resp, body, nil
+		}
+
+		wait := retryWait * time.Duration(attempt)
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			wait = time.Duration(seconds) * time.Second
+		}
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// nextExpectedStart returns the start of the first of the ranges Graph expects next, e.g. 3276800 for "3276800-" or
+// "3276800-4194303".
+func nextExpectedStart(ranges []string) (int, bool) {
+	if len(ranges) == 0 {
+		return 0, false
+	}
+	start, _, _ := strings.Cut(ranges[0], "-")
+	n, err := strconv.Atoi(start)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// cancelUploadSession deletes the upload session, so the uploaded chunks are discarded. Errors are ignored, Graph
+// deletes unfinished upload sessions after a while anyway.
+func cancelUploadSession(client *http.Client, uploadURL string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, uploadURL, nil)
+	if err != nil {
+		return
+	}
+	if resp, err := client.Do(req); err == nil {
+		resp.Body.Close()
+	}
+}
@@ -0,0 +1,160 @@
+package graph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// uploadServer is an upload session that accepts chunks in order. It fails the first attempt of the chunks in
+// unavailable with 503, and expects the chunks from the start of restartAt again after receiving it once.
+type uploadServer struct {
+	t           *testing.T
+	size        int
+	received    bytes.Buffer
+	ranges      []string
+	unavailable map[int]bool
+	restartAt   int
+	restarted   bool
+}
+
+func (s *uploadServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "" {
+		s.t.Errorf("the upload URL must be called without a token")
+	}
+
+	contentRange := r.Header.Get("Content-Range")
+	s.ranges = append(s.ranges, contentRange)
+
+	var start, end, total int
+	if _, err := fmt.Sscanf(contentRange, "bytes %d-%d/%d", &start, &end, &total); err != nil || total != s.size {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if s.unavailable[start] {
+		delete(s.unavailable, start)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	data, _ := io.ReadAll(r.Body)
+	if start != s.received.Len() || len(data) != end-start+1 {
+		w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+		return
+	}
+	s.received.Write(data)
+
+	if !s.restarted && s.restartAt > 0 && s.received.Len() > s.restartAt {
+		// Pretend the chunks after restartAt were lost
+		s.restarted = true
+		s.received.Truncate(s.restartAt)
+	}
+
+	if s.received.Len() == s.size {
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "01BYE5RZ", "name": "report.pdf", "webUrl": "https://contoso-my.sharepoint.com/report.pdf", "size": s.size})
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(map[string]any{"nextExpectedRanges": []string{fmt.Sprintf("%d-", s.received.Len())}})
+}
+
+func TestUploadChunks(t *testing.T) {
+	retryWait = time.Millisecond
+	data := bytes.Repeat([]byte("0123456789"), 25)
+
+	for _, tc := range []struct {
+		name        string
+		unavailable map[int]bool
+		restartAt   int
+		wantRanges  []string
+	}{
+		{
+			name:       "in order",
+			wantRanges: []string{"bytes 0-99/250", "bytes 100-199/250", "bytes 200-249/250"},
+		},
+		{
+			name:        "retries unavailable",
+			unavailable: map[int]bool{100: true},
+			wantRanges:  []string{"bytes 0-99/250", "bytes 100-199/250", "bytes 100-199/250", "bytes 200-249/250"},
+		},
+		{
+			name:       "follows next expected ranges",
+			restartAt:  50,
+			wantRanges: []string{"bytes 0-99/250", "bytes 50-149/250", "bytes 150-249/250"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &uploadServer{t: t, size: len(data), unavailable: tc.unavailable, restartAt: tc.restartAt}
+			server := httptest.NewServer(s)
+			defer server.Close()
+
+			item, err := uploadChunks(context.Background(), server.Client(), server.URL, data, 100)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if item.ID != "01BYE5RZ" || item.Name != "report.pdf" || item.Size != int64(len(data)) {
+				t.Fatalf("unexpected item: %+v", item)
+			}
+			if !bytes.Equal(s.received.Bytes(), data) {
+				t.Fatalf("the server received %q", s.received.String())
+			}
+			if strings.Join(s.ranges, ", ") != strings.Join(tc.wantRanges, ", ") {
+				t.Fatalf("expected ranges %v, got %v", tc.wantRanges, s.ranges)
+			}
+		})
+	}
+}
+
+func TestUploadChunksFails(t *testing.T) {
+	retryWait = time.Millisecond
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"error":{"code":"serviceNotAvailable"}}`))
+	}))
+	defer server.Close()
+
+	_, err := uploadChunks(context.Background(), server.Client(), server.URL, make([]byte, 250), 100)
+	if err == nil || !strings.Contains(err.Error(), "serviceNotAvailable") {
+		t.Fatalf("expected the error of the upload session, got %v", err)
+	}
+	if requests != maxChunkAttempts {
+		t.Fatalf("expected %d attempts, got %d", maxChunkAttempts, requests)
+	}
+}
+
+func TestUploadChunkSize(t *testing.T) {
+	if uploadChunkSize%(320*1024) != 0 {
+		t.Fatalf("the chunk size %d is not a multiple of 320 KiB", uploadChunkSize)
+	}
+}
+
+func TestParseOptions(t *testing.T) {
+	for input, want := range map[string]string{"": "rename", "Replace": "replace", " fail ": "fail"} {
+		if got, err := ParseConflictBehavior(input); err != nil || got != want {
+			t.Errorf("%q: expected %s, got %s, %v", input, want, got, err)
+		}
+	}
+	if _, err := ParseConflictBehavior("overwrite"); err == nil {
+		t.Error("expected an error for an invalid conflict behavior")
+	}
+
+	if linkType, scope, err := ParseShareOptions("", ""); err != nil || linkType != "view" || scope != "organization" {
+		t.Errorf("unexpected defaults: %s, %s, %v", linkType, scope, err)
+	}
+	if _, _, err := ParseShareOptions("edit", "everyone"); err == nil {
+		t.Error("expected an error for an invalid scope")
+	}
+	if shareRole("edit") != "write" || shareRole("view") != "read" {
+		t.Error("unexpected share roles")
+	}
+}
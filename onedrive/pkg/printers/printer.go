@@ -0,0 +1,75 @@
+package printers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gptscript-ai/tools/common/locale"
+	"github.com/gptscript-ai/tools/onedrive/pkg/util"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+func DriveToString(drive models.Driveable) string {
+	var (
+		result strings.Builder
+		loc    = locale.FromEnv()
+	)
+
+	result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Name"), util.Deref(drive.GetName())))
+	result.WriteString(fmt.Sprintf("  %s: %s\n", loc.T("Drive ID"), util.Deref(drive.GetId())))
+	result.WriteString(fmt.Sprintf("  %s: %s\n", loc.T("Type"), util.Deref(drive.GetDriveType())))
+	if owner := drive.GetOwner(); owner != nil {
+		if name := identityName(owner); name != "" {
+			result.WriteString(fmt.Sprintf("  %s: %s\n", loc.T("Owner"), name))
+		}
+	}
+	if drive.GetWebUrl() != nil {
+		result.WriteString(fmt.Sprintf("  %s: %s\n", loc.T("Link"), util.Deref(drive.GetWebUrl())))
+	}
+
+	return result.String()
+}
+
+func ItemToString(item models.DriveItemable) string {
+	var (
+		result strings.Builder
+		loc    = locale.FromEnv()
+	)
+
+	result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Name"), util.Deref(item.GetName())))
+	result.WriteString(fmt.Sprintf("  %s: %s\n", loc.T("ID"), util.Deref(item.GetId())))
+	switch {
+	case item.GetFolder() != nil:
+		result.WriteString(fmt.Sprintf("  %s: %s\n", loc.T("Type"), loc.T("Folder")))
+	case item.GetFile() != nil && item.GetFile().GetMimeType() != nil:
+		result.WriteString(fmt.Sprintf("  %s: %s\n", loc.T("Type"), util.Deref(item.GetFile().GetMimeType())))
+	}
+	if item.GetFolder() == nil && item.GetSize() != nil {
+		result.WriteString(fmt.Sprintf("  %s: %s %s\n", loc.T("Size"), loc.Int(util.Deref(item.GetSize())), loc.T("bytes")))
+	}
+	if modified := item.GetLastModifiedDateTime(); modified != nil {
+		result.WriteString(fmt.Sprintf("  %s: %s\n", loc.T("Last modified"), loc.DateTime(*modified)))
+	}
+	if parent := item.GetParentReference(); parent != nil {
+		if parent.GetId() != nil {
+			result.WriteString(fmt.Sprintf("  %s: %s\n", loc.T("Parent folder ID"), util.Deref(parent.GetId())))
+		}
+		if parent.GetDriveId() != nil {
+			result.WriteString(fmt.Sprintf("  %s: %s\n", loc.T("Drive ID"), util.Deref(parent.GetDriveId())))
+		}
+	}
+	if item.GetWebUrl() != nil {
+		result.WriteString(fmt.Sprintf("  %s: %s\n", loc.T("Link"), util.Deref(item.GetWebUrl())))
+	}
+
+	return result.String()
+}
+
+func identityName(identity models.IdentitySetable) string {
+	for _, i := range []models.Identityable{identity.GetUser(), identity.GetGroup(), identity.GetApplication()} {
+		if i != nil && i.GetDisplayName() != nil {
+			return *i.GetDisplayName()
+		}
+	}
+	return ""
+}
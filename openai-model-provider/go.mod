@@ -2,4 +2,7 @@ module github.com/obot-platform/tools/openai-model-provider
 
 go 1.23.4
 
-require github.com/gptscript-ai/chat-completion-client v0.0.0-20241127005108-02b41e1cd02e
+require (
+	github.com/gptscript-ai/chat-completion-client v0.0.0-20241127005108-02b41e1cd02e
+	gopkg.in/yaml.v3 v3.0.1
+)
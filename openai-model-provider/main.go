@@ -19,7 +19,13 @@ func main() {
 		port = "8000"
 	}
 
-	if err := server.Run(apiKey, port); err != nil {
+	rules, err := server.LoadRules(os.Getenv("OBOT_OPENAI_MODEL_PROVIDER_RULES_FILE"), os.Getenv("OBOT_OPENAI_MODEL_PROVIDER_RULES"))
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	if err := server.Run(apiKey, port, rules); err != nil {
 		panic(err)
 	}
 }
@@ -0,0 +1,230 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rules rewrite the requests before they are forwarded upstream, so policies (e.g. which models may be used or a
+// common system prompt) can be applied in one place instead of in every agent.
+//
+// Example:
+//
+//	rules:
+//	- match:
+//	    model: gpt-4-*
+//	  model: gpt-4o
+//	- systemPrompt:
+//	    text: Never share credentials.
+//	    position: prefix
+//	  defaults:
+//	    temperature: 0.2
+//	  overrides:
+//	    user: obot
+type Rules struct {
+	Rules []Rule `json:"rules"`
+}
+
+type Rule struct {
+	Match RuleMatch `json:"match"`
+	// Model replaces the model of the request
+	Model string `json:"model,omitempty"`
+	// SystemPrompt is added to the system message of chat completion requests
+	SystemPrompt *SystemPromptRule `json:"systemPrompt,omitempty"`
+	// Defaults are set on the request if the request doesn't set them
+	Defaults map[string]any `json:"defaults,omitempty"`
+	// Overrides are always set on the request
+	Overrides map[string]any `json:"overrides,omitempty"`
+}
+
+type RuleMatch struct {
+	// Model is a glob pattern matched against the model of the request, e.g. gpt-4-* or org/*, empty matches all models.
+	// A * matches any characters including /, a ? matches a single character.
+	Model string `json:"model,omitempty"`
+	// Path is a glob pattern matched against the request path, e.g. /v1/chat/*, empty matches all paths
+	Path string `json:"path,omitempty"`
+}
+
+type SystemPromptRule struct {
+	Text string `json:"text"`
+	// Position is prefix (default), suffix or replace. If the request has no system message, one is inserted.
+	Position string `json:"position,omitempty"`
+}
+
+// LoadRules reads the rules from the YAML file, or parses them from the YAML content if no file is set.
+// No rules are applied if neither is set.
+func LoadRules(file, content string) (*Rules, error) {
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read rules file %q: %w", file, err)
+		}
+		content = string(data)
+	}
+
+	var rules Rules
+	if content == "" {
+		return &rules, nil
+	}
+
+	// Decode to a generic value first, so the json tags are used and numbers in defaults keep their type
+	var raw any
+	if err := yaml.Unmarshal([]byte(content), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse rules: %w", err)
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse rules: %w", err)
+	}
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse rules: %w", err)
+	}
+
+	for i, rule := range rules.Rules {
+		if rule.SystemPrompt != nil {
+			switch rule.SystemPrompt.Position {
+			case "", "prefix", "suffix", "replace":
+			default:
+				return nil, fmt.Errorf("invalid system prompt position %q in rule %d, must be prefix, suffix or replace", rule.SystemPrompt.Position, i+1)
+			}
+		}
+	}
+
+	return &rules, nil
+}
+
+// Apply rewrites the JSON request body. The rules are applied in order, a rule matches the model as rewritten by the
+// previous rules. It returns false if no rule matched.
+func (r *Rules) Apply(requestPath string, body map[string]any) bool {
+	var applied bool
+	for _, rule := range r.Rules {
+		model, _ := body["model"].(string)
+		if !rule.matches(requestPath, model) {
+			continue
+		}
+		applied = true
+
+		if rule.Model != "" {
+			body["model"] = rule.Model
+		}
+		for k, v := range rule.Defaults {
+			if _, ok := body[k]; !ok {
+				body[k] = v
+			}
+		}
+		for k, v := range rule.Overrides {
+			body[k] = v
+		}
+		if rule.SystemPrompt != nil {
+			if messages, ok := body["messages"].([]any); ok {
+				body["messages"] = rule.SystemPrompt.apply(messages)
+			}
+		}
+	}
+	return applied
+}
+
+func (r Rule) matches(requestPath, model string) bool {
+	return (r.Match.Path == "" || matchGlob(r.Match.Path, requestPath)) &&
+		(r.Match.Model == "" || matchGlob(r.Match.Model, model))
+}
+
+// matchGlob reports whether s matches the pattern, in which * matches any (possibly empty) sequence of characters and
+// ? matches a single character. Unlike path.Match, * also matches /, as model names like org/model contain slashes.
+func matchGlob(pattern, s string) bool {
+	p, str := []rune(pattern), []rune(s)
+	// On a mismatch, backtrack to the last * and let it match one more character
+	i, j, star, next := 0, 0, -1, 0
+	for j < len(str) {
+		switch {
+		case i < len(p) && p[i] == '*':
+			star, next = i, j
+			i++
+		case i < len(p) && (p[i] == '?' || p[i] == str[j]):
+			i++
+			j++
+		case star >= 0:
+			next++
+			i, j = star+1, next
+		default:
+			return false
+		}
+	}
+	for i < len(p) && p[i] == '*' {
+		i++
+	}
+	return i == len(p)
+}
+
+func (s *SystemPromptRule) apply(messages []any) []any {
+	for _, m := range messages {
+		message, ok := m.(map[string]any)
+		if !ok || (message["role"] != "system" && message["role"] != "developer") {
+			continue
+		}
+
+		// Content is either a string or a list of parts, parts are left alone except for replace
+		content, isString := message["content"].(string)
+		switch {
+		case s.Position == "replace":
+			message["content"] = s.Text
+		case !isString:
+			parts, _ := message["content"].([]any)
+			part := map[string]any{"type": "text", "text": s.Text}
+			if s.Position == "suffix" {
+				message["content"] = append(parts, part)
+			} else {
+				message["content"] = append([]any{part}, parts...)
+			}
+		case s.Position == "suffix":
+			message["content"] = content + "\n\n" + s.Text
+		default:
+			message["content"] = s.Text + "\n\n" + content
+		}
+		return messages
+	}
+
+	return append([]any{map[string]any{"role": "system", "content": s.Text}}, messages...)
+}
+
+// applyRules rewrites the body of JSON requests according to the rules before passing them to the next handler.
+// Requests that can't be parsed are passed on unchanged, the upstream API reports the error.
+func (s *server) applyRules(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if len(s.rules.Rules) == 0 || req.Method != http.MethodPost || req.Body == nil {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		data, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to read request: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		var body map[string]any
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&body); err == nil && s.rules.Apply(req.URL.Path, body) {
+			if rewritten, err := json.Marshal(body); err == nil {
+				data = rewritten
+			} else {
+				log.Printf("failed to apply rules to request %s: %v", req.URL.Path, err)
+			}
+		}
+
+		req.Body = io.NopCloser(bytes.NewReader(data))
+		req.ContentLength = int64(len(data))
+		req.Header.Set("Content-Length", strconv.Itoa(len(data)))
+		next.ServeHTTP(w, req)
+	})
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"gpt-4o", "gpt-4o", true},
+		{"gpt-4o", "gpt-4o-mini", false},
+		{"gpt-4*", "gpt-4o-mini", true},
+		{"gpt-4*", "gpt-3.5-turbo", false},
+		{"*", "", true},
+		{"*", "org/model", true},
+		{"org/*", "org/model", true},
+		{"org/*", "org/team/model", true},
+		{"org/*", "other/model", false},
+		{"*/llama-*", "meta/llama-3.1-70b", true},
+		{"*/llama-*", "llama-3.1-70b", false},
+		{"*-mini", "gpt-4o-mini", true},
+		{"*-mini", "gpt-4o-mini-2024", false},
+		{"gpt-?o", "gpt-4o", true},
+		{"gpt-?o", "gpt-40o", false},
+		{"a*b*c", "axxbyyc", true},
+		{"a*b*c", "axxbyy", false},
+		{"/v1/chat/*", "/v1/chat/completions", true},
+		{"/v1/*", "/v1/chat/completions", true},
+		{"/v1/embeddings", "/v1/chat/completions", false},
+		{"", "", true},
+		{"", "gpt-4o", false},
+	} {
+		if got := matchGlob(tc.pattern, tc.s); got != tc.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tc.pattern, tc.s, got, tc.want)
+		}
+	}
+}
+
+func TestRulesApply(t *testing.T) {
+	rules, err := LoadRules("", `
+rules:
+- match:
+    model: org/*
+  model: gpt-4o
+- match:
+    path: /v1/chat/*
+  systemPrompt:
+    text: Never share credentials.
+  defaults:
+    temperature: 0.2
+  overrides:
+    user: obot
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name, path, body string
+		applied          bool
+		want             string
+	}{
+		{
+			name:    "model with slash",
+			path:    "/v1/embeddings",
+			body:    `{"model":"org/team/model","input":"hi"}`,
+			applied: true,
+			want:    `{"model":"gpt-4o","input":"hi"}`,
+		},
+		{
+			name: "no match",
+			path: "/v1/embeddings",
+			body: `{"model":"gpt-4o","input":"hi"}`,
+			want: `{"model":"gpt-4o","input":"hi"}`,
+		},
+		{
+			name:    "chat completion",
+			path:    "/v1/chat/completions",
+			body:    `{"model":"gpt-4o","temperature":1,"messages":[{"role":"system","content":"Be brief."},{"role":"user","content":"hi"}]}`,
+			applied: true,
+			want:    `{"model":"gpt-4o","temperature":1,"user":"obot","messages":[{"role":"system","content":"Never share credentials.\n\nBe brief."},{"role":"user","content":"hi"}]}`,
+		},
+		{
+			name:    "chat completion without system message",
+			path:    "/v1/chat/completions",
+			body:    `{"model":"org/model","messages":[{"role":"user","content":"hi"}]}`,
+			applied: true,
+			want:    `{"model":"gpt-4o","temperature":0.2,"user":"obot","messages":[{"role":"system","content":"Never share credentials."},{"role":"user","content":"hi"}]}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var body, want map[string]any
+			if err := json.Unmarshal([]byte(tc.body), &body); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tc.want), &want); err != nil {
+				t.Fatal(err)
+			}
+
+			if applied := rules.Apply(tc.path, body); applied != tc.applied {
+				t.Errorf("Apply() = %v, want %v", applied, tc.applied)
+			}
+			if !reflect.DeepEqual(body, want) {
+				got, _ := json.Marshal(body)
+				t.Errorf("got body %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
@@ -14,12 +14,13 @@ import (
 	"github.com/gptscript-ai/chat-completion-client"
 )
 
-func Run(apiKey, port string) error {
+func Run(apiKey, port string, rules *Rules) error {
 	mux := http.NewServeMux()
 
 	s := &server{
 		apiKey: apiKey,
 		port:   port,
+		rules:  rules,
 	}
 
 	mux.HandleFunc("/{$}", s.healthz)
@@ -27,9 +28,9 @@ func Run(apiKey, port string) error {
 		Director:       s.proxy,
 		ModifyResponse: s.rewriteModelsResponse,
 	})
-	mux.Handle("/{path...}", &httputil.ReverseProxy{
+	mux.Handle("/{path...}", s.applyRules(&httputil.ReverseProxy{
 		Director: s.proxy,
-	})
+	}))
 
 	httpServer := &http.Server{
 		Addr:    "127.0.0.1:" + port,
@@ -45,6 +46,7 @@ func Run(apiKey, port string) error {
 
 type server struct {
 	apiKey, port string
+	rules        *Rules
 }
 
 func (s *server) healthz(w http.ResponseWriter, _ *http.Request) {
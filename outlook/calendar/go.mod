@@ -3,10 +3,11 @@ module github.com/gptscript-ai/tools/outlook/calendar
 go 1.23.0
 
 replace github.com/gptscript-ai/tools/outlook/common => ../common
+replace github.com/gptscript-ai/tools/common => ../../common
 
 require (
-	github.com/Azure/azure-sdk-for-go/sdk/azcore v1.16.0
 	github.com/gptscript-ai/go-gptscript v0.9.6-0.20241106212914-ba040ce8f47b
+	github.com/gptscript-ai/tools/common v0.0.0-00010101000000-000000000000
 	github.com/gptscript-ai/tools/outlook/common v0.0.0-20241029131940-7d95a94b38c2
 	github.com/jaytaylor/html2text v0.0.0-20230321000545-74c2419ad056
 	github.com/microsoft/kiota-abstractions-go v1.7.0
@@ -15,6 +16,7 @@ require (
 )
 
 require (
+	github.com/Azure/azure-sdk-for-go/sdk/azcore v1.16.0 // indirect
 	github.com/Azure/azure-sdk-for-go/sdk/internal v1.10.0 // indirect
 	github.com/cjlapao/common-go v0.0.41 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"os"
 	"strconv"
@@ -10,19 +11,31 @@ import (
 
 	"github.com/gptscript-ai/tools/outlook/calendar/pkg/commands"
 	"github.com/gptscript-ai/tools/outlook/calendar/pkg/graph"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	"github.com/gptscript-ai/tools/outlook/common/auth"
+	"github.com/gptscript-ai/tools/outlook/common/fields"
+	"github.com/gptscript-ai/tools/outlook/common/pagination"
 )
 
 func main() {
-	if len(os.Args) != 2 {
-		fmt.Println("Usage: calendar <command>")
+	// The user is only used with app-only auth, when there is no signed-in user
+	user := flag.String("user", "", "user principal name of the user to act on with app-only auth")
+	pageSize := flag.Int("page-size", 0, "number of items requested from Graph per page")
+	fieldSet := flag.String("fields", "", "field set (summary, default, full) or comma-separated properties of listed events")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		fmt.Println("Usage: calendar [--user <upn>] [--page-size <n>] [--fields <fields>] <command>")
 		os.Exit(1)
 	}
+	auth.SetUser(*user)
+	pagination.SetPageSize(*pageSize)
+	fields.Set(*fieldSet)
 
-	command := os.Args[1]
+	command := flag.Arg(0)
 
 	switch command {
 	case "listCalendars":
-		if err := commands.ListCalendars(context.Background()); err != nil {
+		if err := commands.ListCalendars(context.Background(), strings.Split(os.Getenv("SHARED_WITH"), ",")); err != nil {
 			fmt.Println(err)
 			os.Exit(1)
 		}
@@ -30,7 +43,13 @@ func main() {
 		now := time.Now()
 		start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 		end := time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 0, now.Location())
-		if err := commands.ListEvents(context.Background(), start, end); err != nil {
+		selects, err := graph.EventFields.FromEnv(graph.EventRequiredFields...)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if err := commands.ListEvents(context.Background(), start, end, strings.Split(os.Getenv("SHARED_WITH"), ","), optionalList("CATEGORIES"), selects); err != nil {
 			fmt.Println(err)
 			os.Exit(1)
 		}
@@ -41,7 +60,68 @@ func main() {
 			os.Exit(1)
 		}
 
-		if err := commands.ListEvents(context.Background(), start, end); err != nil {
+		selects, err := graph.EventFields.FromEnv(graph.EventRequiredFields...)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if err := commands.ListEvents(context.Background(), start, end, strings.Split(os.Getenv("SHARED_WITH"), ","), optionalList("CATEGORIES"), selects); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "calendarView":
+		start, end, err := parseStartEnd(os.Getenv("START"), os.Getenv("END"), false)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		calendarID, owner := os.Getenv("CALENDAR_ID"), graph.OwnerType(os.Getenv("OWNER_TYPE"))
+		if calendarID != "" && owner == "" {
+			fmt.Println("Owner type is required")
+			os.Exit(1)
+		}
+
+		selects, err := graph.EventFields.FromEnv(graph.EventRequiredFields...)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if err := commands.CalendarView(context.Background(), calendarID, owner, start, end, strings.TrimSpace(os.Getenv("TIMEZONE")), optionalList("CATEGORIES"), selects); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "getCalendarChanges":
+		start, end, err := parseStartEnd(os.Getenv("START"), os.Getenv("END"), true)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if start.IsZero() {
+			start = time.Now()
+		}
+		if end.IsZero() {
+			end = start.AddDate(0, 0, 30)
+		}
+
+		var reset bool
+		if v := os.Getenv("RESET"); v != "" {
+			reset, err = strconv.ParseBool(v)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+
+		calendarID, owner := os.Getenv("CALENDAR_ID"), graph.OwnerType(os.Getenv("OWNER_TYPE"))
+		if calendarID != "" && owner == "" {
+			fmt.Println("Owner type is required")
+			os.Exit(1)
+		}
+
+		if err := commands.GetCalendarChanges(context.Background(), calendarID, owner, start, end, reset); err != nil {
 			fmt.Println(err)
 			os.Exit(1)
 		}
@@ -50,6 +130,26 @@ func main() {
 			fmt.Println(err)
 			os.Exit(1)
 		}
+	case "listEventAttachments":
+		if err := commands.ListEventAttachments(context.Background(), os.Getenv("EVENT_ID"), os.Getenv("CALENDAR_ID"), graph.OwnerType(os.Getenv("OWNER_TYPE"))); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "downloadEventAttachment":
+		if err := commands.DownloadEventAttachment(context.Background(), os.Getenv("EVENT_ID"), os.Getenv("CALENDAR_ID"), graph.OwnerType(os.Getenv("OWNER_TYPE")), os.Getenv("ATTACHMENT_ID"), os.Getenv("FILE_NAME")); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "addEventAttachments":
+		if err := commands.AddEventAttachments(context.Background(), os.Getenv("EVENT_ID"), os.Getenv("CALENDAR_ID"), graph.OwnerType(os.Getenv("OWNER_TYPE")), os.Getenv("FILES")); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "getEventResponses":
+		if err := commands.GetEventResponses(context.Background(), os.Getenv("EVENT_ID"), os.Getenv("CALENDAR_ID"), graph.OwnerType(os.Getenv("OWNER_TYPE"))); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
 	case "getEventAttachments":
 		if err := commands.GetEventAttachments(context.Background(), os.Getenv("EVENT_ID"), os.Getenv("CALENDAR_ID"), graph.OwnerType(os.Getenv("OWNER_TYPE"))); err != nil {
 			fmt.Println(err)
@@ -63,6 +163,10 @@ func main() {
 			Body:       os.Getenv("BODY"),
 			Recurrence: os.Getenv("RECURRENCE"),
 		}
+		if v := os.Getenv("ROOMS"); v != "" {
+			info.Rooms = strings.Split(v, ",")
+		}
+		info.Categories = optionalList("CATEGORIES")
 
 		// Unset the BODY variable so that it does not mess up writing files to the workspace later on.
 		if err := os.Unsetenv("BODY"); err != nil {
@@ -77,6 +181,13 @@ func main() {
 		}
 
 		info.IsOnline = isOnline
+		info.OnlineMeetingProvider = os.Getenv("ONLINE_MEETING_PROVIDER")
+
+		info.IsReminderOn, info.ReminderMinutesBeforeStart, err = reminderFromEnv()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
 
 		if id := os.Getenv("CALENDAR_ID"); id != "" {
 			info.ID = id
@@ -97,7 +208,60 @@ func main() {
 		info.Start = start
 		info.End = end
 
-		if err := commands.CreateEvent(context.Background(), info); err != nil {
+		var force, checkAttendees bool
+		if v := os.Getenv("FORCE"); v != "" {
+			force, err = strconv.ParseBool(v)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+		if v := os.Getenv("CHECK_ATTENDEES"); v != "" {
+			checkAttendees, err = strconv.ParseBool(v)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+
+		if err := commands.CreateEvent(context.Background(), info, force, checkAttendees); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "importEvents":
+		calendarID, owner := os.Getenv("CALENDAR_ID"), graph.OwnerType(os.Getenv("OWNER_TYPE"))
+		if calendarID != "" && owner == "" {
+			fmt.Println("Owner type is required")
+			os.Exit(1)
+		}
+
+		var dryRun bool
+		if v := os.Getenv("DRY_RUN"); v != "" {
+			var err error
+			dryRun, err = strconv.ParseBool(v)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+
+		if err := commands.ImportEvents(context.Background(), os.Getenv("FILE"), calendarID, owner, os.Getenv("TIMEZONE"), dryRun); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "exportEvents":
+		var eventIDs []string
+		if v := os.Getenv("EVENT_IDS"); v != "" {
+			eventIDs = strings.Split(v, ",")
+		}
+
+		start, end, err := parseStartEnd(os.Getenv("START"), os.Getenv("END"), len(eventIDs) > 0)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if err := commands.ExportEvents(context.Background(), eventIDs, os.Getenv("CALENDAR_ID"), graph.OwnerType(os.Getenv("OWNER_TYPE")), start, end, os.Getenv("FILE")); err != nil {
 			fmt.Println(err)
 			os.Exit(1)
 		}
@@ -111,6 +275,140 @@ func main() {
 			fmt.Println(err)
 			os.Exit(1)
 		}
+	case "listEventOccurrences":
+		start, end, err := parseStartEnd(os.Getenv("START"), os.Getenv("END"), false)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if err := commands.ListEventOccurrences(context.Background(), os.Getenv("EVENT_ID"), os.Getenv("CALENDAR_ID"), graph.OwnerType(os.Getenv("OWNER_TYPE")), start, end); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "updateEvent":
+		info := graph.UpdateEventInfo{
+			Subject:  optionalEnv("SUBJECT"),
+			Location: optionalEnv("LOCATION"),
+			Body:     optionalEnv("BODY"),
+		}
+
+		// Unset the BODY variable so that it does not mess up writing files to the workspace later on.
+		if err := os.Unsetenv("BODY"); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		start, end, err := parseStartEnd(os.Getenv("START"), os.Getenv("END"), true)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if !start.IsZero() {
+			info.Start = &start
+		}
+		if !end.IsZero() {
+			info.End = &end
+		}
+
+		info.IsReminderOn, info.ReminderMinutesBeforeStart, err = reminderFromEnv()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if categories := optionalEnv("CATEGORIES"); categories != nil {
+			// An empty list removes all categories
+			info.Categories = []string{}
+			if !strings.EqualFold(strings.TrimSpace(*categories), "none") {
+				info.Categories = strings.Split(*categories, ",")
+			}
+		}
+
+		if err := commands.UpdateEvent(context.Background(), os.Getenv("EVENT_ID"), os.Getenv("CALENDAR_ID"), graph.OwnerType(os.Getenv("OWNER_TYPE")), scopeFromEnv(), info); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "cancelEvent":
+		if err := commands.CancelEvent(context.Background(), os.Getenv("EVENT_ID"), os.Getenv("CALENDAR_ID"), graph.OwnerType(os.Getenv("OWNER_TYPE")), scopeFromEnv(), os.Getenv("COMMENT")); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "getSchedules":
+		start, end, err := parseStartEnd(os.Getenv("START"), os.Getenv("END"), false)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if err := commands.GetSchedules(context.Background(), strings.Split(os.Getenv("ATTENDEES"), ","), start, end); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "getWorkingHours":
+		if err := commands.GetWorkingHours(context.Background(), optionalList("ATTENDEES")); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "suggestSlots":
+		start, end, err := parseStartEnd(os.Getenv("START"), os.Getenv("END"), false)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		duration, err := strconv.Atoi(os.Getenv("DURATION"))
+		if err != nil {
+			fmt.Printf("failed to parse duration: %v\n", err)
+			os.Exit(1)
+		}
+
+		if err := commands.SuggestSlots(context.Background(), optionalList("ATTENDEES"), start, end, time.Duration(duration)*time.Minute); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "findMeetingTimes":
+		start, end, err := parseStartEnd(os.Getenv("START"), os.Getenv("END"), false)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		duration, err := strconv.Atoi(os.Getenv("DURATION"))
+		if err != nil {
+			fmt.Printf("failed to parse duration: %v\n", err)
+			os.Exit(1)
+		}
+
+		info := graph.FindMeetingTimesInfo{
+			Attendees:      strings.Split(os.Getenv("ATTENDEES"), ","),
+			Duration:       time.Duration(duration) * time.Minute,
+			Start:          start,
+			End:            end,
+			ActivityDomain: os.Getenv("ACTIVITY_DOMAIN"),
+		}
+		if v := os.Getenv("OPTIONAL_ATTENDEES"); v != "" {
+			info.OptionalAttendees = strings.Split(v, ",")
+		}
+		if v := os.Getenv("MAX_CANDIDATES"); v != "" {
+			info.MaxCandidates, err = strconv.Atoi(v)
+			if err != nil {
+				fmt.Printf("failed to parse max candidates: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if v := os.Getenv("MINIMUM_ATTENDEE_PERCENTAGE"); v != "" {
+			info.MinimumAttendeePercentage, err = strconv.Atoi(v)
+			if err != nil {
+				fmt.Printf("failed to parse minimum attendee percentage: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		if err := commands.FindMeetingTimes(context.Background(), info); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
 	case "searchEvents":
 		start, end, err := parseStartEnd(os.Getenv("START"), os.Getenv("END"), false)
 		if err != nil {
@@ -118,12 +416,127 @@ func main() {
 			os.Exit(1)
 		}
 
-		if err := commands.SearchEvents(context.Background(), os.Getenv("QUERY"), start, end); err != nil {
+		limit := 100
+		if v := os.Getenv("LIMIT"); v != "" {
+			limit, err = strconv.Atoi(v)
+			if err != nil {
+				fmt.Printf("failed to parse limit: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		selects, err := graph.EventFields.FromEnv(graph.EventRequiredFields...)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		search := graph.EventSearch{
+			Query:     os.Getenv("QUERY"),
+			Organizer: os.Getenv("ORGANIZER"),
+			Attendee:  os.Getenv("ATTENDEE"),
+			Start:     start,
+			End:       end,
+		}
+		if err := commands.SearchEvents(context.Background(), search, strings.Split(os.Getenv("SHARED_WITH"), ","), limit, selects); err != nil {
 			fmt.Println(err)
 			os.Exit(1)
 		}
 	case "respondToEvent":
-		if err := commands.RespondToEvent(context.Background(), os.Getenv("EVENT_ID"), os.Getenv("CALENDAR_ID"), graph.OwnerType(os.Getenv("OWNER_TYPE")), os.Getenv("RESPONSE")); err != nil {
+		sendResponse := true
+		if v := os.Getenv("SEND_RESPONSE"); v != "" {
+			var err error
+			sendResponse, err = strconv.ParseBool(v)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+
+		if err := commands.RespondToEvent(context.Background(), os.Getenv("EVENT_ID"), os.Getenv("CALENDAR_ID"), graph.OwnerType(os.Getenv("OWNER_TYPE")), os.Getenv("RESPONSE"), os.Getenv("COMMENT"), sendResponse); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "bulkRespondToEvents":
+		sendResponse := true
+		if v := os.Getenv("SEND_RESPONSE"); v != "" {
+			var err error
+			sendResponse, err = strconv.ParseBool(v)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+
+		if err := commands.BulkRespondToEvents(context.Background(), os.Getenv("EVENT_IDS"), os.Getenv("RESPONSE"), os.Getenv("COMMENT"), sendResponse); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "listUpcomingReminders":
+		start, end, err := parseStartEnd(os.Getenv("START"), os.Getenv("END"), true)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if start.IsZero() {
+			start = time.Now()
+		}
+		if end.IsZero() {
+			end = start.Add(24 * time.Hour)
+		}
+
+		if err := commands.ListUpcomingReminders(context.Background(), start, end); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "listRoomLists":
+		if err := commands.ListRoomLists(context.Background()); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "findRooms":
+		start, end, err := parseStartEnd(os.Getenv("START"), os.Getenv("END"), true)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if start.IsZero() != end.IsZero() {
+			fmt.Println("Both start and end are required to check the availability of the rooms")
+			os.Exit(1)
+		}
+
+		filter := graph.RoomFilter{
+			Building: os.Getenv("BUILDING"),
+			Query:    os.Getenv("QUERY"),
+		}
+		if v := os.Getenv("MIN_CAPACITY"); v != "" {
+			filter.MinCapacity, err = strconv.Atoi(v)
+			if err != nil {
+				fmt.Println("Invalid min_capacity:", err)
+				os.Exit(1)
+			}
+		}
+
+		var onlyAvailable bool
+		if v := os.Getenv("ONLY_AVAILABLE"); v != "" {
+			onlyAvailable, err = strconv.ParseBool(v)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+
+		if err := commands.FindRooms(context.Background(), os.Getenv("ROOM_LIST"), filter, start, end, onlyAvailable); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "addRoomToEvent":
+		if err := commands.AddRoomToEvent(context.Background(), os.Getenv("EVENT_ID"), os.Getenv("CALENDAR_ID"), graph.OwnerType(os.Getenv("OWNER_TYPE")), os.Getenv("ROOM_EMAIL")); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "listCategories":
+		if err := commands.ListCategories(context.Background()); err != nil {
 			fmt.Println(err)
 			os.Exit(1)
 		}
@@ -165,3 +578,50 @@ func parseStartEnd(start, end string, optional bool) (time.Time, time.Time, erro
 
 	return startTime, endTime, nil
 }
+
+// optionalEnv returns the value of the environment variable, or nil if it is not set or empty.
+func optionalEnv(name string) *string {
+	if v := os.Getenv(name); v != "" {
+		return &v
+	}
+	return nil
+}
+
+// optionalList returns the comma-separated list of the environment variable, or nil if it is not set or empty.
+func optionalList(name string) []string {
+	if v := os.Getenv(name); v != "" {
+		return strings.Split(v, ",")
+	}
+	return nil
+}
+
+// reminderFromEnv returns the reminder settings of an event, which are nil if they are not set.
+func reminderFromEnv() (*bool, *int32, error) {
+	var (
+		isReminderOn *bool
+		minutes      *int32
+	)
+	if v := os.Getenv("IS_REMINDER_ON"); v != "" {
+		on, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid is_reminder_on %q: %w", v, err)
+		}
+		isReminderOn = &on
+	}
+	if v := os.Getenv("REMINDER_MINUTES_BEFORE_START"); v != "" {
+		m, err := strconv.ParseInt(v, 10, 32)
+		if err != nil || m < 0 {
+			return nil, nil, fmt.Errorf("invalid reminder_minutes_before_start %q, it has to be a number of minutes", v)
+		}
+		minutes = util.Ptr(int32(m))
+	}
+	return isReminderOn, minutes, nil
+}
+
+// scopeFromEnv returns the scope of a change to a recurring event, which defaults to a single occurrence.
+func scopeFromEnv() graph.Scope {
+	if scope := os.Getenv("SCOPE"); scope != "" {
+		return graph.Scope(strings.ToLower(strings.TrimSpace(scope)))
+	}
+	return graph.ScopeOccurrence
+}
@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/common/guard"
+	"github.com/gptscript-ai/tools/common/locale"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/graph"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/printers"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	"github.com/gptscript-ai/tools/outlook/common/id"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// CalendarView lists the events of a calendar (the default calendar if calendarID is unset) in the time frame, with
+// recurring events expanded into their occurrences. The times are shown in the time zone, which defaults to the
+// user's time zone from the mailbox settings. If categories are set, only the events with at least one of them are
+// listed. The events have the selected properties, see graph.EventFields.
+func CalendarView(ctx context.Context, calendarID string, owner graph.OwnerType, start, end time.Time, timeZone string, categories, selects []string) error {
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	if timeZone == "" {
+		timeZone, err = graph.GetMailboxTimeZone(ctx, c)
+		if err != nil {
+			return err
+		}
+		if timeZone == "" {
+			timeZone = "UTC"
+		}
+	}
+	// Graph accepts the same IANA and Windows time zone names
+	if _, err := graph.LoadLocation(timeZone); err != nil {
+		return fmt.Errorf("unknown time zone %q, use an IANA time zone name like Europe/Berlin", timeZone)
+	}
+
+	var calendar graph.CalendarInfo
+	if calendarID != "" {
+		trueCalendarID, err := id.GetOutlookID(ctx, calendarID)
+		if err != nil {
+			return fmt.Errorf("failed to get Outlook Calendar ID: %w", err)
+		}
+		calendar, err = graph.GetCalendar(ctx, c, owner, trueCalendarID)
+		if err != nil {
+			return err
+		}
+	} else {
+		calendar, err = graph.GetDefaultCalendar(ctx, c)
+		if err != nil {
+			return err
+		}
+	}
+
+	events, err := graph.ListCalendarView(ctx, c, calendar.ID, calendar.Owner, &start, &end, timeZone, selects)
+	if err != nil {
+		return err
+	}
+	events = graph.FilterEventsByCategories(events, categories)
+
+	if len(events) == 0 {
+		fmt.Println(locale.FromEnv().T("No events found"))
+		return nil
+	}
+
+	translatedCalendarIDs, err := id.SetOutlookIDs(ctx, []string{calendar.ID})
+	if err != nil {
+		return fmt.Errorf("failed to set calendar IDs: %w", err)
+	}
+	calendar.ID = translatedCalendarIDs[calendar.ID]
+
+	translatedEventIDs, err := id.SetOutlookIDs(ctx, util.Map(events, func(event models.Eventable) string {
+		return util.Deref(event.GetId())
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to set event IDs: %w", err)
+	}
+
+	gptscriptClient, err := gptscript.NewGPTScript()
+	if err != nil {
+		return fmt.Errorf("failed to create GPTScript client: %w", err)
+	}
+
+	elements := make([]gptscript.DatasetElement, 0, len(events))
+	for _, event := range events {
+		event.SetId(util.Ptr(translatedEventIDs[util.Deref(event.GetId())]))
+		elements = append(elements, gptscript.DatasetElement{
+			DatasetElementMeta: gptscript.DatasetElementMeta{
+				Name:        util.Deref(event.GetId()) + "_" + util.Deref(event.GetSubject()),
+				Description: util.Deref(event.GetBodyPreview()),
+			},
+			Contents: printers.EventToString(ctx, c, calendar, event),
+		})
+	}
+
+	return guard.PrintElements(ctx, gptscriptClient, elements, gptscript.DatasetOptions{
+		Name:        "calendar_view",
+		Description: "Outlook Calendar events with times in " + timeZone,
+	}, "events")
+}
@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/graph"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// ListCategories prints the categories that can be applied to events, with their colors.
+func ListCategories(ctx context.Context) error {
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	categories, err := graph.ListCategories(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	if len(categories) == 0 {
+		fmt.Println("No categories found")
+		return nil
+	}
+
+	for _, category := range categories {
+		fmt.Printf("%s (color: %s)\n", util.Deref(category.GetDisplayName()), categoryColor(category.GetColor()))
+	}
+	return nil
+}
+
+// masterCategories returns the names of the master categories with the given names. Only master categories can be
+// applied, so that events are labelled (and colored) consistently.
+func masterCategories(ctx context.Context, c *msgraphsdkgo.GraphServiceClient, names []string) ([]string, error) {
+	categories, err := graph.ListCategories(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	return graph.MatchCategories(graph.CategoryNames(categories), names)
+}
+
+// categoryColors are the colors of the presets, as shown by Outlook
+var categoryColors = []string{
+	"red", "orange", "brown", "yellow", "green", "teal", "olive", "blue", "purple", "cranberry", "steel", "dark steel",
+	"gray", "dark gray", "black", "dark red", "dark orange", "dark brown", "dark yellow", "dark green", "dark teal",
+	"dark olive", "dark blue", "dark purple", "dark cranberry",
+}
+
+func categoryColor(color *models.CategoryColor) string {
+	if color == nil || *color < models.PRESET0_CATEGORYCOLOR || int(*color-models.PRESET0_CATEGORYCOLOR) >= len(categoryColors) {
+		return "none"
+	}
+	return categoryColors[*color-models.PRESET0_CATEGORYCOLOR]
+}
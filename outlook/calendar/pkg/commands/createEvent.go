@@ -2,6 +2,7 @@ package commands
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"github.com/gptscript-ai/tools/outlook/calendar/pkg/client"
@@ -11,7 +12,9 @@ import (
 	"github.com/gptscript-ai/tools/outlook/common/id"
 )
 
-func CreateEvent(ctx context.Context, info graph.CreateEventInfo) error {
+// CreateEvent creates the event, unless it overlaps with other events of the organizer (or, if checkAttendees is set,
+// busy times of the attendees). In that case, a conflict report is printed instead, unless force is set.
+func CreateEvent(ctx context.Context, info graph.CreateEventInfo, force, checkAttendees bool) error {
 	c, err := client.NewClient(global.AllScopes)
 	if err != nil {
 		return fmt.Errorf("failed to create client: %w", err)
@@ -26,6 +29,46 @@ func CreateEvent(ctx context.Context, info graph.CreateEventInfo) error {
 		info.ID = trueCalendarID
 	}
 
+	if len(info.Categories) > 0 {
+		info.Categories, err = masterCategories(ctx, c, info.Categories)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !force {
+		report, err := graph.FindConflicts(ctx, c, info, checkAttendees)
+		if err != nil {
+			return fmt.Errorf("failed to check for conflicts: %w", err)
+		}
+
+		if len(report.Conflicts) > 0 {
+			var conflictIDs []string
+			for _, conflict := range report.Conflicts {
+				if conflict.EventID != "" {
+					conflictIDs = append(conflictIDs, conflict.EventID)
+				}
+			}
+			translatedConflictIDs, err := id.SetOutlookIDs(ctx, conflictIDs)
+			if err != nil {
+				return fmt.Errorf("failed to set outlook IDs: %w", err)
+			}
+			for i, conflict := range report.Conflicts {
+				if conflict.EventID != "" {
+					report.Conflicts[i].EventID = translatedConflictIDs[conflict.EventID]
+				}
+			}
+
+			reportJSON, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal conflict report: %w", err)
+			}
+
+			fmt.Printf("The event was not created, because it conflicts with %d existing event(s). Ask the user whether to pick another time or to create it anyway (force=true).\n%s\n", len(report.Conflicts), reportJSON)
+			return nil
+		}
+	}
+
 	event, err := graph.CreateEvent(ctx, c, info)
 	if err != nil {
 		return fmt.Errorf("failed to create event: %w", err)
@@ -37,5 +80,10 @@ func CreateEvent(ctx context.Context, info graph.CreateEventInfo) error {
 	}
 
 	fmt.Printf("Event created with ID: %s\n", eventID)
+	if joinURL := graph.JoinURL(event); joinURL != "" {
+		fmt.Printf("Online meeting join URL: %s\n", joinURL)
+	} else if info.IsOnline {
+		fmt.Println("The online meeting is still being set up, get the event details later for the join URL.")
+	}
 	return nil
 }
@@ -0,0 +1,231 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/common/guard"
+	"github.com/gptscript-ai/tools/common/locale"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/graph"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	"github.com/gptscript-ai/tools/outlook/common/attachments"
+	"github.com/gptscript-ai/tools/outlook/common/id"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// ListEventAttachments lists the attachments of an event, without downloading them.
+func ListEventAttachments(ctx context.Context, eventID, calendarID string, owner graph.OwnerType) error {
+	trueEventID, trueCalendarID, err := eventAndCalendarIDs(ctx, eventID, calendarID)
+	if err != nil {
+		return err
+	}
+
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	result, err := graph.ListEventAttachments(ctx, c, trueEventID, trueCalendarID, owner)
+	if err != nil {
+		return err
+	}
+
+	if len(result) == 0 {
+		fmt.Println("The event has no attachments")
+		return nil
+	}
+
+	translatedAttachmentIDs, err := id.SetOutlookIDs(ctx, util.Map(result, func(attachment models.Attachmentable) string {
+		return util.Deref(attachment.GetId())
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to set Outlook IDs: %w", err)
+	}
+
+	loc := locale.FromEnv()
+	elements := make([]gptscript.DatasetElement, 0, len(result))
+	for _, attachment := range result {
+		attachmentID := translatedAttachmentIDs[util.Deref(attachment.GetId())]
+
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Name"), util.Deref(attachment.GetName())))
+		sb.WriteString(fmt.Sprintf("%s: %s\n", loc.T("ID"), attachmentID))
+		sb.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Type"), attachmentKind(attachment)))
+		sb.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Content Type"), util.Deref(attachment.GetContentType())))
+		sb.WriteString(fmt.Sprintf("%s: %s %s\n", loc.T("Size"), loc.Int(int64(util.Deref(attachment.GetSize()))), loc.T("bytes")))
+		sb.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Is inline"), loc.Bool(util.Deref(attachment.GetIsInline()))))
+
+		elements = append(elements, gptscript.DatasetElement{
+			DatasetElementMeta: gptscript.DatasetElementMeta{
+				Name:        attachmentID,
+				Description: util.Deref(attachment.GetName()),
+			},
+			Contents: sb.String(),
+		})
+	}
+
+	return guard.PrintElements(ctx, nil, elements, gptscript.DatasetOptions{
+		Name:        fmt.Sprintf("%s_outlook_event_attachments", eventID),
+		Description: "Attachments of Outlook Calendar event " + eventID,
+	}, "attachments")
+}
+
+// DownloadEventAttachment saves a file attachment of an event to the workspace, under files/.
+func DownloadEventAttachment(ctx context.Context, eventID, calendarID string, owner graph.OwnerType, attachmentID, fileName string) error {
+	trueEventID, trueCalendarID, err := eventAndCalendarIDs(ctx, eventID, calendarID)
+	if err != nil {
+		return err
+	}
+	trueAttachmentID, err := id.GetOutlookID(ctx, attachmentID)
+	if err != nil {
+		return fmt.Errorf("failed to get attachment ID: %w", err)
+	}
+
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	attachment, err := graph.GetEventAttachment(ctx, c, trueEventID, trueCalendarID, owner, trueAttachmentID)
+	if err != nil {
+		return err
+	}
+
+	fileAttachment, ok := attachment.(models.FileAttachmentable)
+	if !ok || util.Deref(attachment.GetOdataType()) != graph.FileAttachmentType {
+		return fmt.Errorf("attachment %s is a %s and can't be downloaded, only files can", util.Deref(attachment.GetName()), attachmentKind(attachment))
+	}
+
+	if fileName == "" {
+		fileName = util.Deref(attachment.GetName())
+	}
+	fileName, err = cleanFileName(fileName)
+	if err != nil {
+		return err
+	}
+
+	policy, err := attachments.PolicyFromEnv()
+	if err != nil {
+		return fmt.Errorf("failed to load attachment policy: %w", err)
+	}
+	if err := policy.CheckMetadata(fileName, int64(util.Deref(attachment.GetSize()))); err != nil {
+		return err
+	}
+	data := fileAttachment.GetContentBytes()
+	if err := policy.Check(ctx, fileName, data); err != nil {
+		return err
+	}
+
+	gptscriptClient, err := gptscript.NewGPTScript()
+	if err != nil {
+		return fmt.Errorf("failed to create GPTScript client: %w", err)
+	}
+
+	if err := gptscriptClient.WriteFileInWorkspace(ctx, path.Join("files", fileName), data); err != nil {
+		return fmt.Errorf("failed to save attachment to workspace: %w", err)
+	}
+
+	fmt.Printf("Attachment saved to the workspace as %s (%d bytes)\n", fileName, len(data))
+	return nil
+}
+
+// AddEventAttachments attaches a comma-separated list of workspace files to an event.
+func AddEventAttachments(ctx context.Context, eventID, calendarID string, owner graph.OwnerType, files string) error {
+	var paths []string
+	for _, file := range strings.Split(files, ",") {
+		if file = strings.TrimSpace(file); file != "" {
+			paths = append(paths, file)
+		}
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("no files to attach, set the workspace paths of the files")
+	}
+
+	trueEventID, trueCalendarID, err := eventAndCalendarIDs(ctx, eventID, calendarID)
+	if err != nil {
+		return err
+	}
+
+	gsClient, err := gptscript.NewGPTScript()
+	if err != nil {
+		return fmt.Errorf("failed to create GPTScript client: %w", err)
+	}
+
+	policy, err := attachments.PolicyFromEnv()
+	if err != nil {
+		return fmt.Errorf("failed to load attachment policy: %w", err)
+	}
+
+	// Read and check all files before uploading anything, so a policy violation doesn't leave a partially attached event
+	files := make([]graph.AttachmentFile, 0, len(paths))
+	for _, p := range paths {
+		data, err := gsClient.ReadFileInWorkspace(ctx, path.Join("files", p))
+		if err != nil {
+			return fmt.Errorf("failed to read attachment file %s from workspace: %w", p, err)
+		}
+		if len(data) == 0 {
+			return fmt.Errorf("cannot attach empty file %s", p)
+		}
+		if err := policy.Check(ctx, p, data); err != nil {
+			return err
+		}
+		files = append(files, graph.AttachmentFile{Name: p, Data: data})
+	}
+
+	c, err := client.NewClient(global.AllScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	if err := graph.AddEventAttachments(ctx, c, trueEventID, trueCalendarID, owner, files); err != nil {
+		return err
+	}
+
+	fmt.Printf("Attached %d file(s) to event %s\n", len(files), eventID)
+	return nil
+}
+
+// eventAndCalendarIDs returns the Outlook IDs of the event and the calendar, which is optional.
+func eventAndCalendarIDs(ctx context.Context, eventID, calendarID string) (string, string, error) {
+	trueEventID, err := id.GetOutlookID(ctx, eventID)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get Outlook ID: %w", err)
+	}
+
+	var trueCalendarID string
+	if calendarID != "" {
+		trueCalendarID, err = id.GetOutlookID(ctx, calendarID)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to get Outlook ID: %w", err)
+		}
+	}
+	return trueEventID, trueCalendarID, nil
+}
+
+func attachmentKind(attachment models.Attachmentable) string {
+	switch util.Deref(attachment.GetOdataType()) {
+	case graph.FileAttachmentType:
+		return "file"
+	case graph.ItemAttachmentType:
+		return "item (an attached message, event or contact)"
+	case graph.ReferenceAttachmentType:
+		return "link to a cloud file"
+	default:
+		return util.Deref(attachment.GetOdataType())
+	}
+}
+
+// cleanFileName keeps the file inside the files of the workspace
+func cleanFileName(fileName string) (string, error) {
+	cleaned := strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(fileName, "\\", "/")), "/")
+	if cleaned == "" || cleaned == "." {
+		return "", errors.New("the attachment has no name, please provide a file name")
+	}
+	return cleaned, nil
+}
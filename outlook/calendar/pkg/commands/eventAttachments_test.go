@@ -0,0 +1,28 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCleanFileName(t *testing.T) {
+	for fileName, want := range map[string]string{
+		"report.pdf":                "report.pdf",
+		"invoices/2024/11.pdf":      "invoices/2024/11.pdf",
+		"../../etc/passwd":          "etc/passwd",
+		"/tmp/report.pdf":           "tmp/report.pdf",
+		`..\..\windows\report.pdf`:  "windows/report.pdf",
+		"invoices/../../report.pdf": "report.pdf",
+	} {
+		got, err := cleanFileName(fileName)
+		require.NoError(t, err, fileName)
+		assert.Equal(t, want, got, fileName)
+	}
+
+	for _, fileName := range []string{"", ".", "..", "/", "../"} {
+		_, err := cleanFileName(fileName)
+		assert.Error(t, err, fileName)
+	}
+}
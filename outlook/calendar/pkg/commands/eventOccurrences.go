@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gptscript-ai/tools/common/locale"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/graph"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/printers"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	"github.com/gptscript-ai/tools/outlook/common/id"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// ListEventOccurrences prints the occurrences of a recurring event in the time frame. Every occurrence gets its own
+// ID, which can be used to change or cancel only that occurrence.
+func ListEventOccurrences(ctx context.Context, eventID, calendarID string, owner graph.OwnerType, start, end time.Time) error {
+	trueEventID, trueCalendarID, err := trueEventAndCalendarIDs(ctx, eventID, calendarID)
+	if err != nil {
+		return err
+	}
+
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	occurrences, err := graph.ListEventOccurrences(ctx, c, trueEventID, trueCalendarID, owner, start, end)
+	if err != nil {
+		return err
+	}
+	if len(occurrences) == 0 {
+		fmt.Println(locale.FromEnv().T("No events found"))
+		return nil
+	}
+
+	translatedIDs, err := id.SetOutlookIDs(ctx, util.Map(occurrences, func(event models.Eventable) string {
+		return util.Deref(event.GetId())
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to set event IDs: %w", err)
+	}
+
+	for _, occurrence := range occurrences {
+		occurrence.SetId(util.Ptr(translatedIDs[util.Deref(occurrence.GetId())]))
+		printers.PrintEvent(occurrence, false)
+	}
+	return nil
+}
+
+// UpdateEvent changes a single occurrence of a recurring event or the whole series, depending on the scope.
+func UpdateEvent(ctx context.Context, eventID, calendarID string, owner graph.OwnerType, scope graph.Scope, info graph.UpdateEventInfo) error {
+	if info.Subject == nil && info.Location == nil && info.Body == nil && info.Start == nil && info.End == nil &&
+		info.IsReminderOn == nil && info.ReminderMinutesBeforeStart == nil && info.Categories == nil {
+		return fmt.Errorf("nothing to update, set the subject, location, body, start, end, reminder or categories")
+	}
+
+	trueEventID, trueCalendarID, err := trueEventAndCalendarIDs(ctx, eventID, calendarID)
+	if err != nil {
+		return err
+	}
+
+	c, err := client.NewClient(global.AllScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	if len(info.Categories) > 0 {
+		info.Categories, err = masterCategories(ctx, c, info.Categories)
+		if err != nil {
+			return err
+		}
+	}
+
+	event, err := graph.UpdateEvent(ctx, c, trueEventID, trueCalendarID, owner, scope, info)
+	if err != nil {
+		return err
+	}
+
+	// The series has an ID of its own, which the user may not have seen yet
+	updatedID, err := id.SetOutlookID(ctx, util.Deref(event.GetId()))
+	if err != nil {
+		return fmt.Errorf("failed to set event ID: %w", err)
+	}
+
+	fmt.Printf("Event updated successfully (%s). Event ID: %s\n", scopeDescription(event), updatedID)
+	return nil
+}
+
+// CancelEvent cancels a single occurrence of a recurring event or the whole series, depending on the scope, and
+// notifies the attendees.
+func CancelEvent(ctx context.Context, eventID, calendarID string, owner graph.OwnerType, scope graph.Scope, comment string) error {
+	trueEventID, trueCalendarID, err := trueEventAndCalendarIDs(ctx, eventID, calendarID)
+	if err != nil {
+		return err
+	}
+
+	c, err := client.NewClient(global.AllScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	if err := graph.CancelEvent(ctx, c, trueEventID, trueCalendarID, owner, scope, comment); err != nil {
+		return err
+	}
+
+	fmt.Printf("Event cancelled successfully (%s)\n", scope)
+	return nil
+}
+
+// trueEventAndCalendarIDs translates the friendly IDs of an event and its calendar, which is optional.
+func trueEventAndCalendarIDs(ctx context.Context, eventID, calendarID string) (string, string, error) {
+	trueEventID, err := id.GetOutlookID(ctx, eventID)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get Outlook ID: %w", err)
+	}
+
+	var trueCalendarID string
+	if calendarID != "" {
+		trueCalendarID, err = id.GetOutlookID(ctx, calendarID)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to get Outlook Calendar ID: %w", err)
+		}
+	}
+	return trueEventID, trueCalendarID, nil
+}
+
+// scopeDescription describes which part of a recurring event the updated event is
+func scopeDescription(event models.Eventable) string {
+	switch util.Deref(event.GetTypeEscaped()) {
+	case models.SERIESMASTER_EVENTTYPE:
+		return "whole series"
+	case models.OCCURRENCE_EVENTTYPE, models.EXCEPTION_EVENTTYPE:
+		return "single occurrence"
+	default:
+		return "single event"
+	}
+}
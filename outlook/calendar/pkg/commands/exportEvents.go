@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/graph"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/ics"
+	"github.com/gptscript-ai/tools/outlook/common/id"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// ExportEvents writes the events to an .ics file in the workspace. The events are either the ones with the given IDs,
+// or the ones in the time frame of the calendar (the default calendar if calendarID is unset).
+func ExportEvents(ctx context.Context, eventIDs []string, calendarID string, owner graph.OwnerType, start, end time.Time, file string) error {
+	if file == "" {
+		file = "events.ics"
+	} else if !strings.EqualFold(filepath.Ext(file), ".ics") {
+		file += ".ics"
+	}
+
+	var trueCalendarID string
+	if calendarID != "" {
+		var err error
+		trueCalendarID, err = id.GetOutlookID(ctx, calendarID)
+		if err != nil {
+			return fmt.Errorf("failed to get Outlook Calendar ID: %w", err)
+		}
+	}
+
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	var events []models.Eventable
+	if len(eventIDs) > 0 {
+		trueEventIDs, err := id.GetOutlookIDs(ctx, eventIDs)
+		if err != nil {
+			return fmt.Errorf("failed to get Outlook IDs: %w", err)
+		}
+		for _, eventID := range eventIDs {
+			event, err := graph.GetEvent(ctx, c, trueEventIDs[eventID], trueCalendarID, owner)
+			if err != nil {
+				return fmt.Errorf("failed to get event %s: %w", eventID, err)
+			}
+			events = append(events, event)
+		}
+	} else {
+		if start.IsZero() || end.IsZero() {
+			return fmt.Errorf("either event IDs or a start and end time are required")
+		}
+
+		calendar := graph.CalendarInfo{ID: trueCalendarID, Owner: owner}
+		if trueCalendarID == "" {
+			calendar, err = graph.GetDefaultCalendar(ctx, c)
+			if err != nil {
+				return err
+			}
+		}
+
+		events, err = graph.ListCalendarView(ctx, c, calendar.ID, calendar.Owner, &start, &end, "", nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(events) == 0 {
+		fmt.Println("No events found, nothing was exported")
+		return nil
+	}
+
+	icsEvents := make([]ics.Event, 0, len(events))
+	for _, event := range events {
+		icsEvent, err := graph.ToICSEvent(event)
+		if err != nil {
+			return err
+		}
+		icsEvents = append(icsEvents, icsEvent)
+	}
+
+	gsClient, err := gptscript.NewGPTScript()
+	if err != nil {
+		return fmt.Errorf("failed to create GPTScript client: %w", err)
+	}
+
+	if err := gsClient.WriteFileInWorkspace(ctx, filepath.Join("files", file), ics.Marshal(icsEvents, time.Now())); err != nil {
+		return fmt.Errorf("failed to write file %s to workspace: %w", file, err)
+	}
+
+	fmt.Printf("Exported %d event(s) to %s\n", len(icsEvents), file)
+	return nil
+}
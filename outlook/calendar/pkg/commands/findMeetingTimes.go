@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/graph"
+)
+
+// FindMeetingTimes prints the suggested times for a meeting, best first, as JSON.
+func FindMeetingTimes(ctx context.Context, info graph.FindMeetingTimesInfo) error {
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	meetingTimes, err := graph.FindMeetingTimes(ctx, c, info)
+	if err != nil {
+		return err
+	}
+
+	meetingTimesJSON, err := json.MarshalIndent(meetingTimes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal meeting times: %w", err)
+	}
+
+	if len(meetingTimes.Suggestions) == 0 {
+		fmt.Printf("No meeting times found. Suggest widening the time frame, shortening the meeting or making some attendees optional.\n%s\n", meetingTimesJSON)
+		return nil
+	}
+
+	fmt.Printf("Found %d meeting time(s), ranked best first. Confidence is the percentage of attendees likely to attend.\n%s\n", len(meetingTimes.Suggestions), meetingTimesJSON)
+	return nil
+}
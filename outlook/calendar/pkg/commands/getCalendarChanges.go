@@ -0,0 +1,172 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/graph"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	"github.com/gptscript-ai/tools/outlook/common/id"
+	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
+)
+
+// deltaStateLocation is the workspace file with the delta links of the calendars, so that each call only returns the
+// changes since the previous call
+const deltaStateLocation = "outlookcalendardelta.json"
+
+type deltaState struct {
+	Calendars map[string]calendarDeltaState `json:"calendars"`
+}
+
+type calendarDeltaState struct {
+	DeltaLink string    `json:"deltaLink"`
+	QueriedAt time.Time `json:"queriedAt"`
+}
+
+type calendarChanges struct {
+	// Since is the time of the previous call, unset for the first call
+	Since   *time.Time          `json:"since,omitempty"`
+	Changes []graph.EventChange `json:"changes"`
+}
+
+// GetCalendarChanges prints the events of the calendar (the default calendar if calendarID is unset) that were
+// created, updated or deleted since the previous call, as JSON. The first call (or a call with reset) returns the
+// events between start and end, and the following calls track the changes in that time frame.
+func GetCalendarChanges(ctx context.Context, calendarID string, owner graph.OwnerType, start, end time.Time, reset bool) error {
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	var calendar graph.CalendarInfo
+	if calendarID != "" {
+		trueCalendarID, err := id.GetOutlookID(ctx, calendarID)
+		if err != nil {
+			return fmt.Errorf("failed to get Outlook Calendar ID: %w", err)
+		}
+		calendar, err = graph.GetCalendar(ctx, c, owner, trueCalendarID)
+		if err != nil {
+			return err
+		}
+	} else {
+		calendar, err = graph.GetDefaultCalendar(ctx, c)
+		if err != nil {
+			return err
+		}
+	}
+
+	gptscriptClient, err := gptscript.NewGPTScript()
+	if err != nil {
+		return fmt.Errorf("failed to create GPTScript client: %w", err)
+	}
+
+	state, err := loadDeltaState(ctx, gptscriptClient)
+	if err != nil {
+		return err
+	}
+
+	key := string(calendar.Owner) + "|" + calendar.ID
+	previous := state.Calendars[key]
+	if reset {
+		previous = calendarDeltaState{}
+	}
+
+	queriedAt := time.Now()
+	delta, err := graph.GetEventDelta(ctx, c, calendar.ID, calendar.Owner, previous.DeltaLink, start, end)
+	if err != nil && previous.DeltaLink != "" && isSyncStateExpired(err) {
+		// Outlook forgets delta links after a while, start over
+		previous = calendarDeltaState{}
+		delta, err = graph.GetEventDelta(ctx, c, calendar.ID, calendar.Owner, "", start, end)
+	}
+	if err != nil {
+		return err
+	}
+
+	changes := graph.EventChanges(delta, previous.QueriedAt)
+	translatedEventIDs, err := id.SetOutlookIDs(ctx, util.Map(changes, func(change graph.EventChange) string {
+		return change.ID
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to set event IDs: %w", err)
+	}
+	for i := range changes {
+		changes[i].ID = translatedEventIDs[changes[i].ID]
+	}
+
+	result := calendarChanges{Changes: changes}
+	if previous.DeltaLink == "" {
+		fmt.Printf("Started tracking the changes of the events between %s and %s. The next call returns the changes since this call.\n", start.Format(time.RFC3339), end.Format(time.RFC3339))
+	} else {
+		result.Since = &previous.QueriedAt
+	}
+
+	if len(changes) == 0 {
+		fmt.Println("No changes")
+	} else {
+		changesJSON, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal changes: %w", err)
+		}
+		fmt.Println(string(changesJSON))
+	}
+
+	// Only save the delta link once the changes are printed, so they aren't lost if printing fails
+	state.Calendars[key] = calendarDeltaState{
+		DeltaLink: delta.DeltaLink,
+		QueriedAt: queriedAt,
+	}
+	return saveDeltaState(ctx, gptscriptClient, state)
+}
+
+func isSyncStateExpired(err error) bool {
+	var odataErr *odataerrors.ODataError
+	if !errors.As(err, &odataErr) {
+		return false
+	}
+	if odataErr.ResponseStatusCode == http.StatusGone {
+		return true
+	}
+	mainErr := odataErr.GetErrorEscaped()
+	return mainErr != nil && mainErr.GetCode() != nil && strings.HasPrefix(strings.ToLower(*mainErr.GetCode()), "syncstate")
+}
+
+func loadDeltaState(ctx context.Context, gptscriptClient *gptscript.GPTScript) (deltaState, error) {
+	state := deltaState{Calendars: map[string]calendarDeltaState{}}
+
+	data, err := gptscriptClient.ReadFileInWorkspace(ctx, deltaStateLocation)
+	if err != nil {
+		var notFoundErr *gptscript.NotFoundInWorkspaceError
+		if errors.As(err, &notFoundErr) {
+			return state, nil
+		}
+		return state, fmt.Errorf("failed to read the delta state file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("failed to unmarshal the delta state: %w", err)
+	}
+	if state.Calendars == nil {
+		state.Calendars = map[string]calendarDeltaState{}
+	}
+	return state, nil
+}
+
+func saveDeltaState(ctx context.Context, gptscriptClient *gptscript.GPTScript, state deltaState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the delta state: %w", err)
+	}
+
+	if err := gptscriptClient.WriteFileInWorkspace(ctx, deltaStateLocation, data); err != nil {
+		return fmt.Errorf("failed to write the delta state file: %w", err)
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gptscript-ai/tools/outlook/calendar/pkg/client"
 	"github.com/gptscript-ai/tools/outlook/calendar/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/graph"
 )
 
 func GetDefaultTimezone(ctx context.Context) error {
@@ -14,13 +15,13 @@ func GetDefaultTimezone(ctx context.Context) error {
 		return fmt.Errorf("failed to create client: %w", err)
 	}
 
-	settings, err := c.Me().MailboxSettings().Get(ctx, nil)
+	tz, err := graph.GetMailboxTimeZone(ctx, c)
 	if err != nil {
-		return fmt.Errorf("failed to get mailbox settings: %w", err)
+		return err
 	}
 
-	if tz := settings.GetTimeZone(); tz != nil && *tz != "" {
-		fmt.Println("The user's default timezone is", *tz)
+	if tz != "" {
+		fmt.Println("The user's default timezone is", tz)
 	} else {
 		fmt.Println("The user's default timezone not defined")
 	}
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/gptscript-ai/tools/outlook/calendar/pkg/global"
 	"github.com/gptscript-ai/tools/outlook/calendar/pkg/graph"
 	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	attachmentpolicy "github.com/gptscript-ai/tools/outlook/common/attachments"
 	"github.com/gptscript-ai/tools/outlook/common/id"
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
 )
@@ -44,6 +46,11 @@ func GetEventAttachments(ctx context.Context, eventID, calendarID string, owner
 	if len(attachments) < 1 {
 		return nil
 	}
+	policy, err := attachmentpolicy.PolicyFromEnv()
+	if err != nil {
+		return fmt.Errorf("failed to load attachment policy: %w", err)
+	}
+
 	gptscriptClient, _ := gptscript.NewGPTScript()
 	for _, attachment := range attachments {
 		attachmentType := util.Deref(attachment.GetOdataType())
@@ -55,6 +62,15 @@ func GetEventAttachments(ctx context.Context, eventID, calendarID string, owner
 		fileAttachment := attachment.(*models.FileAttachment)
 		fileName := *fileAttachment.GetName()
 		contentBytes := fileAttachment.GetContentBytes()
+
+		if err := policy.Check(ctx, fileName, contentBytes); err != nil {
+			var violation *attachmentpolicy.PolicyViolationError
+			if !errors.As(err, &violation) {
+				return fmt.Errorf("failed to check attachment %s: %w", fileName, err)
+			}
+			fmt.Printf("Skipping attachment rejected by policy: %s\n", violation.JSON())
+			continue
+		}
 		err = gptscriptClient.WriteFileInWorkspace(ctx, fileName, contentBytes)
 		if err != nil {
 			log.Fatalf("Error saving file: %v", err)
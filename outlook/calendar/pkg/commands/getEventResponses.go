@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/graph"
+	"github.com/gptscript-ai/tools/outlook/common/id"
+)
+
+// GetEventResponses prints the response status of each attendee of the event, as JSON.
+func GetEventResponses(ctx context.Context, eventID, calendarID string, owner graph.OwnerType) error {
+	trueEventID, err := id.GetOutlookID(ctx, eventID)
+	if err != nil {
+		return fmt.Errorf("failed to get Outlook ID: %w", err)
+	}
+
+	var trueCalendarID string
+	if calendarID != "" {
+		trueCalendarID, err = id.GetOutlookID(ctx, calendarID)
+		if err != nil {
+			return fmt.Errorf("failed to get Outlook ID: %w", err)
+		}
+	}
+
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	event, err := graph.GetEvent(ctx, c, trueEventID, trueCalendarID, owner)
+	if err != nil {
+		return fmt.Errorf("failed to get event: %w", err)
+	}
+
+	responses := graph.GetEventResponses(event)
+	responses.EventID = eventID
+
+	if len(responses.Attendees) == 0 {
+		fmt.Println("The event has no attendees")
+		return nil
+	}
+
+	responsesJSON, err := json.MarshalIndent(responses, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal responses: %w", err)
+	}
+
+	fmt.Println(string(responsesJSON))
+	return nil
+}
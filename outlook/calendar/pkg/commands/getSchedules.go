@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/graph"
+)
+
+// GetSchedules prints the busy times of the attendees in the time frame, as JSON.
+func GetSchedules(ctx context.Context, attendees []string, start, end time.Time) error {
+	var emails []string
+	for _, a := range attendees {
+		if a = strings.TrimSpace(a); a != "" {
+			emails = append(emails, a)
+		}
+	}
+	if len(emails) == 0 {
+		return fmt.Errorf("at least one attendee is required")
+	}
+
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	schedules, err := graph.GetSchedules(ctx, c, emails, start, end)
+	if err != nil {
+		return err
+	}
+
+	schedulesJSON, err := json.MarshalIndent(schedules, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal schedules: %w", err)
+	}
+
+	fmt.Printf("Busy times between %s and %s. Attendees are free at all other times, unless their schedule has an error.\n%s\n", start.Format(time.RFC3339), end.Format(time.RFC3339), schedulesJSON)
+	return nil
+}
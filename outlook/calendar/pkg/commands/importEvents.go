@@ -0,0 +1,151 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/eventimport"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/graph"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	"github.com/gptscript-ai/tools/outlook/common/id"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+)
+
+type ImportReport struct {
+	DryRun  bool                      `json:"dryRun"`
+	Valid   int                       `json:"validEvents"`
+	Invalid []eventimport.RowError    `json:"invalidEvents,omitempty"`
+	Events  []eventimport.ParsedEvent `json:"events,omitempty"` // only for dry runs
+	Created []ImportedEvent           `json:"createdEvents,omitempty"`
+	Failed  []eventimport.RowError    `json:"failedEvents,omitempty"`
+}
+
+type ImportedEvent struct {
+	Row     int    `json:"row"`
+	Subject string `json:"subject"`
+	EventID string `json:"eventId"`
+}
+
+// ImportEvents creates the events of a CSV, JSON or iCalendar file in the workspace. Nothing is created if any event is invalid,
+// and with dryRun only the validation report is printed. Conflicts with existing events are not checked.
+func ImportEvents(ctx context.Context, file, calendarID string, owner graph.OwnerType, timezone string, dryRun bool) error {
+	gsClient, err := gptscript.NewGPTScript()
+	if err != nil {
+		return fmt.Errorf("failed to create GPTScript client: %w", err)
+	}
+
+	data, err := gsClient.ReadFileInWorkspace(ctx, filepath.Join("files", file))
+	if err != nil {
+		return fmt.Errorf("failed to read file %s from workspace: %w", file, err)
+	}
+
+	c, err := client.NewClient(global.AllScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	defaultLoc, err := importTimezone(ctx, c, timezone)
+	if err != nil {
+		return err
+	}
+
+	var (
+		events  []eventimport.ParsedEvent
+		invalid []eventimport.RowError
+	)
+	if eventimport.IsICS(file, data) {
+		events, invalid, err = eventimport.ParseICS(data, graph.LoadLocation, defaultLoc)
+	} else {
+		events, invalid, err = eventimport.Parse(file, data, defaultLoc)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read events from %s: %w", file, err)
+	}
+
+	report := ImportReport{
+		DryRun:  dryRun,
+		Valid:   len(events),
+		Invalid: invalid,
+	}
+	if dryRun {
+		report.Events = events
+		return printImportReport(report, fmt.Sprintf("Dry run: %d event(s) would be created, %d event(s) are invalid.", len(events), len(invalid)))
+	}
+	if len(invalid) > 0 {
+		return printImportReport(report, fmt.Sprintf("No events were created, because %d event(s) are invalid. Fix the file and try again.", len(invalid)))
+	}
+
+	if calendarID != "" {
+		calendarID, err = id.GetOutlookID(ctx, calendarID)
+		if err != nil {
+			return fmt.Errorf("failed to get outlook ID: %w", err)
+		}
+	}
+
+	for _, e := range events {
+		event, err := graph.CreateEvent(ctx, c, graph.CreateEventInfo{
+			Attendees: e.Attendees,
+			Subject:   e.Subject,
+			Location:  e.Location,
+			Body:      e.Body,
+			ID:        calendarID,
+			Owner:     owner,
+			IsOnline:  e.IsOnline,
+			IsAllDay:  e.IsAllDay,
+			RRule:     e.RRule,
+			Start:     e.Start,
+			End:       e.End,
+		})
+		if err != nil {
+			report.Failed = append(report.Failed, eventimport.RowError{Row: e.Row, Err: err.Error()})
+			continue
+		}
+
+		eventID, err := id.SetOutlookID(ctx, util.Deref(event.GetId()))
+		if err != nil {
+			return fmt.Errorf("failed to set outlook ID: %w", err)
+		}
+		report.Created = append(report.Created, ImportedEvent{Row: e.Row, Subject: e.Subject, EventID: eventID})
+	}
+
+	return printImportReport(report, fmt.Sprintf("%d event(s) created, %d event(s) failed.", len(report.Created), len(report.Failed)))
+}
+
+// importTimezone returns the timezone for times without a UTC offset: the given one, or the user's default timezone
+// if it is an IANA name (mailbox settings may also hold Windows timezone names). Nil if there is none.
+func importTimezone(ctx context.Context, c *msgraphsdkgo.GraphServiceClient, timezone string) (*time.Location, error) {
+	if timezone != "" {
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			return nil, fmt.Errorf("unknown timezone %q (use an IANA name like Europe/Berlin): %w", timezone, err)
+		}
+		return loc, nil
+	}
+
+	settings, err := c.Me().MailboxSettings().Get(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mailbox settings: %w", err)
+	}
+	if tz := util.Deref(settings.GetTimeZone()); tz != "" {
+		if loc, err := time.LoadLocation(tz); err == nil {
+			return loc, nil
+		}
+	}
+	return nil, nil
+}
+
+func printImportReport(report ImportReport, summary string) error {
+	reportJSON, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal import report: %w", err)
+	}
+
+	fmt.Printf("%s\n%s\n", summary, reportJSON)
+	return nil
+}
@@ -12,13 +12,13 @@ import (
 	"github.com/gptscript-ai/tools/outlook/common/id"
 )
 
-func ListCalendars(ctx context.Context) error {
+func ListCalendars(ctx context.Context, sharedWith []string) error {
 	c, err := client.NewClient(global.ReadOnlyScopes)
 	if err != nil {
 		return err
 	}
 
-	calendars, err := graph.ListCalendars(ctx, c)
+	calendars, err := graph.ListCalendars(ctx, c, sharedWith)
 	if err != nil {
 		return fmt.Errorf("failed to list calendars: %w", err)
 	}
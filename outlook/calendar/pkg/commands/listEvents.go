@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/common/guard"
+	"github.com/gptscript-ai/tools/common/locale"
 	"github.com/gptscript-ai/tools/outlook/calendar/pkg/client"
 	"github.com/gptscript-ai/tools/outlook/calendar/pkg/global"
 	"github.com/gptscript-ai/tools/outlook/calendar/pkg/graph"
@@ -15,13 +17,15 @@ import (
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
 )
 
-func ListEvents(ctx context.Context, start, end time.Time) error {
+// ListEvents lists the events of all calendars in the time frame with the selected properties, see graph.EventFields.
+// If categories are set, only the events with at least one of them are listed.
+func ListEvents(ctx context.Context, start, end time.Time, sharedWith, categories, selects []string) error {
 	c, err := client.NewClient(global.ReadOnlyScopes)
 	if err != nil {
 		return fmt.Errorf("failed to create client: %w", err)
 	}
 
-	calendars, err := graph.ListCalendars(ctx, c)
+	calendars, err := graph.ListCalendars(ctx, c, sharedWith)
 	if err != nil {
 		return fmt.Errorf("failed to list calendars: %w", err)
 	}
@@ -40,10 +44,11 @@ func ListEvents(ctx context.Context, start, end time.Time) error {
 			continue
 		}
 
-		events, err := graph.ListCalendarView(ctx, c, cal.ID, cal.Owner, &start, &end)
+		events, err := graph.ListCalendarView(ctx, c, cal.ID, cal.Owner, &start, &end, "", selects)
 		if err != nil {
 			return fmt.Errorf("failed to list events for calendar %s: %w", util.Deref(cal.Calendar.GetName()), err)
 		}
+		events = graph.FilterEventsByCategories(events, categories)
 
 		if len(events) == 0 {
 			continue
@@ -70,7 +75,7 @@ func ListEvents(ctx context.Context, start, end time.Time) error {
 	}
 
 	if len(calendarEvents) == 0 {
-		fmt.Println("No events found")
+		fmt.Println(locale.FromEnv().T("No events found"))
 		return nil
 	}
 
@@ -93,14 +98,8 @@ func ListEvents(ctx context.Context, start, end time.Time) error {
 		}
 	}
 
-	datasetID, err := gptscriptClient.CreateDatasetWithElements(ctx, elements, gptscript.DatasetOptions{
+	return guard.PrintElements(ctx, gptscriptClient, elements, gptscript.DatasetOptions{
 		Name:        "event_list",
 		Description: "List of Outlook Calendar events",
-	})
-	if err != nil {
-		return fmt.Errorf("failed to create dataset with elements: %w", err)
-	}
-
-	fmt.Printf("Created dataset with ID %s with %d events\n", datasetID, len(elements))
-	return nil
+	}, "events")
 }
@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/graph"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	"github.com/gptscript-ai/tools/outlook/common/id"
+)
+
+// ListUpcomingReminders prints the reminders that go off in the time frame, as JSON.
+func ListUpcomingReminders(ctx context.Context, start, end time.Time) error {
+	if !end.After(start) {
+		return fmt.Errorf("the end of the time frame must be after the start")
+	}
+
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	reminders, err := graph.ListReminders(ctx, c, start, end)
+	if err != nil {
+		return err
+	}
+
+	if len(reminders) == 0 {
+		fmt.Println("No reminders found in the time frame")
+		return nil
+	}
+
+	translatedEventIDs, err := id.SetOutlookIDs(ctx, util.Map(reminders, func(r graph.Reminder) string {
+		return r.EventID
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to set event IDs: %w", err)
+	}
+	for i := range reminders {
+		reminders[i].EventID = translatedEventIDs[reminders[i].EventID]
+	}
+
+	remindersJSON, err := json.MarshalIndent(reminders, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal reminders: %w", err)
+	}
+
+	fmt.Printf("Reminders between %s and %s:\n%s\n", start.Format(time.RFC3339), end.Format(time.RFC3339), remindersJSON)
+	return nil
+}
@@ -3,6 +3,7 @@ package commands
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/gptscript-ai/tools/outlook/calendar/pkg/client"
 	"github.com/gptscript-ai/tools/outlook/calendar/pkg/global"
@@ -10,7 +11,9 @@ import (
 	"github.com/gptscript-ai/tools/outlook/common/id"
 )
 
-func RespondToEvent(ctx context.Context, eventID, calendarID string, owner graph.OwnerType, response string) error {
+// RespondToEvent accepts, tentatively accepts or declines the invitation to the event. The organizer is only notified,
+// and gets the comment, if sendResponse is set.
+func RespondToEvent(ctx context.Context, eventID, calendarID string, owner graph.OwnerType, response, comment string, sendResponse bool) error {
 	trueEventID, err := id.GetOutlookID(ctx, eventID)
 	if err != nil {
 		return fmt.Errorf("failed to get Outlook ID: %w", err)
@@ -31,17 +34,17 @@ func RespondToEvent(ctx context.Context, eventID, calendarID string, owner graph
 
 	switch response {
 	case "accept":
-		if err := graph.AcceptEvent(ctx, c, trueEventID, trueCalendarID, owner); err != nil {
+		if err := graph.AcceptEvent(ctx, c, trueEventID, trueCalendarID, owner, comment, sendResponse); err != nil {
 			return fmt.Errorf("failed to accept event: %w", err)
 		}
 		fmt.Println("Event accepted successfully")
-	case "tentative":
-		if err := graph.TentativelyAcceptEvent(ctx, c, trueEventID, trueCalendarID, owner); err != nil {
+	case "tentative", "tentativelyAccept":
+		if err := graph.TentativelyAcceptEvent(ctx, c, trueEventID, trueCalendarID, owner, comment, sendResponse); err != nil {
 			return fmt.Errorf("failed to tentatively accept event: %w", err)
 		}
 		fmt.Println("Event tentatively accepted successfully")
 	case "decline":
-		if err := graph.DeclineEvent(ctx, c, trueEventID, trueCalendarID, owner); err != nil {
+		if err := graph.DeclineEvent(ctx, c, trueEventID, trueCalendarID, owner, comment, sendResponse); err != nil {
 			return fmt.Errorf("failed to decline event: %w", err)
 		}
 		fmt.Println("Event declined successfully")
@@ -51,3 +54,63 @@ func RespondToEvent(ctx context.Context, eventID, calendarID string, owner graph
 
 	return nil
 }
+
+// BulkRespondToEvents accepts, tentatively accepts or declines the invitations to the comma-separated events of the
+// user's calendars, and reports the result of each event.
+func BulkRespondToEvents(ctx context.Context, eventIDs, response, comment string, sendResponse bool) error {
+	switch response {
+	case "accept", "tentative", "tentativelyAccept", "decline":
+	default:
+		return fmt.Errorf("invalid response: %s", response)
+	}
+
+	var ids []string
+	for _, eventID := range strings.Split(eventIDs, ",") {
+		if eventID = strings.TrimSpace(eventID); eventID != "" {
+			ids = append(ids, eventID)
+		}
+	}
+	if len(ids) == 0 {
+		return fmt.Errorf("no event IDs specified")
+	}
+
+	// Events with unknown IDs are reported as failed, instead of failing the whole operation
+	found, missing, err := id.LookupOutlookIDs(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to get Outlook IDs: %w", err)
+	}
+	for _, eventID := range missing {
+		fmt.Printf("%s: failed: Outlook ID not found\n", eventID)
+	}
+
+	var friendlyIDs, trueEventIDs []string
+	for _, eventID := range ids {
+		if trueEventID, ok := found[eventID]; ok {
+			friendlyIDs = append(friendlyIDs, eventID)
+			trueEventIDs = append(trueEventIDs, trueEventID)
+		}
+	}
+
+	c, err := client.NewClient(global.AllScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	errs, err := graph.RespondToEvents(ctx, c, trueEventIDs, response, comment, sendResponse)
+	if err != nil {
+		return err
+	}
+
+	failed := len(missing)
+	for i, err := range errs {
+		if err != nil {
+			fmt.Printf("%s: failed: %v\n", friendlyIDs[i], err)
+			failed++
+			continue
+		}
+		fmt.Printf("%s: %s\n", friendlyIDs[i], response)
+	}
+
+	fmt.Printf("Responded to %d of %d events successfully\n", len(ids)-failed, len(ids))
+	return nil
+}
@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/graph"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	"github.com/gptscript-ai/tools/outlook/common/id"
+)
+
+// ListRoomLists prints the room lists of the organization, as JSON.
+func ListRoomLists(ctx context.Context) error {
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	roomLists, err := graph.ListRoomLists(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	if len(roomLists) == 0 {
+		fmt.Println("No room lists found")
+		return nil
+	}
+
+	roomListsJSON, err := json.MarshalIndent(roomLists, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal room lists: %w", err)
+	}
+
+	fmt.Println(string(roomListsJSON))
+	return nil
+}
+
+// FindRooms prints the rooms matching the filter, as JSON. If start and end are set, it also checks whether the rooms
+// are free in that time frame, and onlyAvailable leaves out the rooms that are not.
+func FindRooms(ctx context.Context, roomList string, filter graph.RoomFilter, start, end time.Time, onlyAvailable bool) error {
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	rooms, err := graph.ListRooms(ctx, c, strings.TrimSpace(roomList))
+	if err != nil {
+		return err
+	}
+	rooms = graph.FilterRooms(rooms, filter)
+
+	checkAvailability := !start.IsZero() && !end.IsZero()
+	if checkAvailability {
+		if err := graph.SetRoomAvailability(ctx, c, rooms, start, end); err != nil {
+			return err
+		}
+		if onlyAvailable {
+			var available []graph.Room
+			for _, r := range rooms {
+				if util.Deref(r.Available) {
+					available = append(available, r)
+				}
+			}
+			rooms = available
+		}
+	}
+
+	if len(rooms) == 0 {
+		fmt.Println("No matching rooms found")
+		return nil
+	}
+
+	roomsJSON, err := json.MarshalIndent(rooms, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal rooms: %w", err)
+	}
+
+	if checkAvailability {
+		fmt.Printf("Rooms and their availability between %s and %s:\n", start.Format(time.RFC3339), end.Format(time.RFC3339))
+	}
+	fmt.Println(string(roomsJSON))
+	return nil
+}
+
+// AddRoomToEvent books the room for the event, by adding it as a resource attendee.
+func AddRoomToEvent(ctx context.Context, eventID, calendarID string, owner graph.OwnerType, roomEmail string) error {
+	roomEmail = strings.TrimSpace(roomEmail)
+	if roomEmail == "" {
+		return fmt.Errorf("the email address of the room is required")
+	}
+
+	trueEventID, err := id.GetOutlookID(ctx, eventID)
+	if err != nil {
+		return fmt.Errorf("failed to get Outlook ID: %w", err)
+	}
+
+	var trueCalendarID string
+	if calendarID != "" {
+		trueCalendarID, err = id.GetOutlookID(ctx, calendarID)
+		if err != nil {
+			return fmt.Errorf("failed to get Outlook ID: %w", err)
+		}
+	}
+
+	c, err := client.NewClient(global.AllScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	if _, err := graph.AddRoomToEvent(ctx, c, trueEventID, trueCalendarID, owner, roomEmail); err != nil {
+		return err
+	}
+
+	fmt.Printf("Successfully invited the room %s to the event. The room accepts or declines depending on its availability and booking policy.\n", roomEmail)
+	return nil
+}
@@ -3,26 +3,31 @@ package commands
 import (
 	"context"
 	"fmt"
-	"strings"
-	"time"
 
 	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/common/guard"
+	"github.com/gptscript-ai/tools/common/locale"
 	"github.com/gptscript-ai/tools/outlook/calendar/pkg/client"
 	"github.com/gptscript-ai/tools/outlook/calendar/pkg/global"
 	"github.com/gptscript-ai/tools/outlook/calendar/pkg/graph"
 	"github.com/gptscript-ai/tools/outlook/calendar/pkg/printers"
 	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
 	"github.com/gptscript-ai/tools/outlook/common/id"
+	"github.com/gptscript-ai/tools/outlook/common/pagination"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
 )
 
-func SearchEvents(ctx context.Context, query string, start, end time.Time) error {
+// SearchEvents lists the events of all calendars that match the search, up to the limit (0 for no limit), with the
+// selected properties, see graph.EventFields. The events are written in batches of one page, so large results that go
+// to a dataset don't have to be kept in memory.
+func SearchEvents(ctx context.Context, search graph.EventSearch, sharedWith []string, limit int, selects []string) error {
 	c, err := client.NewClient(global.ReadOnlyScopes)
 	if err != nil {
 		return fmt.Errorf("failed to create client: %w", err)
 	}
 
-	calendars, err := graph.ListCalendars(ctx, c)
+	calendars, err := graph.ListCalendars(ctx, c, sharedWith)
 	if err != nil {
 		return fmt.Errorf("failed to list calendars: %w", err)
 	}
@@ -35,73 +40,82 @@ func SearchEvents(ctx context.Context, query string, start, end time.Time) error
 		return fmt.Errorf("failed to translate calendar IDs: %w", err)
 	}
 
-	calendarEventsInSubject := make(map[graph.CalendarInfo][]models.Eventable, len(calendars))
-	calendarEventsInPreview := make(map[graph.CalendarInfo][]models.Eventable, len(calendars))
+	gptscriptClient, err := gptscript.NewGPTScript()
+	if err != nil {
+		return fmt.Errorf("failed to create GPTScript client: %w", err)
+	}
+
+	writer := guard.NewWriter(gptscriptClient, gptscript.DatasetOptions{
+		Name:        "event_search " + search.Query,
+		Description: "Search results for Outlook Calendar events",
+	}, "events")
+
+	var count int
 	for _, cal := range calendars {
-		result, err := graph.ListCalendarView(ctx, c, cal.ID, cal.Owner, &start, &end)
-		if err != nil {
-			return fmt.Errorf("failed to search events: %w", err)
+		if limit > 0 && count >= limit {
+			break
 		}
-
-		if len(result) == 0 {
+		if cal.ID == "" {
 			continue
 		}
 
-		// Update the calendar ID to the translated ID
-		cal.ID = translatedCalendarIDs[cal.ID]
-
-		eventIDs := util.Map(result, func(event models.Eventable) string {
-			return util.Deref(event.GetId())
-		})
-		translatedEventIDs, err := id.SetOutlookIDs(ctx, eventIDs)
-		if err != nil {
-			return fmt.Errorf("failed to translate event IDs: %w", err)
-		}
-
-		for _, event := range result {
-			event.SetId(util.Ptr(translatedEventIDs[util.Deref(event.GetId())]))
-
-			if strings.Contains(strings.ToLower(util.Deref(event.GetSubject())), strings.ToLower(query)) {
-				calendarEventsInSubject[cal] = append(calendarEventsInSubject[cal], event)
-			} else if strings.Contains(strings.ToLower(util.Deref(event.GetBodyPreview())), strings.ToLower(query)) {
-				calendarEventsInPreview[cal] = append(calendarEventsInPreview[cal], event)
+		var (
+			batch    []models.Eventable
+			writeErr error
+		)
+		// The calendar is copied, so the printed calendar gets the translated ID while Graph is queried with the real one
+		printed := cal
+		printed.ID = translatedCalendarIDs[cal.ID]
+		if err := graph.SearchCalendar(ctx, c, cal.ID, cal.Owner, search, selects, func(event models.Eventable) bool {
+			count++
+			if batch = append(batch, event); len(batch) >= pagination.PageSize() {
+				writeErr = writeEvents(ctx, c, writer, printed, batch)
+				batch = batch[:0]
 			}
+			return writeErr == nil && (limit <= 0 || count < limit)
+		}); err != nil {
+			return fmt.Errorf("failed to search events in calendar %s: %w", util.Deref(cal.Calendar.GetName()), err)
+		}
+		if writeErr != nil {
+			return writeErr
+		}
+		if err := writeEvents(ctx, c, writer, printed, batch); err != nil {
+			return err
 		}
 	}
 
-	allCalendarEvents := util.Merge(calendarEventsInSubject, calendarEventsInPreview)
-	if len(allCalendarEvents) == 0 {
-		fmt.Println("No events found")
+	if count == 0 {
+		fmt.Println(locale.FromEnv().T("No events found"))
 		return nil
 	}
 
-	gptscriptClient, err := gptscript.NewGPTScript()
-	if err != nil {
-		return fmt.Errorf("failed to create GPTScript client: %w", err)
-	}
+	return writer.Close(ctx)
+}
 
-	var elements []gptscript.DatasetElement
-	for cal, events := range allCalendarEvents {
-		for _, event := range events {
-			name := util.Deref(event.GetId()) + "_" + util.Deref(event.GetSubject())
-			elements = append(elements, gptscript.DatasetElement{
-				DatasetElementMeta: gptscript.DatasetElementMeta{
-					Name:        name,
-					Description: util.Deref(event.GetBodyPreview()),
-				},
-				Contents: printers.EventToString(ctx, c, cal, event),
-			})
-		}
+// writeEvents translates the IDs of the events to friendly IDs and adds the events to the output.
+func writeEvents(ctx context.Context, c *msgraphsdkgo.GraphServiceClient, writer *guard.Writer, cal graph.CalendarInfo, events []models.Eventable) error {
+	if len(events) == 0 {
+		return nil
 	}
 
-	datasetID, err := gptscriptClient.CreateDatasetWithElements(ctx, elements, gptscript.DatasetOptions{
-		Name:        "event_search " + query,
-		Description: "Search results for Outlook Calendar events",
-	})
+	translatedEventIDs, err := id.SetOutlookIDs(ctx, util.Map(events, func(event models.Eventable) string {
+		return util.Deref(event.GetId())
+	}))
 	if err != nil {
-		return fmt.Errorf("failed to create dataset with elements: %w", err)
+		return fmt.Errorf("failed to translate event IDs: %w", err)
+	}
+
+	elements := make([]gptscript.DatasetElement, 0, len(events))
+	for _, event := range events {
+		event.SetId(util.Ptr(translatedEventIDs[util.Deref(event.GetId())]))
+		elements = append(elements, gptscript.DatasetElement{
+			DatasetElementMeta: gptscript.DatasetElementMeta{
+				Name:        util.Deref(event.GetId()) + "_" + util.Deref(event.GetSubject()),
+				Description: util.Deref(event.GetBodyPreview()),
+			},
+			Contents: printers.EventToString(ctx, c, cal, event),
+		})
 	}
 
-	fmt.Printf("Created dataset with ID %s with %d events\n", datasetID, len(elements))
-	return nil
+	return writer.Add(ctx, elements...)
 }
@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/graph"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+)
+
+type participantWorkingHours struct {
+	Email        string              `json:"email"`
+	WorkingHours *graph.WorkingHours `json:"workingHours,omitempty"`
+	Error        string              `json:"error,omitempty"`
+}
+
+type slotSuggestions struct {
+	Slots []graph.Slot `json:"slots"`
+	// Participants are the working hours that the slots are limited to
+	Participants []participantWorkingHours `json:"participants"`
+}
+
+// GetWorkingHours prints the working hours of the user and the attendees, as JSON.
+func GetWorkingHours(ctx context.Context, attendees []string) error {
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	participants, _, err := workingHoursAndSchedules(ctx, c, trimEmails(attendees), time.Now(), time.Now().Add(24*time.Hour))
+	if err != nil {
+		return err
+	}
+
+	participantsJSON, err := json.MarshalIndent(participants, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal working hours: %w", err)
+	}
+
+	fmt.Printf("Working hours, in the time zone of each person. The first entry is the user.\n%s\n", participantsJSON)
+	return nil
+}
+
+// SuggestSlots prints the time frames in which the user and the attendees are working and free for at least the
+// duration, as JSON.
+func SuggestSlots(ctx context.Context, attendees []string, start, end time.Time, duration time.Duration) error {
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	participants, schedules, err := workingHoursAndSchedules(ctx, c, trimEmails(attendees), start, end)
+	if err != nil {
+		return err
+	}
+
+	var workingHours []graph.WorkingHours
+	for _, p := range participants {
+		if p.WorkingHours != nil {
+			workingHours = append(workingHours, *p.WorkingHours)
+		}
+	}
+
+	slots, err := graph.SuggestSlots(workingHours, schedules, start, end, duration)
+	if err != nil {
+		return err
+	}
+
+	suggestionsJSON, err := json.MarshalIndent(slotSuggestions{
+		Slots:        slots,
+		Participants: participants,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal slots: %w", err)
+	}
+
+	if len(slots) == 0 {
+		fmt.Printf("No slots found within working hours. Suggest widening the time frame or shortening the meeting.\n%s\n", suggestionsJSON)
+		return nil
+	}
+
+	fmt.Printf("Found %d slot(s) in which everyone is working and free. The meeting can start at any time within a slot, as long as it ends by the end of the slot. People without working hours or with an error are only limited by their known busy times.\n%s\n", len(slots), suggestionsJSON)
+	return nil
+}
+
+// workingHoursAndSchedules returns the working hours of the user and the attendees, and their schedules in the time
+// frame. The working hours of the user are taken from the mailbox settings, those of the attendees from their
+// schedules.
+func workingHoursAndSchedules(ctx context.Context, c *msgraphsdkgo.GraphServiceClient, attendees []string, start, end time.Time) ([]participantWorkingHours, []graph.Schedule, error) {
+	userEmail, err := graph.GetUserEmail(ctx, c)
+	if err != nil {
+		return nil, nil, err
+	}
+	userWorkingHours, err := graph.GetWorkingHours(ctx, c)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	emails := []string{userEmail}
+	for _, a := range attendees {
+		if !strings.EqualFold(a, userEmail) {
+			emails = append(emails, a)
+		}
+	}
+
+	schedules, err := graph.GetSchedules(ctx, c, emails, start, end)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	participants := []participantWorkingHours{{
+		Email:        userEmail,
+		WorkingHours: userWorkingHours,
+	}}
+	for _, s := range schedules {
+		if strings.EqualFold(s.Email, userEmail) {
+			continue
+		}
+		participants = append(participants, participantWorkingHours{
+			Email:        s.Email,
+			WorkingHours: s.WorkingHours,
+			Error:        s.Error,
+		})
+	}
+	return participants, schedules, nil
+}
+
+func trimEmails(emails []string) []string {
+	var result []string
+	for _, e := range emails {
+		if e = strings.TrimSpace(e); e != "" {
+			result = append(result, e)
+		}
+	}
+	return result
+}
@@ -0,0 +1,301 @@
+// Package eventimport parses and validates the events of a bulk import file (CSV, JSON or iCalendar).
+package eventimport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxEvents is the maximum number of events in a single import
+const MaxEvents = 500
+
+// Event is a single event of the import file. In JSON files, attendees can also be an array, in CSV files they are
+// separated by semicolons.
+type Event struct {
+	Subject   string `json:"subject"`
+	Start     string `json:"start"`
+	End       string `json:"end"`
+	Location  string `json:"location"`
+	Body      string `json:"body"`
+	Attendees any    `json:"attendees"`
+	IsOnline  any    `json:"is_online"`
+	TimeZone  string `json:"timezone"`
+}
+
+// ParsedEvent is a validated event. Row is the row of the event in a CSV file (the header is row 1), or the 1-based
+// position in a JSON file.
+type ParsedEvent struct {
+	Row       int       `json:"row"`
+	Subject   string    `json:"subject"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Location  string    `json:"location,omitempty"`
+	Body      string    `json:"-"`
+	Attendees []string  `json:"attendees,omitempty"`
+	IsOnline  bool      `json:"isOnline"`
+	// Only set for iCalendar files
+	IsAllDay bool   `json:"isAllDay,omitempty"`
+	RRule    string `json:"rrule,omitempty"`
+}
+
+// RowError is the validation error of a single event
+type RowError struct {
+	Row int    `json:"row"`
+	Err string `json:"error"`
+}
+
+// localLayouts are accepted for times without a UTC offset, which are interpreted in the event's timezone
+var localLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+// Parse reads the events from a CSV or JSON file (by extension, falling back to the content). Times without a UTC
+// offset are interpreted in the timezone of the event, or defaultLoc if the event has none.
+// All events are validated - the ones that are invalid are returned as RowErrors.
+func Parse(filename string, data []byte, defaultLoc *time.Location) ([]ParsedEvent, []RowError, error) {
+	var (
+		events []Event
+		rows   []int
+		err    error
+	)
+	switch ext := strings.ToLower(filepath.Ext(filename)); {
+	case ext == ".json", ext != ".csv" && json.Valid(data):
+		events, rows, err = readJSON(data)
+	default:
+		events, rows, err = readCSV(data)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(events) == 0 {
+		return nil, nil, errors.New("the file does not contain any events")
+	}
+	if len(events) > MaxEvents {
+		return nil, nil, fmt.Errorf("the file contains %d events, at most %d can be imported at once", len(events), MaxEvents)
+	}
+
+	var (
+		parsed []ParsedEvent
+		errs   []RowError
+	)
+	for i, e := range events {
+		p, err := e.validate(defaultLoc)
+		if err != nil {
+			errs = append(errs, RowError{Row: rows[i], Err: err.Error()})
+			continue
+		}
+		p.Row = rows[i]
+		parsed = append(parsed, p)
+	}
+	return parsed, errs, nil
+}
+
+func readJSON(data []byte) ([]Event, []int, error) {
+	var events []Event
+	if err := json.Unmarshal(data, &events); err != nil {
+		// Also accept {"events": [...]}
+		var wrapped struct {
+			Events []Event `json:"events"`
+		}
+		if err2 := json.Unmarshal(data, &wrapped); err2 != nil || wrapped.Events == nil {
+			return nil, nil, fmt.Errorf("failed to parse JSON, expected an array of events: %w", err)
+		}
+		events = wrapped.Events
+	}
+
+	rows := make([]int, len(events))
+	for i := range rows {
+		rows[i] = i + 1
+	}
+	return events, rows, nil
+}
+
+func readCSV(data []byte) ([]Event, []int, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil, nil
+	}
+
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		columns[strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "_")] = i
+	}
+	for _, required := range []string{"subject", "start", "end"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, fmt.Errorf("the CSV header is missing the %q column", required)
+		}
+	}
+
+	var (
+		events []Event
+		rows   []int
+	)
+	for i, record := range records[1:] {
+		get := func(column string) string {
+			if idx, ok := columns[column]; ok && idx < len(record) {
+				return strings.TrimSpace(record[idx])
+			}
+			return ""
+		}
+		if strings.Join(record, "") == "" {
+			continue
+		}
+		events = append(events, Event{
+			Subject:   get("subject"),
+			Start:     get("start"),
+			End:       get("end"),
+			Location:  get("location"),
+			Body:      get("body"),
+			Attendees: get("attendees"),
+			IsOnline:  get("is_online"),
+			TimeZone:  get("timezone"),
+		})
+		rows = append(rows, i+2)
+	}
+	return events, rows, nil
+}
+
+func (e Event) validate(defaultLoc *time.Location) (ParsedEvent, error) {
+	var errs []error
+
+	subject := strings.TrimSpace(e.Subject)
+	if subject == "" {
+		errs = append(errs, errors.New("subject is required"))
+	}
+
+	loc := defaultLoc
+	if tz := strings.TrimSpace(e.TimeZone); tz != "" {
+		l, err := time.LoadLocation(tz)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unknown timezone %q (use an IANA name like Europe/Berlin)", tz))
+		} else {
+			loc = l
+		}
+	}
+
+	start, err := parseTime(e.Start, loc)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid start: %w", err))
+	}
+	end, err := parseTime(e.End, loc)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid end: %w", err))
+	}
+	if !start.IsZero() && !end.IsZero() && !end.After(start) {
+		errs = append(errs, errors.New("end must be after start"))
+	}
+
+	attendees, err := parseAttendees(e.Attendees)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	isOnline, err := parseBool(e.IsOnline)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid is_online: %w", err))
+	}
+
+	if len(errs) > 0 {
+		return ParsedEvent{}, errors.Join(errs...)
+	}
+
+	return ParsedEvent{
+		Subject:   subject,
+		Start:     start,
+		End:       end,
+		Location:  strings.TrimSpace(e.Location),
+		Body:      e.Body,
+		Attendees: attendees,
+		IsOnline:  isOnline,
+	}, nil
+}
+
+func parseTime(s string, loc *time.Location) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, errors.New("missing")
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range localLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+			if loc == nil {
+				return time.Time{}, fmt.Errorf("%q has no UTC offset and no timezone is set", s)
+			}
+			return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not in RFC 3339 format (e.g. 2025-03-01T09:00:00+01:00) or YYYY-MM-DD HH:MM", s)
+}
+
+func parseAttendees(v any) ([]string, error) {
+	var raw []string
+	switch a := v.(type) {
+	case nil:
+	case string:
+		raw = strings.FieldsFunc(a, func(r rune) bool { return r == ';' || r == ',' })
+	case []any:
+		for _, item := range a {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid attendee %v", item)
+			}
+			raw = append(raw, s)
+		}
+	default:
+		return nil, fmt.Errorf("invalid attendees %v", v)
+	}
+
+	var attendees []string
+	for _, address := range raw {
+		address = strings.TrimSpace(address)
+		if address == "" {
+			continue
+		}
+		if at := strings.Index(address, "@"); at <= 0 || at == len(address)-1 || strings.ContainsAny(address, " <>") {
+			return nil, fmt.Errorf("invalid attendee email address %q", address)
+		}
+		attendees = append(attendees, address)
+	}
+	return attendees, nil
+}
+
+func parseBool(v any) (bool, error) {
+	switch b := v.(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return b, nil
+	case string:
+		switch strings.ToLower(strings.TrimSpace(b)) {
+		case "":
+			return false, nil
+		case "yes", "y":
+			return true, nil
+		case "no", "n":
+			return false, nil
+		}
+		return strconv.ParseBool(strings.TrimSpace(b))
+	default:
+		return false, fmt.Errorf("%v is not a boolean", v)
+	}
+}
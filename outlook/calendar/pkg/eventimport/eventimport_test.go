@@ -0,0 +1,92 @@
+package eventimport
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCSV(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	data := []byte(`Subject,Start,End,Location,Attendees,Is Online,Timezone
+Onboarding,2025-03-03 09:00,2025-03-03 10:30,Room 1,a@example.com;b@example.com,yes,
+Security Training,2025-03-04T09:00:00Z,2025-03-04T10:00:00Z,,,,
+,2025-03-05 09:00,2025-03-05 08:00,,not-an-email,,Mars/Olympus
+Q&A,2025-03-06 17:00,2025-03-06 18:00,,,false,America/New_York
+`)
+
+	events, errs, err := Parse("sessions.csv", data, berlin)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	require.Equal(t, 2, events[0].Row)
+	require.Equal(t, "Onboarding", events[0].Subject)
+	require.Equal(t, time.Date(2025, 3, 3, 8, 0, 0, 0, time.UTC), events[0].Start.UTC())
+	require.Equal(t, []string{"a@example.com", "b@example.com"}, events[0].Attendees)
+	require.True(t, events[0].IsOnline)
+
+	require.Equal(t, time.Date(2025, 3, 4, 9, 0, 0, 0, time.UTC), events[1].Start.UTC())
+
+	require.Equal(t, 5, events[2].Row)
+	require.Equal(t, time.Date(2025, 3, 6, 22, 0, 0, 0, time.UTC), events[2].Start.UTC())
+
+	require.Len(t, errs, 1)
+	require.Equal(t, 4, errs[0].Row)
+	require.Contains(t, errs[0].Err, "subject is required")
+	require.Contains(t, errs[0].Err, "unknown timezone")
+	require.Contains(t, errs[0].Err, "invalid attendee")
+}
+
+func TestParseJSON(t *testing.T) {
+	data := []byte(`{"events": [
+		{"subject": "Training 1", "start": "2025-03-03T09:00:00+01:00", "end": "2025-03-03T10:00:00+01:00", "attendees": ["a@example.com"], "is_online": true},
+		{"subject": "Training 2", "start": "2025-03-04 09:00", "end": "2025-03-04 10:00"}
+	]}`)
+
+	events, errs, err := Parse("sessions.json", data, nil)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, []string{"a@example.com"}, events[0].Attendees)
+	require.True(t, events[0].IsOnline)
+
+	// Without a default timezone, times need a UTC offset or a timezone
+	require.Len(t, errs, 1)
+	require.Equal(t, 2, errs[0].Row)
+	require.Contains(t, errs[0].Err, "no UTC offset")
+}
+
+func TestParseMissingColumn(t *testing.T) {
+	_, _, err := Parse("sessions.csv", []byte("subject,start\nA,2025-03-03T09:00:00Z\n"), nil)
+	require.ErrorContains(t, err, `"end"`)
+}
+
+func TestParseICS(t *testing.T) {
+	data := []byte("BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" +
+		"BEGIN:VEVENT\r\nSUMMARY:Standup\r\nDTSTART;TZID=Europe/Berlin:20250303T093000\r\nDURATION:PT15M\r\n" +
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR\r\nATTENDEE;CN=A:mailto:a@example.com\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nSUMMARY:Cancelled\r\nDTSTART:20250304T090000Z\r\nSTATUS:CANCELLED\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nSUMMARY:Every hour\r\nDTSTART:20250305T090000Z\r\nRRULE:FREQ=HOURLY\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nSUMMARY:Offsite\r\nDTSTART;VALUE=DATE:20250310\r\nDTEND;VALUE=DATE:20250312\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n")
+	require.True(t, IsICS("invite.txt", data))
+
+	events, errs, err := ParseICS(data, time.LoadLocation, nil)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	require.Equal(t, 1, events[0].Row)
+	require.Equal(t, time.Date(2025, 3, 3, 8, 30, 0, 0, time.UTC), events[0].Start.UTC())
+	require.Equal(t, 15*time.Minute, events[0].End.Sub(events[0].Start))
+	require.Equal(t, []string{"a@example.com"}, events[0].Attendees)
+	require.Equal(t, "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR", events[0].RRule)
+
+	require.Equal(t, 4, events[1].Row)
+	require.True(t, events[1].IsAllDay)
+
+	require.Len(t, errs, 1)
+	require.Equal(t, 3, errs[0].Row)
+	require.Contains(t, errs[0].Err, "FREQ=HOURLY is not supported")
+}
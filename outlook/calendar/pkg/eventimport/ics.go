@@ -0,0 +1,96 @@
+package eventimport
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/ics"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/recurrence"
+)
+
+// IsICS returns whether the file is an iCalendar file, by extension or content.
+func IsICS(filename string, data []byte) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	return ext == ".ics" || ext == ".ical" || bytes.HasPrefix(bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))), []byte("BEGIN:VCALENDAR"))
+}
+
+// ParseICS reads the events of an iCalendar file. Rows are the 1-based positions of the events in the file.
+// Times with a TZID are interpreted in the time zone returned by loadLocation, floating times in defaultLoc.
+// Cancelled events are left out.
+func ParseICS(data []byte, loadLocation func(string) (*time.Location, error), defaultLoc *time.Location) ([]ParsedEvent, []RowError, error) {
+	events, err := ics.Parse(data, loadLocation, defaultLoc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(events) == 0 {
+		return nil, nil, errors.New("the file does not contain any events")
+	}
+	if len(events) > MaxEvents {
+		return nil, nil, fmt.Errorf("the file contains %d events, at most %d can be imported at once", len(events), MaxEvents)
+	}
+
+	var (
+		parsed []ParsedEvent
+		errs   []RowError
+	)
+	for i, e := range events {
+		if e.Err == nil && e.Event.Cancelled {
+			continue
+		}
+		p, err := validateICS(e)
+		if err != nil {
+			errs = append(errs, RowError{Row: i + 1, Err: err.Error()})
+			continue
+		}
+		p.Row = i + 1
+		parsed = append(parsed, p)
+	}
+	return parsed, errs, nil
+}
+
+func validateICS(e ics.ParsedEvent) (ParsedEvent, error) {
+	errs := []error{e.Err}
+
+	subject := strings.TrimSpace(e.Event.Summary)
+	if subject == "" {
+		errs = append(errs, errors.New("SUMMARY is required"))
+	}
+	if e.Err == nil && !e.Event.End.After(e.Event.Start) {
+		errs = append(errs, errors.New("the end must be after the start"))
+	}
+	if e.Event.RRule != "" && e.Err == nil {
+		if _, err := recurrence.FromRRule(e.Event.RRule, e.Event.Start); err != nil {
+			errs = append(errs, fmt.Errorf("unsupported RRULE: %w", err))
+		}
+	}
+
+	var attendees []string
+	for _, a := range e.Event.Attendees {
+		address := strings.TrimSpace(a.Email)
+		if at := strings.Index(address, "@"); at <= 0 || at == len(address)-1 || strings.ContainsAny(address, " <>") {
+			errs = append(errs, fmt.Errorf("invalid attendee email address %q", address))
+			continue
+		}
+		attendees = append(attendees, address)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return ParsedEvent{}, err
+	}
+
+	return ParsedEvent{
+		Subject:   subject,
+		Start:     e.Event.Start,
+		End:       e.Event.End,
+		Location:  strings.TrimSpace(e.Event.Location),
+		Body:      e.Event.Description,
+		Attendees: attendees,
+		IsAllDay:  e.Event.AllDay,
+		RRule:     e.Event.RRule,
+	}, nil
+}
@@ -0,0 +1,191 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"path/filepath"
+
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	"github.com/gptscript-ai/tools/outlook/common/pagination"
+	abstractions "github.com/microsoft/kiota-abstractions-go"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/groups"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+const (
+	FileAttachmentType      = "#microsoft.graph.fileAttachment"
+	ItemAttachmentType      = "#microsoft.graph.itemAttachment"
+	ReferenceAttachmentType = "#microsoft.graph.referenceAttachment"
+)
+
+// attachmentProperties are the properties of an attachment without its content
+var attachmentProperties = []string{"id", "name", "contentType", "size", "isInline", "lastModifiedDateTime"}
+
+// AttachmentFile is the name and content of a file to attach.
+type AttachmentFile struct {
+	Name string
+	Data []byte
+}
+
+// eventOwner returns the user whose events include the event: the owner of a shared calendar, or the user.
+// Event IDs are unique in the mailbox, so the events of all of the user's calendars can be found by ID.
+func eventOwner(client *msgraphsdkgo.GraphServiceClient, calendarID string, owner OwnerType) *users.UserItemRequestBuilder {
+	if calendarID != "" && owner == OwnerTypeShared {
+		return client.Users().ByUserId(calendarID)
+	}
+	return client.Me()
+}
+
+// ListEventAttachments returns the attachments of an event, without their content.
+func ListEventAttachments(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, eventID, calendarID string, owner OwnerType) ([]models.Attachmentable, error) {
+	var pages *pagination.PageIterator[models.Attachmentable]
+	if calendarID != "" && owner == OwnerTypeGroup {
+		attachments := client.Groups().ByGroupId(calendarID).Events().ByEventId(eventID).Attachments()
+		pages = pagination.New(
+			func(ctx context.Context, q pagination.Query) (models.AttachmentCollectionResponseable, error) {
+				return attachments.Get(ctx, &groups.ItemEventsItemAttachmentsRequestBuilderGetRequestConfiguration{
+					QueryParameters: &groups.ItemEventsItemAttachmentsRequestBuilderGetQueryParameters{
+						Top:    q.Top,
+						Select: q.Select,
+					},
+				})
+			},
+			func(ctx context.Context, nextLink string) (models.AttachmentCollectionResponseable, error) {
+				return attachments.WithUrl(nextLink).Get(ctx, nil)
+			},
+		)
+	} else {
+		attachments := eventOwner(client, calendarID, owner).Events().ByEventId(eventID).Attachments()
+		pages = pagination.New(
+			func(ctx context.Context, q pagination.Query) (models.AttachmentCollectionResponseable, error) {
+				return attachments.Get(ctx, &users.ItemEventsItemAttachmentsRequestBuilderGetRequestConfiguration{
+					QueryParameters: &users.ItemEventsItemAttachmentsRequestBuilderGetQueryParameters{
+						Top:    q.Top,
+						Select: q.Select,
+					},
+				})
+			},
+			func(ctx context.Context, nextLink string) (models.AttachmentCollectionResponseable, error) {
+				return attachments.WithUrl(nextLink).Get(ctx, nil)
+			},
+		)
+	}
+
+	result, err := pages.WithSelect(attachmentProperties...).Collect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+	return result, nil
+}
+
+// GetEventAttachment returns an attachment of an event. File attachments include their content.
+func GetEventAttachment(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, eventID, calendarID string, owner OwnerType, attachmentID string) (models.Attachmentable, error) {
+	var (
+		attachment models.Attachmentable
+		err        error
+	)
+	if calendarID != "" && owner == OwnerTypeGroup {
+		attachment, err = client.Groups().ByGroupId(calendarID).Events().ByEventId(eventID).Attachments().ByAttachmentId(attachmentID).Get(ctx, nil)
+	} else {
+		attachment, err = eventOwner(client, calendarID, owner).Events().ByEventId(eventID).Attachments().ByAttachmentId(attachmentID).Get(ctx, nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachment: %w", err)
+	}
+	return attachment, nil
+}
+
+// uploadSessionThreshold is the size from which files are uploaded in chunks. Graph only accepts smaller
+// files in a single request.
+const uploadSessionThreshold = 3 * 1024 * 1024 // 3MB
+
+const uploadChunkSize = 1024 * 1024 // 1MB
+
+// AddEventAttachments attaches the files to the event. Large files are uploaded in chunks.
+func AddEventAttachments(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, eventID, calendarID string, owner OwnerType, files []AttachmentFile) error {
+	// Graph doesn't support concurrent upload sessions for the same item, so the files are attached one by one
+	for _, file := range files {
+		var err error
+		if len(file.Data) < uploadSessionThreshold {
+			err = attachFile(ctx, client, eventID, calendarID, owner, file)
+		} else {
+			err = uploadFile(ctx, client, eventID, calendarID, owner, file)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func attachFile(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, eventID, calendarID string, owner OwnerType, file AttachmentFile) error {
+	attachment := models.NewFileAttachment()
+	attachment.SetName(util.Ptr(filepath.Base(file.Name)))
+	attachment.SetContentBytes(file.Data)
+	if contentType := mime.TypeByExtension(filepath.Ext(file.Name)); contentType != "" {
+		attachment.SetContentType(util.Ptr(contentType))
+	}
+
+	var err error
+	if calendarID != "" && owner == OwnerTypeGroup {
+		_, err = client.Groups().ByGroupId(calendarID).Events().ByEventId(eventID).Attachments().Post(ctx, attachment, nil)
+	} else {
+		_, err = eventOwner(client, calendarID, owner).Events().ByEventId(eventID).Attachments().Post(ctx, attachment, nil)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to attach file %s: %w", file.Name, err)
+	}
+	return nil
+}
+
+func uploadFile(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, eventID, calendarID string, owner OwnerType, file AttachmentFile) error {
+	attachment := models.NewAttachmentItem()
+	attachment.SetAttachmentType(util.Ptr(models.FILE_ATTACHMENTTYPE))
+	attachment.SetName(util.Ptr(filepath.Base(file.Name)))
+	attachment.SetSize(util.Ptr(int64(len(file.Data))))
+
+	var (
+		session models.UploadSessionable
+		err     error
+	)
+	if calendarID != "" && owner == OwnerTypeGroup {
+		requestBody := groups.NewItemEventsItemAttachmentsCreateUploadSessionPostRequestBody()
+		requestBody.SetAttachmentItem(attachment)
+		session, err = client.Groups().ByGroupId(calendarID).Events().ByEventId(eventID).Attachments().CreateUploadSession().Post(ctx, requestBody, nil)
+	} else {
+		requestBody := users.NewItemEventsItemAttachmentsCreateUploadSessionPostRequestBody()
+		requestBody.SetAttachmentItem(attachment)
+		session, err = eventOwner(client, calendarID, owner).Events().ByEventId(eventID).Attachments().CreateUploadSession().Post(ctx, requestBody, nil)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create upload session for file %s: %w", file.Name, err)
+	}
+
+	errorMapping := abstractions.ErrorMappings{
+		"4XX": odataerrors.CreateODataErrorFromDiscriminatorValue,
+		"5XX": odataerrors.CreateODataErrorFromDiscriminatorValue,
+	}
+	totalSize := len(file.Data)
+	for start := 0; start < totalSize; start += uploadChunkSize {
+		end := min(start+uploadChunkSize, totalSize)
+		chunk := file.Data[start:end]
+		contentRange := fmt.Sprintf("bytes %d-%d/%d", start, end-1, totalSize)
+
+		requestInfo := abstractions.NewRequestInformation()
+		requestInfo.UrlTemplate = util.Deref(session.GetUploadUrl())
+		requestInfo.Method = abstractions.PUT
+		requestInfo.Headers.Add("Content-Length", fmt.Sprintf("%d", len(chunk)))
+		requestInfo.Headers.Add("Content-Range", contentRange)
+		requestInfo.SetStreamContentAndContentType(chunk, "application/octet-stream")
+
+		if err := client.BaseRequestBuilder.RequestAdapter.SendNoContent(ctx, requestInfo, errorMapping); err != nil {
+			return fmt.Errorf("failed to upload chunk %s for file %s: %w", contentRange, file.Name, err)
+		}
+	}
+
+	return nil
+}
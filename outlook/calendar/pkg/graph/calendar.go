@@ -3,9 +3,12 @@ package graph
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	"github.com/gptscript-ai/tools/outlook/common/fields"
+	"github.com/gptscript-ai/tools/outlook/common/pagination"
 	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
 	"github.com/microsoftgraph/msgraph-sdk-go/groups"
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
@@ -23,9 +26,25 @@ type OwnerType string
 const (
 	OwnerTypeUser  OwnerType = "user"
 	OwnerTypeGroup OwnerType = "group"
+	// OwnerTypeShared is the default calendar of another user that was shared with the user. Its ID is the email address
+	// of the other user.
+	OwnerTypeShared OwnerType = "shared"
 )
 
 func GetCalendar(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, owner OwnerType, id string) (CalendarInfo, error) {
+	if owner == OwnerTypeShared {
+		resp, err := client.Users().ByUserId(id).Calendar().Get(ctx, nil)
+		if err != nil {
+			return CalendarInfo{}, fmt.Errorf("failed to get calendar shared by %s: %w", id, err)
+		}
+
+		return CalendarInfo{
+			Calendar: resp,
+			ID:       id,
+			Owner:    OwnerTypeShared,
+		}, nil
+	}
+
 	if owner == OwnerTypeUser {
 		resp, err := client.Me().Calendars().ByCalendarId(id).Get(ctx, nil)
 		if err != nil {
@@ -51,21 +70,27 @@ func GetCalendar(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, o
 	}, nil
 }
 
-func ListCalendars(ctx context.Context, client *msgraphsdkgo.GraphServiceClient) ([]CalendarInfo, error) {
-	calendarsGetResp, err := client.Me().Calendars().Get(ctx, &users.ItemCalendarsRequestBuilderGetRequestConfiguration{
-		QueryParameters: &users.ItemCalendarsRequestBuilderGetQueryParameters{
-			Top: util.Ptr(int32(100)),
+// ListCalendars returns the calendars of the user, including the ones of other users they added, the calendars of
+// their groups, and the default calendars of the users in sharedWith that shared them with the user.
+func ListCalendars(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, sharedWith []string) ([]CalendarInfo, error) {
+	userCalendars, err := pagination.New(
+		func(ctx context.Context, q pagination.Query) (models.CalendarCollectionResponseable, error) {
+			return client.Me().Calendars().Get(ctx, &users.ItemCalendarsRequestBuilderGetRequestConfiguration{
+				QueryParameters: &users.ItemCalendarsRequestBuilderGetQueryParameters{
+					Top: q.Top,
+				},
+			})
 		},
-	})
-
-	// TODO - handle if there are more than 100
-
+		func(ctx context.Context, nextLink string) (models.CalendarCollectionResponseable, error) {
+			return client.Me().Calendars().WithUrl(nextLink).Get(ctx, nil)
+		},
+	).Collect(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list user's calendars: %w", err)
 	}
 
 	var calendars []CalendarInfo
-	for _, calendar := range calendarsGetResp.GetValue() {
+	for _, calendar := range userCalendars {
 		calendars = append(calendars, CalendarInfo{
 			Calendar: calendar,
 			ID:       util.Deref(calendar.GetId()),
@@ -74,19 +99,24 @@ func ListCalendars(ctx context.Context, client *msgraphsdkgo.GraphServiceClient)
 	}
 
 	// Get the group memberships so that we can check group calendars.
-	memberOf, err := client.Me().MemberOf().Get(ctx, &users.ItemMemberOfRequestBuilderGetRequestConfiguration{
-		QueryParameters: &users.ItemMemberOfRequestBuilderGetQueryParameters{
-			Top: util.Ptr(int32(100)),
+	memberOf, err := pagination.New(
+		func(ctx context.Context, q pagination.Query) (models.DirectoryObjectCollectionResponseable, error) {
+			return client.Me().MemberOf().Get(ctx, &users.ItemMemberOfRequestBuilderGetRequestConfiguration{
+				QueryParameters: &users.ItemMemberOfRequestBuilderGetQueryParameters{
+					Top:    q.Top,
+					Select: q.Select,
+				},
+			})
 		},
-	})
-
-	// TODO - handle if there are more than 100
-
+		func(ctx context.Context, nextLink string) (models.DirectoryObjectCollectionResponseable, error) {
+			return client.Me().MemberOf().WithUrl(nextLink).Get(ctx, nil)
+		},
+	).WithSelect("id").Collect(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get group memberships: %w", err)
 	}
 
-	for _, group := range memberOf.GetValue() {
+	for _, group := range memberOf {
 		result, err := client.Groups().ByGroupId(util.Deref(group.GetId())).Calendar().Get(ctx, nil)
 		if err != nil {
 			// Some groups don't have calendars and will just error out. That's fine.
@@ -100,41 +130,127 @@ func ListCalendars(ctx context.Context, client *msgraphsdkgo.GraphServiceClient)
 		})
 	}
 
-	return calendars, nil
-}
-
-func ListCalendarView(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, id string, owner OwnerType, start, end *time.Time) ([]models.Eventable, error) {
-	if owner == OwnerTypeUser {
-		resp, err := client.Me().Calendars().ByCalendarId(id).CalendarView().Get(ctx, &users.ItemCalendarsItemCalendarViewRequestBuilderGetRequestConfiguration{
-			QueryParameters: &users.ItemCalendarsItemCalendarViewRequestBuilderGetQueryParameters{
-				EndDateTime:   util.Ptr(util.Deref(end).Format(time.RFC3339)),
-				StartDateTime: util.Ptr(util.Deref(start).Format(time.RFC3339)),
-				Top:           util.Ptr(int32(100)),
-			},
-		})
-
-		// TODO - handle if there are more than 100
+	for _, email := range sharedWith {
+		if email = strings.TrimSpace(email); email == "" {
+			continue
+		}
 
+		calendar, err := GetCalendar(ctx, client, OwnerTypeShared, email)
 		if err != nil {
-			return nil, fmt.Errorf("failed to list calendar view: %w", err)
+			return nil, err
 		}
+		calendars = append(calendars, calendar)
+	}
+
+	return calendars, nil
+}
 
-		return resp.GetValue(), nil
+// GetMailboxTimeZone returns the time zone of the user's mailbox settings, or an empty string if it is not set.
+func GetMailboxTimeZone(ctx context.Context, client *msgraphsdkgo.GraphServiceClient) (string, error) {
+	settings, err := client.Me().MailboxSettings().Get(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get mailbox settings: %w", err)
 	}
+	return util.Deref(settings.GetTimeZone()), nil
+}
 
-	resp, err := client.Groups().ByGroupId(id).CalendarView().Get(ctx, &groups.ItemCalendarViewRequestBuilderGetRequestConfiguration{
-		QueryParameters: &groups.ItemCalendarViewRequestBuilderGetQueryParameters{
-			EndDateTime:   util.Ptr(util.Deref(end).Format(time.RFC3339)),
-			StartDateTime: util.Ptr(util.Deref(start).Format(time.RFC3339)),
-			Top:           util.Ptr(int32(100)),
-		},
-	})
+// EventFields are the field sets of the commands that list and search events. The default set has the properties that
+// printers.EventToString prints, and the body preview that describes the events in datasets.
+var EventFields = fields.Sets{
+	fields.Summary: {"subject", "start", "end", "isAllDay"},
+	fields.Default: {"subject", "start", "end", "isAllDay", "categories", "bodyPreview"},
+	fields.Full:    nil,
+}
 
-	// TODO - handle if there are more than 100
+// EventRequiredFields are always selected, the printers need the times and the events are filtered by category.
+var EventRequiredFields = []string{"start", "end", "isAllDay", "categories"}
 
+// ListCalendarView returns the events of the calendar in the time frame, ordered by start, with recurring events
+// expanded into their occurrences. The start and end of the events are in the time zone (an IANA or Windows time zone
+// name), or in UTC if it is empty. Only the selected properties are returned, all default properties if selects is
+// nil.
+func ListCalendarView(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, id string, owner OwnerType, start, end *time.Time, timeZone string, selects []string) ([]models.Eventable, error) {
+	events, err := calendarViewPages(client, id, owner, start, end, timeZone).WithSelect(selects...).Collect(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list calendar view: %w", err)
 	}
 
-	return resp.GetValue(), nil
+	return events, nil
+}
+
+// calendarViewPages returns an iterator over the events of the calendar view, following @odata.nextLink across pages.
+func calendarViewPages(client *msgraphsdkgo.GraphServiceClient, id string, owner OwnerType, start, end *time.Time, timeZone string) *pagination.PageIterator[models.Eventable] {
+	startDateTime := util.Ptr(util.Deref(start).Format(time.RFC3339))
+	endDateTime := util.Ptr(util.Deref(end).Format(time.RFC3339))
+	headers := timeZoneHeaders(timeZone)
+	orderBy := []string{"start/dateTime"}
+
+	var pages *pagination.PageIterator[models.Eventable]
+	switch owner {
+	case OwnerTypeUser:
+		calendarView := client.Me().Calendars().ByCalendarId(id).CalendarView()
+		pages = pagination.New(
+			func(ctx context.Context, q pagination.Query) (models.EventCollectionResponseable, error) {
+				return calendarView.Get(ctx, &users.ItemCalendarsItemCalendarViewRequestBuilderGetRequestConfiguration{
+					Headers: headers,
+					QueryParameters: &users.ItemCalendarsItemCalendarViewRequestBuilderGetQueryParameters{
+						EndDateTime:   endDateTime,
+						StartDateTime: startDateTime,
+						Orderby:       orderBy,
+						Top:           q.Top,
+						Select:        q.Select,
+					},
+				})
+			},
+			func(ctx context.Context, nextLink string) (models.EventCollectionResponseable, error) {
+				return calendarView.WithUrl(nextLink).Get(ctx, &users.ItemCalendarsItemCalendarViewRequestBuilderGetRequestConfiguration{
+					Headers: headers,
+				})
+			},
+		)
+	case OwnerTypeShared:
+		calendarView := client.Users().ByUserId(id).Calendar().CalendarView()
+		pages = pagination.New(
+			func(ctx context.Context, q pagination.Query) (models.EventCollectionResponseable, error) {
+				return calendarView.Get(ctx, &users.ItemCalendarCalendarViewRequestBuilderGetRequestConfiguration{
+					Headers: headers,
+					QueryParameters: &users.ItemCalendarCalendarViewRequestBuilderGetQueryParameters{
+						EndDateTime:   endDateTime,
+						StartDateTime: startDateTime,
+						Orderby:       orderBy,
+						Top:           q.Top,
+						Select:        q.Select,
+					},
+				})
+			},
+			func(ctx context.Context, nextLink string) (models.EventCollectionResponseable, error) {
+				return calendarView.WithUrl(nextLink).Get(ctx, &users.ItemCalendarCalendarViewRequestBuilderGetRequestConfiguration{
+					Headers: headers,
+				})
+			},
+		)
+	default:
+		calendarView := client.Groups().ByGroupId(id).CalendarView()
+		pages = pagination.New(
+			func(ctx context.Context, q pagination.Query) (models.EventCollectionResponseable, error) {
+				return calendarView.Get(ctx, &groups.ItemCalendarViewRequestBuilderGetRequestConfiguration{
+					Headers: headers,
+					QueryParameters: &groups.ItemCalendarViewRequestBuilderGetQueryParameters{
+						EndDateTime:   endDateTime,
+						StartDateTime: startDateTime,
+						Orderby:       orderBy,
+						Top:           q.Top,
+						Select:        q.Select,
+					},
+				})
+			},
+			func(ctx context.Context, nextLink string) (models.EventCollectionResponseable, error) {
+				return calendarView.WithUrl(nextLink).Get(ctx, &groups.ItemCalendarViewRequestBuilderGetRequestConfiguration{
+					Headers: headers,
+				})
+			},
+		)
+	}
+
+	return pages
 }
@@ -0,0 +1,78 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// ListCategories returns the master categories of the user, which are the categories that can be applied to events.
+func ListCategories(ctx context.Context, client *msgraphsdkgo.GraphServiceClient) ([]models.OutlookCategoryable, error) {
+	result, err := client.Me().Outlook().MasterCategories().Get(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list categories: %w", err)
+	}
+
+	return result.GetValue(), nil
+}
+
+// CategoryNames returns the names of the categories.
+func CategoryNames(categories []models.OutlookCategoryable) []string {
+	return util.Map(categories, func(category models.OutlookCategoryable) string {
+		return util.Deref(category.GetDisplayName())
+	})
+}
+
+// MatchCategories returns the names of the available categories with the given names, which are matched
+// case-insensitively, so that events are labelled consistently. Empty and duplicate names are left out.
+func MatchCategories(available, names []string) ([]string, error) {
+	result := []string{}
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		idx := slices.IndexFunc(available, func(category string) bool {
+			return strings.EqualFold(category, name)
+		})
+		if idx < 0 {
+			return nil, fmt.Errorf("category %q doesn't exist, available categories: %s", name, CategoriesToString(available))
+		}
+		if !slices.Contains(result, available[idx]) {
+			result = append(result, available[idx])
+		}
+	}
+	return result, nil
+}
+
+// FilterEventsByCategories returns the events that have at least one of the categories, matched case-insensitively.
+// Without categories, all events are returned.
+func FilterEventsByCategories(events []models.Eventable, categories []string) []models.Eventable {
+	if len(categories) == 0 {
+		return events
+	}
+
+	var result []models.Eventable
+	for _, event := range events {
+		if slices.ContainsFunc(event.GetCategories(), func(c string) bool {
+			return slices.ContainsFunc(categories, func(category string) bool {
+				return strings.EqualFold(c, strings.TrimSpace(category))
+			})
+		}) {
+			result = append(result, event)
+		}
+	}
+	return result
+}
+
+// CategoriesToString returns the categories as a comma-separated list.
+func CategoriesToString(categories []string) string {
+	if len(categories) == 0 {
+		return "(none)"
+	}
+	return strings.Join(categories, ", ")
+}
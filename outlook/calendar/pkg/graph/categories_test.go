@@ -0,0 +1,50 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchCategories(t *testing.T) {
+	available := []string{"Project Apollo", "Blue category", "Travel"}
+
+	matched, err := MatchCategories(available, []string{" project apollo", "TRAVEL", "", "Project Apollo"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Project Apollo", "Travel"}, matched)
+
+	matched, err = MatchCategories(available, []string{" "})
+	require.NoError(t, err)
+	assert.Empty(t, matched)
+
+	_, err = MatchCategories(available, []string{"Travel", "Project Gemini"})
+	assert.ErrorContains(t, err, `"Project Gemini"`)
+	assert.ErrorContains(t, err, "Project Apollo, Blue category, Travel")
+}
+
+func TestFilterEventsByCategories(t *testing.T) {
+	newEvent := func(id string, categories ...string) models.Eventable {
+		event := models.NewEvent()
+		event.SetId(util.Ptr(id))
+		event.SetCategories(categories)
+		return event
+	}
+	events := []models.Eventable{
+		newEvent("apollo", "Project Apollo"),
+		newEvent("both", "Travel", "Project Apollo"),
+		newEvent("travel", "Travel"),
+		newEvent("none"),
+	}
+
+	ids := func(events []models.Eventable) []string {
+		return util.Map(events, func(e models.Eventable) string { return util.Deref(e.GetId()) })
+	}
+
+	assert.Equal(t, []string{"apollo", "both", "travel", "none"}, ids(FilterEventsByCategories(events, nil)))
+	assert.Equal(t, []string{"apollo", "both"}, ids(FilterEventsByCategories(events, []string{"project apollo"})))
+	assert.Equal(t, []string{"both", "travel"}, ids(FilterEventsByCategories(events, []string{"Gemini", " Travel"})))
+	assert.Empty(t, FilterEventsByCategories(events, []string{"Gemini"}))
+}
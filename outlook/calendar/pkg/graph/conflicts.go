@@ -0,0 +1,257 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	"github.com/gptscript-ai/tools/outlook/common/pagination"
+	abstractions "github.com/microsoft/kiota-abstractions-go"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+// Conflict is an existing event (or busy time of an attendee) that overlaps with a new event.
+type Conflict struct {
+	// Who is busy: "organizer" or the email address of the attendee
+	Participant string    `json:"participant"`
+	EventID     string    `json:"eventId,omitempty"` // only known for the organizer's own events
+	Subject     string    `json:"subject,omitempty"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	ShowAs      string    `json:"showAs"`
+}
+
+type ConflictReport struct {
+	Start     time.Time  `json:"start"`
+	End       time.Time  `json:"end"`
+	Conflicts []Conflict `json:"conflicts"`
+	// Attendees whose availability could not be determined, e.g. because they are external
+	UnknownAvailability map[string]string `json:"unknownAvailability,omitempty"`
+}
+
+const organizer = "organizer"
+
+// conflictFields are the properties of the events that are checked for conflicts
+var conflictFields = []string{"id", "subject", "start", "end", "showAs", "isCancelled"}
+
+// utcHeaders makes Graph return the start and end of events and schedule items in UTC, instead of the time zone
+// they were created in, which may be a Windows time zone name
+func utcHeaders() *abstractions.RequestHeaders {
+	return timeZoneHeaders("UTC")
+}
+
+// timeZoneHeaders makes Graph return the start and end of events in the time zone. Without a time zone, no header is
+// set and Graph returns them in UTC.
+func timeZoneHeaders(timeZone string) *abstractions.RequestHeaders {
+	headers := abstractions.NewRequestHeaders()
+	if timeZone != "" {
+		headers.Add("Prefer", fmt.Sprintf("outlook.timezone=%q", timeZone))
+	}
+	return headers
+}
+
+// FindConflicts checks the organizer's default calendar (and the target calendar of the event, if set) for events
+// overlapping with the new event, and optionally the free/busy schedules of the attendees.
+// For recurring events, only the first occurrence is checked.
+func FindConflicts(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, info CreateEventInfo, checkAttendees bool) (ConflictReport, error) {
+	report := ConflictReport{
+		Start: info.Start,
+		End:   info.End,
+	}
+
+	headers := utcHeaders()
+	events, err := pagination.New(
+		func(ctx context.Context, q pagination.Query) (models.EventCollectionResponseable, error) {
+			return client.Me().CalendarView().Get(ctx, &users.ItemCalendarViewRequestBuilderGetRequestConfiguration{
+				Headers: headers,
+				QueryParameters: &users.ItemCalendarViewRequestBuilderGetQueryParameters{
+					StartDateTime: util.Ptr(info.Start.UTC().Format(time.RFC3339)),
+					EndDateTime:   util.Ptr(info.End.UTC().Format(time.RFC3339)),
+					Top:           q.Top,
+					Select:        q.Select,
+				},
+			})
+		},
+		func(ctx context.Context, nextLink string) (models.EventCollectionResponseable, error) {
+			return client.Me().CalendarView().WithUrl(nextLink).Get(ctx, &users.ItemCalendarViewRequestBuilderGetRequestConfiguration{
+				Headers: headers,
+			})
+		},
+	).WithSelect(conflictFields...).Collect(ctx)
+	if err != nil {
+		return report, fmt.Errorf("failed to list calendar view: %w", err)
+	}
+
+	if info.ID != "" {
+		calendarEvents, err := ListCalendarView(ctx, client, info.ID, info.Owner, &info.Start, &info.End, "UTC", conflictFields)
+		if err != nil {
+			return report, err
+		}
+		events = append(events, calendarEvents...)
+	}
+
+	seen := map[string]struct{}{}
+	for _, event := range events {
+		eventID := util.Deref(event.GetId())
+		if _, ok := seen[eventID]; ok {
+			continue
+		}
+		seen[eventID] = struct{}{}
+
+		if util.Deref(event.GetIsCancelled()) || showAs(event.GetShowAs()) == "free" {
+			continue
+		}
+
+		start, err := parseDateTimeTimeZone(event.GetStart())
+		if err != nil {
+			return report, err
+		}
+		end, err := parseDateTimeTimeZone(event.GetEnd())
+		if err != nil {
+			return report, err
+		}
+		if !overlaps(start, end, info.Start, info.End) {
+			continue
+		}
+
+		report.Conflicts = append(report.Conflicts, Conflict{
+			Participant: organizer,
+			EventID:     eventID,
+			Subject:     util.Deref(event.GetSubject()),
+			Start:       start,
+			End:         end,
+			ShowAs:      showAs(event.GetShowAs()),
+		})
+	}
+
+	if checkAttendees {
+		if err := findAttendeeConflicts(ctx, client, info, &report); err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+func findAttendeeConflicts(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, info CreateEventInfo, report *ConflictReport) error {
+	var attendees []string
+	for _, a := range info.Attendees {
+		if a = strings.TrimSpace(a); a != "" {
+			attendees = append(attendees, a)
+		}
+	}
+	if len(attendees) == 0 {
+		return nil
+	}
+
+	schedules, err := GetSchedules(ctx, client, attendees, info.Start, info.End)
+	if err != nil {
+		return fmt.Errorf("failed to get attendee schedules: %w", err)
+	}
+
+	for _, schedule := range schedules {
+		if schedule.Error != "" {
+			if report.UnknownAvailability == nil {
+				report.UnknownAvailability = map[string]string{}
+			}
+			report.UnknownAvailability[schedule.Email] = schedule.Error
+			continue
+		}
+
+		for _, block := range schedule.Busy {
+			if !overlaps(block.Start, block.End, info.Start, info.End) {
+				continue
+			}
+
+			report.Conflicts = append(report.Conflicts, Conflict{
+				Participant: schedule.Email,
+				Subject:     block.Subject,
+				Start:       block.Start,
+				End:         block.End,
+				ShowAs:      block.Status,
+			})
+		}
+	}
+
+	return nil
+}
+
+func overlaps(aStart, aEnd, bStart, bEnd time.Time) bool {
+	return aStart.Before(bEnd) && aEnd.After(bStart)
+}
+
+func showAs(s *models.FreeBusyStatus) string {
+	if s == nil {
+		return "busy"
+	}
+	return s.String()
+}
+
+func toDateTimeTimeZone(t time.Time) models.DateTimeTimeZoneable {
+	dt := models.NewDateTimeTimeZone()
+	dt.SetDateTime(util.Ptr(t.UTC().Format("2006-01-02T15:04:05")))
+	dt.SetTimeZone(util.Ptr("UTC"))
+	return dt
+}
+
+// toEventDateTimeTimeZone returns the point in time in the time zone of a date time of an event, e.g. its start, so
+// that changing the time doesn't move the event to UTC and a recurring series keeps its local time across daylight
+// saving time. Graph returns the times of events in UTC unless another time zone is preferred, so the time zone the
+// event was created in is used then.
+func toEventDateTimeTimeZone(t time.Time, existing models.DateTimeTimeZoneable, originalTimeZone *string) (models.DateTimeTimeZoneable, error) {
+	if existing == nil {
+		return toDateTimeTimeZone(t), nil
+	}
+	tz := util.Deref(existing.GetTimeZone())
+	if original := util.Deref(originalTimeZone); (tz == "" || tz == "UTC") && original != "" {
+		// The original time zone can be a custom one, e.g. tzone://Microsoft/Custom, which stays in UTC
+		if _, err := LoadLocation(original); err == nil {
+			tz = original
+		}
+	}
+	if tz == "" || tz == "UTC" {
+		return toDateTimeTimeZone(t), nil
+	}
+
+	// Parsing the existing date time in its time zone resolves Windows time zone names
+	zoned := models.NewDateTimeTimeZone()
+	zoned.SetDateTime(existing.GetDateTime())
+	zoned.SetTimeZone(&tz)
+	current, err := parseDateTimeTimeZone(zoned)
+	if err != nil {
+		return nil, err
+	}
+
+	dt := models.NewDateTimeTimeZone()
+	dt.SetDateTime(util.Ptr(t.In(current.Location()).Format("2006-01-02T15:04:05")))
+	dt.SetTimeZone(util.Ptr(tz))
+	return dt, nil
+}
+
+// parseDateTimeTimeZone parses the Graph representation of a point in time, e.g. "2024-10-01T09:00:00.0000000" in "UTC".
+// The time zone is an IANA or a Windows time zone name (e.g. "Pacific Standard Time"), as events of other calendars
+// are listed in the time zone they were created in.
+func parseDateTimeTimeZone(dt models.DateTimeTimeZoneable) (time.Time, error) {
+	if dt == nil {
+		return time.Time{}, fmt.Errorf("missing date time")
+	}
+
+	loc := time.UTC
+	if tz := util.Deref(dt.GetTimeZone()); tz != "" && tz != "UTC" {
+		l, err := LoadLocation(tz)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("unknown time zone %q of date time %q: %w", tz, util.Deref(dt.GetDateTime()), err)
+		}
+		loc = l
+	}
+
+	t, err := time.ParseInLocation("2006-01-02T15:04:05.9999999", util.Deref(dt.GetDateTime()), loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse date time %q: %w", util.Deref(dt.GetDateTime()), err)
+	}
+	return t, nil
+}
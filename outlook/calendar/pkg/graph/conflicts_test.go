@@ -0,0 +1,62 @@
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dateTimeTimeZone(dateTime, timeZone string) models.DateTimeTimeZoneable {
+	dt := models.NewDateTimeTimeZone()
+	dt.SetDateTime(util.Ptr(dateTime))
+	if timeZone != "" {
+		dt.SetTimeZone(util.Ptr(timeZone))
+	}
+	return dt
+}
+
+func TestParseDateTimeTimeZone(t *testing.T) {
+	for _, tc := range []struct {
+		name, dateTime, timeZone string
+		want                     time.Time
+	}{
+		{"UTC", "2024-10-01T09:00:00.0000000", "UTC", time.Date(2024, 10, 1, 9, 0, 0, 0, time.UTC)},
+		{"no time zone", "2024-10-01T09:00:00", "", time.Date(2024, 10, 1, 9, 0, 0, 0, time.UTC)},
+		{"IANA", "2024-10-01T09:00:00.0000000", "Europe/Berlin", time.Date(2024, 10, 1, 7, 0, 0, 0, time.UTC)},
+		{"Windows", "2024-10-01T09:00:00.0000000", "Pacific Standard Time", time.Date(2024, 10, 1, 16, 0, 0, 0, time.UTC)},
+		{"Windows in winter", "2024-12-01T09:00:00.0000000", "W. Europe Standard Time", time.Date(2024, 12, 1, 8, 0, 0, 0, time.UTC)},
+		{"Windows with half hour offset", "2024-10-01T09:00:00.0000000", "India Standard Time", time.Date(2024, 10, 1, 3, 30, 0, 0, time.UTC)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseDateTimeTimeZone(dateTimeTimeZone(tc.dateTime, tc.timeZone))
+			require.NoError(t, err)
+			assert.True(t, tc.want.Equal(got), "got %s, want %s", got.UTC(), tc.want)
+		})
+	}
+
+	_, err := parseDateTimeTimeZone(dateTimeTimeZone("2024-10-01T09:00:00", "Atlantis Standard Time"))
+	assert.ErrorContains(t, err, `unknown time zone "Atlantis Standard Time"`)
+
+	_, err = parseDateTimeTimeZone(nil)
+	assert.Error(t, err)
+}
+
+func TestWindowsTimeZonesLoad(t *testing.T) {
+	for windows, iana := range windowsTimeZones {
+		_, err := time.LoadLocation(iana)
+		assert.NoError(t, err, "time zone %q", windows)
+	}
+}
+
+func TestOverlaps(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2024, 10, 1, hour, 0, 0, 0, time.UTC) }
+
+	assert.True(t, overlaps(at(9), at(11), at(10), at(12)))
+	assert.True(t, overlaps(at(9), at(12), at(10), at(11)))
+	assert.False(t, overlaps(at(9), at(10), at(10), at(11)), "back to back events don't overlap")
+	assert.False(t, overlaps(at(12), at(13), at(10), at(11)))
+}
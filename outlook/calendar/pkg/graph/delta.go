@@ -0,0 +1,178 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	"github.com/gptscript-ai/tools/outlook/common/pagination"
+	abstractions "github.com/microsoft/kiota-abstractions-go"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+const (
+	ChangeExisting = "existing"
+	ChangeCreated  = "created"
+	ChangeUpdated  = "updated"
+	ChangeDeleted  = "deleted"
+)
+
+// EventDelta holds the changes of the events of a calendar since the last delta query.
+type EventDelta struct {
+	// Events are the new and changed events. Recurring events are expanded into their occurrences.
+	Events []models.Eventable
+	// RemovedIDs are the IDs of the events that were deleted or moved out of the time frame
+	RemovedIDs []string
+	// DeltaLink is the link to get the changes after this query
+	DeltaLink string
+}
+
+// EventChange is a change of an event since the last delta query.
+type EventChange struct {
+	// Change is created, updated or deleted, or existing for the events returned by the first query
+	Change      string     `json:"change"`
+	ID          string     `json:"id"`
+	Subject     string     `json:"subject,omitempty"`
+	Start       *time.Time `json:"start,omitempty"`
+	End         *time.Time `json:"end,omitempty"`
+	Location    string     `json:"location,omitempty"`
+	Organizer   string     `json:"organizer,omitempty"`
+	IsCancelled bool       `json:"isCancelled,omitempty"`
+	ModifiedAt  *time.Time `json:"modifiedAt,omitempty"`
+}
+
+// deltaPage is a page of the delta query of a calendar view, which has a different type for each calendar owner.
+type deltaPage interface {
+	GetValue() []models.Eventable
+	GetOdataNextLink() *string
+	GetOdataDeltaLink() *string
+}
+
+// GetEventDelta returns the changes of the events of the calendar since the query that returned deltaLink. Without a
+// delta link, it returns the events between start and end, and the delta link for the following queries, which keep
+// tracking the same time frame. Group calendars don't support delta queries.
+func GetEventDelta(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, id string, owner OwnerType, deltaLink string, start, end time.Time) (*EventDelta, error) {
+	headers := abstractions.NewRequestHeaders()
+	headers.Add("Prefer", "odata.maxpagesize=100")
+	startDateTime := util.Ptr(start.UTC().Format(time.RFC3339))
+	endDateTime := util.Ptr(end.UTC().Format(time.RFC3339))
+
+	var getFirst, getNext func() (deltaPage, error)
+	var link string
+	switch owner {
+	case OwnerTypeUser:
+		delta := client.Me().Calendars().ByCalendarId(id).CalendarView().Delta()
+		getFirst = func() (deltaPage, error) {
+			return delta.GetAsDeltaGetResponse(ctx, &users.ItemCalendarsItemCalendarViewDeltaRequestBuilderGetRequestConfiguration{
+				Headers: headers,
+				QueryParameters: &users.ItemCalendarsItemCalendarViewDeltaRequestBuilderGetQueryParameters{
+					EndDateTime:   endDateTime,
+					StartDateTime: startDateTime,
+				},
+			})
+		}
+		getNext = func() (deltaPage, error) {
+			return delta.WithUrl(link).GetAsDeltaGetResponse(ctx, &users.ItemCalendarsItemCalendarViewDeltaRequestBuilderGetRequestConfiguration{
+				Headers: headers,
+			})
+		}
+	case OwnerTypeShared:
+		// The calendar view of another user is their default calendar
+		delta := client.Users().ByUserId(id).CalendarView().Delta()
+		getFirst = func() (deltaPage, error) {
+			return delta.GetAsDeltaGetResponse(ctx, &users.ItemCalendarViewDeltaRequestBuilderGetRequestConfiguration{
+				Headers: headers,
+				QueryParameters: &users.ItemCalendarViewDeltaRequestBuilderGetQueryParameters{
+					EndDateTime:   endDateTime,
+					StartDateTime: startDateTime,
+				},
+			})
+		}
+		getNext = func() (deltaPage, error) {
+			return delta.WithUrl(link).GetAsDeltaGetResponse(ctx, &users.ItemCalendarViewDeltaRequestBuilderGetRequestConfiguration{
+				Headers: headers,
+			})
+		}
+	default:
+		return nil, fmt.Errorf("tracking changes is not supported for group calendars")
+	}
+
+	var (
+		page deltaPage
+		err  error
+	)
+	if deltaLink != "" {
+		link = deltaLink
+		page, err = pagination.WithRetry(ctx, getNext)
+	} else {
+		page, err = pagination.WithRetry(ctx, getFirst)
+	}
+
+	result := &EventDelta{}
+	for {
+		if err != nil {
+			return nil, fmt.Errorf("failed to get calendar changes: %w", err)
+		}
+
+		for _, event := range page.GetValue() {
+			if _, removed := event.GetAdditionalData()["@removed"]; removed {
+				result.RemovedIDs = append(result.RemovedIDs, util.Deref(event.GetId()))
+				continue
+			}
+			result.Events = append(result.Events, event)
+		}
+
+		if nextLink := util.Deref(page.GetOdataNextLink()); nextLink != "" {
+			link = nextLink
+			page, err = pagination.WithRetry(ctx, getNext)
+			continue
+		}
+
+		result.DeltaLink = util.Deref(page.GetOdataDeltaLink())
+		return result, nil
+	}
+}
+
+// EventChanges returns the changes of the delta. Events created after the previous query are created, other events
+// are updated. Without a previous query (a zero time), all events are existing.
+func EventChanges(delta *EventDelta, previousQuery time.Time) []EventChange {
+	changes := make([]EventChange, 0, len(delta.Events)+len(delta.RemovedIDs))
+	for _, event := range delta.Events {
+		change := EventChange{
+			Change:      ChangeUpdated,
+			ID:          util.Deref(event.GetId()),
+			Subject:     util.Deref(event.GetSubject()),
+			IsCancelled: util.Deref(event.GetIsCancelled()),
+			ModifiedAt:  event.GetLastModifiedDateTime(),
+		}
+		switch {
+		case previousQuery.IsZero():
+			change.Change = ChangeExisting
+		case event.GetCreatedDateTime() != nil && event.GetCreatedDateTime().After(previousQuery):
+			change.Change = ChangeCreated
+		}
+		if start, err := parseDateTimeTimeZone(event.GetStart()); err == nil {
+			change.Start = &start
+		}
+		if end, err := parseDateTimeTimeZone(event.GetEnd()); err == nil {
+			change.End = &end
+		}
+		if location := event.GetLocation(); location != nil {
+			change.Location = util.Deref(location.GetDisplayName())
+		}
+		if organizer := event.GetOrganizer(); organizer != nil && organizer.GetEmailAddress() != nil {
+			change.Organizer = util.Deref(organizer.GetEmailAddress().GetAddress())
+		}
+		changes = append(changes, change)
+	}
+	for _, id := range delta.RemovedIDs {
+		changes = append(changes, EventChange{
+			Change: ChangeDeleted,
+			ID:     id,
+		})
+	}
+	return changes
+}
@@ -0,0 +1,68 @@
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventChanges(t *testing.T) {
+	previousQuery := time.Date(2024, 11, 4, 12, 0, 0, 0, time.UTC)
+
+	newEvent := func(id string, created time.Time) models.Eventable {
+		event := models.NewEvent()
+		event.SetId(util.Ptr(id))
+		event.SetSubject(util.Ptr("Subject of " + id))
+		event.SetCreatedDateTime(&created)
+		event.SetLastModifiedDateTime(util.Ptr(previousQuery.Add(time.Hour)))
+		event.SetStart(dateTimeTimeZone("2024-11-05T09:00:00.0000000", "UTC"))
+		event.SetEnd(dateTimeTimeZone("2024-11-05T10:00:00.0000000", "UTC"))
+		return event
+	}
+
+	cancelled := newEvent("cancelled", previousQuery.Add(-24*time.Hour))
+	cancelled.SetIsCancelled(util.Ptr(true))
+	location := models.NewLocation()
+	location.SetDisplayName(util.Ptr("Everest"))
+	cancelled.SetLocation(location)
+	organizer := models.NewRecipient()
+	email := models.NewEmailAddress()
+	email.SetAddress(util.Ptr("adele@contoso.com"))
+	organizer.SetEmailAddress(email)
+	cancelled.SetOrganizer(organizer)
+
+	delta := &EventDelta{
+		Events: []models.Eventable{
+			newEvent("new", previousQuery.Add(time.Minute)),
+			newEvent("changed", previousQuery.Add(-time.Minute)),
+			cancelled,
+		},
+		RemovedIDs: []string{"removed"},
+	}
+
+	changes := EventChanges(delta, previousQuery)
+	require.Len(t, changes, 4)
+	assert.Equal(t, []string{ChangeCreated, ChangeUpdated, ChangeUpdated, ChangeDeleted}, util.Map(changes, func(c EventChange) string {
+		return c.Change
+	}))
+
+	assert.Equal(t, "Subject of new", changes[0].Subject)
+	assert.Equal(t, time.Date(2024, 11, 5, 9, 0, 0, 0, time.UTC), util.Deref(changes[0].Start))
+	assert.Equal(t, time.Date(2024, 11, 5, 10, 0, 0, 0, time.UTC), util.Deref(changes[0].End))
+	assert.Equal(t, previousQuery.Add(time.Hour), util.Deref(changes[0].ModifiedAt))
+
+	assert.True(t, changes[2].IsCancelled)
+	assert.Equal(t, "Everest", changes[2].Location)
+	assert.Equal(t, "adele@contoso.com", changes[2].Organizer)
+
+	assert.Equal(t, EventChange{Change: ChangeDeleted, ID: "removed"}, changes[3])
+
+	// Without a previous query, the events are the initial state of the calendar
+	for _, c := range EventChanges(&EventDelta{Events: delta.Events}, time.Time{}) {
+		assert.Equal(t, ChangeExisting, c.Change)
+	}
+}
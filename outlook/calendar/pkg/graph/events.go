@@ -3,10 +3,13 @@ package graph
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/gptscript-ai/tools/outlook/calendar/pkg/recurrence"
 	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	"github.com/gptscript-ai/tools/outlook/common/batch"
+	abstractions "github.com/microsoft/kiota-abstractions-go"
 	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
 	"github.com/microsoftgraph/msgraph-sdk-go/groups"
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
@@ -15,10 +18,16 @@ import (
 
 type CreateEventInfo struct {
 	Attendees                               []string // slice of email addresses
+	Rooms                                   []string // email addresses of rooms, added as resource attendees
 	Subject, Location, Body, ID, Recurrence string
 	Owner                                   OwnerType
-	IsOnline                                bool
+	IsOnline, IsAllDay                      bool
 	Start, End                              time.Time
+	OnlineMeetingProvider                   string   // Teams if unset
+	RRule                                   string   // iCalendar recurrence rule, used instead of Recurrence
+	IsReminderOn                            *bool    // Outlook's default if nil
+	ReminderMinutesBeforeStart              *int32   // Outlook's default if nil
+	Categories                              []string // names of master categories
 }
 
 func GetEvent(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, eventID, calendarID string, owner OwnerType) (models.Eventable, error) {
@@ -49,6 +58,18 @@ func GetEvent(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, even
 				return nil, fmt.Errorf("failed to get event: %w", err)
 			}
 			return resp, nil
+		case OwnerTypeShared:
+			requestParameters := &users.ItemCalendarEventsEventItemRequestBuilderGetQueryParameters{
+				Expand: expand,
+			}
+			configuration := &users.ItemCalendarEventsEventItemRequestBuilderGetRequestConfiguration{
+				QueryParameters: requestParameters,
+			}
+			resp, err := client.Users().ByUserId(calendarID).Calendar().Events().ByEventId(eventID).Get(ctx, configuration)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get event: %w", err)
+			}
+			return resp, nil
 		}
 	}
 	requestParameters := &users.ItemEventsEventItemRequestBuilderGetQueryParameters{
@@ -80,6 +101,18 @@ func CreateEvent(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, i
 			return nil, fmt.Errorf("failed to convert recurrence for Graph API: %w", err)
 		}
 
+		requestBody.SetRecurrence(graphRecurrence)
+	} else if info.RRule != "" {
+		r, err := recurrence.FromRRule(info.RRule, info.Start)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert recurrence rule: %w", err)
+		}
+
+		graphRecurrence, err := r.ConvertForGraphAPI()
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert recurrence for Graph API: %w", err)
+		}
+
 		requestBody.SetRecurrence(graphRecurrence)
 	}
 
@@ -91,9 +124,13 @@ func CreateEvent(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, i
 		attendee.SetEmailAddress(email)
 		attendees = append(attendees, attendee)
 	}
+	attendees = append(attendees, roomAttendees(info.Rooms)...)
 	requestBody.SetAttendees(attendees)
 
 	requestBody.SetSubject(&info.Subject)
+	if len(info.Categories) > 0 {
+		requestBody.SetCategories(info.Categories)
+	}
 
 	location := models.NewLocation()
 	location.SetDisplayName(&info.Location)
@@ -105,6 +142,13 @@ func CreateEvent(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, i
 	requestBody.SetBody(body)
 
 	requestBody.SetIsOnlineMeeting(&info.IsOnline)
+	if info.IsOnline {
+		provider, err := ParseOnlineMeetingProvider(info.OnlineMeetingProvider)
+		if err != nil {
+			return nil, err
+		}
+		requestBody.SetOnlineMeetingProvider(&provider)
+	}
 
 	start := models.NewDateTimeTimeZone()
 	start.SetDateTime(util.Ptr(info.Start.UTC().Format(time.RFC3339)))
@@ -116,6 +160,15 @@ func CreateEvent(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, i
 	end.SetTimeZone(util.Ptr("UTC"))
 	requestBody.SetEnd(end)
 
+	setReminder(requestBody, info.IsReminderOn, info.ReminderMinutesBeforeStart)
+
+	if info.IsAllDay {
+		// All day events start and end at midnight, the end is the day after the last day
+		requestBody.SetIsAllDay(util.Ptr(true))
+		start.SetDateTime(util.Ptr(info.Start.Format(time.DateOnly) + "T00:00:00"))
+		end.SetDateTime(util.Ptr(info.End.Format(time.DateOnly) + "T00:00:00"))
+	}
+
 	if info.ID != "" {
 		switch info.Owner {
 		case OwnerTypeUser:
@@ -130,8 +183,14 @@ func CreateEvent(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, i
 				return nil, fmt.Errorf("failed to create event: %w", err)
 			}
 			return event, nil
+		case OwnerTypeShared:
+			event, err := client.Users().ByUserId(info.ID).Calendar().Events().Post(ctx, requestBody, nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create event: %w", err)
+			}
+			return event, nil
 		default:
-			return nil, fmt.Errorf("invalid owner type: %s (possible values are \"user\" and \"group\")", info.Owner)
+			return nil, fmt.Errorf("invalid owner type: %s (possible values are \"user\", \"group\" and \"shared\")", info.Owner)
 		}
 	}
 
@@ -197,9 +256,12 @@ func DeleteEvent(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, e
 	return nil
 }
 
-func AcceptEvent(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, eventID, calendarID string, owner OwnerType) error {
+func AcceptEvent(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, eventID, calendarID string, owner OwnerType, comment string, sendResponse bool) error {
 	requestBody := users.NewItemEventsItemAcceptPostRequestBody()
-	requestBody.SetSendResponse(util.Ptr(true))
+	requestBody.SetSendResponse(util.Ptr(sendResponse))
+	if comment != "" {
+		requestBody.SetComment(util.Ptr(comment))
+	}
 
 	if calendarID != "" {
 		switch owner {
@@ -222,9 +284,12 @@ func AcceptEvent(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, e
 	return nil
 }
 
-func TentativelyAcceptEvent(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, eventID, calendarID string, owner OwnerType) error {
+func TentativelyAcceptEvent(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, eventID, calendarID string, owner OwnerType, comment string, sendResponse bool) error {
 	requestBody := users.NewItemEventsItemTentativelyAcceptPostRequestBody()
-	requestBody.SetSendResponse(util.Ptr(true))
+	requestBody.SetSendResponse(util.Ptr(sendResponse))
+	if comment != "" {
+		requestBody.SetComment(util.Ptr(comment))
+	}
 
 	if calendarID != "" {
 		switch owner {
@@ -247,9 +312,12 @@ func TentativelyAcceptEvent(ctx context.Context, client *msgraphsdkgo.GraphServi
 	return nil
 }
 
-func DeclineEvent(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, eventID, calendarID string, owner OwnerType) error {
+func DeclineEvent(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, eventID, calendarID string, owner OwnerType, comment string, sendResponse bool) error {
 	requestBody := users.NewItemEventsItemDeclinePostRequestBody()
-	requestBody.SetSendResponse(util.Ptr(true))
+	requestBody.SetSendResponse(util.Ptr(sendResponse))
+	if comment != "" {
+		requestBody.SetComment(util.Ptr(comment))
+	}
 
 	if calendarID != "" {
 		switch owner {
@@ -271,3 +339,77 @@ func DeclineEvent(ctx context.Context, client *msgraphsdkgo.GraphServiceClient,
 	}
 	return nil
 }
+
+// RespondToEvents accepts, tentatively accepts or declines the invitations to the events of the user's calendars using
+// JSON batches, and returns the error for each event, which is nil if the response was sent.
+func RespondToEvents(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, eventIDs []string, response, comment string, sendResponse bool) ([]error, error) {
+	requests := make([]*abstractions.RequestInformation, 0, len(eventIDs))
+	for _, eventID := range eventIDs {
+		event := client.Me().Events().ByEventId(eventID)
+
+		var (
+			requestInfo *abstractions.RequestInformation
+			err         error
+		)
+		switch response {
+		case "accept":
+			requestBody := users.NewItemEventsItemAcceptPostRequestBody()
+			requestBody.SetSendResponse(util.Ptr(sendResponse))
+			if comment != "" {
+				requestBody.SetComment(util.Ptr(comment))
+			}
+			requestInfo, err = event.Accept().ToPostRequestInformation(ctx, requestBody, nil)
+		case "tentative", "tentativelyAccept":
+			requestBody := users.NewItemEventsItemTentativelyAcceptPostRequestBody()
+			requestBody.SetSendResponse(util.Ptr(sendResponse))
+			if comment != "" {
+				requestBody.SetComment(util.Ptr(comment))
+			}
+			requestInfo, err = event.TentativelyAccept().ToPostRequestInformation(ctx, requestBody, nil)
+		case "decline":
+			requestBody := users.NewItemEventsItemDeclinePostRequestBody()
+			requestBody.SetSendResponse(util.Ptr(sendResponse))
+			if comment != "" {
+				requestBody.SetComment(util.Ptr(comment))
+			}
+			requestInfo, err = event.Decline().ToPostRequestInformation(ctx, requestBody, nil)
+		default:
+			return nil, fmt.Errorf("invalid response: %s", response)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to build response request: %w", err)
+		}
+		requests = append(requests, requestInfo)
+	}
+
+	results, err := batch.SendAll(ctx, client, requests)
+	if err != nil {
+		return nil, fmt.Errorf("failed to respond to events: %w", err)
+	}
+
+	return util.Map(results, func(result batch.Result) error {
+		return result.Err
+	}), nil
+}
+
+// ParseOnlineMeetingProvider parses the name of the service hosting an online meeting, which defaults to Teams.
+func ParseOnlineMeetingProvider(provider string) (models.OnlineMeetingProviderType, error) {
+	switch strings.ToLower(strings.TrimSpace(provider)) {
+	case "", "teams", "teamsforbusiness":
+		return models.TEAMSFORBUSINESS_ONLINEMEETINGPROVIDERTYPE, nil
+	case "skypeforbusiness":
+		return models.SKYPEFORBUSINESS_ONLINEMEETINGPROVIDERTYPE, nil
+	case "skypeforconsumer":
+		return models.SKYPEFORCONSUMER_ONLINEMEETINGPROVIDERTYPE, nil
+	default:
+		return models.UNKNOWN_ONLINEMEETINGPROVIDERTYPE, fmt.Errorf("invalid online meeting provider %q, must be teamsForBusiness, skypeForBusiness or skypeForConsumer", provider)
+	}
+}
+
+// JoinURL returns the URL to join the online meeting of the event, or an empty string if it has none.
+func JoinURL(event models.Eventable) string {
+	if meeting := event.GetOnlineMeeting(); meeting != nil && util.Deref(meeting.GetJoinUrl()) != "" {
+		return util.Deref(meeting.GetJoinUrl())
+	}
+	return util.Deref(event.GetOnlineMeetingUrl())
+}
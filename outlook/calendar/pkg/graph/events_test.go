@@ -0,0 +1,25 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOnlineMeetingProvider(t *testing.T) {
+	for provider, expected := range map[string]models.OnlineMeetingProviderType{
+		"":                 models.TEAMSFORBUSINESS_ONLINEMEETINGPROVIDERTYPE,
+		"teams":            models.TEAMSFORBUSINESS_ONLINEMEETINGPROVIDERTYPE,
+		"teamsForBusiness": models.TEAMSFORBUSINESS_ONLINEMEETINGPROVIDERTYPE,
+		"skypeForBusiness": models.SKYPEFORBUSINESS_ONLINEMEETINGPROVIDERTYPE,
+		"SkypeForConsumer": models.SKYPEFORCONSUMER_ONLINEMEETINGPROVIDERTYPE,
+	} {
+		actual, err := ParseOnlineMeetingProvider(provider)
+		assert.NoError(t, err, provider)
+		assert.Equal(t, expected, actual, provider)
+	}
+
+	_, err := ParseOnlineMeetingProvider("zoom")
+	assert.ErrorContains(t, err, `invalid online meeting provider "zoom"`)
+}
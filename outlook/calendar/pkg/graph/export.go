@@ -0,0 +1,109 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/ics"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/recurrence"
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	"github.com/jaytaylor/html2text"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// GetDefaultCalendar returns the default calendar of the user.
+func GetDefaultCalendar(ctx context.Context, client *msgraphsdkgo.GraphServiceClient) (CalendarInfo, error) {
+	resp, err := client.Me().Calendar().Get(ctx, nil)
+	if err != nil {
+		return CalendarInfo{}, fmt.Errorf("failed to get default calendar: %w", err)
+	}
+
+	return CalendarInfo{
+		Calendar: resp,
+		ID:       util.Deref(resp.GetId()),
+		Owner:    OwnerTypeUser,
+	}, nil
+}
+
+// ToICSEvent converts the event for an iCalendar file. Series masters get the recurrence rule of the series, while
+// occurrences are exported as single events.
+func ToICSEvent(event models.Eventable) (ics.Event, error) {
+	start, err := parseDateTimeTimeZone(event.GetStart())
+	if err != nil {
+		return ics.Event{}, err
+	}
+	end, err := parseDateTimeTimeZone(event.GetEnd())
+	if err != nil {
+		return ics.Event{}, err
+	}
+
+	result := ics.Event{
+		UID:       util.Deref(event.GetICalUId()),
+		Summary:   util.Deref(event.GetSubject()),
+		Start:     start,
+		End:       end,
+		AllDay:    util.Deref(event.GetIsAllDay()),
+		URL:       JoinURL(event),
+		Cancelled: util.Deref(event.GetIsCancelled()),
+	}
+	if result.UID == "" {
+		result.UID = util.Deref(event.GetId())
+	}
+	if location := event.GetLocation(); location != nil {
+		result.Location = util.Deref(location.GetDisplayName())
+	}
+	if body := event.GetBody(); body != nil {
+		result.Description = util.Deref(body.GetContent())
+		if body.GetContentType() != nil && *body.GetContentType() == models.HTML_BODYTYPE {
+			if text, err := html2text.FromString(result.Description, html2text.Options{}); err == nil {
+				result.Description = text
+			}
+		}
+		result.Description = strings.TrimSpace(result.Description)
+	}
+	if organizer := event.GetOrganizer(); organizer != nil && organizer.GetEmailAddress() != nil {
+		result.Organizer = &ics.Attendee{
+			Email: util.Deref(organizer.GetEmailAddress().GetAddress()),
+			Name:  util.Deref(organizer.GetEmailAddress().GetName()),
+		}
+	}
+	for _, a := range event.GetAttendees() {
+		if a.GetEmailAddress() == nil {
+			continue
+		}
+		attendee := ics.Attendee{
+			Email:    util.Deref(a.GetEmailAddress().GetAddress()),
+			Name:     util.Deref(a.GetEmailAddress().GetName()),
+			Optional: a.GetTypeEscaped() != nil && *a.GetTypeEscaped() == models.OPTIONAL_ATTENDEETYPE,
+		}
+		if status := a.GetStatus(); status != nil && status.GetResponse() != nil {
+			attendee.Status = participationStatus(*status.GetResponse())
+		}
+		result.Attendees = append(result.Attendees, attendee)
+	}
+
+	if event.GetTypeEscaped() != nil && *event.GetTypeEscaped() == models.SERIESMASTER_EVENTTYPE && event.GetRecurrence() != nil {
+		result.RRule, err = recurrence.FromGraph(event.GetRecurrence()).RRule(result.AllDay, start.Location())
+		if err != nil {
+			return ics.Event{}, fmt.Errorf("failed to convert the recurrence of event %q: %w", result.Summary, err)
+		}
+	}
+
+	return result, nil
+}
+
+// participationStatus converts the response of an attendee to the PARTSTAT of iCalendar.
+func participationStatus(response models.ResponseType) string {
+	switch response {
+	case models.ACCEPTED_RESPONSETYPE, models.ORGANIZER_RESPONSETYPE:
+		return "ACCEPTED"
+	case models.TENTATIVELYACCEPTED_RESPONSETYPE:
+		return "TENTATIVE"
+	case models.DECLINED_RESPONSETYPE:
+		return "DECLINED"
+	default:
+		return "NEEDS-ACTION"
+	}
+}
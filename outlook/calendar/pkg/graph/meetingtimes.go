@@ -0,0 +1,190 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	"github.com/microsoft/kiota-abstractions-go/serialization"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+// FindMeetingTimesInfo describes the meeting to find times for.
+type FindMeetingTimesInfo struct {
+	Attendees, OptionalAttendees []string
+	Duration                     time.Duration
+	// The time frame in which the meeting has to take place
+	Start, End time.Time
+	// "work" only suggests times during the working hours of the attendees, "personal" also includes evenings and
+	// weekends, "unrestricted" any time
+	ActivityDomain string
+	// Zero uses the default of Graph
+	MaxCandidates, MinimumAttendeePercentage int
+}
+
+// MeetingTimeSuggestion is a time at which the meeting could take place. Rank 1 is the best suggestion.
+type MeetingTimeSuggestion struct {
+	Rank                  int               `json:"rank"`
+	Start                 time.Time         `json:"start"`
+	End                   time.Time         `json:"end"`
+	Confidence            float64           `json:"confidence"`
+	OrganizerAvailability string            `json:"organizerAvailability"`
+	AttendeeAvailability  map[string]string `json:"attendeeAvailability,omitempty"`
+	Locations             []string          `json:"locations,omitempty"`
+	Reason                string            `json:"reason,omitempty"`
+}
+
+type MeetingTimes struct {
+	Suggestions []MeetingTimeSuggestion `json:"suggestions"`
+	// Why there are no suggestions, e.g. "attendeesUnavailable"
+	EmptySuggestionsReason string `json:"emptySuggestionsReason,omitempty"`
+}
+
+// FindMeetingTimes suggests times for a meeting with the attendees, based on their availability.
+func FindMeetingTimes(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, info FindMeetingTimesInfo) (MeetingTimes, error) {
+	if info.Duration <= 0 {
+		return MeetingTimes{}, fmt.Errorf("duration must be positive")
+	}
+	if !info.End.After(info.Start) {
+		return MeetingTimes{}, fmt.Errorf("end time must be after start time")
+	}
+
+	domain, err := parseActivityDomain(info.ActivityDomain)
+	if err != nil {
+		return MeetingTimes{}, err
+	}
+
+	var attendees []models.AttendeeBaseable
+	for _, list := range []struct {
+		emails       []string
+		attendeeType models.AttendeeType
+	}{
+		{info.Attendees, models.REQUIRED_ATTENDEETYPE},
+		{info.OptionalAttendees, models.OPTIONAL_ATTENDEETYPE},
+	} {
+		for _, email := range list.emails {
+			if email = strings.TrimSpace(email); email == "" {
+				continue
+			}
+			emailAddress := models.NewEmailAddress()
+			emailAddress.SetAddress(util.Ptr(email))
+			attendee := models.NewAttendeeBase()
+			attendee.SetEmailAddress(emailAddress)
+			attendee.SetTypeEscaped(util.Ptr(list.attendeeType))
+			attendees = append(attendees, attendee)
+		}
+	}
+
+	timeSlot := models.NewTimeSlot()
+	timeSlot.SetStart(toDateTimeTimeZone(info.Start))
+	timeSlot.SetEnd(toDateTimeTimeZone(info.End))
+	timeConstraint := models.NewTimeConstraint()
+	timeConstraint.SetActivityDomain(&domain)
+	timeConstraint.SetTimeSlots([]models.TimeSlotable{timeSlot})
+
+	requestBody := users.NewItemFindMeetingTimesPostRequestBody()
+	requestBody.SetAttendees(attendees)
+	requestBody.SetTimeConstraint(timeConstraint)
+	requestBody.SetMeetingDuration(isoDuration(info.Duration))
+	requestBody.SetReturnSuggestionReasons(util.Ptr(true))
+	if info.MaxCandidates > 0 {
+		requestBody.SetMaxCandidates(util.Ptr(int32(info.MaxCandidates)))
+	}
+	if info.MinimumAttendeePercentage > 0 {
+		requestBody.SetMinimumAttendeePercentage(util.Ptr(float64(info.MinimumAttendeePercentage)))
+	}
+
+	result, err := client.Me().FindMeetingTimes().Post(ctx, requestBody, &users.ItemFindMeetingTimesRequestBuilderPostRequestConfiguration{
+		Headers: utcHeaders(),
+	})
+	if err != nil {
+		return MeetingTimes{}, fmt.Errorf("failed to find meeting times: %w", err)
+	}
+
+	meetingTimes := MeetingTimes{
+		Suggestions:            []MeetingTimeSuggestion{},
+		EmptySuggestionsReason: util.Deref(result.GetEmptySuggestionsReason()),
+	}
+	for _, s := range result.GetMeetingTimeSuggestions() {
+		suggestion, err := meetingTimeSuggestion(s)
+		if err != nil {
+			return meetingTimes, err
+		}
+		meetingTimes.Suggestions = append(meetingTimes.Suggestions, suggestion)
+	}
+	rankSuggestions(meetingTimes.Suggestions)
+
+	return meetingTimes, nil
+}
+
+func meetingTimeSuggestion(s models.MeetingTimeSuggestionable) (MeetingTimeSuggestion, error) {
+	start, err := parseDateTimeTimeZone(s.GetMeetingTimeSlot().GetStart())
+	if err != nil {
+		return MeetingTimeSuggestion{}, err
+	}
+	end, err := parseDateTimeTimeZone(s.GetMeetingTimeSlot().GetEnd())
+	if err != nil {
+		return MeetingTimeSuggestion{}, err
+	}
+
+	suggestion := MeetingTimeSuggestion{
+		Start:                 start,
+		End:                   end,
+		Confidence:            util.Deref(s.GetConfidence()),
+		OrganizerAvailability: showAs(s.GetOrganizerAvailability()),
+		Reason:                util.Deref(s.GetSuggestionReason()),
+	}
+	for _, a := range s.GetAttendeeAvailability() {
+		if suggestion.AttendeeAvailability == nil {
+			suggestion.AttendeeAvailability = map[string]string{}
+		}
+		availability := "unknown"
+		if status := a.GetAvailability(); status != nil {
+			availability = status.String()
+		}
+		suggestion.AttendeeAvailability[util.Deref(a.GetAttendee().GetEmailAddress().GetAddress())] = availability
+	}
+	for _, location := range s.GetLocations() {
+		if name := util.Deref(location.GetDisplayName()); name != "" {
+			suggestion.Locations = append(suggestion.Locations, name)
+		}
+	}
+	return suggestion, nil
+}
+
+// rankSuggestions sorts the suggestions by confidence, and earlier times first if the confidence is the same.
+func rankSuggestions(suggestions []MeetingTimeSuggestion) {
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].Confidence != suggestions[j].Confidence {
+			return suggestions[i].Confidence > suggestions[j].Confidence
+		}
+		return suggestions[i].Start.Before(suggestions[j].Start)
+	})
+	for i := range suggestions {
+		suggestions[i].Rank = i + 1
+	}
+}
+
+func parseActivityDomain(domain string) (models.ActivityDomain, error) {
+	switch strings.ToLower(strings.TrimSpace(domain)) {
+	case "", "work":
+		return models.WORK_ACTIVITYDOMAIN, nil
+	case "personal":
+		return models.PERSONAL_ACTIVITYDOMAIN, nil
+	case "unrestricted":
+		return models.UNRESTRICTED_ACTIVITYDOMAIN, nil
+	default:
+		return models.UNKNOWN_ACTIVITYDOMAIN, fmt.Errorf("invalid activity domain %q, must be work, personal or unrestricted", domain)
+	}
+}
+
+// isoDuration converts the duration, rounded to seconds, to the ISO 8601 duration Graph expects, e.g. PT1H30M.
+func isoDuration(d time.Duration) *serialization.ISODuration {
+	seconds := int(d.Round(time.Second) / time.Second)
+	return serialization.NewDuration(0, 0, 0, seconds/3600, seconds%3600/60, seconds%60, 0)
+}
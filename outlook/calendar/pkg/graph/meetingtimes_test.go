@@ -0,0 +1,41 @@
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRankSuggestions(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2024, 10, 1, hour, 0, 0, 0, time.UTC) }
+
+	suggestions := []MeetingTimeSuggestion{
+		{Start: at(14), Confidence: 50},
+		{Start: at(11), Confidence: 100},
+		{Start: at(9), Confidence: 100},
+	}
+	rankSuggestions(suggestions)
+	assert.Equal(t, []MeetingTimeSuggestion{
+		{Rank: 1, Start: at(9), Confidence: 100},
+		{Rank: 2, Start: at(11), Confidence: 100},
+		{Rank: 3, Start: at(14), Confidence: 50},
+	}, suggestions)
+}
+
+func TestParseActivityDomain(t *testing.T) {
+	for domain, expected := range map[string]models.ActivityDomain{
+		"":             models.WORK_ACTIVITYDOMAIN,
+		"work":         models.WORK_ACTIVITYDOMAIN,
+		"Personal":     models.PERSONAL_ACTIVITYDOMAIN,
+		"unrestricted": models.UNRESTRICTED_ACTIVITYDOMAIN,
+	} {
+		actual, err := parseActivityDomain(domain)
+		assert.NoError(t, err, domain)
+		assert.Equal(t, expected, actual, domain)
+	}
+
+	_, err := parseActivityDomain("weekends")
+	assert.ErrorContains(t, err, `invalid activity domain "weekends"`)
+}
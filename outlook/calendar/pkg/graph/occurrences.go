@@ -0,0 +1,228 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	"github.com/gptscript-ai/tools/outlook/common/pagination"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/groups"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+// Scope is the part of a recurring event that a change applies to.
+type Scope string
+
+const (
+	ScopeOccurrence Scope = "occurrence"
+	ScopeSeries     Scope = "series"
+)
+
+// UpdateEventInfo is a change to an event. Nil fields are left unchanged.
+type UpdateEventInfo struct {
+	Subject, Location, Body *string
+	Start, End              *time.Time
+	IsReminderOn            *bool
+	// Setting the minutes also turns the reminder on, unless IsReminderOn is false
+	ReminderMinutesBeforeStart *int32
+	// Categories replace the categories of the event. Nil leaves them unchanged, empty removes them.
+	Categories []string
+}
+
+// ListEventOccurrences returns the occurrences of a recurring event in the time frame. The event can be the series or
+// any of its occurrences.
+func ListEventOccurrences(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, eventID, calendarID string, owner OwnerType, start, end time.Time) ([]models.Eventable, error) {
+	event, err := GetEvent(ctx, client, eventID, calendarID, owner)
+	if err != nil {
+		return nil, err
+	}
+	seriesID, err := TargetEventID(event, ScopeSeries)
+	if err != nil {
+		return nil, err
+	}
+
+	startDateTime := util.Ptr(start.Format(time.RFC3339))
+	endDateTime := util.Ptr(end.Format(time.RFC3339))
+
+	var pages *pagination.PageIterator[models.Eventable]
+	switch {
+	case calendarID != "" && owner == OwnerTypeGroup:
+		instances := client.Groups().ByGroupId(calendarID).Events().ByEventId(seriesID).Instances()
+		pages = pagination.New(
+			func(ctx context.Context, q pagination.Query) (models.EventCollectionResponseable, error) {
+				return instances.Get(ctx, &groups.ItemEventsItemInstancesRequestBuilderGetRequestConfiguration{
+					QueryParameters: &groups.ItemEventsItemInstancesRequestBuilderGetQueryParameters{
+						StartDateTime: startDateTime,
+						EndDateTime:   endDateTime,
+						Top:           q.Top,
+					},
+				})
+			},
+			func(ctx context.Context, nextLink string) (models.EventCollectionResponseable, error) {
+				return instances.WithUrl(nextLink).Get(ctx, nil)
+			},
+		)
+	default:
+		// Event IDs are unique in the mailbox, so the events of all of the user's calendars can be found by ID
+		instances := client.Me().Events().ByEventId(seriesID).Instances()
+		pages = pagination.New(
+			func(ctx context.Context, q pagination.Query) (models.EventCollectionResponseable, error) {
+				return instances.Get(ctx, &users.ItemEventsItemInstancesRequestBuilderGetRequestConfiguration{
+					QueryParameters: &users.ItemEventsItemInstancesRequestBuilderGetQueryParameters{
+						StartDateTime: startDateTime,
+						EndDateTime:   endDateTime,
+						Top:           q.Top,
+					},
+				})
+			},
+			func(ctx context.Context, nextLink string) (models.EventCollectionResponseable, error) {
+				return instances.WithUrl(nextLink).Get(ctx, nil)
+			},
+		)
+	}
+
+	occurrences, err := pages.Collect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list occurrences: %w", err)
+	}
+	return occurrences, nil
+}
+
+// TargetEventID returns the ID of the event that a change with the scope applies to: the occurrence itself, or the
+// series master of a recurring event.
+func TargetEventID(event models.Eventable, scope Scope) (string, error) {
+	eventType := util.Deref(event.GetTypeEscaped())
+	switch scope {
+	case ScopeOccurrence:
+		if eventType == models.SERIESMASTER_EVENTTYPE {
+			return "", fmt.Errorf("the event is a recurring series, use the ID of one of its occurrences to change a single occurrence")
+		}
+		return util.Deref(event.GetId()), nil
+	case ScopeSeries:
+		switch eventType {
+		case models.SERIESMASTER_EVENTTYPE:
+			return util.Deref(event.GetId()), nil
+		case models.OCCURRENCE_EVENTTYPE, models.EXCEPTION_EVENTTYPE:
+			return util.Deref(event.GetSeriesMasterId()), nil
+		default:
+			return "", fmt.Errorf("the event is not recurring")
+		}
+	default:
+		return "", fmt.Errorf("invalid scope: %s (possible values are %q and %q)", scope, ScopeOccurrence, ScopeSeries)
+	}
+}
+
+// UpdateEvent changes an occurrence of a recurring event, or the whole series, or a single event. It returns the
+// updated event.
+func UpdateEvent(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, eventID, calendarID string, owner OwnerType, scope Scope, info UpdateEventInfo) (models.Eventable, error) {
+	event, err := GetEvent(ctx, client, eventID, calendarID, owner)
+	if err != nil {
+		return nil, err
+	}
+	targetID, err := targetOrSingleEventID(event, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	requestBody, err := updateEventRequest(event, info)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated models.Eventable
+	switch {
+	case calendarID != "" && owner == OwnerTypeGroup:
+		updated, err = client.Groups().ByGroupId(calendarID).Events().ByEventId(targetID).Patch(ctx, requestBody, nil)
+	default:
+		updated, err = client.Me().Events().ByEventId(targetID).Patch(ctx, requestBody, nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update event: %w", err)
+	}
+	return updated, nil
+}
+
+// updateEventRequest returns the request body that applies the changes to the event. New start and end times keep the
+// time zones of the event.
+func updateEventRequest(event models.Eventable, info UpdateEventInfo) (models.Eventable, error) {
+	requestBody := models.NewEvent()
+	if info.Subject != nil {
+		requestBody.SetSubject(info.Subject)
+	}
+	if info.Location != nil {
+		location := models.NewLocation()
+		location.SetDisplayName(info.Location)
+		requestBody.SetLocation(location)
+	}
+	if info.Body != nil {
+		body := models.NewItemBody()
+		body.SetContent(info.Body)
+		body.SetContentType(util.Ptr(models.TEXT_BODYTYPE))
+		requestBody.SetBody(body)
+	}
+	if info.Start != nil {
+		start, err := toEventDateTimeTimeZone(*info.Start, event.GetStart(), event.GetOriginalStartTimeZone())
+		if err != nil {
+			return nil, err
+		}
+		requestBody.SetStart(start)
+	}
+	if info.End != nil {
+		end, err := toEventDateTimeTimeZone(*info.End, event.GetEnd(), event.GetOriginalEndTimeZone())
+		if err != nil {
+			return nil, err
+		}
+		requestBody.SetEnd(end)
+	}
+	setReminder(requestBody, info.IsReminderOn, info.ReminderMinutesBeforeStart)
+	if info.Categories != nil {
+		requestBody.SetCategories(info.Categories)
+	}
+	return requestBody, nil
+}
+
+// CancelEvent cancels an occurrence of a recurring event, or the whole series, or a single event, and sends the
+// comment to the attendees. Only the organizer can cancel an event.
+func CancelEvent(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, eventID, calendarID string, owner OwnerType, scope Scope, comment string) error {
+	event, err := GetEvent(ctx, client, eventID, calendarID, owner)
+	if err != nil {
+		return err
+	}
+	if !util.Deref(event.GetIsOrganizer()) {
+		return fmt.Errorf("only the organizer can cancel the event, decline it instead")
+	}
+	targetID, err := targetOrSingleEventID(event, scope)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case calendarID != "" && owner == OwnerTypeGroup:
+		requestBody := groups.NewItemEventsItemCancelPostRequestBody()
+		if comment != "" {
+			requestBody.SetComment(&comment)
+		}
+		err = client.Groups().ByGroupId(calendarID).Events().ByEventId(targetID).Cancel().Post(ctx, requestBody, nil)
+	default:
+		requestBody := users.NewItemEventsItemCancelPostRequestBody()
+		if comment != "" {
+			requestBody.SetComment(&comment)
+		}
+		err = client.Me().Events().ByEventId(targetID).Cancel().Post(ctx, requestBody, nil)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to cancel event: %w", err)
+	}
+	return nil
+}
+
+// targetOrSingleEventID is like TargetEventID, but changes of single events apply to the event, whatever the scope.
+func targetOrSingleEventID(event models.Eventable, scope Scope) (string, error) {
+	if util.Deref(event.GetTypeEscaped()) == models.SINGLEINSTANCE_EVENTTYPE && (scope == ScopeOccurrence || scope == ScopeSeries) {
+		return util.Deref(event.GetId()), nil
+	}
+	return TargetEventID(event, scope)
+}
@@ -0,0 +1,100 @@
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func recurringEvent(id string, eventType models.EventType, seriesMasterID string) models.Eventable {
+	event := models.NewEvent()
+	event.SetId(util.Ptr(id))
+	event.SetTypeEscaped(util.Ptr(eventType))
+	if seriesMasterID != "" {
+		event.SetSeriesMasterId(util.Ptr(seriesMasterID))
+	}
+	return event
+}
+
+func TestTargetEventID(t *testing.T) {
+	var (
+		series     = recurringEvent("series", models.SERIESMASTER_EVENTTYPE, "")
+		occurrence = recurringEvent("occurrence", models.OCCURRENCE_EVENTTYPE, "series")
+		exception  = recurringEvent("exception", models.EXCEPTION_EVENTTYPE, "series")
+		single     = recurringEvent("single", models.SINGLEINSTANCE_EVENTTYPE, "")
+	)
+
+	for _, tc := range []struct {
+		event models.Eventable
+		scope Scope
+		want  string
+	}{
+		{occurrence, ScopeOccurrence, "occurrence"},
+		{exception, ScopeOccurrence, "exception"},
+		{occurrence, ScopeSeries, "series"},
+		{exception, ScopeSeries, "series"},
+		{series, ScopeSeries, "series"},
+		{single, ScopeOccurrence, "single"},
+	} {
+		got, err := TargetEventID(tc.event, tc.scope)
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, got)
+	}
+
+	_, err := TargetEventID(series, ScopeOccurrence)
+	assert.ErrorContains(t, err, "use the ID of one of its occurrences")
+	_, err = TargetEventID(single, ScopeSeries)
+	assert.ErrorContains(t, err, "not recurring")
+	_, err = TargetEventID(occurrence, "all")
+	assert.ErrorContains(t, err, "invalid scope")
+
+	// Changes to single events apply to the event, whatever the scope
+	got, err := targetOrSingleEventID(single, ScopeSeries)
+	require.NoError(t, err)
+	assert.Equal(t, "single", got)
+}
+
+func TestUpdateEventRequestKeepsTimeZones(t *testing.T) {
+	// Graph returns the occurrence in UTC, the series was created at 9:00 in Pacific time
+	occurrence := recurringEvent("occurrence", models.OCCURRENCE_EVENTTYPE, "series")
+	occurrence.SetStart(dateTimeTimeZone("2024-10-01T16:00:00.0000000", "UTC"))
+	occurrence.SetEnd(dateTimeTimeZone("2024-10-01T16:30:00.0000000", "UTC"))
+	occurrence.SetOriginalStartTimeZone(util.Ptr("Pacific Standard Time"))
+	occurrence.SetOriginalEndTimeZone(util.Ptr("Pacific Standard Time"))
+
+	// Changing the whole series patches the series master
+	targetID, err := targetOrSingleEventID(occurrence, ScopeSeries)
+	require.NoError(t, err)
+	assert.Equal(t, "series", targetID)
+
+	requestBody, err := updateEventRequest(occurrence, UpdateEventInfo{
+		Start: util.Ptr(time.Date(2024, 10, 1, 17, 0, 0, 0, time.UTC)),
+		End:   util.Ptr(time.Date(2024, 10, 1, 18, 0, 0, 0, time.UTC)),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "2024-10-01T10:00:00", util.Deref(requestBody.GetStart().GetDateTime()))
+	assert.Equal(t, "Pacific Standard Time", util.Deref(requestBody.GetStart().GetTimeZone()))
+	assert.Equal(t, "2024-10-01T11:00:00", util.Deref(requestBody.GetEnd().GetDateTime()))
+	assert.Equal(t, "Pacific Standard Time", util.Deref(requestBody.GetEnd().GetTimeZone()))
+
+	// The time zone of the event's date times is kept as well
+	single := recurringEvent("single", models.SINGLEINSTANCE_EVENTTYPE, "")
+	single.SetStart(dateTimeTimeZone("2024-12-01T09:00:00.0000000", "Europe/Berlin"))
+	requestBody, err = updateEventRequest(single, UpdateEventInfo{Start: util.Ptr(time.Date(2024, 12, 1, 9, 0, 0, 0, time.UTC))})
+	require.NoError(t, err)
+	assert.Equal(t, "2024-12-01T10:00:00", util.Deref(requestBody.GetStart().GetDateTime()))
+	assert.Equal(t, "Europe/Berlin", util.Deref(requestBody.GetStart().GetTimeZone()))
+	assert.Nil(t, requestBody.GetEnd())
+
+	// Events in UTC, or created in a custom time zone, stay in UTC
+	single.SetStart(dateTimeTimeZone("2024-12-01T09:00:00.0000000", "UTC"))
+	single.SetOriginalStartTimeZone(util.Ptr("tzone://Microsoft/Custom"))
+	requestBody, err = updateEventRequest(single, UpdateEventInfo{Start: util.Ptr(time.Date(2024, 12, 1, 9, 0, 0, 0, time.UTC))})
+	require.NoError(t, err)
+	assert.Equal(t, "2024-12-01T09:00:00", util.Deref(requestBody.GetStart().GetDateTime()))
+	assert.Equal(t, "UTC", util.Deref(requestBody.GetStart().GetTimeZone()))
+}
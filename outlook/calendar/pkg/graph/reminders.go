@@ -0,0 +1,104 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	"github.com/gptscript-ai/tools/outlook/common/pagination"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+// Reminder is a reminder of an event of the user.
+type Reminder struct {
+	EventID      string    `json:"eventId"`
+	Subject      string    `json:"subject"`
+	Location     string    `json:"location,omitempty"`
+	Start        time.Time `json:"start"`
+	End          time.Time `json:"end"`
+	ReminderTime time.Time `json:"reminderTime"`
+	WebLink      string    `json:"webLink,omitempty"`
+}
+
+// ListReminders returns the reminders that go off in the time frame, in the order they go off.
+func ListReminders(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, start, end time.Time) ([]Reminder, error) {
+	startDateTime := util.Ptr(start.UTC().Format(time.RFC3339))
+	endDateTime := util.Ptr(end.UTC().Format(time.RFC3339))
+	// The generated builder takes the parameters in alphabetical order
+	reminderView := client.Me().ReminderViewWithStartDateTimeWithEndDateTime(endDateTime, startDateTime)
+	reminders, err := pagination.New(
+		func(ctx context.Context, q pagination.Query) (users.ItemReminderViewWithStartDateTimeWithEndDateTimeGetResponseable, error) {
+			return reminderView.GetAsReminderViewWithStartDateTimeWithEndDateTimeGetResponse(ctx, &users.ItemReminderViewWithStartDateTimeWithEndDateTimeRequestBuilderGetRequestConfiguration{
+				QueryParameters: &users.ItemReminderViewWithStartDateTimeWithEndDateTimeRequestBuilderGetQueryParameters{
+					Top: q.Top,
+				},
+			})
+		},
+		func(ctx context.Context, nextLink string) (users.ItemReminderViewWithStartDateTimeWithEndDateTimeGetResponseable, error) {
+			return reminderView.WithUrl(nextLink).GetAsReminderViewWithStartDateTimeWithEndDateTimeGetResponse(ctx, nil)
+		},
+	).Collect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reminders: %w", err)
+	}
+
+	return remindersFromGraph(reminders)
+}
+
+// remindersFromGraph converts the reminders and sorts them by the time they go off, then by the start of the event.
+func remindersFromGraph(reminders []models.Reminderable) ([]Reminder, error) {
+	result := make([]Reminder, 0, len(reminders))
+	for _, r := range reminders {
+		start, err := parseDateTimeTimeZone(r.GetEventStartTime())
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseDateTimeTimeZone(r.GetEventEndTime())
+		if err != nil {
+			return nil, err
+		}
+		reminderTime, err := parseDateTimeTimeZone(r.GetReminderFireTime())
+		if err != nil {
+			return nil, err
+		}
+
+		reminder := Reminder{
+			EventID:      util.Deref(r.GetEventId()),
+			Subject:      util.Deref(r.GetEventSubject()),
+			Start:        start,
+			End:          end,
+			ReminderTime: reminderTime,
+			WebLink:      util.Deref(r.GetEventWebLink()),
+		}
+		if location := r.GetEventLocation(); location != nil {
+			reminder.Location = util.Deref(location.GetDisplayName())
+		}
+		result = append(result, reminder)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if !result[i].ReminderTime.Equal(result[j].ReminderTime) {
+			return result[i].ReminderTime.Before(result[j].ReminderTime)
+		}
+		return result[i].Start.Before(result[j].Start)
+	})
+	return result, nil
+}
+
+// setReminder sets the reminder of the event, unless both values are nil. Setting the minutes turns the reminder on,
+// unless isReminderOn is false.
+func setReminder(event models.Eventable, isReminderOn *bool, minutesBeforeStart *int32) {
+	if minutesBeforeStart != nil {
+		event.SetReminderMinutesBeforeStart(minutesBeforeStart)
+		if isReminderOn == nil {
+			isReminderOn = util.Ptr(true)
+		}
+	}
+	if isReminderOn != nil {
+		event.SetIsReminderOn(isReminderOn)
+	}
+}
@@ -0,0 +1,72 @@
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func reminder(id, start, end, fire string) models.Reminderable {
+	r := models.NewReminder()
+	r.SetEventId(util.Ptr(id))
+	r.SetEventSubject(util.Ptr("Event " + id))
+	r.SetEventStartTime(dateTimeTimeZone(start, "UTC"))
+	r.SetEventEndTime(dateTimeTimeZone(end, "UTC"))
+	r.SetReminderFireTime(dateTimeTimeZone(fire, "UTC"))
+	return r
+}
+
+func TestRemindersFromGraph(t *testing.T) {
+	withLocation := reminder("b", "2024-10-01T10:00:00.0000000", "2024-10-01T11:00:00.0000000", "2024-10-01T09:45:00.0000000")
+	location := models.NewLocation()
+	location.SetDisplayName(util.Ptr("Room 1"))
+	withLocation.SetEventLocation(location)
+
+	reminders, err := remindersFromGraph([]models.Reminderable{
+		reminder("c", "2024-10-01T10:30:00.0000000", "2024-10-01T11:00:00.0000000", "2024-10-01T10:15:00.0000000"),
+		withLocation,
+		// Goes off at the same time as b, but starts earlier
+		reminder("a", "2024-10-01T09:50:00.0000000", "2024-10-01T10:00:00.0000000", "2024-10-01T09:45:00.0000000"),
+	})
+	require.NoError(t, err)
+	require.Len(t, reminders, 3)
+
+	assert.Equal(t, []string{"a", "b", "c"}, util.Map(reminders, func(r Reminder) string { return r.EventID }))
+	assert.Equal(t, Reminder{
+		EventID:      "b",
+		Subject:      "Event b",
+		Location:     "Room 1",
+		Start:        time.Date(2024, 10, 1, 10, 0, 0, 0, time.UTC),
+		End:          time.Date(2024, 10, 1, 11, 0, 0, 0, time.UTC),
+		ReminderTime: time.Date(2024, 10, 1, 9, 45, 0, 0, time.UTC),
+	}, reminders[1])
+
+	_, err = remindersFromGraph([]models.Reminderable{reminder("d", "tomorrow", "2024-10-01T11:00:00.0000000", "2024-10-01T09:45:00.0000000")})
+	assert.Error(t, err)
+}
+
+func TestSetReminder(t *testing.T) {
+	event := models.NewEvent()
+	setReminder(event, nil, nil)
+	assert.Nil(t, event.GetIsReminderOn())
+	assert.Nil(t, event.GetReminderMinutesBeforeStart())
+
+	event = models.NewEvent()
+	setReminder(event, nil, util.Ptr(int32(30)))
+	assert.Equal(t, util.Ptr(true), event.GetIsReminderOn())
+	assert.Equal(t, util.Ptr(int32(30)), event.GetReminderMinutesBeforeStart())
+
+	event = models.NewEvent()
+	setReminder(event, util.Ptr(false), util.Ptr(int32(30)))
+	assert.Equal(t, util.Ptr(false), event.GetIsReminderOn())
+	assert.Equal(t, util.Ptr(int32(30)), event.GetReminderMinutesBeforeStart())
+
+	event = models.NewEvent()
+	setReminder(event, util.Ptr(false), nil)
+	assert.Equal(t, util.Ptr(false), event.GetIsReminderOn())
+	assert.Nil(t, event.GetReminderMinutesBeforeStart())
+}
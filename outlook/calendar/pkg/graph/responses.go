@@ -0,0 +1,79 @@
+package graph
+
+import (
+	"time"
+
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// AttendeeResponse is the response of an attendee to an event invitation.
+type AttendeeResponse struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+	// Type is required, optional or resource (for rooms and equipment)
+	Type string `json:"type"`
+	// Response is accepted, tentativelyAccepted, declined, or none if the attendee has not responded yet
+	Response    string     `json:"response"`
+	RespondedAt *time.Time `json:"respondedAt,omitempty"`
+}
+
+// EventResponses are the responses of the attendees of an event.
+type EventResponses struct {
+	EventID   string             `json:"eventId"`
+	Subject   string             `json:"subject"`
+	Attendees []AttendeeResponse `json:"attendees"`
+	// Counts is the number of attendees per response
+	Counts map[string]int `json:"counts"`
+	// NotResponded are the email addresses of the people (not resources) that have not responded yet
+	NotResponded []string `json:"notResponded"`
+}
+
+// GetEventResponses returns the responses of the attendees of the event. The organizer is not an attendee.
+func GetEventResponses(event models.Eventable) EventResponses {
+	responses := EventResponses{
+		EventID:      util.Deref(event.GetId()),
+		Subject:      util.Deref(event.GetSubject()),
+		Attendees:    attendeeResponses(event.GetAttendees()),
+		Counts:       map[string]int{},
+		NotResponded: []string{},
+	}
+	for _, a := range responses.Attendees {
+		responses.Counts[a.Response]++
+		if a.Response == "none" && a.Type != "resource" {
+			responses.NotResponded = append(responses.NotResponded, a.Email)
+		}
+	}
+	return responses
+}
+
+func attendeeResponses(attendees []models.Attendeeable) []AttendeeResponse {
+	result := make([]AttendeeResponse, 0, len(attendees))
+	for _, a := range attendees {
+		response := AttendeeResponse{
+			Type:     "required",
+			Response: "none",
+		}
+		if email := a.GetEmailAddress(); email != nil {
+			response.Email = util.Deref(email.GetAddress())
+			response.Name = util.Deref(email.GetName())
+		}
+		if t := a.GetTypeEscaped(); t != nil {
+			response.Type = t.String()
+		}
+		if status := a.GetStatus(); status != nil {
+			if r := status.GetResponse(); r != nil {
+				switch *r {
+				case models.ACCEPTED_RESPONSETYPE, models.TENTATIVELYACCEPTED_RESPONSETYPE, models.DECLINED_RESPONSETYPE:
+					response.Response = r.String()
+					// Graph sets the time to 0001-01-01 if there is no response
+					if t := status.GetTime(); t != nil && t.Year() > 1 {
+						response.RespondedAt = t
+					}
+				}
+			}
+		}
+		result = append(result, response)
+	}
+	return result
+}
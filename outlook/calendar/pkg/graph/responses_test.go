@@ -0,0 +1,45 @@
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func attendee(address string, attendeeType models.AttendeeType, response models.ResponseType, at time.Time) models.Attendeeable {
+	a := models.NewAttendee()
+	email := models.NewEmailAddress()
+	email.SetAddress(util.Ptr(address))
+	a.SetEmailAddress(email)
+	a.SetTypeEscaped(util.Ptr(attendeeType))
+	status := models.NewResponseStatus()
+	status.SetResponse(util.Ptr(response))
+	status.SetTime(util.Ptr(at))
+	a.SetStatus(status)
+	return a
+}
+
+func TestAttendeeResponses(t *testing.T) {
+	// Graph returns this time for attendees without a response
+	noTime := time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)
+	respondedAt := time.Date(2024, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	responses := attendeeResponses([]models.Attendeeable{
+		attendee("adele@contoso.com", models.REQUIRED_ATTENDEETYPE, models.ACCEPTED_RESPONSETYPE, respondedAt),
+		attendee("alex@contoso.com", models.OPTIONAL_ATTENDEETYPE, models.NONE_RESPONSETYPE, noTime),
+		attendee("megan@contoso.com", models.REQUIRED_ATTENDEETYPE, models.NOTRESPONDED_RESPONSETYPE, noTime),
+		attendee("k2@contoso.com", models.RESOURCE_ATTENDEETYPE, models.DECLINED_RESPONSETYPE, respondedAt),
+		models.NewAttendee(),
+	})
+
+	assert.Equal(t, []AttendeeResponse{
+		{Email: "adele@contoso.com", Type: "required", Response: "accepted", RespondedAt: &respondedAt},
+		{Email: "alex@contoso.com", Type: "optional", Response: "none"},
+		{Email: "megan@contoso.com", Type: "required", Response: "none"},
+		{Email: "k2@contoso.com", Type: "resource", Response: "declined", RespondedAt: &respondedAt},
+		{Type: "required", Response: "none"},
+	}, responses)
+}
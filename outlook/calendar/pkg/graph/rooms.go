@@ -0,0 +1,261 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	"github.com/gptscript-ai/tools/outlook/common/pagination"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/places"
+)
+
+// RoomList is a group of rooms, usually the rooms of a building.
+type RoomList struct {
+	Email string `json:"email"`
+	Name  string `json:"name"`
+}
+
+// Room is a meeting room or another bookable resource.
+type Room struct {
+	Email                  string   `json:"email"`
+	Name                   string   `json:"name"`
+	Building               string   `json:"building,omitempty"`
+	Floor                  string   `json:"floor,omitempty"`
+	Capacity               int      `json:"capacity,omitempty"`
+	IsWheelChairAccessible bool     `json:"isWheelChairAccessible,omitempty"`
+	Tags                   []string `json:"tags,omitempty"`
+	// Whether the room is free in the requested time frame, nil if no time frame was requested
+	Available *bool `json:"available,omitempty"`
+	// Why the availability could not be determined
+	AvailabilityError string `json:"availabilityError,omitempty"`
+}
+
+// RoomFilter narrows down the rooms. Empty fields match all rooms.
+type RoomFilter struct {
+	MinCapacity int
+	// Building is matched case-insensitively against the building of the room
+	Building string
+	// Query is matched case-insensitively against the name, email address and tags of the room
+	Query string
+}
+
+// ListRoomLists returns the room lists of the organization.
+func ListRoomLists(ctx context.Context, client *msgraphsdkgo.GraphServiceClient) ([]RoomList, error) {
+	roomLists := client.Places().GraphRoomList()
+	resp, err := pagination.New(
+		func(ctx context.Context, q pagination.Query) (models.RoomListCollectionResponseable, error) {
+			return roomLists.Get(ctx, &places.GraphRoomListRequestBuilderGetRequestConfiguration{
+				QueryParameters: &places.GraphRoomListRequestBuilderGetQueryParameters{
+					Top: q.Top,
+				},
+			})
+		},
+		func(ctx context.Context, nextLink string) (models.RoomListCollectionResponseable, error) {
+			return roomLists.WithUrl(nextLink).Get(ctx, nil)
+		},
+	).Collect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list room lists: %w", err)
+	}
+
+	result := make([]RoomList, 0, len(resp))
+	for _, l := range resp {
+		result = append(result, RoomList{
+			Email: util.Deref(l.GetEmailAddress()),
+			Name:  util.Deref(l.GetDisplayName()),
+		})
+	}
+	return result, nil
+}
+
+// ListRooms returns the rooms in the room list with the email address, or all rooms of the organization if roomList
+// is empty.
+func ListRooms(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, roomList string) ([]Room, error) {
+	var pages *pagination.PageIterator[models.Roomable]
+	if roomList != "" {
+		rooms := client.Places().ByPlaceId(roomList).GraphRoomList().Rooms()
+		pages = pagination.New(
+			func(ctx context.Context, q pagination.Query) (models.RoomCollectionResponseable, error) {
+				return rooms.Get(ctx, &places.ItemGraphRoomListRoomsRequestBuilderGetRequestConfiguration{
+					QueryParameters: &places.ItemGraphRoomListRoomsRequestBuilderGetQueryParameters{
+						Top: q.Top,
+					},
+				})
+			},
+			func(ctx context.Context, nextLink string) (models.RoomCollectionResponseable, error) {
+				return rooms.WithUrl(nextLink).Get(ctx, nil)
+			},
+		)
+	} else {
+		rooms := client.Places().GraphRoom()
+		pages = pagination.New(
+			func(ctx context.Context, q pagination.Query) (models.RoomCollectionResponseable, error) {
+				return rooms.Get(ctx, &places.GraphRoomRequestBuilderGetRequestConfiguration{
+					QueryParameters: &places.GraphRoomRequestBuilderGetQueryParameters{
+						Top: q.Top,
+					},
+				})
+			},
+			func(ctx context.Context, nextLink string) (models.RoomCollectionResponseable, error) {
+				return rooms.WithUrl(nextLink).Get(ctx, nil)
+			},
+		)
+	}
+
+	resp, err := pages.Collect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rooms: %w", err)
+	}
+	return util.Map(resp, roomFromGraph), nil
+}
+
+func roomFromGraph(r models.Roomable) Room {
+	return Room{
+		Email:                  util.Deref(r.GetEmailAddress()),
+		Name:                   util.Deref(r.GetDisplayName()),
+		Building:               util.Deref(r.GetBuilding()),
+		Floor:                  util.Deref(r.GetFloorLabel()),
+		Capacity:               int(util.Deref(r.GetCapacity())),
+		IsWheelChairAccessible: util.Deref(r.GetIsWheelChairAccessible()),
+		Tags:                   r.GetTags(),
+	}
+}
+
+// FilterRooms returns the rooms matching the filter, ordered by capacity and name.
+func FilterRooms(rooms []Room, filter RoomFilter) []Room {
+	building := strings.ToLower(strings.TrimSpace(filter.Building))
+	query := strings.ToLower(strings.TrimSpace(filter.Query))
+
+	var result []Room
+	for _, r := range rooms {
+		if r.Capacity < filter.MinCapacity {
+			continue
+		}
+		if building != "" && !strings.Contains(strings.ToLower(r.Building), building) {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(strings.Join(append([]string{r.Name, r.Email}, r.Tags...), "\n")), query) {
+			continue
+		}
+		result = append(result, r)
+	}
+
+	// The smallest rooms that are big enough first, so that large rooms stay free for large meetings
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Capacity != result[j].Capacity {
+			return result[i].Capacity < result[j].Capacity
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// SetRoomAvailability checks the schedules of the rooms and sets whether they are free in the time frame.
+func SetRoomAvailability(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, rooms []Room, start, end time.Time) error {
+	if len(rooms) == 0 {
+		return nil
+	}
+
+	schedules, err := GetSchedules(ctx, client, util.Map(rooms, func(r Room) string { return r.Email }), start, end)
+	if err != nil {
+		return err
+	}
+	applyRoomSchedules(rooms, schedules)
+	return nil
+}
+
+// applyRoomSchedules sets the availability of the rooms from their schedules. Rooms without a schedule are unknown.
+func applyRoomSchedules(rooms []Room, schedules []Schedule) {
+	byEmail := make(map[string]Schedule, len(schedules))
+	for _, s := range schedules {
+		byEmail[strings.ToLower(s.Email)] = s
+	}
+
+	for i := range rooms {
+		schedule, ok := byEmail[strings.ToLower(rooms[i].Email)]
+		switch {
+		case !ok:
+			rooms[i].AvailabilityError = "no schedule was returned for the room"
+		case schedule.Error != "":
+			rooms[i].AvailabilityError = schedule.Error
+		default:
+			rooms[i].Available = util.Ptr(len(schedule.Busy) == 0)
+		}
+	}
+}
+
+// AddRoomToEvent adds the room as a resource attendee of the event and sets the location of the event to the room.
+// The room accepts or declines the invitation depending on its availability and booking policy.
+func AddRoomToEvent(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, eventID, calendarID string, owner OwnerType, roomEmail string) (models.Eventable, error) {
+	event, err := GetEvent(ctx, client, eventID, calendarID, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	roomName := roomEmail
+	if place, err := client.Places().ByPlaceId(roomEmail).Get(ctx, nil); err == nil && util.Deref(place.GetDisplayName()) != "" {
+		roomName = util.Deref(place.GetDisplayName())
+	}
+
+	requestBody := models.NewEvent()
+	attendees, added := withRoomAttendee(event.GetAttendees(), roomEmail, roomName)
+	if !added {
+		return nil, fmt.Errorf("the room %s is already an attendee of the event", roomEmail)
+	}
+	requestBody.SetAttendees(attendees)
+
+	location := models.NewLocation()
+	location.SetDisplayName(&roomName)
+	location.SetLocationEmailAddress(&roomEmail)
+	location.SetLocationType(util.Ptr(models.CONFERENCEROOM_LOCATIONTYPE))
+	requestBody.SetLocation(location)
+
+	var updated models.Eventable
+	switch {
+	case calendarID != "" && owner == OwnerTypeGroup:
+		updated, err = client.Groups().ByGroupId(calendarID).Events().ByEventId(eventID).Patch(ctx, requestBody, nil)
+	default:
+		updated, err = client.Me().Events().ByEventId(eventID).Patch(ctx, requestBody, nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to add room to event: %w", err)
+	}
+	return updated, nil
+}
+
+// withRoomAttendee returns the attendees with the room added as a resource, as Graph replaces the attendees of an
+// event on update. The bool is false if the room already is an attendee.
+func withRoomAttendee(attendees []models.Attendeeable, roomEmail, roomName string) ([]models.Attendeeable, bool) {
+	for _, a := range attendees {
+		if a.GetEmailAddress() != nil && strings.EqualFold(util.Deref(a.GetEmailAddress().GetAddress()), roomEmail) {
+			return attendees, false
+		}
+	}
+
+	room := models.NewAttendee()
+	email := models.NewEmailAddress()
+	email.SetAddress(&roomEmail)
+	if roomName != "" {
+		email.SetName(&roomName)
+	}
+	room.SetEmailAddress(email)
+	room.SetTypeEscaped(util.Ptr(models.RESOURCE_ATTENDEETYPE))
+	return append(attendees, room), true
+}
+
+// roomAttendees returns the rooms as resource attendees of a new event.
+func roomAttendees(rooms []string) []models.Attendeeable {
+	var attendees []models.Attendeeable
+	for _, r := range rooms {
+		if r = strings.TrimSpace(r); r == "" {
+			continue
+		}
+		attendees, _ = withRoomAttendee(attendees, r, "")
+	}
+	return attendees
+}
@@ -0,0 +1,72 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterRooms(t *testing.T) {
+	rooms := []Room{
+		{Email: "everest@contoso.com", Name: "Everest", Building: "Building 1", Capacity: 20, Tags: []string{"Projector"}},
+		{Email: "k2@contoso.com", Name: "K2", Building: "Building 1", Capacity: 6},
+		{Email: "denali@contoso.com", Name: "Denali", Building: "Building 2", Capacity: 6, Tags: []string{"Whiteboard"}},
+		{Email: "focus@contoso.com", Name: "Focus Room", Building: "Building 2"},
+	}
+
+	names := func(rooms []Room) []string {
+		return util.Map(rooms, func(r Room) string { return r.Name })
+	}
+
+	assert.Equal(t, []string{"Focus Room", "Denali", "K2", "Everest"}, names(FilterRooms(rooms, RoomFilter{})))
+	assert.Equal(t, []string{"Denali", "K2", "Everest"}, names(FilterRooms(rooms, RoomFilter{MinCapacity: 5})))
+	assert.Equal(t, []string{"K2", "Everest"}, names(FilterRooms(rooms, RoomFilter{Building: "building 1"})))
+	assert.Equal(t, []string{"Everest"}, names(FilterRooms(rooms, RoomFilter{Query: "projector"})))
+	assert.Equal(t, []string{"Denali"}, names(FilterRooms(rooms, RoomFilter{Query: "denali@", MinCapacity: 6})))
+	assert.Empty(t, FilterRooms(rooms, RoomFilter{MinCapacity: 50}))
+}
+
+func TestApplyRoomSchedules(t *testing.T) {
+	rooms := []Room{
+		{Email: "Everest@contoso.com"},
+		{Email: "k2@contoso.com"},
+		{Email: "denali@contoso.com"},
+		{Email: "focus@contoso.com"},
+	}
+	applyRoomSchedules(rooms, []Schedule{
+		{Email: "everest@contoso.com", Busy: []BusyBlock{}},
+		{Email: "k2@contoso.com", Busy: []BusyBlock{{Status: "busy"}}},
+		{Email: "denali@contoso.com", Error: "The mailbox is not available"},
+	})
+
+	assert.Equal(t, util.Ptr(true), rooms[0].Available)
+	assert.Equal(t, util.Ptr(false), rooms[1].Available)
+	assert.Nil(t, rooms[2].Available)
+	assert.Equal(t, "The mailbox is not available", rooms[2].AvailabilityError)
+	assert.Nil(t, rooms[3].Available)
+	assert.NotEmpty(t, rooms[3].AvailabilityError)
+}
+
+func TestWithRoomAttendee(t *testing.T) {
+	person := models.NewAttendee()
+	email := models.NewEmailAddress()
+	email.SetAddress(util.Ptr("adele@contoso.com"))
+	person.SetEmailAddress(email)
+
+	attendees, added := withRoomAttendee([]models.Attendeeable{person}, "k2@contoso.com", "K2")
+	require.True(t, added)
+	require.Len(t, attendees, 2)
+	assert.Equal(t, "k2@contoso.com", util.Deref(attendees[1].GetEmailAddress().GetAddress()))
+	assert.Equal(t, "K2", util.Deref(attendees[1].GetEmailAddress().GetName()))
+	assert.Equal(t, models.RESOURCE_ATTENDEETYPE, util.Deref(attendees[1].GetTypeEscaped()))
+
+	_, added = withRoomAttendee(attendees, "K2@contoso.com", "K2")
+	assert.False(t, added)
+
+	rooms := roomAttendees([]string{"k2@contoso.com", " ", "denali@contoso.com", "k2@contoso.com"})
+	require.Len(t, rooms, 2)
+	assert.Nil(t, rooms[0].GetEmailAddress().GetName())
+}
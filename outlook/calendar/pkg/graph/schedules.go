@@ -0,0 +1,108 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+// maxSchedules is the number of schedules Graph returns for a single getSchedule request.
+const maxSchedules = 20
+
+// BusyBlock is a time in which someone is not free, e.g. because of a meeting or an out of office.
+type BusyBlock struct {
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Status   string    `json:"status"`
+	Subject  string    `json:"subject,omitempty"` // only visible if the owner shares the details of their calendar
+	Location string    `json:"location,omitempty"`
+}
+
+// Schedule is the free/busy information of a user or resource in a time frame.
+type Schedule struct {
+	Email string      `json:"email"`
+	Busy  []BusyBlock `json:"busy"`
+	// WorkingHours are the working hours of the user, nil for resources and users that didn't set them
+	WorkingHours *WorkingHours `json:"workingHours,omitempty"`
+	// Why the availability could not be determined, e.g. because the user is external
+	Error string `json:"error,omitempty"`
+}
+
+// GetSchedules returns the times in which the users or resources with the given email addresses are busy.
+func GetSchedules(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, emails []string, start, end time.Time) ([]Schedule, error) {
+	if !end.After(start) {
+		return nil, fmt.Errorf("end time must be after start time")
+	}
+
+	var schedules []Schedule
+	for i := 0; i < len(emails); i += maxSchedules {
+		requestBody := users.NewItemCalendarGetSchedulePostRequestBody()
+		requestBody.SetSchedules(emails[i:min(i+maxSchedules, len(emails))])
+		requestBody.SetStartTime(toDateTimeTimeZone(start))
+		requestBody.SetEndTime(toDateTimeTimeZone(end))
+
+		resp, err := client.Me().Calendar().GetSchedule().PostAsGetSchedulePostResponse(ctx, requestBody, &users.ItemCalendarGetScheduleRequestBuilderPostRequestConfiguration{
+			Headers: utcHeaders(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get schedules: %w", err)
+		}
+
+		for _, info := range resp.GetValue() {
+			schedule, err := scheduleFromInformation(info)
+			if err != nil {
+				return nil, err
+			}
+			schedules = append(schedules, schedule)
+		}
+	}
+
+	return schedules, nil
+}
+
+// scheduleFromInformation converts the schedule returned by Graph, leaving out the items in which the owner is free.
+func scheduleFromInformation(info models.ScheduleInformationable) (Schedule, error) {
+	schedule := Schedule{
+		Email:        util.Deref(info.GetScheduleId()),
+		Busy:         []BusyBlock{},
+		WorkingHours: workingHoursFromGraph(info.GetWorkingHours()),
+	}
+	if scheduleErr := info.GetError(); scheduleErr != nil {
+		schedule.Error = util.Deref(scheduleErr.GetMessage())
+		return schedule, nil
+	}
+
+	for _, item := range info.GetScheduleItems() {
+		status := "unknown"
+		if s := item.GetStatus(); s != nil {
+			status = s.String()
+		}
+		if status == "free" {
+			continue
+		}
+
+		start, err := parseDateTimeTimeZone(item.GetStart())
+		if err != nil {
+			return schedule, err
+		}
+		end, err := parseDateTimeTimeZone(item.GetEnd())
+		if err != nil {
+			return schedule, err
+		}
+
+		schedule.Busy = append(schedule.Busy, BusyBlock{
+			Start:    start,
+			End:      end,
+			Status:   status,
+			Subject:  util.Deref(item.GetSubject()),
+			Location: util.Deref(item.GetLocation()),
+		})
+	}
+
+	return schedule, nil
+}
@@ -0,0 +1,52 @@
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scheduleItem(start, end string, status models.FreeBusyStatus, subject string) models.ScheduleItemable {
+	item := models.NewScheduleItem()
+	item.SetStart(dateTimeTimeZone(start, "UTC"))
+	item.SetEnd(dateTimeTimeZone(end, "UTC"))
+	item.SetStatus(&status)
+	if subject != "" {
+		item.SetSubject(util.Ptr(subject))
+	}
+	return item
+}
+
+func TestScheduleFromInformation(t *testing.T) {
+	info := models.NewScheduleInformation()
+	info.SetScheduleId(util.Ptr("adele@contoso.com"))
+	info.SetScheduleItems([]models.ScheduleItemable{
+		scheduleItem("2024-10-01T09:00:00.0000000", "2024-10-01T10:00:00.0000000", models.BUSY_FREEBUSYSTATUS, "Standup"),
+		scheduleItem("2024-10-01T10:00:00.0000000", "2024-10-01T11:00:00.0000000", models.FREE_FREEBUSYSTATUS, "Focus time"),
+		scheduleItem("2024-10-01T13:00:00.0000000", "2024-10-01T14:00:00.0000000", models.TENTATIVE_FREEBUSYSTATUS, ""),
+	})
+
+	schedule, err := scheduleFromInformation(info)
+	require.NoError(t, err)
+	assert.Equal(t, Schedule{
+		Email: "adele@contoso.com",
+		Busy: []BusyBlock{
+			{Start: time.Date(2024, 10, 1, 9, 0, 0, 0, time.UTC), End: time.Date(2024, 10, 1, 10, 0, 0, 0, time.UTC), Status: "busy", Subject: "Standup"},
+			{Start: time.Date(2024, 10, 1, 13, 0, 0, 0, time.UTC), End: time.Date(2024, 10, 1, 14, 0, 0, 0, time.UTC), Status: "tentative"},
+		},
+	}, schedule)
+
+	freeBusyErr := models.NewFreeBusyError()
+	freeBusyErr.SetMessage(util.Ptr("The user is external"))
+	info = models.NewScheduleInformation()
+	info.SetScheduleId(util.Ptr("guest@example.com"))
+	info.SetError(freeBusyErr)
+
+	schedule, err = scheduleFromInformation(info)
+	require.NoError(t, err)
+	assert.Equal(t, Schedule{Email: "guest@example.com", Busy: []BusyBlock{}, Error: "The user is external"}, schedule)
+}
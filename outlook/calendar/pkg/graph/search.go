@@ -0,0 +1,88 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	"github.com/gptscript-ai/tools/outlook/common/fields"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// EventSearch filters the events in a time frame. Text filters are matched case-insensitively, and empty filters
+// match all events.
+type EventSearch struct {
+	// Query is matched against the subject and the body
+	Query string
+	// Organizer and Attendee are matched against the email addresses and names
+	Organizer, Attendee string
+	Start, End          time.Time
+}
+
+// SearchCalendar calls fn for the events of the calendar that match the search, following @odata.nextLink across
+// pages. It stops when fn returns false. The events have the selected properties, all default properties if selects
+// is nil, and the properties that are matched.
+func SearchCalendar(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, id string, owner OwnerType, search EventSearch, selects []string, fn func(event models.Eventable) bool) error {
+	selects = fields.With(selects, "subject", "bodyPreview", "organizer", "attendees")
+	if strings.TrimSpace(search.Query) != "" {
+		selects = fields.With(selects, "body")
+	}
+
+	// Graph can't filter the calendar view by these fields, so the events are matched while paging
+	err := calendarViewPages(client, id, owner, &search.Start, &search.End, "").WithSelect(selects...).Iterate(ctx, func(event models.Eventable) bool {
+		if !MatchesEventSearch(event, search) {
+			return true
+		}
+		return fn(event)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to search events: %w", err)
+	}
+	return nil
+}
+
+// MatchesEventSearch returns whether the event matches the text filters of the search.
+func MatchesEventSearch(event models.Eventable, search EventSearch) bool {
+	if query := strings.ToLower(strings.TrimSpace(search.Query)); query != "" {
+		text := util.Deref(event.GetSubject()) + "\n" + util.Deref(event.GetBodyPreview())
+		if body := event.GetBody(); body != nil {
+			text += "\n" + util.Deref(body.GetContent())
+		}
+		if !strings.Contains(strings.ToLower(text), query) {
+			return false
+		}
+	}
+
+	if organizer := strings.TrimSpace(search.Organizer); organizer != "" {
+		if event.GetOrganizer() == nil || !matchesEmailAddress(event.GetOrganizer().GetEmailAddress(), organizer) {
+			return false
+		}
+	}
+
+	if attendee := strings.TrimSpace(search.Attendee); attendee != "" {
+		found := false
+		for _, a := range event.GetAttendees() {
+			if matchesEmailAddress(a.GetEmailAddress(), attendee) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func matchesEmailAddress(email models.EmailAddressable, text string) bool {
+	if email == nil {
+		return false
+	}
+	text = strings.ToLower(text)
+	return strings.Contains(strings.ToLower(util.Deref(email.GetAddress())), text) ||
+		strings.Contains(strings.ToLower(util.Deref(email.GetName())), text)
+}
@@ -0,0 +1,54 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchesEventSearch(t *testing.T) {
+	emailAddress := func(address, name string) models.EmailAddressable {
+		email := models.NewEmailAddress()
+		email.SetAddress(util.Ptr(address))
+		email.SetName(util.Ptr(name))
+		return email
+	}
+
+	event := models.NewEvent()
+	event.SetSubject(util.Ptr("Quarterly Review"))
+	event.SetBodyPreview(util.Ptr("Agenda: numbers"))
+	body := models.NewItemBody()
+	body.SetContent(util.Ptr("<html><body>Agenda: numbers, then the roadmap</body></html>"))
+	event.SetBody(body)
+	organizer := models.NewRecipient()
+	organizer.SetEmailAddress(emailAddress("adele@contoso.com", "Adele Vance"))
+	event.SetOrganizer(organizer)
+	attendee := models.NewAttendee()
+	attendee.SetEmailAddress(emailAddress("alex@contoso.com", "Alex Wilber"))
+	event.SetAttendees([]models.Attendeeable{attendee})
+
+	for _, search := range []EventSearch{
+		{},
+		{Query: "quarterly"},
+		{Query: "ROADMAP"},
+		{Organizer: "adele@"},
+		{Organizer: "vance"},
+		{Attendee: "Alex"},
+		{Query: "review", Organizer: "adele", Attendee: "alex@contoso.com"},
+	} {
+		assert.True(t, MatchesEventSearch(event, search), "%+v", search)
+	}
+
+	for _, search := range []EventSearch{
+		{Query: "budget"},
+		{Organizer: "alex"},
+		{Attendee: "adele"},
+		{Query: "review", Attendee: "megan"},
+	} {
+		assert.False(t, MatchesEventSearch(event, search), "%+v", search)
+	}
+
+	assert.False(t, MatchesEventSearch(models.NewEvent(), EventSearch{Organizer: "adele"}))
+}
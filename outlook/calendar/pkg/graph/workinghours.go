@@ -0,0 +1,219 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+// WorkingHours are the days and hours in which someone works, in their time zone.
+type WorkingHours struct {
+	// DaysOfWeek are lowercase English day names, e.g. monday
+	DaysOfWeek []string `json:"daysOfWeek"`
+	// StartTime and EndTime are local times of day, e.g. 09:00
+	StartTime string `json:"startTime"`
+	EndTime   string `json:"endTime"`
+	// TimeZone is the name of the time zone, which can be an IANA or a Windows time zone name
+	TimeZone string `json:"timeZone"`
+}
+
+// Slot is a time frame in which all participants are free and working.
+type Slot struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// GetWorkingHours returns the working hours from the user's mailbox settings, or nil if they are not set.
+func GetWorkingHours(ctx context.Context, client *msgraphsdkgo.GraphServiceClient) (*WorkingHours, error) {
+	settings, err := client.Me().MailboxSettings().Get(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mailbox settings: %w", err)
+	}
+	return workingHoursFromGraph(settings.GetWorkingHours()), nil
+}
+
+// GetUserEmail returns the email address of the user.
+func GetUserEmail(ctx context.Context, client *msgraphsdkgo.GraphServiceClient) (string, error) {
+	user, err := client.Me().Get(ctx, &users.UserItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.UserItemRequestBuilderGetQueryParameters{
+			Select: []string{"mail", "userPrincipalName"},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get user: %w", err)
+	}
+	if email := util.Deref(user.GetMail()); email != "" {
+		return email, nil
+	}
+	return util.Deref(user.GetUserPrincipalName()), nil
+}
+
+func workingHoursFromGraph(wh models.WorkingHoursable) *WorkingHours {
+	if wh == nil || wh.GetStartTime() == nil || wh.GetEndTime() == nil {
+		return nil
+	}
+
+	result := &WorkingHours{
+		DaysOfWeek: util.Map(wh.GetDaysOfWeek(), func(d models.DayOfWeek) string {
+			return d.String()
+		}),
+		// Times of day are returned with fractional seconds, e.g. 09:00:00.0000000
+		StartTime: timeOfDay(wh.GetStartTime().String()),
+		EndTime:   timeOfDay(wh.GetEndTime().String()),
+		TimeZone:  "UTC",
+	}
+	if tz := wh.GetTimeZone(); tz != nil && util.Deref(tz.GetName()) != "" {
+		result.TimeZone = util.Deref(tz.GetName())
+	}
+	return result
+}
+
+func timeOfDay(s string) string {
+	if len(s) > len("15:04") {
+		return s[:len("15:04")]
+	}
+	return s
+}
+
+// workingWindows returns the time frames between start and end in which someone with the working hours works.
+func workingWindows(wh WorkingHours, start, end time.Time) ([]Slot, error) {
+	loc, err := LoadLocation(wh.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q of working hours: %w", wh.TimeZone, err)
+	}
+	dayStart, err := time.Parse("15:04", wh.StartTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start time %q of working hours: %w", wh.StartTime, err)
+	}
+	dayEnd, err := time.Parse("15:04", wh.EndTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end time %q of working hours: %w", wh.EndTime, err)
+	}
+
+	var windows []Slot
+	// Start a day early, as the working day that start falls into may have begun on the previous day in UTC
+	first := start.In(loc).AddDate(0, 0, -1)
+	for day := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, loc); day.Before(end); day = day.AddDate(0, 0, 1) {
+		if !slices.ContainsFunc(wh.DaysOfWeek, func(d string) bool {
+			return strings.EqualFold(d, day.Weekday().String())
+		}) {
+			continue
+		}
+
+		window := Slot{
+			Start: time.Date(day.Year(), day.Month(), day.Day(), dayStart.Hour(), dayStart.Minute(), 0, 0, loc),
+			End:   time.Date(day.Year(), day.Month(), day.Day(), dayEnd.Hour(), dayEnd.Minute(), 0, 0, loc),
+		}
+		if !window.End.After(window.Start) {
+			// Working hours that pass midnight
+			window.End = window.End.AddDate(0, 0, 1)
+		}
+		if window.Start.Before(start) {
+			window.Start = start
+		}
+		if window.End.After(end) {
+			window.End = end
+		}
+		if window.End.After(window.Start) {
+			windows = append(windows, window)
+		}
+	}
+	return windows, nil
+}
+
+// intersectSlots returns the time frames that are in both lists. The lists have to be sorted and not overlap.
+func intersectSlots(a, b []Slot) []Slot {
+	var result []Slot
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		start, end := a[i].Start, a[i].End
+		if b[j].Start.After(start) {
+			start = b[j].Start
+		}
+		if b[j].End.Before(end) {
+			end = b[j].End
+		}
+		if end.After(start) {
+			result = append(result, Slot{Start: start, End: end})
+		}
+		if a[i].End.Before(b[j].End) {
+			i++
+		} else {
+			j++
+		}
+	}
+	return result
+}
+
+// subtractBusy returns the parts of the slots that don't overlap with the busy blocks.
+func subtractBusy(slots []Slot, busy []BusyBlock) []Slot {
+	sorted := slices.Clone(busy)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+
+	var result []Slot
+	for _, slot := range slots {
+		free := slot
+		for _, b := range sorted {
+			if !b.End.After(free.Start) || !b.Start.Before(free.End) {
+				continue
+			}
+			if b.Start.After(free.Start) {
+				result = append(result, Slot{Start: free.Start, End: b.Start})
+			}
+			free.Start = b.End
+			if !free.End.After(free.Start) {
+				break
+			}
+		}
+		if free.End.After(free.Start) {
+			result = append(result, free)
+		}
+	}
+	return result
+}
+
+// SuggestSlots returns the time frames between start and end of at least the duration in which everyone with working
+// hours works, and no one is busy. Blocks in which someone works elsewhere don't count as busy.
+func SuggestSlots(workingHours []WorkingHours, schedules []Schedule, start, end time.Time, duration time.Duration) ([]Slot, error) {
+	if duration <= 0 {
+		return nil, fmt.Errorf("duration must be positive")
+	}
+	if !end.After(start) {
+		return nil, fmt.Errorf("end time must be after start time")
+	}
+
+	slots := []Slot{{Start: start, End: end}}
+	for _, wh := range workingHours {
+		windows, err := workingWindows(wh, start, end)
+		if err != nil {
+			return nil, err
+		}
+		slots = intersectSlots(slots, windows)
+	}
+
+	var busy []BusyBlock
+	for _, s := range schedules {
+		for _, b := range s.Busy {
+			if b.Status != "workingElsewhere" {
+				busy = append(busy, b)
+			}
+		}
+	}
+
+	result := []Slot{}
+	for _, slot := range subtractBusy(slots, busy) {
+		if slot.End.Sub(slot.Start) >= duration {
+			result = append(result, slot)
+		}
+	}
+	return result, nil
+}
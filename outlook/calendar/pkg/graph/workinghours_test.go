@@ -0,0 +1,88 @@
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkingWindows(t *testing.T) {
+	wh := WorkingHours{
+		DaysOfWeek: []string{"monday", "tuesday", "wednesday", "thursday", "friday"},
+		StartTime:  "09:00",
+		EndTime:    "17:00",
+		TimeZone:   "Europe/Berlin",
+	}
+
+	// Friday noon to Tuesday 10:00 in Berlin (UTC+1)
+	windows, err := workingWindows(wh, time.Date(2024, 11, 8, 11, 0, 0, 0, time.UTC), time.Date(2024, 11, 12, 9, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, []Slot{
+		{Start: time.Date(2024, 11, 8, 11, 0, 0, 0, time.UTC), End: time.Date(2024, 11, 8, 16, 0, 0, 0, time.UTC)},
+		{Start: time.Date(2024, 11, 11, 8, 0, 0, 0, time.UTC), End: time.Date(2024, 11, 11, 16, 0, 0, 0, time.UTC)},
+		{Start: time.Date(2024, 11, 12, 8, 0, 0, 0, time.UTC), End: time.Date(2024, 11, 12, 9, 0, 0, 0, time.UTC)},
+	}, utcSlots(windows))
+
+	// Windows time zone names are used by Graph
+	wh.TimeZone = "Pacific Standard Time"
+	windows, err = workingWindows(wh, time.Date(2024, 11, 8, 0, 0, 0, 0, time.UTC), time.Date(2024, 11, 9, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, []Slot{
+		{Start: time.Date(2024, 11, 8, 0, 0, 0, 0, time.UTC), End: time.Date(2024, 11, 8, 1, 0, 0, 0, time.UTC)},
+		{Start: time.Date(2024, 11, 8, 17, 0, 0, 0, time.UTC), End: time.Date(2024, 11, 9, 0, 0, 0, 0, time.UTC)},
+	}, utcSlots(windows))
+
+	wh.TimeZone = "Customized Time Zone"
+	_, err = workingWindows(wh, time.Date(2024, 11, 8, 0, 0, 0, 0, time.UTC), time.Date(2024, 11, 9, 0, 0, 0, 0, time.UTC))
+	assert.Error(t, err)
+}
+
+func TestSuggestSlots(t *testing.T) {
+	day := func(hour, minute int) time.Time {
+		return time.Date(2024, 11, 11, hour, minute, 0, 0, time.UTC)
+	}
+	workingHours := []WorkingHours{
+		{DaysOfWeek: []string{"monday"}, StartTime: "09:00", EndTime: "17:00", TimeZone: "UTC"},
+		{DaysOfWeek: []string{"Monday"}, StartTime: "10:00", EndTime: "18:00", TimeZone: "UTC"},
+	}
+	schedules := []Schedule{
+		{Email: "adele@contoso.com", Busy: []BusyBlock{
+			{Start: day(11, 0), End: day(12, 0), Status: "busy"},
+			{Start: day(11, 30), End: day(12, 30), Status: "tentative"},
+		}},
+		{Email: "alex@contoso.com", Busy: []BusyBlock{
+			{Start: day(13, 0), End: day(17, 0), Status: "workingElsewhere"},
+			{Start: day(15, 0), End: day(15, 45), Status: "oof"},
+		}},
+	}
+
+	slots, err := SuggestSlots(workingHours, schedules, day(0, 0), day(23, 59), 30*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, []Slot{
+		{Start: day(10, 0), End: day(11, 0)},
+		{Start: day(12, 30), End: day(15, 0)},
+		{Start: day(15, 45), End: day(17, 0)},
+	}, utcSlots(slots))
+
+	slots, err = SuggestSlots(workingHours, schedules, day(0, 0), day(23, 59), 2*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []Slot{{Start: day(12, 30), End: day(15, 0)}}, utcSlots(slots))
+
+	// Without working hours, only the busy times count
+	slots, err = SuggestSlots(nil, schedules[:1], day(10, 0), day(13, 0), time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []Slot{{Start: day(10, 0), End: day(11, 0)}}, utcSlots(slots))
+
+	_, err = SuggestSlots(workingHours, schedules, day(12, 0), day(11, 0), time.Hour)
+	assert.Error(t, err)
+}
+
+func utcSlots(slots []Slot) []Slot {
+	result := make([]Slot, 0, len(slots))
+	for _, s := range slots {
+		result = append(result, Slot{Start: s.Start.UTC(), End: s.End.UTC()})
+	}
+	return result
+}
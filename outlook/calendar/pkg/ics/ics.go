@@ -0,0 +1,142 @@
+// Package ics reads and writes iCalendar (RFC 5545) files, for interop with calendars other than Outlook.
+package ics
+
+import (
+	"bytes"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	prodID = "-//Acorn//Outlook Calendar//EN"
+	// maxLineLength is the maximum length of a content line in octets, excluding the line break
+	maxLineLength = 75
+
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405Z"
+)
+
+// Event is a VEVENT. The start and end are written in UTC, or as dates for all day events, in which case the end is
+// the day after the last day of the event.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	URL         string
+	Start, End  time.Time
+	AllDay      bool
+	Organizer   *Attendee
+	Attendees   []Attendee
+	// RRule is the recurrence rule of the event without the "RRULE:" prefix, e.g. "FREQ=WEEKLY;BYDAY=MO"
+	RRule     string
+	Cancelled bool
+}
+
+// Attendee is an attendee or the organizer of an event.
+type Attendee struct {
+	Email, Name string
+	Optional    bool
+	// Status is the participation status, e.g. ACCEPTED or NEEDS-ACTION. Empty for the organizer.
+	Status string
+}
+
+// Marshal returns the events as a VCALENDAR. now is the time stamp of the events.
+func Marshal(events []Event, now time.Time) []byte {
+	var w writer
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", prodID)
+	w.line("CALSCALE", "GREGORIAN")
+	w.line("METHOD", "PUBLISH")
+	for _, e := range events {
+		w.line("BEGIN", "VEVENT")
+		w.line("UID", e.UID)
+		w.line("DTSTAMP", now.UTC().Format(dateTimeLayout))
+		if e.AllDay {
+			w.line("DTSTART;VALUE=DATE", e.Start.Format(dateLayout))
+			w.line("DTEND;VALUE=DATE", e.End.Format(dateLayout))
+		} else {
+			w.line("DTSTART", e.Start.UTC().Format(dateTimeLayout))
+			w.line("DTEND", e.End.UTC().Format(dateTimeLayout))
+		}
+		if e.RRule != "" {
+			w.line("RRULE", e.RRule)
+		}
+		w.text("SUMMARY", e.Summary)
+		w.text("DESCRIPTION", e.Description)
+		w.text("LOCATION", e.Location)
+		if e.URL != "" {
+			w.line("URL", e.URL)
+		}
+		if e.Organizer != nil {
+			w.line("ORGANIZER"+commonName(e.Organizer.Name), "mailto:"+e.Organizer.Email)
+		}
+		for _, a := range e.Attendees {
+			role := "REQ-PARTICIPANT"
+			if a.Optional {
+				role = "OPT-PARTICIPANT"
+			}
+			params := commonName(a.Name) + ";ROLE=" + role
+			if a.Status != "" {
+				params += ";PARTSTAT=" + a.Status
+			}
+			w.line("ATTENDEE"+params, "mailto:"+a.Email)
+		}
+		if e.Cancelled {
+			w.line("STATUS", "CANCELLED")
+		}
+		w.line("END", "VEVENT")
+	}
+	w.line("END", "VCALENDAR")
+	return w.buf.Bytes()
+}
+
+type writer struct {
+	buf bytes.Buffer
+}
+
+// text writes a property with a text value, unless the value is empty.
+func (w *writer) text(name, value string) {
+	if value != "" {
+		w.line(name, escapeText(value))
+	}
+}
+
+// line writes a content line, folded so that no line is longer than 75 octets.
+func (w *writer) line(name, value string) {
+	line := name + ":" + value
+	// Continuation lines start with a space, which counts towards the limit
+	for limit := maxLineLength; len(line) > limit; limit = maxLineLength - 1 {
+		// Don't split UTF-8 sequences
+		n := limit
+		for !utf8.RuneStart(line[n]) {
+			n--
+		}
+		w.buf.WriteString(line[:n])
+		w.buf.WriteString("\r\n ")
+		line = line[n:]
+	}
+	w.buf.WriteString(line)
+	w.buf.WriteString("\r\n")
+}
+
+// commonName returns the CN parameter for the name, or an empty string if there is no name.
+func commonName(name string) string {
+	if name == "" {
+		return ""
+	}
+	// Parameter values can't contain quotes, and need to be quoted if they contain any of ":;,"
+	name = strings.ReplaceAll(name, `"`, "'")
+	if strings.ContainsAny(name, ":;,") {
+		name = `"` + name + `"`
+	}
+	return ";CN=" + name
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
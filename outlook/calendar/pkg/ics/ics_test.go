@@ -0,0 +1,88 @@
+package ics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshal(t *testing.T) {
+	now := time.Date(2024, 10, 1, 8, 0, 0, 0, time.UTC)
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+
+	data := Marshal([]Event{
+		{
+			UID:         "040000008200E00074C5B7101A82E008",
+			Summary:     "Planning; Q4, draft",
+			Description: "Agenda:\n1. Budget\\Costs",
+			Location:    "Room 1",
+			Start:       time.Date(2024, 10, 2, 9, 0, 0, 0, berlin),
+			End:         time.Date(2024, 10, 2, 10, 0, 0, 0, berlin),
+			Organizer:   &Attendee{Email: "adele@contoso.com", Name: "Vance, Adele"},
+			Attendees: []Attendee{
+				{Email: "alex@contoso.com", Name: "Alex Wilber", Status: "ACCEPTED"},
+				{Email: "megan@contoso.com", Optional: true, Status: "NEEDS-ACTION"},
+			},
+			RRule: "FREQ=WEEKLY;BYDAY=WE;COUNT=4",
+		},
+		{
+			UID:       "holiday",
+			Summary:   "Holiday",
+			Start:     time.Date(2024, 10, 3, 0, 0, 0, 0, berlin),
+			End:       time.Date(2024, 10, 4, 0, 0, 0, 0, berlin),
+			AllDay:    true,
+			Cancelled: true,
+		},
+	}, now)
+
+	assert.Equal(t, strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Acorn//Outlook Calendar//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"BEGIN:VEVENT",
+		"UID:040000008200E00074C5B7101A82E008",
+		"DTSTAMP:20241001T080000Z",
+		"DTSTART:20241002T070000Z",
+		"DTEND:20241002T080000Z",
+		"RRULE:FREQ=WEEKLY;BYDAY=WE;COUNT=4",
+		`SUMMARY:Planning\; Q4\, draft`,
+		`DESCRIPTION:Agenda:\n1. Budget\\Costs`,
+		"LOCATION:Room 1",
+		`ORGANIZER;CN="Vance, Adele":mailto:adele@contoso.com`,
+		"ATTENDEE;CN=Alex Wilber;ROLE=REQ-PARTICIPANT;PARTSTAT=ACCEPTED:mailto:alex@",
+		" contoso.com",
+		"ATTENDEE;ROLE=OPT-PARTICIPANT;PARTSTAT=NEEDS-ACTION:mailto:megan@contoso.co",
+		" m",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:holiday",
+		"DTSTAMP:20241001T080000Z",
+		"DTSTART;VALUE=DATE:20241003",
+		"DTEND;VALUE=DATE:20241004",
+		"SUMMARY:Holiday",
+		"STATUS:CANCELLED",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n"), string(data))
+}
+
+func TestFoldLines(t *testing.T) {
+	var w writer
+	w.text("DESCRIPTION", strings.Repeat("ä", 80))
+
+	lines := strings.Split(strings.TrimSuffix(w.buf.String(), "\r\n"), "\r\n")
+	assert.Len(t, lines, 3)
+	for i, line := range lines {
+		assert.LessOrEqual(t, len(line), maxLineLength, "line %d", i)
+		if i > 0 {
+			assert.True(t, strings.HasPrefix(line, " "), "line %d", i)
+		}
+	}
+	assert.Equal(t, "DESCRIPTION:"+strings.Repeat("ä", 80), strings.ReplaceAll(strings.Join(lines, "\n"), "\n ", ""))
+}
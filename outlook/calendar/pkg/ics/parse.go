@@ -0,0 +1,293 @@
+package ics
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParsedEvent is a VEVENT of a file, or the reason it couldn't be read.
+type ParsedEvent struct {
+	Event Event
+	Err   error
+}
+
+// property is a content line, e.g. DTSTART;TZID=Europe/Berlin:20241002T090000
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Parse reads the VEVENTs of a VCALENDAR, in the order of the file. Times with a TZID are interpreted in the time zone
+// returned by loadLocation, and floating times (neither UTC nor with a TZID) in defaultLoc.
+// Changed or excluded occurrences of recurring events (RECURRENCE-ID, EXDATE) are not supported.
+func Parse(data []byte, loadLocation func(tzid string) (*time.Location, error), defaultLoc *time.Location) ([]ParsedEvent, error) {
+	lines, err := unfold(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		events []ParsedEvent
+		// The components the current line is in, e.g. VCALENDAR, VEVENT, VALARM
+		stack []string
+		props []property
+	)
+	for i, line := range lines {
+		prop, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		switch prop.name {
+		case "BEGIN":
+			stack = append(stack, strings.ToUpper(prop.value))
+			if len(stack) == 1 && stack[0] != "VCALENDAR" {
+				return nil, errors.New("the file is not an iCalendar file, it has to start with BEGIN:VCALENDAR")
+			}
+			if len(stack) == 2 && stack[1] == "VEVENT" {
+				props = nil
+			}
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1] != strings.ToUpper(prop.value) {
+				return nil, fmt.Errorf("line %d: unexpected END:%s", i+1, prop.value)
+			}
+			if len(stack) == 2 && stack[1] == "VEVENT" {
+				event, err := eventFromProperties(props, loadLocation, defaultLoc)
+				events = append(events, ParsedEvent{Event: event, Err: err})
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			// Only the properties of the event itself, not the ones of its alarms
+			if len(stack) == 2 && stack[1] == "VEVENT" {
+				props = append(props, prop)
+			}
+		}
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("the file ends before END:%s", stack[len(stack)-1])
+	}
+
+	return events, nil
+}
+
+// unfold joins the lines that were folded, which start with a space or tab.
+func unfold(data []byte) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read the file: %w", err)
+	}
+	return lines, nil
+}
+
+// parseLine splits a content line into name, parameters and value. Parameter values may be quoted.
+func parseLine(line string) (property, error) {
+	prop := property{params: map[string]string{}}
+
+	end := strings.IndexAny(line, ";:")
+	if end <= 0 {
+		return prop, fmt.Errorf("invalid content line %q", line)
+	}
+	prop.name = strings.ToUpper(line[:end])
+	rest := line[end:]
+
+	for strings.HasPrefix(rest, ";") {
+		rest = rest[1:]
+		eq := strings.Index(rest, "=")
+		if eq <= 0 {
+			return prop, fmt.Errorf("invalid parameter in %q", line)
+		}
+		name := strings.ToUpper(rest[:eq])
+		rest = rest[eq+1:]
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			closing := strings.Index(rest[1:], `"`)
+			if closing < 0 {
+				return prop, fmt.Errorf("unterminated quote in %q", line)
+			}
+			value = rest[1 : closing+1]
+			rest = rest[closing+2:]
+		} else {
+			end := strings.IndexAny(rest, ";:")
+			if end < 0 {
+				return prop, fmt.Errorf("missing value in %q", line)
+			}
+			value = rest[:end]
+			rest = rest[end:]
+		}
+		prop.params[name] = value
+	}
+
+	if !strings.HasPrefix(rest, ":") {
+		return prop, fmt.Errorf("missing value in %q", line)
+	}
+	prop.value = rest[1:]
+	return prop, nil
+}
+
+func eventFromProperties(props []property, loadLocation func(string) (*time.Location, error), defaultLoc *time.Location) (Event, error) {
+	var (
+		event    Event
+		errs     []error
+		end      *time.Time
+		duration *time.Duration
+	)
+	for _, p := range props {
+		switch p.name {
+		case "UID":
+			event.UID = p.value
+		case "SUMMARY":
+			event.Summary = unescapeText(p.value)
+		case "DESCRIPTION":
+			event.Description = unescapeText(p.value)
+		case "LOCATION":
+			event.Location = unescapeText(p.value)
+		case "URL":
+			event.URL = p.value
+		case "DTSTART":
+			t, allDay, err := parseTime(p, loadLocation, defaultLoc)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("invalid DTSTART: %w", err))
+				continue
+			}
+			event.Start, event.AllDay = t, allDay
+		case "DTEND":
+			t, _, err := parseTime(p, loadLocation, defaultLoc)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("invalid DTEND: %w", err))
+				continue
+			}
+			end = &t
+		case "DURATION":
+			d, err := parseDuration(p.value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("invalid DURATION: %w", err))
+				continue
+			}
+			duration = &d
+		case "RRULE":
+			event.RRule = p.value
+		case "ORGANIZER":
+			organizer := attendee(p)
+			event.Organizer = &organizer
+		case "ATTENDEE":
+			event.Attendees = append(event.Attendees, attendee(p))
+		case "STATUS":
+			event.Cancelled = strings.EqualFold(p.value, "CANCELLED")
+		case "RECURRENCE-ID":
+			errs = append(errs, errors.New("changed occurrences of recurring events (RECURRENCE-ID) are not supported"))
+		case "EXDATE":
+			errs = append(errs, errors.New("excluded occurrences of recurring events (EXDATE) are not supported"))
+		}
+	}
+
+	if event.Start.IsZero() && len(errs) == 0 {
+		errs = append(errs, errors.New("DTSTART is required"))
+	}
+	switch {
+	case end != nil:
+		event.End = *end
+	case duration != nil:
+		event.End = event.Start.Add(*duration)
+	case event.AllDay:
+		// An all day event without an end lasts one day
+		event.End = event.Start.AddDate(0, 0, 1)
+	default:
+		event.End = event.Start
+	}
+
+	return event, errors.Join(errs...)
+}
+
+// parseTime parses a DATE or DATE-TIME value, the bool is true for dates.
+func parseTime(p property, loadLocation func(string) (*time.Location, error), defaultLoc *time.Location) (time.Time, bool, error) {
+	if strings.EqualFold(p.params["VALUE"], "DATE") || len(p.value) == len(dateLayout) {
+		t, err := time.ParseInLocation(dateLayout, p.value, time.UTC)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(p.value, "Z") {
+		t, err := time.Parse(dateTimeLayout, p.value)
+		return t, false, err
+	}
+
+	loc := defaultLoc
+	if tzid := p.params["TZID"]; tzid != "" {
+		// Some calendars prefix the TZID with a slash to refer to a global time zone
+		l, err := loadLocation(strings.TrimPrefix(tzid, "/"))
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("unknown time zone %q", tzid)
+		}
+		loc = l
+	}
+	if loc == nil {
+		return time.Time{}, false, fmt.Errorf("%q has no time zone and no default time zone is set", p.value)
+	}
+
+	t, err := time.ParseInLocation(strings.TrimSuffix(dateTimeLayout, "Z"), p.value, loc)
+	return t, false, err
+}
+
+var durationPattern = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseDuration parses a DURATION value, e.g. PT1H30M or P1D. Days are counted as 24 hours.
+func parseDuration(s string) (time.Duration, error) {
+	m := durationPattern.FindStringSubmatch(s)
+	if m == nil || s == "P" || strings.HasSuffix(s, "T") {
+		return 0, fmt.Errorf("%q is not a duration", s)
+	}
+
+	var d time.Duration
+	for i, unit := range []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second} {
+		if m[i+2] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(m[i+2])
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a duration: %w", s, err)
+		}
+		d += time.Duration(n) * unit
+	}
+	if m[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
+
+func attendee(p property) Attendee {
+	email := p.value
+	if len(email) > len("mailto:") && strings.EqualFold(email[:len("mailto:")], "mailto:") {
+		email = email[len("mailto:"):]
+	}
+	return Attendee{
+		Email:    email,
+		Name:     p.params["CN"],
+		Optional: strings.EqualFold(p.params["ROLE"], "OPT-PARTICIPANT") || strings.EqualFold(p.params["ROLE"], "NON-PARTICIPANT"),
+		Status:   strings.ToUpper(p.params["PARTSTAT"]),
+	}
+}
+
+var textUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+func unescapeText(s string) string {
+	return textUnescaper.Replace(s)
+}
@@ -0,0 +1,142 @@
+package ics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const outlookICS = "BEGIN:VCALENDAR\r\n" +
+	"PRODID:-//Microsoft Corporation//Outlook 16.0 MIMEDIR//EN\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VTIMEZONE\r\n" +
+	"TZID:W. Europe Standard Time\r\n" +
+	"BEGIN:STANDARD\r\n" +
+	"DTSTART:16011028T030000\r\n" +
+	"TZOFFSETFROM:+0200\r\n" +
+	"TZOFFSETTO:+0100\r\n" +
+	"END:STANDARD\r\n" +
+	"END:VTIMEZONE\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:weekly-sync\r\n" +
+	"SUMMARY;LANGUAGE=en-us:Weekly sync\\, team A\r\n" +
+	"DESCRIPTION:Agenda:\\n1. Updates\r\n" +
+	"DTSTART;TZID=W. Europe Standard Time:20241007T090000\r\n" +
+	"DTEND;TZID=W. Europe Standard Time:20241007T093000\r\n" +
+	"RRULE:FREQ=WEEKLY;BYDAY=MO;COUNT=10\r\n" +
+	"ORGANIZER;CN=\"Vance, Adele\":mailto:adele@contoso.com\r\n" +
+	"ATTENDEE;ROLE=REQ-PARTICIPANT;PARTSTAT=NEEDS-ACTION;CN=Alex Wilber:mailto:alex@con\r\n" +
+	" toso.com\r\n" +
+	"ATTENDEE;ROLE=OPT-PARTICIPANT;CN=Megan:MAILTO:megan@contoso.com\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"TRIGGER:-PT15M\r\n" +
+	"DESCRIPTION:Reminder\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Holiday\r\n" +
+	"DTSTART;VALUE=DATE:20241003\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Call\r\n" +
+	"DTSTART:20241008T140000Z\r\n" +
+	"DURATION:PT1H30M\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Weekly sync (moved)\r\n" +
+	"RECURRENCE-ID;TZID=W. Europe Standard Time:20241014T090000\r\n" +
+	"DTSTART;TZID=Mars/Olympus:20241014T100000\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParse(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	loadLocation := func(tzid string) (*time.Location, error) {
+		if tzid == "W. Europe Standard Time" {
+			return berlin, nil
+		}
+		return time.LoadLocation(tzid)
+	}
+
+	events, err := Parse([]byte(outlookICS), loadLocation, nil)
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+
+	require.NoError(t, events[0].Err)
+	assert.Equal(t, "weekly-sync", events[0].Event.UID)
+	assert.Equal(t, "Weekly sync, team A", events[0].Event.Summary)
+	assert.Equal(t, "Agenda:\n1. Updates", events[0].Event.Description)
+	assert.True(t, time.Date(2024, 10, 7, 7, 0, 0, 0, time.UTC).Equal(events[0].Event.Start))
+	assert.True(t, time.Date(2024, 10, 7, 7, 30, 0, 0, time.UTC).Equal(events[0].Event.End))
+	assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO;COUNT=10", events[0].Event.RRule)
+	assert.Equal(t, &Attendee{Email: "adele@contoso.com", Name: "Vance, Adele"}, events[0].Event.Organizer)
+	assert.Equal(t, []Attendee{
+		{Email: "alex@contoso.com", Name: "Alex Wilber", Status: "NEEDS-ACTION"},
+		{Email: "megan@contoso.com", Name: "Megan", Optional: true},
+	}, events[0].Event.Attendees)
+
+	require.NoError(t, events[1].Err)
+	assert.True(t, events[1].Event.AllDay)
+	assert.Equal(t, time.Date(2024, 10, 3, 0, 0, 0, 0, time.UTC), events[1].Event.Start)
+	assert.Equal(t, time.Date(2024, 10, 4, 0, 0, 0, 0, time.UTC), events[1].Event.End)
+
+	require.NoError(t, events[2].Err)
+	assert.Equal(t, 90*time.Minute, events[2].Event.End.Sub(events[2].Event.Start))
+
+	assert.ErrorContains(t, events[3].Err, "RECURRENCE-ID")
+	assert.ErrorContains(t, events[3].Err, `unknown time zone "Mars/Olympus"`)
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	event := Event{
+		UID:         "round-trip",
+		Summary:     "Résumé review; part 2",
+		Description: "Line 1\nLine 2, with a comma and a very long text that has to be folded over multiple lines",
+		Start:       time.Date(2024, 10, 1, 9, 0, 0, 0, time.UTC),
+		End:         time.Date(2024, 10, 1, 10, 0, 0, 0, time.UTC),
+		Organizer:   &Attendee{Email: "adele@contoso.com", Name: "Adele"},
+		Attendees:   []Attendee{{Email: "alex@contoso.com", Status: "ACCEPTED"}},
+		RRule:       "FREQ=DAILY;COUNT=3",
+	}
+
+	events, err := Parse(Marshal([]Event{event}, time.Now()), time.LoadLocation, nil)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.NoError(t, events[0].Err)
+	assert.Equal(t, event, events[0].Event)
+}
+
+func TestParseErrors(t *testing.T) {
+	_, err := Parse([]byte("BEGIN:VCARD\r\nEND:VCARD\r\n"), time.LoadLocation, nil)
+	assert.ErrorContains(t, err, "not an iCalendar file")
+
+	_, err = Parse([]byte("BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\n"), time.LoadLocation, nil)
+	assert.ErrorContains(t, err, "ends before END:VEVENT")
+
+	events, err := Parse([]byte("BEGIN:VCALENDAR\nBEGIN:VEVENT\nSUMMARY:Floating\nDTSTART:20241001T090000\nEND:VEVENT\nEND:VCALENDAR\n"), time.LoadLocation, nil)
+	require.NoError(t, err)
+	assert.ErrorContains(t, events[0].Err, "no time zone")
+}
+
+func TestParseDuration(t *testing.T) {
+	for value, expected := range map[string]time.Duration{
+		"PT1H30M": 90 * time.Minute,
+		"P1D":     24 * time.Hour,
+		"P1W":     7 * 24 * time.Hour,
+		"P1DT2H":  26 * time.Hour,
+		"-PT15M":  -15 * time.Minute,
+		"PT45S":   45 * time.Second,
+	} {
+		d, err := parseDuration(value)
+		assert.NoError(t, err, value)
+		assert.Equal(t, expected, d, value)
+	}
+
+	for _, value := range []string{"", "P", "PT", "1H", "PT1.5H"} {
+		_, err := parseDuration(value)
+		assert.Error(t, err, value)
+	}
+}
@@ -3,19 +3,21 @@ package printers
 import (
 	"fmt"
 
+	"github.com/gptscript-ai/tools/common/locale"
 	"github.com/gptscript-ai/tools/outlook/calendar/pkg/graph"
 	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
 )
 
 func PrintCalendar(calendar graph.CalendarInfo) {
-	fmt.Printf("Name: %s\n", util.Deref(calendar.Calendar.GetName()))
-	fmt.Printf("  ID: %s\n", calendar.ID)
+	loc := locale.FromEnv()
+	fmt.Printf("%s: %s\n", loc.T("Name"), util.Deref(calendar.Calendar.GetName()))
+	fmt.Printf("  %s: %s\n", loc.T("ID"), calendar.ID)
 	if calendar.Calendar.GetOwner() != nil {
-		fmt.Printf("  Owner: %s (%s)\n", util.Deref(calendar.Calendar.GetOwner().GetName()), util.Deref(calendar.Calendar.GetOwner().GetAddress()))
-		fmt.Printf("  Owner Type: %s\n", string(calendar.Owner))
+		fmt.Printf("  %s: %s (%s)\n", loc.T("Owner"), util.Deref(calendar.Calendar.GetOwner().GetName()), util.Deref(calendar.Calendar.GetOwner().GetAddress()))
+		fmt.Printf("  %s: %s\n", loc.T("Owner Type"), string(calendar.Owner))
 	} else {
-		fmt.Printf("  Owner: unknown\n")
-		fmt.Printf("  Owner Type: unknown\n")
+		fmt.Printf("  %s: %s\n", loc.T("Owner"), loc.Unknown())
+		fmt.Printf("  %s: %s\n", loc.T("Owner Type"), loc.Unknown())
 	}
 	fmt.Println()
 }
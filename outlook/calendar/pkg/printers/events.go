@@ -3,19 +3,27 @@ package printers
 import (
 	"context"
 	"fmt"
+	"github.com/gptscript-ai/tools/common/locale"
 	"github.com/gptscript-ai/tools/outlook/calendar/pkg/graph"
 	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
 	"github.com/jaytaylor/html2text"
 	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
 	"strings"
+	"time"
 )
 
 func EventToString(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, calendar graph.CalendarInfo, event models.Eventable) string {
 	var calendarName string
-	if calendar.Owner == graph.OwnerTypeUser {
+	switch calendar.Owner {
+	case graph.OwnerTypeUser:
 		calendarName = util.Deref(calendar.Calendar.GetName())
-	} else {
+	case graph.OwnerTypeShared:
+		calendarName = util.Deref(calendar.Calendar.GetName())
+		if owner := calendar.Calendar.GetOwner(); owner != nil {
+			calendarName += " (" + util.Deref(owner.GetAddress()) + ")"
+		}
+	default:
 		groupName, err := graph.GetGroupNameFromID(ctx, client, calendar.ID)
 		if err != nil {
 			calendarName = calendar.ID
@@ -25,42 +33,53 @@ func EventToString(ctx context.Context, client *msgraphsdkgo.GraphServiceClient,
 	}
 
 	var sb strings.Builder
-	sb.WriteString("Subject: " + util.Deref(event.GetSubject()) + "\n")
-	sb.WriteString("  ID: " + util.Deref(event.GetId()) + "\n")
+	loc := locale.FromEnv()
+	sb.WriteString(loc.T("Subject") + ": " + util.Deref(event.GetSubject()) + "\n")
+	sb.WriteString("  " + loc.T("ID") + ": " + util.Deref(event.GetId()) + "\n")
 	startTZ, endTZ := EventDisplayTimeZone(event)
-	sb.WriteString("  Start: " + util.Deref(event.GetStart().GetDateTime()) + startTZ + "\n")
-	sb.WriteString("  End: " + util.Deref(event.GetEnd().GetDateTime()) + endTZ + "\n")
-	sb.WriteString("  In calendar: " + calendarName + " (ID " + calendar.ID + ")\n")
+	sb.WriteString("  " + loc.T("Start") + ": " + eventTime(loc, event.GetStart(), util.Deref(event.GetIsAllDay()), startTZ) + "\n")
+	sb.WriteString("  " + loc.T("End") + ": " + eventTime(loc, event.GetEnd(), util.Deref(event.GetIsAllDay()), endTZ) + "\n")
+	sb.WriteString("  " + loc.T("In calendar") + ": " + calendarName + " (" + loc.T("ID") + " " + calendar.ID + ")\n")
+	if categories := event.GetCategories(); len(categories) > 0 {
+		sb.WriteString("  " + loc.T("Categories") + ": " + strings.Join(categories, ", ") + "\n")
+	}
 	return sb.String()
 }
 
 func PrintEvent(event models.Eventable, detailed bool) {
-	fmt.Printf("Subject: %s\n", util.Deref(event.GetSubject()))
-	fmt.Printf("  ID: %s\n", util.Deref(event.GetId()))
+	loc := locale.FromEnv()
+	fmt.Printf("%s: %s\n", loc.T("Subject"), util.Deref(event.GetSubject()))
+	fmt.Printf("  %s: %s\n", loc.T("ID"), util.Deref(event.GetId()))
 	startTZ, endTZ := EventDisplayTimeZone(event)
-	fmt.Printf("  Start: %s%s\n", util.Deref(event.GetStart().GetDateTime()), startTZ)
-	fmt.Printf("  End: %s%s\n", util.Deref(event.GetEnd().GetDateTime()), endTZ)
+	fmt.Printf("  %s: %s\n", loc.T("Start"), eventTime(loc, event.GetStart(), util.Deref(event.GetIsAllDay()), startTZ))
+	fmt.Printf("  %s: %s\n", loc.T("End"), eventTime(loc, event.GetEnd(), util.Deref(event.GetIsAllDay()), endTZ))
 
 	if detailed {
-		fmt.Printf("  Location: %s\n", util.Deref(event.GetLocation().GetDisplayName()))
-		fmt.Printf("  Is All Day: %t\n", util.Deref(event.GetIsAllDay()))
+		fmt.Printf("  %s: %s\n", loc.T("Location"), util.Deref(event.GetLocation().GetDisplayName()))
+		fmt.Printf("  %s: %s\n", loc.T("Is All Day"), loc.Bool(util.Deref(event.GetIsAllDay())))
 		isRecurring := false
 		if event.GetSeriesMasterId() != nil {
 			isRecurring = true
 		}
-		fmt.Printf("  Is Recurring: %t\n", isRecurring)
-		fmt.Printf("  Is Cancelled: %t\n", util.Deref(event.GetIsCancelled()))
-		fmt.Printf("  Is Online Meeting: %t\n", util.Deref(event.GetIsOnlineMeeting()))
-		fmt.Printf("  Response Status: %s\n", event.GetResponseStatus().GetResponse().String())
-		fmt.Printf("  Attendees: %s\n", strings.Join(util.Map(event.GetAttendees(), func(a models.Attendeeable) string {
-			return fmt.Sprintf("%s (%s), Response: %s", util.Deref(a.GetEmailAddress().GetName()), util.Deref(a.GetEmailAddress().GetAddress()), a.GetStatus().GetResponse().String())
+		fmt.Printf("  %s: %s\n", loc.T("Is Recurring"), loc.Bool(isRecurring))
+		fmt.Printf("  %s: %s\n", loc.T("Is Cancelled"), loc.Bool(util.Deref(event.GetIsCancelled())))
+		if categories := event.GetCategories(); len(categories) > 0 {
+			fmt.Printf("  %s: %s\n", loc.T("Categories"), strings.Join(categories, ", "))
+		}
+		fmt.Printf("  %s: %s\n", loc.T("Is Online Meeting"), loc.Bool(util.Deref(event.GetIsOnlineMeeting())))
+		if joinURL := graph.JoinURL(event); joinURL != "" {
+			fmt.Printf("  %s: %s\n", loc.T("Online Meeting Join URL"), joinURL)
+		}
+		fmt.Printf("  %s: %s\n", loc.T("Response Status"), event.GetResponseStatus().GetResponse().String())
+		fmt.Printf("  %s: %s\n", loc.T("Attendees"), strings.Join(util.Map(event.GetAttendees(), func(a models.Attendeeable) string {
+			return fmt.Sprintf("%s (%s), %s: %s", util.Deref(a.GetEmailAddress().GetName()), util.Deref(a.GetEmailAddress().GetAddress()), loc.T("Response"), a.GetStatus().GetResponse().String())
 		}), ", "))
 		body, err := html2text.FromString(util.Deref(event.GetBody().GetContent()), html2text.Options{
 			PrettyTables: true,
 		})
 		if err == nil {
-			fmt.Printf("  Body: %s\n", strings.ReplaceAll(body, "\n", "\n  "))
-			fmt.Printf("  (End Body)\n")
+			fmt.Printf("  %s: %s\n", loc.T("Body"), strings.ReplaceAll(body, "\n", "\n  "))
+			fmt.Printf("  (%s)\n", loc.T("End Body"))
 		}
 		attachments := event.GetAttachments()
 		if len(attachments) > 0 {
@@ -68,14 +87,14 @@ func PrintEvent(event models.Eventable, detailed bool) {
 				attachmentType := util.Deref(attachment.GetOdataType())
 				if attachmentType == "#microsoft.graph.fileAttachment" {
 					fileAttachment := attachment.(*models.FileAttachment)
-					fmt.Printf("File Attachment: %s, Size: %d bytes, Content Type: %s\n", *fileAttachment.GetName(), *fileAttachment.GetSize(), *fileAttachment.GetContentType())
+					fmt.Printf("%s: %s, %s: %s %s, %s: %s\n", loc.T("File Attachment"), *fileAttachment.GetName(), loc.T("Size"), loc.Int(int64(*fileAttachment.GetSize())), loc.T("bytes"), loc.T("Content Type"), *fileAttachment.GetContentType())
 				} else if attachmentType == "#microsoft.graph.itemAttachment" {
 					itemAttachment := attachment.(*models.ItemAttachment)
-					fmt.Printf("Item Attachment: %s\n", *itemAttachment.GetName())
+					fmt.Printf("%s: %s\n", loc.T("Item Attachment"), *itemAttachment.GetName())
 				}
 			}
 		}
-		fmt.Printf("You can open the event using this link: %s\n", util.Deref(event.GetWebLink()))
+		fmt.Printf("%s: %s\n", loc.T("You can open the event using this link"), util.Deref(event.GetWebLink()))
 	}
 	fmt.Println()
 }
@@ -98,3 +117,20 @@ func EventDisplayTimeZone(event models.Eventable) (string, string) {
 	}
 	return startTZ, endTZ
 }
+
+// eventTime formats the start or end of an event, followed by the time zone from EventDisplayTimeZone.
+// Graph returns the times without an offset, e.g. 2024-11-04T09:30:00.0000000. Without a locale they're printed as is.
+func eventTime(loc locale.Locale, dt models.DateTimeTimeZoneable, allDay bool, tz string) string {
+	value := util.Deref(dt.GetDateTime())
+	t, err := time.Parse("2006-01-02T15:04:05.9999999", value)
+	if !loc.IsSet() || err != nil {
+		return value + tz
+	}
+	if allDay {
+		return loc.Date(t)
+	}
+	if tz == "Z" {
+		tz = " UTC"
+	}
+	return loc.LocalDateTime(t) + tz
+}
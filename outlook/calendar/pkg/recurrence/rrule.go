@@ -0,0 +1,350 @@
+package recurrence
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gptscript-ai/tools/outlook/calendar/pkg/util"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// rruleDays maps the days of the week of Graph to the ones of iCalendar recurrence rules
+var rruleDays = map[string]string{
+	"sunday":    "SU",
+	"monday":    "MO",
+	"tuesday":   "TU",
+	"wednesday": "WE",
+	"thursday":  "TH",
+	"friday":    "FR",
+	"saturday":  "SA",
+}
+
+// rruleSetPositions maps the week indexes of Graph to the BYSETPOS values of iCalendar recurrence rules
+var rruleSetPositions = map[string]string{
+	"first":  "1",
+	"second": "2",
+	"third":  "3",
+	"fourth": "4",
+	"last":   "-1",
+}
+
+// FromGraph converts the recurrence of an event returned by Graph.
+func FromGraph(r models.PatternedRecurrenceable) Recurrence {
+	var result Recurrence
+	if p := r.GetPattern(); p != nil {
+		result.Pattern = RecurrencePattern{
+			DayOfMonth: int(util.Deref(p.GetDayOfMonth())),
+			DaysOfWeek: util.Map(p.GetDaysOfWeek(), func(d models.DayOfWeek) string {
+				return d.String()
+			}),
+			Interval: int(util.Deref(p.GetInterval())),
+			Month:    int(util.Deref(p.GetMonth())),
+		}
+		if p.GetFirstDayOfWeek() != nil {
+			result.Pattern.FirstDayOfWeek = p.GetFirstDayOfWeek().String()
+		}
+		if p.GetIndex() != nil {
+			result.Pattern.Index = p.GetIndex().String()
+		}
+		if p.GetTypeEscaped() != nil {
+			result.Pattern.RecurrenceType = p.GetTypeEscaped().String()
+		}
+	}
+	if rr := r.GetRangeEscaped(); rr != nil {
+		result.Range = RecurrenceRange{
+			NumberOfOccurrences: int(util.Deref(rr.GetNumberOfOccurrences())),
+		}
+		if rr.GetStartDate() != nil {
+			result.Range.StartDate = rr.GetStartDate().String()
+		}
+		if rr.GetEndDate() != nil {
+			result.Range.EndDate = rr.GetEndDate().String()
+		}
+		if rr.GetTypeEscaped() != nil {
+			result.Range.RecurrenceType = rr.GetTypeEscaped().String()
+		}
+	}
+	return result
+}
+
+// RRule returns the iCalendar recurrence rule (RFC 5545) of the recurrence, without the "RRULE:" prefix.
+// The last occurrence of an end date range is included: for all day events, the end date is the UNTIL date, otherwise
+// UNTIL is the end of that day in loc.
+func (r Recurrence) RRule(allDay bool, loc *time.Location) (string, error) {
+	var parts []string
+	byDay := func() error {
+		if len(r.Pattern.DaysOfWeek) == 0 {
+			return fmt.Errorf("recurrence type %s requires days of the week", r.Pattern.RecurrenceType)
+		}
+		days := make([]string, 0, len(r.Pattern.DaysOfWeek))
+		for _, d := range r.Pattern.DaysOfWeek {
+			day, ok := rruleDays[strings.ToLower(d)]
+			if !ok {
+				return fmt.Errorf("invalid day of the week %q", d)
+			}
+			days = append(days, day)
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+		return nil
+	}
+	setPosition := func() error {
+		index := r.Pattern.Index
+		if index == "" {
+			index = "first"
+		}
+		position, ok := rruleSetPositions[strings.ToLower(index)]
+		if !ok {
+			return fmt.Errorf("invalid week index %q", r.Pattern.Index)
+		}
+		parts = append(parts, "BYSETPOS="+position)
+		return nil
+	}
+
+	switch strings.ToLower(r.Pattern.RecurrenceType) {
+	case "daily":
+		parts = append(parts, "FREQ=DAILY")
+	case "weekly":
+		parts = append(parts, "FREQ=WEEKLY")
+		if err := byDay(); err != nil {
+			return "", err
+		}
+		if day, ok := rruleDays[strings.ToLower(r.Pattern.FirstDayOfWeek)]; ok {
+			parts = append(parts, "WKST="+day)
+		}
+	case "absolutemonthly":
+		parts = append(parts, "FREQ=MONTHLY", "BYMONTHDAY="+strconv.Itoa(r.Pattern.DayOfMonth))
+	case "relativemonthly":
+		parts = append(parts, "FREQ=MONTHLY")
+		if err := byDay(); err != nil {
+			return "", err
+		}
+		if err := setPosition(); err != nil {
+			return "", err
+		}
+	case "absoluteyearly":
+		parts = append(parts, "FREQ=YEARLY", "BYMONTH="+strconv.Itoa(r.Pattern.Month), "BYMONTHDAY="+strconv.Itoa(r.Pattern.DayOfMonth))
+	case "relativeyearly":
+		parts = append(parts, "FREQ=YEARLY", "BYMONTH="+strconv.Itoa(r.Pattern.Month))
+		if err := byDay(); err != nil {
+			return "", err
+		}
+		if err := setPosition(); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("invalid recurrence type %q", r.Pattern.RecurrenceType)
+	}
+	if r.Pattern.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Pattern.Interval))
+	}
+
+	switch strings.ToLower(r.Range.RecurrenceType) {
+	case "enddate":
+		endDate, err := time.ParseInLocation(time.DateOnly, r.Range.EndDate, loc)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse end date: %w", err)
+		}
+		if allDay {
+			parts = append(parts, "UNTIL="+endDate.Format("20060102"))
+		} else {
+			parts = append(parts, "UNTIL="+endDate.AddDate(0, 0, 1).Add(-time.Second).UTC().Format("20060102T150405Z"))
+		}
+	case "numbered":
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Range.NumberOfOccurrences))
+	}
+
+	return strings.Join(parts, ";"), nil
+}
+
+// FromRRule converts an iCalendar recurrence rule (without the "RRULE:" prefix) of a series that starts at start.
+// Only the rules that Outlook can represent are supported, e.g. not hourly ones.
+func FromRRule(rule string, start time.Time) (Recurrence, error) {
+	values := map[string]string{}
+	for _, part := range strings.Split(strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:"), ";") {
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return Recurrence{}, fmt.Errorf("invalid recurrence rule part %q", part)
+		}
+		values[strings.ToUpper(name)] = strings.ToUpper(value)
+	}
+
+	for name := range values {
+		switch name {
+		case "FREQ", "INTERVAL", "COUNT", "UNTIL", "BYDAY", "BYMONTHDAY", "BYMONTH", "BYSETPOS", "WKST":
+		default:
+			return Recurrence{}, fmt.Errorf("%s is not supported in recurrence rules", name)
+		}
+	}
+
+	r := Recurrence{
+		Pattern: RecurrencePattern{Interval: 1},
+		Range: RecurrenceRange{
+			StartDate:      start.Format(time.DateOnly),
+			RecurrenceType: "noEnd",
+		},
+	}
+	if v, ok := values["INTERVAL"]; ok {
+		interval, err := strconv.Atoi(v)
+		if err != nil || interval < 1 {
+			return Recurrence{}, fmt.Errorf("invalid INTERVAL %q", v)
+		}
+		r.Pattern.Interval = interval
+	}
+	if v, ok := values["WKST"]; ok {
+		day, err := graphDay(v)
+		if err != nil {
+			return Recurrence{}, err
+		}
+		r.Pattern.FirstDayOfWeek = day
+	}
+
+	// BYDAY is a list of days, each optionally with the week of the month or year, e.g. MO,WE or -1FR
+	var ordinal string
+	for _, v := range strings.Split(values["BYDAY"], ",") {
+		if v == "" {
+			continue
+		}
+		split := strings.IndexFunc(v, func(r rune) bool { return r >= 'A' && r <= 'Z' })
+		if split < 0 {
+			return Recurrence{}, fmt.Errorf("invalid BYDAY %q", values["BYDAY"])
+		}
+		if split > 0 {
+			if ordinal != "" && ordinal != v[:split] {
+				return Recurrence{}, fmt.Errorf("BYDAY with different weeks (%s) is not supported", values["BYDAY"])
+			}
+			ordinal = v[:split]
+		}
+		day, err := graphDay(v[split:])
+		if err != nil {
+			return Recurrence{}, err
+		}
+		r.Pattern.DaysOfWeek = append(r.Pattern.DaysOfWeek, day)
+	}
+	if v, ok := values["BYSETPOS"]; ok {
+		if ordinal != "" && ordinal != v {
+			return Recurrence{}, fmt.Errorf("BYSETPOS %s conflicts with BYDAY %s", v, values["BYDAY"])
+		}
+		ordinal = v
+	}
+
+	dayOfMonth, month := start.Day(), int(start.Month())
+	if v, ok := values["BYMONTHDAY"]; ok {
+		d, err := strconv.Atoi(v)
+		if err != nil || d < 1 || d > 31 {
+			return Recurrence{}, fmt.Errorf("BYMONTHDAY %q is not supported, it has to be a single day from 1 to 31", v)
+		}
+		dayOfMonth = d
+	}
+	if v, ok := values["BYMONTH"]; ok {
+		m, err := strconv.Atoi(v)
+		if err != nil || m < 1 || m > 12 {
+			return Recurrence{}, fmt.Errorf("BYMONTH %q is not supported, it has to be a single month from 1 to 12", v)
+		}
+		month = m
+	}
+
+	relative := func(recurrenceType string) error {
+		index, err := graphIndex(ordinal)
+		if err != nil {
+			return err
+		}
+		if len(r.Pattern.DaysOfWeek) > 1 {
+			return fmt.Errorf("a %s event on multiple days of the week is not supported", strings.ToLower(values["FREQ"]))
+		}
+		r.Pattern.RecurrenceType = recurrenceType
+		r.Pattern.Index = index
+		return nil
+	}
+
+	switch values["FREQ"] {
+	case "DAILY":
+		r.Pattern.RecurrenceType = "daily"
+		r.Pattern.DaysOfWeek = nil
+	case "WEEKLY":
+		r.Pattern.RecurrenceType = "weekly"
+		if len(r.Pattern.DaysOfWeek) == 0 {
+			r.Pattern.DaysOfWeek = []string{strings.ToLower(start.Weekday().String())}
+		}
+	case "MONTHLY":
+		if len(r.Pattern.DaysOfWeek) > 0 {
+			if err := relative("relativeMonthly"); err != nil {
+				return Recurrence{}, err
+			}
+		} else {
+			r.Pattern.RecurrenceType = "absoluteMonthly"
+			r.Pattern.DayOfMonth = dayOfMonth
+		}
+	case "YEARLY":
+		r.Pattern.Month = month
+		if len(r.Pattern.DaysOfWeek) > 0 {
+			if err := relative("relativeYearly"); err != nil {
+				return Recurrence{}, err
+			}
+		} else {
+			r.Pattern.RecurrenceType = "absoluteYearly"
+			r.Pattern.DayOfMonth = dayOfMonth
+		}
+	case "":
+		return Recurrence{}, fmt.Errorf("FREQ is required in recurrence rules")
+	default:
+		return Recurrence{}, fmt.Errorf("FREQ=%s is not supported, only DAILY, WEEKLY, MONTHLY and YEARLY", values["FREQ"])
+	}
+
+	if v, ok := values["COUNT"]; ok {
+		count, err := strconv.Atoi(v)
+		if err != nil || count < 1 {
+			return Recurrence{}, fmt.Errorf("invalid COUNT %q", v)
+		}
+		r.Range.RecurrenceType = "numbered"
+		r.Range.NumberOfOccurrences = count
+	} else if v, ok := values["UNTIL"]; ok {
+		until, err := parseUntil(v, start.Location())
+		if err != nil {
+			return Recurrence{}, err
+		}
+		r.Range.RecurrenceType = "endDate"
+		r.Range.EndDate = until.Format(time.DateOnly)
+	}
+
+	return r, nil
+}
+
+// parseUntil parses the UNTIL of a recurrence rule, a date or a date-time in UTC or the time zone of the series.
+func parseUntil(v string, loc *time.Location) (time.Time, error) {
+	if t, err := time.ParseInLocation("20060102", v, loc); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("20060102T150405Z", v); err == nil {
+		return t.In(loc), nil
+	}
+	if t, err := time.ParseInLocation("20060102T150405", v, loc); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid UNTIL %q", v)
+}
+
+func graphDay(rruleDay string) (string, error) {
+	for day, abbreviation := range rruleDays {
+		if abbreviation == rruleDay {
+			return day, nil
+		}
+	}
+	return "", fmt.Errorf("invalid day of the week %q", rruleDay)
+}
+
+func graphIndex(ordinal string) (string, error) {
+	if ordinal == "" {
+		return "first", nil
+	}
+	for index, position := range rruleSetPositions {
+		if position == strings.TrimPrefix(ordinal, "+") {
+			return index, nil
+		}
+	}
+	return "", fmt.Errorf("the week %s of a month is not supported, only 1 to 4 and -1 (last)", ordinal)
+}
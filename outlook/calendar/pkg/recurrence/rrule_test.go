@@ -0,0 +1,181 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRRule(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name       string
+		recurrence Recurrence
+		allDay     bool
+		want       string
+	}{
+		{
+			name: "daily without end",
+			recurrence: Recurrence{
+				Pattern: RecurrencePattern{RecurrenceType: "daily", Interval: 1},
+				Range:   RecurrenceRange{RecurrenceType: "noEnd", StartDate: "2024-10-01"},
+			},
+			want: "FREQ=DAILY",
+		},
+		{
+			name: "weekly until an end date",
+			recurrence: Recurrence{
+				Pattern: RecurrencePattern{RecurrenceType: "weekly", Interval: 2, DaysOfWeek: []string{"monday", "wednesday"}, FirstDayOfWeek: "sunday"},
+				Range:   RecurrenceRange{RecurrenceType: "endDate", StartDate: "2024-10-01", EndDate: "2024-12-31"},
+			},
+			want: "FREQ=WEEKLY;BYDAY=MO,WE;WKST=SU;INTERVAL=2;UNTIL=20241231T225959Z",
+		},
+		{
+			name: "all day until an end date",
+			recurrence: Recurrence{
+				Pattern: RecurrencePattern{RecurrenceType: "absoluteMonthly", Interval: 1, DayOfMonth: 15},
+				Range:   RecurrenceRange{RecurrenceType: "endDate", StartDate: "2024-10-15", EndDate: "2025-03-15"},
+			},
+			allDay: true,
+			want:   "FREQ=MONTHLY;BYMONTHDAY=15;UNTIL=20250315",
+		},
+		{
+			name: "last friday of the month",
+			recurrence: Recurrence{
+				Pattern: RecurrencePattern{RecurrenceType: "relativeMonthly", Interval: 1, DaysOfWeek: []string{"friday"}, Index: "last"},
+				Range:   RecurrenceRange{RecurrenceType: "numbered", StartDate: "2024-10-25", NumberOfOccurrences: 6},
+			},
+			want: "FREQ=MONTHLY;BYDAY=FR;BYSETPOS=-1;COUNT=6",
+		},
+		{
+			name: "yearly",
+			recurrence: Recurrence{
+				Pattern: RecurrencePattern{RecurrenceType: "absoluteYearly", Interval: 1, DayOfMonth: 1, Month: 4},
+				Range:   RecurrenceRange{RecurrenceType: "noEnd", StartDate: "2025-04-01"},
+			},
+			want: "FREQ=YEARLY;BYMONTH=4;BYMONTHDAY=1",
+		},
+		{
+			name: "first monday of march",
+			recurrence: Recurrence{
+				Pattern: RecurrencePattern{RecurrenceType: "relativeYearly", Interval: 1, DaysOfWeek: []string{"monday"}, Index: "first", Month: 3},
+				Range:   RecurrenceRange{RecurrenceType: "noEnd", StartDate: "2025-03-03"},
+			},
+			want: "FREQ=YEARLY;BYMONTH=3;BYDAY=MO;BYSETPOS=1",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.recurrence.RRule(tc.allDay, berlin)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	_, err = Recurrence{Pattern: RecurrencePattern{RecurrenceType: "weekly"}}.RRule(false, berlin)
+	assert.ErrorContains(t, err, "requires days of the week")
+}
+
+func TestFromRRule(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	// A Monday
+	start := time.Date(2024, 10, 7, 9, 0, 0, 0, berlin)
+
+	for _, tc := range []struct {
+		rule string
+		want Recurrence
+	}{
+		{
+			rule: "FREQ=DAILY;INTERVAL=2",
+			want: Recurrence{
+				Pattern: RecurrencePattern{RecurrenceType: "daily", Interval: 2},
+				Range:   RecurrenceRange{RecurrenceType: "noEnd", StartDate: "2024-10-07"},
+			},
+		},
+		{
+			rule: "FREQ=WEEKLY;COUNT=10",
+			want: Recurrence{
+				Pattern: RecurrencePattern{RecurrenceType: "weekly", Interval: 1, DaysOfWeek: []string{"monday"}},
+				Range:   RecurrenceRange{RecurrenceType: "numbered", StartDate: "2024-10-07", NumberOfOccurrences: 10},
+			},
+		},
+		{
+			rule: "RRULE:FREQ=WEEKLY;BYDAY=MO,WE;WKST=SU;UNTIL=20241231T225959Z",
+			want: Recurrence{
+				Pattern: RecurrencePattern{RecurrenceType: "weekly", Interval: 1, DaysOfWeek: []string{"monday", "wednesday"}, FirstDayOfWeek: "sunday"},
+				Range:   RecurrenceRange{RecurrenceType: "endDate", StartDate: "2024-10-07", EndDate: "2024-12-31"},
+			},
+		},
+		{
+			rule: "FREQ=MONTHLY",
+			want: Recurrence{
+				Pattern: RecurrencePattern{RecurrenceType: "absoluteMonthly", Interval: 1, DayOfMonth: 7},
+				Range:   RecurrenceRange{RecurrenceType: "noEnd", StartDate: "2024-10-07"},
+			},
+		},
+		{
+			rule: "FREQ=MONTHLY;BYDAY=-1FR",
+			want: Recurrence{
+				Pattern: RecurrencePattern{RecurrenceType: "relativeMonthly", Interval: 1, DaysOfWeek: []string{"friday"}, Index: "last"},
+				Range:   RecurrenceRange{RecurrenceType: "noEnd", StartDate: "2024-10-07"},
+			},
+		},
+		{
+			rule: "FREQ=YEARLY;BYMONTH=3;BYDAY=MO;BYSETPOS=1;UNTIL=20300301",
+			want: Recurrence{
+				Pattern: RecurrencePattern{RecurrenceType: "relativeYearly", Interval: 1, DaysOfWeek: []string{"monday"}, Index: "first", Month: 3},
+				Range:   RecurrenceRange{RecurrenceType: "endDate", StartDate: "2024-10-07", EndDate: "2030-03-01"},
+			},
+		},
+		{
+			rule: "FREQ=YEARLY",
+			want: Recurrence{
+				Pattern: RecurrencePattern{RecurrenceType: "absoluteYearly", Interval: 1, DayOfMonth: 7, Month: 10},
+				Range:   RecurrenceRange{RecurrenceType: "noEnd", StartDate: "2024-10-07"},
+			},
+		},
+	} {
+		t.Run(tc.rule, func(t *testing.T) {
+			got, err := FromRRule(tc.rule, start)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	for rule, expected := range map[string]string{
+		"FREQ=HOURLY":                   "FREQ=HOURLY is not supported",
+		"FREQ=DAILY;BYHOUR=9":           "BYHOUR is not supported",
+		"INTERVAL=2":                    "FREQ is required",
+		"FREQ=MONTHLY;BYDAY=1MO,3MO":    "different weeks",
+		"FREQ=MONTHLY;BYDAY=2MO,2TU":    "multiple days of the week",
+		"FREQ=MONTHLY;BYDAY=5FR":        "the week 5 of a month is not supported",
+		"FREQ=MONTHLY;BYMONTHDAY=1,15":  "has to be a single day",
+		"FREQ=WEEKLY;BYDAY=XY":          `invalid day of the week "XY"`,
+		"FREQ=WEEKLY;UNTIL=next-friday": "invalid UNTIL",
+	} {
+		_, err := FromRRule(rule, start)
+		assert.ErrorContains(t, err, expected, rule)
+	}
+}
+
+func TestRRuleRoundTrip(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	start := time.Date(2024, 10, 7, 9, 0, 0, 0, berlin)
+
+	for _, rule := range []string{
+		"FREQ=WEEKLY;BYDAY=MO,WE;INTERVAL=2;UNTIL=20241231T225959Z",
+		"FREQ=MONTHLY;BYDAY=FR;BYSETPOS=-1;COUNT=6",
+		"FREQ=YEARLY;BYMONTH=4;BYMONTHDAY=1",
+	} {
+		r, err := FromRRule(rule, start)
+		require.NoError(t, err, rule)
+		got, err := r.RRule(false, berlin)
+		require.NoError(t, err, rule)
+		assert.Equal(t, rule, got)
+	}
+}
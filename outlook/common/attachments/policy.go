@@ -0,0 +1,150 @@
+package attachments
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// EnvMaxSizeBytes limits the size of attachments that may be downloaded or attached. 0 or unset means no limit.
+	EnvMaxSizeBytes = "OUTLOOK_ATTACHMENT_MAX_SIZE_BYTES"
+	// EnvBlockedExtensions is a comma separated list of file extensions (e.g. ".exe,.js") that are never downloaded or attached.
+	EnvBlockedExtensions = "OUTLOOK_ATTACHMENT_BLOCKED_EXTENSIONS"
+	// EnvScanCommand is an optional command that is run for every attachment, with the file contents on stdin and the
+	// file name as its last argument. A non-zero exit code rejects the attachment and its output is used as the reason.
+	EnvScanCommand = "OUTLOOK_ATTACHMENT_SCAN_COMMAND"
+	// EnvScanTimeoutSeconds is the timeout for a single scan. Default is 60.
+	EnvScanTimeoutSeconds = "OUTLOOK_ATTACHMENT_SCAN_TIMEOUT_SECONDS"
+)
+
+const (
+	RuleMaxSize          = "max_size"
+	RuleBlockedExtension = "blocked_extension"
+	RuleScan             = "scan"
+)
+
+// PolicyViolationError is returned when an attachment is rejected by the policy.
+type PolicyViolationError struct {
+	File   string `json:"file"`
+	Rule   string `json:"rule"`
+	Reason string `json:"reason"`
+}
+
+func (e *PolicyViolationError) Error() string {
+	return fmt.Sprintf("attachment policy violation for file %q (%s): %s", e.File, e.Rule, e.Reason)
+}
+
+// JSON returns the violation as a JSON object, for tools that want to report it in a structured way.
+func (e *PolicyViolationError) JSON() string {
+	b, _ := json.Marshal(e)
+	return string(b)
+}
+
+type Policy struct {
+	MaxSizeBytes      int64
+	BlockedExtensions []string
+	ScanCommand       []string
+	ScanTimeout       time.Duration
+}
+
+// PolicyFromEnv reads the attachment policy configured by the deployment. Without any configuration, every
+// attachment is allowed.
+func PolicyFromEnv() (Policy, error) {
+	p := Policy{
+		ScanTimeout: 60 * time.Second,
+	}
+
+	if v := os.Getenv(EnvMaxSizeBytes); v != "" {
+		size, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || size < 0 {
+			return Policy{}, fmt.Errorf("invalid value for %s: %q", EnvMaxSizeBytes, v)
+		}
+		p.MaxSizeBytes = size
+	}
+
+	for _, ext := range strings.Split(os.Getenv(EnvBlockedExtensions), ",") {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		p.BlockedExtensions = append(p.BlockedExtensions, ext)
+	}
+
+	p.ScanCommand = strings.Fields(os.Getenv(EnvScanCommand))
+
+	if v := os.Getenv(EnvScanTimeoutSeconds); v != "" {
+		seconds, err := strconv.Atoi(v)
+		if err != nil || seconds <= 0 {
+			return Policy{}, fmt.Errorf("invalid value for %s: %q", EnvScanTimeoutSeconds, v)
+		}
+		p.ScanTimeout = time.Duration(seconds) * time.Second
+	}
+
+	return p, nil
+}
+
+// CheckMetadata validates everything that is known before the content is available, so that downloads
+// of oversized or blocked files can be skipped early.
+func (p Policy) CheckMetadata(name string, size int64) error {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, blocked := range p.BlockedExtensions {
+		if ext == blocked {
+			return &PolicyViolationError{File: name, Rule: RuleBlockedExtension, Reason: fmt.Sprintf("files with extension %s are not allowed", ext)}
+		}
+	}
+
+	if p.MaxSizeBytes > 0 && size > p.MaxSizeBytes {
+		return &PolicyViolationError{File: name, Rule: RuleMaxSize, Reason: fmt.Sprintf("file size %d bytes exceeds the maximum of %d bytes", size, p.MaxSizeBytes)}
+	}
+
+	return nil
+}
+
+// Check validates a file against the whole policy, including the scan hook.
+func (p Policy) Check(ctx context.Context, name string, data []byte) error {
+	if err := p.CheckMetadata(name, int64(len(data))); err != nil {
+		return err
+	}
+
+	if len(p.ScanCommand) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.ScanTimeout)
+	defer cancel()
+
+	args := append(p.ScanCommand[1:len(p.ScanCommand):len(p.ScanCommand)], filepath.Base(name))
+	cmd := exec.CommandContext(ctx, p.ScanCommand[0], args...)
+	cmd.Stdin = bytes.NewReader(data)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("attachment scan of %q timed out after %s", name, p.ScanTimeout)
+		}
+
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return fmt.Errorf("failed to run attachment scan command: %w", err)
+		}
+
+		reason := strings.TrimSpace(string(out))
+		if reason == "" {
+			reason = fmt.Sprintf("scan command exited with code %d", exitErr.ExitCode())
+		}
+		return &PolicyViolationError{File: name, Rule: RuleScan, Reason: reason}
+	}
+
+	return nil
+}
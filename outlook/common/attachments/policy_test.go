@@ -0,0 +1,114 @@
+package attachments
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPolicyFromEnv(t *testing.T) {
+	t.Setenv(EnvMaxSizeBytes, "1024")
+	t.Setenv(EnvBlockedExtensions, " .EXE, js,,.Bat ")
+	t.Setenv(EnvScanCommand, "clamdscan --no-summary -")
+	t.Setenv(EnvScanTimeoutSeconds, "5")
+
+	p, err := PolicyFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.MaxSizeBytes != 1024 || p.ScanTimeout != 5*time.Second {
+		t.Errorf("unexpected policy %+v", p)
+	}
+	if strings.Join(p.BlockedExtensions, ",") != ".exe,.js,.bat" {
+		t.Errorf("unexpected blocked extensions %v", p.BlockedExtensions)
+	}
+	if strings.Join(p.ScanCommand, " ") != "clamdscan --no-summary -" {
+		t.Errorf("unexpected scan command %v", p.ScanCommand)
+	}
+
+	for env, value := range map[string]string{EnvMaxSizeBytes: "-1", EnvScanTimeoutSeconds: "0"} {
+		t.Run(env, func(t *testing.T) {
+			t.Setenv(env, value)
+			if _, err := PolicyFromEnv(); err == nil {
+				t.Errorf("expected an error for %s=%s", env, value)
+			}
+		})
+	}
+}
+
+func TestPolicyFromEnvDefaults(t *testing.T) {
+	for _, env := range []string{EnvMaxSizeBytes, EnvBlockedExtensions, EnvScanCommand, EnvScanTimeoutSeconds} {
+		t.Setenv(env, "")
+	}
+
+	p, err := PolicyFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.MaxSizeBytes != 0 || len(p.BlockedExtensions) != 0 || len(p.ScanCommand) != 0 || p.ScanTimeout != 60*time.Second {
+		t.Errorf("unexpected default policy %+v", p)
+	}
+	if err := p.Check(context.Background(), "setup.exe", make([]byte, 1<<20)); err != nil {
+		t.Errorf("expected every attachment to be allowed without a policy, got %v", err)
+	}
+}
+
+func TestCheckMetadata(t *testing.T) {
+	p := Policy{MaxSizeBytes: 100, BlockedExtensions: []string{".exe"}}
+
+	if err := p.CheckMetadata("report.pdf", 100); err != nil {
+		t.Errorf("expected the file to be allowed, got %v", err)
+	}
+
+	for name, rule := range map[string]string{"Setup.EXE": RuleBlockedExtension, "report.pdf": RuleMaxSize} {
+		var violation *PolicyViolationError
+		if err := p.CheckMetadata(name, 101); !errors.As(err, &violation) || violation.Rule != rule || violation.File != name {
+			t.Errorf("expected a %s violation for %s, got %v", rule, name, err)
+		}
+	}
+
+	violation := &PolicyViolationError{File: "a.exe", Rule: RuleBlockedExtension, Reason: "not allowed"}
+	if violation.JSON() != `{"file":"a.exe","rule":"blocked_extension","reason":"not allowed"}` {
+		t.Errorf("unexpected JSON %s", violation.JSON())
+	}
+}
+
+func TestCheckScan(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	// The file name is passed as the last argument, which is $0 of the script
+	p := Policy{
+		ScanCommand: []string{"sh", "-c", `if grep -q EICAR; then echo "infected: $0"; exit 1; fi`},
+		ScanTimeout: 10 * time.Second,
+	}
+	if err := p.Check(context.Background(), "notes.txt", []byte("hello")); err != nil {
+		t.Errorf("expected the clean file to be allowed, got %v", err)
+	}
+
+	var violation *PolicyViolationError
+	if err := p.Check(context.Background(), "dir/virus.txt", []byte("X5O EICAR test")); !errors.As(err, &violation) ||
+		violation.Rule != RuleScan || violation.Reason != "infected: virus.txt" {
+		t.Errorf("expected a scan violation, got %v", err)
+	}
+
+	p.ScanCommand = []string{"sh", "-c", "exit 3"}
+	if err := p.Check(context.Background(), "a.txt", nil); !errors.As(err, &violation) || violation.Reason != "scan command exited with code 3" {
+		t.Errorf("expected the exit code as reason, got %v", err)
+	}
+
+	p.ScanCommand = []string{"sh", "-c", "exec sleep 5"}
+	p.ScanTimeout = 100 * time.Millisecond
+	if err := p.Check(context.Background(), "a.txt", nil); err == nil || errors.As(err, &violation) || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected a timeout error, got %v", err)
+	}
+
+	p.ScanCommand = []string{"/nonexistent/scanner"}
+	if err := p.Check(context.Background(), "a.txt", nil); err == nil || errors.As(err, &violation) {
+		t.Errorf("expected an error for a missing scan command, got %v", err)
+	}
+}
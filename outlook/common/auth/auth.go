@@ -0,0 +1,186 @@
+// Package auth lets the Outlook tools run as a daemon, without a signed-in user.
+// The tools then get app-only tokens for Microsoft Graph with the OAuth 2.0 client credentials flow, and work on the
+// data of a target user instead of /me, e.g. a shared mailbox that a service agent processes. The app needs
+// application permissions for Graph, granted by an admin, so no interactive consent is needed.
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+const (
+	// EnvTenantID, EnvClientID and EnvClientSecret identify the app registration used for app-only auth. App-only auth
+	// is used when all of them are set.
+	EnvTenantID     = "OUTLOOK_TENANT_ID"
+	EnvClientID     = "OUTLOOK_CLIENT_ID"
+	EnvClientSecret = "OUTLOOK_CLIENT_SECRET"
+	// EnvUser is the user principal name or ID of the user whose data is used with app-only auth. The --user flag
+	// takes precedence.
+	EnvUser = "OUTLOOK_USER"
+)
+
+// graphScope requests the application permissions that were granted to the app. App-only tokens can't be limited to
+// the scopes of a request.
+const graphScope = "https://graph.microsoft.com/.default"
+
+// tokenRefreshMargin is how long before it expires a token is replaced, so that it doesn't expire during a request.
+const tokenRefreshMargin = 5 * time.Minute
+
+// tokenClient sends the token requests of credentials without an HTTP client, so that an unresponsive identity
+// platform fails the tool instead of blocking it.
+var tokenClient = &http.Client{Timeout: 30 * time.Second}
+
+var user string
+
+// SetUser sets the target user for app-only auth, overriding OUTLOOK_USER.
+func SetUser(upn string) {
+	user = strings.TrimSpace(upn)
+}
+
+// User returns the target user for app-only auth, or an empty string if there is none.
+func User() string {
+	if user != "" {
+		return user
+	}
+	return strings.TrimSpace(os.Getenv(EnvUser))
+}
+
+// ClientCredentials gets app-only tokens with the client credentials flow. It implements azcore.TokenCredential, so
+// it can be used in place of the static token of the signed-in user.
+type ClientCredentials struct {
+	TenantID, ClientID, ClientSecret string
+	// TokenURL defaults to the token endpoint of the tenant in the Microsoft identity platform
+	TokenURL string
+	// HTTPClient sends the token requests, defaults to a client with a timeout of 30s
+	HTTPClient *http.Client
+
+	lock      sync.Mutex
+	token     string
+	expiresOn time.Time
+}
+
+// AppOnlyEnv returns the variables of app-only auth from the environment, or nil if app-only auth isn't configured.
+// It fails if only some of the app registration variables are set. The target user is included if it is set, it can
+// also be passed to each tool with --user.
+func AppOnlyEnv() (map[string]string, error) {
+	c, err := clientCredentials()
+	if c == nil || err != nil {
+		return nil, err
+	}
+	env := map[string]string{
+		EnvTenantID:     c.TenantID,
+		EnvClientID:     c.ClientID,
+		EnvClientSecret: c.ClientSecret,
+	}
+	if user := strings.TrimSpace(os.Getenv(EnvUser)); user != "" {
+		env[EnvUser] = user
+	}
+	return env, nil
+}
+
+// ClientCredentialsFromEnv returns the client credentials from the environment, or nil if app-only auth isn't
+// configured. It fails if only some of the variables are set, or if there is no target user.
+func ClientCredentialsFromEnv() (*ClientCredentials, error) {
+	c, err := clientCredentials()
+	if c == nil || err != nil {
+		return nil, err
+	}
+	if User() == "" {
+		return nil, fmt.Errorf("app-only auth needs a target user, set it with --user or %s", EnvUser)
+	}
+	return c, nil
+}
+
+// clientCredentials returns the app registration from the environment, or nil if none of its variables are set.
+func clientCredentials() (*ClientCredentials, error) {
+	c := &ClientCredentials{
+		TenantID:     strings.TrimSpace(os.Getenv(EnvTenantID)),
+		ClientID:     strings.TrimSpace(os.Getenv(EnvClientID)),
+		ClientSecret: os.Getenv(EnvClientSecret),
+	}
+	if c.TenantID == "" && c.ClientID == "" && c.ClientSecret == "" {
+		return nil, nil
+	}
+	if c.TenantID == "" || c.ClientID == "" || c.ClientSecret == "" {
+		return nil, fmt.Errorf("app-only auth needs %s, %s and %s to be set", EnvTenantID, EnvClientID, EnvClientSecret)
+	}
+	return c, nil
+}
+
+// GetToken returns an app-only token for Graph. The token is cached until shortly before it expires. The scopes of
+// the options are ignored, see graphScope.
+func (c *ClientCredentials) GetToken(ctx context.Context, _ policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	token, expiresOn, err := c.Token(ctx)
+	if err != nil {
+		return azcore.AccessToken{}, err
+	}
+	return azcore.AccessToken{Token: token, ExpiresOn: expiresOn}, nil
+}
+
+// Token returns an app-only token for Graph and when it expires.
+func (c *ClientCredentials) Token(ctx context.Context) (string, time.Time, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := time.Now()
+	if c.token != "" && now.Add(tokenRefreshMargin).Before(c.expiresOn) {
+		return c.token, c.expiresOn, nil
+	}
+
+	tokenURL := c.TokenURL
+	if tokenURL == "" {
+		tokenURL = "https://login.microsoftonline.com/" + url.PathEscape(c.TenantID) + "/oauth2/v2.0/token"
+	}
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {c.ClientID},
+		"client_secret": {c.ClientSecret},
+		"scope":         {graphScope},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = tokenClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to request app-only token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		AccessToken      string `json:"access_token"`
+		ExpiresIn        int    `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to decode token response with status %d: %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || result.AccessToken == "" {
+		if result.Error != "" {
+			return "", time.Time{}, fmt.Errorf("failed to get app-only token: %s: %s", result.Error, result.ErrorDescription)
+		}
+		return "", time.Time{}, fmt.Errorf("failed to get app-only token: status %d", resp.StatusCode)
+	}
+
+	c.token = result.AccessToken
+	c.expiresOn = now.Add(time.Duration(result.ExpiresIn) * time.Second)
+	return c.token, c.expiresOn, nil
+}
@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClientCredentialsFromEnv(t *testing.T) {
+	t.Setenv(EnvTenantID, "")
+	t.Setenv(EnvClientID, "")
+	t.Setenv(EnvClientSecret, "")
+	t.Setenv(EnvUser, "")
+
+	if c, err := ClientCredentialsFromEnv(); c != nil || err != nil {
+		t.Fatalf("expected no credentials without configuration, got %v, %v", c, err)
+	}
+
+	t.Setenv(EnvTenantID, "contoso.onmicrosoft.com")
+	t.Setenv(EnvClientID, "app")
+	if _, err := ClientCredentialsFromEnv(); err == nil || !strings.Contains(err.Error(), EnvClientSecret) {
+		t.Fatalf("expected an error about the missing secret, got %v", err)
+	}
+
+	t.Setenv(EnvClientSecret, "secret")
+	if _, err := ClientCredentialsFromEnv(); err == nil || !strings.Contains(err.Error(), "--user") {
+		t.Fatalf("expected an error about the missing user, got %v", err)
+	}
+
+	t.Setenv(EnvUser, "support@contoso.com")
+	c, err := ClientCredentialsFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.TenantID != "contoso.onmicrosoft.com" || c.ClientID != "app" || c.ClientSecret != "secret" {
+		t.Fatalf("unexpected credentials: %+v", c)
+	}
+
+	SetUser("helpdesk@contoso.com")
+	defer SetUser("")
+	if u := User(); u != "helpdesk@contoso.com" {
+		t.Fatalf("expected the user of the flag to take precedence, got %s", u)
+	}
+}
+
+func TestAppOnlyEnv(t *testing.T) {
+	t.Setenv(EnvTenantID, "")
+	t.Setenv(EnvClientID, "")
+	t.Setenv(EnvClientSecret, "")
+	t.Setenv(EnvUser, "")
+
+	if env, err := AppOnlyEnv(); env != nil || err != nil {
+		t.Fatalf("expected no variables without configuration, got %v, %v", env, err)
+	}
+
+	t.Setenv(EnvClientID, "app")
+	if _, err := AppOnlyEnv(); err == nil || !strings.Contains(err.Error(), EnvTenantID) {
+		t.Fatalf("expected an error about the missing tenant, got %v", err)
+	}
+
+	// The target user is optional, the tools can get it with --user
+	t.Setenv(EnvTenantID, " contoso.onmicrosoft.com ")
+	t.Setenv(EnvClientSecret, "secret")
+	env, err := AppOnlyEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(env) != 3 || env[EnvTenantID] != "contoso.onmicrosoft.com" || env[EnvClientID] != "app" || env[EnvClientSecret] != "secret" {
+		t.Fatalf("unexpected variables: %v", env)
+	}
+
+	t.Setenv(EnvUser, "support@contoso.com")
+	if env, err = AppOnlyEnv(); err != nil || env[EnvUser] != "support@contoso.com" {
+		t.Fatalf("expected the target user, got %v, %v", env, err)
+	}
+}
+
+func TestToken(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if r.Form.Get("grant_type") != "client_credentials" || r.Form.Get("scope") != graphScope || r.Form.Get("client_secret") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client", "error_description": "Invalid client secret provided."})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"token_type": "Bearer", "expires_in": 3599, "access_token": "token"})
+	}))
+	defer server.Close()
+
+	c := &ClientCredentials{TenantID: "tenant", ClientID: "app", ClientSecret: "secret", TokenURL: server.URL}
+	for range 2 {
+		token, expiresOn, err := c.Token(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token != "token" || expiresOn.IsZero() {
+			t.Fatalf("unexpected token %q expiring %s", token, expiresOn)
+		}
+	}
+	if requests != 1 {
+		t.Fatalf("expected the token to be cached, got %d requests", requests)
+	}
+
+	c = &ClientCredentials{TenantID: "tenant", ClientID: "app", ClientSecret: "wrong", TokenURL: server.URL}
+	if _, _, err := c.Token(context.Background()); err == nil || !strings.Contains(err.Error(), "invalid_client: Invalid client secret provided.") {
+		t.Fatalf("expected the error of the token endpoint, got %v", err)
+	}
+}
+
+func TestTokenTimeout(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+
+	defer func(c *http.Client) { tokenClient = c }(tokenClient)
+	tokenClient = &http.Client{Timeout: 50 * time.Millisecond}
+
+	c := &ClientCredentials{TenantID: "tenant", ClientID: "app", ClientSecret: "secret", TokenURL: server.URL}
+	if _, _, err := c.Token(context.Background()); err == nil || !strings.Contains(err.Error(), "Client.Timeout") {
+		t.Fatalf("expected the token request to time out, got %v", err)
+	}
+}
+
+func TestReplaceMe(t *testing.T) {
+	for path, expected := range map[string]string{
+		"/v1.0/me/messages":           "/v1.0/users/support@contoso.com/messages",
+		"/v1.0/me":                    "/v1.0/users/support@contoso.com",
+		"/me/calendars/AAMk/events":   "/users/support@contoso.com/calendars/AAMk/events",
+		"/v1.0/Me/mailFolders":        "/v1.0/users/support@contoso.com/mailFolders",
+		"/v1.0/users/me@contoso.com":  "",
+		"/v1.0/groups/1/events/me":    "",
+		"/v1.0/$batch":                "",
+		"/v1.0/users/adele/calendars": "",
+	} {
+		result, ok := replaceMe(path, "support@contoso.com")
+		if expected == "" {
+			if ok || result != path {
+				t.Errorf("%s: expected no replacement, got %s", path, result)
+			}
+			continue
+		}
+		if !ok || result != expected {
+			t.Errorf("%s: expected %s, got %s", path, expected, result)
+		}
+	}
+}
+
+func TestReplaceMeInBatch(t *testing.T) {
+	body := `{"requests":[` +
+		`{"id":"1","method":"POST","url":"/me/messages/AAMk/move?$select=id","body":{"destinationId":"archive","size":12345678901234567}},` +
+		`{"id":"2","method":"GET","url":"/users/adele@contoso.com/messages","dependsOn":["1"]}]}`
+
+	result, ok, err := replaceMeInBatch([]byte(body), "support@contoso.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("expected the batch to be rewritten")
+	}
+
+	var batch struct {
+		Requests []struct {
+			URL       string          `json:"url"`
+			Body      json.RawMessage `json:"body"`
+			DependsOn []string        `json:"dependsOn"`
+		} `json:"requests"`
+	}
+	if err := json.Unmarshal(result, &batch); err != nil {
+		t.Fatal(err)
+	}
+	if batch.Requests[0].URL != "/users/support@contoso.com/messages/AAMk/move?$select=id" {
+		t.Fatalf("unexpected URL: %s", batch.Requests[0].URL)
+	}
+	if !strings.Contains(string(batch.Requests[0].Body), "12345678901234567") {
+		t.Fatalf("expected numbers to be kept, got %s", batch.Requests[0].Body)
+	}
+	if batch.Requests[1].URL != "/users/adele@contoso.com/messages" || len(batch.Requests[1].DependsOn) != 1 {
+		t.Fatalf("expected the other request to be kept, got %+v", batch.Requests[1])
+	}
+
+	unchanged := `{"requests":[{"id":"1","method":"GET","url":"/users/adele@contoso.com/messages"}]}`
+	if result, ok, err := replaceMeInBatch([]byte(unchanged), "support@contoso.com"); err != nil || ok || string(result) != unchanged {
+		t.Fatalf("expected the batch to be kept, got %s, %v, %v", result, ok, err)
+	}
+}
@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	nethttplibrary "github.com/microsoft/kiota-http-go"
+)
+
+// UserHandler sends the requests for /me to /users/{user}, because app-only tokens have no signed-in user. Requests
+// in JSON batches are rewritten as well.
+type UserHandler struct {
+	user string
+}
+
+func NewUserHandler(user string) *UserHandler {
+	return &UserHandler{user: user}
+}
+
+func (h *UserHandler) Intercept(pipeline nethttplibrary.Pipeline, middlewareIndex int, req *http.Request) (*http.Response, error) {
+	if path, ok := replaceMe(req.URL.Path, h.user); ok {
+		req.URL.Path = path
+		req.URL.RawPath = ""
+	}
+
+	if strings.HasSuffix(req.URL.Path, "/$batch") && req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read batch request: %w", err)
+		}
+		if rewritten, ok, err := replaceMeInBatch(body, h.user); err != nil {
+			return nil, err
+		} else if ok {
+			body = rewritten
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+		req.ContentLength = int64(len(body))
+	}
+
+	return pipeline.Next(req, middlewareIndex)
+}
+
+// replaceMe replaces the me segment at the start of the path, after the optional API version (e.g. /v1.0/me/messages
+// or /me/messages), with users/{user}.
+func replaceMe(path, user string) (string, bool) {
+	segments := strings.Split(path, "/")
+	for i := 1; i < len(segments) && i <= 2; i++ {
+		if strings.EqualFold(segments[i], "me") {
+			segments[i] = "users/" + url.PathEscape(user)
+			return strings.Join(segments, "/"), true
+		}
+	}
+	return path, false
+}
+
+// replaceMeInBatch replaces /me in the URLs of the requests of a JSON batch. It returns whether any URL was replaced.
+func replaceMeInBatch(body []byte, user string) ([]byte, bool, error) {
+	var batch map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	// Numbers in the bodies of the requests are kept as they are
+	decoder.UseNumber()
+	if err := decoder.Decode(&batch); err != nil {
+		return nil, false, fmt.Errorf("failed to decode batch request: %w", err)
+	}
+
+	requests, _ := batch["requests"].([]any)
+	var replaced bool
+	for _, r := range requests {
+		request, ok := r.(map[string]any)
+		if !ok {
+			continue
+		}
+		u, _ := request["url"].(string)
+		path, query, hasQuery := strings.Cut(u, "?")
+		if path, ok = replaceMe(path, user); ok {
+			if hasQuery {
+				path += "?" + query
+			}
+			request["url"] = path
+			replaced = true
+		}
+	}
+	if !replaced {
+		return body, false, nil
+	}
+
+	result, err := json.Marshal(batch)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to encode batch request: %w", err)
+	}
+	return result, true, nil
+}
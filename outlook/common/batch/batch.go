@@ -0,0 +1,211 @@
+// Package batch sends Microsoft Graph requests as JSON batches ($batch).
+// A batch holds up to 20 requests. Requests can depend on earlier requests, in which case Graph only runs them after
+// their dependencies succeeded, and they have to be in the same batch. Every request of a batch has its own status,
+// so a batch can partially fail.
+package batch
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	abstractions "github.com/microsoft/kiota-abstractions-go"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	msgraphcore "github.com/microsoftgraph/msgraph-sdk-go-core"
+)
+
+const (
+	// MaxSize is the maximum number of requests in a JSON batch
+	MaxSize = 20
+	// retries is how often throttled requests of a batch are retried
+	retries           = 3
+	defaultRetryAfter = 5 * time.Second
+)
+
+// Request is a request of a batch.
+type Request struct {
+	Info *abstractions.RequestInformation
+	// DependsOn are the indexes of earlier requests that have to succeed before this request is run. If one of them
+	// fails, this request fails with status 424.
+	DependsOn []int
+}
+
+// Result is the response to a request of a batch.
+type Result struct {
+	Status int
+	Body   map[string]any
+	Err    error
+}
+
+// SendAll sends the independent requests and returns the result of each request, in the order of the requests.
+func SendAll(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, requests []*abstractions.RequestInformation) ([]Result, error) {
+	batchRequests := make([]Request, len(requests))
+	for i, info := range requests {
+		batchRequests[i] = Request{Info: info}
+	}
+	return Send(ctx, client, batchRequests)
+}
+
+// Send sends the requests as JSON batches of up to 20 requests and returns the result of each request, in the order of
+// the requests. Requests that depend on each other are kept in the same batch. Requests that are throttled are sent
+// again after the delay requested by Graph, together with the requests that failed because they depend on them. An
+// error is only returned if the requests are invalid or a whole batch fails.
+func Send(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, requests []Request) ([]Result, error) {
+	groups, err := split(requests)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, len(requests))
+	for _, group := range groups {
+		pending := group
+		for attempt := 0; len(pending) > 0; attempt++ {
+			batch := msgraphcore.NewBatchRequest(client.BaseRequestBuilder.RequestAdapter)
+			items := make(map[int]msgraphcore.BatchItem, len(pending))
+			for _, i := range pending {
+				item, err := batch.AddBatchRequestStep(*requests[i].Info)
+				if err != nil {
+					return nil, fmt.Errorf("failed to add request to batch: %w", err)
+				}
+				// Dependencies that aren't pending anymore succeeded in an earlier attempt
+				for _, dep := range requests[i].DependsOn {
+					if depItem, ok := items[dep]; ok {
+						item.DependsOnItem(depItem)
+					}
+				}
+				items[i] = item
+			}
+
+			response, err := batch.Send(ctx, client.BaseRequestBuilder.RequestAdapter)
+			if err != nil {
+				return nil, fmt.Errorf("failed to send batch request: %w", err)
+			}
+
+			var (
+				throttled  []int
+				retryAfter time.Duration
+			)
+			// The requests are pending in order, so dependencies are handled before the requests that depend on them
+			for _, i := range pending {
+				item := response.GetResponseById(*items[i].GetId())
+				if item == nil || item.GetStatus() == nil {
+					results[i] = Result{Err: fmt.Errorf("no response to the request")}
+					continue
+				}
+
+				status := int(*item.GetStatus())
+				results[i] = Result{Status: status, Body: item.GetBody()}
+				if status < 400 {
+					continue
+				}
+
+				results[i].Err = itemError(status, item.GetBody())
+				if attempt >= retries {
+					continue
+				}
+				if status == 429 || status == 503 {
+					throttled = append(throttled, i)
+					retryAfter = max(retryAfter, retryDelay(item.GetHeaders()))
+				} else if status == 424 && slices.ContainsFunc(requests[i].DependsOn, func(dep int) bool {
+					return slices.Contains(throttled, dep)
+				}) {
+					throttled = append(throttled, i)
+				}
+			}
+
+			pending = throttled
+			if len(pending) > 0 {
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-time.After(retryAfter):
+				}
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// split returns the indexes of the requests in groups of up to MaxSize, in which every request is in the same group as
+// its dependencies. The groups keep the order of the requests.
+func split(requests []Request) ([][]int, error) {
+	// component is the index of the first request of the requests that are connected by dependencies
+	component := make([]int, len(requests))
+	var find func(i int) int
+	find = func(i int) int {
+		if component[i] != i {
+			component[i] = find(component[i])
+		}
+		return component[i]
+	}
+
+	for i, request := range requests {
+		if request.Info == nil {
+			return nil, fmt.Errorf("request %d has no request information", i)
+		}
+		component[i] = i
+		for _, dep := range request.DependsOn {
+			if dep < 0 || dep >= i {
+				return nil, fmt.Errorf("request %d can only depend on earlier requests, not on %d", i, dep)
+			}
+			a, b := find(i), find(dep)
+			component[max(a, b)] = min(a, b)
+		}
+	}
+
+	var (
+		order   []int
+		members = map[int][]int{}
+	)
+	for i := range requests {
+		root := find(i)
+		if _, ok := members[root]; !ok {
+			order = append(order, root)
+		}
+		members[root] = append(members[root], i)
+	}
+
+	var groups [][]int
+	for _, root := range order {
+		m := members[root]
+		if len(m) > MaxSize {
+			return nil, fmt.Errorf("requests %v depend on each other, but a batch can only hold %d requests", m, MaxSize)
+		}
+		if len(groups) == 0 || len(groups[len(groups)-1])+len(m) > MaxSize {
+			groups = append(groups, nil)
+		}
+		last := len(groups) - 1
+		groups[last] = append(groups[last], m...)
+	}
+	for _, group := range groups {
+		slices.Sort(group)
+	}
+	return groups, nil
+}
+
+// itemError returns the error of a failed request of a batch, with the message of the Graph error if there is one.
+func itemError(status int, body map[string]any) error {
+	if graphErr, ok := body["error"].(map[string]any); ok {
+		if message, ok := graphErr["message"].(string); ok && message != "" {
+			return fmt.Errorf("%s (status %d)", message, status)
+		}
+	}
+	return fmt.Errorf("request failed with status %d", status)
+}
+
+// retryDelay returns the delay that Graph asks for in the Retry-After header of a throttled request.
+func retryDelay(headers msgraphcore.RequestHeader) time.Duration {
+	for key, value := range headers {
+		if !strings.EqualFold(key, "Retry-After") {
+			continue
+		}
+		if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return defaultRetryAfter
+}
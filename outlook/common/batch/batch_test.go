@@ -0,0 +1,111 @@
+package batch
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	abstractions "github.com/microsoft/kiota-abstractions-go"
+	msgraphcore "github.com/microsoftgraph/msgraph-sdk-go-core"
+)
+
+func requestsWithDependencies(dependencies ...[]int) []Request {
+	requests := make([]Request, len(dependencies))
+	for i, deps := range dependencies {
+		requests[i] = Request{Info: abstractions.NewRequestInformation(), DependsOn: deps}
+	}
+	return requests
+}
+
+func TestSplit(t *testing.T) {
+	independent := make([][]int, 45)
+	groups, err := split(requestsWithDependencies(independent...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 3 || len(groups[0]) != MaxSize || len(groups[1]) != MaxSize || len(groups[2]) != 5 {
+		t.Fatalf("unexpected group sizes: %v", groups)
+	}
+	if groups[1][0] != 20 || groups[2][4] != 44 {
+		t.Fatalf("expected the groups to keep the order of the requests: %v", groups)
+	}
+
+	// 19 independent requests, then a chain of 3 that doesn't fit in the first batch anymore
+	dependencies := make([][]int, 22)
+	dependencies[20] = []int{19}
+	dependencies[21] = []int{20}
+	groups, err = split(requestsWithDependencies(dependencies...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 2 || len(groups[0]) != 19 || !reflect.DeepEqual(groups[1], []int{19, 20, 21}) {
+		t.Fatalf("expected the chain to be in one batch: %v", groups)
+	}
+
+	// Requests that are connected through a shared dependency end up in the same batch, in order
+	groups, err = split(requestsWithDependencies(nil, nil, []int{0}, nil, []int{2, 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(groups, [][]int{{0, 1, 2, 3, 4}}) {
+		t.Fatalf("unexpected groups: %v", groups)
+	}
+}
+
+func TestSplitInvalid(t *testing.T) {
+	for name, tc := range map[string]struct {
+		requests []Request
+		err      string
+	}{
+		"self":    {requestsWithDependencies([]int{0}), "can only depend on earlier requests"},
+		"later":   {requestsWithDependencies([]int{1}, nil), "can only depend on earlier requests"},
+		"missing": {[]Request{{}}, "no request information"},
+		"too large": {requestsWithDependencies(func() [][]int {
+			chain := make([][]int, MaxSize+1)
+			for i := 1; i < len(chain); i++ {
+				chain[i] = []int{i - 1}
+			}
+			return chain
+		}()...), "a batch can only hold 20 requests"},
+	} {
+		if _, err := split(tc.requests); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: expected error containing %q, got %v", name, tc.err, err)
+		}
+	}
+}
+
+func TestItemError(t *testing.T) {
+	for _, tc := range []struct {
+		status   int
+		body     map[string]any
+		expected string
+	}{
+		{404, map[string]any{
+			"error": map[string]any{"code": "ErrorItemNotFound", "message": "The specified object was not found in the store."},
+		}, "The specified object was not found in the store. (status 404)"},
+		{500, nil, "request failed with status 500"},
+		{400, map[string]any{"error": "bad request"}, "request failed with status 400"},
+	} {
+		if err := itemError(tc.status, tc.body); err.Error() != tc.expected {
+			t.Errorf("expected %q, got %q", tc.expected, err)
+		}
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	for _, tc := range []struct {
+		headers  msgraphcore.RequestHeader
+		expected time.Duration
+	}{
+		{msgraphcore.RequestHeader{"retry-after": "7"}, 7 * time.Second},
+		{msgraphcore.RequestHeader{"Content-Type": "application/json", "Retry-After": "2"}, 2 * time.Second},
+		{msgraphcore.RequestHeader{"Retry-After": "Wed, 21 Oct 2015 07:28:00 GMT"}, defaultRetryAfter},
+		{msgraphcore.RequestHeader{"Retry-After": "0"}, defaultRetryAfter},
+		{nil, defaultRetryAfter},
+	} {
+		if delay := retryDelay(tc.headers); delay != tc.expected {
+			t.Errorf("%v: expected %s, got %s", tc.headers, tc.expected, delay)
+		}
+	}
+}
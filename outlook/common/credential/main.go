@@ -0,0 +1,52 @@
+// Command credential is the credential tool of the Outlook tools. If app-only auth is configured (see the auth
+// package), it returns the app registration as the credential, so that the tools run without a signed-in user and
+// without interactive consent. Otherwise it runs the OAuth credential tool, which signs the user in.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/gptscript-ai/tools/outlook/common/auth"
+)
+
+// oauthTool is the OAuth credential tool, relative to the directory of this tool. It gets the same parameters.
+const oauthTool = "../../../oauth2/bin/gptscript-go-tool"
+
+func main() {
+	credential, err := appOnlyCredential()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if credential != nil {
+		fmt.Print(string(credential))
+		return
+	}
+
+	cmd := exec.Command(filepath.Join(os.Getenv("GPTSCRIPT_TOOL_DIR"), oauthTool))
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		// The OAuth tool already printed its error
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.ExitCode())
+		}
+		fmt.Printf("failed to run OAuth credential tool: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// appOnlyCredential returns the credential with the variables of app-only auth, or nil if app-only auth isn't
+// configured. The credential doesn't expire, the tools get their tokens themselves.
+func appOnlyCredential() ([]byte, error) {
+	env, err := auth.AppOnlyEnv()
+	if env == nil || err != nil {
+		return nil, err
+	}
+	return json.Marshal(map[string]any{"env": env})
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gptscript-ai/tools/outlook/common/auth"
+)
+
+func TestAppOnlyCredential(t *testing.T) {
+	t.Setenv(auth.EnvTenantID, "")
+	t.Setenv(auth.EnvClientID, "")
+	t.Setenv(auth.EnvClientSecret, "")
+	t.Setenv(auth.EnvUser, "")
+
+	if credential, err := appOnlyCredential(); credential != nil || err != nil {
+		t.Fatalf("expected the OAuth credential without app-only auth, got %s, %v", credential, err)
+	}
+
+	t.Setenv(auth.EnvTenantID, "contoso.onmicrosoft.com")
+	if _, err := appOnlyCredential(); err == nil {
+		t.Fatal("expected an error for an incomplete app registration")
+	}
+
+	t.Setenv(auth.EnvClientID, "app")
+	t.Setenv(auth.EnvClientSecret, "secret")
+	t.Setenv(auth.EnvUser, "support@contoso.com")
+	data, err := appOnlyCredential()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var credential struct {
+		Env map[string]string `json:"env"`
+	}
+	if err := json.Unmarshal(data, &credential); err != nil {
+		t.Fatal(err)
+	}
+	if credential.Env[auth.EnvClientSecret] != "secret" || credential.Env[auth.EnvUser] != "support@contoso.com" || len(credential.Env) != 4 {
+		t.Fatalf("unexpected credential: %s", data)
+	}
+}
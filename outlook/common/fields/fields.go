@@ -0,0 +1,115 @@
+// Package fields selects the properties that list and search commands request from Microsoft Graph with $select.
+// Callers pick a named field set, e.g. summary for a short overview of many items, or list the properties themselves,
+// and so trade the completeness of the output against its size.
+package fields
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+const (
+	// EnvFields is the field set, or the comma-separated properties, that a command returns. The --fields flag takes
+	// precedence.
+	EnvFields = "FIELDS"
+
+	// Summary, Default and Full are the names of the field sets of the commands. Summary has the properties needed
+	// to identify the items, Default the properties the commands print, and Full all default properties of Graph.
+	Summary = "summary"
+	Default = "default"
+	Full    = "full"
+)
+
+// Sets are the named field sets of a command. A nil set selects all default properties of Graph.
+type Sets map[string][]string
+
+var fields string
+
+// Set sets the field set or properties of the commands, overriding FIELDS.
+func Set(value string) {
+	fields = strings.TrimSpace(value)
+}
+
+// Value returns the field set or properties of the commands, or an empty string if there are none.
+func Value() string {
+	if fields != "" {
+		return fields
+	}
+	return strings.TrimSpace(os.Getenv(EnvFields))
+}
+
+// FromEnv returns the properties to select for the configured value, see Resolve.
+func (s Sets) FromEnv(required ...string) ([]string, error) {
+	return s.Resolve(Value(), required...)
+}
+
+// Resolve returns the properties to select for the value, which is the name of a field set or a comma-separated list
+// of properties. An empty value selects the Default set. The ID and the required properties are always selected, so
+// that the commands can translate the IDs and filter the items. A nil result selects all default properties.
+func (s Sets) Resolve(value string, required ...string) ([]string, error) {
+	if value == "" {
+		value = Default
+	}
+
+	var properties []string
+	if set, ok := s[strings.ToLower(value)]; ok {
+		if set == nil {
+			return nil, nil
+		}
+		properties = set
+	} else if strings.EqualFold(value, Full) {
+		return nil, nil
+	} else {
+		for _, property := range strings.Split(value, ",") {
+			if property = strings.TrimSpace(property); property == "" {
+				continue
+			}
+			if !valid(property) {
+				return nil, fmt.Errorf("invalid field %q, expected %s or a comma-separated list of properties", property, s.names())
+			}
+			properties = append(properties, property)
+		}
+	}
+
+	return With(properties, append([]string{"id"}, required...)...), nil
+}
+
+// With returns the properties with the extra properties added, or nil if all default properties are selected.
+func With(properties []string, extra ...string) []string {
+	if properties == nil {
+		return nil
+	}
+
+	result := slices.Clone(properties)
+	for _, property := range extra {
+		if !slices.ContainsFunc(result, func(p string) bool { return strings.EqualFold(p, property) }) {
+			result = append(result, property)
+		}
+	}
+	return result
+}
+
+// names returns the names of the field sets for error messages.
+func (s Sets) names() string {
+	names := make([]string, 0, len(s)+1)
+	for name := range s {
+		names = append(names, name)
+	}
+	if _, ok := s[Full]; !ok {
+		names = append(names, Full)
+	}
+	slices.Sort(names)
+	return strings.Join(names, ", ")
+}
+
+// valid returns whether the property is a Graph property name, which keeps the value from changing the query.
+func valid(property string) bool {
+	for _, r := range property {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '_' {
+			return false
+		}
+	}
+	return true
+}
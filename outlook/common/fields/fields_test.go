@@ -0,0 +1,78 @@
+package fields
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+var testSets = Sets{
+	Summary: {"subject", "receivedDateTime"},
+	Default: {"subject", "receivedDateTime", "bodyPreview"},
+	Full:    nil,
+}
+
+func TestResolve(t *testing.T) {
+	for value, want := range map[string][]string{
+		"":                         {"subject", "receivedDateTime", "bodyPreview", "id", "parentFolderId"},
+		"summary":                  {"subject", "receivedDateTime", "id", "parentFolderId"},
+		"Summary":                  {"subject", "receivedDateTime", "id", "parentFolderId"},
+		"full":                     nil,
+		"subject, from,,":          {"subject", "from", "id", "parentFolderId"},
+		"id,ParentFolderId,isRead": {"id", "ParentFolderId", "isRead"},
+	} {
+		got, err := testSets.Resolve(value, "parentFolderId")
+		if err != nil {
+			t.Fatalf("%q: %v", value, err)
+		}
+		if !slices.Equal(got, want) || (got == nil) != (want == nil) {
+			t.Errorf("%q: properties = %v, want %v", value, got, want)
+		}
+	}
+
+	for _, value := range []string{"subject,body/content", "subject&$expand=attachments", "everything but the body"} {
+		if _, err := testSets.Resolve(value); err == nil || !strings.Contains(err.Error(), "default, full, summary") {
+			t.Errorf("%q: expected an error listing the field sets, got %v", value, err)
+		}
+	}
+}
+
+func TestResolveWithoutFullSet(t *testing.T) {
+	sets := Sets{Default: {"displayName"}}
+	if got, err := sets.Resolve("FULL"); err != nil || got != nil {
+		t.Fatalf("expected all properties, got %v, %v", got, err)
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	defer Set("")
+
+	t.Setenv(EnvFields, "summary")
+	got, err := testSets.FromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"subject", "receivedDateTime", "id"}; !slices.Equal(got, want) {
+		t.Errorf("properties = %v, want %v", got, want)
+	}
+
+	Set(" full ")
+	if got, err := testSets.FromEnv(); err != nil || got != nil {
+		t.Errorf("expected the flag to take precedence, got %v, %v", got, err)
+	}
+}
+
+func TestWith(t *testing.T) {
+	if got := With(nil, "categories"); got != nil {
+		t.Errorf("expected all properties to stay selected, got %v", got)
+	}
+
+	properties := []string{"subject"}
+	got := With(properties, "Subject", "categories")
+	if want := []string{"subject", "categories"}; !slices.Equal(got, want) {
+		t.Errorf("properties = %v, want %v", got, want)
+	}
+	if len(properties) != 1 {
+		t.Errorf("expected the properties to be kept, got %v", properties)
+	}
+}
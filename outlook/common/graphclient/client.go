@@ -0,0 +1,61 @@
+package graphclient
+
+import (
+	"context"
+	"os"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/gptscript-ai/tools/outlook/common/auth"
+	azureauth "github.com/microsoft/kiota-authentication-azure-go"
+	nethttplibrary "github.com/microsoft/kiota-http-go"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	msgraphcore "github.com/microsoftgraph/msgraph-sdk-go-core"
+)
+
+// graphHosts are the hosts the token is sent to, the same as for msgraphsdkgo.NewGraphServiceClientWithCredentials
+var graphHosts = []string{"graph.microsoft.com", "graph.microsoft.us", "dod-graph.microsoft.us", "graph.microsoft.de", "microsoftgraph.chinacloudapi.cn", "canary.graph.microsoft.com"}
+
+// StaticTokenCredential is taken from https://github.com/gptscript-ai/mail-assistant/blob/10944805801bbb6f71eccefd1bea5f114fded164/pkg/mstoken/auth.go
+type StaticTokenCredential struct {
+	token string
+}
+
+func (s StaticTokenCredential) GetToken(_ context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: s.token}, nil
+}
+
+// NewClient returns the Graph client that the Outlook tools share. It uses the token in the credentialEnv variable,
+// or app-only tokens if app-only auth is configured, in which case the requests for /me are sent to the target user,
+// see auth.UserHandler. The client retries throttled requests, see throttleHandler, and logs the requests with their
+// latency if enabled, see telemetryHandler.
+func NewClient(credentialEnv string, scopes []string) (*msgraphsdkgo.GraphServiceClient, error) {
+	var credential azcore.TokenCredential = StaticTokenCredential{
+		token: os.Getenv(credentialEnv),
+	}
+	appCredentials, err := auth.ClientCredentialsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if appCredentials != nil {
+		credential = appCredentials
+	}
+
+	authProvider, err := azureauth.NewAzureIdentityAuthenticationProviderWithScopesAndValidHosts(credential, scopes, graphHosts)
+	if err != nil {
+		return nil, err
+	}
+
+	options := msgraphsdkgo.GetDefaultClientOptions()
+	middleware := withThrottleHandler(msgraphcore.GetDefaultMiddlewaresWithOptions(&options), newThrottleHandler())
+	if appCredentials != nil {
+		middleware = append([]nethttplibrary.Middleware{auth.NewUserHandler(auth.User())}, middleware...)
+	}
+	// The telemetry handler comes last, so that it measures every attempt that is sent to Graph
+	middleware = append(middleware, newTelemetryHandler())
+	adapter, err := msgraphsdkgo.NewGraphRequestAdapterWithParseNodeFactoryAndSerializationWriterFactoryAndHttpClient(authProvider, nil, nil, msgraphcore.GetDefaultClient(&options, middleware...))
+	if err != nil {
+		return nil, err
+	}
+	return msgraphsdkgo.NewGraphServiceClient(adapter), nil
+}
@@ -0,0 +1,92 @@
+package graphclient
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	nethttplibrary "github.com/microsoft/kiota-http-go"
+)
+
+// EnvLog enables the log of the Graph requests, which is written to stderr so that it doesn't mix with the output of
+// the tools.
+const EnvLog = "OUTLOOK_GRAPH_LOG"
+
+// latencyStats counts the requests sent to Graph and how long they took.
+type latencyStats struct {
+	requests int
+	failed   int
+	total    time.Duration
+	slowest  time.Duration
+}
+
+// average returns the average latency of the requests.
+func (s latencyStats) average() time.Duration {
+	if s.requests == 0 {
+		return 0
+	}
+	return s.total / time.Duration(s.requests)
+}
+
+// telemetryHandler measures the latency of the requests sent to Graph, and logs each request with its status and
+// latency if EnvLog is enabled. Only the method and path are logged, the query can contain search terms.
+type telemetryHandler struct {
+	enabled bool
+	now     func() time.Time
+	log     io.Writer
+
+	lock  sync.Mutex
+	stats latencyStats
+}
+
+func newTelemetryHandler() *telemetryHandler {
+	enabled, _ := strconv.ParseBool(os.Getenv(EnvLog))
+	return &telemetryHandler{
+		enabled: enabled,
+		now:     time.Now,
+		log:     os.Stderr,
+	}
+}
+
+func (h *telemetryHandler) Intercept(pipeline nethttplibrary.Pipeline, middlewareIndex int, req *http.Request) (*http.Response, error) {
+	return h.do(req, func(req *http.Request) (*http.Response, error) {
+		return pipeline.Next(req, middlewareIndex)
+	})
+}
+
+func (h *telemetryHandler) do(req *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	start := h.now()
+	resp, err := send(req)
+	latency := h.now().Sub(start)
+
+	stats := h.record(latency, err != nil || resp.StatusCode >= http.StatusBadRequest)
+	if !h.enabled {
+		return resp, err
+	}
+
+	result := "failed"
+	if err == nil {
+		result = strconv.Itoa(resp.StatusCode)
+	}
+	_, _ = fmt.Fprintf(h.log, "Graph API %s %s: %s in %s (%d requests, %d failed, average %s, slowest %s)\n",
+		req.Method, req.URL.Path, result, latency.Round(time.Millisecond), stats.requests, stats.failed,
+		stats.average().Round(time.Millisecond), stats.slowest.Round(time.Millisecond))
+	return resp, err
+}
+
+// record adds a request to the stats and returns them.
+func (h *telemetryHandler) record(latency time.Duration, failed bool) latencyStats {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.stats.requests++
+	if failed {
+		h.stats.failed++
+	}
+	h.stats.total += latency
+	h.stats.slowest = max(h.stats.slowest, latency)
+	return h.stats
+}
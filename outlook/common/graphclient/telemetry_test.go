@@ -0,0 +1,97 @@
+package graphclient
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testTelemetryHandler(enabled bool) (*telemetryHandler, *bytes.Buffer, func(time.Duration)) {
+	var (
+		log bytes.Buffer
+		now = time.Date(2024, 11, 1, 10, 0, 0, 0, time.UTC)
+	)
+	h := newTelemetryHandler()
+	h.enabled = enabled
+	h.now = func() time.Time { return now }
+	h.log = &log
+	return h, &log, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestTelemetryHandler(t *testing.T) {
+	h, log, advance := testTelemetryHandler(true)
+
+	req, err := http.NewRequest(http.MethodGet, "https://graph.microsoft.com/v1.0/me/messages?$search=%22invoice%22", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, r := range []struct {
+		latency time.Duration
+		status  int
+		err     error
+	}{
+		{latency: 120 * time.Millisecond, status: 200},
+		{latency: 40 * time.Millisecond, status: 429},
+		{latency: 20 * time.Millisecond, err: errors.New("connection reset")},
+	} {
+		_, err := h.do(req, func(*http.Request) (*http.Response, error) {
+			advance(r.latency)
+			if r.err != nil {
+				return nil, r.err
+			}
+			return response(r.status, ""), nil
+		})
+		if !errors.Is(err, r.err) {
+			t.Fatalf("expected the error of the request, got %v", err)
+		}
+	}
+
+	if expected := (latencyStats{requests: 3, failed: 2, total: 180 * time.Millisecond, slowest: 120 * time.Millisecond}); h.stats != expected {
+		t.Fatalf("expected stats %+v, got %+v", expected, h.stats)
+	}
+
+	lines := strings.Split(strings.TrimSpace(log.String()), "\n")
+	expected := []string{
+		"Graph API GET /v1.0/me/messages: 200 in 120ms (1 requests, 0 failed, average 120ms, slowest 120ms)",
+		"Graph API GET /v1.0/me/messages: 429 in 40ms (2 requests, 1 failed, average 80ms, slowest 120ms)",
+		"Graph API GET /v1.0/me/messages: failed in 20ms (3 requests, 2 failed, average 60ms, slowest 120ms)",
+	}
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("unexpected log:\n%s", log.String())
+	}
+}
+
+func TestTelemetryHandlerDisabled(t *testing.T) {
+	h, log, advance := testTelemetryHandler(false)
+
+	req, err := http.NewRequest(http.MethodGet, "https://graph.microsoft.com/v1.0/me", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.do(req, func(*http.Request) (*http.Response, error) {
+		advance(time.Second)
+		return response(200, ""), nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if log.Len() != 0 {
+		t.Fatalf("expected no log, got %s", log.String())
+	}
+	if h.stats.requests != 1 || h.stats.total != time.Second {
+		t.Fatalf("expected the request to be measured, got %+v", h.stats)
+	}
+}
+
+func TestNewTelemetryHandler(t *testing.T) {
+	for value, enabled := range map[string]bool{"": false, "true": true, "1": true, "false": false, "verbose": false} {
+		t.Setenv(EnvLog, value)
+		if h := newTelemetryHandler(); h.enabled != enabled {
+			t.Errorf("%q: expected enabled to be %v", value, enabled)
+		}
+	}
+}
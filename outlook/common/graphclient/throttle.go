@@ -0,0 +1,176 @@
+package graphclient
+
+import (
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	nethttplibrary "github.com/microsoft/kiota-http-go"
+)
+
+const (
+	maxThrottleRetries = 5
+	minBackoff         = time.Second
+	maxBackoff         = 30 * time.Second
+)
+
+// throttleStats counts the requests that Graph throttled and how long the client waited for them.
+type throttleStats struct {
+	throttled int
+	waited    time.Duration
+}
+
+// throttleHandler retries requests that Graph throttled (429) or couldn't handle (503), after the delay requested in
+// the Retry-After header, or with an exponential backoff if there is none. Both get jitter, so parallel requests that
+// were throttled together don't come back together. It replaces the retry handler of the SDK, which gives up after
+// three retries.
+type throttleHandler struct {
+	maxRetries int
+	// jitter returns a random duration in [0, d)
+	jitter func(d time.Duration) time.Duration
+	sleep  func(req *http.Request, d time.Duration) error
+	log    io.Writer
+
+	lock  sync.Mutex
+	stats throttleStats
+}
+
+func newThrottleHandler() *throttleHandler {
+	return &throttleHandler{
+		maxRetries: maxThrottleRetries,
+		jitter: func(d time.Duration) time.Duration {
+			if d <= 0 {
+				return 0
+			}
+			return rand.N(d)
+		},
+		sleep: func(req *http.Request, d time.Duration) error {
+			timer := time.NewTimer(d)
+			defer timer.Stop()
+			select {
+			case <-req.Context().Done():
+				return req.Context().Err()
+			case <-timer.C:
+				return nil
+			}
+		},
+		log: os.Stderr,
+	}
+}
+
+// withThrottleHandler returns the middleware with the retry handler of the SDK replaced by the throttle handler.
+func withThrottleHandler(middleware []nethttplibrary.Middleware, handler *throttleHandler) []nethttplibrary.Middleware {
+	result := make([]nethttplibrary.Middleware, 0, len(middleware)+1)
+	replaced := false
+	for _, m := range middleware {
+		if _, ok := m.(*nethttplibrary.RetryHandler); ok {
+			if !replaced {
+				result = append(result, handler)
+				replaced = true
+			}
+			continue
+		}
+		result = append(result, m)
+	}
+	if !replaced {
+		result = append([]nethttplibrary.Middleware{handler}, result...)
+	}
+	return result
+}
+
+func (h *throttleHandler) Intercept(pipeline nethttplibrary.Pipeline, middlewareIndex int, req *http.Request) (*http.Response, error) {
+	return h.do(req, func(req *http.Request) (*http.Response, error) {
+		return pipeline.Next(req, middlewareIndex)
+	})
+}
+
+func (h *throttleHandler) do(req *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := send(req)
+		if err != nil || (resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable) {
+			return resp, err
+		}
+
+		delay := h.delay(resp, attempt)
+		retry := attempt < h.maxRetries && rewindBody(req)
+		stats := h.record(retry, delay)
+		if !retry {
+			_, _ = fmt.Fprintf(h.log, "Graph API throttled %s %s with status %d, giving up after %d retries (%d throttled requests, waited %s in total)\n",
+				req.Method, req.URL.Path, resp.StatusCode, attempt, stats.throttled, stats.waited.Round(time.Millisecond))
+			return resp, nil
+		}
+		_, _ = fmt.Fprintf(h.log, "Graph API throttled %s %s with status %d, retrying in %s (%d throttled requests, waited %s in total)\n",
+			req.Method, req.URL.Path, resp.StatusCode, delay.Round(time.Millisecond), stats.throttled, stats.waited.Round(time.Millisecond))
+
+		// The connection can only be reused once the body was read
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+
+		if err := h.sleep(req, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// delay returns how long to wait before the request is sent again.
+func (h *throttleHandler) delay(resp *http.Response, attempt int) time.Duration {
+	if d, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+		return d + h.jitter(d/10+time.Millisecond)
+	}
+	backoff := min(minBackoff<<attempt, maxBackoff)
+	return backoff/2 + h.jitter(backoff/2)
+}
+
+// record adds a throttled request to the stats and returns them.
+func (h *throttleHandler) record(retry bool, delay time.Duration) throttleStats {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.stats.throttled++
+	if retry {
+		h.stats.waited += delay
+	}
+	return h.stats
+}
+
+// retryAfter parses the Retry-After header, which is either a number of seconds or an HTTP date.
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// rewindBody prepares the body of the request to be sent again, it returns false if that isn't possible.
+func rewindBody(req *http.Request) bool {
+	if req.Body == nil || req.Body == http.NoBody {
+		return true
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return false
+		}
+		req.Body = body
+		return true
+	}
+	// The SDK sends the content from a reader that can be rewound
+	if seeker, ok := req.Body.(io.Seeker); ok {
+		_, err := seeker.Seek(0, io.SeekStart)
+		return err == nil
+	}
+	return false
+}
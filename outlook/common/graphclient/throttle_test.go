@@ -0,0 +1,184 @@
+package graphclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	nethttplibrary "github.com/microsoft/kiota-http-go"
+)
+
+func testThrottleHandler(log io.Writer) (*throttleHandler, *[]time.Duration) {
+	var sleeps []time.Duration
+	h := newThrottleHandler()
+	h.jitter = func(time.Duration) time.Duration { return 0 }
+	h.sleep = func(_ *http.Request, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return nil
+	}
+	h.log = log
+	return h, &sleeps
+}
+
+func response(status int, retryAfter string) *http.Response {
+	resp := &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("{}"))}
+	if retryAfter != "" {
+		resp.Header.Set("Retry-After", retryAfter)
+	}
+	return resp
+}
+
+func TestThrottleHandlerRetries(t *testing.T) {
+	var log bytes.Buffer
+	h, sleeps := testThrottleHandler(&log)
+
+	req, err := http.NewRequest(http.MethodPost, "https://graph.microsoft.com/v1.0/me/messages", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader([]byte(`{"subject": "Hello"}`)))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader([]byte(`{"subject": "Hello"}`))), nil
+	}
+
+	var bodies []string
+	responses := []*http.Response{response(429, "3"), response(503, ""), response(201, "")}
+	resp, err := h.do(req, func(req *http.Request) (*http.Response, error) {
+		body, _ := io.ReadAll(req.Body)
+		bodies = append(bodies, string(body))
+		resp := responses[0]
+		responses = responses[1:]
+		return resp, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != 201 {
+		t.Fatalf("expected the response of the last attempt, got %d", resp.StatusCode)
+	}
+	if expected := []string{`{"subject": "Hello"}`, `{"subject": "Hello"}`, `{"subject": "Hello"}`}; !slices.Equal(bodies, expected) {
+		t.Fatalf("expected the body to be sent again, got %q", bodies)
+	}
+	// Retry-After first, then the backoff of the second attempt without jitter
+	if expected := []time.Duration{3 * time.Second, time.Second}; !slices.Equal(*sleeps, expected) {
+		t.Fatalf("expected sleeps %v, got %v", expected, *sleeps)
+	}
+	if expected := (throttleStats{throttled: 2, waited: 4 * time.Second}); h.stats != expected {
+		t.Fatalf("expected stats %+v, got %+v", expected, h.stats)
+	}
+	if !strings.Contains(log.String(), "Graph API throttled POST /v1.0/me/messages with status 429, retrying in 3s (1 throttled requests, waited 3s in total)") {
+		t.Fatalf("unexpected log: %s", log.String())
+	}
+}
+
+func TestThrottleHandlerGivesUp(t *testing.T) {
+	var log bytes.Buffer
+	h, sleeps := testThrottleHandler(&log)
+	h.maxRetries = 2
+
+	req, err := http.NewRequest(http.MethodGet, "https://graph.microsoft.com/v1.0/me/messages", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var requests int
+	resp, err := h.do(req, func(*http.Request) (*http.Response, error) {
+		requests++
+		return response(429, "1"), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != 429 || requests != 3 || len(*sleeps) != 2 {
+		t.Fatalf("expected 3 requests and 2 sleeps ending with 429, got %d requests, %d sleeps and %d", requests, len(*sleeps), resp.StatusCode)
+	}
+	if !strings.Contains(log.String(), "giving up after 2 retries (3 throttled requests, waited 2s in total)") {
+		t.Fatalf("unexpected log: %s", log.String())
+	}
+
+	// Bodies that can't be sent again aren't retried
+	req.Body = io.NopCloser(strings.NewReader("data"))
+	requests = 0
+	if _, err = h.do(req, func(*http.Request) (*http.Response, error) {
+		requests++
+		return response(503, ""), nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 {
+		t.Fatalf("expected no retries, got %d requests", requests)
+	}
+}
+
+func TestThrottleHandlerCanceled(t *testing.T) {
+	h := newThrottleHandler()
+	h.log = io.Discard
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://graph.microsoft.com/v1.0/me", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = h.do(req, func(*http.Request) (*http.Response, error) {
+		cancel()
+		return response(429, "60"), nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the request to be canceled, got %v", err)
+	}
+}
+
+func TestThrottleDelay(t *testing.T) {
+	h := newThrottleHandler()
+	for attempt, backoff := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second, 30 * time.Second, 30 * time.Second} {
+		if delay := h.delay(response(429, ""), attempt); delay < backoff/2 || delay >= backoff {
+			t.Errorf("attempt %d: expected a delay in [%s, %s), got %s", attempt, backoff/2, backoff, delay)
+		}
+	}
+
+	if delay := h.delay(response(429, "10"), 0); delay < 10*time.Second || delay > 11*time.Second {
+		t.Errorf("expected a delay in [10s, 11s], got %s", delay)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 11, 1, 10, 0, 0, 0, time.UTC)
+	for value, expected := range map[string]time.Duration{
+		"5":                             5 * time.Second,
+		"0":                             0,
+		"Fri, 01 Nov 2024 10:00:30 GMT": 30 * time.Second,
+		"Fri, 01 Nov 2024 09:00:00 GMT": 0,
+	} {
+		if d, ok := retryAfter(value, now); !ok || d != expected {
+			t.Errorf("%s: expected %s, got %s, %v", value, expected, d, ok)
+		}
+	}
+
+	for _, value := range []string{"", "-1", "soon"} {
+		if _, ok := retryAfter(value, now); ok {
+			t.Errorf("%s: expected no delay", value)
+		}
+	}
+}
+
+func TestWithThrottleHandler(t *testing.T) {
+	h := newThrottleHandler()
+	redirect := nethttplibrary.NewRedirectHandler()
+	middleware := withThrottleHandler([]nethttplibrary.Middleware{nethttplibrary.NewRetryHandler(), redirect}, h)
+	if expected := []nethttplibrary.Middleware{h, redirect}; !reflect.DeepEqual(middleware, expected) {
+		t.Fatalf("expected the retry handler to be replaced, got %v", middleware)
+	}
+
+	middleware = withThrottleHandler([]nethttplibrary.Middleware{redirect}, h)
+	if expected := []nethttplibrary.Middleware{h, redirect}; !reflect.DeepEqual(middleware, expected) {
+		t.Fatalf("expected the throttle handler to be added, got %v", middleware)
+	}
+}
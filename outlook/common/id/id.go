@@ -5,27 +5,165 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/gptscript-ai/go-gptscript"
 )
 
+// EnvTTL is how long an ID that isn't used anymore is kept in the cache, as a Go duration (e.g. 720h). 0 keeps IDs
+// forever. Default is 30 days.
+const EnvTTL = "OUTLOOK_ID_CACHE_TTL"
+
+const defaultTTL = 30 * 24 * time.Hour
+
+// touchInterval is how often the last use of an ID is updated, so that reading IDs doesn't write the cache every time.
+const touchInterval = time.Hour
+
 type Cache struct {
 	OutlookToNumber map[string]int `json:"outlookToNumber"`
 	NumberToOutlook map[int]string `json:"numberToOutlook"`
+	// LastUsed is the Unix time at which each number was last set or looked up
+	LastUsed map[int]int64 `json:"lastUsed,omitempty"`
+	// Next is the next number to hand out. Numbers of expired IDs are never reused, so that an old number can't
+	// silently point to a different item.
+	Next int `json:"next,omitempty"`
 }
 
 const cacheLocation = "outlookcache.json"
 
+// store keeps the cache in memory for the lifetime of the process, so that a command that translates IDs several
+// times reads the cache file only once.
+var store struct {
+	sync.Mutex
+	gs    *gptscript.GPTScript
+	cache *Cache
+}
+
+func newCache() Cache {
+	return Cache{
+		OutlookToNumber: make(map[string]int),
+		NumberToOutlook: make(map[int]string),
+		LastUsed:        make(map[int]int64),
+		Next:            1,
+	}
+}
+
+// init fills in the fields that caches written by older versions don't have. Their IDs count as used now.
+func (c *Cache) init(now time.Time) {
+	if c.OutlookToNumber == nil {
+		c.OutlookToNumber = make(map[string]int)
+	}
+	if c.NumberToOutlook == nil {
+		c.NumberToOutlook = make(map[int]string)
+	}
+	if c.LastUsed == nil {
+		c.LastUsed = make(map[int]int64, len(c.NumberToOutlook))
+	}
+	for num := range c.NumberToOutlook {
+		if _, ok := c.LastUsed[num]; !ok {
+			c.LastUsed[num] = now.Unix()
+		}
+		if num >= c.Next {
+			c.Next = num + 1
+		}
+	}
+	if c.Next < 1 {
+		c.Next = 1
+	}
+}
+
+// evict removes the IDs that weren't used within the TTL. It returns whether anything was removed.
+func (c *Cache) evict(now time.Time, ttl time.Duration) bool {
+	if ttl <= 0 {
+		return false
+	}
+
+	var evicted bool
+	for num, lastUsed := range c.LastUsed {
+		if now.Sub(time.Unix(lastUsed, 0)) > ttl {
+			delete(c.OutlookToNumber, c.NumberToOutlook[num])
+			delete(c.NumberToOutlook, num)
+			delete(c.LastUsed, num)
+			evicted = true
+		}
+	}
+	return evicted
+}
+
+// touch records the use of the number. It returns whether the cache changed.
+func (c *Cache) touch(num int, now time.Time) bool {
+	if now.Sub(time.Unix(c.LastUsed[num], 0)) < touchInterval {
+		return false
+	}
+	c.LastUsed[num] = now.Unix()
+	return true
+}
+
+// lookup returns the Outlook IDs for the IDs that are known. IDs that aren't numbers are most likely already Outlook
+// IDs, so they are returned as they are.
+func (c *Cache) lookup(ids []string, now time.Time) (results map[string]string, missing []string, changed bool) {
+	results = make(map[string]string, len(ids))
+	for _, id := range ids {
+		idNum, err := strconv.Atoi(id)
+		if err != nil {
+			results[id] = id
+			continue
+		}
+
+		outlookID, ok := c.NumberToOutlook[idNum]
+		if !ok {
+			missing = append(missing, id)
+			continue
+		}
+
+		results[id] = outlookID
+		changed = c.touch(idNum, now) || changed
+	}
+	return results, missing, changed
+}
+
+// set returns the numbers for the Outlook IDs, adding the ones that aren't known yet.
+func (c *Cache) set(outlookIDs []string, now time.Time) (results map[string]string, changed bool) {
+	results = make(map[string]string, len(outlookIDs))
+	for _, outlookID := range outlookIDs {
+		// First we try looking for an existing one.
+		numID, ok := c.OutlookToNumber[outlookID]
+
+		// If it doesn't exist, we create a new one.
+		if !ok {
+			numID = c.Next
+			c.Next++
+			c.OutlookToNumber[outlookID] = numID
+			c.NumberToOutlook[numID] = outlookID
+			c.LastUsed[numID] = now.Unix()
+			changed = true
+		} else {
+			changed = c.touch(numID, now) || changed
+		}
+
+		results[outlookID] = strconv.Itoa(numID)
+	}
+	return results, changed
+}
+
+func ttlFromEnv() time.Duration {
+	if v := os.Getenv(EnvTTL); v != "" {
+		if ttl, err := time.ParseDuration(v); err == nil && ttl >= 0 {
+			return ttl
+		}
+	}
+	return defaultTTL
+}
+
 func loadCache(ctx context.Context, gs *gptscript.GPTScript) (Cache, error) {
 	cacheBytes, err := gs.ReadFileInWorkspace(ctx, cacheLocation)
 	if err != nil {
 		var notFoundError *gptscript.NotFoundInWorkspaceError
 		if errors.As(err, &notFoundError) {
-			return Cache{
-				OutlookToNumber: make(map[string]int),
-				NumberToOutlook: make(map[int]string),
-			}, nil
+			return newCache(), nil
 		}
 		return Cache{}, fmt.Errorf("failed to read the Outlook cache file: %w", err)
 	}
@@ -49,6 +187,42 @@ func writeCache(ctx context.Context, gs *gptscript.GPTScript, c Cache) error {
 	return nil
 }
 
+// withCache calls fn with the in-memory cache, loading it first if needed, and writes the cache back if fn changed
+// it. Expired IDs are evicted when the cache is loaded. The caller must not hold the store lock.
+func withCache(ctx context.Context, fn func(c *Cache, now time.Time) bool) error {
+	store.Lock()
+	defer store.Unlock()
+
+	now := time.Now()
+	var mustWrite bool
+	if store.cache == nil {
+		if store.gs == nil {
+			gs, err := gptscript.NewGPTScript()
+			if err != nil {
+				return fmt.Errorf("failed to initialize the GPTScript client: %w", err)
+			}
+			store.gs = gs
+		}
+
+		cache, err := loadCache(ctx, store.gs)
+		if err != nil {
+			return err
+		}
+		cache.init(now)
+		mustWrite = cache.evict(now, ttlFromEnv())
+		store.cache = &cache
+	}
+
+	if fn(store.cache, now) || mustWrite {
+		if err := writeCache(ctx, store.gs, *store.cache); err != nil {
+			// The file wasn't updated, so load it again next time instead of using a cache that doesn't match it
+			store.cache = nil
+			return err
+		}
+	}
+	return nil
+}
+
 func GetOutlookID(ctx context.Context, id string) (string, error) {
 	ids, err := GetOutlookIDs(ctx, []string{id})
 	if err != nil {
@@ -57,39 +231,37 @@ func GetOutlookID(ctx context.Context, id string) (string, error) {
 	return ids[id], nil
 }
 
+// GetOutlookIDs returns the Outlook IDs for the IDs, in one read of the cache. It fails if any ID is unknown.
 func GetOutlookIDs(ctx context.Context, ids []string) (map[string]string, error) {
-	if len(ids) == 0 {
-		return map[string]string{}, nil
-	}
-
-	gs, err := gptscript.NewGPTScript()
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize the GPTScript client: %w", err)
-	}
-
-	cache, err := loadCache(ctx, gs)
+	results, missing, err := LookupOutlookIDs(ctx, ids)
 	if err != nil {
 		return nil, err
 	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("error: Outlook ID not found")
+	}
+	return results, nil
+}
 
-	results := map[string]string{}
-	for _, id := range ids {
-		idNum, err := strconv.Atoi(id)
-		if err != nil {
-			// If the ID does not convert to a number, it's most likely already an Outlook ID, so we just return it back.
-			results[id] = id
-			continue
-		}
-
-		outlookID, ok := cache.NumberToOutlook[idNum]
-		if !ok {
-			return nil, fmt.Errorf("error: Outlook ID not found")
-		}
-
-		results[id] = outlookID
+// LookupOutlookIDs returns the Outlook IDs for the IDs that are known, and the IDs that are unknown or have expired,
+// so that bulk operations can report them one by one instead of failing as a whole.
+func LookupOutlookIDs(ctx context.Context, ids []string) (map[string]string, []string, error) {
+	if len(ids) == 0 {
+		return map[string]string{}, nil, nil
 	}
 
-	return results, nil
+	var (
+		results map[string]string
+		missing []string
+	)
+	if err := withCache(ctx, func(c *Cache, now time.Time) bool {
+		var changed bool
+		results, missing, changed = c.lookup(ids, now)
+		return changed
+	}); err != nil {
+		return nil, nil, err
+	}
+	return results, missing, nil
 }
 
 func SetOutlookID(ctx context.Context, outlookID string) (string, error) {
@@ -100,43 +272,19 @@ func SetOutlookID(ctx context.Context, outlookID string) (string, error) {
 	return ids[outlookID], nil
 }
 
+// SetOutlookIDs returns the numbers for the Outlook IDs, in one read and at most one write of the cache.
 func SetOutlookIDs(ctx context.Context, outlookIDs []string) (map[string]string, error) {
 	if len(outlookIDs) == 0 {
 		return map[string]string{}, nil
 	}
 
-	gs, err := gptscript.NewGPTScript()
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize the GPTScript client: %w", err)
-	}
-
-	cache, err := loadCache(ctx, gs)
-	if err != nil {
+	var results map[string]string
+	if err := withCache(ctx, func(c *Cache, now time.Time) bool {
+		var changed bool
+		results, changed = c.set(outlookIDs, now)
+		return changed
+	}); err != nil {
 		return nil, err
 	}
-
-	results := map[string]string{}
-	mustWrite := false
-	for _, outlookID := range outlookIDs {
-		// First we try looking for an existing one.
-		numID, ok := cache.OutlookToNumber[outlookID]
-
-		// If it doesn't exist, we create a new one.
-		if !ok {
-			numID = len(cache.OutlookToNumber) + 1
-			cache.OutlookToNumber[outlookID] = numID
-			cache.NumberToOutlook[numID] = outlookID
-			mustWrite = true
-		}
-
-		results[outlookID] = strconv.Itoa(numID)
-	}
-
-	if mustWrite {
-		if err = writeCache(ctx, gs, cache); err != nil {
-			return nil, err
-		}
-	}
-
 	return results, nil
 }
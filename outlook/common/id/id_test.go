@@ -0,0 +1,102 @@
+package id
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestCacheSetAndLookup(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	c := newCache()
+
+	ids, changed := c.set([]string{"AAA", "BBB", "AAA"}, now)
+	if !changed {
+		t.Fatal("expected the cache to change")
+	}
+	if ids["AAA"] != "1" || ids["BBB"] != "2" {
+		t.Fatalf("unexpected IDs: %v", ids)
+	}
+
+	// Known IDs don't change the cache until the touch interval has passed
+	if _, changed = c.set([]string{"AAA"}, now.Add(time.Minute)); changed {
+		t.Fatal("expected the cache not to change")
+	}
+
+	results, missing, changed := c.lookup([]string{"1", "2", "3", "AAMk-outlook-id"}, now.Add(2*touchInterval))
+	if !changed {
+		t.Fatal("expected the last use to be updated")
+	}
+	if results["1"] != "AAA" || results["2"] != "BBB" || results["AAMk-outlook-id"] != "AAMk-outlook-id" {
+		t.Fatalf("unexpected results: %v", results)
+	}
+	if len(missing) != 1 || missing[0] != "3" {
+		t.Fatalf("unexpected missing IDs: %v", missing)
+	}
+}
+
+func TestCacheEvict(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	ttl := 24 * time.Hour
+	c := newCache()
+
+	c.set([]string{"old"}, now)
+	c.set([]string{"new"}, now.Add(ttl))
+
+	if c.evict(now.Add(ttl), 0) {
+		t.Fatal("expected no eviction without a TTL")
+	}
+	if c.evict(now.Add(ttl), ttl) {
+		t.Fatal("expected no eviction within the TTL")
+	}
+	if !c.evict(now.Add(ttl+time.Second), ttl) {
+		t.Fatal("expected the old ID to be evicted")
+	}
+	if _, ok := c.OutlookToNumber["old"]; ok {
+		t.Fatal("expected the old ID to be removed")
+	}
+	if _, missing, _ := c.lookup([]string{"1"}, now); len(missing) != 1 {
+		t.Fatal("expected the number of the old ID to be unknown")
+	}
+
+	// Numbers of evicted IDs are not reused
+	ids, _ := c.set([]string{"newer"}, now.Add(ttl))
+	if ids["newer"] != "3" {
+		t.Fatalf("expected a new number, got %s", ids["newer"])
+	}
+}
+
+func TestCacheInitOldFormat(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+
+	var c Cache
+	if err := json.Unmarshal([]byte(`{"outlookToNumber":{"AAA":1,"CCC":3},"numberToOutlook":{"1":"AAA","3":"CCC"}}`), &c); err != nil {
+		t.Fatal(err)
+	}
+	c.init(now)
+
+	if c.Next != 4 {
+		t.Fatalf("expected next number 4, got %d", c.Next)
+	}
+	if c.LastUsed[1] != now.Unix() || c.LastUsed[3] != now.Unix() {
+		t.Fatalf("expected existing IDs to count as used now: %v", c.LastUsed)
+	}
+	if c.evict(now.Add(time.Hour), 24*time.Hour) {
+		t.Fatal("expected existing IDs not to be evicted right away")
+	}
+}
+
+func TestTTLFromEnv(t *testing.T) {
+	for v, expected := range map[string]time.Duration{
+		"":        defaultTTL,
+		"48h":     48 * time.Hour,
+		"0":       0,
+		"-1h":     defaultTTL,
+		"invalid": defaultTTL,
+	} {
+		t.Setenv(EnvTTL, v)
+		if ttl := ttlFromEnv(); ttl != expected {
+			t.Errorf("%q: expected %s, got %s", v, expected, ttl)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+// Package pagination pages through Microsoft Graph collections.
+// Graph returns at most one page of items per request, and an @odata.nextLink pointing to the next page.
+// The next link keeps the query of the first request ($filter, $select, $orderby, ...), so only the first request
+// needs to be configured.
+package pagination
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
+)
+
+const (
+	// DefaultPageSize is the $top used for the requests unless another page size is configured. Graph supports
+	// larger pages for most collections, but large pages of messages with bodies are slow to serialize.
+	DefaultPageSize = 100
+	// MaxPageSize is the largest page size that can be configured, the largest $top most Graph collections accept.
+	MaxPageSize = 999
+	// EnvPageSize configures the page size of the requests. The --page-size flag takes precedence.
+	EnvPageSize = "OUTLOOK_PAGE_SIZE"
+
+	maxRetries     = 5
+	initialBackoff = time.Second
+	maxBackoff     = 30 * time.Second
+)
+
+var pageSize int
+
+// SetPageSize sets the page size of the requests, overriding OUTLOOK_PAGE_SIZE. A size of 0 or less keeps the
+// configured page size.
+func SetPageSize(size int) {
+	pageSize = size
+}
+
+// PageSize returns the configured page size of the requests, DefaultPageSize if none or an invalid one is
+// configured. It is at most MaxPageSize.
+func PageSize() int {
+	size := pageSize
+	if size <= 0 {
+		size, _ = strconv.Atoi(strings.TrimSpace(os.Getenv(EnvPageSize)))
+	}
+	if size <= 0 {
+		return DefaultPageSize
+	}
+	return min(size, MaxPageSize)
+}
+
+// Page is implemented by all Graph collection responses, e.g. models.MessageCollectionResponseable.
+type Page[T any] interface {
+	GetValue() []T
+	GetOdataNextLink() *string
+}
+
+// Query holds the query parameters the first request must set on its request configuration.
+type Query struct {
+	// Top is the page size, the configured page size or the limit of the iterator if that is smaller
+	Top *int32
+	// Select are the properties that are returned for each item, all default properties if empty
+	Select []string
+}
+
+// PageIterator returns the items of a collection across pages.
+//
+// Example:
+//
+//	messages, err := pagination.New(
+//		func(ctx context.Context, q pagination.Query) (models.MessageCollectionResponseable, error) {
+//			return client.Me().Messages().Get(ctx, &users.ItemMessagesRequestBuilderGetRequestConfiguration{
+//				QueryParameters: &users.ItemMessagesRequestBuilderGetQueryParameters{Top: q.Top, Select: q.Select},
+//			})
+//		},
+//		func(ctx context.Context, nextLink string) (models.MessageCollectionResponseable, error) {
+//			return client.Me().Messages().WithUrl(nextLink).Get(ctx, nil)
+//		},
+//	).WithLimit(limit).Collect(ctx)
+type PageIterator[T any] struct {
+	getFirst func(ctx context.Context, q Query) (Page[T], error)
+	getNext  func(ctx context.Context, nextLink string) (Page[T], error)
+	limit    int
+	selects  []string
+	key      func(item T) string
+}
+
+// New returns an iterator requesting the first page with getFirst and the following pages with getNext, which
+// is usually the WithUrl(nextLink) request builder of the collection.
+func New[T any, P Page[T]](getFirst func(ctx context.Context, q Query) (P, error), getNext func(ctx context.Context, nextLink string) (P, error)) *PageIterator[T] {
+	return &PageIterator[T]{
+		getFirst: func(ctx context.Context, q Query) (Page[T], error) {
+			return getFirst(ctx, q)
+		},
+		getNext: func(ctx context.Context, nextLink string) (Page[T], error) {
+			return getNext(ctx, nextLink)
+		},
+	}
+}
+
+// WithLimit stops the iteration after limit items. A limit of 0 or less returns all items.
+func (p *PageIterator[T]) WithLimit(limit int) *PageIterator[T] {
+	p.limit = limit
+	return p
+}
+
+// WithSelect sets the $select projection of the first request, which is kept for all following pages.
+func (p *PageIterator[T]) WithSelect(properties ...string) *PageIterator[T] {
+	p.selects = properties
+	return p
+}
+
+// WithDedupe skips items whose key was already returned. Items can show up on two pages when the collection changes
+// while paging, e.g. when new messages arrive during a search. Skipped items don't count towards the limit.
+func (p *PageIterator[T]) WithDedupe(key func(item T) string) *PageIterator[T] {
+	p.key = key
+	return p
+}
+
+// Iterate calls fn for every item until fn returns false, the limit is reached or there are no more pages.
+// Throttled requests are retried after the delay requested by Graph.
+func (p *PageIterator[T]) Iterate(ctx context.Context, fn func(item T) bool) error {
+	top := int32(PageSize())
+	if p.limit > 0 && p.limit < int(top) {
+		top = int32(p.limit)
+	}
+	query := Query{Top: &top, Select: p.selects}
+
+	page, err := WithRetry(ctx, func() (Page[T], error) {
+		return p.getFirst(ctx, query)
+	})
+
+	var (
+		count int
+		seen  = map[string]struct{}{}
+	)
+	for {
+		if err != nil {
+			return err
+		}
+		if page == nil {
+			return nil
+		}
+
+		for _, item := range page.GetValue() {
+			if p.key != nil {
+				k := p.key(item)
+				if _, ok := seen[k]; ok {
+					continue
+				}
+				seen[k] = struct{}{}
+			}
+			if !fn(item) {
+				return nil
+			}
+			if count++; p.limit > 0 && count >= p.limit {
+				return nil
+			}
+		}
+
+		nextLink := page.GetOdataNextLink()
+		if nextLink == nil || *nextLink == "" {
+			return nil
+		}
+		page, err = WithRetry(ctx, func() (Page[T], error) {
+			return p.getNext(ctx, *nextLink)
+		})
+	}
+}
+
+// Collect returns all items up to the limit.
+func (p *PageIterator[T]) Collect(ctx context.Context) ([]T, error) {
+	var items []T
+	err := p.Iterate(ctx, func(item T) bool {
+		items = append(items, item)
+		return true
+	})
+	return items, err
+}
+
+// WithRetry calls get again if Graph throttled the request or was temporarily unavailable. The SDK already retries
+// a few times, this covers longer throttling periods when paging through large collections. It is also used for
+// requests that can't use the PageIterator, like delta queries.
+func WithRetry[P any](ctx context.Context, get func() (P, error)) (P, error) {
+	backoff := initialBackoff
+	for attempt := 0; ; attempt++ {
+		result, err := get()
+		delay, retry := retryDelay(err, backoff)
+		if !retry || attempt >= maxRetries {
+			return result, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return result, fmt.Errorf("%w (gave up retrying: %w)", err, ctx.Err())
+		case <-time.After(delay):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// retryDelay returns the delay before the request is retried, and whether it should be retried at all.
+// The Retry-After header is used if present, otherwise backoff.
+func retryDelay(err error, backoff time.Duration) (time.Duration, bool) {
+	var odataErr *odataerrors.ODataError
+	if err == nil || !errors.As(err, &odataErr) {
+		return 0, false
+	}
+	switch odataErr.ResponseStatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+	default:
+		return 0, false
+	}
+
+	if odataErr.ResponseHeaders != nil {
+		for _, value := range odataErr.ResponseHeaders.Get("Retry-After") {
+			if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+				return time.Duration(seconds) * time.Second, true
+			}
+		}
+	}
+	return backoff, true
+}
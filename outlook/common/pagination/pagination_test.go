@@ -0,0 +1,238 @@
+package pagination
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	abstractions "github.com/microsoft/kiota-abstractions-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
+)
+
+type page struct {
+	values   []string
+	nextLink *string
+}
+
+func (p *page) GetValue() []string        { return p.values }
+func (p *page) GetOdataNextLink() *string { return p.nextLink }
+
+func graphError(status int, retryAfter string) error {
+	err := odataerrors.NewODataError()
+	err.ResponseStatusCode = status
+	if retryAfter != "" {
+		err.ResponseHeaders = abstractions.NewResponseHeaders()
+		err.ResponseHeaders.Add("Retry-After", retryAfter)
+	}
+	return err
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantDelay time.Duration
+		wantRetry bool
+	}{
+		{name: "no error", err: nil},
+		{name: "other error", err: errors.New("boom")},
+		{name: "not found", err: graphError(http.StatusNotFound, "")},
+		{name: "throttled with retry-after", err: graphError(http.StatusTooManyRequests, "7"), wantDelay: 7 * time.Second, wantRetry: true},
+		{name: "throttled with zero retry-after", err: graphError(http.StatusTooManyRequests, "0"), wantDelay: 0, wantRetry: true},
+		{name: "throttled with invalid retry-after", err: graphError(http.StatusTooManyRequests, "soon"), wantDelay: 3 * time.Second, wantRetry: true},
+		{name: "throttled without retry-after", err: graphError(http.StatusTooManyRequests, ""), wantDelay: 3 * time.Second, wantRetry: true},
+		{name: "unavailable", err: graphError(http.StatusServiceUnavailable, "2"), wantDelay: 2 * time.Second, wantRetry: true},
+		{name: "gateway timeout", err: graphError(http.StatusGatewayTimeout, ""), wantDelay: 3 * time.Second, wantRetry: true},
+		{name: "wrapped", err: errors.Join(errors.New("listing failed"), graphError(http.StatusTooManyRequests, "1")), wantDelay: time.Second, wantRetry: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delay, retry := retryDelay(tt.err, 3*time.Second)
+			if retry != tt.wantRetry {
+				t.Fatalf("retry = %v, want %v", retry, tt.wantRetry)
+			}
+			if retry && delay != tt.wantDelay {
+				t.Errorf("delay = %v, want %v", delay, tt.wantDelay)
+			}
+		})
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   bool
+	}{
+		{name: "success", errs: nil, wantCalls: 1},
+		{name: "recovers after throttling", errs: []error{graphError(http.StatusTooManyRequests, "0"), graphError(http.StatusServiceUnavailable, "0")}, wantCalls: 3},
+		{name: "does not retry other errors", errs: []error{graphError(http.StatusBadRequest, "")}, wantCalls: 1, wantErr: true},
+		{name: "gives up after the maximum retries", errs: repeat(graphError(http.StatusTooManyRequests, "0"), maxRetries+5), wantCalls: maxRetries + 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			result, err := WithRetry(context.Background(), func() (string, error) {
+				calls++
+				if calls <= len(tt.errs) {
+					return "", tt.errs[calls-1]
+				}
+				return "ok", nil
+			})
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error: %v", err, tt.wantErr)
+			}
+			if err == nil && result != "ok" {
+				t.Errorf("result = %q, want ok", result)
+			}
+		})
+	}
+}
+
+func TestWithRetryStopsWhenContextIsDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var calls int
+	_, err := WithRetry(ctx, func() (string, error) {
+		calls++
+		return "", graphError(http.StatusTooManyRequests, "60")
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestIterateFollowsNextLink(t *testing.T) {
+	pages := map[string]*page{
+		"page2": {values: []string{"c", "d"}, nextLink: ptr("page3")},
+		"page3": {values: []string{"d", "e"}},
+	}
+
+	tests := []struct {
+		name     string
+		limit    int
+		dedupe   bool
+		want     []string
+		wantTop  int32
+		wantNext []string
+	}{
+		{name: "all pages", want: []string{"a", "b", "c", "d", "d", "e"}, wantTop: DefaultPageSize, wantNext: []string{"page2", "page3"}},
+		{name: "stops at the limit", limit: 3, want: []string{"a", "b", "c"}, wantTop: 3, wantNext: []string{"page2"}},
+		{name: "dedupe", dedupe: true, want: []string{"a", "b", "c", "d", "e"}, wantTop: DefaultPageSize, wantNext: []string{"page2", "page3"}},
+		{name: "duplicates don't count towards the limit", limit: 5, dedupe: true, want: []string{"a", "b", "c", "d", "e"}, wantTop: 5, wantNext: []string{"page2", "page3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				top       int32
+				nextLinks []string
+			)
+			iterator := New(
+				func(_ context.Context, q Query) (*page, error) {
+					top = *q.Top
+					return &page{values: []string{"a", "b"}, nextLink: ptr("page2")}, nil
+				},
+				func(_ context.Context, nextLink string) (*page, error) {
+					nextLinks = append(nextLinks, nextLink)
+					return pages[nextLink], nil
+				},
+			).WithLimit(tt.limit)
+			if tt.dedupe {
+				iterator.WithDedupe(func(item string) string { return item })
+			}
+
+			got, err := iterator.Collect(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("items = %v, want %v", got, tt.want)
+			}
+			if top != tt.wantTop {
+				t.Errorf("top = %d, want %d", top, tt.wantTop)
+			}
+			if !slices.Equal(nextLinks, tt.wantNext) {
+				t.Errorf("next links = %v, want %v", nextLinks, tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestPageSize(t *testing.T) {
+	defer SetPageSize(0)
+
+	for value, want := range map[string]int{"": DefaultPageSize, "25": 25, " 500 ": 500, "5000": MaxPageSize, "0": DefaultPageSize, "-1": DefaultPageSize, "many": DefaultPageSize} {
+		t.Setenv(EnvPageSize, value)
+		if got := PageSize(); got != want {
+			t.Errorf("%q: page size = %d, want %d", value, got, want)
+		}
+	}
+
+	t.Setenv(EnvPageSize, "25")
+	SetPageSize(50)
+	if got := PageSize(); got != 50 {
+		t.Errorf("page size = %d, want the size of the flag", got)
+	}
+
+	var top int32
+	if _, err := New(
+		func(_ context.Context, q Query) (*page, error) {
+			top = *q.Top
+			return &page{values: []string{"a"}}, nil
+		},
+		func(context.Context, string) (*page, error) { return nil, nil },
+	).WithLimit(80).Collect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if top != 50 {
+		t.Errorf("top = %d, want the page size below the limit", top)
+	}
+}
+
+func TestIterateRetriesThrottledPages(t *testing.T) {
+	var throttled bool
+	got, err := New(
+		func(_ context.Context, _ Query) (*page, error) {
+			return &page{values: []string{"a"}, nextLink: ptr("page2")}, nil
+		},
+		func(_ context.Context, _ string) (*page, error) {
+			if !throttled {
+				throttled = true
+				return nil, graphError(http.StatusTooManyRequests, "0")
+			}
+			return &page{values: []string{"b"}}, nil
+		},
+	).Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("items = %v, want [a b]", got)
+	}
+}
+
+func repeat(err error, n int) []error {
+	errs := make([]error, n)
+	for i := range errs {
+		errs[i] = err
+	}
+	return errs
+}
+
+func ptr(s string) *string {
+	return &s
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gptscript-ai/tools/outlook/common/auth"
+	"github.com/gptscript-ai/tools/outlook/common/fields"
+	"github.com/gptscript-ai/tools/outlook/common/pagination"
+	"github.com/gptscript-ai/tools/outlook/contacts/pkg/commands"
+	"github.com/gptscript-ai/tools/outlook/contacts/pkg/graph"
+)
+
+func main() {
+	// The user is only used with app-only auth, when there is no signed-in user
+	user := flag.String("user", "", "user principal name of the user to act on with app-only auth")
+	pageSize := flag.Int("page-size", 0, "number of items requested from Graph per page")
+	fieldSet := flag.String("fields", "", "field set (summary, default, full) or comma-separated properties of listed contacts")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		fmt.Println("Usage: contacts [--user <upn>] [--page-size <n>] [--fields <fields>] <command>")
+		os.Exit(1)
+	}
+	auth.SetUser(*user)
+	pagination.SetPageSize(*pageSize)
+	fields.Set(*fieldSet)
+
+	command := flag.Arg(0)
+
+	switch command {
+	case "listContacts":
+		limit, err := limitFromEnv()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		// The display name names the contacts in datasets
+		selects, err := graph.ContactFields.FromEnv("displayName")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if err := commands.ListContacts(context.Background(), os.Getenv("FOLDER_ID"), limit, selects); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "searchContacts":
+		limit, err := limitFromEnv()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		// The display name names the contacts in datasets
+		selects, err := graph.ContactFields.FromEnv("displayName")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if err := commands.SearchContacts(context.Background(), os.Getenv("FOLDER_ID"), os.Getenv("QUERY"), limit, selects); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "createContact":
+		if err := commands.CreateContact(context.Background(), os.Getenv("FOLDER_ID"), contactInfoFromEnv()); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "updateContact":
+		if err := commands.UpdateContact(context.Background(), os.Getenv("CONTACT_ID"), contactInfoFromEnv()); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "listContactFolders":
+		if err := commands.ListContactFolders(context.Background(), os.Getenv("PARENT_FOLDER_ID")); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "createContactFolder":
+		if err := commands.CreateContactFolder(context.Background(), os.Getenv("PARENT_FOLDER_ID"), os.Getenv("NAME")); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "renameContactFolder":
+		if err := commands.RenameContactFolder(context.Background(), os.Getenv("FOLDER_ID"), os.Getenv("NAME")); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Printf("Unknown command: %q\n", command)
+		os.Exit(1)
+	}
+}
+
+// contactInfoFromEnv returns the properties of a contact. Properties that are not set are nil, so that they are left
+// unchanged when updating a contact.
+func contactInfoFromEnv() graph.ContactInfo {
+	return graph.ContactInfo{
+		GivenName:      optionalEnv("GIVEN_NAME"),
+		Surname:        optionalEnv("SURNAME"),
+		DisplayName:    optionalEnv("DISPLAY_NAME"),
+		Company:        optionalEnv("COMPANY"),
+		JobTitle:       optionalEnv("JOB_TITLE"),
+		Department:     optionalEnv("DEPARTMENT"),
+		MobilePhone:    optionalEnv("MOBILE_PHONE"),
+		Notes:          optionalEnv("NOTES"),
+		Emails:         optionalList("EMAILS"),
+		BusinessPhones: optionalList("BUSINESS_PHONES"),
+		HomePhones:     optionalList("HOME_PHONES"),
+		Categories:     optionalList("CATEGORIES"),
+	}
+}
+
+// limitFromEnv returns the maximum number of contacts to return, 100 if it is not set.
+func limitFromEnv() (int, error) {
+	v := os.Getenv("LIMIT")
+	if v == "" {
+		return 100, nil
+	}
+	limit, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse limit: %w", err)
+	}
+	return limit, nil
+}
+
+// optionalEnv returns the value of the environment variable, or nil if it is not set or empty.
+func optionalEnv(name string) *string {
+	if v := os.Getenv(name); v != "" {
+		return &v
+	}
+	return nil
+}
+
+// optionalList returns the comma-separated list of the environment variable, or nil if it is not set or empty. The
+// value "none" returns an empty list, which clears the list when updating a contact.
+func optionalList(name string) []string {
+	v := os.Getenv(name)
+	if v == "" {
+		return nil
+	}
+	if strings.EqualFold(strings.TrimSpace(v), "none") {
+		return []string{}
+	}
+	return strings.Split(v, ",")
+}
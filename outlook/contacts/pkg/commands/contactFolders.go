@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/common/guard"
+	"github.com/gptscript-ai/tools/outlook/common/id"
+	"github.com/gptscript-ai/tools/outlook/contacts/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/contacts/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/contacts/pkg/graph"
+	"github.com/gptscript-ai/tools/outlook/contacts/pkg/printers"
+	"github.com/gptscript-ai/tools/outlook/contacts/pkg/util"
+)
+
+// ListContactFolders lists the contact folders below the default contacts folder, or the child folders of the parent
+// folder if it is set.
+func ListContactFolders(ctx context.Context, parentFolderID string) error {
+	trueParentFolderID, err := trueFolderID(ctx, parentFolderID)
+	if err != nil {
+		return err
+	}
+
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	folders, err := graph.ListContactFolders(ctx, c, trueParentFolderID)
+	if err != nil {
+		return err
+	}
+
+	if len(folders) == 0 {
+		fmt.Println("No contact folders found. Contacts without a folder are in the default contacts folder.")
+		return nil
+	}
+
+	var outlookIDs []string
+	for _, folder := range folders {
+		outlookIDs = append(outlookIDs, util.Deref(folder.GetId()))
+		if folder.GetParentFolderId() != nil {
+			outlookIDs = append(outlookIDs, util.Deref(folder.GetParentFolderId()))
+		}
+	}
+	translatedIDs, err := id.SetOutlookIDs(ctx, outlookIDs)
+	if err != nil {
+		return fmt.Errorf("failed to set Outlook IDs: %w", err)
+	}
+
+	var elements []gptscript.DatasetElement
+	for _, folder := range folders {
+		folder.SetId(util.Ptr(translatedIDs[util.Deref(folder.GetId())]))
+		if folder.GetParentFolderId() != nil {
+			folder.SetParentFolderId(util.Ptr(translatedIDs[util.Deref(folder.GetParentFolderId())]))
+		}
+
+		elements = append(elements, gptscript.DatasetElement{
+			DatasetElementMeta: gptscript.DatasetElementMeta{
+				Name:        util.Deref(folder.GetId()),
+				Description: util.Deref(folder.GetDisplayName()),
+			},
+			Contents: printers.ContactFolderToString(folder),
+		})
+	}
+
+	gptscriptClient, err := gptscript.NewGPTScript()
+	if err != nil {
+		return fmt.Errorf("failed to create GPTScript client: %w", err)
+	}
+
+	datasetName := "outlook_contact_folders"
+	if parentFolderID != "" {
+		datasetName = fmt.Sprintf("%s_outlook_contact_folders", parentFolderID)
+	}
+	return guard.PrintElements(ctx, gptscriptClient, elements, gptscript.DatasetOptions{
+		Name: datasetName,
+	}, "folders")
+}
+
+// CreateContactFolder creates a contact folder below the default contacts folder, or in the parent folder if it is
+// set.
+func CreateContactFolder(ctx context.Context, parentFolderID, name string) error {
+	if name = strings.TrimSpace(name); name == "" {
+		return fmt.Errorf("the folder name is required")
+	}
+
+	trueParentFolderID, err := trueFolderID(ctx, parentFolderID)
+	if err != nil {
+		return err
+	}
+
+	c, err := client.NewClient(global.AllScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	folder, err := graph.CreateContactFolder(ctx, c, trueParentFolderID, name)
+	if err != nil {
+		return err
+	}
+
+	folderID, err := id.SetOutlookID(ctx, util.Deref(folder.GetId()))
+	if err != nil {
+		return fmt.Errorf("failed to set folder ID: %w", err)
+	}
+
+	fmt.Printf("Contact folder %s created with ID: %s\n", name, folderID)
+	return nil
+}
+
+// RenameContactFolder changes the name of the contact folder.
+func RenameContactFolder(ctx context.Context, folderID, name string) error {
+	if name = strings.TrimSpace(name); name == "" {
+		return fmt.Errorf("the folder name is required")
+	}
+
+	trueFolderID, err := id.GetOutlookID(ctx, folderID)
+	if err != nil {
+		return fmt.Errorf("failed to get folder ID: %w", err)
+	}
+
+	c, err := client.NewClient(global.AllScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	if _, err := graph.RenameContactFolder(ctx, c, trueFolderID, name); err != nil {
+		return err
+	}
+
+	fmt.Printf("Contact folder renamed to %s\n", name)
+	return nil
+}
@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gptscript-ai/tools/outlook/common/id"
+	"github.com/gptscript-ai/tools/outlook/contacts/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/contacts/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/contacts/pkg/graph"
+	"github.com/gptscript-ai/tools/outlook/contacts/pkg/util"
+)
+
+// CreateContact creates the contact in the folder, or in the default contacts folder if folderID is empty.
+func CreateContact(ctx context.Context, folderID string, info graph.ContactInfo) error {
+	if util.Deref(info.DisplayName) == "" && util.Deref(info.GivenName) == "" && util.Deref(info.Surname) == "" {
+		return fmt.Errorf("a contact needs a display name, given name or surname")
+	}
+
+	trueFolderID, err := trueFolderID(ctx, folderID)
+	if err != nil {
+		return err
+	}
+
+	c, err := client.NewClient(global.AllScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	contact, err := graph.CreateContact(ctx, c, trueFolderID, info)
+	if err != nil {
+		return err
+	}
+
+	contactID, err := id.SetOutlookID(ctx, util.Deref(contact.GetId()))
+	if err != nil {
+		return fmt.Errorf("failed to set contact ID: %w", err)
+	}
+
+	fmt.Printf("Contact %s created with ID: %s\n", util.Deref(contact.GetDisplayName()), contactID)
+	return nil
+}
+
+// UpdateContact updates the properties of the contact that are set in the info.
+func UpdateContact(ctx context.Context, contactID string, info graph.ContactInfo) error {
+	if info.IsEmpty() {
+		return fmt.Errorf("nothing to update, set at least one property of the contact")
+	}
+
+	trueContactID, err := id.GetOutlookID(ctx, contactID)
+	if err != nil {
+		return fmt.Errorf("failed to get contact ID: %w", err)
+	}
+
+	c, err := client.NewClient(global.AllScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	contact, err := graph.UpdateContact(ctx, c, trueContactID, info)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Contact %s updated successfully\n", util.Deref(contact.GetDisplayName()))
+	return nil
+}
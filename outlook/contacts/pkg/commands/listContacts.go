@@ -0,0 +1,104 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/common/guard"
+	"github.com/gptscript-ai/tools/common/locale"
+	"github.com/gptscript-ai/tools/outlook/common/id"
+	"github.com/gptscript-ai/tools/outlook/contacts/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/contacts/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/contacts/pkg/graph"
+	"github.com/gptscript-ai/tools/outlook/contacts/pkg/printers"
+	"github.com/gptscript-ai/tools/outlook/contacts/pkg/util"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// ListContacts lists up to limit contacts of the folder, or of the default contacts folder if folderID is empty, with
+// the selected properties, see graph.ContactFields.
+func ListContacts(ctx context.Context, folderID string, limit int, selects []string) error {
+	trueFolderID, err := trueFolderID(ctx, folderID)
+	if err != nil {
+		return err
+	}
+
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	contacts, err := graph.ListContacts(ctx, c, trueFolderID, limit, selects)
+	if err != nil {
+		return err
+	}
+
+	if len(contacts) == 0 {
+		fmt.Println(locale.FromEnv().T("No contacts found"))
+		return nil
+	}
+
+	elements, err := contactElements(ctx, contacts)
+	if err != nil {
+		return err
+	}
+
+	gptscriptClient, err := gptscript.NewGPTScript()
+	if err != nil {
+		return fmt.Errorf("failed to create GPTScript client: %w", err)
+	}
+
+	datasetName := "outlook_contacts"
+	if folderID != "" {
+		datasetName = fmt.Sprintf("%s_outlook_contacts", folderID)
+	}
+	return guard.PrintElements(ctx, gptscriptClient, elements, gptscript.DatasetOptions{
+		Name:        datasetName,
+		Description: "Outlook contacts",
+	}, "contacts")
+}
+
+// contactElements translates the IDs of the contacts to friendly IDs and returns them as dataset elements.
+func contactElements(ctx context.Context, contacts []models.Contactable) ([]gptscript.DatasetElement, error) {
+	var outlookIDs []string
+	for _, contact := range contacts {
+		outlookIDs = append(outlookIDs, util.Deref(contact.GetId()))
+		if contact.GetParentFolderId() != nil {
+			outlookIDs = append(outlookIDs, util.Deref(contact.GetParentFolderId()))
+		}
+	}
+	translatedIDs, err := id.SetOutlookIDs(ctx, outlookIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set Outlook IDs: %w", err)
+	}
+
+	elements := make([]gptscript.DatasetElement, 0, len(contacts))
+	for _, contact := range contacts {
+		contact.SetId(util.Ptr(translatedIDs[util.Deref(contact.GetId())]))
+		if contact.GetParentFolderId() != nil {
+			contact.SetParentFolderId(util.Ptr(translatedIDs[util.Deref(contact.GetParentFolderId())]))
+		}
+
+		elements = append(elements, gptscript.DatasetElement{
+			DatasetElementMeta: gptscript.DatasetElementMeta{
+				Name:        util.Deref(contact.GetId()) + "_" + util.Deref(contact.GetDisplayName()),
+				Description: util.Deref(contact.GetDisplayName()),
+			},
+			Contents: printers.ContactToString(contact),
+		})
+	}
+	return elements, nil
+}
+
+// trueFolderID returns the Outlook ID of the folder, or an empty string for the default contacts folder.
+func trueFolderID(ctx context.Context, folderID string) (string, error) {
+	if folderID == "" {
+		return "", nil
+	}
+	trueFolderID, err := id.GetOutlookID(ctx, folderID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get folder ID: %w", err)
+	}
+	return trueFolderID, nil
+}
@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/common/guard"
+	"github.com/gptscript-ai/tools/common/locale"
+	"github.com/gptscript-ai/tools/outlook/common/pagination"
+	"github.com/gptscript-ai/tools/outlook/contacts/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/contacts/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/contacts/pkg/graph"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// SearchContacts lists the contacts of the folder that match the query, up to the limit (0 for no limit), with the
+// selected properties, see graph.ContactFields. The contacts are written in batches of one page, so large results that
+// go to a dataset don't have to be kept in memory.
+func SearchContacts(ctx context.Context, folderID, query string, limit int, selects []string) error {
+	trueFolderID, err := trueFolderID(ctx, folderID)
+	if err != nil {
+		return err
+	}
+
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	gptscriptClient, err := gptscript.NewGPTScript()
+	if err != nil {
+		return fmt.Errorf("failed to create GPTScript client: %w", err)
+	}
+
+	writer := guard.NewWriter(gptscriptClient, gptscript.DatasetOptions{
+		Name:        "contact_search " + query,
+		Description: "Search results for Outlook contacts",
+	}, "contacts")
+
+	var (
+		count    int
+		batch    []models.Contactable
+		writeErr error
+	)
+	write := func() {
+		var elements []gptscript.DatasetElement
+		if elements, writeErr = contactElements(ctx, batch); writeErr == nil {
+			writeErr = writer.Add(ctx, elements...)
+		}
+		batch = batch[:0]
+	}
+	if err := graph.SearchContacts(ctx, c, trueFolderID, query, selects, func(contact models.Contactable) bool {
+		count++
+		if batch = append(batch, contact); len(batch) >= pagination.PageSize() {
+			write()
+		}
+		return writeErr == nil && (limit <= 0 || count < limit)
+	}); err != nil {
+		return err
+	}
+	if writeErr != nil {
+		return writeErr
+	}
+	if len(batch) > 0 {
+		if write(); writeErr != nil {
+			return writeErr
+		}
+	}
+
+	if count == 0 {
+		fmt.Println(locale.FromEnv().T("No contacts found"))
+		return nil
+	}
+
+	return writer.Close(ctx)
+}
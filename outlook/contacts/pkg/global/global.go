@@ -0,0 +1,8 @@
+package global
+
+const CredentialEnv = "GPTSCRIPT_GRAPH_MICROSOFT_COM_BEARER_TOKEN"
+
+var (
+	ReadOnlyScopes = []string{"Contacts.Read", "User.Read"}
+	AllScopes      = []string{"Contacts.Read", "Contacts.ReadWrite", "User.Read"}
+)
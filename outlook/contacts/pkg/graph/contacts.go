@@ -0,0 +1,227 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gptscript-ai/tools/outlook/common/fields"
+	"github.com/gptscript-ai/tools/outlook/common/pagination"
+	"github.com/gptscript-ai/tools/outlook/contacts/pkg/util"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+// ContactInfo are the properties of a contact. When updating a contact, nil fields are left unchanged, and empty
+// lists are cleared.
+type ContactInfo struct {
+	GivenName, Surname, DisplayName                   *string
+	Company, JobTitle, Department, MobilePhone, Notes *string
+	Emails, BusinessPhones, HomePhones, Categories    []string
+}
+
+// IsEmpty returns whether none of the properties are set.
+func (info ContactInfo) IsEmpty() bool {
+	return info.GivenName == nil && info.Surname == nil && info.DisplayName == nil && info.Company == nil &&
+		info.JobTitle == nil && info.Department == nil && info.MobilePhone == nil && info.Notes == nil &&
+		info.Emails == nil && info.BusinessPhones == nil && info.HomePhones == nil && info.Categories == nil
+}
+
+// contactFromInfo returns a contact with the properties of the info that are set.
+func contactFromInfo(info ContactInfo) models.Contactable {
+	contact := models.NewContact()
+	if info.GivenName != nil {
+		contact.SetGivenName(info.GivenName)
+	}
+	if info.Surname != nil {
+		contact.SetSurname(info.Surname)
+	}
+	if info.DisplayName != nil {
+		contact.SetDisplayName(info.DisplayName)
+	}
+	if info.Company != nil {
+		contact.SetCompanyName(info.Company)
+	}
+	if info.JobTitle != nil {
+		contact.SetJobTitle(info.JobTitle)
+	}
+	if info.Department != nil {
+		contact.SetDepartment(info.Department)
+	}
+	if info.MobilePhone != nil {
+		contact.SetMobilePhone(info.MobilePhone)
+	}
+	if info.Notes != nil {
+		contact.SetPersonalNotes(info.Notes)
+	}
+	if info.Emails != nil {
+		emails := []models.EmailAddressable{}
+		for _, e := range trimList(info.Emails) {
+			email := models.NewEmailAddress()
+			email.SetAddress(util.Ptr(e))
+			emails = append(emails, email)
+		}
+		contact.SetEmailAddresses(emails)
+	}
+	if info.BusinessPhones != nil {
+		contact.SetBusinessPhones(trimList(info.BusinessPhones))
+	}
+	if info.HomePhones != nil {
+		contact.SetHomePhones(trimList(info.HomePhones))
+	}
+	if info.Categories != nil {
+		contact.SetCategories(trimList(info.Categories))
+	}
+	return contact
+}
+
+func trimList(list []string) []string {
+	result := []string{}
+	for _, s := range list {
+		if s = strings.TrimSpace(s); s != "" {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// ContactFields are the field sets of the commands that list and search contacts. The default set has the properties
+// that printers.ContactToString prints.
+var ContactFields = fields.Sets{
+	fields.Summary: {"displayName", "emailAddresses", "businessPhones", "mobilePhone"},
+	fields.Default: {"displayName", "emailAddresses", "businessPhones", "mobilePhone", "homePhones", "companyName", "jobTitle", "department", "businessAddress", "categories", "personalNotes", "parentFolderId"},
+	fields.Full:    nil,
+}
+
+// ListContacts returns up to limit contacts of the folder, or of the default contacts folder if folderID is empty,
+// ordered by display name. A limit of 0 or less returns all contacts. Only the selected properties are returned, all
+// default properties if selects is nil.
+func ListContacts(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, folderID string, limit int, selects []string) ([]models.Contactable, error) {
+	contacts, err := contactPages(client, folderID).WithSelect(selects...).WithLimit(limit).Collect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list contacts: %w", err)
+	}
+	return contacts, nil
+}
+
+// SearchContacts calls fn for the contacts of the folder that match the query, following @odata.nextLink across
+// pages. It stops when fn returns false. The contacts have the selected properties, all default properties if selects
+// is nil, and the properties that are matched.
+func SearchContacts(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, folderID, query string, selects []string, fn func(contact models.Contactable) bool) error {
+	selects = fields.With(selects, "displayName", "givenName", "surname", "companyName", "jobTitle", "department", "mobilePhone", "businessPhones", "homePhones", "emailAddresses")
+
+	// Graph doesn't support $search for contacts, and $filter can only match the start of a property, so the contacts
+	// are matched while paging
+	if err := contactPages(client, folderID).WithSelect(selects...).Iterate(ctx, func(contact models.Contactable) bool {
+		if !MatchesContact(contact, query) {
+			return true
+		}
+		return fn(contact)
+	}); err != nil {
+		return fmt.Errorf("failed to search contacts: %w", err)
+	}
+	return nil
+}
+
+// MatchesContact returns whether the names, email addresses, phone numbers, company, job title or department of the
+// contact contain the query, case-insensitively.
+func MatchesContact(contact models.Contactable, query string) bool {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return true
+	}
+
+	fields := []string{
+		util.Deref(contact.GetDisplayName()),
+		util.Deref(contact.GetGivenName()),
+		util.Deref(contact.GetSurname()),
+		util.Deref(contact.GetCompanyName()),
+		util.Deref(contact.GetJobTitle()),
+		util.Deref(contact.GetDepartment()),
+		util.Deref(contact.GetMobilePhone()),
+	}
+	fields = append(fields, contact.GetBusinessPhones()...)
+	fields = append(fields, contact.GetHomePhones()...)
+	for _, email := range contact.GetEmailAddresses() {
+		fields = append(fields, util.Deref(email.GetAddress()), util.Deref(email.GetName()))
+	}
+
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field), query) {
+			return true
+		}
+	}
+	return false
+}
+
+func contactPages(client *msgraphsdkgo.GraphServiceClient, folderID string) *pagination.PageIterator[models.Contactable] {
+	orderBy := []string{"displayName"}
+	if folderID == "" {
+		return pagination.New(
+			func(ctx context.Context, q pagination.Query) (models.ContactCollectionResponseable, error) {
+				return client.Me().Contacts().Get(ctx, &users.ItemContactsRequestBuilderGetRequestConfiguration{
+					QueryParameters: &users.ItemContactsRequestBuilderGetQueryParameters{
+						Orderby: orderBy,
+						Top:     q.Top,
+						Select:  q.Select,
+					},
+				})
+			},
+			func(ctx context.Context, nextLink string) (models.ContactCollectionResponseable, error) {
+				return client.Me().Contacts().WithUrl(nextLink).Get(ctx, nil)
+			},
+		)
+	}
+
+	contacts := client.Me().ContactFolders().ByContactFolderId(folderID).Contacts()
+	return pagination.New(
+		func(ctx context.Context, q pagination.Query) (models.ContactCollectionResponseable, error) {
+			return contacts.Get(ctx, &users.ItemContactFoldersItemContactsRequestBuilderGetRequestConfiguration{
+				QueryParameters: &users.ItemContactFoldersItemContactsRequestBuilderGetQueryParameters{
+					Orderby: orderBy,
+					Top:     q.Top,
+					Select:  q.Select,
+				},
+			})
+		},
+		func(ctx context.Context, nextLink string) (models.ContactCollectionResponseable, error) {
+			return contacts.WithUrl(nextLink).Get(ctx, nil)
+		},
+	)
+}
+
+// GetContact returns the contact.
+func GetContact(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, contactID string) (models.Contactable, error) {
+	contact, err := client.Me().Contacts().ByContactId(contactID).Get(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact: %w", err)
+	}
+	return contact, nil
+}
+
+// CreateContact creates the contact in the folder, or in the default contacts folder if folderID is empty.
+func CreateContact(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, folderID string, info ContactInfo) (models.Contactable, error) {
+	var (
+		contact models.Contactable
+		err     error
+	)
+	if folderID == "" {
+		contact, err = client.Me().Contacts().Post(ctx, contactFromInfo(info), nil)
+	} else {
+		contact, err = client.Me().ContactFolders().ByContactFolderId(folderID).Contacts().Post(ctx, contactFromInfo(info), nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create contact: %w", err)
+	}
+	return contact, nil
+}
+
+// UpdateContact updates the properties of the contact that are set in the info.
+func UpdateContact(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, contactID string, info ContactInfo) (models.Contactable, error) {
+	contact, err := client.Me().Contacts().ByContactId(contactID).Patch(ctx, contactFromInfo(info), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update contact: %w", err)
+	}
+	return contact, nil
+}
@@ -0,0 +1,56 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/gptscript-ai/tools/outlook/contacts/pkg/util"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchesContact(t *testing.T) {
+	email := models.NewEmailAddress()
+	email.SetAddress(util.Ptr("adele@contoso.com"))
+	email.SetName(util.Ptr("Adele Vance"))
+
+	contact := models.NewContact()
+	contact.SetDisplayName(util.Ptr("Adele Vance"))
+	contact.SetGivenName(util.Ptr("Adele"))
+	contact.SetSurname(util.Ptr("Vance"))
+	contact.SetCompanyName(util.Ptr("Contoso"))
+	contact.SetJobTitle(util.Ptr("Retail Manager"))
+	contact.SetBusinessPhones([]string{"+1 425 555 0109"})
+	contact.SetEmailAddresses([]models.EmailAddressable{email})
+
+	for _, query := range []string{"", "adele", "VANCE", "contoso.com", "contoso", "retail", "555 0109", "  adele  "} {
+		assert.True(t, MatchesContact(contact, query), query)
+	}
+	for _, query := range []string{"alex", "fabrikam", "0110"} {
+		assert.False(t, MatchesContact(contact, query), query)
+	}
+}
+
+func TestContactFromInfo(t *testing.T) {
+	contact := contactFromInfo(ContactInfo{
+		GivenName:      util.Ptr("Adele"),
+		Emails:         []string{" adele@contoso.com", "", "adele.vance@contoso.com "},
+		BusinessPhones: []string{},
+	})
+
+	assert.Equal(t, "Adele", util.Deref(contact.GetGivenName()))
+	assert.Nil(t, contact.GetSurname())
+	assert.Nil(t, contact.GetCompanyName())
+	assert.Equal(t, []string{"adele@contoso.com", "adele.vance@contoso.com"}, util.Map(contact.GetEmailAddresses(), func(email models.EmailAddressable) string {
+		return util.Deref(email.GetAddress())
+	}))
+	// An empty list clears the property, a nil list leaves it unchanged
+	assert.NotNil(t, contact.GetBusinessPhones())
+	assert.Empty(t, contact.GetBusinessPhones())
+	assert.Nil(t, contact.GetHomePhones())
+}
+
+func TestContactInfoIsEmpty(t *testing.T) {
+	assert.True(t, ContactInfo{}.IsEmpty())
+	assert.False(t, ContactInfo{Notes: util.Ptr("")}.IsEmpty())
+	assert.False(t, ContactInfo{Categories: []string{}}.IsEmpty())
+}
@@ -0,0 +1,86 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gptscript-ai/tools/outlook/common/pagination"
+	"github.com/gptscript-ai/tools/outlook/contacts/pkg/util"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+// ListContactFolders returns the contact folders below the default contacts folder, or the child folders of the
+// parent folder if parentFolderID is set.
+func ListContactFolders(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, parentFolderID string) ([]models.ContactFolderable, error) {
+	var (
+		folders []models.ContactFolderable
+		err     error
+	)
+	if parentFolderID == "" {
+		folders, err = pagination.New(
+			func(ctx context.Context, q pagination.Query) (models.ContactFolderCollectionResponseable, error) {
+				return client.Me().ContactFolders().Get(ctx, &users.ItemContactFoldersRequestBuilderGetRequestConfiguration{
+					QueryParameters: &users.ItemContactFoldersRequestBuilderGetQueryParameters{
+						Top: q.Top,
+					},
+				})
+			},
+			func(ctx context.Context, nextLink string) (models.ContactFolderCollectionResponseable, error) {
+				return client.Me().ContactFolders().WithUrl(nextLink).Get(ctx, nil)
+			},
+		).Collect(ctx)
+	} else {
+		childFolders := client.Me().ContactFolders().ByContactFolderId(parentFolderID).ChildFolders()
+		folders, err = pagination.New(
+			func(ctx context.Context, q pagination.Query) (models.ContactFolderCollectionResponseable, error) {
+				return childFolders.Get(ctx, &users.ItemContactFoldersItemChildFoldersRequestBuilderGetRequestConfiguration{
+					QueryParameters: &users.ItemContactFoldersItemChildFoldersRequestBuilderGetQueryParameters{
+						Top: q.Top,
+					},
+				})
+			},
+			func(ctx context.Context, nextLink string) (models.ContactFolderCollectionResponseable, error) {
+				return childFolders.WithUrl(nextLink).Get(ctx, nil)
+			},
+		).Collect(ctx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list contact folders: %w", err)
+	}
+	return folders, nil
+}
+
+// CreateContactFolder creates a contact folder below the default contacts folder, or in the parent folder if
+// parentFolderID is set.
+func CreateContactFolder(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, parentFolderID, name string) (models.ContactFolderable, error) {
+	requestBody := models.NewContactFolder()
+	requestBody.SetDisplayName(util.Ptr(name))
+
+	var (
+		folder models.ContactFolderable
+		err    error
+	)
+	if parentFolderID == "" {
+		folder, err = client.Me().ContactFolders().Post(ctx, requestBody, nil)
+	} else {
+		folder, err = client.Me().ContactFolders().ByContactFolderId(parentFolderID).ChildFolders().Post(ctx, requestBody, nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create contact folder: %w", err)
+	}
+	return folder, nil
+}
+
+// RenameContactFolder changes the name of the contact folder.
+func RenameContactFolder(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, folderID, name string) (models.ContactFolderable, error) {
+	requestBody := models.NewContactFolder()
+	requestBody.SetDisplayName(util.Ptr(name))
+
+	folder, err := client.Me().ContactFolders().ByContactFolderId(folderID).Patch(ctx, requestBody, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rename contact folder: %w", err)
+	}
+	return folder, nil
+}
@@ -0,0 +1,87 @@
+package printers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gptscript-ai/tools/common/locale"
+	"github.com/gptscript-ai/tools/outlook/contacts/pkg/util"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+func ContactToString(contact models.Contactable) string {
+	var (
+		result strings.Builder
+		loc    = locale.FromEnv()
+	)
+
+	result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Name"), util.Deref(contact.GetDisplayName())))
+	result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("ID"), util.Deref(contact.GetId())))
+
+	emails := util.Map(contact.GetEmailAddresses(), func(email models.EmailAddressable) string {
+		if name := util.Deref(email.GetName()); name != "" && name != util.Deref(email.GetAddress()) {
+			return fmt.Sprintf("%s <%s>", name, util.Deref(email.GetAddress()))
+		}
+		return util.Deref(email.GetAddress())
+	})
+	writeList(&result, loc.T("Email addresses"), emails)
+	writeList(&result, loc.T("Business phone"), contact.GetBusinessPhones())
+	writeField(&result, loc.T("Mobile phone"), util.Deref(contact.GetMobilePhone()))
+	writeList(&result, loc.T("Home phone"), contact.GetHomePhones())
+	writeField(&result, loc.T("Company"), util.Deref(contact.GetCompanyName()))
+	writeField(&result, loc.T("Job title"), util.Deref(contact.GetJobTitle()))
+	writeField(&result, loc.T("Department"), util.Deref(contact.GetDepartment()))
+	writeField(&result, loc.T("Business address"), addressToString(contact.GetBusinessAddress()))
+	writeList(&result, loc.T("Categories"), contact.GetCategories())
+	writeField(&result, loc.T("Notes"), util.Deref(contact.GetPersonalNotes()))
+	if contact.GetParentFolderId() != nil {
+		result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Parent folder ID"), util.Deref(contact.GetParentFolderId())))
+	}
+
+	return result.String()
+}
+
+func ContactFolderToString(folder models.ContactFolderable) string {
+	var (
+		result strings.Builder
+		loc    = locale.FromEnv()
+	)
+
+	result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Name"), util.Deref(folder.GetDisplayName())))
+	result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("ID"), util.Deref(folder.GetId())))
+	if folder.GetParentFolderId() != nil {
+		result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Parent folder ID"), util.Deref(folder.GetParentFolderId())))
+	}
+
+	return result.String()
+}
+
+// writeField writes the field if it has a value, so that contacts with few details stay short.
+func writeField(result *strings.Builder, name, value string) {
+	if value = strings.TrimSpace(value); value != "" {
+		result.WriteString(fmt.Sprintf("%s: %s\n", name, value))
+	}
+}
+
+func writeList(result *strings.Builder, name string, values []string) {
+	writeField(result, name, strings.Join(values, ", "))
+}
+
+func addressToString(address models.PhysicalAddressable) string {
+	if address == nil {
+		return ""
+	}
+
+	var parts []string
+	for _, part := range []string{
+		util.Deref(address.GetStreet()),
+		strings.TrimSpace(util.Deref(address.GetPostalCode()) + " " + util.Deref(address.GetCity())),
+		util.Deref(address.GetState()),
+		util.Deref(address.GetCountryOrRegion()),
+	} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -2,78 +2,355 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
+	"github.com/gptscript-ai/tools/outlook/common/attachments"
+	"github.com/gptscript-ai/tools/outlook/common/auth"
+	"github.com/gptscript-ai/tools/outlook/common/fields"
+	"github.com/gptscript-ai/tools/outlook/common/pagination"
 	"github.com/gptscript-ai/tools/outlook/mail/pkg/commands"
 	"github.com/gptscript-ai/tools/outlook/mail/pkg/graph"
 )
 
 func main() {
-	if len(os.Args) != 2 {
-		fmt.Println("Usage: mail <command>")
+	// The user is only used with app-only auth, when there is no signed-in user
+	user := flag.String("user", "", "user principal name of the user to act on with app-only auth")
+	pageSize := flag.Int("page-size", 0, "number of items requested from Graph per page")
+	fieldSet := flag.String("fields", "", "field set (summary, default, full) or comma-separated properties of listed messages")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		fmt.Println("Usage: mail [--user <upn>] [--page-size <n>] [--fields <fields>] <command>")
 		os.Exit(1)
 	}
+	auth.SetUser(*user)
+	pagination.SetPageSize(*pageSize)
+	fields.Set(*fieldSet)
 
-	command := os.Args[1]
+	command := flag.Arg(0)
 
 	switch command {
 	case "listMailFolders":
-		if err := commands.ListMailFolders(context.Background()); err != nil {
+		if err := commands.ListMailFolders(context.Background(), os.Getenv("MAILBOX"), os.Getenv("PARENT_FOLDER_ID")); err != nil {
 			fmt.Printf("failed to list mail folders: %v\n", err)
 			os.Exit(1)
 		}
+	case "createMailFolder":
+		if err := commands.CreateMailFolder(context.Background(), os.Getenv("MAILBOX"), os.Getenv("PARENT_FOLDER_ID"), os.Getenv("NAME")); err != nil {
+			fmt.Printf("failed to create mail folder: %v\n", err)
+			os.Exit(1)
+		}
+	case "renameMailFolder":
+		if err := commands.RenameMailFolder(context.Background(), os.Getenv("MAILBOX"), os.Getenv("FOLDER_ID"), os.Getenv("NAME")); err != nil {
+			fmt.Printf("failed to rename mail folder: %v\n", err)
+			os.Exit(1)
+		}
+	case "deleteMailFolder":
+		if err := commands.DeleteMailFolder(context.Background(), os.Getenv("MAILBOX"), os.Getenv("FOLDER_ID")); err != nil {
+			fmt.Printf("failed to delete mail folder: %v\n", err)
+			os.Exit(1)
+		}
+	case "moveMailFolder":
+		if err := commands.MoveMailFolder(context.Background(), os.Getenv("MAILBOX"), os.Getenv("FOLDER_ID"), os.Getenv("DESTINATION_FOLDER_ID")); err != nil {
+			fmt.Printf("failed to move mail folder: %v\n", err)
+			os.Exit(1)
+		}
 	case "listMessages":
 		if err := commands.ListMessages(
 			context.Background(),
+			os.Getenv("MAILBOX"),
 			os.Getenv("FOLDER_ID"),
 			os.Getenv("START"),
 			os.Getenv("END"),
+			os.Getenv("SINCE"),
+			os.Getenv("CLASSIFICATION"),
 			os.Getenv("LIMIT"),
+			fields.Value(),
 		); err != nil {
 			fmt.Printf("failed to list mail: %v\n", err)
 			os.Exit(1)
 		}
+	case "getNewMessages":
+		var reset bool
+		if v := os.Getenv("RESET"); v != "" {
+			var err error
+			reset, err = strconv.ParseBool(v)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+		if err := commands.GetNewMessages(context.Background(), os.Getenv("MAILBOX"), os.Getenv("FOLDER_ID"), os.Getenv("SINCE"), reset); err != nil {
+			fmt.Printf("failed to get new messages: %v\n", err)
+			os.Exit(1)
+		}
 	case "getMessageDetails":
-		if err := commands.GetMessageDetails(context.Background(), os.Getenv("MESSAGE_ID")); err != nil {
+		if err := commands.GetMessageDetails(context.Background(), os.Getenv("MAILBOX"), os.Getenv("MESSAGE_ID")); err != nil {
 			fmt.Printf("failed to get message details: %v\n", err)
 			os.Exit(1)
 		}
+	case "getMessageHeaders":
+		if err := commands.GetMessageHeaders(context.Background(), os.Getenv("MAILBOX"), os.Getenv("MESSAGE_ID")); err != nil {
+			fmt.Printf("failed to get message headers: %v\n", err)
+			os.Exit(1)
+		}
+	case "listAttachments":
+		if err := commands.ListAttachments(context.Background(), os.Getenv("MAILBOX"), os.Getenv("MESSAGE_ID")); err != nil {
+			fmt.Printf("failed to list attachments: %v\n", err)
+			os.Exit(1)
+		}
+	case "downloadAttachment":
+		if err := commands.DownloadAttachment(context.Background(), os.Getenv("MAILBOX"), os.Getenv("MESSAGE_ID"), os.Getenv("ATTACHMENT_ID"), os.Getenv("FILE_NAME")); err != nil {
+			var violation *attachments.PolicyViolationError
+			if errors.As(err, &violation) {
+				fmt.Printf("failed to download attachment: attachment rejected by policy: %s\n", violation.JSON())
+				os.Exit(1)
+			}
+			fmt.Printf("failed to download attachment: %v\n", err)
+			os.Exit(1)
+		}
+	case "downloadMessageEml":
+		if err := commands.DownloadMessageEml(context.Background(), os.Getenv("MAILBOX"), os.Getenv("MESSAGE_ID"), os.Getenv("FILE_NAME")); err != nil {
+			fmt.Printf("failed to download message: %v\n", err)
+			os.Exit(1)
+		}
+	case "getThread":
+		if err := commands.GetThread(context.Background(), os.Getenv("MAILBOX"), os.Getenv("MESSAGE_ID")); err != nil {
+			fmt.Printf("failed to get thread: %v\n", err)
+			os.Exit(1)
+		}
+	case "summarizeThread":
+		if err := commands.SummarizeThread(context.Background(), os.Getenv("MAILBOX"), os.Getenv("MESSAGE_ID")); err != nil {
+			fmt.Printf("failed to summarize thread: %v\n", err)
+			os.Exit(1)
+		}
 	case "searchMessages":
+		query := graph.SearchQuery{
+			Subject:     os.Getenv("SUBJECT"),
+			FromAddress: os.Getenv("FROM_ADDRESS"),
+			FromName:    os.Getenv("FROM_NAME"),
+			Start:       os.Getenv("START"),
+			End:         os.Getenv("END"),
+		}
+		if err := parseBoolsFromEnv(map[string]*bool{
+			"HAS_ATTACHMENTS": &query.HasAttachments,
+			"UNREAD_ONLY":     &query.UnreadOnly,
+		}); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
 		if err := commands.SearchMessages(
 			context.Background(),
-			os.Getenv("SUBJECT"),
-			os.Getenv("FROM_ADDRESS"),
-			os.Getenv("FROM_NAME"),
+			os.Getenv("MAILBOX"),
 			os.Getenv("FOLDER_ID"),
-			os.Getenv("START"),
-			os.Getenv("END"),
+			query,
+			os.Getenv("IMPORTANCE"),
+			os.Getenv("CLASSIFICATION"),
 			os.Getenv("LIMIT"),
+			fields.Value(),
 		); err != nil {
 			fmt.Printf("failed to search messages: %v\n", err)
 			os.Exit(1)
 		}
+	case "getSignature":
+		if err := commands.GetSignature(context.Background(), os.Getenv("MAILBOX")); err != nil {
+			fmt.Printf("failed to get signature: %v\n", err)
+			os.Exit(1)
+		}
 	case "createDraft":
-		if err := commands.CreateDraft(context.Background(), getDraftInfoFromEnv()); err != nil {
+		info, err := getDraftInfoFromEnv()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if err := commands.CreateDraft(context.Background(), info); err != nil {
+			var violation *attachments.PolicyViolationError
+			if errors.As(err, &violation) {
+				fmt.Printf("failed to create draft: attachment rejected by policy: %s\n", violation.JSON())
+				os.Exit(1)
+			}
 			fmt.Printf("failed to create draft: %v\n", err)
 			os.Exit(1)
 		}
+	case "listDrafts":
+		if err := commands.ListDrafts(context.Background(), os.Getenv("MAILBOX"), os.Getenv("LIMIT")); err != nil {
+			fmt.Printf("failed to list drafts: %v\n", err)
+			os.Exit(1)
+		}
+	case "updateDraft":
+		info := graph.DraftInfo{
+			Subject:    os.Getenv("SUBJECT"),
+			Body:       os.Getenv("BODY"),
+			Recipients: listToUpdate("RECIPIENTS"),
+			CC:         listToUpdate("CC"),
+			BCC:        listToUpdate("BCC"),
+			Mailbox:    os.Getenv("MAILBOX"),
+		}
+		if err := setDraftOptionsFromEnv(&info); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if err := commands.UpdateDraft(context.Background(), os.Getenv("DRAFT_ID"), info); err != nil {
+			fmt.Printf("failed to update draft: %v\n", err)
+			os.Exit(1)
+		}
+	case "addAttachmentToDraft":
+		if err := commands.AddAttachmentToDraft(context.Background(), os.Getenv("MAILBOX"), os.Getenv("DRAFT_ID"), os.Getenv("ATTACHMENTS")); err != nil {
+			var violation *attachments.PolicyViolationError
+			if errors.As(err, &violation) {
+				fmt.Printf("failed to add attachment to draft: attachment rejected by policy: %s\n", violation.JSON())
+				os.Exit(1)
+			}
+			fmt.Printf("failed to add attachment to draft: %v\n", err)
+			os.Exit(1)
+		}
 	case "sendDraft":
-		if err := commands.SendDraft(context.Background(), os.Getenv("DRAFT_ID")); err != nil {
+		if err := commands.SendDraft(context.Background(), os.Getenv("MAILBOX"), os.Getenv("DRAFT_ID")); err != nil {
 			fmt.Printf("failed to send draft: %v\n", err)
 			os.Exit(1)
 		}
+	case "forwardMessage":
+		if err := commands.ForwardMessage(context.Background(), os.Getenv("MAILBOX"), os.Getenv("MESSAGE_ID"), os.Getenv("RECIPIENTS"), os.Getenv("COMMENT")); err != nil {
+			fmt.Printf("failed to forward message: %v\n", err)
+			os.Exit(1)
+		}
 	case "deleteMessage":
-		if err := commands.DeleteMessage(context.Background(), os.Getenv("MESSAGE_ID")); err != nil {
+		if err := commands.DeleteMessage(context.Background(), os.Getenv("MAILBOX"), os.Getenv("MESSAGE_ID")); err != nil {
 			fmt.Printf("failed to delete message: %v\n", err)
 			os.Exit(1)
 		}
 	case "moveMessage":
-		if err := commands.MoveMessage(context.Background(), os.Getenv("MESSAGE_ID"), os.Getenv("DESTINATION_FOLDER_ID")); err != nil {
+		if err := commands.MoveMessage(context.Background(), os.Getenv("MAILBOX"), os.Getenv("MESSAGE_ID"), os.Getenv("DESTINATION_FOLDER_ID")); err != nil {
 			fmt.Printf("failed to move message: %v\n", err)
 			os.Exit(1)
 		}
+	case "bulkMoveMessages":
+		if err := commands.BulkMoveMessages(context.Background(), os.Getenv("MAILBOX"), os.Getenv("MESSAGE_IDS"), os.Getenv("DESTINATION_FOLDER_ID")); err != nil {
+			fmt.Printf("failed to move messages: %v\n", err)
+			os.Exit(1)
+		}
+	case "bulkDeleteMessages":
+		if err := commands.BulkDeleteMessages(context.Background(), os.Getenv("MAILBOX"), os.Getenv("MESSAGE_IDS")); err != nil {
+			fmt.Printf("failed to delete messages: %v\n", err)
+			os.Exit(1)
+		}
+	case "bulkArchiveMessages":
+		if err := commands.BulkArchiveMessages(context.Background(), os.Getenv("MAILBOX"), os.Getenv("MESSAGE_IDS")); err != nil {
+			fmt.Printf("failed to archive messages: %v\n", err)
+			os.Exit(1)
+		}
+	case "bulkMarkMessagesRead":
+		read := true
+		if err := parseBoolsFromEnv(map[string]*bool{"READ": &read}); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if err := commands.BulkMarkMessagesRead(context.Background(), os.Getenv("MAILBOX"), os.Getenv("MESSAGE_IDS"), read); err != nil {
+			fmt.Printf("failed to mark messages: %v\n", err)
+			os.Exit(1)
+		}
+	case "archiveMessage":
+		if err := commands.ArchiveMessage(context.Background(), os.Getenv("MAILBOX"), os.Getenv("MESSAGE_ID")); err != nil {
+			fmt.Printf("failed to archive message: %v\n", err)
+			os.Exit(1)
+		}
+	case "flagMessage":
+		if err := commands.FlagMessage(context.Background(), os.Getenv("MAILBOX"), os.Getenv("MESSAGE_ID"), os.Getenv("STATUS"), os.Getenv("DUE_DATE")); err != nil {
+			fmt.Printf("failed to flag message: %v\n", err)
+			os.Exit(1)
+		}
+	case "listCategories":
+		if err := commands.ListCategories(context.Background(), os.Getenv("MAILBOX")); err != nil {
+			fmt.Printf("failed to list categories: %v\n", err)
+			os.Exit(1)
+		}
+	case "addMessageCategories":
+		if err := commands.UpdateMessageCategories(context.Background(), os.Getenv("MAILBOX"), os.Getenv("MESSAGE_ID"), true, os.Getenv("CATEGORIES")); err != nil {
+			fmt.Printf("failed to add message categories: %v\n", err)
+			os.Exit(1)
+		}
+	case "removeMessageCategories":
+		if err := commands.UpdateMessageCategories(context.Background(), os.Getenv("MAILBOX"), os.Getenv("MESSAGE_ID"), false, os.Getenv("CATEGORIES")); err != nil {
+			fmt.Printf("failed to remove message categories: %v\n", err)
+			os.Exit(1)
+		}
+	case "reportJunk":
+		if err := commands.ReportJunk(context.Background(), os.Getenv("MAILBOX"), os.Getenv("MESSAGE_ID"), true); err != nil {
+			fmt.Printf("failed to report junk: %v\n", err)
+			os.Exit(1)
+		}
+	case "reportNotJunk":
+		if err := commands.ReportJunk(context.Background(), os.Getenv("MAILBOX"), os.Getenv("MESSAGE_ID"), false); err != nil {
+			fmt.Printf("failed to report not junk: %v\n", err)
+			os.Exit(1)
+		}
+	case "reportPhishing":
+		if err := commands.ReportPhishing(context.Background(), os.Getenv("MAILBOX"), os.Getenv("MESSAGE_ID")); err != nil {
+			fmt.Printf("failed to report phishing: %v\n", err)
+			os.Exit(1)
+		}
+	case "listSenderLists":
+		if err := commands.ListSenderLists(context.Background(), os.Getenv("MAILBOX")); err != nil {
+			fmt.Printf("failed to list blocked and safe senders: %v\n", err)
+			os.Exit(1)
+		}
+	case "blockSenders":
+		if err := commands.UpdateSenderList(context.Background(), os.Getenv("MAILBOX"), graph.BlockedSenders, true, os.Getenv("ADDRESSES")); err != nil {
+			fmt.Printf("failed to block senders: %v\n", err)
+			os.Exit(1)
+		}
+	case "unblockSenders":
+		if err := commands.UpdateSenderList(context.Background(), os.Getenv("MAILBOX"), graph.BlockedSenders, false, os.Getenv("ADDRESSES")); err != nil {
+			fmt.Printf("failed to unblock senders: %v\n", err)
+			os.Exit(1)
+		}
+	case "addSafeSenders":
+		if err := commands.UpdateSenderList(context.Background(), os.Getenv("MAILBOX"), graph.SafeSenders, true, os.Getenv("ADDRESSES")); err != nil {
+			fmt.Printf("failed to add safe senders: %v\n", err)
+			os.Exit(1)
+		}
+	case "removeSafeSenders":
+		if err := commands.UpdateSenderList(context.Background(), os.Getenv("MAILBOX"), graph.SafeSenders, false, os.Getenv("ADDRESSES")); err != nil {
+			fmt.Printf("failed to remove safe senders: %v\n", err)
+			os.Exit(1)
+		}
+	case "listInboxRules":
+		if err := commands.ListInboxRules(context.Background(), os.Getenv("MAILBOX")); err != nil {
+			fmt.Printf("failed to list inbox rules: %v\n", err)
+			os.Exit(1)
+		}
+	case "createInboxRule":
+		info, err := getRuleInfoFromEnv()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if err := commands.CreateInboxRule(context.Background(), os.Getenv("MAILBOX"), info); err != nil {
+			fmt.Printf("failed to create inbox rule: %v\n", err)
+			os.Exit(1)
+		}
+	case "deleteInboxRule":
+		if err := commands.DeleteInboxRule(context.Background(), os.Getenv("MAILBOX"), os.Getenv("RULE_ID")); err != nil {
+			fmt.Printf("failed to delete inbox rule: %v\n", err)
+			os.Exit(1)
+		}
+	case "getAutomaticReplies":
+		if err := commands.GetAutomaticReplies(context.Background()); err != nil {
+			fmt.Printf("failed to get automatic replies: %v\n", err)
+			os.Exit(1)
+		}
+	case "setAutomaticReplies":
+		if err := commands.SetAutomaticReplies(context.Background(), os.Getenv("STATUS"), os.Getenv("START"), os.Getenv("END"), os.Getenv("INTERNAL_MESSAGE"), os.Getenv("EXTERNAL_MESSAGE"), os.Getenv("EXTERNAL_AUDIENCE")); err != nil {
+			fmt.Printf("failed to set automatic replies: %v\n", err)
+			os.Exit(1)
+		}
 	case "getDefaultTimezone":
 		if err := commands.GetDefaultTimezone(context.Background()); err != nil {
 			fmt.Printf("failed to get default timezone: %v\n", err)
@@ -85,10 +362,17 @@ func main() {
 	}
 }
 
-func getDraftInfoFromEnv() graph.DraftInfo {
+func getDraftInfoFromEnv() (graph.DraftInfo, error) {
 	var attachments []string
 	if os.Getenv("ATTACHMENTS") != "" {
-		attachments = strings.Split(os.Getenv("ATTACHMENTS"), ",")
+		for _, file := range strings.Split(os.Getenv("ATTACHMENTS"), ",") {
+			attachments = append(attachments, strings.TrimSpace(file))
+		}
+	}
+
+	files, err := parseInlineAttachments(os.Getenv("INLINE_ATTACHMENTS"))
+	if err != nil {
+		return graph.DraftInfo{}, err
 	}
 
 	info := graph.DraftInfo{
@@ -98,11 +382,129 @@ func getDraftInfoFromEnv() graph.DraftInfo {
 		CC:          strings.Split(os.Getenv("CC"), ","),
 		BCC:         strings.Split(os.Getenv("BCC"), ","),
 		Attachments: attachments,
+		Files:       files,
+		Mailbox:     os.Getenv("MAILBOX"),
+		Mentions:    splitList(os.Getenv("MENTIONS")),
+	}
+	if err := parseBoolsFromEnv(map[string]*bool{"APPEND_SIGNATURE": &info.AppendSignature}); err != nil {
+		return graph.DraftInfo{}, err
+	}
+	if err := setDraftOptionsFromEnv(&info); err != nil {
+		return graph.DraftInfo{}, err
 	}
 
 	// We need to unset BODY, because if it's still set when we try to write files to the workspace,
 	// it will cause problems, since the workspace tools have an argument with the same name.
 	_ = os.Unsetenv("BODY")
 
-	return info
+	return info, nil
+}
+
+// setDraftOptionsFromEnv sets the importance and sensitivity of the draft from the IMPORTANCE and SENSITIVITY
+// environment variables, if they are set.
+func setDraftOptionsFromEnv(info *graph.DraftInfo) error {
+	var err error
+	if v := os.Getenv("IMPORTANCE"); v != "" {
+		if info.Importance, err = graph.ParseImportance(v); err != nil {
+			return err
+		}
+	}
+	if v := os.Getenv("SENSITIVITY"); v != "" {
+		if info.Sensitivity, err = graph.ParseSensitivity(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseInlineAttachments parses attachments whose content is passed directly, as a JSON list of objects with a name
+// and either a text content or a base64 encoded content.
+func parseInlineAttachments(s string) ([]graph.AttachmentFile, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	var inline []struct {
+		Name          string `json:"name"`
+		Content       string `json:"content"`
+		ContentBase64 string `json:"content_base64"`
+	}
+	if err := json.Unmarshal([]byte(s), &inline); err != nil {
+		return nil, fmt.Errorf("failed to parse inline attachments: %w", err)
+	}
+
+	files := make([]graph.AttachmentFile, 0, len(inline))
+	for _, attachment := range inline {
+		data := []byte(attachment.Content)
+		if attachment.ContentBase64 != "" {
+			var err error
+			data, err = base64.StdEncoding.DecodeString(attachment.ContentBase64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode content of inline attachment %s: %w", attachment.Name, err)
+			}
+		}
+		files = append(files, graph.AttachmentFile{Name: attachment.Name, Data: data})
+	}
+	return files, nil
+}
+
+func getRuleInfoFromEnv() (graph.RuleInfo, error) {
+	info := graph.RuleInfo{
+		Name:            os.Getenv("NAME"),
+		FromAddresses:   splitList(os.Getenv("FROM_ADDRESSES")),
+		SenderContains:  splitList(os.Getenv("SENDER_CONTAINS")),
+		SubjectContains: splitList(os.Getenv("SUBJECT_CONTAINS")),
+		BodyContains:    splitList(os.Getenv("BODY_CONTAINS")),
+		MoveToFolderID:  os.Getenv("MOVE_TO_FOLDER_ID"),
+		CopyToFolderID:  os.Getenv("COPY_TO_FOLDER_ID"),
+		Categories:      splitList(os.Getenv("CATEGORIES")),
+	}
+
+	if err := parseBoolsFromEnv(map[string]*bool{
+		"HAS_ATTACHMENTS":       &info.HasAttachments,
+		"MARK_AS_READ":          &info.MarkAsRead,
+		"DELETE":                &info.Delete,
+		"STOP_PROCESSING_RULES": &info.StopProcessingRules,
+	}); err != nil {
+		return graph.RuleInfo{}, err
+	}
+
+	return info, nil
+}
+
+// parseBoolsFromEnv sets the bools from the environment variables that are set
+func parseBoolsFromEnv(vars map[string]*bool) error {
+	for env, value := range vars {
+		if v := os.Getenv(env); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("invalid value for %s: %w", strings.ToLower(env), err)
+			}
+			*value = b
+		}
+	}
+	return nil
+}
+
+// listToUpdate returns the list in the environment variable, nil if it is not set, or an empty list if it is set to
+// an empty value, so that it is cleared.
+func listToUpdate(env string) []string {
+	v, ok := os.LookupEnv(env)
+	if !ok {
+		return nil
+	}
+	if list := splitList(v); list != nil {
+		return list
+	}
+	return []string{}
+}
+
+func splitList(s string) []string {
+	var result []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
 }
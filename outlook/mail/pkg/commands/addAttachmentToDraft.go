@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gptscript-ai/tools/outlook/common/id"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/graph"
+)
+
+// AddAttachmentToDraft attaches a comma-separated list of workspace files to an existing draft.
+func AddAttachmentToDraft(ctx context.Context, mailbox, draftID, files string) error {
+	var paths []string
+	for _, file := range strings.Split(files, ",") {
+		if file = strings.TrimSpace(file); file != "" {
+			paths = append(paths, file)
+		}
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("no files to attach, set the workspace paths of the files")
+	}
+
+	trueDraftID, err := id.GetOutlookID(ctx, draftID)
+	if err != nil {
+		return fmt.Errorf("failed to get outlook ID: %w", err)
+	}
+
+	c, err := client.NewClient(global.AllScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	if err := graph.AddAttachmentsToDraft(ctx, c, mailbox, trueDraftID, paths); err != nil {
+		return err
+	}
+
+	fmt.Printf("Attached %d file(s) to draft %s\n", len(paths), draftID)
+	return nil
+}
@@ -0,0 +1,169 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/common/guard"
+	"github.com/gptscript-ai/tools/common/locale"
+	"github.com/gptscript-ai/tools/outlook/common/attachments"
+	"github.com/gptscript-ai/tools/outlook/common/id"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/graph"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/util"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+func ListAttachments(ctx context.Context, mailbox, messageID string) error {
+	trueMessageID, err := id.GetOutlookID(ctx, messageID)
+	if err != nil {
+		return fmt.Errorf("failed to get message ID: %w", err)
+	}
+
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	result, err := graph.ListAttachments(ctx, c, mailbox, trueMessageID)
+	if err != nil {
+		return fmt.Errorf("failed to list attachments: %w", err)
+	}
+
+	if len(result) == 0 {
+		fmt.Println("The message has no attachments")
+		return nil
+	}
+
+	attachmentIDs := util.Map(result, func(attachment models.Attachmentable) string {
+		return util.Deref(attachment.GetId())
+	})
+	translatedAttachmentIDs, err := id.SetOutlookIDs(ctx, attachmentIDs)
+	if err != nil {
+		return fmt.Errorf("failed to set Outlook IDs: %w", err)
+	}
+
+	loc := locale.FromEnv()
+	elements := make([]gptscript.DatasetElement, 0, len(result))
+	for _, attachment := range result {
+		attachmentID := translatedAttachmentIDs[util.Deref(attachment.GetId())]
+
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Name"), util.Deref(attachment.GetName())))
+		sb.WriteString(fmt.Sprintf("%s: %s\n", loc.T("ID"), attachmentID))
+		sb.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Type"), attachmentKind(attachment)))
+		sb.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Content Type"), util.Deref(attachment.GetContentType())))
+		sb.WriteString(fmt.Sprintf("%s: %s %s\n", loc.T("Size"), loc.Int(int64(util.Deref(attachment.GetSize()))), loc.T("bytes")))
+		sb.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Is inline"), loc.Bool(util.Deref(attachment.GetIsInline()))))
+
+		elements = append(elements, gptscript.DatasetElement{
+			DatasetElementMeta: gptscript.DatasetElementMeta{
+				Name:        attachmentID,
+				Description: util.Deref(attachment.GetName()),
+			},
+			Contents: sb.String(),
+		})
+	}
+
+	return guard.PrintElements(ctx, nil, elements, gptscript.DatasetOptions{
+		Name:        fmt.Sprintf("%s_outlook_mail_attachments", messageID),
+		Description: "Attachments of Outlook mail message " + messageID,
+	}, "attachments")
+}
+
+func DownloadAttachment(ctx context.Context, mailbox, messageID, attachmentID, fileName string) error {
+	trueMessageID, err := id.GetOutlookID(ctx, messageID)
+	if err != nil {
+		return fmt.Errorf("failed to get message ID: %w", err)
+	}
+
+	trueAttachmentID, err := id.GetOutlookID(ctx, attachmentID)
+	if err != nil {
+		return fmt.Errorf("failed to get attachment ID: %w", err)
+	}
+
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	attachment, err := graph.GetAttachment(ctx, c, mailbox, trueMessageID, trueAttachmentID)
+	if err != nil {
+		return fmt.Errorf("failed to get attachment: %w", err)
+	}
+
+	if fileName == "" {
+		fileName = util.Deref(attachment.GetName())
+		if util.Deref(attachment.GetOdataType()) == graph.ItemAttachmentType && !strings.HasSuffix(strings.ToLower(fileName), ".eml") {
+			fileName += ".eml"
+		}
+	}
+	fileName, err = cleanFileName(fileName)
+	if err != nil {
+		return err
+	}
+
+	switch util.Deref(attachment.GetOdataType()) {
+	case graph.FileAttachmentType, graph.ItemAttachmentType:
+	case graph.ReferenceAttachmentType:
+		return fmt.Errorf("attachment %s is a link to a cloud file and can't be downloaded", util.Deref(attachment.GetName()))
+	default:
+		return fmt.Errorf("unsupported attachment type %q", util.Deref(attachment.GetOdataType()))
+	}
+
+	policy, err := attachments.PolicyFromEnv()
+	if err != nil {
+		return fmt.Errorf("failed to load attachment policy: %w", err)
+	}
+	if err := policy.CheckMetadata(fileName, int64(util.Deref(attachment.GetSize()))); err != nil {
+		return err
+	}
+
+	data, err := graph.DownloadAttachment(ctx, c, mailbox, trueMessageID, trueAttachmentID, int64(util.Deref(attachment.GetSize())))
+	if err != nil {
+		return fmt.Errorf("failed to download attachment: %w", err)
+	}
+
+	if err := policy.Check(ctx, fileName, data); err != nil {
+		return err
+	}
+
+	gptscriptClient, err := gptscript.NewGPTScript()
+	if err != nil {
+		return fmt.Errorf("failed to create GPTScript client: %w", err)
+	}
+
+	if err := gptscriptClient.WriteFileInWorkspace(ctx, path.Join("files", fileName), data); err != nil {
+		return fmt.Errorf("failed to save attachment to workspace: %w", err)
+	}
+
+	fmt.Printf("Attachment saved to the workspace as %s (%d bytes)\n", fileName, len(data))
+	return nil
+}
+
+func attachmentKind(attachment models.Attachmentable) string {
+	switch util.Deref(attachment.GetOdataType()) {
+	case graph.FileAttachmentType:
+		return "file"
+	case graph.ItemAttachmentType:
+		return "item (an attached message, event or contact)"
+	case graph.ReferenceAttachmentType:
+		return "link to a cloud file"
+	default:
+		return util.Deref(attachment.GetOdataType())
+	}
+}
+
+// cleanFileName keeps the file inside the files of the workspace
+func cleanFileName(fileName string) (string, error) {
+	cleaned := strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(fileName, "\\", "/")), "/")
+	if cleaned == "" || cleaned == "." {
+		return "", errors.New("the attachment has no name, please provide a file name")
+	}
+	return cleaned, nil
+}
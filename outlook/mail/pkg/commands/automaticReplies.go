@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/graph"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/printers"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+func GetAutomaticReplies(ctx context.Context) error {
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	setting, err := graph.GetAutomaticReplies(ctx, c)
+	if err != nil {
+		return fmt.Errorf("failed to get automatic replies: %w", err)
+	}
+
+	settingStr, err := printers.AutomaticRepliesToString(setting)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(settingStr)
+	return nil
+}
+
+// SetAutomaticReplies turns the automatic replies on, off or on for a schedule window. Messages and the external
+// audience are only changed if they are set.
+func SetAutomaticReplies(ctx context.Context, status, start, end, internalMessage, externalMessage, externalAudience string) error {
+	parsedStatus, err := models.ParseAutomaticRepliesStatus(strings.TrimSpace(status))
+	if err != nil || parsedStatus == nil {
+		return fmt.Errorf("invalid status %q, must be disabled, alwaysEnabled or scheduled", status)
+	}
+
+	info := graph.AutomaticRepliesInfo{
+		Status: *parsedStatus.(*models.AutomaticRepliesStatus),
+	}
+
+	if info.Status == models.SCHEDULED_AUTOMATICREPLIESSTATUS {
+		if start == "" || end == "" {
+			return fmt.Errorf("start and end are required for scheduled automatic replies")
+		}
+		startTime, err := time.Parse(time.RFC3339, start)
+		if err != nil {
+			return fmt.Errorf("failed to parse start time: %w", err)
+		}
+		endTime, err := time.Parse(time.RFC3339, end)
+		if err != nil {
+			return fmt.Errorf("failed to parse end time: %w", err)
+		}
+		if !endTime.After(startTime) {
+			return fmt.Errorf("end time must be after start time")
+		}
+		info.Start, info.End = &startTime, &endTime
+	}
+
+	if internalMessage != "" {
+		info.InternalMessage = &internalMessage
+	}
+	if externalMessage != "" {
+		info.ExternalMessage = &externalMessage
+	}
+	if externalAudience != "" {
+		parsedAudience, err := models.ParseExternalAudienceScope(strings.TrimSpace(externalAudience))
+		if err != nil || parsedAudience == nil {
+			return fmt.Errorf("invalid external audience %q, must be none, contactsOnly or all", externalAudience)
+		}
+		info.ExternalAudience = parsedAudience.(*models.ExternalAudienceScope)
+	}
+
+	c, err := client.NewClient(global.SettingsScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	setting, err := graph.SetAutomaticReplies(ctx, c, info)
+	if err != nil {
+		return fmt.Errorf("failed to set automatic replies: %w", err)
+	}
+
+	settingStr, err := printers.AutomaticRepliesToString(setting)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Automatic replies updated successfully.")
+	fmt.Print(settingStr)
+	return nil
+}
@@ -0,0 +1,148 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gptscript-ai/tools/outlook/common/id"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/graph"
+)
+
+// BulkMoveMessages moves the comma-separated messages to the destination folder and reports the result of each
+// message.
+func BulkMoveMessages(ctx context.Context, mailbox, messageIDs, destinationFolderID string) error {
+	trueDestinationFolderID, err := id.GetOutlookID(ctx, destinationFolderID)
+	if err != nil {
+		return fmt.Errorf("failed to get destination folder ID: %w", err)
+	}
+
+	return bulkMove(ctx, mailbox, messageIDs, trueDestinationFolderID, "moved")
+}
+
+// BulkDeleteMessages moves the comma-separated messages to Deleted Items and reports the result of each message.
+func BulkDeleteMessages(ctx context.Context, mailbox, messageIDs string) error {
+	return bulkMove(ctx, mailbox, messageIDs, "deleteditems", "deleted")
+}
+
+// BulkArchiveMessages moves the comma-separated messages to the Archive folder and reports the result of each message.
+func BulkArchiveMessages(ctx context.Context, mailbox, messageIDs string) error {
+	return bulkMove(ctx, mailbox, messageIDs, graph.ArchiveFolder, "archived")
+}
+
+// BulkMarkMessagesRead marks the comma-separated messages as read or unread and reports the result of each message.
+func BulkMarkMessagesRead(ctx context.Context, mailbox, messageIDs string, read bool) error {
+	ids, trueMessageIDs, friendlyIDs, failed, err := bulkMessageIDs(ctx, messageIDs)
+	if err != nil {
+		return err
+	}
+
+	c, err := client.NewClient(global.AllScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	errs, err := graph.MarkMessagesRead(ctx, c, mailbox, trueMessageIDs, read)
+	if err != nil {
+		return fmt.Errorf("failed to mark messages: %w", err)
+	}
+
+	action := "marked as read"
+	if !read {
+		action = "marked as unread"
+	}
+	for i, err := range errs {
+		if err != nil {
+			fmt.Printf("%s: failed: %v\n", friendlyIDs[trueMessageIDs[i]], err)
+			failed++
+			continue
+		}
+		fmt.Printf("%s: %s\n", friendlyIDs[trueMessageIDs[i]], action)
+	}
+
+	fmt.Printf("%d of %d messages %s successfully\n", len(ids)-failed, len(ids), action)
+	return nil
+}
+
+// bulkMessageIDs splits the comma-separated message IDs and translates them to Outlook IDs. Messages with unknown IDs
+// are reported as failed, instead of failing the whole operation. It returns the given IDs, the Outlook IDs of the
+// known messages, the given ID for each Outlook ID, and the number of unknown messages.
+func bulkMessageIDs(ctx context.Context, messageIDs string) ([]string, []string, map[string]string, int, error) {
+	var ids []string
+	for _, messageID := range strings.Split(messageIDs, ",") {
+		if messageID = strings.TrimSpace(messageID); messageID != "" {
+			ids = append(ids, messageID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil, nil, 0, fmt.Errorf("no message IDs specified")
+	}
+
+	found, missing, err := id.LookupOutlookIDs(ctx, ids)
+	if err != nil {
+		return nil, nil, nil, 0, fmt.Errorf("failed to get message IDs: %w", err)
+	}
+	for _, messageID := range missing {
+		fmt.Printf("%s: failed: Outlook ID not found\n", messageID)
+	}
+
+	var (
+		trueMessageIDs []string
+		friendlyIDs    = map[string]string{}
+	)
+	for _, messageID := range ids {
+		trueMessageID, ok := found[messageID]
+		if !ok {
+			continue
+		}
+		trueMessageIDs = append(trueMessageIDs, trueMessageID)
+		friendlyIDs[trueMessageID] = messageID
+	}
+	return ids, trueMessageIDs, friendlyIDs, len(missing), nil
+}
+
+func bulkMove(ctx context.Context, mailbox, messageIDs, destinationFolderID, action string) error {
+	ids, trueMessageIDs, friendlyIDs, failed, err := bulkMessageIDs(ctx, messageIDs)
+	if err != nil {
+		return err
+	}
+
+	c, err := client.NewClient(global.AllScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	results, err := graph.MoveMessages(ctx, c, mailbox, trueMessageIDs, destinationFolderID)
+	if err != nil {
+		return fmt.Errorf("failed to move messages: %w", err)
+	}
+
+	var newMessageIDs []string
+	for _, result := range results {
+		if result.Err == nil && result.NewMessageID != "" {
+			newMessageIDs = append(newMessageIDs, result.NewMessageID)
+		}
+	}
+	translatedNewMessageIDs, err := id.SetOutlookIDs(ctx, newMessageIDs)
+	if err != nil {
+		return fmt.Errorf("failed to save new message IDs: %w", err)
+	}
+
+	for _, result := range results {
+		messageID := friendlyIDs[result.MessageID]
+		switch {
+		case result.Err != nil:
+			fmt.Printf("%s: failed: %v\n", messageID, result.Err)
+			failed++
+		case action != "deleted":
+			fmt.Printf("%s: %s, new message ID: %s\n", messageID, action, translatedNewMessageIDs[result.NewMessageID])
+		default:
+			fmt.Printf("%s: %s\n", messageID, action)
+		}
+	}
+
+	fmt.Printf("%d of %d messages %s successfully\n", len(ids)-failed, len(ids), action)
+	return nil
+}
@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gptscript-ai/tools/outlook/common/id"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/graph"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/util"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+func ListCategories(ctx context.Context, mailbox string) error {
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	categories, err := graph.ListCategories(ctx, c, mailbox)
+	if err != nil {
+		return fmt.Errorf("failed to list categories: %w", err)
+	}
+
+	if len(categories) == 0 {
+		fmt.Println("No categories found")
+		return nil
+	}
+
+	for _, category := range categories {
+		fmt.Printf("%s (color: %s)\n", util.Deref(category.GetDisplayName()), categoryColor(category.GetColor()))
+	}
+	return nil
+}
+
+// UpdateMessageCategories applies categories to a message or removes them from it. Only categories of the
+// mailbox can be applied, so that messages are labelled consistently.
+func UpdateMessageCategories(ctx context.Context, mailbox, messageID string, add bool, categories string) error {
+	var names []string
+	for _, name := range strings.Split(categories, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("no categories specified")
+	}
+
+	trueMessageID, err := id.GetOutlookID(ctx, messageID)
+	if err != nil {
+		return fmt.Errorf("failed to get message ID: %w", err)
+	}
+
+	c, err := client.NewClient(global.AllScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	if add {
+		masterCategories, err := graph.ListCategories(ctx, c, mailbox)
+		if err != nil {
+			return fmt.Errorf("failed to list categories: %w", err)
+		}
+		available := graph.CategoryNames(masterCategories)
+
+		for i, name := range names {
+			idx := slices.IndexFunc(available, func(category string) bool {
+				return strings.EqualFold(category, name)
+			})
+			if idx < 0 {
+				return fmt.Errorf("category %q doesn't exist, available categories: %s", name, categoriesToString(available))
+			}
+			names[i] = available[idx]
+		}
+	}
+
+	updated, err := graph.UpdateMessageCategories(ctx, c, mailbox, trueMessageID, add, names...)
+	if err != nil {
+		return fmt.Errorf("failed to update message categories: %w", err)
+	}
+
+	fmt.Printf("Message categories updated successfully. The message now has the categories: %s\n", categoriesToString(updated))
+	return nil
+}
+
+// categoryColors are the colors of the presets, as shown by Outlook
+var categoryColors = []string{
+	"red", "orange", "brown", "yellow", "green", "teal", "olive", "blue", "purple", "cranberry", "steel", "dark steel",
+	"gray", "dark gray", "black", "dark red", "dark orange", "dark brown", "dark yellow", "dark green", "dark teal",
+	"dark olive", "dark blue", "dark purple", "dark cranberry",
+}
+
+func categoryColor(color *models.CategoryColor) string {
+	if color == nil || *color < models.PRESET0_CATEGORYCOLOR || int(*color-models.PRESET0_CATEGORYCOLOR) >= len(categoryColors) {
+		return "none"
+	}
+	return categoryColors[*color-models.PRESET0_CATEGORYCOLOR]
+}
+
+func categoriesToString(categories []string) string {
+	if len(categories) == 0 {
+		return "(none)"
+	}
+	return strings.Join(categories, ", ")
+}
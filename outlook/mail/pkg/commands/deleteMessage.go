@@ -10,7 +10,7 @@ import (
 	"github.com/gptscript-ai/tools/outlook/mail/pkg/graph"
 )
 
-func DeleteMessage(ctx context.Context, messageID string) error {
+func DeleteMessage(ctx context.Context, mailbox, messageID string) error {
 	trueMessageID, err := id.GetOutlookID(ctx, messageID)
 	if err != nil {
 		return fmt.Errorf("failed to get message ID: %w", err)
@@ -21,7 +21,7 @@ func DeleteMessage(ctx context.Context, messageID string) error {
 		return fmt.Errorf("failed to create client: %w", err)
 	}
 
-	if err := graph.DeleteMessage(ctx, c, trueMessageID); err != nil {
+	if err := graph.DeleteMessage(ctx, c, mailbox, trueMessageID); err != nil {
 		return fmt.Errorf("failed to delete message: %w", err)
 	}
 
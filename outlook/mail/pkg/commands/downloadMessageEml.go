@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/outlook/common/id"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/graph"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/util"
+)
+
+// DownloadMessageEml saves the raw MIME content of a message to the files of the workspace as an .eml file, and
+// prints the path of the file. The file name defaults to the subject of the message.
+func DownloadMessageEml(ctx context.Context, mailbox, messageID, fileName string) error {
+	trueMessageID, err := id.GetOutlookID(ctx, messageID)
+	if err != nil {
+		return fmt.Errorf("failed to get message ID: %w", err)
+	}
+
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	if fileName == "" {
+		message, err := graph.GetMessageDetails(ctx, c, mailbox, trueMessageID)
+		if err != nil {
+			return fmt.Errorf("failed to get message details: %w", err)
+		}
+		fileName = emlFileName(util.Deref(message.GetSubject()), messageID)
+	}
+	if !strings.HasSuffix(strings.ToLower(fileName), ".eml") {
+		fileName += ".eml"
+	}
+	fileName, err = cleanFileName(fileName)
+	if err != nil {
+		return err
+	}
+
+	data, err := graph.GetMessageMIME(ctx, c, mailbox, trueMessageID)
+	if err != nil {
+		return fmt.Errorf("failed to download message: %w", err)
+	}
+
+	gptscriptClient, err := gptscript.NewGPTScript()
+	if err != nil {
+		return fmt.Errorf("failed to create GPTScript client: %w", err)
+	}
+
+	if err := gptscriptClient.WriteFileInWorkspace(ctx, path.Join("files", fileName), data); err != nil {
+		return fmt.Errorf("failed to save message to workspace: %w", err)
+	}
+
+	fmt.Printf("Message saved to the workspace as %s (%d bytes)\n", fileName, len(data))
+	return nil
+}
+
+// emlFileName turns the subject of a message into a file name, replacing the characters that aren't allowed in file
+// names on common file systems. Messages without a subject are named after their ID.
+func emlFileName(subject, messageID string) string {
+	name := strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		if r < ' ' {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(subject))
+	if name = strings.Trim(name, ". "); name == "" {
+		return "message-" + messageID
+	}
+	return name
+}
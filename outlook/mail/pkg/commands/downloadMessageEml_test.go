@@ -0,0 +1,19 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmlFileName(t *testing.T) {
+	for subject, want := range map[string]string{
+		"Quarterly report":        "Quarterly report",
+		"Re: Q3/Q4 <draft>?":      "Re_ Q3_Q4 _draft__",
+		"  tabs\tand\nnewlines  ": "tabsandnewlines",
+		"...":                     "message-msg-1",
+		"":                        "message-msg-1",
+	} {
+		assert.Equal(t, want, emlFileName(subject, "msg-1"), subject)
+	}
+}
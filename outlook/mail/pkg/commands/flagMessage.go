@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gptscript-ai/tools/outlook/common/id"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/graph"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// FlagMessage sets (flagged), completes (complete) or clears (clear) the follow-up flag of a message. The due date
+// is a date (2024-11-01, the end of that day in UTC) or a date and time in RFC 3339 format.
+func FlagMessage(ctx context.Context, mailbox, messageID, status, dueDate string) error {
+	var flagStatus models.FollowupFlagStatus
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "", "flagged", "flag":
+		flagStatus = models.FLAGGED_FOLLOWUPFLAGSTATUS
+	case "complete", "completed":
+		flagStatus = models.COMPLETE_FOLLOWUPFLAGSTATUS
+	case "clear", "notflagged", "none":
+		flagStatus = models.NOTFLAGGED_FOLLOWUPFLAGSTATUS
+	default:
+		return fmt.Errorf("invalid status %q, must be one of flagged, complete or clear", status)
+	}
+
+	var due *time.Time
+	if dueDate != "" {
+		if flagStatus != models.FLAGGED_FOLLOWUPFLAGSTATUS {
+			return fmt.Errorf("a due date can only be set when flagging a message")
+		}
+		t, err := time.Parse(time.RFC3339, dueDate)
+		if err != nil {
+			day, dateErr := time.Parse(time.DateOnly, dueDate)
+			if dateErr != nil {
+				return fmt.Errorf("invalid due date %q, expected a date (YYYY-MM-DD) or RFC 3339 date and time", dueDate)
+			}
+			t = day.Add(24*time.Hour - time.Second)
+		}
+		due = &t
+	}
+
+	trueMessageID, err := id.GetOutlookID(ctx, messageID)
+	if err != nil {
+		return fmt.Errorf("failed to get message ID: %w", err)
+	}
+
+	c, err := client.NewClient(global.AllScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	if _, err := graph.FlagMessage(ctx, c, mailbox, trueMessageID, flagStatus, due); err != nil {
+		return fmt.Errorf("failed to flag message: %w", err)
+	}
+
+	switch {
+	case flagStatus == models.COMPLETE_FOLLOWUPFLAGSTATUS:
+		fmt.Println("Message flag marked as complete")
+	case flagStatus == models.NOTFLAGGED_FOLLOWUPFLAGSTATUS:
+		fmt.Println("Message flag cleared")
+	case due != nil:
+		fmt.Printf("Message flagged for follow-up, due %s\n", due.UTC().Format(time.RFC3339))
+	default:
+		fmt.Println("Message flagged for follow-up")
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gptscript-ai/tools/outlook/common/id"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/graph"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/printers"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/util"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+func CreateMailFolder(ctx context.Context, mailbox, parentFolderID, name string) error {
+	if name = strings.TrimSpace(name); name == "" {
+		return fmt.Errorf("folder name is required")
+	}
+
+	var (
+		trueParentFolderID string
+		err                error
+	)
+	if parentFolderID != "" {
+		trueParentFolderID, err = id.GetOutlookID(ctx, parentFolderID)
+		if err != nil {
+			return fmt.Errorf("failed to get parent folder ID: %w", err)
+		}
+	}
+
+	c, err := client.NewClient(global.AllScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	folder, err := graph.CreateMailFolder(ctx, c, mailbox, trueParentFolderID, name)
+	if err != nil {
+		return fmt.Errorf("failed to create mail folder: %w", err)
+	}
+
+	return printFolder(ctx, "Mail folder created successfully.", folder)
+}
+
+func RenameMailFolder(ctx context.Context, mailbox, folderID, name string) error {
+	if name = strings.TrimSpace(name); name == "" {
+		return fmt.Errorf("folder name is required")
+	}
+
+	trueFolderID, err := id.GetOutlookID(ctx, folderID)
+	if err != nil {
+		return fmt.Errorf("failed to get folder ID: %w", err)
+	}
+
+	c, err := client.NewClient(global.AllScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	folder, err := graph.RenameMailFolder(ctx, c, mailbox, trueFolderID, name)
+	if err != nil {
+		return fmt.Errorf("failed to rename mail folder: %w", err)
+	}
+
+	return printFolder(ctx, "Mail folder renamed successfully.", folder)
+}
+
+func DeleteMailFolder(ctx context.Context, mailbox, folderID string) error {
+	trueFolderID, err := id.GetOutlookID(ctx, folderID)
+	if err != nil {
+		return fmt.Errorf("failed to get folder ID: %w", err)
+	}
+
+	c, err := client.NewClient(global.AllScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	if err := graph.DeleteMailFolder(ctx, c, mailbox, trueFolderID); err != nil {
+		return fmt.Errorf("failed to delete mail folder: %w", err)
+	}
+
+	fmt.Println("Mail folder deleted successfully")
+	return nil
+}
+
+func MoveMailFolder(ctx context.Context, mailbox, folderID, destinationFolderID string) error {
+	trueFolderID, err := id.GetOutlookID(ctx, folderID)
+	if err != nil {
+		return fmt.Errorf("failed to get folder ID: %w", err)
+	}
+
+	trueDestinationFolderID, err := id.GetOutlookID(ctx, destinationFolderID)
+	if err != nil {
+		return fmt.Errorf("failed to get destination folder ID: %w", err)
+	}
+
+	c, err := client.NewClient(global.AllScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	folder, err := graph.MoveMailFolder(ctx, c, mailbox, trueFolderID, trueDestinationFolderID)
+	if err != nil {
+		return fmt.Errorf("failed to move mail folder: %w", err)
+	}
+
+	return printFolder(ctx, "Mail folder moved successfully.", folder)
+}
+
+// printFolder prints the folder with friendly IDs
+func printFolder(ctx context.Context, message string, folder models.MailFolderable) error {
+	folderID, err := id.SetOutlookID(ctx, util.Deref(folder.GetId()))
+	if err != nil {
+		return fmt.Errorf("failed to set folder ID: %w", err)
+	}
+	folder.SetId(util.Ptr(folderID))
+
+	if folder.GetParentFolderId() != nil {
+		parentFolderID, err := id.SetOutlookID(ctx, util.Deref(folder.GetParentFolderId()))
+		if err != nil {
+			return fmt.Errorf("failed to set parent folder ID: %w", err)
+		}
+		folder.SetParentFolderId(util.Ptr(parentFolderID))
+	}
+
+	folderStr, err := printers.MailFolderToString(folder)
+	if err != nil {
+		return fmt.Errorf("failed to convert mail folder to string: %w", err)
+	}
+
+	fmt.Println(message)
+	fmt.Print(folderStr)
+	return nil
+}
@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gptscript-ai/tools/outlook/common/id"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/graph"
+)
+
+func ForwardMessage(ctx context.Context, mailbox, messageID, recipients, comment string) error {
+	var addresses []string
+	for _, address := range strings.Split(recipients, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			addresses = append(addresses, address)
+		}
+	}
+	if len(addresses) == 0 {
+		return fmt.Errorf("at least one recipient is required")
+	}
+
+	trueMessageID, err := id.GetOutlookID(ctx, messageID)
+	if err != nil {
+		return fmt.Errorf("failed to get message ID: %w", err)
+	}
+
+	c, err := client.NewClient(global.AllScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	if err := graph.ForwardMessage(ctx, c, mailbox, trueMessageID, addresses, comment); err != nil {
+		return fmt.Errorf("failed to forward message: %w", err)
+	}
+
+	fmt.Printf("Message forwarded successfully to %s\n", strings.Join(addresses, ", "))
+	return nil
+}
@@ -12,7 +12,7 @@ import (
 	"github.com/gptscript-ai/tools/outlook/mail/pkg/util"
 )
 
-func GetMessageDetails(ctx context.Context, messageID string) error {
+func GetMessageDetails(ctx context.Context, mailbox, messageID string) error {
 	trueMessageID, err := id.GetOutlookID(ctx, messageID)
 	if err != nil {
 		return fmt.Errorf("failed to get outlook ID: %w", err)
@@ -23,7 +23,7 @@ func GetMessageDetails(ctx context.Context, messageID string) error {
 		return fmt.Errorf("failed to create client: %w", err)
 	}
 
-	result, err := graph.GetMessageDetails(ctx, c, trueMessageID)
+	result, err := graph.GetMessageDetails(ctx, c, mailbox, trueMessageID)
 	if err != nil {
 		return fmt.Errorf("failed to get message details: %w", err)
 	}
@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gptscript-ai/tools/outlook/common/id"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/graph"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/printers"
+)
+
+// GetMessageHeaders prints the SPF, DKIM and DMARC results, the Received chain and all internet headers of a message.
+func GetMessageHeaders(ctx context.Context, mailbox, messageID string) error {
+	trueMessageID, err := id.GetOutlookID(ctx, messageID)
+	if err != nil {
+		return fmt.Errorf("failed to get outlook ID: %w", err)
+	}
+
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	headers, err := graph.GetMessageHeaders(ctx, c, mailbox, trueMessageID)
+	if err != nil {
+		return err
+	}
+	if len(headers) == 0 {
+		// Graph only has the headers of messages that were received, not of drafts or sent messages
+		fmt.Println("The message has no internet message headers")
+		return nil
+	}
+
+	fmt.Print(printers.HeadersToString(headers))
+	return nil
+}
@@ -0,0 +1,150 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/common/guard"
+	"github.com/gptscript-ai/tools/outlook/common/id"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/graph"
+	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
+)
+
+// deltaStateLocation is the workspace file with the delta links of the folders, so that each call only returns the
+// changes since the previous call
+const deltaStateLocation = "outlookmaildelta.json"
+
+type deltaState struct {
+	DeltaLinks map[string]string `json:"deltaLinks"`
+}
+
+// GetNewMessages prints the messages of the folder that were added or changed since the previous call. The first call
+// (or a call with reset) returns the messages received since the given time, 1 day by default.
+func GetNewMessages(ctx context.Context, mailbox, folderID, since string, reset bool) error {
+	if folderID == "" {
+		folderID = "inbox"
+	}
+	trueFolderID, err := id.GetOutlookID(ctx, folderID)
+	if err != nil {
+		return fmt.Errorf("failed to get folder ID: %w", err)
+	}
+
+	if since == "" {
+		since = "1d"
+	}
+	start, err := parseSince(since, time.Now())
+	if err != nil {
+		return err
+	}
+	startTime, _ := time.Parse(time.RFC3339, start)
+
+	gptscriptClient, err := gptscript.NewGPTScript()
+	if err != nil {
+		return fmt.Errorf("failed to create GPTScript client: %w", err)
+	}
+
+	state, err := loadDeltaState(ctx, gptscriptClient)
+	if err != nil {
+		return err
+	}
+
+	key := strings.ToLower(strings.TrimSpace(mailbox)) + "|" + trueFolderID
+	deltaLink := state.DeltaLinks[key]
+	if reset {
+		deltaLink = ""
+	}
+
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	delta, err := graph.GetMessageDelta(ctx, c, mailbox, trueFolderID, deltaLink, startTime)
+	if err != nil && deltaLink != "" && isSyncStateExpired(err) {
+		// Outlook forgets delta links after a while, start over
+		deltaLink = ""
+		delta, err = graph.GetMessageDelta(ctx, c, mailbox, trueFolderID, "", startTime)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get new messages: %w", err)
+	}
+
+	if deltaLink == "" {
+		fmt.Printf("Started tracking new messages. Messages received since %s:\n", start)
+	}
+	if len(delta.RemovedIDs) > 0 {
+		fmt.Printf("%d messages were deleted or moved out of the folder since the last check.\n", len(delta.RemovedIDs))
+	}
+
+	if len(delta.Messages) == 0 {
+		fmt.Println("No new messages")
+	} else {
+		writer := guard.NewWriter(gptscriptClient, gptscript.DatasetOptions{
+			Name:        fmt.Sprintf("%s_outlook_new_mail", folderID),
+			Description: "New Outlook mail messages in folder " + folderID,
+		}, "messages")
+		if err := writeMessages(ctx, writer, delta.Messages); err != nil {
+			return err
+		}
+		if err := writer.Close(ctx); err != nil {
+			return err
+		}
+	}
+
+	// Only save the delta link once the messages are printed, so they aren't lost if printing fails
+	state.DeltaLinks[key] = delta.DeltaLink
+	return saveDeltaState(ctx, gptscriptClient, state)
+}
+
+func isSyncStateExpired(err error) bool {
+	var odataErr *odataerrors.ODataError
+	if !errors.As(err, &odataErr) {
+		return false
+	}
+	if odataErr.ResponseStatusCode == http.StatusGone {
+		return true
+	}
+	mainErr := odataErr.GetErrorEscaped()
+	return mainErr != nil && mainErr.GetCode() != nil && strings.HasPrefix(strings.ToLower(*mainErr.GetCode()), "syncstate")
+}
+
+func loadDeltaState(ctx context.Context, gptscriptClient *gptscript.GPTScript) (deltaState, error) {
+	state := deltaState{DeltaLinks: map[string]string{}}
+
+	data, err := gptscriptClient.ReadFileInWorkspace(ctx, deltaStateLocation)
+	if err != nil {
+		var notFoundErr *gptscript.NotFoundInWorkspaceError
+		if errors.As(err, &notFoundErr) {
+			return state, nil
+		}
+		return state, fmt.Errorf("failed to read the delta state file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("failed to unmarshal the delta state: %w", err)
+	}
+	if state.DeltaLinks == nil {
+		state.DeltaLinks = map[string]string{}
+	}
+	return state, nil
+}
+
+func saveDeltaState(ctx context.Context, gptscriptClient *gptscript.GPTScript, state deltaState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the delta state: %w", err)
+	}
+
+	if err := gptscriptClient.WriteFileInWorkspace(ctx, deltaStateLocation, data); err != nil {
+		return fmt.Errorf("failed to write the delta state file: %w", err)
+	}
+	return nil
+}
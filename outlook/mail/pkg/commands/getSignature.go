@@ -0,0 +1,30 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/graph"
+)
+
+// GetSignature prints the signature detected from the messages the user sent recently.
+func GetSignature(ctx context.Context, mailbox string) error {
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	signature, err := graph.GetSignature(ctx, c, mailbox)
+	if err != nil {
+		return err
+	}
+	if signature == "" {
+		fmt.Println("No signature found in the recently sent messages")
+		return nil
+	}
+
+	fmt.Printf("Signature:\n%s\n", signature)
+	return nil
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/common/guard"
+	"github.com/gptscript-ai/tools/common/locale"
+	"github.com/gptscript-ai/tools/outlook/common/id"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/graph"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/thread"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/util"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// GetThread prints all messages of the conversation (thread) a message belongs to, oldest first and without the
+// quoted text of earlier messages.
+func GetThread(ctx context.Context, mailbox, messageID string) error {
+	trueMessageID, err := id.GetOutlookID(ctx, messageID)
+	if err != nil {
+		return fmt.Errorf("failed to get outlook ID: %w", err)
+	}
+
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	msg, err := graph.GetMessageDetails(ctx, c, mailbox, trueMessageID)
+	if err != nil {
+		return fmt.Errorf("failed to get message details: %w", err)
+	}
+
+	messages, err := graph.ListConversationMessages(ctx, c, mailbox, util.Deref(msg.GetConversationId()))
+	if err != nil {
+		return err
+	}
+
+	messageIDs := util.Map(messages, func(message models.Messageable) string {
+		return util.Deref(message.GetId())
+	})
+	translatedMessageIDs, err := id.SetOutlookIDs(ctx, messageIDs)
+	if err != nil {
+		return fmt.Errorf("failed to translate message IDs: %w", err)
+	}
+	for _, message := range messages {
+		message.SetId(util.Ptr(translatedMessageIDs[util.Deref(message.GetId())]))
+	}
+
+	loc := locale.FromEnv()
+	transcript := thread.MessagesWithIDs(messages, loc)
+	if len(transcript) == 0 {
+		fmt.Println(loc.T("The thread does not contain any messages"))
+		return nil
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Subject"), util.Deref(msg.GetSubject())))
+	output.WriteString(fmt.Sprintf("%s: %d\n\n", loc.T("Messages in thread"), len(messages)))
+	for _, message := range transcript {
+		output.WriteString(message.Text)
+	}
+
+	// Long threads are stored with one element per message
+	return guard.Print(ctx, nil, output.String(), func() ([]gptscript.DatasetElement, error) {
+		elements := make([]gptscript.DatasetElement, 0, len(transcript))
+		for _, message := range transcript {
+			elements = append(elements, gptscript.DatasetElement{
+				DatasetElementMeta: gptscript.DatasetElementMeta{
+					Name:        message.ID,
+					Description: util.Deref(msg.GetSubject()),
+				},
+				Contents: message.Text,
+			})
+		}
+		return elements, nil
+	}, gptscript.DatasetOptions{
+		Name:        fmt.Sprintf("%s_outlook_mail_thread", messageID),
+		Description: "Messages of the Outlook mail thread " + util.Deref(msg.GetSubject()),
+	}, "messages")
+}
@@ -0,0 +1,146 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gptscript-ai/tools/outlook/common/id"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/graph"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/util"
+)
+
+func ArchiveMessage(ctx context.Context, mailbox, messageID string) error {
+	trueMessageID, err := id.GetOutlookID(ctx, messageID)
+	if err != nil {
+		return fmt.Errorf("failed to get message ID: %w", err)
+	}
+
+	c, err := client.NewClient(global.AllScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	message, err := graph.ArchiveMessage(ctx, c, mailbox, trueMessageID)
+	if err != nil {
+		return fmt.Errorf("failed to archive message: %w", err)
+	}
+
+	// Save the new message ID
+	newMessageID, err := id.SetOutlookID(ctx, util.Deref(message.GetId()))
+	if err != nil {
+		return fmt.Errorf("failed to save new message ID: %w", err)
+	}
+
+	fmt.Printf("Message archived successfully. New message ID: %s\n", newMessageID)
+	return nil
+}
+
+func ReportJunk(ctx context.Context, mailbox, messageID string, junk bool) error {
+	trueMessageID, err := id.GetOutlookID(ctx, messageID)
+	if err != nil {
+		return fmt.Errorf("failed to get message ID: %w", err)
+	}
+
+	c, err := client.NewClient(global.AllScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	message, err := graph.ReportJunk(ctx, c, mailbox, trueMessageID, junk)
+	if err != nil {
+		return fmt.Errorf("failed to report message: %w", err)
+	}
+
+	newMessageID, err := id.SetOutlookID(ctx, util.Deref(message.GetId()))
+	if err != nil {
+		return fmt.Errorf("failed to save new message ID: %w", err)
+	}
+
+	if junk {
+		fmt.Printf("Message reported as junk and moved to the Junk Email folder. New message ID: %s\n", newMessageID)
+	} else {
+		fmt.Printf("Message reported as not junk and moved to the Inbox. New message ID: %s\n", newMessageID)
+	}
+	return nil
+}
+
+func ReportPhishing(ctx context.Context, mailbox, messageID string) error {
+	trueMessageID, err := id.GetOutlookID(ctx, messageID)
+	if err != nil {
+		return fmt.Errorf("failed to get message ID: %w", err)
+	}
+
+	c, err := client.NewClient(global.SecurityScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	message, err := graph.ReportPhishing(ctx, c, mailbox, trueMessageID)
+	if err != nil {
+		return fmt.Errorf("failed to report message: %w", err)
+	}
+
+	newMessageID, err := id.SetOutlookID(ctx, util.Deref(message.GetId()))
+	if err != nil {
+		return fmt.Errorf("failed to save new message ID: %w", err)
+	}
+
+	fmt.Printf("Message reported as phishing and moved to the Junk Email folder. New message ID: %s\n", newMessageID)
+	return nil
+}
+
+var senderListNames = map[graph.SenderList]string{
+	graph.BlockedSenders: "Blocked",
+	graph.SafeSenders:    "Safe",
+}
+
+func ListSenderLists(ctx context.Context, mailbox string) error {
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	for _, list := range []graph.SenderList{graph.BlockedSenders, graph.SafeSenders} {
+		senders, err := graph.GetSenderList(ctx, c, mailbox, list)
+		if err != nil {
+			return fmt.Errorf("failed to get %s senders: %w", list, err)
+		}
+		fmt.Printf("%s senders: %s\n", senderListNames[list], sendersToString(senders))
+	}
+	return nil
+}
+
+func UpdateSenderList(ctx context.Context, mailbox string, list graph.SenderList, add bool, addresses string) error {
+	var senders []string
+	for _, address := range strings.Split(addresses, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			senders = append(senders, address)
+		}
+	}
+	if len(senders) == 0 {
+		return fmt.Errorf("no email addresses specified")
+	}
+
+	c, err := client.NewClient(global.SettingsScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	updated, err := graph.UpdateSenderList(ctx, c, mailbox, list, add, senders...)
+	if err != nil {
+		return fmt.Errorf("failed to update %s senders: %w", list, err)
+	}
+
+	fmt.Printf("%s senders list updated successfully. It now contains: %s\n", senderListNames[list], sendersToString(updated))
+	return nil
+}
+
+func sendersToString(senders []string) string {
+	if len(senders) == 0 {
+		return "(none)"
+	}
+	return strings.Join(senders, ", ")
+}
@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/common/guard"
+	"github.com/gptscript-ai/tools/outlook/common/id"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/graph"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/printers"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/util"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// ListDrafts lists the drafts of the mailbox, most recently modified first, so a draft can be picked up again to
+// update or send it.
+func ListDrafts(ctx context.Context, mailbox, limit string) error {
+	var (
+		limitInt int = 100
+		err      error
+	)
+	if limit != "" {
+		limitInt, err = strconv.Atoi(limit)
+		if err != nil {
+			return fmt.Errorf("failed to parse limit: %w", err)
+		}
+		if limitInt < 0 {
+			return fmt.Errorf("limit must be 0 (no limit) or a positive integer")
+		}
+	}
+
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	gptscriptClient, err := gptscript.NewGPTScript()
+	if err != nil {
+		return fmt.Errorf("failed to create GPTScript client: %w", err)
+	}
+
+	writer := guard.NewWriter(gptscriptClient, gptscript.DatasetOptions{
+		Name:        "outlook_mail_drafts",
+		Description: "Outlook mail drafts",
+	}, "drafts")
+
+	if err := writeInBatches(ctx, func(yield func(models.Messageable) bool) error {
+		if err := graph.ListDrafts(ctx, c, mailbox, limitInt, yield); err != nil {
+			return fmt.Errorf("failed to list drafts: %w", err)
+		}
+		return nil
+	}, func(ctx context.Context, drafts []models.Messageable) error {
+		return writeDrafts(ctx, writer, drafts)
+	}); err != nil {
+		return err
+	}
+
+	return writer.Close(ctx)
+}
+
+// writeDrafts translates the IDs of the drafts to friendly IDs and adds the drafts to the output
+func writeDrafts(ctx context.Context, writer *guard.Writer, drafts []models.Messageable) error {
+	if len(drafts) == 0 {
+		return nil
+	}
+
+	draftIDs := util.Map(drafts, func(draft models.Messageable) string {
+		return util.Deref(draft.GetId())
+	})
+	translatedDraftIDs, err := id.SetOutlookIDs(ctx, draftIDs)
+	if err != nil {
+		return fmt.Errorf("failed to translate draft IDs: %w", err)
+	}
+
+	elements := make([]gptscript.DatasetElement, 0, len(drafts))
+	for _, draft := range drafts {
+		draft.SetId(util.Ptr(translatedDraftIDs[util.Deref(draft.GetId())]))
+		elements = append(elements, gptscript.DatasetElement{
+			DatasetElementMeta: gptscript.DatasetElementMeta{
+				Name:        util.Deref(draft.GetId()),
+				Description: util.Deref(draft.GetSubject()),
+			},
+			Contents: printers.DraftToString(draft),
+		})
+	}
+
+	return writer.Add(ctx, elements...)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/common/guard"
 	"github.com/gptscript-ai/tools/outlook/common/id"
 	"github.com/gptscript-ai/tools/outlook/mail/pkg/client"
 	"github.com/gptscript-ai/tools/outlook/mail/pkg/global"
@@ -14,13 +15,28 @@ import (
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
 )
 
-func ListMailFolders(ctx context.Context) error {
+// ListMailFolders lists the top-level folders, or the child folders of the parent folder if it is set.
+func ListMailFolders(ctx context.Context, mailbox, parentFolderID string) error {
+	var trueParentFolderID string
+	if parentFolderID != "" {
+		var err error
+		trueParentFolderID, err = id.GetOutlookID(ctx, parentFolderID)
+		if err != nil {
+			return fmt.Errorf("failed to get parent folder ID: %w", err)
+		}
+	}
+
 	c, err := client.NewClient(global.ReadOnlyScopes)
 	if err != nil {
 		return fmt.Errorf("failed to create client: %w", err)
 	}
 
-	result, err := graph.ListMailFolders(ctx, c)
+	var result []models.MailFolderable
+	if trueParentFolderID != "" {
+		result, err = graph.ListChildFolders(ctx, c, mailbox, trueParentFolderID)
+	} else {
+		result, err = graph.ListMailFolders(ctx, c, mailbox)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to list mail folders: %w", err)
 	}
@@ -72,13 +88,11 @@ func ListMailFolders(ctx context.Context) error {
 		})
 	}
 
-	datasetID, err := gptscriptClient.CreateDatasetWithElements(ctx, elements, gptscript.DatasetOptions{
-		Name: "outlook_mail_folders",
-	})
-	if err != nil {
-		return fmt.Errorf("failed to create dataset with elements: %w", err)
+	datasetName := "outlook_mail_folders"
+	if parentFolderID != "" {
+		datasetName = fmt.Sprintf("%s_outlook_mail_folders", parentFolderID)
 	}
-
-	fmt.Printf("Created dataset with ID %s with %d folders\n", datasetID, len(result))
-	return nil
+	return guard.PrintElements(ctx, gptscriptClient, elements, gptscript.DatasetOptions{
+		Name: datasetName,
+	}, "folders")
 }
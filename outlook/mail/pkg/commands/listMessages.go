@@ -4,9 +4,13 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/common/guard"
 	"github.com/gptscript-ai/tools/outlook/common/id"
+	"github.com/gptscript-ai/tools/outlook/common/pagination"
 	"github.com/gptscript-ai/tools/outlook/mail/pkg/client"
 	"github.com/gptscript-ai/tools/outlook/mail/pkg/global"
 	"github.com/gptscript-ai/tools/outlook/mail/pkg/graph"
@@ -15,10 +19,11 @@ import (
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
 )
 
-func ListMessages(ctx context.Context, folderID, start, end, limit string) error {
+// ListMessages lists the messages of the folder, newest first, with the properties of the fields, see
+// graph.MessageFields. The messages are translated and written in batches of one page, so large listings that go to
+// a dataset don't have to be kept in memory.
+func ListMessages(ctx context.Context, mailbox, folderID, start, end, since, classification, limit, fields string) error {
 	var (
-		// TODO: Change the default to a value < 1 when we have pagination implemented to trigger
-		// listing all messages.
 		limitInt int = 100
 		err      error
 	)
@@ -27,11 +32,32 @@ func ListMessages(ctx context.Context, folderID, start, end, limit string) error
 		if err != nil {
 			return fmt.Errorf("failed to parse limit: %w", err)
 		}
-		if limitInt < 1 {
-			return fmt.Errorf("limit must be a positive integer")
+		if limitInt < 0 {
+			return fmt.Errorf("limit must be 0 (no limit) or a positive integer")
 		}
 	}
 
+	if since != "" {
+		if start != "" {
+			return fmt.Errorf("only one of start and since can be set")
+		}
+		start, err = parseSince(since, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+
+	parsedClassification, err := parseClassification(classification)
+	if err != nil {
+		return err
+	}
+
+	// The folder IDs are translated with the message IDs
+	selects, err := graph.MessageFields.Resolve(fields, "parentFolderId")
+	if err != nil {
+		return err
+	}
+
 	var trueFolderID string
 	if folderID != "" {
 		trueFolderID, err = id.GetOutlookID(ctx, folderID)
@@ -45,17 +71,61 @@ func ListMessages(ctx context.Context, folderID, start, end, limit string) error
 		return fmt.Errorf("failed to create client: %w", err)
 	}
 
-	messages, err := graph.ListMessages(ctx, c, trueFolderID, start, end, limitInt)
-	if err != nil {
-		return fmt.Errorf("failed to list mail: %w", err)
-	}
-
 	gptscriptClient, err := gptscript.NewGPTScript()
 	if err != nil {
 		return fmt.Errorf("failed to create GPTScript client: %w", err)
 	}
 
-	// Translate Outlook IDs to friendly IDs before we print.
+	writer := guard.NewWriter(gptscriptClient, gptscript.DatasetOptions{
+		Name:        fmt.Sprintf("%s_outlook_mail", folderID),
+		Description: "Outlook mail messages in folder " + folderID,
+	}, "messages")
+
+	if err := writeInBatches(ctx, func(yield func(models.Messageable) bool) error {
+		if err := graph.ListMessages(ctx, c, mailbox, trueFolderID, start, end, parsedClassification, limitInt, selects, yield); err != nil {
+			return fmt.Errorf("failed to list mail: %w", err)
+		}
+		return nil
+	}, func(ctx context.Context, messages []models.Messageable) error {
+		return writeMessages(ctx, writer, messages)
+	}); err != nil {
+		return err
+	}
+
+	return writer.Close(ctx)
+}
+
+// writeInBatches collects the items yielded by list and writes them in batches of one page, so large listings don't
+// have to be kept in memory. Listing stops at the first error of write.
+func writeInBatches[T any](ctx context.Context, list func(yield func(T) bool) error, write func(ctx context.Context, items []T) error) error {
+	var (
+		batch    []T
+		writeErr error
+	)
+	if err := list(func(item T) bool {
+		if batch = append(batch, item); len(batch) >= pagination.PageSize() {
+			writeErr = write(ctx, batch)
+			batch = batch[:0]
+		}
+		return writeErr == nil
+	}); err != nil {
+		return err
+	}
+	if writeErr != nil {
+		return writeErr
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+	return write(ctx, batch)
+}
+
+// writeMessages translates the IDs of the messages to friendly IDs and adds the messages to the output
+func writeMessages(ctx context.Context, writer *guard.Writer, messages []models.Messageable) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
 	messageIDs := util.Map(messages, func(message models.Messageable) string {
 		return util.Deref(message.GetId())
 	})
@@ -72,7 +142,7 @@ func ListMessages(ctx context.Context, folderID, start, end, limit string) error
 		return fmt.Errorf("failed to translate folder IDs: %w", err)
 	}
 
-	var elements []gptscript.DatasetElement
+	elements := make([]gptscript.DatasetElement, 0, len(messages))
 	for _, message := range messages {
 		message.SetId(util.Ptr(translatedMessageIDs[util.Deref(message.GetId())]))
 		message.SetParentFolderId(util.Ptr(translatedFolderIDs[util.Deref(message.GetParentFolderId())]))
@@ -90,14 +160,55 @@ func ListMessages(ctx context.Context, folderID, start, end, limit string) error
 		})
 	}
 
-	datasetID, err := gptscriptClient.CreateDatasetWithElements(ctx, elements, gptscript.DatasetOptions{
-		Name:        fmt.Sprintf("%s_outlook_mail", folderID),
-		Description: "Outlook mail messages in folder " + folderID,
-	})
-	if err != nil {
-		return fmt.Errorf("failed to create dataset with elements: %w", err)
+	return writer.Add(ctx, elements...)
+}
+
+// parseSince returns the start time of messages received since a time, given as a date (2024-11-01), a date and
+// time in RFC 3339 format, or a duration back from now (90m, 24h, 7d, 2w)
+func parseSince(since string, now time.Time) (string, error) {
+	since = strings.TrimSpace(since)
+	if t, err := time.Parse(time.RFC3339, since); err == nil {
+		return t.UTC().Format(time.RFC3339), nil
 	}
+	if t, err := time.ParseInLocation(time.DateOnly, since, now.Location()); err == nil {
+		return t.UTC().Format(time.RFC3339), nil
+	}
+
+	var unit time.Duration
+	switch {
+	case strings.HasSuffix(since, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(since, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+
+	var d time.Duration
+	if unit > 0 {
+		n, err := strconv.Atoi(since[:len(since)-1])
+		if err != nil {
+			return "", fmt.Errorf("invalid since %q: %w", since, err)
+		}
+		d = time.Duration(n) * unit
+	} else {
+		var err error
+		if d, err = time.ParseDuration(since); err != nil {
+			return "", fmt.Errorf("invalid since %q, expected a date or a duration like 24h or 7d", since)
+		}
+	}
+	if d <= 0 {
+		return "", fmt.Errorf("since must be a positive duration")
+	}
+	return now.Add(-d).UTC().Format(time.RFC3339), nil
+}
 
-	fmt.Printf("Created dataset with ID %s with %d messages\n", datasetID, len(messages))
-	return nil
+// parseClassification parses the Focused Inbox classification (focused or other), an empty string means no filter
+func parseClassification(classification string) (*models.InferenceClassificationType, error) {
+	if classification == "" {
+		return nil, nil
+	}
+	parsed, err := models.ParseInferenceClassificationType(strings.ToLower(strings.TrimSpace(classification)))
+	if err != nil || parsed == nil {
+		return nil, fmt.Errorf("invalid classification %q, must be focused or other", classification)
+	}
+	return parsed.(*models.InferenceClassificationType), nil
 }
@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gptscript-ai/tools/outlook/common/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 11, 15, 12, 0, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		since, want string
+	}{
+		{"2024-11-01", "2024-11-01T00:00:00Z"},
+		{"2024-11-01T08:30:00+01:00", "2024-11-01T07:30:00Z"},
+		{"90m", "2024-11-15T10:30:00Z"},
+		{"24h", "2024-11-14T12:00:00Z"},
+		{" 7d ", "2024-11-08T12:00:00Z"},
+		{"2w", "2024-11-01T12:00:00Z"},
+	} {
+		got, err := parseSince(tc.since, now)
+		require.NoError(t, err, tc.since)
+		assert.Equal(t, tc.want, got, tc.since)
+	}
+
+	for _, since := range []string{"yesterday", "xd", "0h", "-2d", ""} {
+		_, err := parseSince(since, now)
+		assert.Error(t, err, since)
+	}
+}
+
+func TestWriteInBatches(t *testing.T) {
+	ctx := context.Background()
+	list := func(n int) func(yield func(int) bool) error {
+		return func(yield func(int) bool) error {
+			for i := range n {
+				if !yield(i) {
+					return nil
+				}
+			}
+			return nil
+		}
+	}
+
+	t.Run("batches of one page", func(t *testing.T) {
+		var sizes []int
+		require.NoError(t, writeInBatches(ctx, list(2*pagination.PageSize()+1), func(_ context.Context, items []int) error {
+			sizes = append(sizes, len(items))
+			return nil
+		}))
+		assert.Equal(t, []int{pagination.PageSize(), pagination.PageSize(), 1}, sizes)
+	})
+
+	t.Run("nothing listed", func(t *testing.T) {
+		require.NoError(t, writeInBatches(ctx, list(0), func(context.Context, []int) error {
+			t.Fatal("nothing should be written")
+			return nil
+		}))
+	})
+
+	t.Run("write error stops listing", func(t *testing.T) {
+		var listed, writes int
+		err := writeInBatches(ctx, func(yield func(int) bool) error {
+			for i := range 3 * pagination.PageSize() {
+				listed++
+				if !yield(i) {
+					break
+				}
+			}
+			return nil
+		}, func(context.Context, []int) error {
+			writes++
+			return errors.New("write failed")
+		})
+		assert.EqualError(t, err, "write failed")
+		assert.Equal(t, pagination.PageSize(), listed)
+		assert.Equal(t, 1, writes)
+	})
+
+	t.Run("list error", func(t *testing.T) {
+		err := writeInBatches(ctx, func(yield func(int) bool) error {
+			yield(1)
+			return errors.New("list failed")
+		}, func(context.Context, []int) error {
+			t.Fatal("nothing should be written")
+			return nil
+		})
+		assert.EqualError(t, err, "list failed")
+	})
+}
@@ -11,7 +11,7 @@ import (
 	"github.com/gptscript-ai/tools/outlook/mail/pkg/util"
 )
 
-func MoveMessage(ctx context.Context, messageID, destinationFolderID string) error {
+func MoveMessage(ctx context.Context, mailbox, messageID, destinationFolderID string) error {
 	trueMessageID, err := id.GetOutlookID(ctx, messageID)
 	if err != nil {
 		return fmt.Errorf("failed to get message ID: %w", err)
@@ -27,7 +27,7 @@ func MoveMessage(ctx context.Context, messageID, destinationFolderID string) err
 		return fmt.Errorf("failed to create client: %w", err)
 	}
 
-	message, err := graph.MoveMessage(ctx, c, trueMessageID, trueDestinationFolderID)
+	message, err := graph.MoveMessage(ctx, c, mailbox, trueMessageID, trueDestinationFolderID)
 	if err != nil {
 		return fmt.Errorf("failed to move message: %w", err)
 	}
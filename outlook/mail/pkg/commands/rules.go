@@ -0,0 +1,167 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/common/guard"
+	"github.com/gptscript-ai/tools/outlook/common/id"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/graph"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/printers"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/util"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+func ListInboxRules(ctx context.Context, mailbox string) error {
+	c, err := client.NewClient(global.SettingsScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	rules, err := graph.ListRules(ctx, c, mailbox)
+	if err != nil {
+		return fmt.Errorf("failed to list inbox rules: %w", err)
+	}
+
+	if len(rules) == 0 {
+		fmt.Println("No inbox rules found")
+		return nil
+	}
+
+	elements := make([]gptscript.DatasetElement, 0, len(rules))
+	for _, rule := range rules {
+		if err := setRuleIDs(ctx, rule); err != nil {
+			return err
+		}
+		elements = append(elements, gptscript.DatasetElement{
+			DatasetElementMeta: gptscript.DatasetElementMeta{
+				Name:        util.Deref(rule.GetId()),
+				Description: util.Deref(rule.GetDisplayName()),
+			},
+			Contents: printers.RuleToString(rule),
+		})
+	}
+
+	return guard.PrintElements(ctx, nil, elements, gptscript.DatasetOptions{
+		Name:        "outlook_mail_inbox_rules",
+		Description: "Outlook inbox rules",
+	}, "rules")
+}
+
+func CreateInboxRule(ctx context.Context, mailbox string, info graph.RuleInfo) error {
+	if info.Name = strings.TrimSpace(info.Name); info.Name == "" {
+		return fmt.Errorf("rule name is required")
+	}
+	if len(info.FromAddresses) == 0 && len(info.SenderContains) == 0 && len(info.SubjectContains) == 0 && len(info.BodyContains) == 0 && !info.HasAttachments {
+		// A rule without conditions would apply to every incoming message
+		return fmt.Errorf("at least one condition is required")
+	}
+	if info.MoveToFolderID == "" && info.CopyToFolderID == "" && len(info.Categories) == 0 && !info.MarkAsRead && !info.Delete {
+		return fmt.Errorf("at least one action is required")
+	}
+	if info.Delete && (info.MoveToFolderID != "" || info.CopyToFolderID != "") {
+		return fmt.Errorf("a rule can't both delete messages and move or copy them to a folder")
+	}
+
+	var err error
+	if info.MoveToFolderID != "" {
+		info.MoveToFolderID, err = id.GetOutlookID(ctx, info.MoveToFolderID)
+		if err != nil {
+			return fmt.Errorf("failed to get move to folder ID: %w", err)
+		}
+	}
+	if info.CopyToFolderID != "" {
+		info.CopyToFolderID, err = id.GetOutlookID(ctx, info.CopyToFolderID)
+		if err != nil {
+			return fmt.Errorf("failed to get copy to folder ID: %w", err)
+		}
+	}
+
+	c, err := client.NewClient(global.SettingsScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	rule, err := graph.CreateRule(ctx, c, mailbox, info)
+	if err != nil {
+		return fmt.Errorf("failed to create inbox rule: %w", err)
+	}
+
+	fmt.Println("Inbox rule created successfully.")
+	return printRule(ctx, rule)
+}
+
+func DeleteInboxRule(ctx context.Context, mailbox, ruleID string) error {
+	trueRuleID, err := id.GetOutlookID(ctx, ruleID)
+	if err != nil {
+		return fmt.Errorf("failed to get rule ID: %w", err)
+	}
+
+	c, err := client.NewClient(global.SettingsScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	rules, err := graph.ListRules(ctx, c, mailbox)
+	if err != nil {
+		return fmt.Errorf("failed to list inbox rules: %w", err)
+	}
+	for _, rule := range rules {
+		if util.Deref(rule.GetId()) != trueRuleID {
+			continue
+		}
+		if graph.IsSenderListRule(rule, graph.BlockedSenders) || graph.IsSenderListRule(rule, graph.SafeSenders) {
+			return fmt.Errorf("the rule %q keeps the blocked or safe senders list, use the sender list tools to change it", util.Deref(rule.GetDisplayName()))
+		}
+	}
+
+	if err := graph.DeleteRule(ctx, c, mailbox, trueRuleID); err != nil {
+		return fmt.Errorf("failed to delete inbox rule: %w", err)
+	}
+
+	fmt.Println("Inbox rule deleted successfully")
+	return nil
+}
+
+// printRule prints the rule with friendly IDs for the rule and its folders
+func printRule(ctx context.Context, rule models.MessageRuleable) error {
+	if err := setRuleIDs(ctx, rule); err != nil {
+		return err
+	}
+	fmt.Print(printers.RuleToString(rule))
+	return nil
+}
+
+// setRuleIDs replaces the IDs of the rule and its folders with friendly IDs
+func setRuleIDs(ctx context.Context, rule models.MessageRuleable) error {
+	outlookIDs := []string{util.Deref(rule.GetId())}
+	actions := rule.GetActions()
+	if actions != nil {
+		if actions.GetMoveToFolder() != nil {
+			outlookIDs = append(outlookIDs, util.Deref(actions.GetMoveToFolder()))
+		}
+		if actions.GetCopyToFolder() != nil {
+			outlookIDs = append(outlookIDs, util.Deref(actions.GetCopyToFolder()))
+		}
+	}
+
+	ids, err := id.SetOutlookIDs(ctx, outlookIDs)
+	if err != nil {
+		return fmt.Errorf("failed to set rule IDs: %w", err)
+	}
+
+	rule.SetId(util.Ptr(ids[util.Deref(rule.GetId())]))
+	if actions != nil {
+		if actions.GetMoveToFolder() != nil {
+			actions.SetMoveToFolder(util.Ptr(ids[util.Deref(actions.GetMoveToFolder())]))
+		}
+		if actions.GetCopyToFolder() != nil {
+			actions.SetCopyToFolder(util.Ptr(ids[util.Deref(actions.GetCopyToFolder())]))
+		}
+	}
+	return nil
+}
@@ -6,6 +6,8 @@ import (
 	"strconv"
 
 	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/common/guard"
+	"github.com/gptscript-ai/tools/common/locale"
 	"github.com/gptscript-ai/tools/outlook/common/id"
 	"github.com/gptscript-ai/tools/outlook/mail/pkg/client"
 	"github.com/gptscript-ai/tools/outlook/mail/pkg/global"
@@ -15,7 +17,7 @@ import (
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
 )
 
-func SearchMessages(ctx context.Context, subject, fromAddress, fromName, folderID, start, end, limit string) error {
+func SearchMessages(ctx context.Context, mailbox, folderID string, query graph.SearchQuery, importance, classification, limit, fields string) error {
 	var (
 		limitInt = 10
 		err      error
@@ -25,6 +27,23 @@ func SearchMessages(ctx context.Context, subject, fromAddress, fromName, folderI
 		if err != nil {
 			return fmt.Errorf("failed to parse limit: %w", err)
 		}
+		if limitInt < 0 {
+			return fmt.Errorf("limit must be 0 (no limit) or a positive integer")
+		}
+	}
+
+	if importance != "" {
+		if query.Importance, err = graph.ParseImportance(importance); err != nil {
+			return err
+		}
+	}
+	if query.Classification, err = parseClassification(classification); err != nil {
+		return err
+	}
+	// The folder IDs are translated with the message IDs
+	selects, err := graph.MessageFields.Resolve(fields, "parentFolderId")
+	if err != nil {
+		return err
 	}
 
 	trueFolderID, err := id.GetOutlookID(ctx, folderID)
@@ -37,13 +56,13 @@ func SearchMessages(ctx context.Context, subject, fromAddress, fromName, folderI
 		return fmt.Errorf("failed to create client: %w", err)
 	}
 
-	messages, err := graph.SearchMessages(ctx, c, subject, fromAddress, fromName, trueFolderID, start, end, limitInt)
+	messages, err := graph.SearchMessages(ctx, c, mailbox, trueFolderID, query, limitInt, selects)
 	if err != nil {
 		return fmt.Errorf("failed to search messages: %w", err)
 	}
 
 	if len(messages) == 0 {
-		fmt.Println("no messages found")
+		fmt.Println(locale.FromEnv().T("no messages found"))
 		return nil
 	}
 
@@ -87,13 +106,7 @@ func SearchMessages(ctx context.Context, subject, fromAddress, fromName, folderI
 		})
 	}
 
-	datasetID, err := gptscriptClient.CreateDatasetWithElements(ctx, elements, gptscript.DatasetOptions{
+	return guard.PrintElements(ctx, gptscriptClient, elements, gptscript.DatasetOptions{
 		Name: fmt.Sprintf("outlook_mail_search_results"),
-	})
-	if err != nil {
-		return fmt.Errorf("failed to create dataset: %w", err)
-	}
-
-	fmt.Printf("Created dataset with ID %s with %d messages\n", datasetID, len(messages))
-	return nil
+	}, "messages")
 }
@@ -10,7 +10,7 @@ import (
 	"github.com/gptscript-ai/tools/outlook/mail/pkg/graph"
 )
 
-func SendDraft(ctx context.Context, draftID string) error {
+func SendDraft(ctx context.Context, mailbox, draftID string) error {
 	trueDraftID, err := id.GetOutlookID(ctx, draftID)
 	if err != nil {
 		return fmt.Errorf("failed to get outlook ID: %w", err)
@@ -21,7 +21,7 @@ func SendDraft(ctx context.Context, draftID string) error {
 		return fmt.Errorf("failed to create client: %w", err)
 	}
 
-	if err := graph.SendDraft(ctx, c, trueDraftID); err != nil {
+	if err := graph.SendDraft(ctx, c, mailbox, trueDraftID); err != nil {
 		return fmt.Errorf("failed to send draft: %w", err)
 	}
 
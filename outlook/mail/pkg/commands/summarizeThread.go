@@ -0,0 +1,113 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/outlook/common/id"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/graph"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/thread"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/util"
+)
+
+type threadSummary struct {
+	Summary     string       `json:"summary"`
+	ActionItems []actionItem `json:"actionItems"`
+}
+
+type actionItem struct {
+	Owner string `json:"owner"`
+	Task  string `json:"task"`
+	Due   string `json:"due"`
+}
+
+func SummarizeThread(ctx context.Context, mailbox, messageID string) error {
+	trueMessageID, err := id.GetOutlookID(ctx, messageID)
+	if err != nil {
+		return fmt.Errorf("failed to get outlook ID: %w", err)
+	}
+
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	msg, err := graph.GetMessageDetails(ctx, c, mailbox, trueMessageID)
+	if err != nil {
+		return fmt.Errorf("failed to get message details: %w", err)
+	}
+
+	messages, err := graph.ListConversationMessages(ctx, c, mailbox, util.Deref(msg.GetConversationId()))
+	if err != nil {
+		return err
+	}
+
+	transcript := thread.Assemble(messages)
+	if transcript == "" {
+		fmt.Println("The thread does not contain any messages to summarize")
+		return nil
+	}
+
+	g, err := gptscript.NewGPTScript()
+	if err != nil {
+		return fmt.Errorf("failed to create GPTScript client: %w", err)
+	}
+	defer g.Close()
+
+	run, err := g.Evaluate(ctx, gptscript.Options{}, gptscript.ToolDef{
+		JSONResponse: true,
+		Instructions: fmt.Sprintf(`
+You are given an email thread, oldest message first. Quoted text of earlier messages has already been removed.
+Summarize the thread in a few sentences, focusing on decisions, open questions and the current state of the discussion.
+Then list all action items that were requested or agreed on, including who owns them and when they are due, if mentioned.
+
+Output a JSON object with exactly these fields:
+- "summary": the summary as a string
+- "actionItems": an array of objects with the fields "owner", "task" and "due" (use an empty string if unknown). Use an empty array if there are no action items.
+
+Subject: %s
+
+%s`, util.Deref(msg.GetSubject()), transcript),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to summarize thread: %w", err)
+	}
+
+	result, err := run.Text()
+	if err != nil {
+		return fmt.Errorf("failed to summarize thread: %w", err)
+	}
+
+	var summary threadSummary
+	if err := json.Unmarshal([]byte(result), &summary); err != nil {
+		return fmt.Errorf("failed to unmarshal thread summary: %w", err)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Subject: %s\n", util.Deref(msg.GetSubject())))
+	sb.WriteString(fmt.Sprintf("Messages in thread: %d\n\n", len(messages)))
+	sb.WriteString(fmt.Sprintf("Summary:\n%s\n", strings.TrimSpace(summary.Summary)))
+	if len(summary.ActionItems) > 0 {
+		sb.WriteString("\nAction items:\n")
+		for _, item := range summary.ActionItems {
+			sb.WriteString(fmt.Sprintf("- %s", item.Task))
+			if item.Owner != "" {
+				sb.WriteString(fmt.Sprintf(" (owner: %s)", item.Owner))
+			}
+			if item.Due != "" {
+				sb.WriteString(fmt.Sprintf(" (due: %s)", item.Due))
+			}
+			sb.WriteString("\n")
+		}
+	} else {
+		sb.WriteString("\nNo action items.\n")
+	}
+
+	fmt.Print(sb.String())
+	return nil
+}
@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gptscript-ai/tools/outlook/common/id"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/graph"
+)
+
+// UpdateDraft changes the subject, body, recipients, importance and sensitivity of an existing draft, fields that are
+// not set are kept.
+// Recipient lists that are set but empty are cleared.
+func UpdateDraft(ctx context.Context, draftID string, info graph.DraftInfo) error {
+	if info.Subject == "" && info.Body == "" && info.Recipients == nil && info.CC == nil && info.BCC == nil && info.Importance == nil && info.Sensitivity == nil {
+		return fmt.Errorf("nothing to update, set the subject, body, recipients, importance or sensitivity")
+	}
+
+	trueDraftID, err := id.GetOutlookID(ctx, draftID)
+	if err != nil {
+		return fmt.Errorf("failed to get outlook ID: %w", err)
+	}
+
+	c, err := client.NewClient(global.AllScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	if _, err := graph.UpdateDraft(ctx, c, trueDraftID, info); err != nil {
+		return fmt.Errorf("failed to update draft: %w", err)
+	}
+
+	fmt.Printf("Draft updated successfully. Draft ID: %s\n", draftID)
+	return nil
+}
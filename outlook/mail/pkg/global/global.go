@@ -3,6 +3,10 @@ package global
 const CredentialEnv = "GPTSCRIPT_GRAPH_MICROSOFT_COM_BEARER_TOKEN"
 
 var (
-	ReadOnlyScopes = []string{"Mail.Read", "User.Read", "MailboxSettings.Read"}
-	AllScopes      = []string{"Mail.Read", "Mail.ReadWrite", "Mail.Send", "User.Read", "MailboxSettings.Read"}
+	ReadOnlyScopes = []string{"Mail.Read", "Mail.Read.Shared", "User.Read", "MailboxSettings.Read"}
+	AllScopes      = []string{"Mail.Read", "Mail.ReadWrite", "Mail.Send", "Mail.Read.Shared", "Mail.ReadWrite.Shared", "Mail.Send.Shared", "User.Read", "MailboxSettings.Read"}
+	// SettingsScopes are needed to change mailbox settings, like the inbox rules and the automatic replies
+	SettingsScopes = []string{"Mail.Read", "User.Read", "MailboxSettings.ReadWrite"}
+	// SecurityScopes are needed to submit messages as threats to the security team
+	SecurityScopes = []string{"Mail.ReadWrite", "Mail.ReadWrite.Shared", "User.Read", "ThreatSubmission.ReadWrite"}
 )
@@ -0,0 +1,133 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/gptscript-ai/tools/outlook/common/pagination"
+	abstractions "github.com/microsoft/kiota-abstractions-go"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+const (
+	FileAttachmentType      = "#microsoft.graph.fileAttachment"
+	ItemAttachmentType      = "#microsoft.graph.itemAttachment"
+	ReferenceAttachmentType = "#microsoft.graph.referenceAttachment"
+)
+
+// downloadChunkSize is the size of the ranges requested for large attachments. Graph returns attachments of
+// up to 150MB, which would otherwise have to come in a single response.
+const downloadChunkSize = 4 * 1024 * 1024 // 4MB
+
+// attachmentProperties are the properties of an attachment without its content
+var attachmentProperties = []string{"id", "name", "contentType", "size", "isInline", "lastModifiedDateTime"}
+
+// ListAttachments returns the attachments of a message, without their content.
+func ListAttachments(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress, messageID string) ([]models.Attachmentable, error) {
+	attachments := mailbox(client, mailboxAddress).Messages().ByMessageId(messageID).Attachments()
+	result, err := pagination.New(
+		func(ctx context.Context, q pagination.Query) (models.AttachmentCollectionResponseable, error) {
+			return attachments.Get(ctx, &users.ItemMessagesItemAttachmentsRequestBuilderGetRequestConfiguration{
+				QueryParameters: &users.ItemMessagesItemAttachmentsRequestBuilderGetQueryParameters{
+					Top:    q.Top,
+					Select: q.Select,
+				},
+			})
+		},
+		func(ctx context.Context, nextLink string) (models.AttachmentCollectionResponseable, error) {
+			return attachments.WithUrl(nextLink).Get(ctx, nil)
+		},
+	).WithSelect(attachmentProperties...).Collect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetAttachment returns an attachment of a message, without its content.
+func GetAttachment(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress, messageID, attachmentID string) (models.Attachmentable, error) {
+	attachment, err := mailbox(client, mailboxAddress).Messages().ByMessageId(messageID).Attachments().ByAttachmentId(attachmentID).Get(ctx, &users.ItemMessagesItemAttachmentsAttachmentItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.ItemMessagesItemAttachmentsAttachmentItemRequestBuilderGetQueryParameters{
+			Select: attachmentProperties,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachment: %w", err)
+	}
+
+	return attachment, nil
+}
+
+// DownloadAttachment returns the raw content of a file attachment, or the MIME content of an item attachment.
+// Attachments larger than downloadChunkSize are downloaded in ranges.
+func DownloadAttachment(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress, messageID, attachmentID string, size int64) ([]byte, error) {
+	requestInfo, err := mailbox(client, mailboxAddress).Messages().ByMessageId(messageID).Attachments().ByAttachmentId(attachmentID).ToGetRequestInformation(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build attachment request: %w", err)
+	}
+	uri, err := requestInfo.GetUri()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build attachment URL: %w", err)
+	}
+	uri.Path += "/$value"
+	if uri.RawPath != "" {
+		uri.RawPath += "/$value"
+	}
+
+	if size <= downloadChunkSize {
+		return downloadRange(ctx, client, *uri, "")
+	}
+
+	data := make([]byte, 0, size)
+	for int64(len(data)) < size {
+		var (
+			start = int64(len(data))
+			end   = min(start+downloadChunkSize, size) - 1
+		)
+
+		chunk, err := downloadRange(ctx, client, *uri, fmt.Sprintf("bytes=%d-%d", start, end))
+		if err != nil {
+			return nil, err
+		}
+		if len(chunk) == 0 {
+			break
+		}
+		if start == 0 && int64(len(chunk)) > end+1 {
+			// The range was ignored and the whole attachment was returned
+			return chunk, nil
+		}
+		data = append(data, chunk...)
+	}
+
+	return data, nil
+}
+
+func downloadRange(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, uri url.URL, byteRange string) ([]byte, error) {
+	requestInfo := abstractions.NewRequestInformation()
+	requestInfo.Method = abstractions.GET
+	requestInfo.SetUri(uri)
+	requestInfo.Headers.Add("Accept", "application/octet-stream")
+	if byteRange != "" {
+		requestInfo.Headers.Add("Range", byteRange)
+	}
+	errorMapping := abstractions.ErrorMappings{
+		"4XX": odataerrors.CreateODataErrorFromDiscriminatorValue,
+		"5XX": odataerrors.CreateODataErrorFromDiscriminatorValue,
+	}
+
+	result, err := client.BaseRequestBuilder.RequestAdapter.SendPrimitive(ctx, requestInfo, "[]byte", errorMapping)
+	if err != nil {
+		if byteRange != "" {
+			return nil, fmt.Errorf("failed to download attachment range %s: %w", byteRange, err)
+		}
+		return nil, fmt.Errorf("failed to download attachment: %w", err)
+	}
+
+	data, _ := result.([]byte)
+	return data, nil
+}
@@ -0,0 +1,76 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/util"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+// AutomaticRepliesInfo is a change to the automatic replies (out-of-office) settings. Nil fields are left unchanged.
+type AutomaticRepliesInfo struct {
+	Status models.AutomaticRepliesStatus
+	// Start and End are the schedule window, only used when the status is scheduled
+	Start, End *time.Time
+	// InternalMessage and ExternalMessage are markdown, for senders inside and outside the organization
+	InternalMessage, ExternalMessage *string
+	ExternalAudience                 *models.ExternalAudienceScope
+}
+
+func GetAutomaticReplies(ctx context.Context, client *msgraphsdkgo.GraphServiceClient) (models.AutomaticRepliesSettingable, error) {
+	settings, err := client.Me().MailboxSettings().Get(ctx, &users.ItemMailboxSettingsRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.ItemMailboxSettingsRequestBuilderGetQueryParameters{
+			Select: []string{"automaticRepliesSetting"},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mailbox settings: %w", err)
+	}
+
+	if settings.GetAutomaticRepliesSetting() == nil {
+		return models.NewAutomaticRepliesSetting(), nil
+	}
+	return settings.GetAutomaticRepliesSetting(), nil
+}
+
+func SetAutomaticReplies(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, info AutomaticRepliesInfo) (models.AutomaticRepliesSettingable, error) {
+	setting := models.NewAutomaticRepliesSetting()
+	setting.SetStatus(util.Ptr(info.Status))
+	if info.Status == models.SCHEDULED_AUTOMATICREPLIESSTATUS {
+		if info.Start == nil || info.End == nil {
+			return nil, fmt.Errorf("scheduled automatic replies need a start and an end")
+		}
+		setting.SetScheduledStartDateTime(utcDateTimeTimeZone(*info.Start))
+		setting.SetScheduledEndDateTime(utcDateTimeTimeZone(*info.End))
+	}
+	if info.InternalMessage != nil {
+		setting.SetInternalReplyMessage(util.Ptr(markdownToHTML(*info.InternalMessage)))
+	}
+	if info.ExternalMessage != nil {
+		setting.SetExternalReplyMessage(util.Ptr(markdownToHTML(*info.ExternalMessage)))
+	}
+	if info.ExternalAudience != nil {
+		setting.SetExternalAudience(info.ExternalAudience)
+	}
+
+	requestBody := models.NewMailboxSettings()
+	requestBody.SetAutomaticRepliesSetting(setting)
+
+	if _, err := client.Me().MailboxSettings().Patch(ctx, requestBody, nil); err != nil {
+		return nil, fmt.Errorf("failed to update automatic replies: %w", err)
+	}
+
+	// The response of the update does not always include the automatic replies, so get them again
+	return GetAutomaticReplies(ctx, client)
+}
+
+// markdownToHTML renders markdown as an HTML fragment, for settings that are HTML but not a whole message
+func markdownToHTML(s string) string {
+	return string(markdown.ToHTML([]byte(s), parser.NewWithExtensions(parser.CommonExtensions), nil))
+}
@@ -0,0 +1,65 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/util"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+// ListCategories returns the master categories of the mailbox, which are the categories that can be applied to
+// messages.
+func ListCategories(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress string) ([]models.OutlookCategoryable, error) {
+	result, err := mailbox(client, mailboxAddress).Outlook().MasterCategories().Get(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list categories: %w", err)
+	}
+
+	return result.GetValue(), nil
+}
+
+// UpdateMessageCategories applies the categories to a message, or removes them from it, and returns the categories
+// of the message. Categories are matched case-insensitively.
+func UpdateMessageCategories(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress, messageID string, add bool, categories ...string) ([]string, error) {
+	message, err := mailbox(client, mailboxAddress).Messages().ByMessageId(messageID).Get(ctx, &users.ItemMessagesMessageItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.ItemMessagesMessageItemRequestBuilderGetQueryParameters{
+			Select: []string{"id", "categories"},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message categories: %w", err)
+	}
+
+	current := message.GetCategories()
+	updated := slices.DeleteFunc(slices.Clone(current), func(c string) bool {
+		return slices.ContainsFunc(categories, func(category string) bool {
+			return strings.EqualFold(c, category)
+		})
+	})
+	if add {
+		updated = append(updated, categories...)
+	}
+	if slices.Equal(updated, current) {
+		return current, nil
+	}
+
+	requestBody := models.NewMessage()
+	requestBody.SetCategories(updated)
+	if _, err := mailbox(client, mailboxAddress).Messages().ByMessageId(messageID).Patch(ctx, requestBody, nil); err != nil {
+		return nil, fmt.Errorf("failed to update message categories: %w", err)
+	}
+
+	return updated, nil
+}
+
+// CategoryNames returns the names of the categories.
+func CategoryNames(categories []models.OutlookCategoryable) []string {
+	return util.Map(categories, func(category models.OutlookCategoryable) string {
+		return util.Deref(category.GetDisplayName())
+	})
+}
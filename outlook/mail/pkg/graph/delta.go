@@ -0,0 +1,81 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gptscript-ai/tools/outlook/common/pagination"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/util"
+	abstractions "github.com/microsoft/kiota-abstractions-go"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+// MessageDelta holds the changes of the messages of a folder since the last delta query.
+type MessageDelta struct {
+	// Messages are the new and changed messages
+	Messages []models.Messageable
+	// RemovedIDs are the IDs of the messages that were deleted or moved out of the folder
+	RemovedIDs []string
+	// DeltaLink is the link to get the changes after this query
+	DeltaLink string
+}
+
+// GetMessageDelta returns the changes of the messages of the folder since the query that returned deltaLink. Without
+// a delta link, it returns the messages received since the given time, and the delta link for the following queries.
+func GetMessageDelta(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress, folderID, deltaLink string, since time.Time) (*MessageDelta, error) {
+	headers := abstractions.NewRequestHeaders()
+	headers.Add("Prefer", "odata.maxpagesize=100")
+	delta := mailbox(client, mailboxAddress).MailFolders().ByMailFolderId(folderID).Messages().Delta()
+
+	getPage := func(link string) (users.ItemMailFoldersItemMessagesDeltaGetResponseable, error) {
+		return pagination.WithRetry(ctx, func() (users.ItemMailFoldersItemMessagesDeltaGetResponseable, error) {
+			return delta.WithUrl(link).GetAsDeltaGetResponse(ctx, &users.ItemMailFoldersItemMessagesDeltaRequestBuilderGetRequestConfiguration{
+				Headers: headers,
+			})
+		})
+	}
+
+	var (
+		page users.ItemMailFoldersItemMessagesDeltaGetResponseable
+		err  error
+	)
+	if deltaLink != "" {
+		page, err = getPage(deltaLink)
+	} else {
+		page, err = pagination.WithRetry(ctx, func() (users.ItemMailFoldersItemMessagesDeltaGetResponseable, error) {
+			return delta.GetAsDeltaGetResponse(ctx, &users.ItemMailFoldersItemMessagesDeltaRequestBuilderGetRequestConfiguration{
+				Headers: headers,
+				QueryParameters: &users.ItemMailFoldersItemMessagesDeltaRequestBuilderGetQueryParameters{
+					// The delta query of messages only supports filtering on receivedDateTime
+					Filter: util.Ptr(fmt.Sprintf("receivedDateTime ge %s", since.UTC().Format(time.RFC3339))),
+				},
+			})
+		})
+	}
+
+	result := &MessageDelta{}
+	for {
+		if err != nil {
+			return nil, fmt.Errorf("failed to get message changes: %w", err)
+		}
+
+		for _, message := range page.GetValue() {
+			if _, removed := message.GetAdditionalData()["@removed"]; removed {
+				result.RemovedIDs = append(result.RemovedIDs, util.Deref(message.GetId()))
+				continue
+			}
+			result.Messages = append(result.Messages, message)
+		}
+
+		if nextLink := util.Deref(page.GetOdataNextLink()); nextLink != "" {
+			page, err = getPage(nextLink)
+			continue
+		}
+
+		result.DeltaLink = util.Deref(page.GetOdataDeltaLink())
+		return result, nil
+	}
+}
@@ -2,25 +2,99 @@ package graph
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/gptscript-ai/tools/outlook/common/pagination"
 	"github.com/gptscript-ai/tools/outlook/mail/pkg/util"
 	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
 	"github.com/microsoftgraph/msgraph-sdk-go/users"
 )
 
-func ListMailFolders(ctx context.Context, client *msgraphsdkgo.GraphServiceClient) ([]models.MailFolderable, error) {
-	result, err := client.Me().MailFolders().Get(ctx, &users.ItemMailFoldersRequestBuilderGetRequestConfiguration{
-		QueryParameters: &users.ItemMailFoldersRequestBuilderGetQueryParameters{
-			Top: util.Ptr(int32(100)),
+func ListMailFolders(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress string) ([]models.MailFolderable, error) {
+	mailFolders := mailbox(client, mailboxAddress).MailFolders()
+	return pagination.New(
+		func(ctx context.Context, q pagination.Query) (models.MailFolderCollectionResponseable, error) {
+			return mailFolders.Get(ctx, &users.ItemMailFoldersRequestBuilderGetRequestConfiguration{
+				QueryParameters: &users.ItemMailFoldersRequestBuilderGetQueryParameters{
+					Top: q.Top,
+				},
+			})
 		},
-	})
+		func(ctx context.Context, nextLink string) (models.MailFolderCollectionResponseable, error) {
+			return mailFolders.WithUrl(nextLink).Get(ctx, nil)
+		},
+	).Collect(ctx)
+}
+
+// ListChildFolders returns the folders directly below a folder.
+func ListChildFolders(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress, parentFolderID string) ([]models.MailFolderable, error) {
+	childFolders := mailbox(client, mailboxAddress).MailFolders().ByMailFolderId(parentFolderID).ChildFolders()
+	return pagination.New(
+		func(ctx context.Context, q pagination.Query) (models.MailFolderCollectionResponseable, error) {
+			return childFolders.Get(ctx, &users.ItemMailFoldersItemChildFoldersRequestBuilderGetRequestConfiguration{
+				QueryParameters: &users.ItemMailFoldersItemChildFoldersRequestBuilderGetQueryParameters{
+					Top: q.Top,
+				},
+			})
+		},
+		func(ctx context.Context, nextLink string) (models.MailFolderCollectionResponseable, error) {
+			return childFolders.WithUrl(nextLink).Get(ctx, nil)
+		},
+	).Collect(ctx)
+}
+
+// CreateMailFolder creates a folder below the parent folder, or a top-level folder if parentFolderID is empty.
+func CreateMailFolder(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress, parentFolderID, name string) (models.MailFolderable, error) {
+	requestBody := models.NewMailFolder()
+	requestBody.SetDisplayName(util.Ptr(name))
+
+	var (
+		folder models.MailFolderable
+		err    error
+	)
+	if parentFolderID == "" {
+		folder, err = mailbox(client, mailboxAddress).MailFolders().Post(ctx, requestBody, nil)
+	} else {
+		folder, err = mailbox(client, mailboxAddress).MailFolders().ByMailFolderId(parentFolderID).ChildFolders().Post(ctx, requestBody, nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mail folder: %w", err)
+	}
+
+	return folder, nil
+}
+
+func RenameMailFolder(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress, folderID, name string) (models.MailFolderable, error) {
+	requestBody := models.NewMailFolder()
+	requestBody.SetDisplayName(util.Ptr(name))
+
+	folder, err := mailbox(client, mailboxAddress).MailFolders().ByMailFolderId(folderID).Patch(ctx, requestBody, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rename mail folder: %w", err)
+	}
+
+	return folder, nil
+}
+
+// DeleteMailFolder deletes a folder with its messages and child folders. Outlook moves it to Deleted Items.
+func DeleteMailFolder(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress, folderID string) error {
+	if err := mailbox(client, mailboxAddress).MailFolders().ByMailFolderId(folderID).Delete(ctx, nil); err != nil {
+		return fmt.Errorf("failed to delete mail folder: %w", err)
+	}
+
+	return nil
+}
 
-	// TODO - handle if there are more than 100
+// MoveMailFolder moves a folder with its messages and child folders below the destination folder.
+func MoveMailFolder(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress, folderID, destinationFolderID string) (models.MailFolderable, error) {
+	requestBody := users.NewItemMailFoldersItemMovePostRequestBody()
+	requestBody.SetDestinationId(util.Ptr(destinationFolderID))
 
+	folder, err := mailbox(client, mailboxAddress).MailFolders().ByMailFolderId(folderID).Move().Post(ctx, requestBody, nil)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to move mail folder: %w", err)
 	}
 
-	return result.GetValue(), nil
+	return folder, nil
 }
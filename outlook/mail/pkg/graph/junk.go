@@ -0,0 +1,279 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/util"
+	abstractions "github.com/microsoft/kiota-abstractions-go"
+	"github.com/microsoft/kiota-abstractions-go/serialization"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+// SenderList is one of the sender lists managed by the tool.
+type SenderList string
+
+const (
+	BlockedSenders SenderList = "blocked"
+	SafeSenders    SenderList = "safe"
+)
+
+// Graph has no API for the blocked and safe senders lists of the junk email settings, so they are kept as inbox
+// rules with these names: messages from blocked senders are moved to Junk Email, messages from safe senders to the Inbox.
+// The rules match the sender addresses exactly, and run after the user's own rules without stopping them.
+var senderListRules = map[SenderList]struct {
+	name   string
+	folder string // well-known folder name
+}{
+	BlockedSenders: {name: "Blocked senders", folder: "junkemail"},
+	SafeSenders:    {name: "Safe senders", folder: "inbox"},
+}
+
+// ArchiveFolder is the well-known name of the folder that Outlook's Archive action uses
+const ArchiveFolder = "archive"
+
+// ArchiveMessage moves the message to the Archive folder of the mailbox.
+func ArchiveMessage(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress, messageID string) (models.Messageable, error) {
+	return MoveMessage(ctx, client, mailboxAddress, messageID, ArchiveFolder)
+}
+
+// ReportJunk marks the message as junk and moves it to the Junk Email folder, or marks it as not junk and moves it
+// to the Inbox. Besides training the junk filter, Outlook adds the sender to (or removes it from) the blocked senders
+// of the mailbox's junk email settings, which are separate from the sender list rules kept by UpdateSenderList. The
+// actions are only available in the beta API.
+func ReportJunk(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress, messageID string, junk bool) (models.Messageable, error) {
+	action, body := "markAsNotJunk", map[string]bool{"moveToInbox": true}
+	if junk {
+		action, body = "markAsJunk", map[string]bool{"moveToJunk": true}
+	}
+
+	content, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := postBeta(ctx, client, fmt.Sprintf("%s/messages/%s/%s", betaUser(mailboxAddress), messageID, action), content, models.CreateMessageFromDiscriminatorValue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s: %w", action, err)
+	}
+
+	message, ok := result.(models.Messageable)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response to %s", action)
+	}
+	return message, nil
+}
+
+// ReportPhishing submits the message as phishing to Microsoft and the security team of the organization, and moves
+// it to the Junk Email folder. Threat submissions are only available in the beta API and need the
+// ThreatSubmission.ReadWrite scope.
+func ReportPhishing(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress, messageID string) (models.Messageable, error) {
+	recipient, user := strings.TrimSpace(mailboxAddress), betaUser(mailboxAddress)
+	if recipient == "" {
+		me, err := client.Me().Get(ctx, &users.UserItemRequestBuilderGetRequestConfiguration{
+			QueryParameters: &users.UserItemRequestBuilderGetQueryParameters{Select: []string{"id", "mail", "userPrincipalName"}},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		recipient = util.Deref(me.GetMail())
+		if recipient == "" {
+			recipient = util.Deref(me.GetUserPrincipalName())
+		}
+		// The message URL of the submission must name the user
+		user = "users/" + url.PathEscape(util.Deref(me.GetId()))
+	}
+
+	content, err := json.Marshal(map[string]string{
+		"@odata.type":           "#microsoft.graph.security.emailUrlThreatSubmission",
+		"category":              "phishing",
+		"recipientEmailAddress": recipient,
+		"messageUrl":            fmt.Sprintf("https://graph.microsoft.com/beta/%s/messages/%s", user, messageID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := postBeta(ctx, client, "security/threatSubmission/emailThreats", content, nil); err != nil {
+		return nil, fmt.Errorf("failed to submit message as phishing: %w", err)
+	}
+
+	return ReportJunk(ctx, client, mailboxAddress, messageID, true)
+}
+
+// betaUser returns the path of the mailbox in the beta API
+func betaUser(mailboxAddress string) string {
+	if mailboxAddress = strings.TrimSpace(mailboxAddress); mailboxAddress != "" {
+		return "users/" + url.PathEscape(mailboxAddress)
+	}
+	return "me"
+}
+
+// postBeta posts the JSON content to the path of the beta API. The response is parsed with the factory, or ignored if
+// it is nil.
+func postBeta(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, path string, content []byte, factory serialization.ParsableFactory) (serialization.Parsable, error) {
+	requestInfo := abstractions.NewRequestInformation()
+	requestInfo.UrlTemplate = "https://graph.microsoft.com/beta/" + path
+	requestInfo.Method = abstractions.POST
+	requestInfo.Headers.Add("Accept", "application/json")
+	requestInfo.SetStreamContentAndContentType(content, "application/json")
+	errorMapping := abstractions.ErrorMappings{
+		"4XX": odataerrors.CreateODataErrorFromDiscriminatorValue,
+		"5XX": odataerrors.CreateODataErrorFromDiscriminatorValue,
+	}
+
+	if factory == nil {
+		return nil, client.BaseRequestBuilder.RequestAdapter.SendNoContent(ctx, requestInfo, errorMapping)
+	}
+	return client.BaseRequestBuilder.RequestAdapter.Send(ctx, requestInfo, factory, errorMapping)
+}
+
+// GetSenderList returns the email addresses on the sender list.
+func GetSenderList(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress string, list SenderList) ([]string, error) {
+	rule, err := getSenderListRule(ctx, client, mailboxAddress, list)
+	if err != nil || rule == nil {
+		return nil, err
+	}
+	return ruleSenders(rule), nil
+}
+
+// UpdateSenderList adds the email addresses to the sender list, or removes them from it.
+// An address is removed from the other list when it is added, as a sender can't be both blocked and safe.
+func UpdateSenderList(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress string, list SenderList, add bool, addresses ...string) ([]string, error) {
+	addresses = util.Map(addresses, normalizeSender)
+	for _, address := range addresses {
+		if !IsSenderAddress(address) {
+			return nil, fmt.Errorf("%q is not an email address", address)
+		}
+	}
+
+	if add {
+		other := SafeSenders
+		if list == SafeSenders {
+			other = BlockedSenders
+		}
+		if _, err := UpdateSenderList(ctx, client, mailboxAddress, other, false, addresses...); err != nil {
+			return nil, err
+		}
+	}
+
+	rule, err := getSenderListRule(ctx, client, mailboxAddress, list)
+	if err != nil {
+		return nil, err
+	}
+
+	var senders []string
+	if rule != nil {
+		senders = ruleSenders(rule)
+	}
+
+	updated := slices.DeleteFunc(slices.Clone(senders), func(s string) bool {
+		return slices.Contains(addresses, normalizeSender(s))
+	})
+	if add {
+		updated = append(updated, addresses...)
+	}
+	if slices.Equal(updated, senders) {
+		return senders, nil
+	}
+
+	rules := mailbox(client, mailboxAddress).MailFolders().ByMailFolderId("inbox").MessageRules()
+	switch {
+	case len(updated) == 0 && rule != nil:
+		// A rule without senders would match every message
+		if err := rules.ByMessageRuleId(util.Deref(rule.GetId())).Delete(ctx, nil); err != nil {
+			return nil, fmt.Errorf("failed to delete %s senders rule: %w", list, err)
+		}
+	case rule != nil:
+		conditions := models.NewMessageRulePredicates()
+		conditions.SetFromAddresses(emailAddressesToRecipientable(updated))
+		patch := models.NewMessageRule()
+		patch.SetConditions(conditions)
+		if _, err := rules.ByMessageRuleId(util.Deref(rule.GetId())).Patch(ctx, patch, nil); err != nil {
+			return nil, fmt.Errorf("failed to update %s senders rule: %w", list, err)
+		}
+	case len(updated) > 0:
+		newRule, err := newSenderListRule(ctx, client, mailboxAddress, list, updated)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := rules.Post(ctx, newRule, nil); err != nil {
+			return nil, fmt.Errorf("failed to create %s senders rule: %w", list, err)
+		}
+	}
+
+	return updated, nil
+}
+
+func getSenderListRule(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress string, list SenderList) (models.MessageRuleable, error) {
+	rules, err := ListRules(ctx, client, mailboxAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rule := range rules {
+		if IsSenderListRule(rule, list) {
+			return rule, nil
+		}
+	}
+	return nil, nil
+}
+
+// IsSenderListRule reports whether the inbox rule is the one that keeps the given blocked or safe senders list.
+func IsSenderListRule(rule models.MessageRuleable, list SenderList) bool {
+	return util.Deref(rule.GetDisplayName()) == senderListRules[list].name
+}
+
+func newSenderListRule(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress string, list SenderList, senders []string) (models.MessageRuleable, error) {
+	folderID, err := ruleFolderID(ctx, client, mailboxAddress, senderListRules[list].folder)
+	if err != nil {
+		return nil, err
+	}
+
+	sequence, err := nextRuleSequence(ctx, client, mailboxAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	conditions := models.NewMessageRulePredicates()
+	conditions.SetFromAddresses(emailAddressesToRecipientable(senders))
+
+	actions := models.NewMessageRuleActions()
+	actions.SetMoveToFolder(util.Ptr(folderID))
+
+	rule := models.NewMessageRule()
+	rule.SetDisplayName(util.Ptr(senderListRules[list].name))
+	rule.SetSequence(util.Ptr(sequence))
+	rule.SetIsEnabled(util.Ptr(true))
+	rule.SetConditions(conditions)
+	rule.SetActions(actions)
+	return rule, nil
+}
+
+// ruleSenders returns the sender addresses that the rule matches
+func ruleSenders(rule models.MessageRuleable) []string {
+	if rule.GetConditions() == nil {
+		return nil
+	}
+	return util.Map(rule.GetConditions().GetFromAddresses(), func(recipient models.Recipientable) string {
+		return util.Deref(recipient.GetEmailAddress().GetAddress())
+	})
+}
+
+// IsSenderAddress reports whether s looks like a single email address. Sender lists only hold exact addresses,
+// as rules can't match a domain without also matching addresses that merely contain it.
+func IsSenderAddress(s string) bool {
+	at := strings.LastIndex(s, "@")
+	return at > 0 && at < len(s)-1 && !strings.ContainsAny(s, " ,;")
+}
+
+func normalizeSender(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
@@ -0,0 +1,50 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/util"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSenderAddress(t *testing.T) {
+	for s, want := range map[string]bool{
+		"bob@example.com":                  true,
+		"bob.smith+news@mail.example.com":  true,
+		"example.com":                      false,
+		"@example.com":                     false,
+		"bob@":                             false,
+		"bob@example.com, ann@example.com": false,
+		"bob@example.com;ann@example.com":  false,
+		"bob smith@example.com":            false,
+		"":                                 false,
+	} {
+		assert.Equal(t, want, IsSenderAddress(s), s)
+	}
+}
+
+func TestNormalizeSender(t *testing.T) {
+	assert.Equal(t, "bob@example.com", normalizeSender("  Bob@Example.COM "))
+}
+
+func TestSenderListRule(t *testing.T) {
+	conditions := models.NewMessageRulePredicates()
+	conditions.SetFromAddresses(emailAddressesToRecipientable([]string{"bob@example.com", "ann@example.com"}))
+	rule := models.NewMessageRule()
+	rule.SetDisplayName(util.Ptr(senderListRules[BlockedSenders].name))
+	rule.SetConditions(conditions)
+
+	assert.True(t, IsSenderListRule(rule, BlockedSenders))
+	assert.False(t, IsSenderListRule(rule, SafeSenders))
+	assert.Equal(t, []string{"bob@example.com", "ann@example.com"}, ruleSenders(rule))
+
+	assert.Empty(t, ruleSenders(models.NewMessageRule()))
+}
+
+func TestBetaUser(t *testing.T) {
+	assert.Equal(t, "me", betaUser(""))
+	assert.Equal(t, "me", betaUser("  "))
+	assert.Equal(t, "users/shared@example.com", betaUser(" shared@example.com "))
+	assert.Equal(t, "users/team%2Fsales@example.com", betaUser("team/sales@example.com"))
+}
@@ -2,9 +2,12 @@ package graph
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"mime"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -12,6 +15,10 @@ import (
 	"github.com/gomarkdown/markdown/html"
 	"github.com/gomarkdown/markdown/parser"
 	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/outlook/common/attachments"
+	"github.com/gptscript-ai/tools/outlook/common/batch"
+	"github.com/gptscript-ai/tools/outlook/common/fields"
+	"github.com/gptscript-ai/tools/outlook/common/pagination"
 	"github.com/gptscript-ai/tools/outlook/mail/pkg/util"
 	abstractions "github.com/microsoft/kiota-abstractions-go"
 	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
@@ -20,111 +27,300 @@ import (
 	"github.com/microsoftgraph/msgraph-sdk-go/users"
 )
 
-func ListMessages(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, folderID, start, end string, limit int) ([]models.Messageable, error) {
+// MessageFields are the field sets of the commands that list and search messages. The default set has the properties
+// that printers.MessageToString prints.
+var MessageFields = fields.Sets{
+	fields.Summary: {"subject", "sender", "receivedDateTime", "isRead", "isDraft"},
+	fields.Default: {"subject", "sender", "receivedDateTime", "isRead", "isDraft", "categories", "flag", "webLink", "bodyPreview"},
+	fields.Full:    nil,
+}
+
+// ListMessages calls fn for the messages of the folder, newest first, following @odata.nextLink across pages. It stops
+// when fn returns false or after limit messages, a limit of 0 or less lists all messages. If classification is set,
+// only the messages of the Focused or Other inbox are listed. Only the selected properties are returned, all default
+// properties if selects is nil.
+func ListMessages(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress, folderID, start, end string, classification *models.InferenceClassificationType, limit int, selects []string, fn func(message models.Messageable) bool) error {
 	queryParams := &users.ItemMailFoldersItemMessagesRequestBuilderGetQueryParameters{
 		Orderby: []string{"receivedDateTime DESC"},
 	}
+	if filter := listFilter(start, end, classification, time.Now()); filter != "" {
+		queryParams.Filter = util.Ptr(filter)
+	}
 
-	if limit > 0 {
-		queryParams.Top = util.Ptr(int32(limit))
+	messages := mailbox(client, mailboxAddress).MailFolders().ByMailFolderId(folderID).Messages()
+	err := pagination.New(
+		func(ctx context.Context, q pagination.Query) (models.MessageCollectionResponseable, error) {
+			queryParams.Top, queryParams.Select = q.Top, q.Select
+			return messages.Get(ctx, &users.ItemMailFoldersItemMessagesRequestBuilderGetRequestConfiguration{
+				QueryParameters: queryParams,
+			})
+		},
+		func(ctx context.Context, nextLink string) (models.MessageCollectionResponseable, error) {
+			return messages.WithUrl(nextLink).Get(ctx, nil)
+		},
+	).WithSelect(selects...).WithLimit(limit).Iterate(ctx, fn)
+	if err != nil {
+		return fmt.Errorf("failed to list mail: %w", err)
 	}
 
-	var filters []string
-	if start != "" {
-		filters = append(filters, fmt.Sprintf("receivedDateTime ge %s", start))
+	return nil
+}
+
+// ListDrafts calls fn for the drafts of the mailbox, most recently modified first, following @odata.nextLink across
+// pages. It stops when fn returns false or after limit drafts, a limit of 0 or less lists all drafts.
+func ListDrafts(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress string, limit int, fn func(message models.Messageable) bool) error {
+	queryParams := &users.ItemMailFoldersItemMessagesRequestBuilderGetQueryParameters{
+		Orderby: []string{"lastModifiedDateTime DESC"},
 	}
-	if end != "" {
-		filters = append(filters, fmt.Sprintf("receivedDateTime le %s", end))
+
+	// "drafts" is the well-known name of the Drafts folder
+	messages := mailbox(client, mailboxAddress).MailFolders().ByMailFolderId("drafts").Messages()
+	err := pagination.New(
+		func(ctx context.Context, q pagination.Query) (models.MessageCollectionResponseable, error) {
+			queryParams.Top, queryParams.Select = q.Top, q.Select
+			return messages.Get(ctx, &users.ItemMailFoldersItemMessagesRequestBuilderGetRequestConfiguration{
+				QueryParameters: queryParams,
+			})
+		},
+		func(ctx context.Context, nextLink string) (models.MessageCollectionResponseable, error) {
+			return messages.WithUrl(nextLink).Get(ctx, nil)
+		},
+	).WithSelect("id", "subject", "toRecipients", "ccRecipients", "bccRecipients", "lastModifiedDateTime", "hasAttachments", "bodyPreview", "webLink", "isDraft").
+		WithLimit(limit).Iterate(ctx, fn)
+	if err != nil {
+		return fmt.Errorf("failed to list drafts: %w", err)
 	}
 
-	if len(filters) > 0 {
-		queryParams.Filter = util.Ptr(strings.Join(filters, " and "))
+	return nil
+}
+
+func GetMessageDetails(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress, messageID string) (models.Messageable, error) {
+	result, err := mailbox(client, mailboxAddress).Messages().ByMessageId(messageID).Get(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message details: %w", err)
 	}
 
-	result, err := client.Me().MailFolders().ByMailFolderId(folderID).Messages().Get(ctx, &users.ItemMailFoldersItemMessagesRequestBuilderGetRequestConfiguration{
-		QueryParameters: queryParams,
+	return result, nil
+}
+
+// GetMessageMIME returns the message in MIME format (RFC 822), as it would be saved to an .eml file.
+func GetMessageMIME(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress, messageID string) ([]byte, error) {
+	data, err := mailbox(client, mailboxAddress).Messages().ByMessageId(messageID).Content().Get(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MIME content of message: %w", err)
+	}
+
+	return data, nil
+}
+
+// GetMessageHeaders returns the internet message headers of a message, in the order of the message, so the most
+// recent Received header comes first.
+func GetMessageHeaders(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress, messageID string) ([]models.InternetMessageHeaderable, error) {
+	message, err := mailbox(client, mailboxAddress).Messages().ByMessageId(messageID).Get(ctx, &users.ItemMessagesMessageItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.ItemMessagesMessageItemRequestBuilderGetQueryParameters{
+			Select: []string{"id", "internetMessageHeaders"},
+		},
 	})
-	// TODO - handle pagination if there are more messages than can be returned in a single call
 	if err != nil {
-		return nil, fmt.Errorf("failed to list mail: %w", err)
+		return nil, fmt.Errorf("failed to get message headers: %w", err)
 	}
 
-	return result.GetValue(), nil
+	return message.GetInternetMessageHeaders(), nil
 }
 
-func GetMessageDetails(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, messageID string) (models.Messageable, error) {
-	result, err := client.Me().Messages().ByMessageId(messageID).Get(ctx, nil)
+// ListConversationMessages returns all messages of a conversation (thread), oldest first.
+// The bodies are requested as plain text, and uniqueBody holds only the part that isn't quoted from earlier messages.
+func ListConversationMessages(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress, conversationID string) ([]models.Messageable, error) {
+	headers := abstractions.NewRequestHeaders()
+	headers.Add("Prefer", `outlook.body-content-type="text"`)
+
+	messages, err := pagination.New(
+		func(ctx context.Context, q pagination.Query) (models.MessageCollectionResponseable, error) {
+			return mailbox(client, mailboxAddress).Messages().Get(ctx, &users.ItemMessagesRequestBuilderGetRequestConfiguration{
+				Headers: headers,
+				QueryParameters: &users.ItemMessagesRequestBuilderGetQueryParameters{
+					// Graph rejects $orderby in combination with a conversationId filter, so we sort client-side
+					Filter: util.Ptr(fmt.Sprintf("conversationId eq '%s'", strings.ReplaceAll(conversationID, "'", "''"))),
+					Select: q.Select,
+					Top:    q.Top,
+				},
+			})
+		},
+		func(ctx context.Context, nextLink string) (models.MessageCollectionResponseable, error) {
+			return mailbox(client, mailboxAddress).Messages().WithUrl(nextLink).Get(ctx, &users.ItemMessagesRequestBuilderGetRequestConfiguration{
+				Headers: headers,
+			})
+		},
+	).WithSelect("id", "subject", "from", "sender", "toRecipients", "ccRecipients", "receivedDateTime", "sentDateTime", "body", "uniqueBody", "hasAttachments", "isDraft").Collect(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get message details: %w", err)
+		return nil, fmt.Errorf("failed to list conversation messages: %w", err)
 	}
 
-	return result, nil
+	slices.SortStableFunc(messages, func(a, b models.Messageable) int {
+		return util.Deref(a.GetReceivedDateTime()).Compare(util.Deref(b.GetReceivedDateTime()))
+	})
+	return messages, nil
 }
 
-func SearchMessages(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, subject, fromAddress, fromName, folderID, start, end string, limit int) ([]models.Messageable, error) {
-	var (
-		result models.MessageCollectionResponseable
-		err    error
-		filter []string
-	)
+// SearchQuery holds the criteria of a message search. All criteria that are set have to match.
+type SearchQuery struct {
+	Subject     string
+	FromAddress string
+	FromName    string
+	// Start and End are the time frame in RFC 3339 format
+	Start, End     string
+	HasAttachments bool
+	UnreadOnly     bool
+	Importance     *models.Importance
+	// Classification limits the search to the Focused or Other inbox
+	Classification *models.InferenceClassificationType
+}
+
+// SearchMessages returns up to limit messages that match the query, newest first, from the folder or from the whole
+// mailbox if folderID is empty. Only the selected properties are returned, all default properties if selects is nil.
+func SearchMessages(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress, folderID string, query SearchQuery, limit int, selects []string) ([]models.Messageable, error) {
+	if query.Subject == "" && query.FromAddress == "" && query.FromName == "" && query.Start == "" && query.End == "" && !query.HasAttachments && !query.UnreadOnly && query.Importance == nil && query.Classification == nil {
+		return nil, fmt.Errorf("at least one search criterion must be provided")
+	}
+
+	filter := searchFilter(query, time.Now())
+
+	var pages *pagination.PageIterator[models.Messageable]
+	if folderID != "" {
+		messages := mailbox(client, mailboxAddress).MailFolders().ByMailFolderId(folderID).Messages()
+		pages = pagination.New(
+			func(ctx context.Context, q pagination.Query) (models.MessageCollectionResponseable, error) {
+				return messages.Get(ctx, &users.ItemMailFoldersItemMessagesRequestBuilderGetRequestConfiguration{
+					QueryParameters: &users.ItemMailFoldersItemMessagesRequestBuilderGetQueryParameters{
+						Orderby: []string{"receivedDateTime DESC"},
+						Filter:  util.Ptr(filter),
+						Top:     q.Top,
+						Select:  q.Select,
+					},
+				})
+			},
+			func(ctx context.Context, nextLink string) (models.MessageCollectionResponseable, error) {
+				return messages.WithUrl(nextLink).Get(ctx, nil)
+			},
+		)
+	} else {
+		pages = pagination.New(
+			func(ctx context.Context, q pagination.Query) (models.MessageCollectionResponseable, error) {
+				return mailbox(client, mailboxAddress).Messages().Get(ctx, &users.ItemMessagesRequestBuilderGetRequestConfiguration{
+					QueryParameters: &users.ItemMessagesRequestBuilderGetQueryParameters{
+						Orderby: []string{"receivedDateTime DESC"},
+						Filter:  util.Ptr(filter),
+						Top:     q.Top,
+						Select:  q.Select,
+					},
+				})
+			},
+			func(ctx context.Context, nextLink string) (models.MessageCollectionResponseable, error) {
+				return mailbox(client, mailboxAddress).Messages().WithUrl(nextLink).Get(ctx, nil)
+			},
+		)
+	}
+
+	messages, err := pages.WithSelect(selects...).WithLimit(limit).WithDedupe(func(message models.Messageable) string {
+		return util.Deref(message.GetId())
+	}).Collect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+// listFilter returns the OData filter of the messages to list, or an empty string to list all messages
+func listFilter(start, end string, classification *models.InferenceClassificationType, now time.Time) string {
+	var filters []string
+	if start != "" {
+		filters = append(filters, fmt.Sprintf("receivedDateTime ge %s", start))
+	}
+	if end != "" {
+		filters = append(filters, fmt.Sprintf("receivedDateTime le %s", end))
+	}
+	if classification != nil {
+		if len(filters) == 0 {
+			// Graph only combines the receivedDateTime order with filters on other properties if the filter starts
+			// with a receivedDateTime condition, see searchFilter
+			filters = append(filters, fmt.Sprintf("receivedDateTime le %s", now.Add(time.Hour*24).Format(time.RFC3339)))
+		}
+		filters = append(filters, classificationFilter(*classification))
+	}
+
+	return strings.Join(filters, " and ")
+}
+
+// classificationFilter returns the OData filter of the messages of the Focused or Other inbox
+func classificationFilter(classification models.InferenceClassificationType) string {
+	return fmt.Sprintf("inferenceClassification eq '%s'", classification.String())
+}
+
+// searchFilter returns the OData filter of the messages that match the query
+func searchFilter(query SearchQuery, now time.Time) string {
+	var filter []string
 
 	// It is important that a receivedDateTime filter is first in the list.
 	// Details in the first answer on this question:
 	// https://learn.microsoft.com/en-us/answers/questions/656200/graph-api-to-filter-results-on-from-and-subject-an
-	if end != "" {
-		filter = append(filter, fmt.Sprintf("receivedDateTime le %s", end))
+	if query.End != "" {
+		filter = append(filter, fmt.Sprintf("receivedDateTime le %s", query.End))
 	} else {
 		// Using the receivedDateTime in the orderBy parameter requires us to have it in the filter as well.
 		// So we ask for messages that were received prior to tomorrow, which should be all messages.
-		tomorrow := time.Now().Add(time.Hour * 24).Format(time.RFC3339)
+		tomorrow := now.Add(time.Hour * 24).Format(time.RFC3339)
 		filter = append(filter, fmt.Sprintf("receivedDateTime le %s", tomorrow))
 	}
-	if subject != "" {
-		filter = append(filter, fmt.Sprintf("contains(subject, '%s')", subject))
+	if query.Start != "" {
+		filter = append(filter, fmt.Sprintf("receivedDateTime ge %s", query.Start))
 	}
-	if fromAddress != "" {
-		filter = append(filter, fmt.Sprintf("contains(from/emailAddress/address, '%s')", fromAddress))
+	if query.Subject != "" {
+		filter = append(filter, fmt.Sprintf("contains(subject, '%s')", escapeFilterString(query.Subject)))
 	}
-	if fromName != "" {
-		filter = append(filter, fmt.Sprintf("contains(from/emailAddress/name, '%s')", fromName))
+	if query.FromAddress != "" {
+		filter = append(filter, fmt.Sprintf("contains(from/emailAddress/address, '%s')", escapeFilterString(query.FromAddress)))
 	}
-	if start != "" {
-		filter = append(filter, fmt.Sprintf("receivedDateTime ge %s", start))
+	if query.FromName != "" {
+		filter = append(filter, fmt.Sprintf("contains(from/emailAddress/name, '%s')", escapeFilterString(query.FromName)))
 	}
-
-	if len(filter) == 0 {
-		return nil, fmt.Errorf("at least one of subject, from_address, or from_name must be provided")
+	if query.HasAttachments {
+		filter = append(filter, "hasAttachments eq true")
 	}
-
-	if folderID != "" {
-		result, err = client.Me().MailFolders().ByMailFolderId(folderID).Messages().Get(ctx, &users.ItemMailFoldersItemMessagesRequestBuilderGetRequestConfiguration{
-			QueryParameters: &users.ItemMailFoldersItemMessagesRequestBuilderGetQueryParameters{
-				Orderby: []string{"receivedDateTime DESC"},
-				Filter:  util.Ptr(strings.Join(filter, " and ")),
-				Top:     util.Ptr(int32(limit)),
-			},
-		})
-	} else {
-		result, err = client.Me().Messages().Get(ctx, &users.ItemMessagesRequestBuilderGetRequestConfiguration{
-			QueryParameters: &users.ItemMessagesRequestBuilderGetQueryParameters{
-				Orderby: []string{"receivedDateTime DESC"},
-				Filter:  util.Ptr(strings.Join(filter, " and ")),
-				Top:     util.Ptr(int32(limit)),
-			},
-		})
+	if query.UnreadOnly {
+		filter = append(filter, "isRead eq false")
 	}
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to search messages: %w", err)
+	if query.Importance != nil {
+		filter = append(filter, fmt.Sprintf("importance eq '%s'", query.Importance.String()))
+	}
+	if query.Classification != nil {
+		filter = append(filter, classificationFilter(*query.Classification))
 	}
 
-	return result.GetValue(), nil
+	return strings.Join(filter, " and ")
+}
+
+// escapeFilterString escapes a string for a quoted literal in an OData filter
+func escapeFilterString(s string) string {
+	return strings.ReplaceAll(s, "'", "''")
 }
 
 type DraftInfo struct {
 	Subject, Body       string
-	Recipients, CC, BCC []string // slice of email addresses
-	Attachments         []string // slice of workspace file paths
+	Recipients, CC, BCC []string         // slice of email addresses
+	Attachments         []string         // slice of workspace file paths
+	Files               []AttachmentFile // attachments that are not in the workspace, e.g. generated reports
+	Mailbox             string           // address of a shared or delegated mailbox, empty for the user's own mailbox
+	Importance          *models.Importance
+	Sensitivity         *models.Sensitivity // only normal and confidential are supported
+	Mentions            []string            // email addresses of the people @-mentioned in the body
+	AppendSignature     bool                // append the user's signature, as detected by GetSignature, to the body
+}
+
+// AttachmentFile is the name and content of a file to attach.
+type AttachmentFile struct {
+	Name string
+	Data []byte
 }
 
 var (
@@ -138,13 +334,19 @@ func CreateDraft(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, i
 	requestBody := models.NewMessage()
 	requestBody.SetIsDraft(util.Ptr(true))
 	requestBody.SetSubject(util.Ptr(info.Subject))
-	requestBody.SetToRecipients(emailAddressesToRecipientable(info.Recipients))
+	// Like Outlook, people that are mentioned become recipients if they aren't already
+	requestBody.SetToRecipients(emailAddressesToRecipientable(mentionedRecipients(info.Recipients, info.CC, info.Mentions)))
 
 	for _, file := range info.Attachments {
 		if file == "" {
 			return nil, fmt.Errorf("attachment file path cannot be empty")
 		}
 	}
+	for _, file := range info.Files {
+		if file.Name == "" {
+			return nil, fmt.Errorf("attachment file name cannot be empty")
+		}
+	}
 
 	if len(info.CC) > 0 {
 		requestBody.SetCcRecipients(emailAddressesToRecipientable(info.CC))
@@ -154,21 +356,34 @@ func CreateDraft(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, i
 		requestBody.SetBccRecipients(emailAddressesToRecipientable(info.BCC))
 	}
 
-	body := models.NewItemBody()
-	body.SetContentType(util.Ptr(models.HTML_BODYTYPE))
-
-	bodyHTML := string(markdown.Render(mdParser.Parse([]byte(info.Body)), mdRenderer))
-	body.SetContent(util.Ptr(bodyHTML))
+	if info.AppendSignature {
+		signature, err := GetSignature(ctx, client, info.Mailbox)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get signature: %w", err)
+		}
+		info.Body = appendSignature(info.Body, signature)
+	}
 
-	requestBody.SetBody(body)
+	requestBody.SetBody(htmlBody(info.Body))
+	if err := setImportanceAndSensitivity(requestBody, info); err != nil {
+		return nil, err
+	}
 
-	draft, err := client.Me().Messages().Post(ctx, requestBody, nil)
+	var (
+		draft models.Messageable
+		err   error
+	)
+	if len(info.Mentions) > 0 {
+		draft, err = postMessageBeta(ctx, client, info.Mailbox, requestBody, info.Mentions)
+	} else {
+		draft, err = mailbox(client, info.Mailbox).Messages().Post(ctx, requestBody, nil)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to create draft message: %w", err)
 	}
 
-	if len(info.Attachments) > 0 {
-		if err := attachFiles(ctx, client, util.Deref(draft.GetId()), info.Attachments); err != nil {
+	if len(info.Attachments) > 0 || len(info.Files) > 0 {
+		if err := attachFiles(ctx, client, info.Mailbox, util.Deref(draft.GetId()), info.Attachments, info.Files); err != nil {
 			return nil, fmt.Errorf("failed to attach files to draft: %w", err)
 		}
 	}
@@ -176,6 +391,191 @@ func CreateDraft(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, i
 	return draft, nil
 }
 
+// mentionedRecipients returns the recipients with the mentioned addresses added that aren't recipients or CC yet
+func mentionedRecipients(recipients, cc, mentions []string) []string {
+	result := slices.Clone(recipients)
+	for _, mention := range mentions {
+		if !slices.ContainsFunc(slices.Concat(result, cc), func(address string) bool {
+			return strings.EqualFold(strings.TrimSpace(address), mention)
+		}) {
+			result = append(result, mention)
+		}
+	}
+	return result
+}
+
+// mentionsJSON adds the mentions collection to a message in JSON format. Mentions are only available in the beta API,
+// so the v1.0 model has no property for them.
+func mentionsJSON(content []byte, addresses []string) ([]byte, error) {
+	var message map[string]any
+	if err := json.Unmarshal(content, &message); err != nil {
+		return nil, err
+	}
+
+	mentions := make([]map[string]any, 0, len(addresses))
+	for _, address := range addresses {
+		mentions = append(mentions, map[string]any{
+			"mentioned": map[string]string{"address": address},
+		})
+	}
+	message["mentions"] = mentions
+
+	return json.Marshal(message)
+}
+
+// postMessageBeta creates the message with mentions of the email addresses, using the beta API
+func postMessageBeta(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress string, message models.Messageable, mentions []string) (models.Messageable, error) {
+	writer, err := client.BaseRequestBuilder.RequestAdapter.GetSerializationWriterFactory().GetSerializationWriter("application/json")
+	if err != nil {
+		return nil, err
+	}
+	defer writer.Close()
+	if err := writer.WriteObjectValue("", message); err != nil {
+		return nil, fmt.Errorf("failed to serialize message: %w", err)
+	}
+	content, err := writer.GetSerializedContent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize message: %w", err)
+	}
+	if content, err = mentionsJSON(content, mentions); err != nil {
+		return nil, fmt.Errorf("failed to add mentions to message: %w", err)
+	}
+
+	result, err := postBeta(ctx, client, betaUser(mailboxAddress)+"/messages", content, models.CreateMessageFromDiscriminatorValue)
+	if err != nil {
+		return nil, err
+	}
+	created, ok := result.(models.Messageable)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response to creating a message")
+	}
+	return created, nil
+}
+
+// UpdateDraft changes the subject, body, recipients, importance and sensitivity of a draft. Empty fields of the info
+// are kept, attachments are not changed. Recipient lists that are empty but not nil are cleared.
+func UpdateDraft(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, draftID string, info DraftInfo) (models.Messageable, error) {
+	requestBody := models.NewMessage()
+	if info.Subject != "" {
+		requestBody.SetSubject(util.Ptr(info.Subject))
+	}
+	if info.Body != "" {
+		requestBody.SetBody(htmlBody(info.Body))
+	}
+	if info.Recipients != nil {
+		requestBody.SetToRecipients(recipientsOrEmpty(info.Recipients))
+	}
+	if info.CC != nil {
+		requestBody.SetCcRecipients(recipientsOrEmpty(info.CC))
+	}
+	if info.BCC != nil {
+		requestBody.SetBccRecipients(recipientsOrEmpty(info.BCC))
+	}
+	if err := setImportanceAndSensitivity(requestBody, info); err != nil {
+		return nil, err
+	}
+
+	draft, err := mailbox(client, info.Mailbox).Messages().ByMessageId(draftID).Patch(ctx, requestBody, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update draft message: %w", err)
+	}
+
+	return draft, nil
+}
+
+// sensitivityPropertyID is the MAPI property of the sensitivity of a message (PidTagSensitivity), which Graph doesn't
+// expose as a property of messages
+const sensitivityPropertyID = "Integer 0x0036"
+
+// sensitivityValues are the values of PidTagSensitivity
+var sensitivityValues = map[models.Sensitivity]string{
+	models.NORMAL_SENSITIVITY:       "0",
+	models.CONFIDENTIAL_SENSITIVITY: "3",
+}
+
+// setImportanceAndSensitivity sets the importance and sensitivity of the info on the message, if they are set
+func setImportanceAndSensitivity(message models.Messageable, info DraftInfo) error {
+	if info.Importance != nil {
+		message.SetImportance(info.Importance)
+	}
+	if info.Sensitivity != nil {
+		value, ok := sensitivityValues[*info.Sensitivity]
+		if !ok {
+			return fmt.Errorf("unsupported sensitivity %s, must be normal or confidential", info.Sensitivity.String())
+		}
+		property := models.NewSingleValueLegacyExtendedProperty()
+		property.SetId(util.Ptr(sensitivityPropertyID))
+		property.SetValue(util.Ptr(value))
+		message.SetSingleValueExtendedProperties([]models.SingleValueLegacyExtendedPropertyable{property})
+	}
+	return nil
+}
+
+// ParseImportance parses the importance of a message: low, normal or high
+func ParseImportance(importance string) (*models.Importance, error) {
+	parsed, err := models.ParseImportance(strings.ToLower(strings.TrimSpace(importance)))
+	if err != nil || parsed == nil {
+		return nil, fmt.Errorf("invalid importance %q, must be low, normal or high", importance)
+	}
+	return parsed.(*models.Importance), nil
+}
+
+// ParseSensitivity parses the sensitivity of a message: normal or confidential
+func ParseSensitivity(sensitivity string) (*models.Sensitivity, error) {
+	parsed, err := models.ParseSensitivity(strings.ToLower(strings.TrimSpace(sensitivity)))
+	if err != nil || parsed == nil {
+		return nil, fmt.Errorf("invalid sensitivity %q, must be normal or confidential", sensitivity)
+	}
+	if _, ok := sensitivityValues[*parsed.(*models.Sensitivity)]; !ok {
+		return nil, fmt.Errorf("invalid sensitivity %q, must be normal or confidential", sensitivity)
+	}
+	return parsed.(*models.Sensitivity), nil
+}
+
+// AddAttachmentsToDraft attaches files from the workspace to an existing draft. Large files are uploaded in chunks.
+func AddAttachmentsToDraft(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress, draftID string, paths []string) error {
+	for _, file := range paths {
+		if file == "" {
+			return fmt.Errorf("attachment file path cannot be empty")
+		}
+	}
+
+	message, err := mailbox(client, mailboxAddress).Messages().ByMessageId(draftID).Get(ctx, &users.ItemMessagesMessageItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.ItemMessagesMessageItemRequestBuilderGetQueryParameters{
+			Select: []string{"id", "isDraft"},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get draft: %w", err)
+	}
+	if !util.Deref(message.GetIsDraft()) {
+		return fmt.Errorf("message %s is not a draft, attachments can only be added to drafts", draftID)
+	}
+
+	if err := attachFiles(ctx, client, mailboxAddress, draftID, paths, nil); err != nil {
+		return fmt.Errorf("failed to attach files to draft: %w", err)
+	}
+
+	return nil
+}
+
+// htmlBody renders the markdown as the HTML body of a message
+func htmlBody(md string) models.ItemBodyable {
+	body := models.NewItemBody()
+	body.SetContentType(util.Ptr(models.HTML_BODYTYPE))
+	body.SetContent(util.Ptr(string(markdown.Render(mdParser.Parse([]byte(md)), mdRenderer))))
+	return body
+}
+
+// recipientsOrEmpty is like emailAddressesToRecipientable, but returns an empty list instead of nil, so that the
+// recipients are cleared rather than left out of a patch.
+func recipientsOrEmpty(addresses []string) []models.Recipientable {
+	if recipients := emailAddressesToRecipientable(addresses); recipients != nil {
+		return recipients
+	}
+	return []models.Recipientable{}
+}
+
 func emailAddressesToRecipientable(addresses []string) []models.Recipientable {
 	var recipients []models.Recipientable
 	for _, address := range addresses {
@@ -188,7 +588,7 @@ func emailAddressesToRecipientable(addresses []string) []models.Recipientable {
 	return recipients
 }
 
-func attachFiles(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, draftID string, files []string) error {
+func attachFiles(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress, draftID string, paths []string, files []AttachmentFile) error {
 	gsClient, err := gptscript.NewGPTScript()
 	if err != nil {
 		return fmt.Errorf("failed to create GPTScript client: %w", err)
@@ -198,30 +598,70 @@ func attachFiles(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, d
 	uploadCtx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Minute*5))
 	defer cancel()
 
-	// Note: While it's tempting to paralleize attachment uploads, Microsoft Graph API doesn't
-	// seem to support concurrent upload sessions (returns "change key" errors) or non-sequential
-	// file chunk uploads (returns "invalid start offset" errors).
-	var errs []error
-	for _, file := range files {
+	policy, err := attachments.PolicyFromEnv()
+	if err != nil {
+		return fmt.Errorf("failed to load attachment policy: %w", err)
+	}
+
+	// Read and check all files before uploading anything, so a policy violation doesn't leave a partially attached draft
+	all := make([]AttachmentFile, 0, len(paths)+len(files))
+	for _, file := range paths {
 		// Read the file from the workspace
 		data, err := gsClient.ReadFileInWorkspace(uploadCtx, filepath.Join("files", file))
 		if err != nil {
 			return fmt.Errorf("failed to read attachment file %s from workspace: %v", file, err)
 		}
+		all = append(all, AttachmentFile{Name: file, Data: data})
+	}
+	all = append(all, files...)
 
-		if len(data) < 1 {
-			return fmt.Errorf("cannot attach empty file %s", file)
+	for _, file := range all {
+		if len(file.Data) < 1 {
+			return fmt.Errorf("cannot attach empty file %s", file.Name)
 		}
 
-		errs = append(errs, uploadFile(uploadCtx, client, draftID, file, data))
+		if err := policy.Check(uploadCtx, file.Name, file.Data); err != nil {
+			return err
+		}
+	}
+
+	// Note: While it's tempting to paralleize attachment uploads, Microsoft Graph API doesn't
+	// seem to support concurrent upload sessions (returns "change key" errors) or non-sequential
+	// file chunk uploads (returns "invalid start offset" errors).
+	var errs []error
+	for _, file := range all {
+		if len(file.Data) < uploadSessionThreshold {
+			errs = append(errs, attachFile(uploadCtx, client, mailboxAddress, draftID, file.Name, file.Data))
+		} else {
+			errs = append(errs, uploadFile(uploadCtx, client, mailboxAddress, draftID, file.Name, file.Data))
+		}
 	}
 
 	return errors.Join(errs...)
 }
 
+// uploadSessionThreshold is the size from which files are uploaded in chunks. Graph only accepts smaller
+// files in a single request.
+const uploadSessionThreshold = 3 * 1024 * 1024 // 3MB
+
+func attachFile(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress, draftID string, file string, data []byte) error {
+	attachment := models.NewFileAttachment()
+	attachment.SetName(util.Ptr(filepath.Base(file)))
+	attachment.SetContentBytes(data)
+	if contentType := mime.TypeByExtension(filepath.Ext(file)); contentType != "" {
+		attachment.SetContentType(util.Ptr(contentType))
+	}
+
+	if _, err := mailbox(client, mailboxAddress).Messages().ByMessageId(draftID).Attachments().Post(ctx, attachment, nil); err != nil {
+		return fmt.Errorf("failed to attach file %s: %v", file, err)
+	}
+
+	return nil
+}
+
 const uploadChunkSize = 1024 * 1024 // 1MB
 
-func uploadFile(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, draftID string, file string, data []byte) error {
+func uploadFile(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress, draftID string, file string, data []byte) error {
 	// Prepare attachment info
 	attachment := models.NewAttachmentItem()
 	attachment.SetAttachmentType(util.Ptr(models.FILE_ATTACHMENTTYPE))
@@ -235,7 +675,7 @@ func uploadFile(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, dr
 	requestBody.SetAdditionalData(map[string]any{"@microsoft.graph.conflictBehavior": "replace"})
 
 	// Create the upload session
-	session, err := client.Me().Messages().ByMessageId(draftID).Attachments().CreateUploadSession().Post(ctx, requestBody, nil)
+	session, err := mailbox(client, mailboxAddress).Messages().ByMessageId(draftID).Attachments().CreateUploadSession().Post(ctx, requestBody, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create upload session for file %s: %v", file, err)
 	}
@@ -280,16 +720,60 @@ func uploadFile(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, dr
 	return nil
 }
 
-func SendDraft(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, draftID string) error {
-	if err := client.Me().Messages().ByMessageId(draftID).Send().Post(ctx, nil); err != nil {
+func SendDraft(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress, draftID string) error {
+	if err := mailbox(client, mailboxAddress).Messages().ByMessageId(draftID).Send().Post(ctx, nil); err != nil {
 		return fmt.Errorf("failed to send draft: %w", err)
 	}
 
 	return nil
 }
 
-func DeleteMessage(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, messageID string) error {
-	folders, err := ListMailFolders(ctx, client)
+// FlagMessage sets the follow-up flag of a message. A due date is only set for flagged messages, starting now.
+func FlagMessage(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress, messageID string, status models.FollowupFlagStatus, due *time.Time) (models.Messageable, error) {
+	flag := models.NewFollowupFlag()
+	flag.SetFlagStatus(util.Ptr(status))
+	if status == models.FLAGGED_FOLLOWUPFLAGSTATUS && due != nil {
+		// Graph requires a start date together with the due date
+		flag.SetStartDateTime(utcDateTimeTimeZone(time.Now()))
+		flag.SetDueDateTime(utcDateTimeTimeZone(*due))
+	}
+
+	requestBody := models.NewMessage()
+	requestBody.SetFlag(flag)
+
+	message, err := mailbox(client, mailboxAddress).Messages().ByMessageId(messageID).Patch(ctx, requestBody, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to flag message: %w", err)
+	}
+
+	return message, nil
+}
+
+func utcDateTimeTimeZone(t time.Time) models.DateTimeTimeZoneable {
+	dt := models.NewDateTimeTimeZone()
+	dt.SetDateTime(util.Ptr(t.UTC().Format("2006-01-02T15:04:05")))
+	dt.SetTimeZone(util.Ptr("UTC"))
+	return dt
+}
+
+// ForwardMessage forwards a message with its attachments to the recipients, with an optional comment above the
+// original message.
+func ForwardMessage(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress, messageID string, recipients []string, comment string) error {
+	requestBody := users.NewItemMessagesItemForwardPostRequestBody()
+	requestBody.SetToRecipients(emailAddressesToRecipientable(recipients))
+	if comment != "" {
+		requestBody.SetComment(util.Ptr(comment))
+	}
+
+	if err := mailbox(client, mailboxAddress).Messages().ByMessageId(messageID).Forward().Post(ctx, requestBody, nil); err != nil {
+		return fmt.Errorf("failed to forward message: %w", err)
+	}
+
+	return nil
+}
+
+func DeleteMessage(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress, messageID string) error {
+	folders, err := ListMailFolders(ctx, client, mailboxAddress)
 	if err != nil {
 		return fmt.Errorf("failed to list mail folders: %w", err)
 	}
@@ -299,7 +783,7 @@ func DeleteMessage(ctx context.Context, client *msgraphsdkgo.GraphServiceClient,
 			continue
 		}
 
-		if _, err := MoveMessage(ctx, client, messageID, util.Deref(folder.GetId())); err != nil {
+		if _, err := MoveMessage(ctx, client, mailboxAddress, messageID, util.Deref(folder.GetId())); err != nil {
 			return fmt.Errorf("failed to move message to Deleted Items: %w", err)
 		}
 		return nil
@@ -308,14 +792,76 @@ func DeleteMessage(ctx context.Context, client *msgraphsdkgo.GraphServiceClient,
 	return fmt.Errorf("failed to find Deleted Items folder")
 }
 
-func MoveMessage(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, messageID, destinationFolderID string) (models.Messageable, error) {
+func MoveMessage(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress, messageID, destinationFolderID string) (models.Messageable, error) {
 	requestBody := users.NewItemMessagesItemMovePostRequestBody()
 	requestBody.SetDestinationId(util.Ptr(destinationFolderID))
 
-	message, err := client.Me().Messages().ByMessageId(messageID).Move().Post(ctx, requestBody, nil)
+	message, err := mailbox(client, mailboxAddress).Messages().ByMessageId(messageID).Move().Post(ctx, requestBody, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to move message: %w", err)
 	}
 
 	return message, nil
 }
+
+// MarkMessagesRead marks the messages as read or unread using JSON batches, and returns the error for each message,
+// which is nil if the message was marked.
+func MarkMessagesRead(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress string, messageIDs []string, read bool) ([]error, error) {
+	requests := make([]*abstractions.RequestInformation, 0, len(messageIDs))
+	for _, messageID := range messageIDs {
+		requestBody := models.NewMessage()
+		requestBody.SetIsRead(util.Ptr(read))
+
+		requestInfo, err := mailbox(client, mailboxAddress).Messages().ByMessageId(messageID).ToPatchRequestInformation(ctx, requestBody, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build update request: %w", err)
+		}
+		requests = append(requests, requestInfo)
+	}
+
+	responses, err := batch.SendAll(ctx, client, requests)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark messages: %w", err)
+	}
+
+	return util.Map(responses, func(response batch.Result) error {
+		return response.Err
+	}), nil
+}
+
+// MoveResult is the result of moving one of the messages of a bulk move.
+type MoveResult struct {
+	MessageID    string
+	NewMessageID string
+	Err          error
+}
+
+// MoveMessages moves the messages to the destination folder using JSON batches, and returns the result for each
+// message. The destination can also be a well-known folder name, like deleteditems.
+func MoveMessages(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress string, messageIDs []string, destinationFolderID string) ([]MoveResult, error) {
+	requests := make([]*abstractions.RequestInformation, 0, len(messageIDs))
+	for _, messageID := range messageIDs {
+		requestBody := users.NewItemMessagesItemMovePostRequestBody()
+		requestBody.SetDestinationId(util.Ptr(destinationFolderID))
+
+		requestInfo, err := mailbox(client, mailboxAddress).Messages().ByMessageId(messageID).Move().ToPostRequestInformation(ctx, requestBody, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build move request: %w", err)
+		}
+		requests = append(requests, requestInfo)
+	}
+
+	responses, err := batch.SendAll(ctx, client, requests)
+	if err != nil {
+		return nil, fmt.Errorf("failed to move messages: %w", err)
+	}
+
+	results := make([]MoveResult, len(messageIDs))
+	for i, response := range responses {
+		results[i] = MoveResult{MessageID: messageIDs[i], Err: response.Err}
+		if response.Err == nil {
+			results[i].NewMessageID, _ = response.Body["id"].(string)
+		}
+	}
+	return results, nil
+}
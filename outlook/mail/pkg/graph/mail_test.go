@@ -0,0 +1,149 @@
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/util"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchFilter(t *testing.T) {
+	now := time.Date(2024, 11, 15, 12, 0, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		name  string
+		query SearchQuery
+		want  string
+	}{
+		{
+			name:  "subject",
+			query: SearchQuery{Subject: "Bob's report"},
+			want:  "receivedDateTime le 2024-11-16T12:00:00Z and contains(subject, 'Bob''s report')",
+		},
+		{
+			name:  "time frame",
+			query: SearchQuery{Start: "2024-11-01T00:00:00Z", End: "2024-11-08T00:00:00Z"},
+			want:  "receivedDateTime le 2024-11-08T00:00:00Z and receivedDateTime ge 2024-11-01T00:00:00Z",
+		},
+		{
+			name: "sender and flags",
+			query: SearchQuery{
+				FromAddress:    "alice@example.com",
+				FromName:       "Alice",
+				HasAttachments: true,
+				UnreadOnly:     true,
+				Importance:     util.Ptr(models.HIGH_IMPORTANCE),
+			},
+			want: "receivedDateTime le 2024-11-16T12:00:00Z and contains(from/emailAddress/address, 'alice@example.com') and " +
+				"contains(from/emailAddress/name, 'Alice') and hasAttachments eq true and isRead eq false and importance eq 'high'",
+		},
+		{
+			name:  "focused",
+			query: SearchQuery{Subject: "report", Classification: util.Ptr(models.FOCUSED_INFERENCECLASSIFICATIONTYPE)},
+			want:  "receivedDateTime le 2024-11-16T12:00:00Z and contains(subject, 'report') and inferenceClassification eq 'focused'",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, searchFilter(tc.query, now))
+		})
+	}
+}
+
+func TestListFilter(t *testing.T) {
+	now := time.Date(2024, 11, 15, 12, 0, 0, 0, time.UTC)
+	other := util.Ptr(models.OTHER_INFERENCECLASSIFICATIONTYPE)
+
+	assert.Empty(t, listFilter("", "", nil, now))
+	assert.Equal(t, "receivedDateTime ge 2024-11-01T00:00:00Z and receivedDateTime le 2024-11-08T00:00:00Z",
+		listFilter("2024-11-01T00:00:00Z", "2024-11-08T00:00:00Z", nil, now))
+	assert.Equal(t, "receivedDateTime ge 2024-11-01T00:00:00Z and inferenceClassification eq 'other'",
+		listFilter("2024-11-01T00:00:00Z", "", other, now))
+	// The filter has to start with receivedDateTime, as the messages are ordered by it
+	assert.Equal(t, "receivedDateTime le 2024-11-16T12:00:00Z and inferenceClassification eq 'other'",
+		listFilter("", "", other, now))
+}
+
+func TestSetImportanceAndSensitivity(t *testing.T) {
+	message := models.NewMessage()
+	require.NoError(t, setImportanceAndSensitivity(message, DraftInfo{}))
+	assert.Nil(t, message.GetImportance())
+	assert.Empty(t, message.GetSingleValueExtendedProperties())
+
+	require.NoError(t, setImportanceAndSensitivity(message, DraftInfo{
+		Importance:  util.Ptr(models.HIGH_IMPORTANCE),
+		Sensitivity: util.Ptr(models.CONFIDENTIAL_SENSITIVITY),
+	}))
+	assert.Equal(t, models.HIGH_IMPORTANCE, util.Deref(message.GetImportance()))
+	require.Len(t, message.GetSingleValueExtendedProperties(), 1)
+	assert.Equal(t, "Integer 0x0036", util.Deref(message.GetSingleValueExtendedProperties()[0].GetId()))
+	assert.Equal(t, "3", util.Deref(message.GetSingleValueExtendedProperties()[0].GetValue()))
+
+	assert.Error(t, setImportanceAndSensitivity(message, DraftInfo{Sensitivity: util.Ptr(models.PRIVATE_SENSITIVITY)}))
+}
+
+func TestParseImportanceAndSensitivity(t *testing.T) {
+	importance, err := ParseImportance(" High")
+	require.NoError(t, err)
+	assert.Equal(t, models.HIGH_IMPORTANCE, *importance)
+	_, err = ParseImportance("urgent")
+	assert.Error(t, err)
+
+	sensitivity, err := ParseSensitivity("Confidential")
+	require.NoError(t, err)
+	assert.Equal(t, models.CONFIDENTIAL_SENSITIVITY, *sensitivity)
+	for _, value := range []string{"private", "secret"} {
+		_, err = ParseSensitivity(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestMentionedRecipients(t *testing.T) {
+	assert.Equal(t, []string{"alice@example.com", "carol@example.com"},
+		mentionedRecipients([]string{"alice@example.com"}, []string{"bob@example.com"}, []string{"Alice@Example.com", "bob@example.com", "carol@example.com"}))
+	assert.Equal(t, []string{"alice@example.com"}, mentionedRecipients([]string{"alice@example.com"}, nil, nil))
+}
+
+func TestMentionsJSON(t *testing.T) {
+	content, err := mentionsJSON([]byte(`{"subject":"Review","isDraft":true}`), []string{"alice@example.com", "bob@example.com"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"subject": "Review",
+		"isDraft": true,
+		"mentions": [
+			{"mentioned": {"address": "alice@example.com"}},
+			{"mentioned": {"address": "bob@example.com"}}
+		]
+	}`, string(content))
+
+	_, err = mentionsJSON([]byte("not json"), []string{"alice@example.com"})
+	assert.Error(t, err)
+}
+
+func TestRecipientsOrEmpty(t *testing.T) {
+	recipients := recipientsOrEmpty(nil)
+	assert.NotNil(t, recipients, "an empty list clears the recipients of a draft")
+	assert.Empty(t, recipients)
+
+	recipients = recipientsOrEmpty([]string{"alice@example.com", "bob@example.com"})
+	require.Len(t, recipients, 2)
+	assert.Equal(t, "alice@example.com", util.Deref(recipients[0].GetEmailAddress().GetAddress()))
+	assert.Equal(t, "bob@example.com", util.Deref(recipients[1].GetEmailAddress().GetAddress()))
+}
+
+func TestHTMLBody(t *testing.T) {
+	body := htmlBody("Hello **Bob**")
+	assert.Equal(t, models.HTML_BODYTYPE, util.Deref(body.GetContentType()))
+	assert.Contains(t, util.Deref(body.GetContent()), "<p>Hello <strong>Bob</strong></p>")
+}
+
+func TestUTCDateTimeTimeZone(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	dt := utcDateTimeTimeZone(time.Date(2024, 11, 8, 17, 0, 0, 0, berlin))
+	assert.Equal(t, "2024-11-08T16:00:00", util.Deref(dt.GetDateTime()))
+	assert.Equal(t, "UTC", util.Deref(dt.GetTimeZone()))
+}
@@ -0,0 +1,18 @@
+package graph
+
+import (
+	"strings"
+
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+// mailbox returns the request builder of the mailbox with the given address, or of the signed-in user's own mailbox
+// if it is empty. Other mailboxes, like shared mailboxes or mailboxes the user is a delegate of, need the .Shared
+// scopes and the user must have been granted access to them.
+func mailbox(client *msgraphsdkgo.GraphServiceClient, address string) *users.UserItemRequestBuilder {
+	if address = strings.TrimSpace(address); address == "" {
+		return client.Me()
+	}
+	return client.Users().ByUserId(address)
+}
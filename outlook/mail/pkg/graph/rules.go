@@ -0,0 +1,138 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/util"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// RuleInfo describes an inbox rule. A message has to match all conditions, and every action is applied to it.
+type RuleInfo struct {
+	Name string
+
+	// Conditions
+	FromAddresses   []string
+	SenderContains  []string
+	SubjectContains []string
+	BodyContains    []string
+	HasAttachments  bool
+
+	// Actions
+	MoveToFolderID      string
+	CopyToFolderID      string
+	Categories          []string
+	MarkAsRead          bool
+	Delete              bool
+	StopProcessingRules bool
+}
+
+func ListRules(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress string) ([]models.MessageRuleable, error) {
+	result, err := mailbox(client, mailboxAddress).MailFolders().ByMailFolderId("inbox").MessageRules().Get(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list inbox rules: %w", err)
+	}
+
+	return result.GetValue(), nil
+}
+
+// CreateRule creates an enabled inbox rule that runs after the existing rules.
+func CreateRule(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress string, info RuleInfo) (models.MessageRuleable, error) {
+	sequence, err := nextRuleSequence(ctx, client, mailboxAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	conditions := models.NewMessageRulePredicates()
+	if len(info.FromAddresses) > 0 {
+		conditions.SetFromAddresses(emailAddressesToRecipientable(info.FromAddresses))
+	}
+	if len(info.SenderContains) > 0 {
+		conditions.SetSenderContains(info.SenderContains)
+	}
+	if len(info.SubjectContains) > 0 {
+		conditions.SetSubjectContains(info.SubjectContains)
+	}
+	if len(info.BodyContains) > 0 {
+		conditions.SetBodyContains(info.BodyContains)
+	}
+	if info.HasAttachments {
+		conditions.SetHasAttachments(util.Ptr(true))
+	}
+
+	actions := models.NewMessageRuleActions()
+	if info.MoveToFolderID != "" {
+		folderID, err := ruleFolderID(ctx, client, mailboxAddress, info.MoveToFolderID)
+		if err != nil {
+			return nil, err
+		}
+		actions.SetMoveToFolder(util.Ptr(folderID))
+	}
+	if info.CopyToFolderID != "" {
+		folderID, err := ruleFolderID(ctx, client, mailboxAddress, info.CopyToFolderID)
+		if err != nil {
+			return nil, err
+		}
+		actions.SetCopyToFolder(util.Ptr(folderID))
+	}
+	if len(info.Categories) > 0 {
+		actions.SetAssignCategories(info.Categories)
+	}
+	if info.MarkAsRead {
+		actions.SetMarkAsRead(util.Ptr(true))
+	}
+	if info.Delete {
+		actions.SetDelete(util.Ptr(true))
+	}
+	if info.StopProcessingRules {
+		actions.SetStopProcessingRules(util.Ptr(true))
+	}
+
+	requestBody := models.NewMessageRule()
+	requestBody.SetDisplayName(util.Ptr(info.Name))
+	requestBody.SetSequence(util.Ptr(sequence))
+	requestBody.SetIsEnabled(util.Ptr(true))
+	requestBody.SetConditions(conditions)
+	requestBody.SetActions(actions)
+
+	rule, err := mailbox(client, mailboxAddress).MailFolders().ByMailFolderId("inbox").MessageRules().Post(ctx, requestBody, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create inbox rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+func DeleteRule(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress, ruleID string) error {
+	if err := mailbox(client, mailboxAddress).MailFolders().ByMailFolderId("inbox").MessageRules().ByMessageRuleId(ruleID).Delete(ctx, nil); err != nil {
+		return fmt.Errorf("failed to delete inbox rule: %w", err)
+	}
+
+	return nil
+}
+
+// ruleFolderID returns the actual ID of a folder. Rules need it, well-known names like "archive" are not accepted.
+func ruleFolderID(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress, folderID string) (string, error) {
+	folder, err := mailbox(client, mailboxAddress).MailFolders().ByMailFolderId(folderID).Get(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get %s folder: %w", folderID, err)
+	}
+
+	return util.Deref(folder.GetId()), nil
+}
+
+// nextRuleSequence returns the sequence number that makes a new rule run after the existing rules
+func nextRuleSequence(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress string) (int32, error) {
+	rules, err := ListRules(ctx, client, mailboxAddress)
+	if err != nil {
+		return 0, err
+	}
+
+	var sequence int32
+	for _, rule := range rules {
+		sequence = max(sequence, util.Deref(rule.GetSequence()))
+	}
+	return sequence + 1, nil
+}
@@ -0,0 +1,116 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/util"
+	abstractions "github.com/microsoft/kiota-abstractions-go"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+const (
+	// signatureSampleSize is the number of sent messages the signature is detected from
+	signatureSampleSize = 10
+	// maxSignatureLines is the number of lines from which a common ending is rather a repeated message than a signature
+	maxSignatureLines = 12
+)
+
+// GetSignature returns the signature the user puts under their messages, or an empty string if none was found. Graph
+// doesn't expose the signatures of Outlook, neither in the mailbox settings nor elsewhere, so the signature is detected
+// as the ending that the most recently sent messages have in common.
+func GetSignature(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, mailboxAddress string) (string, error) {
+	headers := abstractions.NewRequestHeaders()
+	headers.Add("Prefer", `outlook.body-content-type="text"`)
+
+	// "sentitems" is the well-known name of the Sent Items folder
+	result, err := mailbox(client, mailboxAddress).MailFolders().ByMailFolderId("sentitems").Messages().Get(ctx, &users.ItemMailFoldersItemMessagesRequestBuilderGetRequestConfiguration{
+		Headers: headers,
+		QueryParameters: &users.ItemMailFoldersItemMessagesRequestBuilderGetQueryParameters{
+			// uniqueBody leaves out the quoted messages of replies and forwards
+			Select:  []string{"id", "uniqueBody"},
+			Orderby: []string{"sentDateTime DESC"},
+			Top:     util.Ptr(int32(signatureSampleSize)),
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to list sent messages: %w", err)
+	}
+
+	var bodies []string
+	for _, message := range result.GetValue() {
+		if body := message.GetUniqueBody(); body != nil {
+			bodies = append(bodies, util.Deref(body.GetContent()))
+		}
+	}
+	return detectSignature(bodies), nil
+}
+
+// detectSignature returns the ending that the most pairs of the bodies have in common, preferring longer endings if
+// pairs are tied. Endings of more than maxSignatureLines lines are ignored.
+func detectSignature(bodies []string) string {
+	var (
+		counts     = map[string]int{}
+		best       string
+		bestCount  int
+		bestLength int
+	)
+	for i := range bodies {
+		for j := i + 1; j < len(bodies); j++ {
+			suffix := commonSuffix(bodyLines(bodies[i]), bodyLines(bodies[j]))
+			if len(suffix) == 0 || len(suffix) > maxSignatureLines {
+				continue
+			}
+
+			signature := strings.Join(suffix, "\n")
+			counts[signature]++
+			if count := counts[signature]; count > bestCount || (count == bestCount && len(suffix) > bestLength) {
+				best, bestCount, bestLength = signature, count, len(suffix)
+			}
+		}
+	}
+	return best
+}
+
+// bodyLines returns the lines of a plain text body without trailing whitespace and trailing empty lines
+func bodyLines(body string) []string {
+	lines := strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " \t\u00a0")
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// commonSuffix returns the lines both lists end with, without leading empty lines
+func commonSuffix(a, b []string) []string {
+	n := 0
+	for n < len(a) && n < len(b) && a[len(a)-1-n] == b[len(b)-1-n] {
+		n++
+	}
+	suffix := a[len(a)-n:]
+	for len(suffix) > 0 && strings.TrimSpace(suffix[0]) == "" {
+		suffix = suffix[1:]
+	}
+	return suffix
+}
+
+// appendSignature appends the plain text signature to the markdown body, keeping its line breaks
+func appendSignature(body, signature string) string {
+	if signature == "" {
+		return body
+	}
+
+	lines := strings.Split(signature, "\n")
+	for i, line := range lines {
+		// Lines like "--" or "# 1" would be markdown headings or lists
+		if line != "" && strings.ContainsRune(`-#*+>=`, rune(line[0])) {
+			lines[i] = `\` + line
+		}
+	}
+	return strings.TrimRight(body, "\n") + "\n\n" + strings.Join(lines, "  \n")
+}
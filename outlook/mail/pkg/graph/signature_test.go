@@ -0,0 +1,32 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectSignature(t *testing.T) {
+	signature := "Best regards\nAlice Smith\nExample Inc. | +1 555 0100"
+	bodies := []string{
+		"Hi Bob,\r\n\r\nsounds good.\r\n\r\n" + signature + "\r\n\r\n",
+		"Thanks!\n\n" + signature,
+		"Hi team,\nthe report is attached.\n\n" + signature + " \n",
+		"Sent from my phone",
+		"Thanks!\n\nSent from my phone",
+	}
+	assert.Equal(t, signature, detectSignature(bodies))
+
+	assert.Empty(t, detectSignature([]string{"Hi Bob", "See you tomorrow"}))
+	assert.Empty(t, detectSignature(nil))
+}
+
+func TestDetectSignatureIgnoresRepeatedMessages(t *testing.T) {
+	message := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13"
+	assert.Empty(t, detectSignature([]string{message, message}))
+}
+
+func TestAppendSignature(t *testing.T) {
+	assert.Equal(t, "Hello", appendSignature("Hello", ""))
+	assert.Equal(t, "Hello\n\n\\--  \nAlice  \n\\# 1 fan", appendSignature("Hello\n", "--\nAlice\n# 1 fan"))
+}
@@ -0,0 +1,72 @@
+package printers
+
+import (
+	"regexp"
+	"strings"
+
+	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/util"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+var blankLines = regexp.MustCompile(`\n{3,}`)
+
+// BodyToMarkdown converts a message body to markdown. HTML bodies keep their links and images, plain text bodies are
+// returned as they are.
+func BodyToMarkdown(body models.ItemBodyable) (string, error) {
+	if body == nil {
+		return "", nil
+	}
+
+	content := strings.ReplaceAll(util.Deref(body.GetContent()), "\r\n", "\n")
+	if body.GetContentType() != nil && *body.GetContentType() == models.TEXT_BODYTYPE {
+		return strings.TrimSpace(blankLines.ReplaceAllString(content, "\n\n")), nil
+	}
+
+	markdown, err := newBodyConverter().ConvertString(content)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(blankLines.ReplaceAllString(markdown, "\n\n")), nil
+}
+
+func newBodyConverter() *md.Converter {
+	converter := md.NewConverter("", true, nil)
+	converter.Remove("head", "style", "script", "title")
+	converter.Before(func(selec *goquery.Selection) {
+		// Tracking pixels and hidden preheaders are noise
+		selec.Find("img").Each(func(_ int, img *goquery.Selection) {
+			if isTrackingPixel(img) {
+				img.Remove()
+			}
+		})
+		selec.Find("[style]").Each(func(_ int, s *goquery.Selection) {
+			if style := strings.ReplaceAll(strings.ToLower(s.AttrOr("style", "")), " ", ""); strings.Contains(style, "display:none") {
+				s.Remove()
+			}
+		})
+	})
+	converter.AddRules(md.Rule{
+		Filter: []string{"img"},
+		Replacement: func(_ string, selec *goquery.Selection, _ *md.Options) *string {
+			// Inline base64 images would flood the output, so only their description is kept
+			if src := strings.TrimSpace(selec.AttrOr("src", "")); strings.HasPrefix(src, "data:") {
+				alt := strings.TrimSpace(selec.AttrOr("alt", "image"))
+				return md.String("[" + alt + "]")
+			}
+			// Images from the web and inline attachments (cid:) use the default rule
+			return nil
+		},
+	})
+	return converter
+}
+
+func isTrackingPixel(img *goquery.Selection) bool {
+	for _, attr := range []string{"width", "height"} {
+		if v := strings.TrimSuffix(strings.TrimSpace(img.AttrOr(attr, "")), "px"); v == "0" || v == "1" {
+			return true
+		}
+	}
+	return false
+}
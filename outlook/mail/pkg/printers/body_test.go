@@ -0,0 +1,66 @@
+package printers
+
+import (
+	"testing"
+
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/util"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func itemBody(contentType models.BodyType, content string) models.ItemBodyable {
+	body := models.NewItemBody()
+	body.SetContentType(&contentType)
+	body.SetContent(util.Ptr(content))
+	return body
+}
+
+func TestBodyToMarkdown(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		body models.ItemBodyable
+		want string
+	}{
+		{
+			name: "plain text",
+			body: itemBody(models.TEXT_BODYTYPE, "Hi,\r\n\r\n\r\n\r\nsee you <b>soon</b>\r\n"),
+			want: "Hi,\n\nsee you <b>soon</b>",
+		},
+		{
+			name: "links",
+			body: itemBody(models.HTML_BODYTYPE, `<html><head><title>Newsletter</title><style>p { color: red; }</style></head><body><p>Hello <a href="https://example.com">world</a></p></body></html>`),
+			want: "Hello [world](https://example.com)",
+		},
+		{
+			name: "tracking pixel",
+			body: itemBody(models.HTML_BODYTYPE, `<p>Hi</p><img src="https://t.example.com/open.gif" width="1px" height="1px">`),
+			want: "Hi",
+		},
+		{
+			name: "hidden preheader",
+			body: itemBody(models.HTML_BODYTYPE, `<div style="display: none">Preview text</div><p>Hi</p>`),
+			want: "Hi",
+		},
+		{
+			name: "inline image",
+			body: itemBody(models.HTML_BODYTYPE, `<p><img src="data:image/png;base64,iVBORw0KGgo=" alt="logo"></p>`),
+			want: "[logo]",
+		},
+		{
+			name: "image",
+			body: itemBody(models.HTML_BODYTYPE, `<p><img src="https://example.com/chart.png" alt="chart"></p>`),
+			want: "![chart](https://example.com/chart.png)",
+		},
+		{
+			name: "no body",
+			want: "",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := BodyToMarkdown(tc.body)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package printers
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/util"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// authResult is a result of an authentication method in an Authentication-Results header, e.g. spf=pass
+type authResult struct {
+	method, result string
+	// properties are the properties of the result, e.g. smtp.mailfrom=example.com
+	properties []string
+}
+
+// HeadersToString returns the SPF, DKIM and DMARC results and the Received chain of a message, followed by all of its
+// headers. The headers have to be in the order of the message.
+func HeadersToString(headers []models.InternetMessageHeaderable) string {
+	var result strings.Builder
+
+	var (
+		authResults    []authResult
+		receivedSPF    string
+		dkimSignatures []string
+		received       []string
+	)
+	for _, header := range headers {
+		name, value := util.Deref(header.GetName()), unfold(util.Deref(header.GetValue()))
+		switch strings.ToLower(name) {
+		case "authentication-results":
+			// The first header was added by the server that received the message, later ones may come from the sender
+			if authResults == nil {
+				authResults = parseAuthenticationResults(value)
+			}
+		case "received-spf":
+			if receivedSPF == "" {
+				receivedSPF = value
+			}
+		case "dkim-signature":
+			dkimSignatures = append(dkimSignatures, dkimSignature(value))
+		case "received":
+			received = append(received, value)
+		}
+	}
+
+	result.WriteString("Authentication:\n")
+	for _, method := range []string{"spf", "dkim", "dmarc"} {
+		i := slices.IndexFunc(authResults, func(r authResult) bool { return r.method == method })
+		switch {
+		case i >= 0:
+			result.WriteString(fmt.Sprintf("  %s: %s", strings.ToUpper(method), authResults[i].result))
+			if len(authResults[i].properties) > 0 {
+				result.WriteString(fmt.Sprintf(" (%s)", strings.Join(authResults[i].properties, ", ")))
+			}
+			result.WriteString("\n")
+		case method == "spf" && receivedSPF != "":
+			result.WriteString(fmt.Sprintf("  SPF: %s\n", receivedSPF))
+		default:
+			result.WriteString(fmt.Sprintf("  %s: not reported\n", strings.ToUpper(method)))
+		}
+	}
+
+	if len(dkimSignatures) > 0 {
+		result.WriteString("DKIM signatures:\n")
+		for _, signature := range dkimSignatures {
+			result.WriteString(fmt.Sprintf("  %s\n", signature))
+		}
+	}
+
+	if len(received) > 0 {
+		// Every server adds its Received header on top, so the chain starts with the last one
+		result.WriteString("Received chain (first hop first):\n")
+		for i := range received {
+			result.WriteString(fmt.Sprintf("  %d. %s\n", i+1, received[len(received)-1-i]))
+		}
+	}
+
+	result.WriteString("Headers:\n")
+	for _, header := range headers {
+		result.WriteString(fmt.Sprintf("  %s: %s\n", util.Deref(header.GetName()), unfold(util.Deref(header.GetValue()))))
+	}
+
+	return result.String()
+}
+
+// parseAuthenticationResults returns the results of an Authentication-Results header (RFC 8601), without comments
+func parseAuthenticationResults(value string) []authResult {
+	var results []authResult
+	for _, part := range strings.Split(stripComments(value), ";") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+		// The header starts with the ID of the server that added it, which isn't a result
+		method, result, ok := strings.Cut(fields[0], "=")
+		if !ok {
+			continue
+		}
+		r := authResult{method: strings.ToLower(method), result: strings.ToLower(result)}
+		for _, field := range fields[1:] {
+			if strings.Contains(field, "=") {
+				r.properties = append(r.properties, field)
+			}
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// dkimSignature returns the signing domain and selector of a DKIM-Signature header
+func dkimSignature(value string) string {
+	var domain, selector string
+	for _, tag := range strings.Split(value, ";") {
+		name, v, _ := strings.Cut(strings.TrimSpace(tag), "=")
+		switch strings.TrimSpace(name) {
+		case "d":
+			domain = strings.TrimSpace(v)
+		case "s":
+			selector = strings.TrimSpace(v)
+		}
+	}
+	return fmt.Sprintf("d=%s s=%s", domain, selector)
+}
+
+// stripComments removes the comments in parentheses from a header value
+func stripComments(value string) string {
+	var (
+		result strings.Builder
+		depth  int
+	)
+	for _, r := range value {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')' && depth > 0:
+			depth--
+		case depth == 0:
+			result.WriteRune(r)
+		}
+	}
+	return result.String()
+}
+
+// unfold joins the lines of a folded header value
+func unfold(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
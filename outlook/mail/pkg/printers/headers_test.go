@@ -0,0 +1,59 @@
+package printers
+
+import (
+	"testing"
+
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/util"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func header(name, value string) models.InternetMessageHeaderable {
+	h := models.NewInternetMessageHeader()
+	h.SetName(util.Ptr(name))
+	h.SetValue(util.Ptr(value))
+	return h
+}
+
+func TestHeadersToString(t *testing.T) {
+	headers := []models.InternetMessageHeaderable{
+		header("Received", "from mail.example.com (10.0.0.2) by mx.contoso.com\r\n with SMTP; Fri, 1 Nov 2024 09:30:02 +0000"),
+		header("Authentication-Results", "spf=pass (sender IP is 10.0.0.1) smtp.mailfrom=example.com; dkim=pass (signature was verified)\r\n header.d=example.com;dmarc=pass action=none header.from=example.com;compauth=pass reason=100"),
+		header("Authentication-Results", "mail.example.com; spf=fail smtp.mailfrom=example.com"),
+		header("Received", "from laptop (10.0.0.1) by mail.example.com with ESMTPSA; Fri, 1 Nov 2024 09:30:01 +0000"),
+		header("DKIM-Signature", "v=1; a=rsa-sha256; d=example.com; s=selector1;\r\n h=From:Subject; bh=abc=; b=def="),
+		header("Subject", "Quarterly report"),
+	}
+
+	assert.Equal(t, `Authentication:
+  SPF: pass (smtp.mailfrom=example.com)
+  DKIM: pass (header.d=example.com)
+  DMARC: pass (action=none, header.from=example.com)
+DKIM signatures:
+  d=example.com s=selector1
+Received chain (first hop first):
+  1. from laptop (10.0.0.1) by mail.example.com with ESMTPSA; Fri, 1 Nov 2024 09:30:01 +0000
+  2. from mail.example.com (10.0.0.2) by mx.contoso.com with SMTP; Fri, 1 Nov 2024 09:30:02 +0000
+Headers:
+  Received: from mail.example.com (10.0.0.2) by mx.contoso.com with SMTP; Fri, 1 Nov 2024 09:30:02 +0000
+  Authentication-Results: spf=pass (sender IP is 10.0.0.1) smtp.mailfrom=example.com; dkim=pass (signature was verified) header.d=example.com;dmarc=pass action=none header.from=example.com;compauth=pass reason=100
+  Authentication-Results: mail.example.com; spf=fail smtp.mailfrom=example.com
+  Received: from laptop (10.0.0.1) by mail.example.com with ESMTPSA; Fri, 1 Nov 2024 09:30:01 +0000
+  DKIM-Signature: v=1; a=rsa-sha256; d=example.com; s=selector1; h=From:Subject; bh=abc=; b=def=
+  Subject: Quarterly report
+`, HeadersToString(headers))
+}
+
+func TestHeadersToStringWithoutAuthenticationResults(t *testing.T) {
+	headers := []models.InternetMessageHeaderable{
+		header("Received-SPF", "Pass (protection.outlook.com: domain of example.com designates 10.0.0.1 as permitted sender)"),
+	}
+
+	assert.Equal(t, `Authentication:
+  SPF: Pass (protection.outlook.com: domain of example.com designates 10.0.0.1 as permitted sender)
+  DKIM: not reported
+  DMARC: not reported
+Headers:
+  Received-SPF: Pass (protection.outlook.com: domain of example.com designates 10.0.0.1 as permitted sender)
+`, HeadersToString(headers))
+}
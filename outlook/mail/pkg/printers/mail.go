@@ -3,23 +3,25 @@ package printers
 import (
 	"fmt"
 	"strings"
-	"time"
 
-	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/gptscript-ai/tools/common/locale"
 	"github.com/gptscript-ai/tools/outlook/mail/pkg/util"
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
 )
 
 func MailFolderToString(folder models.MailFolderable) (string, error) {
-	var result strings.Builder
+	var (
+		result strings.Builder
+		loc    = locale.FromEnv()
+	)
 
-	result.WriteString(fmt.Sprintf("Name: %s\n", util.Deref(folder.GetDisplayName())))
-	result.WriteString(fmt.Sprintf("ID: %s\n", util.Deref(folder.GetId())))
+	result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Name"), util.Deref(folder.GetDisplayName())))
+	result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("ID"), util.Deref(folder.GetId())))
 	if folder.GetParentFolderId() != nil {
-		result.WriteString(fmt.Sprintf("Parent folder ID: %s\n", util.Deref(folder.GetParentFolderId())))
+		result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Parent folder ID"), util.Deref(folder.GetParentFolderId())))
 	}
-	result.WriteString(fmt.Sprintf("Unread item count: %d\n", util.Deref(folder.GetUnreadItemCount())))
-	result.WriteString(fmt.Sprintf("Total item count: %d\n", util.Deref(folder.GetTotalItemCount())))
+	result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Unread item count"), loc.Int(int64(util.Deref(folder.GetUnreadItemCount())))))
+	result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Total item count"), loc.Int(int64(util.Deref(folder.GetTotalItemCount())))))
 
 	return result.String(), nil
 }
@@ -34,39 +36,195 @@ func PrintMessage(msg models.Messageable, detailed bool) error {
 }
 
 func MessageToString(msg models.Messageable, detailed bool) (string, error) {
-	var result strings.Builder
+	var (
+		result strings.Builder
+		loc    = locale.FromEnv()
+	)
 
-	result.WriteString(fmt.Sprintf("Subject: %s\n", util.Deref(msg.GetSubject())))
-	result.WriteString(fmt.Sprintf("Message ID: %s\n", util.Deref(msg.GetId())))
+	result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Subject"), util.Deref(msg.GetSubject())))
+	result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Message ID"), util.Deref(msg.GetId())))
+	// Properties that weren't selected are left out, see graph.MessageFields
 	if !util.Deref(msg.GetIsDraft()) {
-		result.WriteString(fmt.Sprintf("Sender: %s (email address: %s)\n", util.Deref(msg.GetSender().GetEmailAddress().GetName()), util.Deref(msg.GetSender().GetEmailAddress().GetAddress())))
-		result.WriteString(fmt.Sprintf("Received: %s\n", msg.GetReceivedDateTime().Format(time.RFC3339)))
-	} else {
-		result.WriteString(fmt.Sprintf("Created: %s\n", msg.GetReceivedDateTime().Format(time.RFC3339)))
+		if sender := msg.GetSender(); sender != nil && sender.GetEmailAddress() != nil {
+			result.WriteString(fmt.Sprintf("%s: %s (%s: %s)\n", loc.T("Sender"), util.Deref(sender.GetEmailAddress().GetName()), loc.T("email address"), util.Deref(sender.GetEmailAddress().GetAddress())))
+		}
+		if received := msg.GetReceivedDateTime(); received != nil {
+			result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Received"), loc.DateTime(*received)))
+		}
+	} else if received := msg.GetReceivedDateTime(); received != nil {
+		result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Created"), loc.DateTime(*received)))
+	}
+	if isRead := msg.GetIsRead(); isRead != nil {
+		result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Is unread"), loc.Bool(!*isRead)))
+	}
+	if categories := msg.GetCategories(); len(categories) > 0 {
+		result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Categories"), strings.Join(categories, ", ")))
+	}
+	if flag := msg.GetFlag(); flag != nil && flag.GetFlagStatus() != nil && *flag.GetFlagStatus() != models.NOTFLAGGED_FOLLOWUPFLAGSTATUS {
+		result.WriteString(fmt.Sprintf("%s: %s", loc.T("Follow-up flag"), flag.GetFlagStatus().String()))
+		if due := flag.GetDueDateTime(); due != nil && *flag.GetFlagStatus() == models.FLAGGED_FOLLOWUPFLAGSTATUS {
+			result.WriteString(fmt.Sprintf(" (%s: %s %s)", loc.T("due"), util.Deref(due.GetDateTime()), util.Deref(due.GetTimeZone())))
+		}
+		result.WriteString("\n")
+	}
+	if msg.GetWebLink() != nil {
+		result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Link"), util.Deref(msg.GetWebLink())))
 	}
-	result.WriteString(fmt.Sprintf("Is unread: %t\n", !util.Deref(msg.GetIsRead())))
-	result.WriteString(fmt.Sprintf("Link: %s\n", util.Deref(msg.GetWebLink())))
 
 	if detailed {
-		result.WriteString(fmt.Sprintf("To: %s\n", strings.Join(util.Map(msg.GetToRecipients(), recipientableToString), ", ")))
-		result.WriteString(fmt.Sprintf("CC: %s\n", strings.Join(util.Map(msg.GetCcRecipients(), recipientableToString), ", ")))
-		result.WriteString(fmt.Sprintf("Has attachments: %t\n", util.Deref(msg.GetHasAttachments())))
+		result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("To"), strings.Join(util.Map(msg.GetToRecipients(), recipientableToString), ", ")))
+		result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("CC"), strings.Join(util.Map(msg.GetCcRecipients(), recipientableToString), ", ")))
+		result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Has attachments"), loc.Bool(util.Deref(msg.GetHasAttachments()))))
 
-		converter := md.NewConverter("", true, nil)
-		bodyHTML := util.Deref(msg.GetBody().GetContent())
-		bodyMarkdown, err := converter.ConvertString(bodyHTML)
+		bodyMarkdown, err := BodyToMarkdown(msg.GetBody())
 		if err != nil {
 			return "", fmt.Errorf("failed to convert email body HTML to markdown: %w", err)
 		}
 
-		result.WriteString(fmt.Sprintf("Body: %s", strings.ReplaceAll(bodyMarkdown, "\n", "\n  ")))
-	} else {
-		result.WriteString(fmt.Sprintf("Body preview: %s\n", strings.ReplaceAll(util.Deref(msg.GetBodyPreview()), "\n", "\n  ")))
+		result.WriteString(fmt.Sprintf("%s: %s", loc.T("Body"), strings.ReplaceAll(bodyMarkdown, "\n", "\n  ")))
+	} else if msg.GetBodyPreview() != nil {
+		result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Body preview"), strings.ReplaceAll(util.Deref(msg.GetBodyPreview()), "\n", "\n  ")))
 	}
 
 	return result.String(), nil
 }
 
+// DraftToString prints the fields of a draft that matter while working on it
+func DraftToString(msg models.Messageable) string {
+	var (
+		result strings.Builder
+		loc    = locale.FromEnv()
+	)
+
+	result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Subject"), util.Deref(msg.GetSubject())))
+	result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Draft ID"), util.Deref(msg.GetId())))
+	result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Last modified"), loc.DateTime(util.Deref(msg.GetLastModifiedDateTime()))))
+	result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("To"), strings.Join(util.Map(msg.GetToRecipients(), recipientableToString), ", ")))
+	if cc := msg.GetCcRecipients(); len(cc) > 0 {
+		result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("CC"), strings.Join(util.Map(cc, recipientableToString), ", ")))
+	}
+	if bcc := msg.GetBccRecipients(); len(bcc) > 0 {
+		result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("BCC"), strings.Join(util.Map(bcc, recipientableToString), ", ")))
+	}
+	result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Has attachments"), loc.Bool(util.Deref(msg.GetHasAttachments()))))
+	result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Link"), util.Deref(msg.GetWebLink())))
+	result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Body preview"), strings.ReplaceAll(util.Deref(msg.GetBodyPreview()), "\n", "\n  ")))
+
+	return result.String()
+}
+
 func recipientableToString(r models.Recipientable) string {
 	return fmt.Sprintf("%s (%s)", util.Deref(r.GetEmailAddress().GetName()), util.Deref(r.GetEmailAddress().GetAddress()))
 }
+
+// RuleToString prints an inbox rule. Only the conditions and actions that the rule tools can set are described, along
+// with forwarding, which is always shown so that the user knows where their mail goes.
+func RuleToString(rule models.MessageRuleable) string {
+	var (
+		result     strings.Builder
+		loc        = locale.FromEnv()
+		conditions []string
+		actions    []string
+	)
+
+	if c := rule.GetConditions(); c != nil {
+		if from := c.GetFromAddresses(); len(from) > 0 {
+			conditions = append(conditions, fmt.Sprintf(loc.T("from %s"), strings.Join(util.Map(from, recipientableToString), ", ")))
+		}
+		if senders := c.GetSenderContains(); len(senders) > 0 {
+			conditions = append(conditions, fmt.Sprintf(loc.T("sender contains %s"), quoteAll(loc, senders)))
+		}
+		if subjects := c.GetSubjectContains(); len(subjects) > 0 {
+			conditions = append(conditions, fmt.Sprintf(loc.T("subject contains %s"), quoteAll(loc, subjects)))
+		}
+		if bodies := c.GetBodyContains(); len(bodies) > 0 {
+			conditions = append(conditions, fmt.Sprintf(loc.T("body contains %s"), quoteAll(loc, bodies)))
+		}
+		if util.Deref(c.GetHasAttachments()) {
+			conditions = append(conditions, loc.T("has attachments"))
+		}
+	}
+
+	if a := rule.GetActions(); a != nil {
+		if a.GetMoveToFolder() != nil {
+			actions = append(actions, fmt.Sprintf(loc.T("move to folder %s"), util.Deref(a.GetMoveToFolder())))
+		}
+		if a.GetCopyToFolder() != nil {
+			actions = append(actions, fmt.Sprintf(loc.T("copy to folder %s"), util.Deref(a.GetCopyToFolder())))
+		}
+		if categories := a.GetAssignCategories(); len(categories) > 0 {
+			actions = append(actions, fmt.Sprintf(loc.T("assign categories %s"), strings.Join(categories, ", ")))
+		}
+		if util.Deref(a.GetMarkAsRead()) {
+			actions = append(actions, loc.T("mark as read"))
+		}
+		if util.Deref(a.GetDelete()) {
+			actions = append(actions, loc.T("delete"))
+		}
+		if forwardTo := a.GetForwardTo(); len(forwardTo) > 0 {
+			actions = append(actions, fmt.Sprintf(loc.T("forward to %s"), strings.Join(util.Map(forwardTo, recipientableToString), ", ")))
+		}
+		if redirectTo := a.GetRedirectTo(); len(redirectTo) > 0 {
+			actions = append(actions, fmt.Sprintf(loc.T("redirect to %s"), strings.Join(util.Map(redirectTo, recipientableToString), ", ")))
+		}
+		if util.Deref(a.GetStopProcessingRules()) {
+			actions = append(actions, loc.T("stop processing more rules"))
+		}
+	}
+
+	result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Name"), util.Deref(rule.GetDisplayName())))
+	result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("ID"), util.Deref(rule.GetId())))
+	result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Enabled"), loc.Bool(util.Deref(rule.GetIsEnabled()))))
+	if len(conditions) > 0 {
+		result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Conditions"), strings.Join(conditions, "; ")))
+	}
+	if len(actions) > 0 {
+		result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Actions"), strings.Join(actions, "; ")))
+	}
+
+	return result.String()
+}
+
+func quoteAll(loc locale.Locale, values []string) string {
+	return strings.Join(util.Map(values, func(v string) string {
+		return fmt.Sprintf("%q", v)
+	}), loc.T(" or "))
+}
+
+func AutomaticRepliesToString(setting models.AutomaticRepliesSettingable) (string, error) {
+	var (
+		result    strings.Builder
+		loc       = locale.FromEnv()
+		converter = newBodyConverter()
+	)
+
+	status := models.DISABLED_AUTOMATICREPLIESSTATUS
+	if setting.GetStatus() != nil {
+		status = *setting.GetStatus()
+	}
+	result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Status"), status.String()))
+	if status == models.SCHEDULED_AUTOMATICREPLIESSTATUS {
+		if start := setting.GetScheduledStartDateTime(); start != nil {
+			result.WriteString(fmt.Sprintf("%s: %s %s\n", loc.T("Start"), util.Deref(start.GetDateTime()), util.Deref(start.GetTimeZone())))
+		}
+		if end := setting.GetScheduledEndDateTime(); end != nil {
+			result.WriteString(fmt.Sprintf("%s: %s %s\n", loc.T("End"), util.Deref(end.GetDateTime()), util.Deref(end.GetTimeZone())))
+		}
+	}
+	if setting.GetExternalAudience() != nil {
+		result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("External audience"), setting.GetExternalAudience().String()))
+	}
+
+	internalMessage, err := converter.ConvertString(util.Deref(setting.GetInternalReplyMessage()))
+	if err != nil {
+		return "", fmt.Errorf("failed to convert internal reply message HTML to markdown: %w", err)
+	}
+	externalMessage, err := converter.ConvertString(util.Deref(setting.GetExternalReplyMessage()))
+	if err != nil {
+		return "", fmt.Errorf("failed to convert external reply message HTML to markdown: %w", err)
+	}
+	result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Internal reply message"), strings.ReplaceAll(internalMessage, "\n", "\n  ")))
+	result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("External reply message"), strings.ReplaceAll(externalMessage, "\n", "\n  ")))
+
+	return result.String(), nil
+}
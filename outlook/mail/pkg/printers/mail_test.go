@@ -0,0 +1,229 @@
+package printers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gptscript-ai/tools/common/locale"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/util"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setLocale(t *testing.T, tag string) {
+	t.Setenv(locale.EnvLocale, tag)
+	t.Setenv(locale.EnvDefaultLocale, "")
+}
+
+func recipient(name, address string) models.Recipientable {
+	emailAddress := models.NewEmailAddress()
+	emailAddress.SetName(util.Ptr(name))
+	emailAddress.SetAddress(util.Ptr(address))
+	r := models.NewRecipient()
+	r.SetEmailAddress(emailAddress)
+	return r
+}
+
+func testMessage() models.Messageable {
+	msg := models.NewMessage()
+	msg.SetId(util.Ptr("msg-1"))
+	msg.SetSubject(util.Ptr("Quarterly report"))
+	msg.SetSender(recipient("Alice", "alice@example.com"))
+	msg.SetReceivedDateTime(util.Ptr(time.Date(2024, 11, 1, 9, 30, 0, 0, time.UTC)))
+	msg.SetIsRead(util.Ptr(false))
+	msg.SetWebLink(util.Ptr("https://outlook.office.com/mail/msg-1"))
+	msg.SetBodyPreview(util.Ptr("Hi,\nthe report is attached"))
+	msg.SetToRecipients([]models.Recipientable{recipient("Bob", "bob@example.com")})
+	msg.SetHasAttachments(util.Ptr(true))
+	msg.SetBody(itemBody(models.HTML_BODYTYPE, `<p>Hi,</p><p>the report is <b>attached</b></p>`))
+	return msg
+}
+
+func TestMessageToString(t *testing.T) {
+	setLocale(t, "")
+
+	t.Run("summary", func(t *testing.T) {
+		msg := testMessage()
+		msg.SetCategories([]string{"Finance", "Important"})
+		flag := models.NewFollowupFlag()
+		flag.SetFlagStatus(util.Ptr(models.FLAGGED_FOLLOWUPFLAGSTATUS))
+		due := models.NewDateTimeTimeZone()
+		due.SetDateTime(util.Ptr("2024-11-08T00:00:00"))
+		due.SetTimeZone(util.Ptr("UTC"))
+		flag.SetDueDateTime(due)
+		msg.SetFlag(flag)
+
+		got, err := MessageToString(msg, false)
+		require.NoError(t, err)
+		assert.Equal(t, `Subject: Quarterly report
+Message ID: msg-1
+Sender: Alice (email address: alice@example.com)
+Received: 2024-11-01T09:30:00Z
+Is unread: true
+Categories: Finance, Important
+Follow-up flag: flagged (due: 2024-11-08T00:00:00 UTC)
+Link: https://outlook.office.com/mail/msg-1
+Body preview: Hi,
+  the report is attached
+`, got)
+	})
+
+	t.Run("detailed", func(t *testing.T) {
+		got, err := MessageToString(testMessage(), true)
+		require.NoError(t, err)
+		assert.Equal(t, "Subject: Quarterly report\n"+
+			"Message ID: msg-1\n"+
+			"Sender: Alice (email address: alice@example.com)\n"+
+			"Received: 2024-11-01T09:30:00Z\n"+
+			"Is unread: true\n"+
+			"Link: https://outlook.office.com/mail/msg-1\n"+
+			"To: Bob (bob@example.com)\n"+
+			"CC: \n"+
+			"Has attachments: true\n"+
+			"Body: Hi,\n  \n  the report is **attached**", got)
+	})
+
+	t.Run("draft", func(t *testing.T) {
+		msg := testMessage()
+		msg.SetIsDraft(util.Ptr(true))
+		got, err := MessageToString(msg, false)
+		require.NoError(t, err)
+		assert.Contains(t, got, "Created: 2024-11-01T09:30:00Z\n")
+		assert.NotContains(t, got, "Sender:")
+	})
+
+	t.Run("selected fields", func(t *testing.T) {
+		msg := models.NewMessage()
+		msg.SetId(util.Ptr("msg-1"))
+		msg.SetSubject(util.Ptr("Quarterly report"))
+		got, err := MessageToString(msg, false)
+		require.NoError(t, err)
+		assert.Equal(t, "Subject: Quarterly report\nMessage ID: msg-1\n", got)
+	})
+
+	t.Run("localized", func(t *testing.T) {
+		setLocale(t, "de-DE")
+		got, err := MessageToString(testMessage(), false)
+		require.NoError(t, err)
+		assert.Contains(t, got, "Betreff: Quarterly report\n")
+		assert.Contains(t, got, "Empfangen: 01.11.2024 09:30 UTC\n")
+		assert.Contains(t, got, "Ungelesen: ja\n")
+	})
+}
+
+func TestDraftToString(t *testing.T) {
+	setLocale(t, "")
+
+	draft := testMessage()
+	draft.SetIsDraft(util.Ptr(true))
+	draft.SetLastModifiedDateTime(util.Ptr(time.Date(2024, 11, 2, 8, 0, 0, 0, time.UTC)))
+	assert.Equal(t, `Subject: Quarterly report
+Draft ID: msg-1
+Last modified: 2024-11-02T08:00:00Z
+To: Bob (bob@example.com)
+Has attachments: true
+Link: https://outlook.office.com/mail/msg-1
+Body preview: Hi,
+  the report is attached
+`, DraftToString(draft))
+
+	draft.SetCcRecipients([]models.Recipientable{recipient("Carol", "carol@example.com")})
+	draft.SetBccRecipients([]models.Recipientable{recipient("Dave", "dave@example.com"), recipient("Erin", "erin@example.com")})
+	got := DraftToString(draft)
+	assert.Contains(t, got, "CC: Carol (carol@example.com)\n")
+	assert.Contains(t, got, "BCC: Dave (dave@example.com), Erin (erin@example.com)\n")
+}
+
+func TestMailFolderToString(t *testing.T) {
+	folder := models.NewMailFolder()
+	folder.SetId(util.Ptr("folder-1"))
+	folder.SetDisplayName(util.Ptr("Projects"))
+	folder.SetUnreadItemCount(util.Ptr(int32(3)))
+	folder.SetTotalItemCount(util.Ptr(int32(1234)))
+
+	setLocale(t, "")
+	got, err := MailFolderToString(folder)
+	require.NoError(t, err)
+	assert.Equal(t, "Name: Projects\nID: folder-1\nUnread item count: 3\nTotal item count: 1234\n", got)
+
+	setLocale(t, "de")
+	folder.SetParentFolderId(util.Ptr("inbox"))
+	got, err = MailFolderToString(folder)
+	require.NoError(t, err)
+	assert.Equal(t, "Name: Projects\nID: folder-1\nID des übergeordneten Ordners: inbox\nAnzahl ungelesener Elemente: 3\nAnzahl Elemente: 1.234\n", got)
+}
+
+func TestRuleToString(t *testing.T) {
+	conditions := models.NewMessageRulePredicates()
+	conditions.SetFromAddresses([]models.Recipientable{recipient("Alice", "alice@example.com")})
+	conditions.SetSubjectContains([]string{"invoice", "receipt"})
+	actions := models.NewMessageRuleActions()
+	actions.SetMoveToFolder(util.Ptr("folder-1"))
+	actions.SetMarkAsRead(util.Ptr(true))
+	actions.SetStopProcessingRules(util.Ptr(true))
+
+	rule := models.NewMessageRule()
+	rule.SetId(util.Ptr("rule-1"))
+	rule.SetDisplayName(util.Ptr("Invoices"))
+	rule.SetIsEnabled(util.Ptr(true))
+	rule.SetConditions(conditions)
+	rule.SetActions(actions)
+
+	setLocale(t, "")
+	assert.Equal(t, `Name: Invoices
+ID: rule-1
+Enabled: true
+Conditions: from Alice (alice@example.com); subject contains "invoice" or "receipt"
+Actions: move to folder folder-1; mark as read; stop processing more rules
+`, RuleToString(rule))
+
+	setLocale(t, "de")
+	assert.Equal(t, `Name: Invoices
+ID: rule-1
+Aktiviert: ja
+Bedingungen: von Alice (alice@example.com); Betreff enthält "invoice" oder "receipt"
+Aktionen: in Ordner folder-1 verschieben; als gelesen markieren; keine weiteren Regeln verarbeiten
+`, RuleToString(rule))
+
+	setLocale(t, "")
+	assert.Equal(t, "Name: Empty\nID: rule-2\nEnabled: false\n", RuleToString(func() models.MessageRuleable {
+		empty := models.NewMessageRule()
+		empty.SetId(util.Ptr("rule-2"))
+		empty.SetDisplayName(util.Ptr("Empty"))
+		return empty
+	}()))
+}
+
+func TestAutomaticRepliesToString(t *testing.T) {
+	setLocale(t, "")
+
+	start := models.NewDateTimeTimeZone()
+	start.SetDateTime(util.Ptr("2024-12-20T17:00:00"))
+	start.SetTimeZone(util.Ptr("UTC"))
+	end := models.NewDateTimeTimeZone()
+	end.SetDateTime(util.Ptr("2025-01-06T08:00:00"))
+	end.SetTimeZone(util.Ptr("UTC"))
+
+	setting := models.NewAutomaticRepliesSetting()
+	setting.SetStatus(util.Ptr(models.SCHEDULED_AUTOMATICREPLIESSTATUS))
+	setting.SetScheduledStartDateTime(start)
+	setting.SetScheduledEndDateTime(end)
+	setting.SetExternalAudience(util.Ptr(models.CONTACTSONLY_EXTERNALAUDIENCESCOPE))
+	setting.SetInternalReplyMessage(util.Ptr("<p>I'm <b>out</b> until January 6.</p>"))
+	setting.SetExternalReplyMessage(util.Ptr("<p>I'm out of office.</p>"))
+
+	got, err := AutomaticRepliesToString(setting)
+	require.NoError(t, err)
+	assert.Equal(t, `Status: scheduled
+Start: 2024-12-20T17:00:00 UTC
+End: 2025-01-06T08:00:00 UTC
+External audience: contactsOnly
+Internal reply message: I'm **out** until January 6.
+External reply message: I'm out of office.
+`, got)
+
+	got, err = AutomaticRepliesToString(models.NewAutomaticRepliesSetting())
+	require.NoError(t, err)
+	assert.Equal(t, "Status: disabled\nInternal reply message: \nExternal reply message: \n", got)
+}
@@ -0,0 +1,139 @@
+package thread
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gptscript-ai/tools/common/locale"
+	"github.com/gptscript-ai/tools/outlook/mail/pkg/util"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+var (
+	// Lines that introduce the quoted previous message in replies, in the formats used by the common mail clients
+	replyHeaderPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)^-{2,}\s*original message\s*-{2,}$`),
+		regexp.MustCompile(`(?i)^-{2,}\s*forwarded message\s*-{2,}$`),
+		regexp.MustCompile(`(?i)^on .+ wrote:$`),
+		regexp.MustCompile(`(?i)^from: .+`),
+		regexp.MustCompile(`^_{10,}$`),
+	}
+	blankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+// StripQuoted removes quoted text of earlier messages from a plain text mail body:
+// everything after a reply header and all lines starting with ">".
+func StripQuoted(body string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if isReplyHeader(trimmed) {
+			break
+		}
+		if strings.HasPrefix(trimmed, ">") {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, " \t"))
+	}
+
+	return strings.TrimSpace(blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+func isReplyHeader(line string) bool {
+	for _, p := range replyHeaderPatterns {
+		if p.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// Message is a message of a transcript, rendered as plain text
+type Message struct {
+	ID   string
+	Text string
+}
+
+// Assemble renders the messages of a conversation (sorted oldest first) as a plain text transcript, without
+// the quoted duplicates of earlier messages. Messages whose content is identical to an earlier one are skipped.
+func Assemble(messages []models.Messageable) string {
+	var sb strings.Builder
+	for _, message := range assemble(messages, false, locale.Locale{}) {
+		sb.WriteString(message.Text)
+	}
+	return sb.String()
+}
+
+// MessagesWithIDs renders the messages of the transcript like Assemble, one by one and with the ID of each
+// message, so that the messages can be referred to. The labels and dates are formatted for the locale.
+func MessagesWithIDs(messages []models.Messageable, loc locale.Locale) []Message {
+	return assemble(messages, true, loc)
+}
+
+func assemble(messages []models.Messageable, withIDs bool, loc locale.Locale) []Message {
+	var (
+		result []Message
+		seen   = map[string]struct{}{}
+	)
+
+	for _, msg := range messages {
+		if util.Deref(msg.GetIsDraft()) {
+			continue
+		}
+
+		content := bodyContent(msg.GetUniqueBody())
+		if strings.TrimSpace(content) == "" {
+			content = bodyContent(msg.GetBody())
+		}
+		content = StripQuoted(content)
+
+		if _, ok := seen[content]; ok {
+			continue
+		}
+		seen[content] = struct{}{}
+
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf(loc.T("--- Message %d ---")+"\n", len(result)+1))
+		if withIDs {
+			sb.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Message ID"), util.Deref(msg.GetId())))
+		}
+		sb.WriteString(fmt.Sprintf("%s: %s\n", loc.T("From"), recipientToString(loc, msg.GetFrom())))
+		if to := util.Map(msg.GetToRecipients(), func(r models.Recipientable) string { return recipientToString(loc, r) }); len(to) > 0 {
+			sb.WriteString(fmt.Sprintf("%s: %s\n", loc.T("To"), strings.Join(to, ", ")))
+		}
+		if cc := util.Map(msg.GetCcRecipients(), func(r models.Recipientable) string { return recipientToString(loc, r) }); len(cc) > 0 {
+			sb.WriteString(fmt.Sprintf("%s: %s\n", loc.T("CC"), strings.Join(cc, ", ")))
+		}
+		if received := msg.GetReceivedDateTime(); received != nil {
+			sb.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Date"), loc.DateTime(*received)))
+		}
+		if util.Deref(msg.GetHasAttachments()) {
+			sb.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Has attachments"), loc.Bool(true)))
+		}
+		sb.WriteString("\n")
+		sb.WriteString(content)
+		sb.WriteString("\n\n")
+
+		result = append(result, Message{
+			ID:   util.Deref(msg.GetId()),
+			Text: sb.String(),
+		})
+	}
+
+	return result
+}
+
+func bodyContent(body models.ItemBodyable) string {
+	if body == nil {
+		return ""
+	}
+	return util.Deref(body.GetContent())
+}
+
+func recipientToString(loc locale.Locale, r models.Recipientable) string {
+	if r == nil || r.GetEmailAddress() == nil {
+		return loc.Unknown()
+	}
+	return fmt.Sprintf("%s <%s>", util.Deref(r.GetEmailAddress().GetName()), util.Deref(r.GetEmailAddress().GetAddress()))
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gptscript-ai/tools/outlook/common/auth"
+	"github.com/gptscript-ai/tools/outlook/common/fields"
+	"github.com/gptscript-ai/tools/outlook/common/pagination"
+	"github.com/gptscript-ai/tools/outlook/todo/pkg/commands"
+	"github.com/gptscript-ai/tools/outlook/todo/pkg/graph"
+)
+
+func main() {
+	// The user is only used with app-only auth, when there is no signed-in user
+	user := flag.String("user", "", "user principal name of the user to act on with app-only auth")
+	pageSize := flag.Int("page-size", 0, "number of items requested from Graph per page")
+	fieldSet := flag.String("fields", "", "field set (summary, default, full) or comma-separated properties of listed tasks")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		fmt.Println("Usage: todo [--user <upn>] [--page-size <n>] [--fields <fields>] <command>")
+		os.Exit(1)
+	}
+	auth.SetUser(*user)
+	pagination.SetPageSize(*pageSize)
+	fields.Set(*fieldSet)
+
+	command := flag.Arg(0)
+
+	switch command {
+	case "listTaskLists":
+		if err := commands.ListTaskLists(context.Background()); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "listTasks":
+		var includeCompleted bool
+		if v := os.Getenv("INCLUDE_COMPLETED"); v != "" {
+			var err error
+			includeCompleted, err = strconv.ParseBool(v)
+			if err != nil {
+				fmt.Printf("failed to parse include_completed: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		limit := 100
+		if v := os.Getenv("LIMIT"); v != "" {
+			var err error
+			limit, err = strconv.Atoi(v)
+			if err != nil {
+				fmt.Printf("failed to parse limit: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		// The title describes the tasks in datasets
+		selects, err := graph.TaskFields.FromEnv("title")
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if err := commands.ListTasks(context.Background(), os.Getenv("LIST_ID"), includeCompleted, limit, selects); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "createTask":
+		info, err := taskInfoFromEnv()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if err := commands.CreateTask(context.Background(), os.Getenv("LIST_ID"), info); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "completeTask":
+		if err := commands.CompleteTask(context.Background(), os.Getenv("LIST_ID"), os.Getenv("TASK_ID")); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "scheduleTask":
+		dueDate, reminder, err := scheduleFromEnv()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if err := commands.ScheduleTask(context.Background(), os.Getenv("LIST_ID"), os.Getenv("TASK_ID"), dueDate, strings.TrimSpace(os.Getenv("TIMEZONE")), reminder); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "createTaskFromMessage":
+		info, err := taskInfoFromEnv()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if err := commands.CreateTaskFromMessage(context.Background(), os.Getenv("LIST_ID"), os.Getenv("MESSAGE_ID"), info); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Printf("Unknown command: %q\n", command)
+		os.Exit(1)
+	}
+}
+
+// taskInfoFromEnv returns the properties of a new task.
+func taskInfoFromEnv() (graph.TaskInfo, error) {
+	dueDate, reminder, err := scheduleFromEnv()
+	if err != nil {
+		return graph.TaskInfo{}, err
+	}
+
+	info := graph.TaskInfo{
+		Title:      optionalEnv("TITLE"),
+		Body:       optionalEnv("BODY"),
+		DueDate:    dueDate,
+		TimeZone:   strings.TrimSpace(os.Getenv("TIMEZONE")),
+		Reminder:   reminder,
+		Categories: optionalList("CATEGORIES"),
+	}
+	if importance := os.Getenv("IMPORTANCE"); importance != "" {
+		if info.Importance, err = graph.ParseImportance(importance); err != nil {
+			return graph.TaskInfo{}, err
+		}
+	}
+	return info, nil
+}
+
+// scheduleFromEnv returns the due date and the reminder of a task, which are nil if they are not set. The due date
+// is a date (2024-11-01), or a date and time in RFC 3339 format of which only the date is used.
+func scheduleFromEnv() (*time.Time, *time.Time, error) {
+	var dueDate, reminder *time.Time
+	if v := strings.TrimSpace(os.Getenv("DUE_DATE")); v != "" {
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			if t, err = time.Parse(time.RFC3339, v); err != nil {
+				return nil, nil, fmt.Errorf("invalid due date %q, expected a date (YYYY-MM-DD) or RFC 3339 date and time", v)
+			}
+		}
+		dueDate = &t
+	}
+	if v := strings.TrimSpace(os.Getenv("REMINDER")); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse reminder: %w", err)
+		}
+		reminder = &t
+	}
+	return dueDate, reminder, nil
+}
+
+// optionalEnv returns the value of the environment variable, or nil if it is not set or empty.
+func optionalEnv(name string) *string {
+	if v := os.Getenv(name); v != "" {
+		return &v
+	}
+	return nil
+}
+
+// optionalList returns the comma-separated list of the environment variable, or nil if it is not set or empty.
+func optionalList(name string) []string {
+	if v := os.Getenv(name); v != "" {
+		return strings.Split(v, ",")
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+package client
+
+import (
+	"github.com/gptscript-ai/tools/outlook/common/graphclient"
+	"github.com/gptscript-ai/tools/outlook/todo/pkg/global"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+)
+
+// NewClient returns the shared Graph client of the Outlook tools for the token of this tool, see graphclient.NewClient.
+func NewClient(scopes []string) (*msgraphsdkgo.GraphServiceClient, error) {
+	return graphclient.NewClient(global.CredentialEnv, scopes)
+}
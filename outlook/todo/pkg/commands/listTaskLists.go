@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/common/guard"
+	"github.com/gptscript-ai/tools/outlook/common/id"
+	"github.com/gptscript-ai/tools/outlook/todo/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/todo/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/todo/pkg/graph"
+	"github.com/gptscript-ai/tools/outlook/todo/pkg/printers"
+	"github.com/gptscript-ai/tools/outlook/todo/pkg/util"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+// ListTaskLists lists the To Do task lists of the user.
+func ListTaskLists(ctx context.Context) error {
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	lists, err := graph.ListTaskLists(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	translatedIDs, err := id.SetOutlookIDs(ctx, util.Map(lists, func(list models.TodoTaskListable) string {
+		return util.Deref(list.GetId())
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to set task list IDs: %w", err)
+	}
+
+	var elements []gptscript.DatasetElement
+	for _, list := range lists {
+		list.SetId(util.Ptr(translatedIDs[util.Deref(list.GetId())]))
+		elements = append(elements, gptscript.DatasetElement{
+			DatasetElementMeta: gptscript.DatasetElementMeta{
+				Name:        util.Deref(list.GetId()),
+				Description: util.Deref(list.GetDisplayName()),
+			},
+			Contents: printers.TaskListToString(list),
+		})
+	}
+
+	gptscriptClient, err := gptscript.NewGPTScript()
+	if err != nil {
+		return fmt.Errorf("failed to create GPTScript client: %w", err)
+	}
+
+	return guard.PrintElements(ctx, gptscriptClient, elements, gptscript.DatasetOptions{
+		Name: "todo_task_lists",
+	}, "task lists")
+}
@@ -0,0 +1,88 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/common/guard"
+	"github.com/gptscript-ai/tools/common/locale"
+	"github.com/gptscript-ai/tools/outlook/common/id"
+	"github.com/gptscript-ai/tools/outlook/todo/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/todo/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/todo/pkg/graph"
+	"github.com/gptscript-ai/tools/outlook/todo/pkg/printers"
+	"github.com/gptscript-ai/tools/outlook/todo/pkg/util"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+)
+
+// ListTasks lists up to limit tasks of the task list, or of the default task list if listID is empty, with the
+// selected properties, see graph.TaskFields.
+func ListTasks(ctx context.Context, listID string, includeCompleted bool, limit int, selects []string) error {
+	c, err := client.NewClient(global.ReadOnlyScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	trueListID, err := trueListID(ctx, c, listID)
+	if err != nil {
+		return err
+	}
+
+	tasks, err := graph.ListTasks(ctx, c, trueListID, includeCompleted, limit, selects)
+	if err != nil {
+		return err
+	}
+
+	if len(tasks) == 0 {
+		fmt.Println(locale.FromEnv().T("No tasks found"))
+		return nil
+	}
+
+	var outlookIDs []string
+	for _, task := range tasks {
+		outlookIDs = append(outlookIDs, util.Deref(task.GetId()))
+	}
+	translatedIDs, err := id.SetOutlookIDs(ctx, outlookIDs)
+	if err != nil {
+		return fmt.Errorf("failed to set task IDs: %w", err)
+	}
+
+	var elements []gptscript.DatasetElement
+	for _, task := range tasks {
+		task.SetId(util.Ptr(translatedIDs[util.Deref(task.GetId())]))
+		elements = append(elements, gptscript.DatasetElement{
+			DatasetElementMeta: gptscript.DatasetElementMeta{
+				Name:        util.Deref(task.GetId()),
+				Description: util.Deref(task.GetTitle()),
+			},
+			Contents: printers.TaskToString(task),
+		})
+	}
+
+	gptscriptClient, err := gptscript.NewGPTScript()
+	if err != nil {
+		return fmt.Errorf("failed to create GPTScript client: %w", err)
+	}
+
+	datasetName := "todo_tasks"
+	if listID != "" {
+		datasetName = fmt.Sprintf("%s_todo_tasks", listID)
+	}
+	return guard.PrintElements(ctx, gptscriptClient, elements, gptscript.DatasetOptions{
+		Name:        datasetName,
+		Description: "To Do tasks",
+	}, "tasks")
+}
+
+// trueListID returns the Outlook ID of the task list, or of the default task list if listID is empty.
+func trueListID(ctx context.Context, c *msgraphsdkgo.GraphServiceClient, listID string) (string, error) {
+	if listID == "" {
+		return graph.DefaultTaskListID(ctx, c)
+	}
+	trueListID, err := id.GetOutlookID(ctx, listID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get task list ID: %w", err)
+	}
+	return trueListID, nil
+}
@@ -0,0 +1,156 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gptscript-ai/tools/outlook/common/id"
+	"github.com/gptscript-ai/tools/outlook/todo/pkg/client"
+	"github.com/gptscript-ai/tools/outlook/todo/pkg/global"
+	"github.com/gptscript-ai/tools/outlook/todo/pkg/graph"
+	"github.com/gptscript-ai/tools/outlook/todo/pkg/util"
+)
+
+// CreateTask creates the task in the task list, or in the default task list if listID is empty.
+func CreateTask(ctx context.Context, listID string, info graph.TaskInfo) error {
+	if util.Deref(info.Title) == "" {
+		return fmt.Errorf("the task title is required")
+	}
+
+	c, err := client.NewClient(global.AllScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	trueListID, err := trueListID(ctx, c, listID)
+	if err != nil {
+		return err
+	}
+
+	task, err := graph.CreateTask(ctx, c, trueListID, info)
+	if err != nil {
+		return err
+	}
+
+	taskID, err := id.SetOutlookID(ctx, util.Deref(task.GetId()))
+	if err != nil {
+		return fmt.Errorf("failed to set task ID: %w", err)
+	}
+
+	fmt.Printf("Task %s created with ID: %s\n", util.Deref(task.GetTitle()), taskID)
+	return nil
+}
+
+// CompleteTask marks the task of the task list, or of the default task list if listID is empty, as completed.
+func CompleteTask(ctx context.Context, listID, taskID string) error {
+	trueTaskID, err := id.GetOutlookID(ctx, taskID)
+	if err != nil {
+		return fmt.Errorf("failed to get task ID: %w", err)
+	}
+
+	c, err := client.NewClient(global.AllScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	trueListID, err := trueListID(ctx, c, listID)
+	if err != nil {
+		return err
+	}
+
+	task, err := graph.CompleteTask(ctx, c, trueListID, trueTaskID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Task %s completed\n", util.Deref(task.GetTitle()))
+	return nil
+}
+
+// ScheduleTask sets the due date and the reminder of the task of the task list, or of the default task list if
+// listID is empty.
+func ScheduleTask(ctx context.Context, listID, taskID string, dueDate *time.Time, timeZone string, reminder *time.Time) error {
+	if dueDate == nil && reminder == nil {
+		return fmt.Errorf("nothing to schedule, set a due date or a reminder")
+	}
+
+	trueTaskID, err := id.GetOutlookID(ctx, taskID)
+	if err != nil {
+		return fmt.Errorf("failed to get task ID: %w", err)
+	}
+
+	c, err := client.NewClient(global.AllScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	trueListID, err := trueListID(ctx, c, listID)
+	if err != nil {
+		return err
+	}
+
+	task, err := graph.UpdateTask(ctx, c, trueListID, trueTaskID, graph.TaskInfo{
+		DueDate:  dueDate,
+		TimeZone: timeZone,
+		Reminder: reminder,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Task %s scheduled\n", util.Deref(task.GetTitle()))
+	return nil
+}
+
+// CreateTaskFromMessage creates a task for the message in the task list, or in the default task list if listID is
+// empty. The task links to the message, so it can be opened in Outlook from To Do. The title defaults to the subject
+// of the message, and the due date to the due date of the message's follow-up flag.
+func CreateTaskFromMessage(ctx context.Context, listID, messageID string, info graph.TaskInfo) error {
+	trueMessageID, err := id.GetOutlookID(ctx, messageID)
+	if err != nil {
+		return fmt.Errorf("failed to get message ID: %w", err)
+	}
+
+	c, err := client.NewClient(global.AllScopes)
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	trueListID, err := trueListID(ctx, c, listID)
+	if err != nil {
+		return err
+	}
+
+	message, err := graph.GetMessage(ctx, c, trueMessageID)
+	if err != nil {
+		return err
+	}
+
+	if util.Deref(info.Title) == "" {
+		info.Title = message.GetSubject()
+		if util.Deref(info.Title) == "" {
+			info.Title = util.Ptr("Follow up on email")
+		}
+	}
+	if info.DueDate == nil {
+		info.DueDate, info.TimeZone = graph.MessageDueDate(message)
+	}
+	if info.Importance == nil {
+		info.Importance = message.GetImportance()
+	}
+	info.LinkedResources = append(info.LinkedResources, graph.MessageLink(message))
+
+	task, err := graph.CreateTask(ctx, c, trueListID, info)
+	if err != nil {
+		return err
+	}
+
+	taskID, err := id.SetOutlookID(ctx, util.Deref(task.GetId()))
+	if err != nil {
+		return fmt.Errorf("failed to set task ID: %w", err)
+	}
+
+	fmt.Printf("Task %s created for the message with ID: %s\n", util.Deref(task.GetTitle()), taskID)
+	return nil
+}
@@ -0,0 +1,8 @@
+package global
+
+const CredentialEnv = "GPTSCRIPT_GRAPH_MICROSOFT_COM_BEARER_TOKEN"
+
+var (
+	ReadOnlyScopes = []string{"Tasks.Read", "User.Read"}
+	AllScopes      = []string{"Tasks.Read", "Tasks.ReadWrite", "Mail.Read", "User.Read"}
+)
@@ -0,0 +1,53 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gptscript-ai/tools/outlook/common/pagination"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+// ListTaskLists returns the To Do task lists of the user, including the lists shared with them.
+func ListTaskLists(ctx context.Context, client *msgraphsdkgo.GraphServiceClient) ([]models.TodoTaskListable, error) {
+	lists, err := pagination.New(
+		func(ctx context.Context, q pagination.Query) (models.TodoTaskListCollectionResponseable, error) {
+			return client.Me().Todo().Lists().Get(ctx, &users.ItemTodoListsRequestBuilderGetRequestConfiguration{
+				QueryParameters: &users.ItemTodoListsRequestBuilderGetQueryParameters{
+					Top: q.Top,
+				},
+			})
+		},
+		func(ctx context.Context, nextLink string) (models.TodoTaskListCollectionResponseable, error) {
+			return client.Me().Todo().Lists().WithUrl(nextLink).Get(ctx, nil)
+		},
+	).Collect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list task lists: %w", err)
+	}
+	return lists, nil
+}
+
+// DefaultTaskListID returns the ID of the user's default task list, which is called "Tasks" in To Do.
+func DefaultTaskListID(ctx context.Context, client *msgraphsdkgo.GraphServiceClient) (string, error) {
+	lists, err := ListTaskLists(ctx, client)
+	if err != nil {
+		return "", err
+	}
+	list := findWellknownList(lists, models.DEFAULTLIST_WELLKNOWNLISTNAME)
+	if list == nil {
+		return "", fmt.Errorf("the user has no default task list")
+	}
+	return *list.GetId(), nil
+}
+
+func findWellknownList(lists []models.TodoTaskListable, name models.WellknownListName) models.TodoTaskListable {
+	for _, list := range lists {
+		if list.GetWellknownListName() != nil && *list.GetWellknownListName() == name && list.GetId() != nil {
+			return list
+		}
+	}
+	return nil
+}
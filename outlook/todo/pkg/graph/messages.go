@@ -0,0 +1,51 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gptscript-ai/tools/outlook/todo/pkg/util"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+// GetMessage returns the properties of the message that are needed to create a task for it.
+func GetMessage(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, messageID string) (models.Messageable, error) {
+	message, err := client.Me().Messages().ByMessageId(messageID).Get(ctx, &users.ItemMessagesMessageItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.ItemMessagesMessageItemRequestBuilderGetQueryParameters{
+			Select: []string{"id", "subject", "bodyPreview", "webLink", "flag", "importance"},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+	return message, nil
+}
+
+// MessageLink returns the linked resource that opens the message in Outlook from the task, the same way To Do links
+// the tasks of flagged emails.
+func MessageLink(message models.Messageable) models.LinkedResourceable {
+	link := models.NewLinkedResource()
+	link.SetApplicationName(util.Ptr("Outlook"))
+	link.SetDisplayName(message.GetSubject())
+	link.SetExternalId(message.GetId())
+	link.SetWebUrl(message.GetWebLink())
+	return link
+}
+
+// MessageDueDate returns the due date of the follow-up flag of the message and its time zone, or nil if the message
+// is not flagged or the flag has no due date.
+func MessageDueDate(message models.Messageable) (*time.Time, string) {
+	flag := message.GetFlag()
+	if flag == nil || util.Deref(flag.GetFlagStatus()) != models.FLAGGED_FOLLOWUPFLAGSTATUS || flag.GetDueDateTime() == nil {
+		return nil, ""
+	}
+	// Only the day is kept, in the time zone of the flag, e.g. "2024-10-01T00:00:00.0000000" in "UTC"
+	due, err := time.Parse("2006-01-02T15:04:05.9999999", util.Deref(flag.GetDueDateTime().GetDateTime()))
+	if err != nil {
+		return nil, ""
+	}
+	return &due, util.Deref(flag.GetDueDateTime().GetTimeZone())
+}
@@ -0,0 +1,157 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gptscript-ai/tools/outlook/common/fields"
+	"github.com/gptscript-ai/tools/outlook/common/pagination"
+	"github.com/gptscript-ai/tools/outlook/todo/pkg/util"
+	msgraphsdkgo "github.com/microsoftgraph/msgraph-sdk-go"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+)
+
+// TaskInfo are the properties of a task. Nil fields are not set.
+type TaskInfo struct {
+	Title, Body *string
+	// DueDate is the day the task is due. To Do only keeps the date, in the time zone of TimeZone (UTC if empty).
+	DueDate  *time.Time
+	TimeZone string
+	// Reminder is the time the user is reminded of the task
+	Reminder        *time.Time
+	Importance      *models.Importance
+	Categories      []string
+	LinkedResources []models.LinkedResourceable
+}
+
+// taskFromInfo returns a task with the properties of the info that are set.
+func taskFromInfo(info TaskInfo) models.TodoTaskable {
+	task := models.NewTodoTask()
+	if info.Title != nil {
+		task.SetTitle(info.Title)
+	}
+	if info.Body != nil {
+		body := models.NewItemBody()
+		body.SetContentType(util.Ptr(models.TEXT_BODYTYPE))
+		body.SetContent(info.Body)
+		task.SetBody(body)
+	}
+	if info.DueDate != nil {
+		timeZone := info.TimeZone
+		if timeZone == "" {
+			timeZone = "UTC"
+		}
+		due := models.NewDateTimeTimeZone()
+		due.SetDateTime(util.Ptr(info.DueDate.Format(time.DateOnly) + "T00:00:00"))
+		due.SetTimeZone(util.Ptr(timeZone))
+		task.SetDueDateTime(due)
+	}
+	if info.Reminder != nil {
+		reminder := models.NewDateTimeTimeZone()
+		reminder.SetDateTime(util.Ptr(info.Reminder.UTC().Format("2006-01-02T15:04:05")))
+		reminder.SetTimeZone(util.Ptr("UTC"))
+		task.SetReminderDateTime(reminder)
+		task.SetIsReminderOn(util.Ptr(true))
+	}
+	if info.Importance != nil {
+		task.SetImportance(info.Importance)
+	}
+	if info.Categories != nil {
+		task.SetCategories(trimList(info.Categories))
+	}
+	if info.LinkedResources != nil {
+		task.SetLinkedResources(info.LinkedResources)
+	}
+	return task
+}
+
+func trimList(list []string) []string {
+	result := []string{}
+	for _, s := range list {
+		if s = strings.TrimSpace(s); s != "" {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// ParseImportance parses the importance of a task: low, normal or high
+func ParseImportance(importance string) (*models.Importance, error) {
+	parsed, err := models.ParseImportance(strings.ToLower(strings.TrimSpace(importance)))
+	if err != nil || parsed == nil {
+		return nil, fmt.Errorf("invalid importance %q, must be low, normal or high", importance)
+	}
+	return parsed.(*models.Importance), nil
+}
+
+// TaskFields are the field sets of the commands that list tasks. The default set has the properties that
+// printers.TaskToString prints, the linked resources are always expanded.
+var TaskFields = fields.Sets{
+	fields.Summary: {"title", "status", "importance", "dueDateTime"},
+	fields.Default: {"title", "status", "importance", "dueDateTime", "reminderDateTime", "isReminderOn", "completedDateTime", "categories", "createdDateTime", "body"},
+	fields.Full:    nil,
+}
+
+// ListTasks returns up to limit tasks of the task list, with their linked resources. Completed tasks are only
+// returned if includeCompleted is set. A limit of 0 or less returns all tasks. Only the selected properties are
+// returned, all default properties if selects is nil.
+func ListTasks(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, listID string, includeCompleted bool, limit int, selects []string) ([]models.TodoTaskable, error) {
+	var filter *string
+	if !includeCompleted {
+		filter = util.Ptr("status ne 'completed'")
+	}
+
+	tasks := client.Me().Todo().Lists().ByTodoTaskListId(listID).Tasks()
+	result, err := pagination.New(
+		func(ctx context.Context, q pagination.Query) (models.TodoTaskCollectionResponseable, error) {
+			return tasks.Get(ctx, &users.ItemTodoListsItemTasksRequestBuilderGetRequestConfiguration{
+				QueryParameters: &users.ItemTodoListsItemTasksRequestBuilderGetQueryParameters{
+					Filter: filter,
+					Expand: []string{"linkedResources"},
+					Top:    q.Top,
+					Select: q.Select,
+				},
+			})
+		},
+		func(ctx context.Context, nextLink string) (models.TodoTaskCollectionResponseable, error) {
+			return tasks.WithUrl(nextLink).Get(ctx, nil)
+		},
+	).WithSelect(selects...).WithLimit(limit).Collect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	return result, nil
+}
+
+// CreateTask creates the task in the task list.
+func CreateTask(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, listID string, info TaskInfo) (models.TodoTaskable, error) {
+	task, err := client.Me().Todo().Lists().ByTodoTaskListId(listID).Tasks().Post(ctx, taskFromInfo(info), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create task: %w", err)
+	}
+	return task, nil
+}
+
+// UpdateTask updates the properties of the task that are set in the info.
+func UpdateTask(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, listID, taskID string, info TaskInfo) (models.TodoTaskable, error) {
+	task, err := client.Me().Todo().Lists().ByTodoTaskListId(listID).Tasks().ByTodoTaskId(taskID).Patch(ctx, taskFromInfo(info), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update task: %w", err)
+	}
+	return task, nil
+}
+
+// CompleteTask marks the task as completed.
+func CompleteTask(ctx context.Context, client *msgraphsdkgo.GraphServiceClient, listID, taskID string) (models.TodoTaskable, error) {
+	requestBody := models.NewTodoTask()
+	requestBody.SetStatus(util.Ptr(models.COMPLETED_TASKSTATUS))
+
+	task, err := client.Me().Todo().Lists().ByTodoTaskListId(listID).Tasks().ByTodoTaskId(taskID).Patch(ctx, requestBody, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete task: %w", err)
+	}
+	return task, nil
+}
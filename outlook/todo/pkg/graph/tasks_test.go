@@ -0,0 +1,91 @@
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gptscript-ai/tools/outlook/todo/pkg/util"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskFromInfo(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	task := taskFromInfo(TaskInfo{
+		Title:      util.Ptr("Send the report"),
+		DueDate:    util.Ptr(time.Date(2024, 11, 1, 23, 30, 0, 0, berlin)),
+		TimeZone:   "Europe/Berlin",
+		Reminder:   util.Ptr(time.Date(2024, 11, 1, 9, 0, 0, 0, berlin)),
+		Categories: []string{" Work", "", "Reports "},
+	})
+
+	assert.Equal(t, "Send the report", util.Deref(task.GetTitle()))
+	assert.Nil(t, task.GetBody())
+	assert.Nil(t, task.GetImportance())
+	// The due date keeps its day, the reminder is sent in UTC
+	assert.Equal(t, "2024-11-01T00:00:00", util.Deref(task.GetDueDateTime().GetDateTime()))
+	assert.Equal(t, "Europe/Berlin", util.Deref(task.GetDueDateTime().GetTimeZone()))
+	assert.Equal(t, "2024-11-01T08:00:00", util.Deref(task.GetReminderDateTime().GetDateTime()))
+	assert.Equal(t, "UTC", util.Deref(task.GetReminderDateTime().GetTimeZone()))
+	assert.True(t, util.Deref(task.GetIsReminderOn()))
+	assert.Equal(t, []string{"Work", "Reports"}, task.GetCategories())
+
+	task = taskFromInfo(TaskInfo{DueDate: util.Ptr(time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC))})
+	assert.Equal(t, "UTC", util.Deref(task.GetDueDateTime().GetTimeZone()))
+	assert.Nil(t, task.GetReminderDateTime())
+	assert.Nil(t, task.GetIsReminderOn())
+}
+
+func TestFindWellknownList(t *testing.T) {
+	list := func(id string, name models.WellknownListName) models.TodoTaskListable {
+		l := models.NewTodoTaskList()
+		l.SetId(util.Ptr(id))
+		l.SetWellknownListName(util.Ptr(name))
+		return l
+	}
+	lists := []models.TodoTaskListable{
+		list("groceries", models.NONE_WELLKNOWNLISTNAME),
+		list("flagged", models.FLAGGEDEMAILS_WELLKNOWNLISTNAME),
+		list("tasks", models.DEFAULTLIST_WELLKNOWNLISTNAME),
+	}
+
+	assert.Equal(t, "tasks", util.Deref(findWellknownList(lists, models.DEFAULTLIST_WELLKNOWNLISTNAME).GetId()))
+	assert.Equal(t, "flagged", util.Deref(findWellknownList(lists, models.FLAGGEDEMAILS_WELLKNOWNLISTNAME).GetId()))
+	assert.Nil(t, findWellknownList(lists[:2], models.DEFAULTLIST_WELLKNOWNLISTNAME))
+}
+
+func TestMessageLinkAndDueDate(t *testing.T) {
+	message := models.NewMessage()
+	message.SetId(util.Ptr("AAMkAGI2"))
+	message.SetSubject(util.Ptr("Quarterly numbers"))
+	message.SetWebLink(util.Ptr("https://outlook.office365.com/owa/?ItemID=AAMkAGI2"))
+
+	link := MessageLink(message)
+	assert.Equal(t, "Outlook", util.Deref(link.GetApplicationName()))
+	assert.Equal(t, "Quarterly numbers", util.Deref(link.GetDisplayName()))
+	assert.Equal(t, "AAMkAGI2", util.Deref(link.GetExternalId()))
+	assert.Equal(t, "https://outlook.office365.com/owa/?ItemID=AAMkAGI2", util.Deref(link.GetWebUrl()))
+
+	due, _ := MessageDueDate(message)
+	assert.Nil(t, due)
+
+	dueDateTime := models.NewDateTimeTimeZone()
+	dueDateTime.SetDateTime(util.Ptr("2024-11-01T00:00:00.0000000"))
+	dueDateTime.SetTimeZone(util.Ptr("Pacific Standard Time"))
+	flag := models.NewFollowupFlag()
+	flag.SetFlagStatus(util.Ptr(models.FLAGGED_FOLLOWUPFLAGSTATUS))
+	flag.SetDueDateTime(dueDateTime)
+	message.SetFlag(flag)
+
+	due, timeZone := MessageDueDate(message)
+	require.NotNil(t, due)
+	assert.Equal(t, "2024-11-01", due.Format(time.DateOnly))
+	assert.Equal(t, "Pacific Standard Time", timeZone)
+
+	flag.SetFlagStatus(util.Ptr(models.COMPLETE_FOLLOWUPFLAGSTATUS))
+	due, _ = MessageDueDate(message)
+	assert.Nil(t, due)
+}
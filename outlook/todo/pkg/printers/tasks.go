@@ -0,0 +1,88 @@
+package printers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gptscript-ai/tools/common/locale"
+	"github.com/gptscript-ai/tools/outlook/todo/pkg/util"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+)
+
+func TaskListToString(list models.TodoTaskListable) string {
+	var (
+		result strings.Builder
+		loc    = locale.FromEnv()
+	)
+
+	result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Name"), util.Deref(list.GetDisplayName())))
+	result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("ID"), util.Deref(list.GetId())))
+	if name := list.GetWellknownListName(); name != nil && *name != models.NONE_WELLKNOWNLISTNAME {
+		result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Type"), name.String()))
+	}
+
+	return result.String()
+}
+
+func TaskToString(task models.TodoTaskable) string {
+	var (
+		result strings.Builder
+		loc    = locale.FromEnv()
+	)
+
+	result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Subject"), util.Deref(task.GetTitle())))
+	result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("ID"), util.Deref(task.GetId())))
+	if status := task.GetStatus(); status != nil {
+		result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Status"), status.String()))
+	}
+	if importance := task.GetImportance(); importance != nil && *importance != models.NORMAL_IMPORTANCE {
+		result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Importance"), importance.String()))
+	}
+	if due := task.GetDueDateTime(); due != nil {
+		result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Due date"), day(loc, due)))
+	}
+	if reminder := task.GetReminderDateTime(); reminder != nil && util.Deref(task.GetIsReminderOn()) {
+		result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Reminder"), dateTime(loc, reminder)))
+	}
+	if completed := task.GetCompletedDateTime(); completed != nil {
+		result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Completed"), day(loc, completed)))
+	}
+	if len(task.GetCategories()) > 0 {
+		result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Categories"), strings.Join(task.GetCategories(), ", ")))
+	}
+	if created := task.GetCreatedDateTime(); created != nil {
+		result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Created"), loc.DateTime(*created)))
+	}
+	if body := task.GetBody(); body != nil && strings.TrimSpace(util.Deref(body.GetContent())) != "" {
+		result.WriteString(fmt.Sprintf("%s: %s\n", loc.T("Body"), strings.TrimSpace(util.Deref(body.GetContent()))))
+	}
+	if links := task.GetLinkedResources(); len(links) > 0 {
+		result.WriteString(fmt.Sprintf("%s:\n", loc.T("Linked items")))
+		for _, link := range links {
+			result.WriteString(fmt.Sprintf("  - %s (%s): %s\n", util.Deref(link.GetDisplayName()), util.Deref(link.GetApplicationName()), util.Deref(link.GetWebUrl())))
+		}
+	}
+
+	return result.String()
+}
+
+// day returns the day of a Graph date time, e.g. "2024-10-01T00:00:00.0000000". To Do only keeps the day of due and
+// completed dates, so the time and time zone are left out.
+func day(loc locale.Locale, dt models.DateTimeTimeZoneable) string {
+	date, _, _ := strings.Cut(util.Deref(dt.GetDateTime()), "T")
+	t, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		return date
+	}
+	return loc.Date(t)
+}
+
+// dateTime returns a Graph date time in the local format, followed by its time zone.
+func dateTime(loc locale.Locale, dt models.DateTimeTimeZoneable) string {
+	t, err := time.Parse("2006-01-02T15:04:05.9999999", util.Deref(dt.GetDateTime()))
+	if err != nil {
+		return strings.TrimSpace(util.Deref(dt.GetDateTime()) + " " + util.Deref(dt.GetTimeZone()))
+	}
+	return strings.TrimSpace(loc.LocalDateTime(t) + " " + util.Deref(dt.GetTimeZone()))
+}
@@ -0,0 +1,440 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/common/locale"
+)
+
+const (
+	// diffContext is the number of unchanged lines shown around changes in a unified diff
+	diffContext = 3
+	// maxDiffLines limits the size of the LCS table, larger texts are diffed by their common prefix and suffix only
+	maxDiffLines = 5_000
+)
+
+// row is a record of tabular data, by column
+type row map[string]string
+
+// runDiff renders the changes between the BEFORE and AFTER payloads (or the BEFORE_FILE and AFTER_FILE workspace
+// files) as Markdown: a table for tabular data (JSON arrays of objects or CSV), a field table for JSON objects and a
+// unified diff for everything else.
+func runDiff(ctx context.Context, client *gptscript.GPTScript) (string, error) {
+	before, beforeName, err := diffInput(ctx, client, "BEFORE")
+	if err != nil {
+		return "", err
+	}
+	after, afterName, err := diffInput(ctx, client, "AFTER")
+	if err != nil {
+		return "", err
+	}
+	if beforeName == "" {
+		beforeName = "before"
+	}
+	if afterName == "" {
+		afterName = "after"
+	}
+
+	if bytes.Equal(before, after) {
+		return locale.FromEnv().T("No changes"), nil
+	}
+
+	key := gptscript.GetEnv("KEY", "")
+	if beforeRows, columns, ok := parseTable(before, beforeName); ok {
+		if afterRows, afterColumns, ok := parseTable(after, afterName); ok {
+			return tableDiff(beforeRows, afterRows, mergeColumns(columns, afterColumns), key), nil
+		}
+	}
+
+	var beforeObj, afterObj map[string]any
+	if json.Unmarshal(before, &beforeObj) == nil && json.Unmarshal(after, &afterObj) == nil {
+		return fieldDiff(beforeObj, afterObj), nil
+	}
+
+	return unifiedDiff(beforeName, afterName, before, after), nil
+}
+
+func diffInput(ctx context.Context, client *gptscript.GPTScript, name string) ([]byte, string, error) {
+	if filename := gptscript.GetEnv(name+"_FILE", ""); filename != "" {
+		if client == nil {
+			return nil, "", fmt.Errorf("failed to read %s: no gptscript client", filename)
+		}
+		content, err := client.ReadFileInWorkspace(ctx, path.Join("files", filename))
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read %s: %w", filename, err)
+		}
+		return content, filename, nil
+	}
+	return []byte(gptscript.GetEnv(name, "")), "", nil
+}
+
+// parseTable parses a JSON array of objects or a CSV file (with header) into rows.
+func parseTable(data []byte, name string) ([]row, []string, bool) {
+	if strings.EqualFold(path.Ext(name), ".csv") {
+		records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+		if err != nil || len(records) == 0 {
+			return nil, nil, false
+		}
+		columns := records[0]
+		rows := make([]row, 0, len(records)-1)
+		for _, record := range records[1:] {
+			r := row{}
+			for i, column := range columns {
+				if i < len(record) {
+					r[column] = record[i]
+				}
+			}
+			rows = append(rows, r)
+		}
+		return rows, columns, true
+	}
+
+	var objects []map[string]any
+	if err := json.Unmarshal(data, &objects); err != nil {
+		return nil, nil, false
+	}
+
+	var (
+		rows    = make([]row, 0, len(objects))
+		columns []string
+	)
+	for _, object := range objects {
+		r := row{}
+		// Map order is random, keep the columns of each object sorted so the table is stable
+		keys := make([]string, 0, len(object))
+		for k := range object {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			r[k] = formatValue(object[k])
+			if !slices.Contains(columns, k) {
+				columns = append(columns, k)
+			}
+		}
+		rows = append(rows, r)
+	}
+	return rows, columns, true
+}
+
+func mergeColumns(a, b []string) []string {
+	columns := slices.Clone(a)
+	for _, column := range b {
+		if !slices.Contains(columns, column) {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// tableDiff matches the rows by the key column (or "id" if present, otherwise their position) and renders the
+// added, removed and changed rows. Changed cells are shown as "old → new".
+func tableDiff(before, after []row, columns []string, key string) string {
+	loc := locale.FromEnv()
+	if key == "" {
+		for _, column := range columns {
+			if strings.EqualFold(column, "id") {
+				key = column
+				break
+			}
+		}
+	}
+
+	rowKey := func(r row, i int) string {
+		if key != "" {
+			return r[key]
+		}
+		return fmt.Sprint(i + 1)
+	}
+
+	beforeByKey := map[string]row{}
+	for i, r := range before {
+		beforeByKey[rowKey(r, i)] = r
+	}
+
+	var (
+		lines                   []string
+		seen                    = map[string]bool{}
+		added, changed, removed int
+	)
+	addLine := func(status string, cells []string) {
+		lines = append(lines, "| "+status+" | "+strings.Join(cells, " | ")+" |")
+	}
+
+	for i, r := range after {
+		k := rowKey(r, i)
+		seen[k] = true
+		old, ok := beforeByKey[k]
+		if !ok {
+			added++
+			addLine(loc.T("added"), cells(columns, func(c string) string { return r[c] }))
+			continue
+		}
+
+		var rowChanged bool
+		rowCells := cells(columns, func(c string) string {
+			if old[c] != r[c] {
+				rowChanged = true
+				return fmt.Sprintf("%s → %s", orEmpty(old[c]), orEmpty(r[c]))
+			}
+			return r[c]
+		})
+		if rowChanged {
+			changed++
+			addLine(loc.T("changed"), rowCells)
+		}
+	}
+	for i, r := range before {
+		if k := rowKey(r, i); !seen[k] {
+			removed++
+			addLine(loc.T("removed"), cells(columns, func(c string) string { return r[c] }))
+		}
+	}
+
+	if len(lines) == 0 {
+		return loc.T("No changes")
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, loc.T("%d added, %d changed, %d removed"), added, changed, removed)
+	if key != "" {
+		fmt.Fprintf(&out, loc.T(" (rows matched by %s)"), key)
+	}
+	out.WriteString("\n\n| " + loc.T("Change") + " | " + strings.Join(escapeCells(columns), " | ") + " |\n")
+	out.WriteString("|---" + strings.Repeat("|---", len(columns)) + "|\n")
+	out.WriteString(strings.Join(lines, "\n"))
+	return out.String()
+}
+
+func cells(columns []string, value func(string) string) []string {
+	result := make([]string, 0, len(columns))
+	for _, column := range columns {
+		result = append(result, value(column))
+	}
+	return escapeCells(result)
+}
+
+func escapeCells(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		v = strings.ReplaceAll(v, "|", `\|`)
+		result = append(result, strings.ReplaceAll(v, "\n", "<br>"))
+	}
+	return result
+}
+
+func orEmpty(s string) string {
+	if s == "" {
+		return locale.FromEnv().T("(empty)")
+	}
+	return s
+}
+
+// fieldDiff renders the changed fields of two JSON objects, nested fields are flattened to dotted paths.
+func fieldDiff(before, after map[string]any) string {
+	loc := locale.FromEnv()
+	beforeFields, afterFields := map[string]string{}, map[string]string{}
+	flatten("", before, beforeFields)
+	flatten("", after, afterFields)
+
+	var fields []string
+	for field := range beforeFields {
+		fields = append(fields, field)
+	}
+	for field := range afterFields {
+		if _, ok := beforeFields[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	var lines []string
+	for _, field := range fields {
+		old, hadOld := beforeFields[field]
+		updated, hasNew := afterFields[field]
+		switch {
+		case !hadOld:
+			old = loc.T("(not set)")
+		case !hasNew:
+			updated = loc.T("(removed)")
+		case old == updated:
+			continue
+		}
+		lines = append(lines, "| "+strings.Join(escapeCells([]string{field, old, updated}), " | ")+" |")
+	}
+
+	if len(lines) == 0 {
+		return loc.T("No changes")
+	}
+	return "| " + loc.T("Field") + " | " + loc.T("Before") + " | " + loc.T("After") + " |\n|---|---|---|\n" + strings.Join(lines, "\n")
+}
+
+func flatten(prefix string, value any, fields map[string]string) {
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 && prefix != "" {
+			fields[prefix] = "{}"
+		}
+		for k, child := range v {
+			if prefix != "" {
+				k = prefix + "." + k
+			}
+			flatten(k, child, fields)
+		}
+	default:
+		fields[prefix] = formatValue(v)
+	}
+}
+
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case map[string]any, []any:
+		data, _ := json.Marshal(v)
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// unifiedDiff renders a line based diff in the unified format, in a Markdown code block.
+func unifiedDiff(beforeName, afterName string, before, after []byte) string {
+	a, b := splitLines(string(before)), splitLines(string(after))
+	ops := diffLines(a, b)
+
+	var out strings.Builder
+	out.WriteString("```diff\n")
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", beforeName, afterName)
+
+	// Group the operations into hunks of changes with their surrounding context
+	for start := 0; start < len(ops); {
+		first := slices.IndexFunc(ops[start:], func(op diffOp) bool { return op.kind != ' ' })
+		if first < 0 {
+			break
+		}
+		first += start
+
+		hunkStart := max(first-diffContext, start)
+		hunkEnd := first
+		for i := first; i < len(ops); i++ {
+			if ops[i].kind != ' ' {
+				hunkEnd = i + 1
+			} else if i-hunkEnd >= 2*diffContext {
+				break
+			}
+		}
+		hunkEnd = min(hunkEnd+diffContext, len(ops))
+
+		aStart, bStart := lineNumbers(ops[:hunkStart])
+		aLen, bLen := lineNumbers(ops[hunkStart:hunkEnd])
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", aStart+1, aLen, bStart+1, bLen)
+		for _, op := range ops[hunkStart:hunkEnd] {
+			out.WriteByte(op.kind)
+			out.WriteString(op.line)
+			out.WriteByte('\n')
+		}
+		start = hunkEnd
+	}
+	out.WriteString("```")
+	return out.String()
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// lineNumbers returns the number of lines of the old and new text covered by the operations
+func lineNumbers(ops []diffOp) (int, int) {
+	var a, b int
+	for _, op := range ops {
+		if op.kind != '+' {
+			a++
+		}
+		if op.kind != '-' {
+			b++
+		}
+	}
+	return a, b
+}
+
+// diffLines computes the line operations transforming a into b, based on the longest common subsequence.
+func diffLines(a, b []string) []diffOp {
+	// The common prefix and suffix don't need to be part of the LCS table
+	var prefix, suffix []diffOp
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		prefix = append(prefix, diffOp{' ', a[0]})
+		a, b = a[1:], b[1:]
+	}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		suffix = append([]diffOp{{' ', a[len(a)-1]}}, suffix...)
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+
+	ops := prefix
+	if len(a) > maxDiffLines || len(b) > maxDiffLines {
+		for _, line := range a {
+			ops = append(ops, diffOp{'-', line})
+		}
+		for _, line := range b {
+			ops = append(ops, diffOp{'+', line})
+		}
+		return append(ops, suffix...)
+	}
+
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return append(ops, suffix...)
+}
@@ -2,7 +2,12 @@ module github.com/obot-platform/tools/result-formatter
 
 go 1.23.2
 
-require github.com/gptscript-ai/go-gptscript v0.9.6-0.20241028114905-1fce3cceae35
+replace github.com/gptscript-ai/tools/common => ../common
+
+require (
+	github.com/gptscript-ai/go-gptscript v0.9.6-0.20241106212914-ba040ce8f47b
+	github.com/gptscript-ai/tools/common v0.0.0-00010101000000-000000000000
+)
 
 require (
 	github.com/getkin/kin-openapi v0.124.0 // indirect
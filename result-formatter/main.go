@@ -10,6 +10,7 @@ import (
 	"sync"
 
 	"github.com/gptscript-ai/go-gptscript"
+	"github.com/gptscript-ai/tools/common/locale"
 )
 
 type output struct {
@@ -28,6 +29,7 @@ type document struct {
 }
 
 type hit struct {
+	ID      string `json:"id,omitempty"` // used to give relevance feedback on the hit
 	URL     string `json:"url,omitempty"`
 	Content string `json:"content,omitempty"`
 }
@@ -50,6 +52,19 @@ func main() {
 		_, _ = fmt.Fprintf(os.Stderr, "failed to create gptscript client: %v\n", clientErr)
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		if clientErr != nil {
+			client = nil
+		}
+		result, err := runDiff(ctx, client)
+		if err != nil {
+			fmt.Printf("failed to diff: %v\n", err)
+			return
+		}
+		fmt.Println(result)
+		return
+	}
+
 	if err := json.Unmarshal([]byte(out), &output); err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "failed to unmarshal output: %v\n", err)
 		fmt.Print(out)
@@ -75,6 +90,7 @@ func main() {
 
 			url, _ := doc.Metadata["url"].(string)
 			outDocs = append(outDocs, hit{
+				ID:      doc.ID,
 				URL:     url,
 				Content: doc.Content,
 			})
@@ -125,7 +141,7 @@ func main() {
 	}
 	wg.Wait()
 	if len(outDocs) == 0 {
-		_, _ = fmt.Println("no relevant documents found")
+		_, _ = fmt.Println(locale.FromEnv().T("no relevant documents found"))
 		return
 	}
 	_ = json.NewEncoder(os.Stdout).Encode(outDocs)
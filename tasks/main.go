@@ -1,13 +1,18 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"maps"
+	"net/http"
 	"os"
 	"slices"
+	"strconv"
+	"time"
 
 	"github.com/obot-platform/obot/apiclient"
 	"github.com/obot-platform/obot/apiclient/types"
@@ -19,6 +24,9 @@ var (
 	id       = os.Getenv("ID")
 	threadID = os.Getenv("OBOT_THREAD_ID")
 	args     = os.Getenv("ARGS")
+	schedule = os.Getenv("SCHEDULE")
+	timezone = os.Getenv("TIMEZONE")
+	confirm  = os.Getenv("CONFIRM")
 )
 
 func main() {
@@ -128,9 +136,118 @@ func run(ctx context.Context, c *apiclient.Client) error {
 	return nil
 }
 
+type scheduleInfo struct {
+	TaskID    string    `json:"taskID"`
+	Schedule  string    `json:"schedule"`
+	Spec      *Schedule `json:"spec"`
+	Weekdays  []string  `json:"weekdays,omitempty"`
+	RRule     string    `json:"rrule"`
+	Cron      string    `json:"cron,omitempty"`
+	NextRuns  []string  `json:"nextRuns"`
+	Warnings  []string  `json:"warnings,omitempty"`
+	Confirmed bool      `json:"confirmed"`
+	SavedID   string    `json:"savedID,omitempty"`
+	Message   string    `json:"message"`
+}
+
+// scheduleTask parses the schedule and prints it with its next runs, so the user can confirm it. The schedule is
+// only saved if it was confirmed.
+func scheduleTask(ctx context.Context, c *apiclient.Client, workflowID string) error {
+	if workflowID == "" {
+		return fmt.Errorf("missing task ID")
+	}
+
+	now := time.Now()
+	s, err := ParseSchedule(schedule, timezone, now)
+	if err != nil {
+		return err
+	}
+
+	info := scheduleInfo{
+		TaskID:   workflowID,
+		Schedule: s.String(),
+		Spec:     s,
+		RRule:    s.RRule(),
+	}
+	for _, day := range s.Weekdays {
+		info.Weekdays = append(info.Weekdays, day.String())
+	}
+	for _, run := range s.Next(now, 5) {
+		info.NextRuns = append(info.NextRuns, run.Format(time.RFC3339))
+	}
+	if s.defaultedZone {
+		info.Warnings = append(info.Warnings, "The schedule doesn't name a timezone, so UTC is used.")
+	}
+
+	cron, warning, cronErr := s.Cron(now)
+	info.Cron = cron
+	if warning != "" {
+		info.Warnings = append(info.Warnings, warning)
+	}
+
+	if confirmed, _ := strconv.ParseBool(confirm); !confirmed {
+		info.Message = "The schedule is not saved yet. Show the schedule, the next runs and the warnings to the user. Only after they confirmed it, call Schedule Task again with the same arguments and Confirm set to true."
+		if cronErr != nil {
+			info.Message = fmt.Sprintf("This schedule can't be saved: %v. Ask the user for a different schedule.", cronErr)
+		}
+		return json.NewEncoder(os.Stdout).Encode(info)
+	}
+	if cronErr != nil {
+		return cronErr
+	}
+
+	info.SavedID, err = createCronJob(ctx, c, workflowID, info.Schedule, cron)
+	if err != nil {
+		return fmt.Errorf("save schedule: %v", err)
+	}
+	info.Confirmed = true
+	info.Message = "The schedule is saved."
+	return json.NewEncoder(os.Stdout).Encode(info)
+}
+
+// createCronJob saves the cron schedule of the task
+func createCronJob(ctx context.Context, c *apiclient.Client, workflowID, description, cron string) (string, error) {
+	body, err := json.Marshal(map[string]string{
+		"description": description,
+		"schedule":    cron,
+		"workflow":    workflowID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+"/cronjobs", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		data, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("unexpected status %d: %s", resp.StatusCode, data)
+	}
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
 func mainErr(ctx context.Context) error {
 	if len(os.Args) == 1 {
-		fmt.Printf("incorrect usage: %s [list|run]\n", os.Args[0])
+		fmt.Printf("incorrect usage: %s [list|run|list-runs|schedule]\n", os.Args[0])
 		return nil
 	}
 
@@ -152,6 +269,8 @@ func mainErr(ctx context.Context) error {
 		return run(ctx, client)
 	case "list-runs":
 		return runs(ctx, client, id)
+	case "schedule":
+		return scheduleTask(ctx, client, id)
 	}
 
 	return nil
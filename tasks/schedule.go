@@ -0,0 +1,555 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	minutely = "minutely"
+	hourly   = "hourly"
+	daily    = "daily"
+	weekly   = "weekly"
+	monthly  = "monthly"
+)
+
+// Schedule is a recurrence parsed from a phrase like "every other Tuesday at 9am Pacific".
+type Schedule struct {
+	Frequency string `json:"frequency"`
+	// Interval is the number of minutes, hours, days, weeks or months between runs
+	Interval int            `json:"interval"`
+	Weekdays []time.Weekday `json:"-"`
+	// MonthDay is the day of the month for monthly schedules, -1 for the last day
+	MonthDay int `json:"monthDay,omitempty"`
+	// Ordinal selects the nth weekday of the month for monthly schedules, e.g. 1 for the first Monday, -1 for the last
+	Ordinal  int    `json:"ordinal,omitempty"`
+	Hour     int    `json:"hour"`
+	Minute   int    `json:"minute"`
+	TimeZone string `json:"timeZone"`
+	// Start is the date of the first run, intervals are counted from it
+	Start string `json:"start"`
+
+	loc   *time.Location
+	start time.Time
+	// defaultedZone is set if neither the phrase nor the caller set a timezone
+	defaultedZone bool
+}
+
+var (
+	zoneNames = map[string]string{
+		"pacific": "America/Los_Angeles", "pt": "America/Los_Angeles", "pst": "America/Los_Angeles", "pdt": "America/Los_Angeles",
+		"mountain": "America/Denver", "mt": "America/Denver", "mst": "America/Denver", "mdt": "America/Denver",
+		"central": "America/Chicago", "ct": "America/Chicago", "cst": "America/Chicago", "cdt": "America/Chicago",
+		"eastern": "America/New_York", "et": "America/New_York", "est": "America/New_York", "edt": "America/New_York",
+		"utc": "UTC", "gmt": "UTC",
+		"bst": "Europe/London", "cet": "Europe/Berlin", "cest": "Europe/Berlin",
+		"jst": "Asia/Tokyo", "aest": "Australia/Sydney", "aedt": "Australia/Sydney",
+	}
+	weekdayNames = map[string]time.Weekday{
+		"sunday": time.Sunday, "sun": time.Sunday,
+		"monday": time.Monday, "mon": time.Monday,
+		"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+		"wednesday": time.Wednesday, "wed": time.Wednesday,
+		"thursday": time.Thursday, "thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday,
+		"friday": time.Friday, "fri": time.Friday,
+		"saturday": time.Saturday, "sat": time.Saturday,
+	}
+	ordinals = map[string]int{
+		"first": 1, "1st": 1, "second": 2, "2nd": 2, "third": 3, "3rd": 3, "fourth": 4, "4th": 4, "last": -1,
+	}
+	numbers = map[string]int{
+		"other": 2, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6, "ten": 10, "fifteen": 15, "twenty": 20, "thirty": 30,
+	}
+
+	ianaZone      = regexp.MustCompile(`\b[A-Za-z]+/[A-Za-z_]+(?:/[A-Za-z_]+)?\b`)
+	startDate     = regexp.MustCompile(`\b(?:starting|beginning|from)(?: on)? (\d{4}-\d{2}-\d{2})\b`)
+	clock12       = regexp.MustCompile(`\b(?:at )?(\d{1,2})(?::(\d{2}))? ?(am|pm)\b`)
+	clock24       = regexp.MustCompile(`\b(?:at )?(\d{1,2}):(\d{2})\b`)
+	atHour        = regexp.MustCompile(`\bat (\d{1,2})\b`)
+	minutePast    = regexp.MustCompile(`\bat :(\d{2})\b|\b(\d{1,2}) (?:minutes )?past(?: the hour)?\b`)
+	every         = regexp.MustCompile(`\bevery (\d+|other|two|three|four|five|six|ten|fifteen|twenty|thirty)(?:st|nd|rd|th)? (?:min|hour|day|week|month|sun|mon|tue|wed|thu|fri|sat)`)
+	monthDay      = regexp.MustCompile(`\b(?:on )?(?:the |day )(\d{1,2})(?:st|nd|rd|th)?\b|\b(\d{1,2})(?:st|nd|rd|th)\b`)
+	ordinalDay    = regexp.MustCompile(`\b(first|1st|second|2nd|third|3rd|fourth|4th|last) (sunday|monday|tuesday|wednesday|thursday|friday|saturday|sun|mon|tue|tues|wed|thu|thur|thurs|fri|sat)\b`)
+	minutesPhrase = regexp.MustCompile(`\bmin(?:ute)?s?\b`)
+	hoursPhrase   = regexp.MustCompile(`\bhourly\b|\bhours?\b`)
+	monthsPhrase  = regexp.MustCompile(`\bmonthly\b|\bmonths?\b`)
+	weeksPhrase   = regexp.MustCompile(`\bweekly\b|\bweeks?\b|\bbiweekly\b|\bfortnightly\b|\bweekdays?\b|\bweekends?\b`)
+	daysPhrase    = regexp.MustCompile(`\bdaily\b|\bdays?\b|\bnightly\b|\bevery (?:morning|evening|night)\b`)
+)
+
+// ParseSchedule parses a schedule phrase, e.g. "every other Tuesday at 9am Pacific", "weekdays at 17:30",
+// "every 2 hours at :15" or "on the last Friday of every month at noon UTC". The timezone of the phrase takes
+// precedence over defaultZone, UTC is used if neither is set. The first run is the first matching time after now.
+func ParseSchedule(phrase, defaultZone string, now time.Time) (*Schedule, error) {
+	if strings.TrimSpace(phrase) == "" {
+		return nil, errors.New("missing schedule")
+	}
+
+	s := &Schedule{Interval: 1}
+
+	// Zone names are case-sensitive
+	zone := defaultZone
+	if match := ianaZone.FindString(phrase); match != "" {
+		zone = match
+		phrase = strings.Replace(phrase, match, " ", 1)
+	}
+
+	text := strings.ToLower(phrase)
+	text = strings.NewReplacer("a.m.", "am", "p.m.", "pm", ",", " ", ";", " ", " and ", " ", "&", " ").Replace(text)
+	var (
+		words    []string
+		prevZone bool
+	)
+	for _, word := range strings.Fields(text) {
+		if name, ok := zoneNames[strings.Trim(word, ".")]; ok {
+			zone, prevZone = name, true
+			continue
+		}
+		if prevZone && (word == "time" || word == "timezone") {
+			// e.g. "pacific time"
+			continue
+		}
+		words, prevZone = append(words, word), false
+	}
+	text = " " + strings.Join(words, " ") + " "
+
+	if zone == "" {
+		zone, s.defaultedZone = "UTC", true
+	}
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", zone)
+	}
+	s.loc = loc
+	s.TimeZone = loc.String()
+
+	from := now.In(loc)
+	if match := startDate.FindStringSubmatch(text); match != nil {
+		start, err := time.ParseInLocation("2006-01-02", match[1], loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start date %q", match[1])
+		}
+		if start.After(from) {
+			// Runs at midnight on the start date are included
+			from = start.Add(-time.Nanosecond)
+		}
+		text = strings.Replace(text, match[0], " ", 1)
+	}
+
+	minuteOfHour := -1
+	if match := minutePast.FindStringSubmatch(text); match != nil {
+		if minuteOfHour, _ = strconv.Atoi(match[1] + match[2]); minuteOfHour > 59 {
+			return nil, fmt.Errorf("invalid minute %q", strings.TrimSpace(match[0]))
+		}
+		text = strings.Replace(text, match[0], " ", 1)
+	}
+
+	hasTime, err := s.parseTime(&text)
+	if err != nil {
+		return nil, err
+	}
+
+	if match := every.FindStringSubmatch(text); match != nil {
+		if n, ok := numbers[match[1]]; ok {
+			s.Interval = n
+		} else if s.Interval, err = strconv.Atoi(match[1]); err != nil || s.Interval < 1 {
+			return nil, fmt.Errorf("invalid interval %q", match[1])
+		}
+	}
+
+	switch {
+	case minutesPhrase.MatchString(text):
+		s.Frequency = minutely
+		s.Hour, s.Minute = 0, 0
+	case hoursPhrase.MatchString(text):
+		s.Frequency = hourly
+		s.Hour = 0
+		if minuteOfHour >= 0 {
+			s.Minute = minuteOfHour
+		}
+	case monthsPhrase.MatchString(text):
+		s.Frequency = monthly
+		if err := s.parseMonthDay(text); err != nil {
+			return nil, err
+		}
+	case weeksPhrase.MatchString(text) || len(findWeekdays(text)) > 0:
+		s.Frequency = weekly
+		if strings.Contains(text, " biweekly ") || strings.Contains(text, " fortnightly ") {
+			s.Interval = 2
+		}
+		switch {
+		case strings.Contains(text, " weekday ") || strings.Contains(text, " weekdays "):
+			s.Weekdays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+		case strings.Contains(text, " weekend ") || strings.Contains(text, " weekends "):
+			s.Weekdays = []time.Weekday{time.Saturday, time.Sunday}
+		default:
+			s.Weekdays = findWeekdays(text)
+		}
+		if len(s.Weekdays) == 0 {
+			return nil, errors.New("the schedule doesn't say on which day of the week, e.g. \"every Monday\"")
+		}
+	case daysPhrase.MatchString(text):
+		s.Frequency = daily
+	default:
+		return nil, fmt.Errorf("could not understand the schedule %q, try e.g. \"every weekday at 9am\", \"every other Tuesday at 14:00 Pacific\" or \"on the 1st of every month at noon UTC\"", phrase)
+	}
+
+	if !hasTime && s.Frequency != minutely && s.Frequency != hourly {
+		return nil, errors.New("the schedule doesn't say at which time, e.g. \"at 9am\"")
+	}
+	if s.Frequency == minutely && s.Interval > 60*24 {
+		return nil, errors.New("minute intervals must be at most a day")
+	}
+
+	// The first run is the first matching time, intervals are counted from it
+	interval := s.Interval
+	s.Interval = 1
+	s.start = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+	if s.Frequency != minutely && s.Frequency != hourly {
+		first := s.Next(from, 1)
+		if len(first) == 0 {
+			return nil, errors.New("the schedule never runs")
+		}
+		s.start = time.Date(first[0].Year(), first[0].Month(), first[0].Day(), 0, 0, 0, 0, loc)
+	}
+	s.Interval = interval
+	s.Start = s.start.Format("2006-01-02")
+	return s, nil
+}
+
+func (s *Schedule) parseTime(text *string) (bool, error) {
+	replace := func(match string) {
+		*text = strings.Replace(*text, match, " ", 1)
+	}
+	switch {
+	case strings.Contains(*text, " noon ") || strings.Contains(*text, " midday "):
+		s.Hour = 12
+		return true, nil
+	case strings.Contains(*text, " midnight "):
+		s.Hour = 0
+		return true, nil
+	}
+
+	if match := clock12.FindStringSubmatch(*text); match != nil {
+		hour, _ := strconv.Atoi(match[1])
+		minute, _ := strconv.Atoi(match[2])
+		if hour < 1 || hour > 12 || minute > 59 {
+			return false, fmt.Errorf("invalid time %q", strings.TrimSpace(match[0]))
+		}
+		if match[3] == "pm" && hour != 12 {
+			hour += 12
+		} else if match[3] == "am" && hour == 12 {
+			hour = 0
+		}
+		s.Hour, s.Minute = hour, minute
+		replace(match[0])
+		return true, nil
+	}
+	if match := clock24.FindStringSubmatch(*text); match != nil {
+		hour, _ := strconv.Atoi(match[1])
+		minute, _ := strconv.Atoi(match[2])
+		if hour > 23 || minute > 59 {
+			return false, fmt.Errorf("invalid time %q", strings.TrimSpace(match[0]))
+		}
+		s.Hour, s.Minute = hour, minute
+		replace(match[0])
+		return true, nil
+	}
+	if match := atHour.FindStringSubmatch(*text); match != nil {
+		hour, _ := strconv.Atoi(match[1])
+		if hour > 23 {
+			return false, fmt.Errorf("invalid time %q", strings.TrimSpace(match[0]))
+		}
+		s.Hour = hour
+		replace(match[0])
+		return true, nil
+	}
+	return false, nil
+}
+
+func (s *Schedule) parseMonthDay(text string) error {
+	if match := ordinalDay.FindStringSubmatch(text); match != nil {
+		s.Ordinal = ordinals[match[1]]
+		s.Weekdays = []time.Weekday{weekdayNames[match[2]]}
+		return nil
+	}
+	if strings.Contains(text, " last day ") {
+		s.MonthDay = -1
+		return nil
+	}
+	if match := monthDay.FindStringSubmatch(text); match != nil {
+		day, _ := strconv.Atoi(match[1] + match[2])
+		if day < 1 || day > 31 {
+			return fmt.Errorf("invalid day of the month %d", day)
+		}
+		s.MonthDay = day
+		return nil
+	}
+	return errors.New("the schedule doesn't say on which day of the month, e.g. \"on the 1st\" or \"on the first Monday\"")
+}
+
+func findWeekdays(text string) []time.Weekday {
+	var weekdays []time.Weekday
+	for _, word := range strings.Fields(text) {
+		day, ok := weekdayNames[strings.TrimSuffix(word, "s")]
+		if !ok {
+			day, ok = weekdayNames[word]
+		}
+		if ok && !slices.Contains(weekdays, day) {
+			weekdays = append(weekdays, day)
+		}
+	}
+	slices.Sort(weekdays)
+	return weekdays
+}
+
+// Next returns the next n run times after the given time.
+func (s *Schedule) Next(after time.Time, n int) []time.Time {
+	var runs []time.Time
+	switch s.Frequency {
+	case minutely, hourly:
+		step := time.Duration(s.Interval) * time.Minute
+		if s.Frequency == hourly {
+			step = time.Duration(s.Interval) * time.Hour
+		}
+		next := s.start.Add(time.Duration(s.Minute) * time.Minute)
+		if next.Before(after) || next.Equal(after) {
+			next = next.Add((after.Sub(next)/step + 1) * step)
+		}
+		for ; len(runs) < n; next = next.Add(step) {
+			runs = append(runs, next)
+		}
+	default:
+		day := after.In(s.loc)
+		day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, s.loc)
+		// Monthly schedules on e.g. the 31st or the fifth Monday don't run every month
+		for i := 0; len(runs) < n && i < 366*5; i++ {
+			d := day.AddDate(0, 0, i)
+			if !s.matches(d) {
+				continue
+			}
+			if run := time.Date(d.Year(), d.Month(), d.Day(), s.Hour, s.Minute, 0, 0, s.loc); run.After(after) {
+				runs = append(runs, run)
+			}
+		}
+	}
+	return runs
+}
+
+func (s *Schedule) matches(d time.Time) bool {
+	if d.Before(s.start) {
+		return false
+	}
+	switch s.Frequency {
+	case daily:
+		return daysBetween(s.start, d)%s.Interval == 0
+	case weekly:
+		if !slices.Contains(s.Weekdays, d.Weekday()) {
+			return false
+		}
+		return (daysBetween(startOfWeek(s.start), startOfWeek(d))/7)%s.Interval == 0
+	case monthly:
+		months := (d.Year()-s.start.Year())*12 + int(d.Month()) - int(s.start.Month())
+		if months%s.Interval != 0 {
+			return false
+		}
+		lastDay := d.AddDate(0, 0, 1).Month() != d.Month()
+		switch {
+		case s.Ordinal > 0:
+			return d.Weekday() == s.Weekdays[0] && (d.Day()-1)/7+1 == s.Ordinal
+		case s.Ordinal < 0:
+			return d.Weekday() == s.Weekdays[0] && d.AddDate(0, 0, 7).Month() != d.Month()
+		case s.MonthDay < 0:
+			return lastDay
+		default:
+			return d.Day() == s.MonthDay
+		}
+	}
+	return false
+}
+
+// daysBetween returns the number of calendar days from a to b, regardless of daylight saving time changes
+func daysBetween(a, b time.Time) int {
+	ua := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	ub := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(ub.Sub(ua).Hours() / 24)
+}
+
+// startOfWeek returns the Monday of the week of t
+func startOfWeek(t time.Time) time.Time {
+	return t.AddDate(0, 0, -((int(t.Weekday()) + 6) % 7))
+}
+
+// String returns the normalized schedule, e.g. "every other week on Tuesday at 09:00 (America/Los_Angeles)"
+func (s *Schedule) String() string {
+	var sb strings.Builder
+	at := fmt.Sprintf(" at %02d:%02d", s.Hour, s.Minute)
+	switch s.Frequency {
+	case minutely:
+		sb.WriteString("every " + plural(s.Interval, "minute"))
+		at = ""
+	case hourly:
+		sb.WriteString("every " + plural(s.Interval, "hour"))
+		at = fmt.Sprintf(" at :%02d", s.Minute)
+	case daily:
+		sb.WriteString("every " + plural(s.Interval, "day"))
+	case weekly:
+		names := make([]string, 0, len(s.Weekdays))
+		for _, day := range s.Weekdays {
+			names = append(names, day.String())
+		}
+		sb.WriteString("every " + plural(s.Interval, "week") + " on " + strings.Join(names, ", "))
+	case monthly:
+		switch {
+		case s.Ordinal != 0:
+			ordinal := map[int]string{1: "first", 2: "second", 3: "third", 4: "fourth", -1: "last"}[s.Ordinal]
+			sb.WriteString("on the " + ordinal + " " + s.Weekdays[0].String())
+		case s.MonthDay < 0:
+			sb.WriteString("on the last day")
+		default:
+			sb.WriteString(fmt.Sprintf("on day %d", s.MonthDay))
+		}
+		sb.WriteString(" of every " + plural(s.Interval, "month"))
+	}
+	sb.WriteString(at + " (" + s.TimeZone + ")")
+	if s.Interval > 1 && s.Frequency != minutely && s.Frequency != hourly {
+		sb.WriteString(", starting " + s.Start)
+	}
+	return sb.String()
+}
+
+func plural(n int, unit string) string {
+	switch n {
+	case 1:
+		return unit
+	case 2:
+		return "other " + unit
+	default:
+		return fmt.Sprintf("%d %ss", n, unit)
+	}
+}
+
+// RRule returns the schedule as an iCalendar (RFC 5545) recurrence rule.
+func (s *Schedule) RRule() string {
+	rule := []string{"FREQ=" + strings.ToUpper(s.Frequency), fmt.Sprintf("INTERVAL=%d", s.Interval)}
+	var days []string
+	for _, day := range s.Weekdays {
+		days = append(days, strings.ToUpper(day.String()[:2]))
+	}
+	switch {
+	case s.Frequency == weekly:
+		rule = append(rule, "BYDAY="+strings.Join(days, ","))
+	case s.Frequency == monthly && s.Ordinal != 0:
+		rule = append(rule, fmt.Sprintf("BYDAY=%d%s", s.Ordinal, days[0]))
+	case s.Frequency == monthly:
+		rule = append(rule, fmt.Sprintf("BYMONTHDAY=%d", s.MonthDay))
+	}
+	if s.Frequency != minutely {
+		if s.Frequency != hourly {
+			rule = append(rule, fmt.Sprintf("BYHOUR=%d", s.Hour))
+		}
+		rule = append(rule, fmt.Sprintf("BYMINUTE=%d", s.Minute))
+	}
+	return fmt.Sprintf("DTSTART;TZID=%s:%sT000000\nRRULE:%s", s.TimeZone, s.start.Format("20060102"), strings.Join(rule, ";"))
+}
+
+// Cron returns the schedule as a cron expression in UTC, which is how the task scheduler runs tasks. Schedules
+// that cron can't express (e.g. every other week) return an error. If the timezone has daylight saving time, the
+// returned warning says how the run time shifts.
+func (s *Schedule) Cron(now time.Time) (string, string, error) {
+	next := s.Next(now, 1)[0]
+	_, offset := next.Zone()
+	offset /= 60
+
+	var warning string
+	_, january := time.Date(now.Year(), time.January, 1, 12, 0, 0, 0, s.loc).Zone()
+	_, july := time.Date(now.Year(), time.July, 1, 12, 0, 0, 0, s.loc).Zone()
+	if january != july {
+		warning = fmt.Sprintf("%s observes daylight saving time, but the task scheduler runs tasks in UTC: the task runs at %02d:%02d %s only until the next change of daylight saving time, and an hour earlier or later after it.",
+			s.TimeZone, next.Hour(), next.Minute(), next.Format("MST"))
+	}
+
+	local := s.Hour*60 + s.Minute
+	utc := local - offset
+	dayShift := 0
+	switch {
+	case utc < 0:
+		utc += 24 * 60
+		dayShift = -1
+	case utc >= 24*60:
+		utc -= 24 * 60
+		dayShift = 1
+	}
+	hour, minute := utc/60, utc%60
+
+	notSupported := fmt.Errorf("the task scheduler can't run a task %s, it only supports schedules that cron can express", s.String())
+	switch s.Frequency {
+	case minutely:
+		if s.Interval == 1 {
+			return "* * * * *", "", nil
+		}
+		if 60%s.Interval != 0 {
+			return "", "", notSupported
+		}
+		return fmt.Sprintf("*/%d * * * *", s.Interval), "", nil
+	case hourly:
+		if 24%s.Interval != 0 {
+			return "", "", notSupported
+		}
+		minute = ((s.Minute-offset)%60 + 60) % 60
+		if s.Interval == 1 {
+			return fmt.Sprintf("%d * * * *", minute), "", nil
+		}
+		var hours []string
+		for h := 0; h < 24; h += s.Interval {
+			utcHour := ((h*60+s.Minute-offset)%(24*60) + 24*60) % (24 * 60) / 60
+			hours = append(hours, strconv.Itoa(utcHour))
+		}
+		slices.SortFunc(hours, func(a, b string) int {
+			x, _ := strconv.Atoi(a)
+			y, _ := strconv.Atoi(b)
+			return x - y
+		})
+		return fmt.Sprintf("%d %s * * *", minute, strings.Join(hours, ",")), warning, nil
+	case daily:
+		if s.Interval != 1 {
+			return "", "", notSupported
+		}
+		return fmt.Sprintf("%d %d * * *", minute, hour), warning, nil
+	case weekly:
+		if s.Interval != 1 {
+			return "", "", notSupported
+		}
+		days := make([]int, 0, len(s.Weekdays))
+		for _, day := range s.Weekdays {
+			days = append(days, (int(day)+dayShift+7)%7)
+		}
+		slices.Sort(days)
+		dayList := make([]string, 0, len(days))
+		for _, day := range days {
+			dayList = append(dayList, strconv.Itoa(day))
+		}
+		return fmt.Sprintf("%d %d * * %s", minute, hour, strings.Join(dayList, ",")), warning, nil
+	case monthly:
+		// Moving the day of the month doesn't work across month boundaries
+		if s.Interval != 1 || dayShift != 0 {
+			return "", "", notSupported
+		}
+		switch {
+		case s.Ordinal > 0:
+			return fmt.Sprintf("%d %d * * %d#%d", minute, hour, s.Weekdays[0], s.Ordinal), warning, nil
+		case s.Ordinal < 0:
+			return fmt.Sprintf("%d %d * * %dL", minute, hour, s.Weekdays[0]), warning, nil
+		case s.MonthDay < 0:
+			return fmt.Sprintf("%d %d L * *", minute, hour), warning, nil
+		default:
+			return fmt.Sprintf("%d %d %d * *", minute, hour, s.MonthDay), warning, nil
+		}
+	}
+	return "", "", notSupported
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// now is a Monday
+var now = time.Date(2025, time.January, 6, 12, 0, 0, 0, time.UTC)
+
+func TestParseSchedule(t *testing.T) {
+	for _, tc := range []struct {
+		phrase, defaultZone string
+		description         string
+		next                []string
+		cron                string
+		cronErr             bool
+		warning             bool
+	}{
+		{
+			phrase:      "every other Tuesday at 9am Pacific",
+			description: "every other week on Tuesday at 09:00 (America/Los_Angeles), starting 2025-01-07",
+			next:        []string{"2025-01-07T09:00:00-08:00", "2025-01-21T09:00:00-08:00", "2025-02-04T09:00:00-08:00", "2025-02-18T09:00:00-08:00", "2025-03-04T09:00:00-08:00"},
+			cronErr:     true,
+		},
+		{
+			phrase:      "weekdays at 17:30",
+			defaultZone: "Europe/Berlin",
+			description: "every week on Monday, Tuesday, Wednesday, Thursday, Friday at 17:30 (Europe/Berlin)",
+			next:        []string{"2025-01-06T17:30:00+01:00", "2025-01-07T17:30:00+01:00", "2025-01-08T17:30:00+01:00", "2025-01-09T17:30:00+01:00", "2025-01-10T17:30:00+01:00"},
+			cron:        "30 16 * * 1,2,3,4,5",
+			warning:     true,
+		},
+		{
+			phrase:      "every Monday and Thursday at 8 p.m. Eastern time",
+			description: "every week on Monday, Thursday at 20:00 (America/New_York)",
+			next:        []string{"2025-01-06T20:00:00-05:00", "2025-01-09T20:00:00-05:00", "2025-01-13T20:00:00-05:00", "2025-01-16T20:00:00-05:00", "2025-01-20T20:00:00-05:00"},
+			cron:        "0 1 * * 2,5",
+			warning:     true,
+		},
+		{
+			phrase:      "every 2 hours at :15",
+			description: "every other hour at :15 (UTC)",
+			next:        []string{"2025-01-06T12:15:00Z", "2025-01-06T14:15:00Z", "2025-01-06T16:15:00Z", "2025-01-06T18:15:00Z", "2025-01-06T20:15:00Z"},
+			cron:        "15 0,2,4,6,8,10,12,14,16,18,20,22 * * *",
+		},
+		{
+			phrase:      "every 15 minutes",
+			description: "every 15 minutes (UTC)",
+			next:        []string{"2025-01-06T12:15:00Z", "2025-01-06T12:30:00Z", "2025-01-06T12:45:00Z", "2025-01-06T13:00:00Z", "2025-01-06T13:15:00Z"},
+			cron:        "*/15 * * * *",
+		},
+		{
+			phrase:      "daily at noon Asia/Tokyo",
+			description: "every day at 12:00 (Asia/Tokyo)",
+			next:        []string{"2025-01-07T12:00:00+09:00", "2025-01-08T12:00:00+09:00", "2025-01-09T12:00:00+09:00", "2025-01-10T12:00:00+09:00", "2025-01-11T12:00:00+09:00"},
+			cron:        "0 3 * * *",
+		},
+		{
+			phrase:      "on the last Friday of every month at 10:00 UTC",
+			description: "on the last Friday of every month at 10:00 (UTC)",
+			next:        []string{"2025-01-31T10:00:00Z", "2025-02-28T10:00:00Z", "2025-03-28T10:00:00Z", "2025-04-25T10:00:00Z", "2025-05-30T10:00:00Z"},
+			cron:        "0 10 * * 5L",
+		},
+		{
+			phrase:      "every other month on the 15th at 8am starting 2025-02-01",
+			description: "on day 15 of every other month at 08:00 (UTC), starting 2025-02-15",
+			next:        []string{"2025-02-15T08:00:00Z", "2025-04-15T08:00:00Z", "2025-06-15T08:00:00Z", "2025-08-15T08:00:00Z", "2025-10-15T08:00:00Z"},
+			cronErr:     true,
+		},
+	} {
+		t.Run(tc.phrase, func(t *testing.T) {
+			s, err := ParseSchedule(tc.phrase, tc.defaultZone, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if description := s.String(); description != tc.description {
+				t.Errorf("expected %q, got %q", tc.description, description)
+			}
+
+			var next []string
+			for _, run := range s.Next(now, 5) {
+				next = append(next, run.Format(time.RFC3339))
+			}
+			if !reflect.DeepEqual(next, tc.next) {
+				t.Errorf("expected next runs %v, got %v", tc.next, next)
+			}
+
+			cron, warning, err := s.Cron(now)
+			if tc.cronErr {
+				if err == nil {
+					t.Errorf("expected an error, got cron %q", cron)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cron != tc.cron {
+				t.Errorf("expected cron %q, got %q", tc.cron, cron)
+			}
+			if (warning != "") != tc.warning {
+				t.Errorf("unexpected warning %q", warning)
+			}
+		})
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	for phrase, expected := range map[string]string{
+		"every Tuesday":          "at which time",
+		"whenever it rains":      "could not understand",
+		"daily at 25:00":         "invalid time",
+		"every week at 9am":      "which day of the week",
+		"monthly at 9am":         "which day of the month",
+		"daily at 9am Mars/Base": "unknown timezone",
+	} {
+		if _, err := ParseSchedule(phrase, "", now); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: expected error containing %q, got %v", phrase, expected, err)
+		}
+	}
+}
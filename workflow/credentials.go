@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/gptscript-ai/go-gptscript"
+)
+
+// stepCredentialsInput is the input of the Step Credentials tool. A workflow step declares the credentials it needs
+// by name, e.g.
+//
+//	Credential: step-credentials from github.com/obot-platform/tools/workflow with github,slack as credentials
+type stepCredentialsInput struct {
+	// Credentials is the comma separated list of the credential names in the credential store
+	Credentials string `json:"credentials"`
+	// CredentialContext is the comma separated list of credential contexts to look the credentials up in
+	CredentialContext string `json:"credential_context,omitempty"`
+}
+
+type credentialOutput struct {
+	Env map[string]string `json:"env"`
+	// Ephemeral keeps gptscript from storing a copy of the credentials for this step, so they are always read from the
+	// credential store when the step runs
+	Ephemeral bool `json:"ephemeral"`
+}
+
+// stepCredentials reads the declared credentials from the credential store and prints their environment variables.
+// gptscript only sets these environment variables for the tool that declared the credential, so they are scoped to
+// the step. The values are never logged.
+func stepCredentials(ctx context.Context, rawInput string) error {
+	var in stepCredentialsInput
+	if err := json.Unmarshal([]byte(rawInput), &in); err != nil {
+		return fmt.Errorf("failed to parse input: %w", err)
+	}
+
+	names := splitList(in.Credentials)
+	if len(names) == 0 {
+		return errors.New("no credentials declared for the step")
+	}
+	credContexts := splitList(in.CredentialContext)
+	if len(credContexts) == 0 {
+		credContexts = []string{"default"}
+	}
+
+	client, err := gptscript.NewGPTScript()
+	if err != nil {
+		return fmt.Errorf("failed to create gptscript client: %w", err)
+	}
+	defer client.Close()
+
+	env := make(map[string]string)
+	// source is the credential each environment variable came from, to report conflicts
+	source := make(map[string]string)
+	for _, name := range names {
+		cred, err := client.RevealCredential(ctx, credContexts, name)
+		if errors.As(err, &gptscript.ErrNotFound{}) {
+			return fmt.Errorf("credential %q not found in the credential store", name)
+		} else if err != nil {
+			return fmt.Errorf("failed to read credential %q: %w", name, err)
+		}
+
+		for key, value := range cred.Env {
+			if other, ok := source[key]; ok && env[key] != value {
+				return fmt.Errorf("credentials %q and %q both set the environment variable %s", other, name, key)
+			}
+			env[key] = value
+			source[key] = name
+		}
+	}
+
+	return json.NewEncoder(os.Stdout).Encode(credentialOutput{
+		Env:       env,
+		Ephemeral: true,
+	})
+}
+
+func splitList(s string) []string {
+	var result []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" && !slices.Contains(result, item) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -1,9 +1,15 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"unicode"
 )
 
 var inputText = os.Getenv("WORKFLOW_INPUT")
@@ -13,13 +19,40 @@ const (
 important headers.`
 	emailContext = `This workflow is being called from an email receiver. The input is a JSON structure of the email message body and any
 important email headers (from, to, subject, etc).`
+	credentialsContext = `Never pass secrets like tokens, passwords or API keys in the inputs of workflow steps. Steps that need secrets
+declare the credentials by name and get them as environment variables when they run. Values of the input that look
+like secrets are replaced with [REDACTED].`
+
+	redacted = "[REDACTED]"
 )
 
+// secretKeys are the words (or sequences of words) of JSON keys in the workflow input whose values are redacted. Keys
+// are split into words at separators and camelCase boundaries, e.g. accessToken, X-Api-Key or client_secrets match.
+var secretKeys = [][]string{
+	{"password"}, {"passwd"}, {"secret"}, {"token"}, {"apikey"}, {"api", "key"}, {"authorization"}, {"credential"},
+	{"privatekey"}, {"private", "key"}, {"cookie"},
+}
+
 type workflowInput struct {
 	Type string `json:"type"`
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "step-credentials" {
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+
+		var input string
+		if len(os.Args) > 2 {
+			input = os.Args[2]
+		}
+		if err := stepCredentials(ctx, input); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	var structuredInput workflowInput
 	if err := json.Unmarshal([]byte(inputText), &structuredInput); err == nil {
 		var context string
@@ -34,5 +67,94 @@ func main() {
 		}
 	}
 
-	fmt.Printf("START WORKFLOW INPUT:\n%s\nEND WORKFLOW INPUT\n\n", inputText)
+	fmt.Printf("START WORKFLOW CONTEXT:\n%s\nEND START WORKFLOW CONTEXT\n\n", credentialsContext)
+	fmt.Printf("START WORKFLOW INPUT:\n%s\nEND WORKFLOW INPUT\n\n", scrubSecrets(inputText))
+}
+
+// scrubSecrets redacts the values of the keys that look like secrets if the input is JSON, so secrets passed in the
+// input don't end up in the prompt and the logs of the workflow
+func scrubSecrets(input string) string {
+	var data any
+	if err := json.Unmarshal([]byte(input), &data); err != nil {
+		return input
+	}
+	if !redact(data) {
+		return input
+	}
+	scrubbed, err := json.Marshal(data)
+	if err != nil {
+		return input
+	}
+	return string(scrubbed)
+}
+
+// redact replaces the string values of secret keys in place and returns whether anything was replaced
+func redact(data any) bool {
+	var changed bool
+	switch v := data.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if s, ok := value.(string); ok && s != "" && isSecretKey(key) {
+				v[key] = redacted
+				changed = true
+			} else if redact(value) {
+				changed = true
+			}
+		}
+	case []any:
+		for _, value := range v {
+			if redact(value) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// isSecretKey reports whether the words of the key contain one of the secret keys. Whole words are compared, so keys
+// like tokenizer or secretary are not redacted, a trailing s is ignored (e.g. tokens).
+func isSecretKey(key string) bool {
+	words := keyWords(key)
+	for i := range words {
+		for _, secret := range secretKeys {
+			if len(words)-i >= len(secret) && slices.EqualFunc(words[i:i+len(secret)], secret, func(word, secretWord string) bool {
+				return word == secretWord || word == secretWord+"s"
+			}) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// keyWords splits the key into lowercase words at non-alphanumeric characters and camelCase boundaries, e.g.
+// "X-API-Key" and "xAPIKey" both become x, api and key.
+func keyWords(key string) []string {
+	var (
+		words   []string
+		current []rune
+	)
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, strings.ToLower(string(current)))
+			current = nil
+		}
+	}
+
+	runes := []rune(key)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		// A new word starts at an upper case letter after a lower case letter or digit (apiKey), or at the last upper
+		// case letter of an acronym followed by a lower case letter (APIKey)
+		if unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+			(unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			flush()
+		}
+		current = append(current, r)
+	}
+	flush()
+	return words
 }
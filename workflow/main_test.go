@@ -0,0 +1,107 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIsSecretKey(t *testing.T) {
+	for _, tc := range []struct {
+		key  string
+		want bool
+	}{
+		{"password", true},
+		{"Password", true},
+		{"user_password", true},
+		{"passwords", true},
+		{"passwd", true},
+		{"client_secret", true},
+		{"clientSecret", true},
+		{"secrets", true},
+		{"token", true},
+		{"accessToken", true},
+		{"refresh-token", true},
+		{"apikey", true},
+		{"apiKey", true},
+		{"APIKey", true},
+		{"X-Api-Key", true},
+		{"api_key", true},
+		{"Authorization", true},
+		{"credentials", true},
+		{"private_key", true},
+		{"privateKey", true},
+		{"Set-Cookie", true},
+		{"oauth2Token", true},
+		{"tokenizer", false},
+		{"tokenized", false},
+		{"secretary", false},
+		{"passwordless", false},
+		{"monkey", false},
+		{"api", false},
+		{"key", false},
+		{"private", false},
+		{"subject", false},
+		{"", false},
+	} {
+		if got := isSecretKey(tc.key); got != tc.want {
+			t.Errorf("isSecretKey(%q) = %v, want %v", tc.key, got, tc.want)
+		}
+	}
+}
+
+func TestKeyWords(t *testing.T) {
+	for key, want := range map[string][]string{
+		"X-API-Key":     {"x", "api", "key"},
+		"xAPIKey":       {"x", "api", "key"},
+		"client_secret": {"client", "secret"},
+		"oauth2Token":   {"oauth2", "token"},
+		"headers.auth":  {"headers", "auth"},
+		"--":            nil,
+	} {
+		if got := keyWords(key); !reflect.DeepEqual(got, want) {
+			t.Errorf("keyWords(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestScrubSecrets(t *testing.T) {
+	for _, tc := range []struct {
+		name, input, want string
+	}{
+		{
+			name:  "not JSON",
+			input: "password: hunter2",
+			want:  "password: hunter2",
+		},
+		{
+			name:  "nothing to redact",
+			input: `{"type": "webhook", "tokenizer": "bpe"}`,
+			want:  `{"type": "webhook", "tokenizer": "bpe"}`,
+		},
+		{
+			name:  "nested",
+			input: `{"type":"webhook","headers":{"Authorization":"Bearer abc","X-Request-Id":"1"},"items":[{"apiKey":"xyz","max_tokens":10}]}`,
+			want:  `{"headers":{"Authorization":"[REDACTED]","X-Request-Id":"1"},"items":[{"apiKey":"[REDACTED]","max_tokens":10}],"type":"webhook"}`,
+		},
+		{
+			name:  "empty values are kept",
+			input: `{"password":""}`,
+			want:  `{"password":""}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := scrubSecrets(tc.input); got != tc.want {
+				t.Errorf("scrubSecrets() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	if got, want := splitList(" github, slack,,github "), []string{"github", "slack"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("splitList() = %q, want %q", got, want)
+	}
+	if got := splitList(""); got != nil {
+		t.Errorf("splitList(\"\") = %q, want nil", got)
+	}
+}
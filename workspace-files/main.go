@@ -3,10 +3,12 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
 	"path"
+	"path/filepath"
 	"strings"
 	"unicode/utf8"
 
@@ -18,12 +20,14 @@ const FilesDir = "files"
 var (
 	FileEnv     = os.Getenv("FILENAME")
 	MaxFileSize = 1 << 16
+	// ExtractTextTool is the knowledge tool that converts documents to text, using the same loaders as ingestion
+	ExtractTextTool = gptscript.GetEnv("EXTRACT_TEXT_TOOL", filepath.Join(os.Getenv("GPTSCRIPT_TOOL_DIR"), "..", "knowledge", "extract-text.gpt"))
 )
 
 func main() {
 	if len(os.Args) == 1 {
 		fmt.Printf(`
-Subcommands: read, write, copy
+Subcommands: read, write, copy, extractText
 env: FILENAME, CONTENT,  TO_FILENAME, GPTSCRIPT_WORKSPACE_DIR
 Usage: go run main.go <path>\n`)
 		return
@@ -64,6 +68,11 @@ Usage: go run main.go <path>\n`)
 			fmt.Printf("Failed to copy %s to %s: %v\n", FileEnv, toFilename, err)
 			return
 		}
+	case "extractText":
+		if err := extractText(ctx, FileEnv); err != nil {
+			fmt.Printf("Failed to extract text from %s: %v\n", FileEnv, err)
+			return
+		}
 	}
 }
 
@@ -200,3 +209,54 @@ func copy(ctx context.Context, filename, toFilename string) error {
 
 	return client.WriteFileInWorkspace(ctx, path.Join(FilesDir, toFilename), data)
 }
+
+func extractText(ctx context.Context, filename string) error {
+	client, err := gptscript.NewGPTScript()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	input, err := json.Marshal(map[string]string{
+		"input": path.Join(FilesDir, filename),
+	})
+	if err != nil {
+		return err
+	}
+
+	run, err := client.Run(ctx, ExtractTextTool, gptscript.Options{
+		Input: string(input),
+		GlobalOptions: gptscript.GlobalOptions{
+			Env: []string{"GPTSCRIPT_WORKSPACE_ID=" + os.Getenv("GPTSCRIPT_WORKSPACE_ID")},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	defer run.Close()
+
+	text, err := run.Text()
+	if err != nil {
+		return err
+	}
+
+	// The knowledge tool reports errors as JSON on stdout
+	var result struct {
+		Error               string `json:"error"`
+		UnsupportedFiletype string `json:"unsupportedFiletype"`
+	}
+	if json.Unmarshal([]byte(text), &result) == nil {
+		switch {
+		case result.Error != "":
+			return errors.New(result.Error)
+		case result.UnsupportedFiletype != "":
+			return fmt.Errorf("unsupported file type %s", result.UnsupportedFiletype)
+		}
+	}
+
+	if len(text) > MaxFileSize {
+		text = text[:MaxFileSize] + fmt.Sprintf("\n\n[Truncated, the extracted text exceeds %d bytes]", MaxFileSize)
+	}
+	fmt.Println(text)
+	return nil
+}